@@ -19,6 +19,7 @@ import (
 	configaccess "github.com/router-for-me/CLIProxyAPI/v6/internal/access/config_access"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/kiro"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/buildinfo"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/cache"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/cmd"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
@@ -85,6 +86,9 @@ func main() {
 	var githubCopilotLogin bool
 	var projectID string
 	var vertexImport string
+	var replayRef string
+	var replayAuth string
+	var replayMock bool
 	var configPath string
 	var password string
 	var noIncognito bool
@@ -111,6 +115,9 @@ func main() {
 	flag.StringVar(&projectID, "project_id", "", "Project ID (Gemini only, not required)")
 	flag.StringVar(&configPath, "config", DefaultConfigPath, "Configure File Path")
 	flag.StringVar(&vertexImport, "vertex-import", "", "Import Vertex service account key JSON file")
+	flag.StringVar(&replayRef, "replay", "", "Replay a captured request log (file path or request ID) through the handler stack")
+	flag.StringVar(&replayAuth, "replay-auth", "", "Credential ID or file name to use with --replay")
+	flag.BoolVar(&replayMock, "replay-mock", false, "Serve the recorded upstream response instead of calling the provider with --replay")
 	flag.StringVar(&password, "password", "", "")
 
 	flag.CommandLine.Usage = func() {
@@ -464,6 +471,7 @@ func main() {
 	// Register the shared token store once so all components use the same persistence backend.
 	if usePostgresStore {
 		sdkAuth.RegisterTokenStore(pgStoreInst)
+		cache.SetSignatureCacheDatabase(pgStoreInst.DB(), pgStoreSchema)
 	} else if useObjectStore {
 		sdkAuth.RegisterTokenStore(objectStoreInst)
 	} else if useGitStore {
@@ -480,6 +488,9 @@ func main() {
 	if vertexImport != "" {
 		// Handle Vertex service account import
 		cmd.DoVertexImport(cfg, vertexImport)
+	} else if replayRef != "" {
+		// Replay a captured request log against a chosen credential or a mock upstream
+		cmd.DoReplay(cfg, configFilePath, replayRef, cmd.ReplayOptions{AuthID: replayAuth, Mock: replayMock})
 	} else if login {
 		// Handle Google/Gemini login
 		cmd.DoLogin(cfg, projectID, options)
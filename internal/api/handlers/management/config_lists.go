@@ -144,15 +144,17 @@ func (h *Handler) PutGeminiKeys(c *gin.Context) {
 	h.cfg.SanitizeGeminiKeys()
 	h.persist(c)
 }
+
+type geminiKeyPatch struct {
+	APIKey         *string            `json:"api-key"`
+	Prefix         *string            `json:"prefix"`
+	BaseURL        *string            `json:"base-url"`
+	ProxyURL       *string            `json:"proxy-url"`
+	Headers        *map[string]string `json:"headers"`
+	ExcludedModels *[]string          `json:"excluded-models"`
+}
+
 func (h *Handler) PatchGeminiKey(c *gin.Context) {
-	type geminiKeyPatch struct {
-		APIKey         *string            `json:"api-key"`
-		Prefix         *string            `json:"prefix"`
-		BaseURL        *string            `json:"base-url"`
-		ProxyURL       *string            `json:"proxy-url"`
-		Headers        *map[string]string `json:"headers"`
-		ExcludedModels *[]string          `json:"excluded-models"`
-	}
 	var body struct {
 		Index *int            `json:"index"`
 		Match *string         `json:"match"`
@@ -270,16 +272,18 @@ func (h *Handler) PutClaudeKeys(c *gin.Context) {
 	h.cfg.SanitizeClaudeKeys()
 	h.persist(c)
 }
+
+type claudeKeyPatch struct {
+	APIKey         *string               `json:"api-key"`
+	Prefix         *string               `json:"prefix"`
+	BaseURL        *string               `json:"base-url"`
+	ProxyURL       *string               `json:"proxy-url"`
+	Models         *[]config.ClaudeModel `json:"models"`
+	Headers        *map[string]string    `json:"headers"`
+	ExcludedModels *[]string             `json:"excluded-models"`
+}
+
 func (h *Handler) PatchClaudeKey(c *gin.Context) {
-	type claudeKeyPatch struct {
-		APIKey         *string               `json:"api-key"`
-		Prefix         *string               `json:"prefix"`
-		BaseURL        *string               `json:"base-url"`
-		ProxyURL       *string               `json:"proxy-url"`
-		Models         *[]config.ClaudeModel `json:"models"`
-		Headers        *map[string]string    `json:"headers"`
-		ExcludedModels *[]string             `json:"excluded-models"`
-	}
 	var body struct {
 		Index *int            `json:"index"`
 		Match *string         `json:"match"`
@@ -393,15 +397,17 @@ func (h *Handler) PutOpenAICompat(c *gin.Context) {
 	h.cfg.SanitizeOpenAICompatibility()
 	h.persist(c)
 }
+
+type openAICompatPatch struct {
+	Name          *string                             `json:"name"`
+	Prefix        *string                             `json:"prefix"`
+	BaseURL       *string                             `json:"base-url"`
+	APIKeyEntries *[]config.OpenAICompatibilityAPIKey `json:"api-key-entries"`
+	Models        *[]config.OpenAICompatibilityModel  `json:"models"`
+	Headers       *map[string]string                  `json:"headers"`
+}
+
 func (h *Handler) PatchOpenAICompat(c *gin.Context) {
-	type openAICompatPatch struct {
-		Name          *string                             `json:"name"`
-		Prefix        *string                             `json:"prefix"`
-		BaseURL       *string                             `json:"base-url"`
-		APIKeyEntries *[]config.OpenAICompatibilityAPIKey `json:"api-key-entries"`
-		Models        *[]config.OpenAICompatibilityModel  `json:"models"`
-		Headers       *map[string]string                  `json:"headers"`
-	}
 	var body struct {
 		Name  *string            `json:"name"`
 		Index *int               `json:"index"`
@@ -515,15 +521,17 @@ func (h *Handler) PutVertexCompatKeys(c *gin.Context) {
 	h.cfg.SanitizeVertexCompatKeys()
 	h.persist(c)
 }
+
+type vertexCompatPatch struct {
+	APIKey   *string                     `json:"api-key"`
+	Prefix   *string                     `json:"prefix"`
+	BaseURL  *string                     `json:"base-url"`
+	ProxyURL *string                     `json:"proxy-url"`
+	Headers  *map[string]string          `json:"headers"`
+	Models   *[]config.VertexCompatModel `json:"models"`
+}
+
 func (h *Handler) PatchVertexCompatKey(c *gin.Context) {
-	type vertexCompatPatch struct {
-		APIKey   *string                     `json:"api-key"`
-		Prefix   *string                     `json:"prefix"`
-		BaseURL  *string                     `json:"base-url"`
-		ProxyURL *string                     `json:"proxy-url"`
-		Headers  *map[string]string          `json:"headers"`
-		Models   *[]config.VertexCompatModel `json:"models"`
-	}
 	var body struct {
 		Index *int               `json:"index"`
 		Match *string            `json:"match"`
@@ -835,16 +843,18 @@ func (h *Handler) PutCodexKeys(c *gin.Context) {
 	h.cfg.SanitizeCodexKeys()
 	h.persist(c)
 }
+
+type codexKeyPatch struct {
+	APIKey         *string              `json:"api-key"`
+	Prefix         *string              `json:"prefix"`
+	BaseURL        *string              `json:"base-url"`
+	ProxyURL       *string              `json:"proxy-url"`
+	Models         *[]config.CodexModel `json:"models"`
+	Headers        *map[string]string   `json:"headers"`
+	ExcludedModels *[]string            `json:"excluded-models"`
+}
+
 func (h *Handler) PatchCodexKey(c *gin.Context) {
-	type codexKeyPatch struct {
-		APIKey         *string              `json:"api-key"`
-		Prefix         *string              `json:"prefix"`
-		BaseURL        *string              `json:"base-url"`
-		ProxyURL       *string              `json:"proxy-url"`
-		Models         *[]config.CodexModel `json:"models"`
-		Headers        *map[string]string   `json:"headers"`
-		ExcludedModels *[]string            `json:"excluded-models"`
-	}
 	var body struct {
 		Index *int           `json:"index"`
 		Match *string        `json:"match"`
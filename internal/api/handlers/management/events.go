@@ -0,0 +1,240 @@
+package management
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/events"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	maxEventStreamBuffer    = 4096
+	eventStreamPingInterval = 15 * time.Second
+	eventStreamWriteTimeout = 10 * time.Second
+)
+
+var eventStreamTopics = map[string]struct{}{
+	events.TopicAuth:     {},
+	events.TopicRefresh:  {},
+	events.TopicCooldown: {},
+	events.TopicConfig:   {},
+	events.TopicRequest:  {},
+}
+
+// eventStreamUpgrader only accepts same-origin and loopback browser origins so that web
+// pages the operator visits cannot open the stream with their management session.
+var eventStreamUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+	CheckOrigin:     checkEventStreamOrigin,
+}
+
+// checkEventStreamOrigin allows requests without an Origin header (non-browser clients),
+// requests from the server's own origin, and requests from localhost.
+func checkEventStreamOrigin(r *http.Request) bool {
+	origin := strings.TrimSpace(r.Header.Get("Origin"))
+	if origin == "" {
+		return true
+	}
+	parsed, errParse := url.Parse(origin)
+	if errParse != nil || parsed.Host == "" {
+		return false
+	}
+	if strings.EqualFold(parsed.Host, r.Host) {
+		return true
+	}
+	switch strings.ToLower(parsed.Hostname()) {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
+
+// GetEvents streams live management events over SSE, or over a WebSocket when the
+// request asks for an upgrade.
+//
+// Query parameters:
+//   - topics: comma separated subset of auth, refresh, cooldown, config, request (default all)
+//   - buffer: per-subscriber buffer size; events beyond it are dropped and reported
+//   - sample: fraction (0-1] of request summaries to deliver (default 1)
+func (h *Handler) GetEvents(c *gin.Context) {
+	opts, errOpts := parseEventStreamOptions(c)
+	if errOpts != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errOpts.Error()})
+		return
+	}
+
+	if websocket.IsWebSocketUpgrade(c.Request) {
+		h.serveEventsWebsocket(c, opts)
+		return
+	}
+	h.serveEventsSSE(c, opts)
+}
+
+func parseEventStreamOptions(c *gin.Context) (events.SubscribeOptions, error) {
+	opts := events.SubscribeOptions{Buffer: events.DefaultBufferSize}
+	if raw := strings.TrimSpace(c.Query("topics")); raw != "" {
+		for _, topic := range strings.Split(raw, ",") {
+			topic = strings.ToLower(strings.TrimSpace(topic))
+			if topic == "" {
+				continue
+			}
+			if _, ok := eventStreamTopics[topic]; !ok {
+				return opts, fmt.Errorf("unknown topic %q", topic)
+			}
+			opts.Topics = append(opts.Topics, topic)
+		}
+	}
+	if raw := strings.TrimSpace(c.Query("buffer")); raw != "" {
+		buffer, errParse := strconv.Atoi(raw)
+		if errParse != nil || buffer <= 0 {
+			return opts, fmt.Errorf("invalid buffer")
+		}
+		if buffer > maxEventStreamBuffer {
+			buffer = maxEventStreamBuffer
+		}
+		opts.Buffer = buffer
+	}
+	if raw := strings.TrimSpace(c.Query("sample")); raw != "" {
+		rate, errParse := strconv.ParseFloat(raw, 64)
+		if errParse != nil || rate <= 0 || rate > 1 {
+			return opts, fmt.Errorf("invalid sample: must be in (0, 1]")
+		}
+		opts.RequestSampleRate = rate
+	}
+	return opts, nil
+}
+
+func (h *Handler) serveEventsSSE(c *gin.Context, opts events.SubscribeOptions) {
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "streaming not supported"})
+		return
+	}
+	sub := events.DefaultBus().Subscribe(opts)
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	_, _ = fmt.Fprintf(c.Writer, "event: ready\ndata: %s\n\n", eventStreamReadyPayload(opts))
+	flusher.Flush()
+
+	ping := time.NewTicker(eventStreamPingInterval)
+	defer ping.Stop()
+	var reportedDrops uint64
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-ping.C:
+			if _, errWrite := fmt.Fprint(c.Writer, ": ping\n\n"); errWrite != nil {
+				return
+			}
+			flusher.Flush()
+		case evt, open := <-sub.C():
+			if !open {
+				return
+			}
+			if dropped := sub.Dropped(); dropped > reportedDrops {
+				_, _ = fmt.Fprintf(c.Writer, "event: dropped\ndata: {\"count\":%d}\n\n", dropped-reportedDrops)
+				reportedDrops = dropped
+			}
+			payload, errMarshal := json.Marshal(evt)
+			if errMarshal != nil {
+				log.WithError(errMarshal).Debug("management events: failed to encode event")
+				continue
+			}
+			if _, errWrite := fmt.Fprintf(c.Writer, "id: %d\nevent: %s.%s\ndata: %s\n\n", evt.ID, evt.Topic, evt.Type, payload); errWrite != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (h *Handler) serveEventsWebsocket(c *gin.Context, opts events.SubscribeOptions) {
+	conn, errUpgrade := eventStreamUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if errUpgrade != nil {
+		log.WithError(errUpgrade).Debug("management events: websocket upgrade failed")
+		return
+	}
+	defer func() {
+		if errClose := conn.Close(); errClose != nil {
+			log.WithError(errClose).Debug("management events: websocket close failed")
+		}
+	}()
+	sub := events.DefaultBus().Subscribe(opts)
+	defer sub.Close()
+
+	// Drain client frames so control messages are processed and disconnects are noticed.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, errRead := conn.ReadMessage(); errRead != nil {
+				return
+			}
+		}
+	}()
+
+	write := func(messageType int, payload []byte) bool {
+		_ = conn.SetWriteDeadline(time.Now().Add(eventStreamWriteTimeout))
+		return conn.WriteMessage(messageType, payload) == nil
+	}
+	if !write(websocket.TextMessage, []byte(`{"topic":"stream","type":"ready","data":`+eventStreamReadyPayload(opts)+`}`)) {
+		return
+	}
+
+	ping := time.NewTicker(eventStreamPingInterval)
+	defer ping.Stop()
+	var reportedDrops uint64
+	for {
+		select {
+		case <-closed:
+			return
+		case <-ping.C:
+			if !write(websocket.PingMessage, nil) {
+				return
+			}
+		case evt, open := <-sub.C():
+			if !open {
+				return
+			}
+			if dropped := sub.Dropped(); dropped > reportedDrops {
+				notice := fmt.Sprintf(`{"topic":"stream","type":"dropped","data":{"count":%d}}`, dropped-reportedDrops)
+				if !write(websocket.TextMessage, []byte(notice)) {
+					return
+				}
+				reportedDrops = dropped
+			}
+			payload, errMarshal := json.Marshal(evt)
+			if errMarshal != nil {
+				log.WithError(errMarshal).Debug("management events: failed to encode event")
+				continue
+			}
+			if !write(websocket.TextMessage, payload) {
+				return
+			}
+		}
+	}
+}
+
+func eventStreamReadyPayload(opts events.SubscribeOptions) string {
+	topics := opts.Topics
+	if len(topics) == 0 {
+		topics = []string{events.TopicAuth, events.TopicRefresh, events.TopicCooldown, events.TopicConfig, events.TopicRequest}
+	}
+	payload, _ := json.Marshal(gin.H{"topics": topics, "buffer": opts.Buffer})
+	return string(payload)
+}
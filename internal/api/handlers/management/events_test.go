@@ -0,0 +1,106 @@
+package management
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/events"
+)
+
+func TestGetEventsStreamsFilteredTopics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := &Handler{}
+	router.GET("/events", h.GetEvents)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events?topics=cooldown", nil)
+	resp, errDo := http.DefaultClient.Do(req)
+	if errDo != nil {
+		t.Fatalf("request: %v", errDo)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Fatalf("content type = %q", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() (string, string) {
+		var name, data string
+		for {
+			line, errRead := reader.ReadString('\n')
+			if errRead != nil {
+				t.Fatalf("read: %v", errRead)
+			}
+			line = strings.TrimRight(line, "\n")
+			switch {
+			case line == "":
+				return name, data
+			case strings.HasPrefix(line, "event: "):
+				name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}
+
+	if name, _ := readEvent(); name != "ready" {
+		t.Fatalf("first event = %q, want ready", name)
+	}
+	events.Publish(events.TopicAuth, "updated", events.AuthData{ID: "ignored"})
+	events.Publish(events.TopicCooldown, "started", events.CooldownData{AuthID: "auth-1", Model: "m"})
+
+	name, data := readEvent()
+	if name != "cooldown.started" {
+		t.Fatalf("event = %q, want cooldown.started", name)
+	}
+	if !strings.Contains(data, `"auth_id":"auth-1"`) {
+		t.Fatalf("unexpected data: %s", data)
+	}
+}
+
+func TestGetEventsRejectsUnknownTopic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := &Handler{}
+	router.GET("/events", h.GetEvents)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events?topics=bogus", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestCheckEventStreamOrigin(t *testing.T) {
+	cases := []struct {
+		origin string
+		want   bool
+	}{
+		{"", true},
+		{"http://proxy.example.com:8317", true},
+		{"http://localhost:5173", true},
+		{"http://127.0.0.1:3000", true},
+		{"https://evil.example.net", false},
+		{"http://proxy.example.com.evil.net:8317", false},
+		{"null", false},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "http://proxy.example.com:8317/v0/management/events", nil)
+		if tc.origin != "" {
+			req.Header.Set("Origin", tc.origin)
+		}
+		if got := checkEventStreamOrigin(req); got != tc.want {
+			t.Errorf("checkEventStreamOrigin(%q) = %v, want %v", tc.origin, got, tc.want)
+		}
+	}
+}
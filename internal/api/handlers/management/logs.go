@@ -14,12 +14,15 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 )
 
 const (
 	defaultLogFileName      = "main.log"
 	logScannerInitialBuffer = 64 * 1024
 	logScannerMaxBuffer     = 8 * 1024 * 1024
+
+	defaultRequestLogIndexLimit = 100
 )
 
 // GetLogs returns log lines with optional incremental loading.
@@ -297,6 +300,98 @@ func (h *Handler) GetRequestLogByID(c *gin.Context) {
 	c.FileAttachment(fullPath, matchedFile)
 }
 
+// GetRequestLogIndex searches the request log index with optional filters and pagination.
+// Supported query parameters: request-id, key, model, auth, format, status, failed,
+// from, to (unix seconds or RFC3339), limit and offset. It returns 404 when RequestLog
+// is disabled.
+func (h *Handler) GetRequestLogIndex(c *gin.Context) {
+	if h == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "handler unavailable"})
+		return
+	}
+	if h.cfg == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "configuration unavailable"})
+		return
+	}
+	if !h.cfg.RequestLog {
+		c.JSON(http.StatusNotFound, gin.H{"error": "request logging is disabled"})
+		return
+	}
+
+	dir := h.logDirectory()
+	if strings.TrimSpace(dir) == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "log directory not configured"})
+		return
+	}
+
+	filter := logging.RequestLogIndexFilter{
+		RequestID:     strings.TrimSpace(c.Query("request-id")),
+		Model:         strings.TrimSpace(c.Query("model")),
+		AuthID:        strings.TrimSpace(c.Query("auth")),
+		HandlerFormat: strings.TrimSpace(c.Query("format")),
+	}
+	if key := strings.TrimSpace(c.Query("key")); key != "" {
+		filter.ClientKey = util.HideAPIKey(key)
+	}
+	if raw := strings.TrimSpace(c.Query("status")); raw != "" {
+		status, errStatus := strconv.Atoi(raw)
+		if errStatus != nil || status <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+			return
+		}
+		filter.Status = status
+	}
+	if raw := strings.TrimSpace(c.Query("failed")); raw != "" {
+		failed, errFailed := strconv.ParseBool(raw)
+		if errFailed != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid failed flag"})
+			return
+		}
+		filter.FailedOnly = failed
+	}
+	var errTime error
+	if filter.From, errTime = parseIndexTime(c.Query("from")); errTime != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid from: %v", errTime)})
+		return
+	}
+	if filter.To, errTime = parseIndexTime(c.Query("to")); errTime != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid to: %v", errTime)})
+		return
+	}
+	limit, errLimit := parseLimit(c.Query("limit"))
+	if errLimit != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid limit: %v", errLimit)})
+		return
+	}
+	if limit == 0 {
+		limit = defaultRequestLogIndexLimit
+	}
+	filter.Limit = limit
+	if raw := strings.TrimSpace(c.Query("offset")); raw != "" {
+		offset, errOffset := strconv.Atoi(raw)
+		if errOffset != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+			return
+		}
+		filter.Offset = offset
+	}
+
+	entries, total, err := logging.QueryRequestLogIndex(dir, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to read request log index: %v", err)})
+		return
+	}
+	if entries == nil {
+		entries = []logging.RequestLogIndexEntry{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"total":   total,
+		"limit":   filter.Limit,
+		"offset":  filter.Offset,
+	})
+}
+
 // DownloadRequestErrorLog downloads a specific error request log file by name.
 func (h *Handler) DownloadRequestErrorLog(c *gin.Context) {
 	if h == nil {
@@ -487,6 +582,21 @@ func parseCutoff(raw string) int64 {
 	return ts
 }
 
+func parseIndexTime(raw string) (time.Time, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if ts, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(ts, 0), nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("must be unix seconds or RFC3339")
+	}
+	return parsed, nil
+}
+
 func parseLimit(raw string) (int, error) {
 	value := strings.TrimSpace(raw)
 	if value == "" {
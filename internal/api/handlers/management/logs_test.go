@@ -0,0 +1,98 @@
+package management
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/tidwall/gjson"
+)
+
+func TestGetRequestLogIndexFiltersAndPaginates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	base := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	entries := []logging.RequestLogIndexEntry{
+		{RequestID: "a", Timestamp: base, File: "a.log", ClientKey: util.HideAPIKey("sk-client-one"), RequestedModel: "gpt-5", HandlerFormat: "openai", Status: 500},
+		{RequestID: "b", Timestamp: base.Add(time.Minute), File: "b.log", ClientKey: util.HideAPIKey("sk-client-one"), RequestedModel: "gpt-5", HandlerFormat: "openai", Status: 200},
+		{RequestID: "c", Timestamp: base.Add(2 * time.Minute), File: "c.log", ClientKey: util.HideAPIKey("sk-client-two"), RequestedModel: "claude-opus-4", HandlerFormat: "claude", AuthID: "auth-1", Status: 429},
+		{RequestID: "d", Timestamp: base.Add(3 * time.Minute), File: "d.log", ClientKey: util.HideAPIKey("sk-client-one"), RequestedModel: "gpt-5", HandlerFormat: "openai", Status: 502},
+	}
+	for _, entry := range entries {
+		if err := logging.AppendRequestLogIndex(dir, entry); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+
+	router := gin.New()
+	h := &Handler{cfg: &config.Config{SDKConfig: config.SDKConfig{RequestLog: true}}, logDir: dir}
+	router.GET("/request-logs", h.GetRequestLogIndex)
+	query := func(rawQuery string) (int, gjson.Result) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/request-logs?"+rawQuery, nil))
+		return rec.Code, gjson.Parse(rec.Body.String())
+	}
+	ids := func(body gjson.Result) []string {
+		var out []string
+		for _, entry := range body.Get("entries").Array() {
+			out = append(out, entry.Get("request_id").String())
+		}
+		return out
+	}
+
+	cases := []struct {
+		query string
+		total int64
+		ids   []string
+	}{
+		{query: "key=sk-client-one&failed=true", total: 2, ids: []string{"d", "a"}},
+		{query: "model=claude&auth=auth-1", total: 1, ids: []string{"c"}},
+		{query: "format=openai&status=200", total: 1, ids: []string{"b"}},
+		{query: "request-id=a", total: 1, ids: []string{"a"}},
+		{query: "from=" + base.Add(30*time.Second).Format(time.RFC3339) + "&to=" + base.Add(150*time.Second).Format(time.RFC3339), total: 2, ids: []string{"c", "b"}},
+		{query: "limit=2&offset=1", total: 4, ids: []string{"c", "b"}},
+		{query: "offset=10", total: 4},
+	}
+	for _, tc := range cases {
+		code, body := query(tc.query)
+		if code != http.StatusOK {
+			t.Fatalf("%s: status = %d, body %s", tc.query, code, body.Raw)
+		}
+		if got := body.Get("total").Int(); got != tc.total {
+			t.Fatalf("%s: total = %d, want %d", tc.query, got, tc.total)
+		}
+		if got := ids(body); len(got) != len(tc.ids) || (len(got) > 0 && got[0] != tc.ids[0]) || (len(got) > 1 && got[1] != tc.ids[1]) {
+			t.Fatalf("%s: entries = %v, want %v", tc.query, got, tc.ids)
+		}
+		if !body.Get("entries").IsArray() {
+			t.Fatalf("%s: entries is not an array: %s", tc.query, body.Raw)
+		}
+	}
+	if _, body := query("limit=2&offset=1"); body.Get("limit").Int() != 2 || body.Get("offset").Int() != 1 {
+		t.Fatalf("pagination not echoed: %s", body.Raw)
+	}
+
+	for _, bad := range []string{"status=abc", "failed=maybe", "from=yesterday", "limit=-1", "offset=-1"} {
+		if code, _ := query(bad); code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400", bad, code)
+		}
+	}
+}
+
+func TestGetRequestLogIndexRequiresRequestLogging(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := &Handler{cfg: &config.Config{}, logDir: t.TempDir()}
+	router.GET("/request-logs", h.GetRequestLogIndex)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/request-logs", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", rec.Code)
+	}
+}
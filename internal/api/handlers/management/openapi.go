@@ -0,0 +1,217 @@
+package management
+
+import (
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/api/openapi"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/cache"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/events"
+)
+
+const (
+	tagConfig = "config"
+	tagKeys   = "provider-keys"
+	tagAmp    = "ampcode"
+	tagLogs   = "logs"
+	tagUsage  = "usage"
+	tagAuth   = "auth"
+	tagOAuth  = "oauth"
+	tagTools  = "tools"
+)
+
+var (
+	statusResponse  = openapi.Fields{"status": ""}
+	authURLResponse = openapi.Fields{"status": "", "url": "", "state": ""}
+	indexQuery      = openapi.Param{Name: "index", Type: "integer", Description: "zero-based entry index"}
+	apiKeyQuery     = openapi.Param{Name: "api-key", Description: "match the entry by API key"}
+	webUIQuery      = openapi.Param{Name: "is_webui", Type: "boolean", Description: "complete the flow through the management web UI callback"}
+)
+
+// valueBody describes the {"value": ...} envelope accepted by the scalar config endpoints.
+func valueBody(sample any) openapi.Fields { return openapi.Fields{"value": sample} }
+
+// scalarOps documents a GET/PUT/PATCH triple for a single config value.
+func scalarOps(ops map[string]openapi.Operation, path, key, summary string, sample any) {
+	ops[openapi.RouteKey("GET", path)] = openapi.Operation{Summary: "Get " + summary, Tag: tagConfig, Response: openapi.Fields{key: sample}}
+	ops[openapi.RouteKey("PUT", path)] = openapi.Operation{Summary: "Set " + summary, Tag: tagConfig, Request: valueBody(sample), Response: statusResponse}
+	ops[openapi.RouteKey("PATCH", path)] = openapi.Operation{Summary: "Set " + summary, Tag: tagConfig, Request: valueBody(sample), Response: statusResponse}
+}
+
+// listOps documents the GET/PUT/PATCH/DELETE set of a config list endpoint. PUT accepts
+// either the bare list or {"items": [...]}.
+func listOps(ops map[string]openapi.Operation, path, key, summary string, list any, patch any, deleteQuery ...openapi.Param) {
+	ops[openapi.RouteKey("GET", path)] = openapi.Operation{Summary: "List " + summary, Tag: tagKeys, Response: openapi.Fields{key: list}}
+	ops[openapi.RouteKey("PUT", path)] = openapi.Operation{Summary: "Replace " + summary, Tag: tagKeys, Request: openapi.OneOf{list, openapi.Fields{"items": list}}, Response: statusResponse}
+	ops[openapi.RouteKey("PATCH", path)] = openapi.Operation{Summary: "Update one of " + summary, Tag: tagKeys, Request: patch, Response: statusResponse}
+	ops[openapi.RouteKey("DELETE", path)] = openapi.Operation{Summary: "Delete one of " + summary, Tag: tagKeys, Query: deleteQuery, Response: statusResponse}
+}
+
+// OpenAPIOperations describes every management route, keyed by openapi.RouteKey with
+// paths relative to /v0/management. Routes registered without an entry here fail the
+// server's OpenAPI coverage test.
+func OpenAPIOperations() map[string]openapi.Operation {
+	ops := map[string]openapi.Operation{
+		"GET /openapi.json": {Summary: "OpenAPI document for the public and management APIs", Tag: tagTools, Response: openapi.Schema{"type": "object"}},
+
+		"GET /usage":         {Summary: "Usage statistics snapshot", Tag: tagUsage, Response: openapi.Fields{"usage": usage.StatisticsSnapshot{}, "failed_requests": int64(0)}},
+		"GET /usage/export":  {Summary: "Export usage statistics", Tag: tagUsage, Response: usageExportPayload{}},
+		"POST /usage/import": {Summary: "Merge an exported usage snapshot", Tag: tagUsage, Request: usageImportPayload{}, Response: openapi.Fields{"added": 0, "skipped": 0, "total_requests": int64(0), "failed_requests": int64(0)}},
+
+		"GET /signature-cache": {Summary: "Thinking signature cache backend, size and hit rate", Tag: tagUsage, Response: openapi.Fields{"signature-cache": cache.SignatureCacheStats{}}},
+		"GET /events": {
+			Summary: "Live event stream over SSE, or WebSocket when upgraded",
+			Tag:     tagUsage,
+			Query: []openapi.Param{
+				{Name: "topics", Description: "comma separated subset of auth, refresh, cooldown, config, request"},
+				{Name: "buffer", Type: "integer", Description: "per-subscriber buffer size"},
+				{Name: "sample", Type: "number", Description: "fraction (0-1] of request summaries to deliver"},
+			},
+			Response:        events.Event{},
+			ResponseContent: openapi.ContentSSE,
+		},
+
+		"GET /config":         {Summary: "Current configuration", Tag: tagConfig, Response: config.Config{}},
+		"GET /config.yaml":    {Summary: "Raw configuration file", Tag: tagConfig, Response: openapi.Schema{"type": "string"}, ResponseContent: openapi.ContentYAML},
+		"PUT /config.yaml":    {Summary: "Replace the configuration file", Tag: tagConfig, Request: openapi.Schema{"type": "string"}, RequestContent: openapi.ContentYAML, Response: openapi.Fields{"ok": true, "changed": []string{}}},
+		"GET /latest-version": {Summary: "Latest released version", Tag: tagConfig, Response: openapi.Fields{"latest-version": ""}},
+		"DELETE /proxy-url":   {Summary: "Clear the global proxy URL", Tag: tagConfig, Response: statusResponse},
+		"POST /api-call": {
+			Summary:  "Perform an HTTP request with credential substitution",
+			Tag:      tagTools,
+			Request:  apiCallRequest{},
+			Response: apiCallResponse{},
+		},
+
+		"GET /logs": {
+			Summary: "Main log lines",
+			Tag:     tagLogs,
+			Query: []openapi.Param{
+				{Name: "after", Type: "integer", Description: "only lines after this unix timestamp"},
+				{Name: "limit", Type: "integer", Description: "maximum number of lines"},
+			},
+			Response: openapi.Fields{"lines": []string{}, "line-count": 0, "latest-timestamp": int64(0)},
+		},
+		"DELETE /logs":                  {Summary: "Clear main logs", Tag: tagLogs, Response: openapi.Fields{"success": true, "message": "", "removed": 0}},
+		"GET /request-error-logs":       {Summary: "List error request logs", Tag: tagLogs, Response: openapi.Fields{"files": []openapi.Fields{{"name": "", "size": int64(0), "modified": int64(0)}}}},
+		"GET /request-error-logs/:name": {Summary: "Download an error request log", Tag: tagLogs, Response: openapi.Schema{"type": "string", "format": "binary"}, ResponseContent: openapi.ContentOctet},
+		"GET /request-log-by-id/:id":    {Summary: "Download a request log by request ID", Tag: tagLogs, Response: openapi.Schema{"type": "string", "format": "binary"}, ResponseContent: openapi.ContentOctet},
+		"GET /request-logs": {
+			Summary: "Search the request log index",
+			Tag:     tagLogs,
+			Query: []openapi.Param{
+				{Name: "request-id"},
+				{Name: "key", Description: "client API key"},
+				{Name: "model"},
+				{Name: "auth", Description: "auth ID"},
+				{Name: "format", Description: "handler format"},
+				{Name: "status", Type: "integer"},
+				{Name: "failed", Type: "boolean"},
+				{Name: "from", Description: "unix seconds or RFC3339"},
+				{Name: "to", Description: "unix seconds or RFC3339"},
+				{Name: "limit", Type: "integer"},
+				{Name: "offset", Type: "integer"},
+			},
+			Response: openapi.Fields{"entries": []logging.RequestLogIndexEntry{}, "total": 0, "limit": 0, "offset": 0},
+		},
+
+		"GET /ampcode":                      {Summary: "Ampcode configuration", Tag: tagAmp, Response: openapi.Fields{"ampcode": config.AmpCode{}}},
+		"DELETE /ampcode/upstream-url":      {Summary: "Clear the ampcode upstream URL", Tag: tagAmp, Response: statusResponse},
+		"DELETE /ampcode/upstream-api-key":  {Summary: "Clear the ampcode upstream API key", Tag: tagAmp, Response: statusResponse},
+		"GET /ampcode/model-mappings":       {Summary: "List ampcode model mappings", Tag: tagAmp, Response: openapi.Fields{"model-mappings": []config.AmpModelMapping{}}},
+		"PUT /ampcode/model-mappings":       {Summary: "Replace ampcode model mappings", Tag: tagAmp, Request: valueBody([]config.AmpModelMapping{}), Response: statusResponse},
+		"PATCH /ampcode/model-mappings":     {Summary: "Add or update ampcode model mappings by source model", Tag: tagAmp, Request: valueBody([]config.AmpModelMapping{}), Response: statusResponse},
+		"DELETE /ampcode/model-mappings":    {Summary: "Remove ampcode model mappings by source model; an empty list clears all", Tag: tagAmp, Request: valueBody([]string{}), Response: statusResponse},
+		"GET /ampcode/upstream-api-keys":    {Summary: "List ampcode upstream API key mappings", Tag: tagAmp, Response: openapi.Fields{"upstream-api-keys": []config.AmpUpstreamAPIKeyEntry{}}},
+		"PUT /ampcode/upstream-api-keys":    {Summary: "Replace ampcode upstream API key mappings", Tag: tagAmp, Request: valueBody([]config.AmpUpstreamAPIKeyEntry{}), Response: statusResponse},
+		"PATCH /ampcode/upstream-api-keys":  {Summary: "Add or update ampcode upstream API key mappings", Tag: tagAmp, Request: valueBody([]config.AmpUpstreamAPIKeyEntry{}), Response: statusResponse},
+		"DELETE /ampcode/upstream-api-keys": {Summary: "Remove ampcode upstream API key mappings; an empty list clears all", Tag: tagAmp, Request: valueBody([]string{}), Response: statusResponse},
+
+		"GET /routing/strategy":   {Summary: "Get the credential routing strategy", Tag: tagConfig, Response: openapi.Fields{"strategy": ""}},
+		"PUT /routing/strategy":   {Summary: "Set the credential routing strategy (round-robin or fill-first)", Tag: tagConfig, Request: valueBody(""), Response: statusResponse},
+		"PATCH /routing/strategy": {Summary: "Set the credential routing strategy (round-robin or fill-first)", Tag: tagConfig, Request: valueBody(""), Response: statusResponse},
+
+		"GET /oauth-excluded-models":    {Summary: "List models excluded per OAuth provider", Tag: tagKeys, Response: openapi.Fields{"oauth-excluded-models": map[string][]string{}}},
+		"PUT /oauth-excluded-models":    {Summary: "Replace models excluded per OAuth provider", Tag: tagKeys, Request: openapi.OneOf{map[string][]string{}, openapi.Fields{"items": map[string][]string{}}}, Response: statusResponse},
+		"PATCH /oauth-excluded-models":  {Summary: "Set the excluded models of one OAuth provider", Tag: tagKeys, Request: openapi.Fields{"provider": "", "models": []string{}}, Response: statusResponse},
+		"DELETE /oauth-excluded-models": {Summary: "Remove the excluded models of one OAuth provider", Tag: tagKeys, Query: []openapi.Param{{Name: "provider", Required: true}}, Response: statusResponse},
+		"GET /oauth-model-alias":        {Summary: "List model aliases per OAuth channel", Tag: tagKeys, Response: openapi.Fields{"oauth-model-alias": map[string][]config.OAuthModelAlias{}}},
+		"PUT /oauth-model-alias":        {Summary: "Replace model aliases per OAuth channel", Tag: tagKeys, Request: openapi.OneOf{map[string][]config.OAuthModelAlias{}, openapi.Fields{"items": map[string][]config.OAuthModelAlias{}}}, Response: statusResponse},
+		"PATCH /oauth-model-alias":      {Summary: "Set the model aliases of one OAuth channel", Tag: tagKeys, Request: openapi.Fields{"channel": "", "provider": "", "aliases": []config.OAuthModelAlias{}}, Response: statusResponse},
+		"DELETE /oauth-model-alias":     {Summary: "Remove the model aliases of one OAuth channel", Tag: tagKeys, Query: []openapi.Param{{Name: "channel"}, {Name: "provider"}}, Response: statusResponse},
+
+		"GET /auth-files": {Summary: "List credential files", Tag: tagAuth, Response: openapi.Fields{"files": []openapi.Fields{{
+			"id": "", "auth_index": "", "name": "", "type": "", "provider": "", "label": "", "email": "",
+			"status": "", "status_message": "", "disabled": true, "unavailable": true, "runtime_only": true,
+			"source": "", "path": "", "size": int64(0),
+		}}}},
+		"GET /auth-files/models":          {Summary: "Models served by a credential", Tag: tagAuth, Query: []openapi.Param{{Name: "name", Required: true}}, Response: openapi.Fields{"models": []openapi.Fields{{"id": "", "display_name": "", "type": "", "owned_by": ""}}}},
+		"GET /model-definitions/:channel": {Summary: "Static model definitions for a channel", Tag: tagAuth, Response: openapi.Fields{"channel": "", "models": []*registry.ModelInfo{}}},
+		"GET /auth-files/download":        {Summary: "Download a credential file", Tag: tagAuth, Query: []openapi.Param{{Name: "name", Required: true}}, Response: openapi.Schema{"type": "string", "format": "binary"}, ResponseContent: openapi.ContentOctet},
+		"POST /auth-files": {
+			Summary:        "Upload a credential file as multipart field \"file\", or as a raw JSON body with ?name=",
+			Tag:            tagAuth,
+			Query:          []openapi.Param{{Name: "name", Description: "file name when posting a raw JSON body"}},
+			Request:        openapi.Schema{"type": "object", "properties": map[string]any{"file": map[string]any{"type": "string", "format": "binary"}}},
+			RequestContent: openapi.ContentMultipart,
+			Response:       statusResponse,
+		},
+		"DELETE /auth-files":       {Summary: "Delete a credential file, or all of them with all=true", Tag: tagAuth, Query: []openapi.Param{{Name: "name"}, {Name: "all", Type: "boolean"}}, Response: openapi.Fields{"status": "", "deleted": 0}},
+		"PATCH /auth-files/status": {Summary: "Enable or disable a credential", Tag: tagAuth, Request: openapi.Fields{"name": "", "disabled": true}, Response: openapi.Fields{"status": "", "disabled": true}},
+		"POST /vertex/import": {
+			Summary:        "Import a Vertex service account key",
+			Tag:            tagAuth,
+			Request:        openapi.Schema{"type": "object", "properties": map[string]any{"file": map[string]any{"type": "string", "format": "binary"}, "location": map[string]any{"type": "string"}}},
+			RequestContent: openapi.ContentMultipart,
+			Response:       openapi.Fields{"status": "", "auth-file": "", "project_id": "", "email": "", "location": ""},
+		},
+
+		"GET /anthropic-auth-url":   {Summary: "Start the Anthropic OAuth flow", Tag: tagOAuth, Query: []openapi.Param{webUIQuery}, Response: authURLResponse},
+		"GET /codex-auth-url":       {Summary: "Start the Codex OAuth flow", Tag: tagOAuth, Query: []openapi.Param{webUIQuery}, Response: authURLResponse},
+		"GET /gemini-cli-auth-url":  {Summary: "Start the Gemini CLI OAuth flow", Tag: tagOAuth, Query: []openapi.Param{webUIQuery, {Name: "project_id"}}, Response: authURLResponse},
+		"GET /antigravity-auth-url": {Summary: "Start the Antigravity OAuth flow", Tag: tagOAuth, Query: []openapi.Param{webUIQuery}, Response: authURLResponse},
+		"GET /qwen-auth-url":        {Summary: "Start the Qwen device flow", Tag: tagOAuth, Query: []openapi.Param{webUIQuery}, Response: authURLResponse},
+		"GET /iflow-auth-url":       {Summary: "Start the iFlow OAuth flow", Tag: tagOAuth, Query: []openapi.Param{webUIQuery}, Response: authURLResponse},
+		"POST /iflow-auth-url":      {Summary: "Authenticate iFlow with a browser cookie", Tag: tagOAuth, Request: openapi.Fields{"cookie": ""}, Response: openapi.Fields{"status": "", "saved_path": "", "email": "", "expired": "", "type": ""}},
+		"GET /kiro-auth-url":        {Summary: "Start the Kiro login flow", Tag: tagOAuth, Query: []openapi.Param{{Name: "method", Description: "device_code or a social provider"}}, Response: openapi.Fields{"status": "", "state": "", "method": ""}},
+		"GET /github-auth-url":      {Summary: "Start the GitHub Copilot device flow", Tag: tagOAuth, Response: openapi.Fields{"status": "", "url": "", "state": "", "user_code": "", "verification_uri": ""}},
+		"POST /oauth-callback":      {Summary: "Complete an OAuth flow with a pasted redirect", Tag: tagOAuth, Request: oauthCallbackRequest{}, Response: statusResponse},
+		"GET /get-auth-status":      {Summary: "Poll an OAuth flow", Tag: tagOAuth, Query: []openapi.Param{{Name: "state"}}, Response: openapi.Fields{"status": "", "error": "", "url": "", "verification_url": "", "user_code": ""}},
+		"GET /copilot/quota":        {Summary: "GitHub Copilot quota for a credential", Tag: tagAuth, Query: []openapi.Param{{Name: "auth_id", Required: true}}, Response: CopilotUsageResponse{}},
+		"GET /kiro/quota":           {Summary: "Kiro quota for a credential", Tag: tagAuth, Query: []openapi.Param{{Name: "auth_id", Required: true}}, Response: openapi.Schema{"type": "object"}},
+	}
+
+	scalarOps(ops, "/debug", "debug", "debug mode", true)
+	scalarOps(ops, "/logging-to-file", "logging-to-file", "file logging", true)
+	scalarOps(ops, "/logs-max-total-size-mb", "logs-max-total-size-mb", "maximum total log size in MB", 0)
+	scalarOps(ops, "/usage-statistics-enabled", "usage-statistics-enabled", "usage statistics collection", true)
+	scalarOps(ops, "/proxy-url", "proxy-url", "global proxy URL", "")
+	scalarOps(ops, "/quota-exceeded/switch-project", "switch-project", "project switching on quota errors", true)
+	scalarOps(ops, "/quota-exceeded/switch-preview-model", "switch-preview-model", "preview model switching on quota errors", true)
+	scalarOps(ops, "/request-log", "request-log", "request logging", true)
+	scalarOps(ops, "/ws-auth", "ws-auth", "websocket authentication", true)
+	scalarOps(ops, "/request-retry", "request-retry", "request retry count", 0)
+	scalarOps(ops, "/max-retry-interval", "max-retry-interval", "maximum retry interval in seconds", 0)
+	scalarOps(ops, "/force-model-prefix", "force-model-prefix", "forced model prefixes", true)
+	scalarOps(ops, "/ampcode/upstream-url", "upstream-url", "ampcode upstream URL", "")
+	scalarOps(ops, "/ampcode/upstream-api-key", "upstream-api-key", "ampcode upstream API key", "")
+	scalarOps(ops, "/ampcode/restrict-management-to-localhost", "restrict-management-to-localhost", "ampcode management localhost restriction", true)
+	scalarOps(ops, "/ampcode/force-model-mappings", "force-model-mappings", "forced ampcode model mappings", true)
+
+	listOps(ops, "/api-keys", "api-keys", "client API keys", []string{},
+		openapi.Fields{"old": "", "new": "", "index": 0, "value": ""},
+		indexQuery, openapi.Param{Name: "value", Description: "match the entry by value"})
+	listOps(ops, "/gemini-api-key", "gemini-api-key", "Gemini API keys", []config.GeminiKey{},
+		openapi.Fields{"index": 0, "match": "", "value": geminiKeyPatch{}}, apiKeyQuery, indexQuery)
+	listOps(ops, "/claude-api-key", "claude-api-key", "Claude API keys", []config.ClaudeKey{},
+		openapi.Fields{"index": 0, "match": "", "value": claudeKeyPatch{}}, apiKeyQuery, indexQuery)
+	listOps(ops, "/codex-api-key", "codex-api-key", "Codex API keys", []config.CodexKey{},
+		openapi.Fields{"index": 0, "match": "", "value": codexKeyPatch{}}, apiKeyQuery, indexQuery)
+	listOps(ops, "/vertex-api-key", "vertex-api-key", "Vertex-compatible API keys", []config.VertexCompatKey{},
+		openapi.Fields{"index": 0, "match": "", "value": vertexCompatPatch{}}, apiKeyQuery, indexQuery)
+	listOps(ops, "/openai-compatibility", "openai-compatibility", "OpenAI-compatible providers", []config.OpenAICompatibility{},
+		openapi.Fields{"name": "", "index": 0, "value": openAICompatPatch{}},
+		openapi.Param{Name: "name", Description: "match the provider by name"}, indexQuery)
+	return ops
+}
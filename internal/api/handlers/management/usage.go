@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/cache"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
 )
 
@@ -32,6 +33,11 @@ func (h *Handler) GetUsageStatistics(c *gin.Context) {
 	})
 }
 
+// GetSignatureCacheStatistics returns the thinking signature cache backend, size and hit rate.
+func (h *Handler) GetSignatureCacheStatistics(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"signature-cache": cache.SignatureCacheStatistics()})
+}
+
 // ExportUsageStatistics returns a complete usage snapshot for backup/migration.
 func (h *Handler) ExportUsageStatistics(c *gin.Context) {
 	var snapshot usage.StatisticsSnapshot
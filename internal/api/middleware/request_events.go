@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/events"
+)
+
+// RequestEventsMiddleware publishes a summary of each proxied request on the request
+// event topic. It does nothing unless a management event subscriber is listening.
+func RequestEventsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet || !shouldLogRequest(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		if !events.HasSubscribers(events.TopicRequest) {
+			return
+		}
+		summary := events.RequestSummary{
+			RequestID: logging.GetGinRequestID(c),
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			Status:    c.Writer.Status(),
+			LatencyMs: time.Since(start).Milliseconds(),
+		}
+		if usage, ok := logging.GetGinRequestUsage(c); ok {
+			summary.Model = usage.Model
+			summary.Provider = usage.Provider
+			summary.AuthID = usage.AuthID
+			summary.AuthIndex = usage.AuthIndex
+			summary.InputTokens = usage.InputTokens
+			summary.OutputTokens = usage.OutputTokens
+		}
+		events.Publish(events.TopicRequest, "completed", summary)
+	}
+}
@@ -7,10 +7,13 @@ import (
 	"bytes"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/tidwall/gjson"
 )
 
 // RequestInfo holds essential details of an incoming HTTP request for logging purposes.
@@ -20,6 +23,7 @@ type RequestInfo struct {
 	Headers   map[string][]string // Headers contains the request headers.
 	Body      []byte              // Body is the raw request body.
 	RequestID string              // RequestID is the unique identifier for the request.
+	StartedAt time.Time           // StartedAt is when the request entered the logging middleware.
 }
 
 // ResponseWriterWrapper wraps the standard gin.ResponseWriter to intercept and log response data.
@@ -289,15 +293,89 @@ func (w *ResponseWriterWrapper) Finalize(c *gin.Context) error {
 		if len(apiResponse) > 0 {
 			_ = w.streamWriter.WriteAPIResponse(apiResponse)
 		}
-		if err := w.streamWriter.Close(); err != nil {
-			w.streamWriter = nil
-			return err
-		}
+		errClose := w.streamWriter.Close()
 		w.streamWriter = nil
-		return nil
+		w.indexRequest(c, finalStatusCode)
+		return errClose
+	}
+
+	errLog := w.logRequest(finalStatusCode, w.cloneHeaders(), w.body.Bytes(), w.extractAPIRequest(c), w.extractAPIResponse(c), slicesAPIResponseError, forceLog)
+	w.indexRequest(c, finalStatusCode)
+	return errLog
+}
+
+// indexRequest appends a searchable summary of the logged request to the request log index
+// when the logger supports it.
+func (w *ResponseWriterWrapper) indexRequest(c *gin.Context, statusCode int) {
+	if w.requestInfo == nil || w.requestInfo.RequestID == "" {
+		return
+	}
+	indexer, ok := w.logger.(interface {
+		AppendIndexEntry(logging.RequestLogIndexEntry) error
+	})
+	if !ok {
+		return
 	}
 
-	return w.logRequest(finalStatusCode, w.cloneHeaders(), w.body.Bytes(), w.extractAPIRequest(c), w.extractAPIResponse(c), slicesAPIResponseError, forceLog)
+	entry := logging.RequestLogIndexEntry{
+		RequestID:      w.requestInfo.RequestID,
+		Timestamp:      w.requestInfo.StartedAt,
+		Method:         w.requestInfo.Method,
+		URL:            w.requestInfo.URL,
+		HandlerFormat:  logging.GetGinHandlerFormat(c),
+		RequestedModel: requestedModel(w.requestInfo),
+		Status:         statusCode,
+		Stream:         w.isStreaming,
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	} else {
+		entry.LatencyMs = time.Since(w.requestInfo.StartedAt).Milliseconds()
+	}
+	if apiKey, exists := c.Get("apiKey"); exists {
+		if key, okKey := apiKey.(string); okKey && key != "" {
+			entry.ClientKey = util.HideAPIKey(key)
+		}
+	}
+	if usage, exists := logging.GetGinRequestUsage(c); exists {
+		entry.Provider = usage.Provider
+		entry.UpstreamModel = usage.Model
+		entry.AuthID = usage.AuthID
+		entry.AuthIndex = usage.AuthIndex
+		entry.InputTokens = usage.InputTokens
+		entry.OutputTokens = usage.OutputTokens
+		entry.ReasoningTokens = usage.ReasoningTokens
+		entry.CachedTokens = usage.CachedTokens
+		entry.TotalTokens = usage.TotalTokens
+	}
+
+	_ = indexer.AppendIndexEntry(entry)
+}
+
+// requestedModel extracts the client-requested model from the JSON body or, for
+// Gemini-style routes, from the "/models/{model}:{action}" path segment.
+func requestedModel(info *RequestInfo) string {
+	if info == nil {
+		return ""
+	}
+	if len(info.Body) > 0 {
+		if model := gjson.GetBytes(info.Body, "model").String(); model != "" {
+			return model
+		}
+	}
+	path := info.URL
+	if idx := strings.Index(path, "?"); idx >= 0 {
+		path = path[:idx]
+	}
+	idx := strings.Index(path, "/models/")
+	if idx < 0 {
+		return ""
+	}
+	model := path[idx+len("/models/"):]
+	if colon := strings.Index(model, ":"); colon >= 0 {
+		model = model[:colon]
+	}
+	return model
 }
 
 func (w *ResponseWriterWrapper) cloneHeaders() map[string][]string {
@@ -0,0 +1,374 @@
+package ollama
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Ollama requests are converted to the OpenAI chat completions format and executed with
+// the "openai" source format, so the translator registry handles every provider. The
+// OpenAI responses are converted back into Ollama's JSON and NDJSON shapes here.
+
+// chatRequestToOpenAI converts an Ollama /api/chat body into an OpenAI chat completions
+// body. It reports whether the client asked for a streamed response.
+func chatRequestToOpenAI(raw []byte) ([]byte, bool, error) {
+	model, err := requestModel(raw)
+	if err != nil {
+		return nil, false, err
+	}
+	out := []byte(`{"messages":[]}`)
+	out, _ = sjson.SetBytes(out, "model", model)
+	toolCallIDs := toolCallIDQueue{}
+	for i, msg := range gjson.GetBytes(raw, "messages").Array() {
+		converted, errMsg := convertMessage(msg, i, &toolCallIDs)
+		if errMsg != nil {
+			return nil, false, errMsg
+		}
+		out, _ = sjson.SetRawBytes(out, "messages.-1", converted)
+	}
+	if tools := gjson.GetBytes(raw, "tools"); tools.IsArray() && len(tools.Array()) > 0 {
+		out, _ = sjson.SetRawBytes(out, "tools", []byte(tools.Raw))
+	}
+	return applyCommonOptions(out, raw)
+}
+
+// generateRequestToOpenAI converts an Ollama /api/generate body into an OpenAI chat
+// completions body with an optional system message and a single user message.
+func generateRequestToOpenAI(raw []byte) ([]byte, bool, error) {
+	model, err := requestModel(raw)
+	if err != nil {
+		return nil, false, err
+	}
+	out := []byte(`{"messages":[]}`)
+	out, _ = sjson.SetBytes(out, "model", model)
+	if system := gjson.GetBytes(raw, "system").String(); system != "" {
+		out, _ = sjson.SetRawBytes(out, "messages.-1", []byte(`{"role":"system","content":`+quote(system)+`}`))
+	}
+	user := []byte(`{"role":"user"}`)
+	content, err := messageContent(gjson.GetBytes(raw, "prompt").String(), gjson.GetBytes(raw, "images"))
+	if err != nil {
+		return nil, false, err
+	}
+	user, _ = sjson.SetRawBytes(user, "content", content)
+	out, _ = sjson.SetRawBytes(out, "messages.-1", user)
+	return applyCommonOptions(out, raw)
+}
+
+func requestModel(raw []byte) (string, error) {
+	if !gjson.ValidBytes(raw) {
+		return "", fmt.Errorf("invalid JSON body")
+	}
+	model := strings.TrimSpace(gjson.GetBytes(raw, "model").String())
+	if model == "" {
+		return "", fmt.Errorf("model is required")
+	}
+	return model, nil
+}
+
+// toolCallIDQueue assigns IDs to assistant tool calls, which Ollama does not carry, and
+// hands them out to the tool results that follow in the same order.
+type toolCallIDQueue struct {
+	pending []pendingToolCall
+}
+
+type pendingToolCall struct {
+	id   string
+	name string
+}
+
+func (q *toolCallIDQueue) push(id, name string) {
+	q.pending = append(q.pending, pendingToolCall{id: id, name: name})
+}
+
+// pop returns the ID of the oldest unanswered call, preferring one with a matching name.
+func (q *toolCallIDQueue) pop(name string) string {
+	idx := 0
+	if name != "" {
+		for i, call := range q.pending {
+			if call.name == name {
+				idx = i
+				break
+			}
+		}
+	}
+	if idx >= len(q.pending) {
+		return ""
+	}
+	id := q.pending[idx].id
+	q.pending = append(q.pending[:idx], q.pending[idx+1:]...)
+	return id
+}
+
+func convertMessage(msg gjson.Result, index int, ids *toolCallIDQueue) ([]byte, error) {
+	role := msg.Get("role").String()
+	out := []byte(`{}`)
+	out, _ = sjson.SetBytes(out, "role", role)
+	switch role {
+	case "tool":
+		id := ids.pop(msg.Get("tool_name").String())
+		if id == "" {
+			id = fmt.Sprintf("call_%d", index)
+		}
+		out, _ = sjson.SetBytes(out, "tool_call_id", id)
+		out, _ = sjson.SetBytes(out, "content", msg.Get("content").String())
+		return out, nil
+	case "assistant":
+		out, _ = sjson.SetBytes(out, "content", msg.Get("content").String())
+		for j, call := range msg.Get("tool_calls").Array() {
+			id := fmt.Sprintf("call_%d_%d", index, j)
+			name := call.Get("function.name").String()
+			args := call.Get("function.arguments")
+			arguments := args.Raw
+			if args.Type == gjson.String {
+				arguments = args.String()
+			} else if !args.Exists() {
+				arguments = "{}"
+			}
+			converted := []byte(`{"type":"function"}`)
+			converted, _ = sjson.SetBytes(converted, "id", id)
+			converted, _ = sjson.SetBytes(converted, "function.name", name)
+			converted, _ = sjson.SetBytes(converted, "function.arguments", arguments)
+			out, _ = sjson.SetRawBytes(out, "tool_calls.-1", converted)
+			ids.push(id, name)
+		}
+		return out, nil
+	}
+	content, err := messageContent(msg.Get("content").String(), msg.Get("images"))
+	if err != nil {
+		return nil, err
+	}
+	out, _ = sjson.SetRawBytes(out, "content", content)
+	return out, nil
+}
+
+// messageContent builds OpenAI message content, switching to content parts when the
+// message carries base64 images.
+func messageContent(text string, images gjson.Result) ([]byte, error) {
+	if !images.IsArray() || len(images.Array()) == 0 {
+		return []byte(quote(text)), nil
+	}
+	parts := []byte(`[]`)
+	if text != "" {
+		parts, _ = sjson.SetRawBytes(parts, "-1", []byte(`{"type":"text","text":`+quote(text)+`}`))
+	}
+	for _, img := range images.Array() {
+		data := img.String()
+		decoded, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, fmt.Errorf("images must be base64 encoded")
+		}
+		part := []byte(`{"type":"image_url"}`)
+		part, _ = sjson.SetBytes(part, "image_url.url", "data:"+http.DetectContentType(decoded)+";base64,"+data)
+		parts, _ = sjson.SetRawBytes(parts, "-1", part)
+	}
+	return parts, nil
+}
+
+// optionFields maps Ollama options to OpenAI request fields.
+var optionFields = [][2]string{
+	{"temperature", "temperature"},
+	{"top_p", "top_p"},
+	{"top_k", "top_k"},
+	{"seed", "seed"},
+	{"stop", "stop"},
+	{"num_predict", "max_tokens"},
+	{"presence_penalty", "presence_penalty"},
+	{"frequency_penalty", "frequency_penalty"},
+}
+
+// applyCommonOptions maps the stream flag, format, think and options fields shared by
+// /api/chat and /api/generate. Ollama streams unless stream is explicitly false.
+func applyCommonOptions(out, raw []byte) ([]byte, bool, error) {
+	stream := gjson.GetBytes(raw, "stream").Type != gjson.False
+	if stream {
+		out, _ = sjson.SetBytes(out, "stream", true)
+		out, _ = sjson.SetBytes(out, "stream_options.include_usage", true)
+	}
+
+	switch format := gjson.GetBytes(raw, "format"); {
+	case format.Type == gjson.String && format.String() == "json":
+		out, _ = sjson.SetBytes(out, "response_format.type", "json_object")
+	case format.IsObject():
+		out, _ = sjson.SetBytes(out, "response_format.type", "json_schema")
+		out, _ = sjson.SetBytes(out, "response_format.json_schema.name", "response")
+		out, _ = sjson.SetRawBytes(out, "response_format.json_schema.schema", []byte(format.Raw))
+	case format.Exists() && format.String() != "":
+		return nil, false, fmt.Errorf("format must be \"json\" or a JSON schema")
+	}
+
+	switch think := gjson.GetBytes(raw, "think"); think.Type {
+	case gjson.True:
+		out, _ = sjson.SetBytes(out, "reasoning_effort", "medium")
+	case gjson.False:
+		out, _ = sjson.SetBytes(out, "reasoning_effort", "none")
+	case gjson.String:
+		out, _ = sjson.SetBytes(out, "reasoning_effort", think.String())
+	}
+
+	options := gjson.GetBytes(raw, "options")
+	for _, field := range optionFields {
+		if v := options.Get(field[0]); v.Exists() {
+			if field[0] == "num_predict" && v.Int() <= 0 {
+				continue
+			}
+			out, _ = sjson.SetRawBytes(out, field[1], []byte(v.Raw))
+		}
+	}
+	return out, stream, nil
+}
+
+// responseKind selects between the /api/chat and /api/generate response shapes.
+type responseKind int
+
+const (
+	chatResponse responseKind = iota
+	generateResponse
+)
+
+// responseBuilder renders Ollama response objects for one request.
+type responseBuilder struct {
+	kind    responseKind
+	model   string
+	started time.Time
+}
+
+// message returns a non-final object carrying content, thinking and tool calls.
+func (b *responseBuilder) message(content, thinking string, toolCalls []byte) []byte {
+	out := []byte(`{}`)
+	out, _ = sjson.SetBytes(out, "model", b.model)
+	out, _ = sjson.SetBytes(out, "created_at", time.Now().UTC().Format(time.RFC3339Nano))
+	if b.kind == generateResponse {
+		out, _ = sjson.SetBytes(out, "response", content)
+		if thinking != "" {
+			out, _ = sjson.SetBytes(out, "thinking", thinking)
+		}
+	} else {
+		out, _ = sjson.SetBytes(out, "message.role", "assistant")
+		out, _ = sjson.SetBytes(out, "message.content", content)
+		if thinking != "" {
+			out, _ = sjson.SetBytes(out, "message.thinking", thinking)
+		}
+		if len(toolCalls) > 0 {
+			out, _ = sjson.SetRawBytes(out, "message.tool_calls", toolCalls)
+		}
+	}
+	out, _ = sjson.SetBytes(out, "done", false)
+	return out
+}
+
+// done marks obj as the final object and adds the finish reason and token counts.
+func (b *responseBuilder) done(obj []byte, finishReason string, usage gjson.Result) []byte {
+	reason := "stop"
+	if finishReason == "length" {
+		reason = "length"
+	}
+	elapsed := time.Since(b.started).Nanoseconds()
+	obj, _ = sjson.SetBytes(obj, "done", true)
+	obj, _ = sjson.SetBytes(obj, "done_reason", reason)
+	obj, _ = sjson.SetBytes(obj, "total_duration", elapsed)
+	obj, _ = sjson.SetBytes(obj, "load_duration", 0)
+	obj, _ = sjson.SetBytes(obj, "prompt_eval_count", usage.Get("prompt_tokens").Int())
+	obj, _ = sjson.SetBytes(obj, "prompt_eval_duration", 0)
+	obj, _ = sjson.SetBytes(obj, "eval_count", usage.Get("completion_tokens").Int())
+	obj, _ = sjson.SetBytes(obj, "eval_duration", elapsed)
+	return obj
+}
+
+// fromOpenAI converts a non-streaming OpenAI chat completion into the final Ollama object.
+func (b *responseBuilder) fromOpenAI(resp []byte) []byte {
+	choice := gjson.GetBytes(resp, "choices.0")
+	toolCalls := []byte(`[]`)
+	for _, call := range choice.Get("message.tool_calls").Array() {
+		toolCalls = appendToolCall(toolCalls, call.Get("function.name").String(), call.Get("function.arguments").String())
+	}
+	if len(gjson.ParseBytes(toolCalls).Array()) == 0 {
+		toolCalls = nil
+	}
+	obj := b.message(choice.Get("message.content").String(), choice.Get("message.reasoning_content").String(), toolCalls)
+	return b.done(obj, choice.Get("finish_reason").String(), gjson.GetBytes(resp, "usage"))
+}
+
+// appendToolCall adds an Ollama tool call, whose arguments are a JSON object rather than
+// the encoded string OpenAI uses.
+func appendToolCall(calls []byte, name, arguments string) []byte {
+	call := []byte(`{"function":{"arguments":{}}}`)
+	call, _ = sjson.SetBytes(call, "function.name", name)
+	if args := strings.TrimSpace(arguments); args != "" && gjson.Valid(args) {
+		call, _ = sjson.SetRawBytes(call, "function.arguments", []byte(args))
+	}
+	calls, _ = sjson.SetRawBytes(calls, "-1", call)
+	return calls
+}
+
+// streamConverter turns OpenAI chat completion chunks into Ollama NDJSON objects.
+// Tool call fragments are accumulated and emitted whole, as Ollama clients expect.
+type streamConverter struct {
+	*responseBuilder
+	finishReason string
+	usage        gjson.Result
+	toolCalls    []*streamToolCall
+}
+
+type streamToolCall struct {
+	name      string
+	arguments strings.Builder
+}
+
+// convert returns the Ollama objects for one OpenAI chunk. It never emits the final object.
+func (s *streamConverter) convert(chunk []byte) [][]byte {
+	chunk = []byte(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(string(chunk)), "data:")))
+	if len(chunk) == 0 || string(chunk) == "[DONE]" || !gjson.ValidBytes(chunk) {
+		return nil
+	}
+	if usage := gjson.GetBytes(chunk, "usage"); usage.IsObject() {
+		s.usage = usage
+	}
+	var out [][]byte
+	for _, choice := range gjson.GetBytes(chunk, "choices").Array() {
+		if reason := choice.Get("finish_reason").String(); reason != "" {
+			s.finishReason = reason
+		}
+		delta := choice.Get("delta")
+		for _, call := range delta.Get("tool_calls").Array() {
+			idx := int(call.Get("index").Int())
+			for len(s.toolCalls) <= idx {
+				s.toolCalls = append(s.toolCalls, &streamToolCall{})
+			}
+			if name := call.Get("function.name").String(); name != "" {
+				s.toolCalls[idx].name = name
+			}
+			s.toolCalls[idx].arguments.WriteString(call.Get("function.arguments").String())
+		}
+		content := delta.Get("content").String()
+		thinking := delta.Get("reasoning_content").String()
+		if content != "" || thinking != "" {
+			out = append(out, s.message(content, thinking, nil))
+		}
+	}
+	return out
+}
+
+// finish returns the remaining objects: accumulated tool calls, if any, and the final
+// object with the finish reason and token counts.
+func (s *streamConverter) finish() [][]byte {
+	var out [][]byte
+	if len(s.toolCalls) > 0 && s.kind == chatResponse {
+		calls := []byte(`[]`)
+		for _, call := range s.toolCalls {
+			calls = appendToolCall(calls, call.name, call.arguments.String())
+		}
+		out = append(out, s.message("", "", calls))
+	}
+	return append(out, s.done(s.message("", "", nil), s.finishReason, s.usage))
+}
+
+func quote(s string) string {
+	out, _ := sjson.Set(`{}`, "v", s)
+	return gjson.Get(out, "v").Raw
+}
@@ -0,0 +1,119 @@
+package ollama
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestChatRequestToOpenAI(t *testing.T) {
+	raw := []byte(`{
+		"model": "gpt-5",
+		"messages": [
+			{"role": "system", "content": "be brief"},
+			{"role": "user", "content": "what is this?", "images": ["iVBORw0KGgo="]},
+			{"role": "assistant", "content": "", "tool_calls": [{"function": {"name": "lookup", "arguments": {"q": "png"}}}]},
+			{"role": "tool", "tool_name": "lookup", "content": "an image format"}
+		],
+		"format": "json",
+		"think": true,
+		"options": {"temperature": 0.2, "num_predict": 64, "stop": ["\n"]}
+	}`)
+	out, stream, err := chatRequestToOpenAI(raw)
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if !stream || !gjson.GetBytes(out, "stream").Bool() || !gjson.GetBytes(out, "stream_options.include_usage").Bool() {
+		t.Fatalf("Ollama requests stream by default: %s", out)
+	}
+	checks := map[string]string{
+		"model":                                      "gpt-5",
+		"messages.0.content":                         "be brief",
+		"messages.1.content.0.text":                  "what is this?",
+		"messages.1.content.1.image_url.url":         "data:image/png;base64,iVBORw0KGgo=",
+		"messages.2.tool_calls.0.function.name":      "lookup",
+		"messages.2.tool_calls.0.function.arguments": `{"q": "png"}`,
+		"messages.3.tool_call_id":                    gjson.GetBytes(out, "messages.2.tool_calls.0.id").String(),
+		"response_format.type":                       "json_object",
+		"reasoning_effort":                           "medium",
+		"temperature":                                "0.2",
+		"max_tokens":                                 "64",
+		"stop.0":                                     "\n",
+	}
+	for path, want := range checks {
+		if got := gjson.GetBytes(out, path).String(); got != want {
+			t.Fatalf("%s = %q, want %q in %s", path, got, want, out)
+		}
+	}
+
+	if _, _, err = chatRequestToOpenAI([]byte(`{"messages":[]}`)); err == nil {
+		t.Fatal("expected an error without a model")
+	}
+}
+
+func TestGenerateRequestToOpenAI(t *testing.T) {
+	out, stream, err := generateRequestToOpenAI([]byte(`{"model":"m","system":"sys","prompt":"hi","stream":false,"format":{"type":"object"}}`))
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if stream || gjson.GetBytes(out, "stream").Exists() {
+		t.Fatalf("stream false must not stream: %s", out)
+	}
+	if gjson.GetBytes(out, "messages.0.role").String() != "system" || gjson.GetBytes(out, "messages.1.content").String() != "hi" {
+		t.Fatalf("unexpected messages: %s", out)
+	}
+	if gjson.GetBytes(out, "response_format.json_schema.schema.type").String() != "object" {
+		t.Fatalf("expected a json_schema response format: %s", out)
+	}
+}
+
+func TestResponseFromOpenAI(t *testing.T) {
+	b := &responseBuilder{kind: chatResponse, model: "m", started: time.Now()}
+	out := b.fromOpenAI([]byte(`{"choices":[{"message":{"role":"assistant","content":"","reasoning_content":"hmm","tool_calls":[{"id":"c1","type":"function","function":{"name":"lookup","arguments":"{\"q\":1}"}}]},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":3,"completion_tokens":4}}`))
+	if !gjson.GetBytes(out, "done").Bool() || gjson.GetBytes(out, "done_reason").String() != "stop" {
+		t.Fatalf("unexpected final object: %s", out)
+	}
+	if gjson.GetBytes(out, "message.thinking").String() != "hmm" || gjson.GetBytes(out, "message.tool_calls.0.function.arguments.q").Int() != 1 {
+		t.Fatalf("unexpected message: %s", out)
+	}
+	if gjson.GetBytes(out, "prompt_eval_count").Int() != 3 || gjson.GetBytes(out, "eval_count").Int() != 4 {
+		t.Fatalf("unexpected counts: %s", out)
+	}
+}
+
+func TestStreamConverter(t *testing.T) {
+	s := &streamConverter{responseBuilder: &responseBuilder{kind: chatResponse, model: "m", started: time.Now()}}
+	var lines [][]byte
+	for _, chunk := range []string{
+		`{"choices":[{"delta":{"role":"assistant","content":"Hel"}}]}`,
+		`{"choices":[{"delta":{"content":"lo"}}]}`,
+		`{"choices":[{"delta":{"tool_calls":[{"index":0,"id":"c1","function":{"name":"lookup","arguments":"{\"q\":"}}]}}]}`,
+		`{"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"2}"}}]}}]}`,
+		`{"choices":[{"delta":{},"finish_reason":"length"}]}`,
+		`{"choices":[],"usage":{"prompt_tokens":5,"completion_tokens":7}}`,
+		`[DONE]`,
+	} {
+		lines = append(lines, s.convert([]byte(chunk))...)
+	}
+	lines = append(lines, s.finish()...)
+
+	var text strings.Builder
+	for _, line := range lines[:len(lines)-1] {
+		if gjson.GetBytes(line, "done").Bool() {
+			t.Fatalf("only the last line may be done: %s", line)
+		}
+		text.WriteString(gjson.GetBytes(line, "message.content").String())
+	}
+	if text.String() != "Hello" {
+		t.Fatalf("content = %q", text.String())
+	}
+	if got := gjson.GetBytes(lines[len(lines)-2], "message.tool_calls.0.function.arguments.q").Int(); got != 2 {
+		t.Fatalf("tool call not reassembled: %s", lines[len(lines)-2])
+	}
+	last := lines[len(lines)-1]
+	if !gjson.GetBytes(last, "done").Bool() || gjson.GetBytes(last, "done_reason").String() != "length" || gjson.GetBytes(last, "eval_count").Int() != 7 {
+		t.Fatalf("unexpected final line: %s", last)
+	}
+}
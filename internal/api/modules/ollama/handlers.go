@@ -0,0 +1,297 @@
+package ollama
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	"github.com/tidwall/gjson"
+)
+
+// ollamaVersion is reported by /api/version. Clients gate features on it, so it tracks a
+// recent Ollama release rather than the proxy version.
+const ollamaVersion = "0.12.0"
+
+// handler serves the Ollama endpoints through the shared base handler.
+type handler struct {
+	*handlers.BaseAPIHandler
+}
+
+func newHandler(base *handlers.BaseAPIHandler) *handler {
+	return &handler{BaseAPIHandler: base}
+}
+
+// HandlerType returns the identifier for this handler implementation.
+func (h *handler) HandlerType() string {
+	return "ollama"
+}
+
+// Models returns the OpenAI-compatible model metadata supported by this handler.
+func (h *handler) Models() []map[string]any {
+	return registry.GetGlobalRegistry().GetAvailableModels("openai")
+}
+
+// Version handles GET /api/version.
+func (h *handler) Version(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"version": ollamaVersion})
+}
+
+// Tags handles GET /api/tags, listing every available model as a local model.
+func (h *handler) Tags(c *gin.Context) {
+	available := registry.GetGlobalRegistry().GetAvailableModels("openai")
+	models := make([]gin.H, 0, len(available))
+	for _, m := range available {
+		id, _ := m["id"].(string)
+		if id == "" {
+			continue
+		}
+		ownedBy, _ := m["owned_by"].(string)
+		created, _ := m["created"].(int64)
+		models = append(models, gin.H{
+			"name":        id,
+			"model":       id,
+			"modified_at": modifiedAt(created),
+			"size":        0,
+			"digest":      digest(id),
+			"details":     modelDetails(ownedBy),
+		})
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i]["name"].(string) < models[j]["name"].(string) })
+	c.JSON(http.StatusOK, gin.H{"models": models})
+}
+
+// Show handles POST /api/show for a model named by "model" or the legacy "name" field.
+func (h *handler) Show(c *gin.Context) {
+	raw, err := c.GetRawData()
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	name := strings.TrimSpace(gjson.GetBytes(raw, "model").String())
+	if name == "" {
+		name = strings.TrimSpace(gjson.GetBytes(raw, "name").String())
+	}
+	if name == "" {
+		writeError(c, http.StatusBadRequest, "model is required")
+		return
+	}
+	info := registry.GetGlobalRegistry().GetModelInfo(name, "")
+	if info == nil {
+		writeError(c, http.StatusNotFound, "model '"+name+"' not found")
+		return
+	}
+
+	family := info.Type
+	if family == "" {
+		family = info.OwnedBy
+	}
+	modelInfo := gin.H{"general.architecture": family, "general.basename": info.ID}
+	contextLength := info.ContextLength
+	if contextLength == 0 {
+		contextLength = info.InputTokenLimit
+	}
+	if contextLength > 0 {
+		modelInfo[family+".context_length"] = contextLength
+	}
+	capabilities := []string{"completion", "tools"}
+	if info.IsEmbedding() {
+		capabilities = []string{"embedding"}
+	} else if info.Thinking != nil {
+		capabilities = append(capabilities, "thinking")
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"modelfile":    "FROM " + info.ID + "\n",
+		"parameters":   "",
+		"template":     "{{ .Prompt }}",
+		"details":      modelDetails(family),
+		"model_info":   modelInfo,
+		"capabilities": capabilities,
+		"modified_at":  modifiedAt(info.Created),
+	})
+}
+
+// Chat handles POST /api/chat.
+func (h *handler) Chat(c *gin.Context) {
+	raw, err := c.GetRawData()
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.execute(c, raw, chatResponse, chatRequestToOpenAI)
+}
+
+// Generate handles POST /api/generate. A request without a prompt only loads the model in
+// Ollama, so it is answered immediately.
+func (h *handler) Generate(c *gin.Context) {
+	raw, err := c.GetRawData()
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if gjson.ValidBytes(raw) && !gjson.GetBytes(raw, "prompt").Exists() && !gjson.GetBytes(raw, "images").Exists() {
+		c.JSON(http.StatusOK, gin.H{
+			"model":       gjson.GetBytes(raw, "model").String(),
+			"created_at":  time.Now().UTC().Format(time.RFC3339Nano),
+			"response":    "",
+			"done":        true,
+			"done_reason": "load",
+		})
+		return
+	}
+	h.execute(c, raw, generateResponse, generateRequestToOpenAI)
+}
+
+// execute converts an Ollama request to OpenAI chat format and runs it through the auth
+// manager, answering in the Ollama shape selected by kind.
+func (h *handler) execute(c *gin.Context, raw []byte, kind responseKind, convert func([]byte) ([]byte, bool, error)) {
+	openAIJSON, stream, err := convert(raw)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	builder := &responseBuilder{kind: kind, model: gjson.GetBytes(openAIJSON, "model").String(), started: time.Now()}
+	if stream {
+		h.stream(c, openAIJSON, builder)
+		return
+	}
+
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
+	resp, errMsg := h.ExecuteWithAuthManager(cliCtx, constant.OpenAI, builder.model, openAIJSON, "")
+	if errMsg != nil {
+		writeErrorMessage(c, errMsg)
+		cliCancel(errMsg.Error)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", builder.fromOpenAI(resp))
+	cliCancel()
+}
+
+// stream forwards the upstream OpenAI stream as Ollama NDJSON. Errors before the first
+// chunk are returned with their HTTP status; later errors become an {"error": ...} line.
+func (h *handler) stream(c *gin.Context, openAIJSON []byte, builder *responseBuilder) {
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		writeError(c, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
+	dataChan, errChan := h.ExecuteStreamWithAuthManager(cliCtx, constant.OpenAI, builder.model, openAIJSON, "")
+	converter := &streamConverter{responseBuilder: builder}
+	writeLines := func(lines [][]byte) {
+		for _, line := range lines {
+			_, _ = c.Writer.Write(append(line, '\n'))
+		}
+	}
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			cliCancel(c.Request.Context().Err())
+			return
+		case errMsg, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
+			}
+			writeErrorMessage(c, errMsg)
+			if errMsg != nil {
+				cliCancel(errMsg.Error)
+			} else {
+				cliCancel(nil)
+			}
+			return
+		case chunk, ok := <-dataChan:
+			c.Header("Content-Type", "application/x-ndjson")
+			if !ok {
+				writeLines(converter.finish())
+				flusher.Flush()
+				cliCancel(nil)
+				return
+			}
+			writeLines(converter.convert(chunk))
+			flusher.Flush()
+
+			noKeepAlive := time.Duration(0)
+			h.ForwardStream(c, flusher, func(err error) { cliCancel(err) }, dataChan, errChan, handlers.StreamForwardOptions{
+				KeepAliveInterval: &noKeepAlive,
+				WriteChunk: func(chunk []byte) {
+					writeLines(converter.convert(chunk))
+				},
+				WriteTerminalError: func(errMsg *interfaces.ErrorMessage) {
+					if errMsg != nil {
+						writeLines([][]byte{errorBody(errorText(errMsg))})
+					}
+				},
+				WriteDone: func() {
+					writeLines(converter.finish())
+				},
+			})
+			return
+		}
+	}
+}
+
+func modelDetails(family string) gin.H {
+	families := []string{}
+	if family != "" {
+		families = append(families, family)
+	}
+	return gin.H{
+		"parent_model":       "",
+		"format":             "",
+		"family":             family,
+		"families":           families,
+		"parameter_size":     "",
+		"quantization_level": "",
+	}
+}
+
+func modifiedAt(created int64) string {
+	if created <= 0 {
+		return time.Unix(0, 0).UTC().Format(time.RFC3339)
+	}
+	return time.Unix(created, 0).UTC().Format(time.RFC3339)
+}
+
+// digest derives a stable placeholder digest from the model ID.
+func digest(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+func errorText(msg *interfaces.ErrorMessage) string {
+	text := http.StatusText(msg.StatusCode)
+	if msg.Error != nil && msg.Error.Error() != "" {
+		text = msg.Error.Error()
+	}
+	return text
+}
+
+func errorBody(text string) []byte {
+	return []byte(`{"error":` + quote(text) + `}`)
+}
+
+func writeError(c *gin.Context, status int, text string) {
+	c.Data(status, "application/json; charset=utf-8", errorBody(text))
+}
+
+func writeErrorMessage(c *gin.Context, msg *interfaces.ErrorMessage) {
+	status := http.StatusInternalServerError
+	if msg != nil && msg.StatusCode > 0 {
+		status = msg.StatusCode
+	}
+	text := http.StatusText(status)
+	if msg != nil {
+		text = errorText(msg)
+	}
+	writeError(c, status, text)
+}
@@ -0,0 +1,96 @@
+// Package ollama implements an optional routing module that exposes the Ollama HTTP API
+// (/api/chat, /api/generate, /api/tags, /api/show and /api/version) on top of the
+// proxy's providers, for local tools that only speak the Ollama protocol.
+package ollama
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/api/modules"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Option configures the OllamaModule.
+type Option func(*OllamaModule)
+
+// OllamaModule implements the RouteModuleV2 interface for the Ollama-compatible API.
+// Routes are always registered so the module can be toggled by hot reload; requests
+// are rejected with 404 while it is disabled.
+type OllamaModule struct {
+	authMiddleware gin.HandlerFunc
+	enabled        atomic.Bool
+	registerOnce   sync.Once
+}
+
+// New creates a new Ollama routing module with the given options.
+func New(opts ...Option) *OllamaModule {
+	m := &OllamaModule{}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// WithAuthMiddleware sets the authentication middleware for the Ollama routes.
+func WithAuthMiddleware(middleware gin.HandlerFunc) Option {
+	return func(m *OllamaModule) {
+		m.authMiddleware = middleware
+	}
+}
+
+// Name returns the module identifier.
+func (m *OllamaModule) Name() string {
+	return "ollama"
+}
+
+// Register sets up the Ollama routes. Routes are registered only once.
+func (m *OllamaModule) Register(ctx modules.Context) error {
+	m.enabled.Store(ctx.Config != nil && ctx.Config.Ollama.Enable)
+	m.registerOnce.Do(func() {
+		auth := m.authMiddleware
+		if auth == nil {
+			auth = ctx.AuthMiddleware
+		}
+		h := newHandler(ctx.BaseHandler)
+		group := ctx.Engine.Group("/api", m.availabilityMiddleware())
+		if auth != nil {
+			group.Use(auth)
+		} else {
+			log.Warn("ollama module: no auth middleware provided, allowing all requests")
+		}
+		group.GET("/version", h.Version)
+		group.GET("/tags", h.Tags)
+		group.POST("/show", h.Show)
+		group.POST("/chat", h.Chat)
+		group.POST("/generate", h.Generate)
+	})
+	return nil
+}
+
+// OnConfigUpdated enables or disables the routes according to the new configuration.
+func (m *OllamaModule) OnConfigUpdated(cfg *config.Config) error {
+	enabled := cfg != nil && cfg.Ollama.Enable
+	if m.enabled.Swap(enabled) != enabled {
+		if enabled {
+			log.Info("ollama-compatible API enabled")
+		} else {
+			log.Info("ollama-compatible API disabled")
+		}
+	}
+	return nil
+}
+
+// availabilityMiddleware hides the routes while the module is disabled.
+func (m *OllamaModule) availabilityMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !m.enabled.Load() {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "ollama API is disabled"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,84 @@
+package ollama
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/api/modules"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestOllamaRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registry.GetGlobalRegistry().RegisterClient("ollama-test", "openai", []*registry.ModelInfo{{ID: "ollama-test-model", OwnedBy: "acme", Type: "openai", ContextLength: 8192}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient("ollama-test") })
+
+	engine := gin.New()
+	auth := func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "Bearer key" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Next()
+	}
+	m := New(WithAuthMiddleware(auth))
+	cfg := &config.Config{}
+	if err := modules.RegisterModule(modules.Context{
+		Engine:      engine,
+		BaseHandler: handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, nil),
+		Config:      cfg,
+	}, m); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer key")
+		rr := httptest.NewRecorder()
+		engine.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := do(http.MethodGet, "/api/version", ""); rr.Code != http.StatusNotFound {
+		t.Fatalf("disabled module should answer 404, got %d", rr.Code)
+	}
+	cfg.Ollama.Enable = true
+	if err := m.OnConfigUpdated(cfg); err != nil {
+		t.Fatalf("config update: %v", err)
+	}
+
+	if rr := do(http.MethodGet, "/api/version", ""); rr.Code != http.StatusOK || gjson.Get(rr.Body.String(), "version").String() == "" {
+		t.Fatalf("version: %d %s", rr.Code, rr.Body.String())
+	}
+	unauthorized := httptest.NewRecorder()
+	engine.ServeHTTP(unauthorized, httptest.NewRequest(http.MethodGet, "/api/tags", nil))
+	if unauthorized.Code != http.StatusUnauthorized {
+		t.Fatalf("expected the auth middleware to reject the request, got %d", unauthorized.Code)
+	}
+
+	tags := do(http.MethodGet, "/api/tags", "").Body.String()
+	if !gjson.Get(tags, `models.#(name=="ollama-test-model")`).Exists() {
+		t.Fatalf("model missing from tags: %s", tags)
+	}
+
+	rr := do(http.MethodPost, "/api/show", `{"model":"ollama-test-model"}`)
+	if rr.Code != http.StatusOK || gjson.Get(rr.Body.String(), "model_info.openai\\.context_length").Int() != 8192 {
+		t.Fatalf("show: %d %s", rr.Code, rr.Body.String())
+	}
+	if rr = do(http.MethodPost, "/api/show", `{"model":"missing"}`); rr.Code != http.StatusNotFound || gjson.Get(rr.Body.String(), "error").String() == "" {
+		t.Fatalf("expected Ollama 404 error, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	if rr = do(http.MethodPost, "/api/chat", `{"messages":[]}`); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without a model, got %d", rr.Code)
+	}
+	if rr = do(http.MethodPost, "/api/generate", `{"model":"ollama-test-model"}`); gjson.Get(rr.Body.String(), "done_reason").String() != "load" {
+		t.Fatalf("generate without prompt should load: %s", rr.Body.String())
+	}
+}
@@ -0,0 +1,289 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	managementHandlers "github.com/router-for-me/CLIProxyAPI/v6/internal/api/handlers/management"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/api/openapi"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/buildinfo"
+)
+
+const (
+	openAPIPrefixRoot       = ""
+	openAPIPrefixV1         = "/v1"
+	openAPIPrefixV1Beta     = "/v1beta"
+	openAPIPrefixOllama     = "/api"
+	openAPIPrefixManagement = "/v0/management"
+)
+
+// openAPIUndocumented lists routes deliberately left out of the document: the management
+// control panel, OAuth callback pages, the Claude Code telemetry sink and the Amp CLI
+// pass-through routes, which mirror the upstream Amp service.
+var openAPIUndocumented = []string{
+	"/management.html",
+	"/anthropic/callback",
+	"/antigravity/callback",
+	"/codex/callback",
+	"/google/callback",
+	"/iflow/callback",
+	"/kiro/callback",
+	"/v0/oauth/kiro",
+	"/api/event_logging/batch",
+	// Amp CLI proxy
+	"/api/provider",
+	"/api/internal",
+	"/api/user",
+	"/api/auth",
+	"/api/meta",
+	"/api/ads",
+	"/api/telemetry",
+	"/api/threads",
+	"/api/otel",
+	"/api/tab",
+	"/auth",
+	"/threads",
+	"/threads.rss",
+	"/news.rss",
+	"/docs",
+	"/settings",
+}
+
+// Provider payloads are passed through to translators as raw JSON, so the public API
+// documents only the fields the proxy itself inspects.
+var (
+	modelRequestBody = openapi.Schema{
+		"type":                 "object",
+		"required":             []string{"model"},
+		"properties":           map[string]any{"model": map[string]any{"type": "string"}, "stream": map[string]any{"type": "boolean"}},
+		"additionalProperties": true,
+	}
+	embeddingRequestBody = openapi.Schema{
+		"type":     "object",
+		"required": []string{"model", "input"},
+		"properties": map[string]any{
+			"model":           map[string]any{"type": "string"},
+			"input":           map[string]any{"oneOf": []any{map[string]any{"type": "string"}, map[string]any{"type": "array", "items": map[string]any{"type": "string"}}}},
+			"dimensions":      map[string]any{"type": "integer"},
+			"encoding_format": map[string]any{"type": "string", "enum": []string{"float", "base64"}},
+		},
+		"additionalProperties": true,
+	}
+	embeddingResponse = openapi.Fields{
+		"object": "",
+		"model":  "",
+		"data":   []openapi.Fields{{"object": "", "index": 0, "embedding": []float64{}}},
+		"usage":  openapi.Fields{"prompt_tokens": int64(0), "total_tokens": int64(0)},
+	}
+	imageGenerationBody = openapi.Schema{
+		"type":     "object",
+		"required": []string{"model", "prompt"},
+		"properties": map[string]any{
+			"model":           map[string]any{"type": "string"},
+			"prompt":          map[string]any{"type": "string"},
+			"n":               map[string]any{"type": "integer", "minimum": 1, "maximum": 10},
+			"size":            map[string]any{"type": "string", "description": "WIDTHxHEIGHT, an aspect ratio such as 16:9, or auto"},
+			"response_format": map[string]any{"type": "string", "enum": []string{"b64_json", "url"}},
+		},
+		"additionalProperties": true,
+	}
+	imageEditBody = openapi.Schema{
+		"type":     "object",
+		"required": []string{"model", "prompt", "image"},
+		"properties": map[string]any{
+			"model":           map[string]any{"type": "string"},
+			"prompt":          map[string]any{"type": "string"},
+			"image":           map[string]any{"type": "string", "format": "binary"},
+			"mask":            map[string]any{"type": "string", "format": "binary"},
+			"n":               map[string]any{"type": "integer", "minimum": 1, "maximum": 10},
+			"size":            map[string]any{"type": "string"},
+			"response_format": map[string]any{"type": "string", "enum": []string{"b64_json", "url"}},
+		},
+	}
+	imageResponse = openapi.Fields{
+		"created": int64(0),
+		"data":    []openapi.Fields{{"b64_json": "", "url": "", "revised_prompt": ""}},
+		"usage":   openapi.Fields{"input_tokens": int64(0), "output_tokens": int64(0), "total_tokens": int64(0)},
+	}
+	inputItemsQuery = []openapi.Param{
+		{Name: "order", Description: "asc or desc (default desc)"},
+		{Name: "limit", Description: "Page size between 1 and 100 (default 20)", Type: "integer"},
+		{Name: "after", Description: "Return items after this item id"},
+	}
+	inputItemsResponse = openapi.Fields{"object": "", "data": []openapi.Fields{{"id": "", "type": ""}}, "first_id": "", "last_id": "", "has_more": false}
+	fileObject         = openapi.Fields{"id": "", "object": "", "bytes": int64(0), "created_at": int64(0), "filename": "", "purpose": ""}
+	fileUploadBody     = openapi.Schema{
+		"type":     "object",
+		"required": []string{"file", "purpose"},
+		"properties": map[string]any{
+			"file":    map[string]any{"type": "string", "format": "binary"},
+			"purpose": map[string]any{"type": "string", "enum": []string{"batch"}},
+		},
+	}
+	fileListQuery = []openapi.Param{
+		{Name: "purpose", Description: "Only return files with this purpose"},
+		{Name: "limit", Description: "Maximum number of files to return", Type: "integer"},
+		{Name: "after", Description: "Return files after this file id"},
+	}
+	batchObject = openapi.Fields{
+		"id": "", "object": "", "endpoint": "", "input_file_id": "", "completion_window": "", "status": "",
+		"output_file_id": "", "error_file_id": "", "created_at": int64(0), "expires_at": int64(0),
+		"request_counts": openapi.Fields{"total": int64(0), "completed": int64(0), "failed": int64(0)},
+		"metadata":       openapi.Schema{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+	}
+	batchCreateBody = openapi.Schema{
+		"type":     "object",
+		"required": []string{"input_file_id", "endpoint", "completion_window"},
+		"properties": map[string]any{
+			"input_file_id":     map[string]any{"type": "string"},
+			"endpoint":          map[string]any{"type": "string", "enum": []string{"/v1/chat/completions", "/v1/responses", "/v1/embeddings"}},
+			"completion_window": map[string]any{"type": "string", "enum": []string{"24h"}},
+			"metadata":          map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+		},
+	}
+	batchListQuery = []openapi.Param{
+		{Name: "limit", Description: "Page size between 1 and 100 (default 20)", Type: "integer"},
+		{Name: "after", Description: "Return batches after this batch id"},
+	}
+	messageBatchObject = openapi.Fields{
+		"id": "", "type": "", "processing_status": "", "created_at": "", "expires_at": "", "ended_at": "",
+		"cancel_initiated_at": "", "archived_at": "", "results_url": "",
+		"request_counts": openapi.Fields{"processing": int64(0), "succeeded": int64(0), "errored": int64(0), "canceled": int64(0), "expired": int64(0)},
+	}
+	messageBatchCreateBody = openapi.Schema{
+		"type":     "object",
+		"required": []string{"requests"},
+		"properties": map[string]any{
+			"requests": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type":     "object",
+					"required": []string{"custom_id", "params"},
+					"properties": map[string]any{
+						"custom_id": map[string]any{"type": "string"},
+						"params":    map[string]any{"type": "object", "additionalProperties": true},
+					},
+				},
+			},
+		},
+	}
+	messageBatchListQuery = []openapi.Param{
+		{Name: "limit", Description: "Page size between 1 and 1000 (default 20)", Type: "integer"},
+		{Name: "after_id", Description: "Return the page after this batch id"},
+		{Name: "before_id", Description: "Return the page before this batch id"},
+	}
+	geminiRequestBody = openapi.Schema{"type": "object", "additionalProperties": true}
+	modelListResponse = openapi.Fields{"object": "", "data": []openapi.Fields{{"id": "", "object": "", "created": int64(0), "owned_by": ""}}}
+	modelResponse     = openapi.Fields{"id": "", "object": "", "created": int64(0), "owned_by": ""}
+	modelQuery        = []openapi.Param{
+		{Name: "extended", Description: "Include full model metadata and a capabilities object", Type: "boolean"},
+	}
+	ollamaChatBody = openapi.Schema{
+		"type":     "object",
+		"required": []string{"model", "messages"},
+		"properties": map[string]any{
+			"model":    map[string]any{"type": "string"},
+			"messages": map[string]any{"type": "array", "items": map[string]any{"type": "object", "additionalProperties": true}},
+			"tools":    map[string]any{"type": "array", "items": map[string]any{"type": "object", "additionalProperties": true}},
+			"format":   map[string]any{"description": "\"json\" or a JSON schema"},
+			"options":  map[string]any{"type": "object", "additionalProperties": true},
+			"think":    map[string]any{"description": "Boolean or one of low, medium, high"},
+			"stream":   map[string]any{"type": "boolean", "default": true},
+		},
+		"additionalProperties": true,
+	}
+	ollamaGenerateBody = openapi.Schema{
+		"type":     "object",
+		"required": []string{"model"},
+		"properties": map[string]any{
+			"model":   map[string]any{"type": "string"},
+			"prompt":  map[string]any{"type": "string"},
+			"system":  map[string]any{"type": "string"},
+			"images":  map[string]any{"type": "array", "items": map[string]any{"type": "string", "format": "byte"}},
+			"format":  map[string]any{"description": "\"json\" or a JSON schema"},
+			"options": map[string]any{"type": "object", "additionalProperties": true},
+			"stream":  map[string]any{"type": "boolean", "default": true},
+		},
+		"additionalProperties": true,
+	}
+	ollamaDetails      = openapi.Fields{"parent_model": "", "format": "", "family": "", "families": []string{}, "parameter_size": "", "quantization_level": ""}
+	ollamaChatResponse = openapi.Fields{
+		"model": "", "created_at": "", "message": openapi.Fields{"role": "", "content": "", "thinking": ""},
+		"done": true, "done_reason": "", "prompt_eval_count": int64(0), "eval_count": int64(0),
+	}
+	ollamaGenerateResponse = openapi.Fields{
+		"model": "", "created_at": "", "response": "", "thinking": "",
+		"done": true, "done_reason": "", "prompt_eval_count": int64(0), "eval_count": int64(0),
+	}
+)
+
+func publicOpenAPIOperations() map[string]map[string]openapi.Operation {
+	return map[string]map[string]openapi.Operation{
+		openAPIPrefixV1: {
+			"GET /models":                       {Summary: "List models (OpenAI format, or Claude format for Claude clients)", Tag: "openai", Query: modelQuery, Response: modelListResponse},
+			"GET /models/*model":                {Summary: "Retrieve a model (OpenAI format, or Claude format for Claude clients)", Tag: "openai", Query: modelQuery, Response: modelResponse},
+			"POST /chat/completions":            {Summary: "OpenAI chat completions", Tag: "openai", Request: modelRequestBody},
+			"POST /completions":                 {Summary: "OpenAI legacy completions", Tag: "openai", Request: modelRequestBody},
+			"POST /responses":                   {Summary: "OpenAI Responses API", Tag: "openai", Request: modelRequestBody},
+			"GET /responses/:id":                {Summary: "Retrieve a stored response", Tag: "openai"},
+			"DELETE /responses/:id":             {Summary: "Delete a stored response", Tag: "openai", Response: openapi.Fields{"id": "", "object": "", "deleted": true}},
+			"GET /responses/:id/input_items":    {Summary: "List the input items of a stored response", Tag: "openai", Query: inputItemsQuery, Response: inputItemsResponse},
+			"POST /embeddings":                  {Summary: "OpenAI embeddings for embedding models", Tag: "openai", Request: embeddingRequestBody, Response: embeddingResponse},
+			"POST /images/generations":          {Summary: "OpenAI image generation through Gemini image models", Tag: "openai", Request: imageGenerationBody, Response: imageResponse},
+			"POST /images/edits":                {Summary: "OpenAI image edits through Gemini image models", Tag: "openai", Request: imageEditBody, RequestContent: "multipart/form-data", Response: imageResponse},
+			"GET /images/files/:id":             {Summary: "Download an image generated with response_format url", Tag: "openai", ResponseContent: "image/png"},
+			"POST /files":                       {Summary: "Upload a JSONL batch input file", Tag: "batch", Request: fileUploadBody, RequestContent: "multipart/form-data", Response: fileObject},
+			"GET /files":                        {Summary: "List uploaded and generated files", Tag: "batch", Query: fileListQuery, Response: openapi.Fields{"object": "", "data": []openapi.Fields{fileObject}, "has_more": false}},
+			"GET /files/:id":                    {Summary: "Retrieve file metadata", Tag: "batch", Response: fileObject},
+			"DELETE /files/:id":                 {Summary: "Delete a file", Tag: "batch", Response: openapi.Fields{"id": "", "object": "", "deleted": true}},
+			"GET /files/:id/content":            {Summary: "Download file content", Tag: "batch", ResponseContent: "application/octet-stream"},
+			"POST /batches":                     {Summary: "Create a batch executed locally in the background", Tag: "batch", Request: batchCreateBody, Response: batchObject},
+			"GET /batches":                      {Summary: "List batches", Tag: "batch", Query: batchListQuery, Response: openapi.Fields{"object": "", "data": []openapi.Fields{batchObject}, "first_id": "", "last_id": "", "has_more": false}},
+			"GET /batches/:id":                  {Summary: "Retrieve a batch", Tag: "batch", Response: batchObject},
+			"POST /batches/:id/cancel":          {Summary: "Cancel a batch", Tag: "batch", Response: batchObject},
+			"POST /messages":                    {Summary: "Anthropic messages", Tag: "claude", Request: modelRequestBody},
+			"POST /messages/batches":            {Summary: "Create an Anthropic message batch executed locally in the background", Tag: "claude", Request: messageBatchCreateBody, Response: messageBatchObject},
+			"GET /messages/batches":             {Summary: "List message batches", Tag: "claude", Query: messageBatchListQuery, Response: openapi.Fields{"data": []openapi.Fields{messageBatchObject}, "has_more": false, "first_id": "", "last_id": ""}},
+			"GET /messages/batches/:id":         {Summary: "Retrieve a message batch", Tag: "claude", Response: messageBatchObject},
+			"POST /messages/batches/:id/cancel": {Summary: "Cancel a message batch", Tag: "claude", Response: messageBatchObject},
+			"GET /messages/batches/:id/results": {Summary: "Download message batch results as JSONL", Tag: "claude", ResponseContent: "application/x-jsonl"},
+			"POST /messages/count_tokens":       {Summary: "Anthropic token counting", Tag: "claude", Request: modelRequestBody, Response: openapi.Fields{"input_tokens": int64(0)}},
+		},
+		openAPIPrefixRoot: {
+			"GET /":                   {Summary: "Server banner listing the main endpoints", Tag: "server", Response: openapi.Fields{"message": "", "endpoints": []string{}}},
+			"POST /v1internal:method": {Summary: "Gemini CLI internal API (generateContent, streamGenerateContent, countTokens)", Tag: "gemini", Request: geminiRequestBody},
+		},
+		openAPIPrefixOllama: {
+			"GET /version":   {Summary: "Ollama server version (when ollama.enable is set)", Tag: "ollama", Response: openapi.Fields{"version": ""}},
+			"GET /tags":      {Summary: "List available models as Ollama local models", Tag: "ollama", Response: openapi.Fields{"models": []openapi.Fields{{"name": "", "model": "", "modified_at": "", "size": int64(0), "digest": "", "details": ollamaDetails}}}},
+			"POST /show":     {Summary: "Show Ollama model information", Tag: "ollama", Request: openapi.Fields{"model": ""}, Response: openapi.Fields{"modelfile": "", "parameters": "", "template": "", "details": ollamaDetails, "model_info": openapi.Fields{}, "capabilities": []string{}, "modified_at": ""}},
+			"POST /chat":     {Summary: "Ollama chat; streams NDJSON unless stream is false", Tag: "ollama", Request: ollamaChatBody, Response: ollamaChatResponse},
+			"POST /generate": {Summary: "Ollama text generation; streams NDJSON unless stream is false", Tag: "ollama", Request: ollamaGenerateBody, Response: ollamaGenerateResponse},
+		},
+		openAPIPrefixV1Beta: {
+			"GET /models":          {Summary: "List models (Gemini format)", Tag: "gemini", Query: modelQuery},
+			"GET /models/*action":  {Summary: "Get a Gemini model", Tag: "gemini", Query: modelQuery},
+			"POST /models/*action": {Summary: "Gemini model actions such as generateContent, streamGenerateContent, countTokens, embedContent and batchEmbedContents", Tag: "gemini", Request: geminiRequestBody},
+		},
+	}
+}
+
+// openAPIOperations returns the operation tables for every documented route prefix.
+func openAPIOperations() map[string]map[string]openapi.Operation {
+	ops := publicOpenAPIOperations()
+	ops[openAPIPrefixManagement] = managementHandlers.OpenAPIOperations()
+	return ops
+}
+
+func (s *Server) openAPIDocument() map[string]any {
+	return openapi.Build(openapi.Info{
+		Title:       "CLI Proxy API",
+		Version:     buildinfo.Version,
+		Description: "OpenAI, Claude, Gemini and Ollama compatible proxy endpoints and the /v0/management API.",
+	}, s.engine.Routes(), openAPIOperations(), openAPIUndocumented)
+}
+
+// serveOpenAPI returns the OpenAPI document built from the live route table.
+func (s *Server) serveOpenAPI(c *gin.Context) {
+	c.JSON(http.StatusOK, s.openAPIDocument())
+}
@@ -0,0 +1,421 @@
+// Package openapi builds OpenAPI 3 documents from the gin route table and a set of
+// operation descriptions keyed by route. Request and response shapes are described
+// with Go values and converted to JSON schemas via reflection, so the document follows
+// the handler types as they evolve.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Version is the OpenAPI specification version emitted by Build.
+const Version = "3.0.3"
+
+// Content types commonly used by operations.
+const (
+	ContentJSON      = "application/json"
+	ContentYAML      = "application/yaml"
+	ContentMultipart = "multipart/form-data"
+	ContentSSE       = "text/event-stream"
+	ContentOctet     = "application/octet-stream"
+)
+
+// Operation describes a single route.
+type Operation struct {
+	Summary string
+	Tag     string
+	// Query lists the supported query parameters.
+	Query []Param
+	// Request describes the request body; nil means no body.
+	Request any
+	// RequestContent overrides the request content type (default application/json).
+	RequestContent string
+	// Response describes the 200 response body; nil means an untyped JSON object.
+	Response any
+	// ResponseContent overrides the response content type (default application/json).
+	ResponseContent string
+}
+
+// Param describes a query parameter.
+type Param struct {
+	Name        string
+	Description string
+	Type        string
+	Required    bool
+}
+
+// Fields describes an inline JSON object whose properties are given as sample values.
+type Fields map[string]any
+
+// OneOf describes a body that accepts any of the listed shapes.
+type OneOf []any
+
+// Schema is a raw JSON schema used verbatim.
+type Schema map[string]any
+
+// Info carries the document metadata.
+type Info struct {
+	Title       string
+	Version     string
+	Description string
+}
+
+// RouteKey returns the lookup key for a route, e.g. "GET /config".
+func RouteKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+var pathParamPattern = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// Build produces the OpenAPI document for routes under the given prefixes. ops maps a
+// prefix to its operation table keyed by RouteKey with the prefix stripped from the path;
+// the empty prefix holds root-level routes. Routes matched by undocumented are left out;
+// any other route without an entry is still listed so the document never hides an endpoint.
+func Build(info Info, routes gin.RoutesInfo, ops map[string]map[string]Operation, undocumented []string) map[string]any {
+	prefixes := make([]string, 0, len(ops))
+	for prefix := range ops {
+		prefixes = append(prefixes, prefix)
+	}
+	// Longest prefix first so nested groups win over their parents.
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+
+	gen := newSchemaGenerator()
+	paths := make(map[string]any)
+	for _, route := range routes {
+		if IsUndocumented(undocumented, route.Path) {
+			continue
+		}
+		prefix, ok := matchPrefix(prefixes, route.Path)
+		if !ok {
+			continue
+		}
+		relative := strings.TrimPrefix(route.Path, prefix)
+		op, found := ops[prefix][RouteKey(route.Method, relative)]
+		openPath, params := convertPath(route.Path)
+		item, _ := paths[openPath].(map[string]any)
+		if item == nil {
+			item = make(map[string]any)
+			paths[openPath] = item
+		}
+		item[strings.ToLower(route.Method)] = gen.operation(route.Method, route.Path, op, found, params)
+	}
+
+	return map[string]any{
+		"openapi": Version,
+		"info": map[string]any{
+			"title":       info.Title,
+			"version":     info.Version,
+			"description": info.Description,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": gen.components,
+			"securitySchemes": map[string]any{
+				"bearerAuth":    map[string]any{"type": "http", "scheme": "bearer"},
+				"managementKey": map[string]any{"type": "apiKey", "in": "header", "name": "X-Management-Key"},
+			},
+		},
+		"security": []any{
+			map[string]any{"bearerAuth": []string{}},
+			map[string]any{"managementKey": []string{}},
+		},
+	}
+}
+
+// Missing returns the registered routes that have no operation entry and are not matched
+// by undocumented. Routes outside every documented prefix are reported as well.
+func Missing(routes gin.RoutesInfo, ops map[string]map[string]Operation, undocumented []string) []string {
+	prefixes := make([]string, 0, len(ops))
+	for prefix := range ops {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+
+	var missing []string
+	for _, route := range routes {
+		if IsUndocumented(undocumented, route.Path) {
+			continue
+		}
+		prefix, ok := matchPrefix(prefixes, route.Path)
+		if !ok {
+			missing = append(missing, RouteKey(route.Method, route.Path))
+			continue
+		}
+		if _, found := ops[prefix][RouteKey(route.Method, strings.TrimPrefix(route.Path, prefix))]; !found {
+			missing = append(missing, RouteKey(route.Method, route.Path))
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// IsUndocumented reports whether path is matched by an entry of undocumented. An entry
+// matches the path itself and every path below it; "/" matches only the root.
+func IsUndocumented(undocumented []string, path string) bool {
+	for _, entry := range undocumented {
+		if path == entry || (entry != "/" && strings.HasPrefix(path, entry+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPrefix(prefixes []string, path string) (string, bool) {
+	for _, prefix := range prefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+func convertPath(path string) (string, []string) {
+	var params []string
+	converted := pathParamPattern.ReplaceAllStringFunc(path, func(match string) string {
+		name := match[1:]
+		params = append(params, name)
+		return "{" + name + "}"
+	})
+	return converted, params
+}
+
+func (g *schemaGenerator) operation(method, path string, op Operation, documented bool, pathParams []string) map[string]any {
+	summary := op.Summary
+	if !documented {
+		summary = "Undocumented route"
+	}
+	out := map[string]any{
+		"operationId": operationID(method, path),
+		"summary":     summary,
+	}
+	if op.Tag != "" {
+		out["tags"] = []string{op.Tag}
+	}
+
+	var parameters []any
+	for _, name := range pathParams {
+		parameters = append(parameters, map[string]any{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]any{"type": "string"},
+		})
+	}
+	for _, param := range op.Query {
+		typ := param.Type
+		if typ == "" {
+			typ = "string"
+		}
+		entry := map[string]any{
+			"name":   param.Name,
+			"in":     "query",
+			"schema": map[string]any{"type": typ},
+		}
+		if param.Description != "" {
+			entry["description"] = param.Description
+		}
+		if param.Required {
+			entry["required"] = true
+		}
+		parameters = append(parameters, entry)
+	}
+	if len(parameters) > 0 {
+		out["parameters"] = parameters
+	}
+
+	if op.Request != nil {
+		content := op.RequestContent
+		if content == "" {
+			content = ContentJSON
+		}
+		out["requestBody"] = map[string]any{
+			"required": true,
+			"content":  map[string]any{content: map[string]any{"schema": g.schemaFor(op.Request)}},
+		}
+	}
+
+	responseContent := op.ResponseContent
+	if responseContent == "" {
+		responseContent = ContentJSON
+	}
+	var responseSchema any = map[string]any{"type": "object"}
+	if op.Response != nil {
+		responseSchema = g.schemaFor(op.Response)
+	}
+	out["responses"] = map[string]any{
+		"200": map[string]any{
+			"description": http.StatusText(http.StatusOK),
+			"content":     map[string]any{responseContent: map[string]any{"schema": responseSchema}},
+		},
+		"default": map[string]any{
+			"description": "Error",
+			"content": map[string]any{ContentJSON: map[string]any{"schema": map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"error": map[string]any{"type": "string"}},
+			}}},
+		},
+	}
+	return out
+}
+
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	upperNext := true
+	for _, r := range path {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			if upperNext && r >= 'a' && r <= 'z' {
+				r -= 'a' - 'A'
+			}
+			b.WriteRune(r)
+			upperNext = false
+		default:
+			upperNext = true
+		}
+	}
+	return b.String()
+}
+
+type schemaGenerator struct {
+	components map[string]any
+	names      map[reflect.Type]string
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		components: make(map[string]any),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	durationType   = reflect.TypeOf(time.Duration(0))
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+)
+
+func (g *schemaGenerator) schemaFor(value any) any {
+	switch v := value.(type) {
+	case Schema:
+		return map[string]any(v)
+	case Fields:
+		properties := make(map[string]any, len(v))
+		for name, sample := range v {
+			properties[name] = g.schemaFor(sample)
+		}
+		return map[string]any{"type": "object", "properties": properties}
+	case []Fields:
+		var items any = map[string]any{"type": "object"}
+		if len(v) > 0 {
+			items = g.schemaFor(v[0])
+		}
+		return map[string]any{"type": "array", "items": items}
+	case OneOf:
+		variants := make([]any, 0, len(v))
+		for _, variant := range v {
+			variants = append(variants, g.schemaFor(variant))
+		}
+		return map[string]any{"oneOf": variants}
+	case nil:
+		return map[string]any{}
+	}
+	return g.schemaForType(reflect.TypeOf(value))
+}
+
+func (g *schemaGenerator) schemaForType(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case durationType:
+		return map[string]any{"type": "integer", "format": "int64", "description": "duration in nanoseconds"}
+	case rawMessageType:
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": g.schemaForType(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schemaForType(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return g.componentRef(t)
+	default:
+		return map[string]any{}
+	}
+}
+
+var componentNameSanitizer = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func (g *schemaGenerator) componentRef(t reflect.Type) map[string]any {
+	name, ok := g.names[t]
+	if !ok {
+		name = componentNameSanitizer.ReplaceAllString(t.String(), "_")
+		g.names[t] = name
+		// Reserve the name before recursing so self-referencing types terminate.
+		g.components[name] = map[string]any{}
+		g.components[name] = g.structSchema(t)
+	}
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	g.collectFields(t, properties)
+	return map[string]any{"type": "object", "properties": properties}
+}
+
+func (g *schemaGenerator) collectFields(t reflect.Type, properties map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.collectFields(embedded, properties)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = g.schemaForType(field.Type)
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/api/openapi"
+)
+
+func TestOpenAPISpecCoversAllRoutes(t *testing.T) {
+	t.Setenv("MANAGEMENT_PASSWORD", "openapi-secret")
+	server := newTestServer(t)
+
+	if missing := openapi.Missing(server.engine.Routes(), openAPIOperations(), openAPIUndocumented); len(missing) > 0 {
+		t.Fatalf("routes registered without an OpenAPI entry (add them to OpenAPIOperations or publicOpenAPIOperations, or to openAPIUndocumented):\n  %s", strings.Join(missing, "\n  "))
+	}
+
+	var managementRoutes int
+	for _, route := range server.engine.Routes() {
+		if strings.HasPrefix(route.Path, openAPIPrefixManagement+"/") {
+			managementRoutes++
+		}
+	}
+	if managementRoutes == 0 {
+		t.Fatal("expected management routes to be registered")
+	}
+}
+
+func TestOpenAPISpecHasNoStaleEntries(t *testing.T) {
+	t.Setenv("MANAGEMENT_PASSWORD", "openapi-secret")
+	server := newTestServer(t)
+
+	registered := make(map[string]struct{})
+	for _, route := range server.engine.Routes() {
+		registered[openapi.RouteKey(route.Method, route.Path)] = struct{}{}
+	}
+	for prefix, ops := range openAPIOperations() {
+		for key := range ops {
+			method, path, _ := strings.Cut(key, " ")
+			if _, ok := registered[openapi.RouteKey(method, prefix+path)]; !ok {
+				t.Errorf("OpenAPI entry %q under %s has no registered route", key, prefix)
+			}
+		}
+	}
+}
+
+func TestOpenAPIEndpoint(t *testing.T) {
+	t.Setenv("MANAGEMENT_PASSWORD", "openapi-secret")
+	server := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/v0/management/openapi.json", nil)
+	req.RemoteAddr = "127.0.0.1:12345"
+	req.Header.Set("Authorization", "Bearer openapi-secret")
+	rr := httptest.NewRecorder()
+	server.engine.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+
+	var doc struct {
+		OpenAPI    string                               `json:"openapi"`
+		Paths      map[string]map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode document: %v", err)
+	}
+	if doc.OpenAPI != openapi.Version {
+		t.Fatalf("openapi = %q, want %q", doc.OpenAPI, openapi.Version)
+	}
+
+	keys, ok := doc.Paths["/v0/management/gemini-api-key"]
+	if !ok {
+		t.Fatal("missing /v0/management/gemini-api-key path")
+	}
+	for _, method := range []string{"get", "put", "patch", "delete"} {
+		if _, ok := keys[method]; !ok {
+			t.Errorf("gemini-api-key missing %s operation", method)
+		}
+	}
+	if _, ok := doc.Components.Schemas["config.GeminiKey"]; !ok {
+		t.Errorf("expected config.GeminiKey component schema")
+	}
+
+	action, ok := doc.Paths["/v0/management/request-log-by-id/{id}"]["get"]
+	if !ok {
+		t.Fatal("expected path parameter to be converted to {id}")
+	}
+	params, _ := action["parameters"].([]any)
+	if len(params) == 0 {
+		t.Fatal("expected path parameter for request-log-by-id")
+	}
+	if _, ok := doc.Paths["/v1/chat/completions"]["post"]; !ok {
+		t.Error("missing public /v1/chat/completions operation")
+	}
+	if _, ok := doc.Paths["/api/chat"]["post"]; !ok {
+		t.Error("missing Ollama /api/chat operation")
+	}
+	if _, ok := doc.Paths["/api/threads"]; ok {
+		t.Error("Amp pass-through routes must not be documented")
+	}
+}
+
+func TestOpenAPIMissingReportsRoutesOutsideDocumentedPrefixes(t *testing.T) {
+	routes := gin.RoutesInfo{
+		{Method: http.MethodGet, Path: "/v1/models"},
+		{Method: http.MethodPost, Path: "/api/chat"},
+		{Method: http.MethodGet, Path: "/healthz"},
+		{Method: http.MethodGet, Path: "/management.html"},
+	}
+	ops := map[string]map[string]openapi.Operation{
+		openAPIPrefixV1: {"GET /models": {}},
+	}
+	missing := openapi.Missing(routes, ops, []string{"/management.html"})
+	if got := strings.Join(missing, ","); got != "GET /healthz,POST /api/chat" {
+		t.Fatalf("Missing() = %v", missing)
+	}
+}
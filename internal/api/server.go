@@ -23,7 +23,10 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/api/middleware"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/api/modules"
 	ampmodule "github.com/router-for-me/CLIProxyAPI/v6/internal/api/modules/amp"
+	ollamamodule "github.com/router-for-me/CLIProxyAPI/v6/internal/api/modules/ollama"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/kiro"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/batch"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/cache"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/managementasset"
@@ -156,6 +159,12 @@ type Server struct {
 	// ampModule is the Amp routing module for model mapping hot-reload
 	ampModule *ampmodule.AmpModule
 
+	// ollamaModule serves the optional Ollama-compatible API and is toggled on hot reload
+	ollamaModule *ollamamodule.OllamaModule
+
+	// batchManager runs locally emulated OpenAI and Anthropic batch jobs; nil when batches are disabled.
+	batchManager *batch.Manager
+
 	// managementRoutesRegistered tracks whether the management routes have been attached to the engine.
 	managementRoutesRegistered atomic.Bool
 	// managementRoutesEnabled controls whether management endpoints serve real handlers.
@@ -224,6 +233,7 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 		}
 	}
 
+	engine.Use(middleware.RequestEventsMiddleware())
 	engine.Use(corsMiddleware())
 	wd, err := os.Getwd()
 	if err != nil {
@@ -257,6 +267,9 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 	managementasset.SetCurrentConfig(cfg)
 	auth.SetQuotaCooldownDisabled(cfg.DisableCooling)
 	misc.SetCodexInstructionsEnabled(cfg.CodexInstructionsEnabled)
+	if err := cache.ConfigureSignatureCache(cfg.SignatureCache); err != nil {
+		log.Errorf("failed to configure signature cache: %v", err)
+	}
 	// Initialize management handler
 	s.mgmt = managementHandlers.NewHandler(cfg, configFilePath, authManager)
 	if optionState.localPassword != "" {
@@ -280,6 +293,10 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 	if err := modules.RegisterModule(ctx, s.ampModule); err != nil {
 		log.Errorf("Failed to register Amp module: %v", err)
 	}
+	s.ollamaModule = ollamamodule.New(ollamamodule.WithAuthMiddleware(AuthMiddleware(accessManager)))
+	if err := modules.RegisterModule(ctx, s.ollamaModule); err != nil {
+		log.Errorf("Failed to register Ollama module: %v", err)
+	}
 
 	// Apply additional router configurators from options
 	if optionState.routerConfigurator != nil {
@@ -320,18 +337,31 @@ func (s *Server) setupRoutes() {
 	geminiCLIHandlers := gemini.NewGeminiCLIAPIHandler(s.handlers)
 	claudeCodeHandlers := claude.NewClaudeCodeAPIHandler(s.handlers)
 	openaiResponsesHandlers := openai.NewOpenAIResponsesAPIHandler(s.handlers)
+	openaiEmbeddingsHandlers := openai.NewOpenAIEmbeddingsAPIHandler(s.handlers)
+	openaiImagesHandlers := openai.NewOpenAIImagesAPIHandler(s.handlers)
 
 	// OpenAI compatible API routes
 	v1 := s.engine.Group("/v1")
 	v1.Use(AuthMiddleware(s.accessManager))
 	{
 		v1.GET("/models", s.unifiedModelsHandler(openaiHandlers, claudeCodeHandlers))
+		v1.GET("/models/*model", s.unifiedModelHandler(openaiHandlers, claudeCodeHandlers))
 		v1.POST("/chat/completions", openaiHandlers.ChatCompletions)
 		v1.POST("/completions", openaiHandlers.Completions)
 		v1.POST("/messages", claudeCodeHandlers.ClaudeMessages)
 		v1.POST("/messages/count_tokens", claudeCodeHandlers.ClaudeCountTokens)
 		v1.POST("/responses", openaiResponsesHandlers.Responses)
-	}
+		v1.GET("/responses/:id", openaiResponsesHandlers.GetResponse)
+		v1.DELETE("/responses/:id", openaiResponsesHandlers.DeleteResponse)
+		v1.GET("/responses/:id/input_items", openaiResponsesHandlers.ResponseInputItems)
+		v1.POST("/embeddings", openaiEmbeddingsHandlers.Embeddings)
+		v1.POST("/images/generations", openaiImagesHandlers.ImageGenerations)
+		v1.POST("/images/edits", openaiImagesHandlers.ImageEdits)
+	}
+	// Generated image URLs are fetched without credentials; the image ID is unguessable
+	// and short-lived.
+	s.engine.GET("/v1/images/files/:id", openaiImagesHandlers.ImageFile)
+	s.setupBatchRoutes(v1)
 
 	// Gemini compatible API routes
 	v1beta := s.engine.Group("/v1beta")
@@ -349,6 +379,8 @@ func (s *Server) setupRoutes() {
 			"endpoints": []string{
 				"POST /v1/chat/completions",
 				"POST /v1/completions",
+				"POST /v1/embeddings",
+				"POST /v1/images/generations",
 				"GET /v1/models",
 			},
 		})
@@ -501,9 +533,12 @@ func (s *Server) registerManagementRoutes() {
 	mgmt := s.engine.Group("/v0/management")
 	mgmt.Use(s.managementAvailabilityMiddleware(), s.mgmt.Middleware())
 	{
+		mgmt.GET("/openapi.json", s.serveOpenAPI)
 		mgmt.GET("/usage", s.mgmt.GetUsageStatistics)
+		mgmt.GET("/events", s.mgmt.GetEvents)
 		mgmt.GET("/usage/export", s.mgmt.ExportUsageStatistics)
 		mgmt.POST("/usage/import", s.mgmt.ImportUsageStatistics)
+		mgmt.GET("/signature-cache", s.mgmt.GetSignatureCacheStatistics)
 		mgmt.GET("/config", s.mgmt.GetConfig)
 		mgmt.GET("/config.yaml", s.mgmt.GetConfigYAML)
 		mgmt.PUT("/config.yaml", s.mgmt.PutConfigYAML)
@@ -555,6 +590,7 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.GET("/request-error-logs", s.mgmt.GetRequestErrorLogs)
 		mgmt.GET("/request-error-logs/:name", s.mgmt.DownloadRequestErrorLog)
 		mgmt.GET("/request-log-by-id/:id", s.mgmt.GetRequestLogByID)
+		mgmt.GET("/request-logs", s.mgmt.GetRequestLogIndex)
 		mgmt.GET("/request-log", s.mgmt.GetRequestLog)
 		mgmt.PUT("/request-log", s.mgmt.PutRequestLog)
 		mgmt.PATCH("/request-log", s.mgmt.PutRequestLog)
@@ -772,24 +808,45 @@ func (s *Server) watchKeepAlive() {
 }
 
 // unifiedModelsHandler creates a unified handler for the /v1/models endpoint
-// that routes to different handlers based on the User-Agent header.
-// If User-Agent starts with "claude-cli", it routes to Claude handler,
-// otherwise it routes to OpenAI handler.
+// that routes to different handlers based on the request headers.
+// Claude clients (see Server.isClaudeClient) get the Claude handler,
+// everyone else gets the OpenAI handler.
 func (s *Server) unifiedModelsHandler(openaiHandler *openai.OpenAIAPIHandler, claudeHandler *claude.ClaudeCodeAPIHandler) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userAgent := c.GetHeader("User-Agent")
-
-		// Route to Claude handler if User-Agent starts with "claude-cli"
-		if strings.HasPrefix(userAgent, "claude-cli") {
-			// log.Debugf("Routing /v1/models to Claude handler for User-Agent: %s", userAgent)
+		if s.isClaudeClient(c) {
 			claudeHandler.ClaudeModels(c)
 		} else {
-			// log.Debugf("Routing /v1/models to OpenAI handler for User-Agent: %s", userAgent)
 			openaiHandler.OpenAIModels(c)
 		}
 	}
 }
 
+// unifiedModelHandler routes GET /v1/models/{id} like unifiedModelsHandler.
+func (s *Server) unifiedModelHandler(openaiHandler *openai.OpenAIAPIHandler, claudeHandler *claude.ClaudeCodeAPIHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.isClaudeClient(c) {
+			claudeHandler.ClaudeModel(c)
+		} else {
+			openaiHandler.OpenAIModel(c)
+		}
+	}
+}
+
+// isClaudeClient reports whether a models request comes from an Anthropic client: the
+// Claude CLI, identified by its User-Agent, or an SDK sending anthropic-version without
+// an OpenAI-style bearer token. Clients that add anthropic-version to OpenAI requests
+// authenticate with Authorization: Bearer and keep the OpenAI format.
+func (s *Server) isClaudeClient(c *gin.Context) bool {
+	if strings.HasPrefix(c.GetHeader("User-Agent"), "claude-cli") {
+		return true
+	}
+	if c.GetHeader("anthropic-version") == "" {
+		return false
+	}
+	authorization := strings.ToLower(strings.TrimSpace(c.GetHeader("Authorization")))
+	return !strings.HasPrefix(authorization, "bearer ")
+}
+
 // Start begins listening for and serving HTTP or HTTPS requests.
 // It's a blocking call and will only return on an unrecoverable error.
 //
@@ -845,10 +902,62 @@ func (s *Server) Stop(ctx context.Context) error {
 		return fmt.Errorf("failed to shutdown HTTP server: %v", err)
 	}
 
+	if s.batchManager != nil {
+		s.batchManager.Close()
+	}
+
 	log.Debug("API server stopped")
 	return nil
 }
 
+// setupBatchRoutes registers the OpenAI /v1/files and /v1/batches endpoints and the
+// Anthropic /v1/messages/batches endpoints when batches are enabled. Both share one
+// batch manager. Enabling or disabling batches requires a restart.
+func (s *Server) setupBatchRoutes(v1 *gin.RouterGroup) {
+	if !s.cfg.Batch.Enable {
+		return
+	}
+	manager, err := batch.NewManager(batchDir(s.cfg), batch.Options{
+		Concurrency:   s.cfg.Batch.Concurrency,
+		MaxFileSizeMB: s.cfg.Batch.MaxFileSizeMB,
+		Load:          s.handlers.InteractiveInFlight,
+	})
+	if err != nil {
+		log.Errorf("failed to load batch state, batch endpoints disabled: %v", err)
+		return
+	}
+	batchHandlers := openai.NewOpenAIBatchAPIHandler(s.handlers, manager)
+	messageBatchHandlers := claude.NewClaudeMessageBatchAPIHandler(s.handlers, manager)
+	manager.Start()
+	s.batchManager = manager
+
+	v1.POST("/files", batchHandlers.UploadFile)
+	v1.GET("/files", batchHandlers.ListFiles)
+	v1.GET("/files/:id", batchHandlers.GetFile)
+	v1.DELETE("/files/:id", batchHandlers.DeleteFile)
+	v1.GET("/files/:id/content", batchHandlers.FileContent)
+	v1.POST("/batches", batchHandlers.CreateBatch)
+	v1.GET("/batches", batchHandlers.ListBatches)
+	v1.GET("/batches/:id", batchHandlers.GetBatch)
+	v1.POST("/batches/:id/cancel", batchHandlers.CancelBatch)
+	v1.POST("/messages/batches", messageBatchHandlers.CreateMessageBatch)
+	v1.GET("/messages/batches", messageBatchHandlers.ListMessageBatches)
+	v1.GET("/messages/batches/:id", messageBatchHandlers.GetMessageBatch)
+	v1.POST("/messages/batches/:id/cancel", messageBatchHandlers.CancelMessageBatch)
+	v1.GET("/messages/batches/:id/results", messageBatchHandlers.MessageBatchResults)
+}
+
+// batchDir resolves the directory holding batch files and job state.
+func batchDir(cfg *config.Config) string {
+	if dir := strings.TrimSpace(cfg.Batch.Dir); dir != "" {
+		return dir
+	}
+	if base := util.WritablePath(); base != "" {
+		return filepath.Join(base, "batches")
+	}
+	return "batches"
+}
+
 // corsMiddleware returns a Gin middleware handler that adds CORS headers
 // to every response, allowing cross-origin requests.
 //
@@ -953,6 +1062,14 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 		}
 	}
 
+	if oldCfg == nil || oldCfg.SignatureCache != cfg.SignatureCache {
+		if err := cache.ConfigureSignatureCache(cfg.SignatureCache); err != nil {
+			log.Errorf("failed to reconfigure signature cache: %v", err)
+		} else if oldCfg != nil {
+			log.Debugf("signature cache backend updated to %q", cfg.SignatureCache.Backend)
+		}
+	}
+
 	if s.handlers != nil && s.handlers.AuthManager != nil {
 		s.handlers.AuthManager.SetRetryConfig(cfg.RequestRetry, time.Duration(cfg.MaxRetryInterval)*time.Second)
 	}
@@ -1010,6 +1127,9 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 	s.oldConfigYaml, _ = yaml.Marshal(cfg)
 
 	s.handlers.UpdateClients(&cfg.SDKConfig)
+	if s.batchManager != nil {
+		s.batchManager.SetLimits(cfg.Batch.Concurrency, cfg.Batch.MaxFileSizeMB)
+	}
 
 	if !cfg.RemoteManagement.DisableControlPanel {
 		staticDir := managementasset.StaticDir(s.configFilePath)
@@ -1029,6 +1149,11 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 	} else {
 		log.Warnf("amp module is nil, skipping config update")
 	}
+	if s.ollamaModule != nil {
+		if err := s.ollamaModule.OnConfigUpdated(cfg); err != nil {
+			log.Errorf("failed to update Ollama module config: %v", err)
+		}
+	}
 
 	// Count client sources from configuration and auth store.
 	tokenStore := sdkAuth.GetTokenStore()
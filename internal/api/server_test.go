@@ -10,9 +10,11 @@ import (
 
 	gin "github.com/gin-gonic/gin"
 	proxyconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
 )
 
 func newTestServer(t *testing.T) *Server {
@@ -36,6 +38,8 @@ func newTestServer(t *testing.T) *Server {
 		LoggingToFile:          false,
 		UsageStatisticsEnabled: false,
 	}
+	cfg.Batch.Enable = true
+	cfg.Batch.Dir = filepath.Join(tmpDir, "batches")
 
 	authManager := auth.NewManager(nil, nil, nil)
 	accessManager := sdkaccess.NewManager()
@@ -109,3 +113,70 @@ func TestAmpProviderModelRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestModelRetrieveRoutes(t *testing.T) {
+	registry.GetGlobalRegistry().RegisterClient("retrieve-test", "gemini", []*registry.ModelInfo{{
+		ID:               "retrieve-test-model",
+		Name:             "models/retrieve-test-model",
+		Type:             "gemini",
+		OwnedBy:          "google",
+		Created:          1750000000,
+		InputTokenLimit:  1048576,
+		OutputTokenLimit: 65536,
+		Thinking:         &registry.ThinkingSupport{Levels: []string{"low", "high"}},
+	}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient("retrieve-test") })
+	server := newTestServer(t)
+
+	do := func(path string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer test-key")
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		rr := httptest.NewRecorder()
+		server.engine.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do("/v1/models/retrieve-test-model", nil)
+	if rr.Code != http.StatusOK || gjson.Get(rr.Body.String(), "object").String() != "model" || gjson.Get(rr.Body.String(), "capabilities").Exists() {
+		t.Fatalf("openai retrieve: %d %s", rr.Code, rr.Body.String())
+	}
+	rr = do("/v1/models/missing-model", nil)
+	if rr.Code != http.StatusNotFound || gjson.Get(rr.Body.String(), "error.code").String() != "model_not_found" {
+		t.Fatalf("openai missing model: %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = do("/v1/models/retrieve-test-model", map[string]string{"anthropic-version": "2023-06-01"})
+	if gjson.Get(rr.Body.String(), "object").String() != "model" || gjson.Get(rr.Body.String(), "type").Exists() {
+		t.Fatalf("anthropic-version with a bearer token must keep the OpenAI format: %s", rr.Body.String())
+	}
+	claudeHeaders := map[string]string{"anthropic-version": "2023-06-01", "Authorization": "", "x-api-key": "test-key"}
+	rr = do("/v1/models/retrieve-test-model", claudeHeaders)
+	if rr.Code != http.StatusOK || gjson.Get(rr.Body.String(), "type").String() != "model" || gjson.Get(rr.Body.String(), "created_at").String() != "2025-06-15T15:06:40Z" {
+		t.Fatalf("claude retrieve: %d %s", rr.Code, rr.Body.String())
+	}
+	rr = do("/v1/models/missing-model", claudeHeaders)
+	if rr.Code != http.StatusNotFound || gjson.Get(rr.Body.String(), "error.type").String() != "not_found_error" {
+		t.Fatalf("claude missing model: %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = do("/v1beta/models/retrieve-test-model?extended=true", nil)
+	body := rr.Body.String()
+	if rr.Code != http.StatusOK || gjson.Get(body, "name").String() != "models/retrieve-test-model" {
+		t.Fatalf("gemini retrieve: %d %s", rr.Code, body)
+	}
+	if gjson.Get(body, "capabilities.context_window").Int() != 1048576 || gjson.Get(body, "capabilities.thinking_levels.1").String() != "high" || !gjson.Get(body, "capabilities.vision").Bool() {
+		t.Fatalf("gemini capabilities: %s", body)
+	}
+	rr = do("/v1beta/models/missing-model", nil)
+	if rr.Code != http.StatusNotFound || gjson.Get(rr.Body.String(), "error.status").String() != "NOT_FOUND" {
+		t.Fatalf("gemini missing model: %d %s", rr.Code, rr.Body.String())
+	}
+
+	list := do("/v1/models?extended=true", nil).Body.String()
+	if !gjson.Get(list, `data.#(id=="retrieve-test-model").capabilities.tools`).Bool() {
+		t.Fatalf("extended listing lacks capabilities: %s", list)
+	}
+}
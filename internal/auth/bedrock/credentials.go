@@ -0,0 +1,88 @@
+package bedrock
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SharedCredentialsFile returns the path of the shared AWS credentials file, honouring
+// the AWS_SHARED_CREDENTIALS_FILE environment variable.
+func SharedCredentialsFile() string {
+	if path := strings.TrimSpace(os.Getenv("AWS_SHARED_CREDENTIALS_FILE")); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".aws", "credentials")
+}
+
+// LoadProfileCredentials reads the static credentials of a profile from the shared AWS
+// credentials file.
+func LoadProfileCredentials(profile string) (Credentials, error) {
+	return LoadProfileCredentialsFromFile(SharedCredentialsFile(), profile)
+}
+
+// LoadProfileCredentialsFromFile reads the static credentials of a profile from an
+// INI-formatted AWS credentials file.
+func LoadProfileCredentialsFromFile(path, profile string) (Credentials, error) {
+	profile = strings.TrimSpace(profile)
+	if profile == "" {
+		profile = "default"
+	}
+	if path == "" {
+		return Credentials{}, fmt.Errorf("bedrock: shared credentials file not found")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("bedrock: open credentials file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var creds Credentials
+	found := false
+	inProfile := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			inProfile = name == profile
+			found = found || inProfile
+			continue
+		}
+		if !inProfile {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "aws_access_key_id":
+			creds.AccessKeyID = value
+		case "aws_secret_access_key":
+			creds.SecretAccessKey = value
+		case "aws_session_token":
+			creds.SessionToken = value
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return Credentials{}, fmt.Errorf("bedrock: read credentials file: %w", err)
+	}
+	if !found {
+		return Credentials{}, fmt.Errorf("bedrock: profile %q not found in %s", profile, path)
+	}
+	if !creds.Valid() {
+		return Credentials{}, fmt.Errorf("bedrock: profile %q has no static credentials", profile)
+	}
+	return creds, nil
+}
@@ -0,0 +1,76 @@
+package bedrock
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSignRequest_GetVanilla checks the "get-vanilla" case of the AWS SigV4 test suite.
+func TestSignRequest_GetVanilla(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds := Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	if err = SignRequest(req, nil, creds, "us-east-1", "service", now); err != nil {
+		t.Fatalf("SignRequest: %v", err)
+	}
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Fatalf("Authorization = %q\nwant %q", got, want)
+	}
+}
+
+func TestSignRequest_SignsSessionTokenAndEscapedModelID(t *testing.T) {
+	body := []byte(`{"messages":[]}`)
+	path := "/model/" + EscapePathSegment("anthropic.claude-sonnet-4-20250514-v1:0") + "/converse"
+	req, err := http.NewRequest(http.MethodPost, "https://bedrock-runtime.us-west-2.amazonaws.com"+path, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	creds := Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "token"}
+	if err = SignRequest(req, body, creds, "us-west-2", ServiceName, time.Now()); err != nil {
+		t.Fatalf("SignRequest: %v", err)
+	}
+	if got := req.URL.EscapedPath(); !strings.Contains(got, "v1%3A0") {
+		t.Fatalf("model ID not escaped in path: %s", got)
+	}
+	if got := canonicalURI(req.URL); !strings.Contains(got, "v1%253A0") {
+		t.Fatalf("canonical URI not double encoded: %s", got)
+	}
+	auth := req.Header.Get("Authorization")
+	if !strings.Contains(auth, "/us-west-2/bedrock/aws4_request") ||
+		!strings.Contains(auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token") {
+		t.Fatalf("unexpected Authorization: %s", auth)
+	}
+	if req.Header.Get("X-Amz-Security-Token") != "token" {
+		t.Fatal("session token header missing")
+	}
+}
+
+func TestLoadProfileCredentialsFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	content := "[default]\naws_access_key_id = AKIDDEFAULT\naws_secret_access_key = s1\n\n" +
+		"[bedrock]\naws_access_key_id=AKIDBEDROCK\naws_secret_access_key=s2\naws_session_token=tok\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	creds, err := LoadProfileCredentialsFromFile(path, "bedrock")
+	if err != nil {
+		t.Fatalf("LoadProfileCredentialsFromFile: %v", err)
+	}
+	if creds.AccessKeyID != "AKIDBEDROCK" || creds.SecretAccessKey != "s2" || creds.SessionToken != "tok" {
+		t.Fatalf("unexpected credentials: %+v", creds)
+	}
+	if _, err = LoadProfileCredentialsFromFile(path, "missing"); err == nil {
+		t.Fatal("expected error for missing profile")
+	}
+}
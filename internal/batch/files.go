@@ -0,0 +1,239 @@
+package batch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// File purposes understood by the emulation. Message batch files hold the requests and
+// results of Anthropic message batches and are not exposed through /v1/files.
+const (
+	PurposeBatch              = "batch"
+	PurposeBatchOutput        = "batch_output"
+	PurposeMessageBatch       = "message_batch"
+	PurposeMessageBatchOutput = "message_batch_output"
+)
+
+// File describes an uploaded or generated file in OpenAI file-object format.
+type File struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	Bytes     int64  `json:"bytes"`
+	CreatedAt int64  `json:"created_at"`
+	Filename  string `json:"filename"`
+	Purpose   string `json:"purpose"`
+	// Owner identifies the client API key that created the file. It is persisted but
+	// not part of the API object.
+	Owner string `json:"-"`
+}
+
+// storedFile is the on-disk form of a File, which keeps the owner.
+type storedFile struct {
+	*File
+	Owner string `json:"owner,omitempty"`
+}
+
+// VisibleTo reports whether the file may be accessed by owner.
+func (f *File) VisibleTo(owner string) bool {
+	return f.Owner == owner
+}
+
+func (m *Manager) filesDir() string {
+	return filepath.Join(m.dir, "files")
+}
+
+func (m *Manager) fileMetaPath(id string) string {
+	return filepath.Join(m.filesDir(), id+".json")
+}
+
+// FilePath returns the location of the stored content for the file with the given ID.
+func (m *Manager) FilePath(id string) string {
+	return filepath.Join(m.filesDir(), id+".data")
+}
+
+// CreateFile stores content read from r on behalf of owner. Uploads larger than the
+// configured limit are rejected with ErrFileTooLarge.
+func (m *Manager) CreateFile(owner, filename, purpose string, r io.Reader) (*File, error) {
+	purpose = strings.TrimSpace(purpose)
+	if purpose == "" {
+		return nil, fmt.Errorf("%w: purpose is required", ErrInvalidRequest)
+	}
+	if err := os.MkdirAll(m.filesDir(), 0o700); err != nil {
+		return nil, fmt.Errorf("batch: create files directory: %w", err)
+	}
+	file := &File{
+		ID:        "file-" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Object:    "file",
+		CreatedAt: time.Now().Unix(),
+		Filename:  filepath.Base(strings.TrimSpace(filename)),
+		Purpose:   purpose,
+		Owner:     owner,
+	}
+	limit := m.maxFileSize.Load()
+	out, err := os.OpenFile(m.FilePath(file.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("batch: create file: %w", err)
+	}
+	written, errCopy := io.Copy(out, io.LimitReader(r, limit+1))
+	errClose := out.Close()
+	if errCopy == nil {
+		errCopy = errClose
+	}
+	if errCopy == nil && written > limit {
+		errCopy = ErrFileTooLarge
+	}
+	if errCopy != nil {
+		_ = os.Remove(m.FilePath(file.ID))
+		if errors.Is(errCopy, ErrFileTooLarge) {
+			return nil, errCopy
+		}
+		return nil, fmt.Errorf("batch: write file: %w", errCopy)
+	}
+	file.Bytes = written
+	if err = writeJSON(m.fileMetaPath(file.ID), storedFile{file, file.Owner}); err != nil {
+		_ = os.Remove(m.FilePath(file.ID))
+		return nil, err
+	}
+	return file, nil
+}
+
+// adoptFile registers an existing file at path as a stored file of owner, moving it into place.
+func (m *Manager) adoptFile(path, filename, purpose, owner string) (*File, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("batch: stat file: %w", err)
+	}
+	if err = os.MkdirAll(m.filesDir(), 0o700); err != nil {
+		return nil, fmt.Errorf("batch: create files directory: %w", err)
+	}
+	file := &File{
+		ID:        "file-" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Object:    "file",
+		Bytes:     info.Size(),
+		CreatedAt: time.Now().Unix(),
+		Filename:  filename,
+		Purpose:   purpose,
+		Owner:     owner,
+	}
+	if err = os.Rename(path, m.FilePath(file.ID)); err != nil {
+		return nil, fmt.Errorf("batch: move file: %w", err)
+	}
+	if err = writeJSON(m.fileMetaPath(file.ID), storedFile{file, file.Owner}); err != nil {
+		_ = os.Remove(m.FilePath(file.ID))
+		return nil, err
+	}
+	return file, nil
+}
+
+// GetFile returns the metadata of the file with the given ID.
+func (m *Manager) GetFile(id string) (*File, error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+	stored := storedFile{File: &File{}}
+	if err := readJSON(m.fileMetaPath(id), &stored); err != nil {
+		return nil, err
+	}
+	stored.File.Owner = stored.Owner
+	return stored.File, nil
+}
+
+// ListFiles returns stored files, newest first, optionally filtered by purpose.
+func (m *Manager) ListFiles(purpose string) ([]*File, error) {
+	entries, err := os.ReadDir(m.filesDir())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("batch: list files: %w", err)
+	}
+	files := make([]*File, 0, len(entries))
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok {
+			continue
+		}
+		file, errGet := m.GetFile(id)
+		if errGet != nil {
+			continue
+		}
+		if purpose != "" && file.Purpose != purpose {
+			continue
+		}
+		files = append(files, file)
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].CreatedAt != files[j].CreatedAt {
+			return files[i].CreatedAt > files[j].CreatedAt
+		}
+		return files[i].ID > files[j].ID
+	})
+	return files, nil
+}
+
+// DeleteFile removes the file with the given ID.
+func (m *Manager) DeleteFile(id string) error {
+	if _, err := m.GetFile(id); err != nil {
+		return err
+	}
+	if err := os.Remove(m.fileMetaPath(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("batch: delete file: %w", err)
+	}
+	if err := os.Remove(m.FilePath(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("batch: delete file: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("batch: encode %s: %w", filepath.Base(path), err)
+	}
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("batch: write %s: %w", filepath.Base(path), err)
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("batch: write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("batch: read %s: %w", filepath.Base(path), err)
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("batch: decode %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// validID reports whether id is safe to use as a file name.
+func validID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
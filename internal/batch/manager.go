@@ -0,0 +1,914 @@
+// Package batch emulates the OpenAI Batch API. Uploaded JSONL request files are executed
+// line by line in the background through the proxy's own executors, and job state is kept
+// on disk so unfinished batches resume after a restart.
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// Batch statuses, matching the OpenAI batch object.
+const (
+	StatusValidating = "validating"
+	StatusFailed     = "failed"
+	StatusInProgress = "in_progress"
+	StatusFinalizing = "finalizing"
+	StatusCompleted  = "completed"
+	StatusExpired    = "expired"
+	StatusCancelling = "cancelling"
+	StatusCancelled  = "cancelled"
+)
+
+const (
+	defaultConcurrency   = 4
+	defaultMaxFileSizeMB = 100
+	completionWindow     = "24h"
+	completionDuration   = 24 * time.Hour
+	loadPollInterval     = 200 * time.Millisecond
+	countPersistInterval = time.Second
+)
+
+var (
+	// ErrNotFound is returned when a file or batch does not exist.
+	ErrNotFound = errors.New("batch: not found")
+	// ErrInvalidRequest wraps client errors that should be reported as 400 responses.
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrFileTooLarge is returned when an upload exceeds the configured size limit.
+	ErrFileTooLarge = errors.New("file exceeds the maximum upload size")
+)
+
+// Executor runs a single batch request on behalf of the batch owner and returns the
+// HTTP status code and response body the endpoint would have produced.
+type Executor func(ctx context.Context, owner string, body []byte) (int, []byte)
+
+// RequestCounts tracks progress of a batch.
+type RequestCounts struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+}
+
+// ResultCounts classifies the requests of a finished batch by outcome.
+type ResultCounts struct {
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Cancelled int `json:"cancelled"`
+	Expired   int `json:"expired"`
+}
+
+// ErrorData describes a validation error found in the input file.
+type ErrorData struct {
+	Code    string  `json:"code"`
+	Message string  `json:"message"`
+	Param   *string `json:"param"`
+	Line    *int    `json:"line"`
+}
+
+// Errors is the list wrapper used for batch validation errors.
+type Errors struct {
+	Object string      `json:"object"`
+	Data   []ErrorData `json:"data"`
+}
+
+// Batch is a batch job in OpenAI batch-object format.
+type Batch struct {
+	ID               string            `json:"id"`
+	Object           string            `json:"object"`
+	Endpoint         string            `json:"endpoint"`
+	Errors           *Errors           `json:"errors"`
+	InputFileID      string            `json:"input_file_id"`
+	CompletionWindow string            `json:"completion_window"`
+	Status           string            `json:"status"`
+	OutputFileID     *string           `json:"output_file_id"`
+	ErrorFileID      *string           `json:"error_file_id"`
+	CreatedAt        int64             `json:"created_at"`
+	InProgressAt     *int64            `json:"in_progress_at"`
+	ExpiresAt        *int64            `json:"expires_at"`
+	FinalizingAt     *int64            `json:"finalizing_at"`
+	CompletedAt      *int64            `json:"completed_at"`
+	FailedAt         *int64            `json:"failed_at"`
+	ExpiredAt        *int64            `json:"expired_at"`
+	CancellingAt     *int64            `json:"cancelling_at"`
+	CancelledAt      *int64            `json:"cancelled_at"`
+	RequestCounts    RequestCounts     `json:"request_counts"`
+	Metadata         map[string]string `json:"metadata"`
+	// Owner identifies the client API key that created the batch. It is persisted but
+	// not part of the API object.
+	Owner string `json:"-"`
+	// ResultCounts is set once the batch has finished, so callers need not read the
+	// result files to count outcomes. It is persisted but not part of the API object.
+	ResultCounts *ResultCounts `json:"-"`
+}
+
+// storedBatch is the on-disk form of a Batch, which keeps the owner and result counts.
+type storedBatch struct {
+	*Batch
+	Owner        string        `json:"owner,omitempty"`
+	ResultCounts *ResultCounts `json:"result_counts,omitempty"`
+}
+
+// VisibleTo reports whether the batch may be accessed by owner.
+func (b *Batch) VisibleTo(owner string) bool {
+	return b.Owner == owner
+}
+
+func (b *Batch) terminal() bool {
+	switch b.Status {
+	case StatusFailed, StatusCompleted, StatusExpired, StatusCancelled:
+		return true
+	}
+	return false
+}
+
+func (b *Batch) clone() *Batch {
+	out := *b
+	out.Metadata = maps.Clone(b.Metadata)
+	if b.ResultCounts != nil {
+		counts := *b.ResultCounts
+		out.ResultCounts = &counts
+	}
+	return &out
+}
+
+// Options tunes a Manager.
+type Options struct {
+	// Concurrency bounds how many batch requests run at once. <= 0 uses 4.
+	Concurrency int
+	// MaxFileSizeMB limits uploads. <= 0 uses 100.
+	MaxFileSizeMB int
+	// Load reports the number of interactive requests in flight. Each one lowers the
+	// effective concurrency by one, down to a minimum of one.
+	Load func() int64
+}
+
+// CreateParams describes a new batch.
+type CreateParams struct {
+	InputFileID      string
+	Endpoint         string
+	CompletionWindow string
+	Metadata         map[string]string
+	// Owner identifies the client API key creating the batch. Result files inherit it.
+	Owner string
+	// IDPrefix replaces the default "batch_" prefix of the generated ID.
+	IDPrefix string
+}
+
+// Manager stores files and runs batch jobs one at a time. Executors are registered per
+// endpoint with Handle before Start is called.
+type Manager struct {
+	dir         string
+	load        func() int64
+	concurrency atomic.Int64
+	maxFileSize atomic.Int64
+
+	mu        sync.Mutex
+	executors map[string]Executor
+	batches   map[string]*Batch
+	cancels   map[string]context.CancelFunc
+	pending   []string
+	wake      chan struct{}
+
+	ctx  context.Context
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+}
+
+// NewManager loads persisted state from dir. Batches that were unfinished when the
+// previous process stopped are resumed once Start is called.
+func NewManager(dir string, opts Options) (*Manager, error) {
+	absDir, err := filepath.Abs(strings.TrimSpace(dir))
+	if err != nil {
+		return nil, fmt.Errorf("batch: resolve directory: %w", err)
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	m := &Manager{
+		dir:       absDir,
+		load:      opts.Load,
+		executors: make(map[string]Executor),
+		batches:   make(map[string]*Batch),
+		cancels:   make(map[string]context.CancelFunc),
+		wake:      make(chan struct{}, 1),
+		ctx:       ctx,
+		stop:      stop,
+	}
+	m.SetLimits(opts.Concurrency, opts.MaxFileSizeMB)
+	if err = m.restore(); err != nil {
+		stop()
+		return nil, err
+	}
+	return m, nil
+}
+
+// Handle registers the executor for batches targeting endpoint.
+func (m *Manager) Handle(endpoint string, exec Executor) {
+	m.mu.Lock()
+	m.executors[endpoint] = exec
+	m.mu.Unlock()
+}
+
+func (m *Manager) executor(endpoint string) Executor {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.executors[endpoint]
+}
+
+// Start launches the background worker.
+func (m *Manager) Start() {
+	m.wg.Add(1)
+	go m.loop()
+}
+
+// SetLimits updates the concurrency and upload size limits.
+func (m *Manager) SetLimits(concurrency, maxFileSizeMB int) {
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	if maxFileSizeMB <= 0 {
+		maxFileSizeMB = defaultMaxFileSizeMB
+	}
+	m.concurrency.Store(int64(concurrency))
+	m.maxFileSize.Store(int64(maxFileSizeMB) << 20)
+}
+
+// MaxFileSize returns the upload size limit in bytes.
+func (m *Manager) MaxFileSize() int64 {
+	return m.maxFileSize.Load()
+}
+
+// Close stops the worker. Running batches keep their persisted state and resume on the
+// next start.
+func (m *Manager) Close() {
+	if m == nil {
+		return
+	}
+	m.stop()
+	m.wg.Wait()
+}
+
+func (m *Manager) batchesDir() string {
+	return filepath.Join(m.dir, "batches")
+}
+
+func (m *Manager) batchPath(id string) string {
+	return filepath.Join(m.batchesDir(), id+".json")
+}
+
+func (m *Manager) partialPath(id, kind string) string {
+	return filepath.Join(m.batchesDir(), id+"."+kind+".jsonl")
+}
+
+func (m *Manager) restore() error {
+	entries, err := os.ReadDir(m.batchesDir())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("batch: read state: %w", err)
+	}
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok || !validID(id) {
+			continue
+		}
+		stored := storedBatch{Batch: &Batch{}}
+		if errRead := readJSON(m.batchPath(id), &stored); errRead != nil {
+			log.Warnf("batch: skip unreadable state %s: %v", entry.Name(), errRead)
+			continue
+		}
+		b := stored.Batch
+		b.Owner = stored.Owner
+		b.ResultCounts = stored.ResultCounts
+		m.batches[b.ID] = b
+		if !b.terminal() {
+			m.pending = append(m.pending, b.ID)
+		}
+	}
+	// Batches finished before result counts were persisted are counted once here.
+	for _, b := range m.batches {
+		if b.terminal() && b.ResultCounts == nil {
+			if b.ResultCounts = m.countResults(b); b.ResultCounts != nil {
+				m.persistLocked(b)
+			}
+		}
+	}
+	sort.Slice(m.pending, func(i, j int) bool {
+		return m.batches[m.pending[i]].CreatedAt < m.batches[m.pending[j]].CreatedAt
+	})
+	return nil
+}
+
+// persistLocked writes the batch state. The caller must hold m.mu.
+func (m *Manager) persistLocked(b *Batch) {
+	if err := writeJSON(m.batchPath(b.ID), storedBatch{b, b.Owner, b.ResultCounts}); err != nil {
+		log.Errorf("batch: persist %s: %v", b.ID, err)
+	}
+}
+
+// update applies fn to the batch under lock, persists it, and returns a snapshot.
+func (m *Manager) update(id string, fn func(b *Batch)) *Batch {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b := m.batches[id]
+	if b == nil {
+		return nil
+	}
+	fn(b)
+	m.persistLocked(b)
+	return b.clone()
+}
+
+// amend applies fn to a batch in memory only. The state file is brought up to date by the
+// next update or persist.
+func (m *Manager) amend(id string, fn func(b *Batch)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if b := m.batches[id]; b != nil {
+		fn(b)
+	}
+}
+
+// persist writes the current state of a batch.
+func (m *Manager) persist(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if b := m.batches[id]; b != nil {
+		m.persistLocked(b)
+	}
+}
+
+// Create validates the parameters and queues a new batch.
+func (m *Manager) Create(params CreateParams) (*Batch, error) {
+	if m.executor(params.Endpoint) == nil {
+		return nil, fmt.Errorf("%w: unsupported endpoint %q", ErrInvalidRequest, params.Endpoint)
+	}
+	if params.CompletionWindow != completionWindow {
+		return nil, fmt.Errorf("%w: completion_window must be %q", ErrInvalidRequest, completionWindow)
+	}
+	file, err := m.GetFile(params.InputFileID)
+	if err == nil && !file.VisibleTo(params.Owner) {
+		err = ErrNotFound
+	}
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("%w: input file %q not found", ErrInvalidRequest, params.InputFileID)
+		}
+		return nil, err
+	}
+	if file.Purpose != PurposeBatch && file.Purpose != PurposeMessageBatch {
+		return nil, fmt.Errorf("%w: input file must have purpose %q", ErrInvalidRequest, PurposeBatch)
+	}
+	if err = os.MkdirAll(m.batchesDir(), 0o700); err != nil {
+		return nil, fmt.Errorf("batch: create state directory: %w", err)
+	}
+
+	total := 0
+	if err = m.scanFile(file.ID, func([]byte) { total++ }); err != nil {
+		return nil, err
+	}
+	prefix := params.IDPrefix
+	if prefix == "" {
+		prefix = "batch_"
+	}
+	now := time.Now()
+	b := &Batch{
+		ID:               prefix + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Object:           "batch",
+		Endpoint:         params.Endpoint,
+		InputFileID:      params.InputFileID,
+		CompletionWindow: params.CompletionWindow,
+		Status:           StatusValidating,
+		CreatedAt:        now.Unix(),
+		ExpiresAt:        timestamp(now.Add(completionDuration)),
+		RequestCounts:    RequestCounts{Total: total},
+		Metadata:         maps.Clone(params.Metadata),
+		Owner:            params.Owner,
+	}
+	m.mu.Lock()
+	m.batches[b.ID] = b
+	m.persistLocked(b)
+	m.pending = append(m.pending, b.ID)
+	snapshot := b.clone()
+	m.mu.Unlock()
+	m.signal()
+	return snapshot, nil
+}
+
+// Get returns the batch with the given ID.
+func (m *Manager) Get(id string) (*Batch, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b := m.batches[id]
+	if b == nil {
+		return nil, ErrNotFound
+	}
+	return b.clone(), nil
+}
+
+// List returns the batches targeting one of endpoints, newest first.
+func (m *Manager) List(endpoints ...string) []*Batch {
+	m.mu.Lock()
+	out := make([]*Batch, 0, len(m.batches))
+	for _, b := range m.batches {
+		if slices.Contains(endpoints, b.Endpoint) {
+			out = append(out, b.clone())
+		}
+	}
+	m.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt != out[j].CreatedAt {
+			return out[i].CreatedAt > out[j].CreatedAt
+		}
+		return out[i].ID > out[j].ID
+	})
+	return out
+}
+
+// Cancel requests cancellation of a batch. Requests already running are aborted, and
+// results collected so far are published when the batch reaches the cancelled state.
+func (m *Manager) Cancel(id string) (*Batch, error) {
+	m.mu.Lock()
+	b := m.batches[id]
+	if b == nil {
+		m.mu.Unlock()
+		return nil, ErrNotFound
+	}
+	switch b.Status {
+	case StatusValidating, StatusInProgress:
+		b.Status = StatusCancelling
+		b.CancellingAt = timestamp(time.Now())
+		m.persistLocked(b)
+		if cancel := m.cancels[id]; cancel != nil {
+			cancel()
+		}
+	case StatusCancelling:
+	default:
+		m.mu.Unlock()
+		return nil, fmt.Errorf("%w: cannot cancel a batch with status %q", ErrInvalidRequest, b.Status)
+	}
+	snapshot := b.clone()
+	m.mu.Unlock()
+	return snapshot, nil
+}
+
+func (m *Manager) signal() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) loop() {
+	defer m.wg.Done()
+	for {
+		m.mu.Lock()
+		var id string
+		if len(m.pending) > 0 {
+			id = m.pending[0]
+			m.pending = m.pending[1:]
+		}
+		m.mu.Unlock()
+		if id == "" {
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-m.wake:
+			}
+			continue
+		}
+		m.process(id)
+		if m.ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// requestLine is one entry of a batch input file.
+type requestLine struct {
+	CustomID string          `json:"custom_id"`
+	Method   string          `json:"method"`
+	URL      string          `json:"url"`
+	Body     json.RawMessage `json:"body"`
+}
+
+func (m *Manager) process(id string) {
+	jobCtx, cancel := context.WithCancel(m.ctx)
+	defer cancel()
+	m.mu.Lock()
+	b := m.batches[id]
+	if b == nil || b.terminal() {
+		m.mu.Unlock()
+		return
+	}
+	m.cancels[id] = cancel
+	snapshot := b.clone()
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		delete(m.cancels, id)
+		m.mu.Unlock()
+	}()
+
+	exec := m.executor(snapshot.Endpoint)
+	if exec == nil && !snapshot.terminal() && snapshot.Status != StatusCancelling {
+		m.fail(snapshot, []ErrorData{{Code: "unsupported_endpoint", Message: fmt.Sprintf("The endpoint %s is not available.", snapshot.Endpoint)}})
+		return
+	}
+	if snapshot.Status == StatusValidating || snapshot.Status == StatusInProgress {
+		lines, errs := m.readRequests(snapshot)
+		if len(errs) > 0 {
+			m.fail(snapshot, errs)
+			return
+		}
+		snapshot = m.update(id, func(b *Batch) {
+			if b.Status == StatusValidating {
+				b.Status = StatusInProgress
+				b.InProgressAt = timestamp(time.Now())
+			}
+			b.RequestCounts.Total = len(lines)
+		})
+		if snapshot.Status == StatusInProgress {
+			m.execute(jobCtx, snapshot, lines, exec)
+		}
+	}
+	if m.ctx.Err() != nil {
+		// Shutting down: keep the persisted state so the batch resumes on restart.
+		return
+	}
+	m.finalize(id)
+}
+
+// fail marks a batch that could not run as failed with errs.
+func (m *Manager) fail(snapshot *Batch, errs []ErrorData) {
+	final := snapshot.clone()
+	final.Status = StatusFailed
+	counts := m.countResults(final)
+	m.update(snapshot.ID, func(b *Batch) {
+		b.Status = StatusFailed
+		b.FailedAt = timestamp(time.Now())
+		b.Errors = &Errors{Object: "list", Data: errs}
+		b.ResultCounts = counts
+	})
+}
+
+// readRequests parses and validates the input file of b.
+func (m *Manager) readRequests(b *Batch) ([]requestLine, []ErrorData) {
+	fail := func(code, message string, line int) []ErrorData {
+		data := ErrorData{Code: code, Message: message}
+		if line > 0 {
+			data.Line = &line
+		}
+		return []ErrorData{data}
+	}
+	f, err := os.Open(m.FilePath(b.InputFileID))
+	if err != nil {
+		return nil, fail("invalid_file", fmt.Sprintf("input file %s could not be read", b.InputFileID), 0)
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), int(m.maxFileSize.Load())+1)
+	var lines []requestLine
+	seen := make(map[string]bool)
+	var errs []ErrorData
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		var line requestLine
+		if errDecode := json.Unmarshal(raw, &line); errDecode != nil {
+			errs = append(errs, fail("invalid_json_line", "This line is not parseable as valid JSON.", lineNo)...)
+			continue
+		}
+		switch {
+		case strings.TrimSpace(line.CustomID) == "":
+			errs = append(errs, fail("missing_required_parameter", "custom_id is required.", lineNo)...)
+		case seen[line.CustomID]:
+			errs = append(errs, fail("duplicate_custom_id", fmt.Sprintf("The custom_id %q is used more than once.", line.CustomID), lineNo)...)
+		case !strings.EqualFold(line.Method, "POST"):
+			errs = append(errs, fail("invalid_method", "Only POST requests are supported.", lineNo)...)
+		case line.URL != b.Endpoint:
+			errs = append(errs, fail("mismatched_endpoint", fmt.Sprintf("The url %q does not match the batch endpoint %q.", line.URL, b.Endpoint), lineNo)...)
+		case !json.Valid(line.Body) || !bytes.HasPrefix(bytes.TrimSpace(line.Body), []byte("{")):
+			errs = append(errs, fail("invalid_request", "body must be a JSON object.", lineNo)...)
+		default:
+			seen[line.CustomID] = true
+			lines = append(lines, line)
+		}
+	}
+	if errScan := scanner.Err(); errScan != nil {
+		return nil, fail("invalid_file", fmt.Sprintf("input file could not be read: %v", errScan), 0)
+	}
+	if len(errs) == 0 && len(lines) == 0 {
+		return nil, fail("empty_file", "The input file contains no requests.", 0)
+	}
+	return lines, errs
+}
+
+// execute runs the outstanding lines of a batch. Lines already recorded by an earlier run
+// are skipped, so an interrupted batch picks up where it stopped.
+func (m *Manager) execute(ctx context.Context, b *Batch, lines []requestLine, exec Executor) {
+	done := make(map[string]bool)
+	completed := m.recordedIDs(b.ID, "output", done)
+	failed := m.recordedIDs(b.ID, "errors", done)
+	m.update(b.ID, func(b *Batch) {
+		b.RequestCounts.Completed = completed
+		b.RequestCounts.Failed = failed
+	})
+
+	output, errOut := os.OpenFile(m.partialPath(b.ID, "output"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if errOut != nil {
+		log.Errorf("batch: open output for %s: %v", b.ID, errOut)
+		return
+	}
+	defer func() { _ = output.Close() }()
+	errorsFile, errErr := os.OpenFile(m.partialPath(b.ID, "errors"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if errErr != nil {
+		log.Errorf("batch: open error output for %s: %v", b.ID, errErr)
+		return
+	}
+	defer func() { _ = errorsFile.Close() }()
+
+	var deadline time.Time
+	if b.ExpiresAt != nil {
+		deadline = time.Unix(*b.ExpiresAt, 0)
+	} else {
+		deadline = time.Unix(b.CreatedAt, 0).Add(completionDuration)
+	}
+	runCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	// done is read-only while requests run; results recorded by this run go to recorded.
+	// Request counts are persisted at most once per countPersistInterval: the partial result
+	// files are authoritative, and a resumed batch recounts them.
+	var writeMu sync.Mutex
+	recorded := make(map[string]bool)
+	var persistedAt time.Time
+	defer m.persist(b.ID)
+	record := func(line requestLine, status int, body []byte) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		target := output
+		if status < 200 || status >= 300 {
+			target = errorsFile
+		}
+		if _, err := target.Write(append(resultLine(line.CustomID, status, body), '\n')); err != nil {
+			log.Errorf("batch: write result for %s: %v", b.ID, err)
+			return
+		}
+		recorded[line.CustomID] = true
+		m.amend(b.ID, func(b *Batch) {
+			if target == output {
+				b.RequestCounts.Completed++
+			} else {
+				b.RequestCounts.Failed++
+			}
+		})
+		if now := time.Now(); now.Sub(persistedAt) >= countPersistInterval {
+			persistedAt = now
+			m.persist(b.ID)
+		}
+	}
+
+	lim := &limiter{freed: make(chan struct{}, 1)}
+	var wg sync.WaitGroup
+	for _, line := range lines {
+		if done[line.CustomID] {
+			continue
+		}
+		if !m.acquire(runCtx, lim) {
+			break
+		}
+		wg.Add(1)
+		go func(line requestLine) {
+			defer wg.Done()
+			defer lim.release()
+			status, body := exec(runCtx, b.Owner, line.Body)
+			if runCtx.Err() != nil {
+				return
+			}
+			record(line, status, body)
+		}(line)
+	}
+	wg.Wait()
+
+	if ctx.Err() == nil && runCtx.Err() != nil {
+		// The completion window elapsed: report the remaining requests as expired.
+		for _, line := range lines {
+			if !done[line.CustomID] && !recorded[line.CustomID] {
+				record(line, 0, nil)
+			}
+		}
+		m.update(b.ID, func(b *Batch) {
+			b.Status = StatusExpired
+		})
+	}
+}
+
+// recordedIDs adds the custom IDs found in a partial result file to done and returns how
+// many there were.
+func (m *Manager) recordedIDs(id, kind string, done map[string]bool) int {
+	data, err := os.ReadFile(m.partialPath(id, kind))
+	if err != nil {
+		return 0
+	}
+	count := 0
+	for _, raw := range bytes.Split(data, []byte("\n")) {
+		var entry struct {
+			CustomID string `json:"custom_id"`
+		}
+		if json.Unmarshal(raw, &entry) != nil || entry.CustomID == "" {
+			continue
+		}
+		done[entry.CustomID] = true
+		count++
+	}
+	return count
+}
+
+// resultLine renders one output line. A zero status marks a request that expired before
+// it was executed.
+func resultLine(customID string, status int, body []byte) []byte {
+	type response struct {
+		StatusCode int             `json:"status_code"`
+		RequestID  string          `json:"request_id"`
+		Body       json.RawMessage `json:"body"`
+	}
+	type lineError struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	out := struct {
+		ID       string     `json:"id"`
+		CustomID string     `json:"custom_id"`
+		Response *response  `json:"response"`
+		Error    *lineError `json:"error"`
+	}{
+		ID:       "batch_req_" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		CustomID: customID,
+	}
+	if status == 0 {
+		out.Error = &lineError{Code: CodeExpired, Message: "This request could not be executed before the completion window expired."}
+	} else {
+		if !json.Valid(body) {
+			body, _ = json.Marshal(string(body))
+		}
+		out.Response = &response{
+			StatusCode: status,
+			RequestID:  "req_" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+			Body:       body,
+		}
+	}
+	data, _ := json.Marshal(out)
+	return data
+}
+
+// finalize publishes partial results as files and moves the batch to its terminal state.
+func (m *Manager) finalize(id string) {
+	current := m.update(id, func(b *Batch) {
+		if b.Status == StatusInProgress {
+			b.Status = StatusFinalizing
+			b.FinalizingAt = timestamp(time.Now())
+		}
+	})
+	if current == nil || current.terminal() && current.Status != StatusExpired {
+		return
+	}
+
+	purpose := PurposeBatchOutput
+	if input, err := m.GetFile(current.InputFileID); err == nil && input.Purpose == PurposeMessageBatch {
+		purpose = PurposeMessageBatchOutput
+	}
+	outputID := m.publishPartial(id, "output", purpose, current.Owner)
+	errorID := m.publishPartial(id, "errors", purpose, current.Owner)
+	final := current.clone()
+	final.Status = finalStatus(current.Status)
+	if outputID != "" {
+		final.OutputFileID = &outputID
+	}
+	if errorID != "" {
+		final.ErrorFileID = &errorID
+	}
+	counts := m.countResults(final)
+	m.update(id, func(b *Batch) {
+		b.OutputFileID, b.ErrorFileID = final.OutputFileID, final.ErrorFileID
+		b.Status = finalStatus(b.Status)
+		b.ResultCounts = counts
+		now := timestamp(time.Now())
+		switch b.Status {
+		case StatusCancelled:
+			b.CancelledAt = now
+		case StatusExpired:
+			b.ExpiredAt = now
+		default:
+			b.CompletedAt = now
+		}
+	})
+}
+
+// finalStatus returns the terminal status a finalizing batch in status reaches.
+func finalStatus(status string) string {
+	switch status {
+	case StatusCancelling:
+		return StatusCancelled
+	case StatusExpired:
+		return StatusExpired
+	}
+	return StatusCompleted
+}
+
+// publishPartial turns a non-empty partial result file into a stored file of owner and
+// returns its ID.
+func (m *Manager) publishPartial(id, kind, purpose, owner string) string {
+	path := m.partialPath(id, kind)
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	if info.Size() == 0 {
+		_ = os.Remove(path)
+		return ""
+	}
+	file, err := m.adoptFile(path, fmt.Sprintf("%s_%s.jsonl", id, kind), purpose, owner)
+	if err != nil {
+		log.Errorf("batch: publish %s results for %s: %v", kind, id, err)
+		return ""
+	}
+	return file.ID
+}
+
+// limiter tracks running batch requests.
+type limiter struct {
+	mu     sync.Mutex
+	active int64
+	freed  chan struct{}
+}
+
+func (l *limiter) release() {
+	l.mu.Lock()
+	l.active--
+	l.mu.Unlock()
+	select {
+	case l.freed <- struct{}{}:
+	default:
+	}
+}
+
+// acquire waits for a free slot. The limit is re-evaluated periodically so batch work
+// backs off while interactive traffic is in flight.
+func (m *Manager) acquire(ctx context.Context, l *limiter) bool {
+	var ticker *time.Ticker
+	for {
+		l.mu.Lock()
+		if l.active < m.limit() {
+			l.active++
+			l.mu.Unlock()
+			return true
+		}
+		l.mu.Unlock()
+		if ticker == nil {
+			ticker = time.NewTicker(loadPollInterval)
+			defer ticker.Stop()
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-l.freed:
+		case <-ticker.C:
+		}
+	}
+}
+
+// limit returns the effective concurrency after yielding to interactive requests.
+func (m *Manager) limit() int64 {
+	limit := m.concurrency.Load()
+	if m.load != nil {
+		limit -= m.load()
+	}
+	return max(limit, 1)
+}
+
+func timestamp(t time.Time) *int64 {
+	v := t.Unix()
+	return &v
+}
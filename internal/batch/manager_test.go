@@ -0,0 +1,316 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+func echoExecutor(ctx context.Context, owner string, body []byte) (int, []byte) {
+	if gjson.GetBytes(body, "fail").Bool() {
+		return http.StatusBadRequest, []byte(`{"error":{"message":"bad"}}`)
+	}
+	return http.StatusOK, []byte(`{"model":"` + gjson.GetBytes(body, "model").String() + `"}`)
+}
+
+func startManager(t *testing.T, dir string, exec Executor, opts Options) *Manager {
+	t.Helper()
+	m, err := NewManager(dir, opts)
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	for _, endpoint := range []string{"/v1/chat/completions", "/v1/responses", "/v1/embeddings"} {
+		m.Handle(endpoint, exec)
+	}
+	m.Start()
+	return m
+}
+
+func waitForStatus(t *testing.T, m *Manager, id string, statuses ...string) *Batch {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		b, err := m.Get(id)
+		if err != nil {
+			t.Fatalf("get batch: %v", err)
+		}
+		for _, status := range statuses {
+			if b.Status == status {
+				return b
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	b, _ := m.Get(id)
+	t.Fatalf("batch %s did not reach %v, status %s", id, statuses, b.Status)
+	return nil
+}
+
+func uploadBatchFile(t *testing.T, m *Manager, lines ...string) *File {
+	t.Helper()
+	file, err := m.CreateFile("", "input.jsonl", PurposeBatch, strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatalf("create file: %v", err)
+	}
+	return file
+}
+
+func TestManagerRunsBatchAndWritesResults(t *testing.T) {
+	m := startManager(t, t.TempDir(), echoExecutor, Options{Concurrency: 2})
+	defer m.Close()
+
+	file := uploadBatchFile(t, m,
+		`{"custom_id":"a","method":"POST","url":"/v1/chat/completions","body":{"model":"m1"}}`,
+		`{"custom_id":"b","method":"POST","url":"/v1/chat/completions","body":{"model":"m2","fail":true}}`,
+		`{"custom_id":"c","method":"POST","url":"/v1/chat/completions","body":{"model":"m3"}}`,
+	)
+	created, err := m.Create(CreateParams{InputFileID: file.ID, Endpoint: "/v1/chat/completions", CompletionWindow: "24h", Metadata: map[string]string{"k": "v"}})
+	if err != nil {
+		t.Fatalf("create batch: %v", err)
+	}
+	b := waitForStatus(t, m, created.ID, StatusCompleted)
+	if b.RequestCounts != (RequestCounts{Total: 3, Completed: 2, Failed: 1}) {
+		t.Fatalf("unexpected counts %+v", b.RequestCounts)
+	}
+	if b.ResultCounts == nil || *b.ResultCounts != (ResultCounts{Succeeded: 2, Failed: 1}) {
+		t.Fatalf("unexpected result counts %+v", b.ResultCounts)
+	}
+	if b.OutputFileID == nil || b.ErrorFileID == nil {
+		t.Fatalf("expected output and error files, got %+v", b)
+	}
+	output, err := os.ReadFile(m.FilePath(*b.OutputFileID))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	if got := bytes.Count(output, []byte("\n")); got != 2 {
+		t.Fatalf("output lines = %d: %s", got, output)
+	}
+	errorsData, _ := os.ReadFile(m.FilePath(*b.ErrorFileID))
+	if gjson.GetBytes(errorsData, "custom_id").String() != "b" || gjson.GetBytes(errorsData, "response.status_code").Int() != http.StatusBadRequest {
+		t.Fatalf("unexpected error file: %s", errorsData)
+	}
+	if outFile, _ := m.GetFile(*b.OutputFileID); outFile == nil || outFile.Purpose != PurposeBatchOutput {
+		t.Fatalf("output file metadata missing: %+v", outFile)
+	}
+	results, err := m.Results(created.ID)
+	if err != nil || len(results) != 3 {
+		t.Fatalf("results: %+v %v", results, err)
+	}
+	if results[1].CustomID != "b" || results[1].StatusCode != http.StatusBadRequest || results[2].StatusCode != http.StatusOK {
+		t.Fatalf("results should follow input order: %+v", results)
+	}
+}
+
+func TestManagerRejectsInvalidInput(t *testing.T) {
+	m := startManager(t, t.TempDir(), echoExecutor, Options{})
+	defer m.Close()
+
+	file := uploadBatchFile(t, m,
+		`{"custom_id":"a","method":"POST","url":"/v1/embeddings","body":{"model":"m"}}`,
+		`{"custom_id":"a","method":"POST","url":"/v1/embeddings","body":{"model":"m"}}`,
+	)
+	if _, err := m.Create(CreateParams{InputFileID: file.ID, Endpoint: "/v1/images/generations", CompletionWindow: "24h"}); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("expected unsupported endpoint error, got %v", err)
+	}
+	created, err := m.Create(CreateParams{InputFileID: file.ID, Endpoint: "/v1/embeddings", CompletionWindow: "24h"})
+	if err != nil {
+		t.Fatalf("create batch: %v", err)
+	}
+	b := waitForStatus(t, m, created.ID, StatusFailed)
+	if b.Errors == nil || len(b.Errors.Data) != 1 || b.Errors.Data[0].Code != "duplicate_custom_id" || *b.Errors.Data[0].Line != 2 {
+		t.Fatalf("unexpected validation errors %+v", b.Errors)
+	}
+	if b.ResultCounts == nil || *b.ResultCounts != (ResultCounts{Failed: 1}) {
+		t.Fatalf("unexpected result counts %+v", b.ResultCounts)
+	}
+}
+
+func TestManagerResumesAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	release := make(chan struct{})
+	var calls atomic.Int64
+	blocking := func(ctx context.Context, owner string, body []byte) (int, []byte) {
+		if calls.Add(1) == 1 {
+			return http.StatusOK, []byte(`{}`)
+		}
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return http.StatusOK, []byte(`{}`)
+	}
+	m := startManager(t, dir, blocking, Options{Concurrency: 1})
+	file := uploadBatchFile(t, m,
+		`{"custom_id":"a","method":"POST","url":"/v1/responses","body":{}}`,
+		`{"custom_id":"b","method":"POST","url":"/v1/responses","body":{}}`,
+		`{"custom_id":"c","method":"POST","url":"/v1/responses","body":{}}`,
+	)
+	created, err := m.Create(CreateParams{InputFileID: file.ID, Endpoint: "/v1/responses", CompletionWindow: "24h"})
+	if err != nil {
+		t.Fatalf("create batch: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for calls.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	// Stopping mid-run keeps the job in progress so a new manager resumes it.
+	m.Close()
+
+	calls.Store(1)
+	close(release)
+	resumed := startManager(t, dir, blocking, Options{Concurrency: 1})
+	defer resumed.Close()
+	b := waitForStatus(t, resumed, created.ID, StatusCompleted)
+	if b.RequestCounts.Completed != 3 {
+		t.Fatalf("expected 3 completed after resume, got %+v", b.RequestCounts)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("resume should only run the 2 outstanding requests, ran %d", got-1)
+	}
+
+	if _, err = resumed.Cancel(created.ID); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("cancelling a completed batch should fail, got %v", err)
+	}
+}
+
+func TestManagerYieldsToInteractiveLoad(t *testing.T) {
+	m := &Manager{load: func() int64 { return 3 }}
+	m.SetLimits(4, 0)
+	if got := m.limit(); got != 1 {
+		t.Fatalf("limit = %d, want 1", got)
+	}
+	m.load = func() int64 { return 10 }
+	if got := m.limit(); got != 1 {
+		t.Fatalf("limit should not drop below 1, got %d", got)
+	}
+}
+
+func TestCreateFileEnforcesSizeLimit(t *testing.T) {
+	m := startManager(t, t.TempDir(), echoExecutor, Options{MaxFileSizeMB: 1})
+	defer m.Close()
+	if _, err := m.CreateFile("", "big.jsonl", PurposeBatch, bytes.NewReader(make([]byte, 1<<20+1))); !errors.Is(err, ErrFileTooLarge) {
+		t.Fatalf("expected ErrFileTooLarge, got %v", err)
+	}
+	files, _ := m.ListFiles("")
+	if len(files) != 0 {
+		t.Fatalf("rejected upload should not be listed: %+v", files)
+	}
+}
+
+func TestManagerCancelRunningBatch(t *testing.T) {
+	started := make(chan struct{}, 4)
+	blocking := func(ctx context.Context, owner string, body []byte) (int, []byte) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return http.StatusOK, []byte(`{}`)
+	}
+	m := startManager(t, t.TempDir(), blocking, Options{Concurrency: 1})
+	defer m.Close()
+	file := uploadBatchFile(t, m,
+		`{"custom_id":"a","method":"POST","url":"/v1/chat/completions","body":{}}`,
+		`{"custom_id":"b","method":"POST","url":"/v1/chat/completions","body":{}}`,
+	)
+	created, err := m.Create(CreateParams{InputFileID: file.ID, Endpoint: "/v1/chat/completions", CompletionWindow: "24h"})
+	if err != nil {
+		t.Fatalf("create batch: %v", err)
+	}
+	<-started
+	cancelling, err := m.Cancel(created.ID)
+	if err != nil || cancelling.CancellingAt == nil {
+		t.Fatalf("cancel: %+v %v", cancelling, err)
+	}
+	b := waitForStatus(t, m, created.ID, StatusCancelled)
+	if b.CancelledAt == nil || b.RequestCounts.Completed != 0 || b.OutputFileID != nil {
+		t.Fatalf("unexpected cancelled batch %+v", b)
+	}
+	results, err := m.Results(created.ID)
+	if err != nil {
+		t.Fatalf("results: %v", err)
+	}
+	if len(results) != 2 || results[0].CustomID != "a" || results[0].Code != CodeCancelled || results[1].Code != CodeCancelled {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if b.ResultCounts == nil || *b.ResultCounts != (ResultCounts{Cancelled: 2}) {
+		t.Fatalf("unexpected result counts %+v", b.ResultCounts)
+	}
+}
+
+func TestManagerPersistsResultCounts(t *testing.T) {
+	dir := t.TempDir()
+	m := startManager(t, dir, echoExecutor, Options{})
+	file := uploadBatchFile(t, m,
+		`{"custom_id":"a","method":"POST","url":"/v1/embeddings","body":{"model":"m"}}`,
+		`{"custom_id":"b","method":"POST","url":"/v1/embeddings","body":{"model":"m","fail":true}}`,
+	)
+	created, err := m.Create(CreateParams{InputFileID: file.ID, Endpoint: "/v1/embeddings", CompletionWindow: "24h"})
+	if err != nil {
+		t.Fatalf("create batch: %v", err)
+	}
+	b := waitForStatus(t, m, created.ID, StatusCompleted)
+	m.Close()
+
+	state, err := os.ReadFile(m.batchPath(b.ID))
+	if err != nil || gjson.GetBytes(state, "result_counts.succeeded").Int() != 1 || gjson.GetBytes(state, "result_counts.failed").Int() != 1 {
+		t.Fatalf("result counts not persisted: %s %v", state, err)
+	}
+	if api, _ := json.Marshal(b); gjson.GetBytes(api, "result_counts").Exists() {
+		t.Fatalf("result counts leaked into the batch object: %s", api)
+	}
+
+	// State written before result counts were persisted is counted once on restore.
+	legacy, _ := sjson.DeleteBytes(state, "result_counts")
+	if err = os.WriteFile(m.batchPath(b.ID), legacy, 0o600); err != nil {
+		t.Fatalf("write legacy state: %v", err)
+	}
+	resumed := startManager(t, dir, echoExecutor, Options{})
+	defer resumed.Close()
+	got, _ := resumed.Get(b.ID)
+	if got == nil || got.ResultCounts == nil || *got.ResultCounts != (ResultCounts{Succeeded: 1, Failed: 1}) {
+		t.Fatalf("result counts not restored: %+v", got)
+	}
+	if state, _ = os.ReadFile(resumed.batchPath(b.ID)); !gjson.GetBytes(state, "result_counts").Exists() {
+		t.Fatalf("backfilled result counts not persisted: %s", state)
+	}
+}
+
+func TestManagerKeepsOwnerAcrossRestart(t *testing.T) {
+	dir := t.TempDir()
+	m := startManager(t, dir, echoExecutor, Options{})
+
+	file, err := m.CreateFile("owner-a", "input.jsonl", PurposeBatch, strings.NewReader(`{"custom_id":"a","method":"POST","url":"/v1/embeddings","body":{"model":"m"}}`))
+	if err != nil {
+		t.Fatalf("create file: %v", err)
+	}
+	if _, err = m.Create(CreateParams{InputFileID: file.ID, Endpoint: "/v1/embeddings", CompletionWindow: "24h", Owner: "owner-b"}); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("expected another owner's input file to be rejected, got %v", err)
+	}
+	created, err := m.Create(CreateParams{InputFileID: file.ID, Endpoint: "/v1/embeddings", CompletionWindow: "24h", Owner: "owner-a"})
+	if err != nil {
+		t.Fatalf("create batch: %v", err)
+	}
+	b := waitForStatus(t, m, created.ID, StatusCompleted)
+	m.Close()
+
+	resumed := startManager(t, dir, echoExecutor, Options{})
+	defer resumed.Close()
+	if got, _ := resumed.Get(b.ID); got == nil || got.Owner != "owner-a" {
+		t.Fatalf("batch owner not restored: %+v", got)
+	}
+	if got, _ := resumed.GetFile(*b.OutputFileID); got == nil || got.Owner != "owner-a" {
+		t.Fatalf("output file should inherit the batch owner: %+v", got)
+	}
+	data, _ := os.ReadFile(resumed.fileMetaPath(file.ID))
+	if gjson.GetBytes(data, "owner").String() != "owner-a" {
+		t.Fatalf("owner not persisted: %s", data)
+	}
+}
@@ -0,0 +1,152 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Reasons reported for requests that did not run.
+const (
+	CodeExpired   = "batch_expired"
+	CodeCancelled = "batch_cancelled"
+	CodeFailed    = "batch_failed"
+)
+
+// Result is the outcome of one request of a finished batch.
+type Result struct {
+	CustomID string
+	// StatusCode and Body hold the endpoint response. StatusCode is 0 when the request
+	// did not run.
+	StatusCode int
+	Body       json.RawMessage
+	// Code explains why a request did not run: CodeExpired, CodeCancelled or CodeFailed.
+	Code string
+}
+
+// Results returns the outcome of every request of a finished batch in input order.
+func (m *Manager) Results(id string) ([]Result, error) {
+	b, err := m.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !b.terminal() {
+		return nil, fmt.Errorf("%w: batch %s is still processing", ErrInvalidRequest, id)
+	}
+	return m.results(b)
+}
+
+// countResults classifies the results of b, whose final status and result files are set.
+// It returns nil when the results cannot be read.
+func (m *Manager) countResults(b *Batch) *ResultCounts {
+	results, err := m.results(b)
+	if err != nil {
+		log.Warnf("batch: count results of %s: %v", b.ID, err)
+		return nil
+	}
+	counts := &ResultCounts{}
+	for _, result := range results {
+		switch {
+		case result.StatusCode >= 200 && result.StatusCode < 300:
+			counts.Succeeded++
+		case result.Code == CodeCancelled:
+			counts.Cancelled++
+		case result.Code == CodeExpired:
+			counts.Expired++
+		default:
+			counts.Failed++
+		}
+	}
+	return counts
+}
+
+// results reads the outcome of every request of b in input order.
+func (m *Manager) results(b *Batch) ([]Result, error) {
+	var err error
+	outcomes := make(map[string]Result)
+	for _, fileID := range []*string{b.OutputFileID, b.ErrorFileID} {
+		if fileID == nil {
+			continue
+		}
+		if err = m.scanFile(*fileID, func(raw []byte) {
+			var entry struct {
+				CustomID string `json:"custom_id"`
+				Response *struct {
+					StatusCode int             `json:"status_code"`
+					Body       json.RawMessage `json:"body"`
+				} `json:"response"`
+				Error *struct {
+					Code string `json:"code"`
+				} `json:"error"`
+			}
+			if json.Unmarshal(raw, &entry) != nil || entry.CustomID == "" {
+				return
+			}
+			result := Result{CustomID: entry.CustomID}
+			if entry.Response != nil {
+				result.StatusCode = entry.Response.StatusCode
+				result.Body = entry.Response.Body
+			} else if entry.Error != nil {
+				result.Code = entry.Error.Code
+			}
+			outcomes[entry.CustomID] = result
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	missing := CodeFailed
+	switch b.Status {
+	case StatusCancelled:
+		missing = CodeCancelled
+	case StatusExpired:
+		missing = CodeExpired
+	}
+	var results []Result
+	seen := make(map[string]bool)
+	err = m.scanFile(b.InputFileID, func(raw []byte) {
+		var line struct {
+			CustomID string `json:"custom_id"`
+		}
+		if json.Unmarshal(raw, &line) != nil || line.CustomID == "" || seen[line.CustomID] {
+			return
+		}
+		seen[line.CustomID] = true
+		result, ok := outcomes[line.CustomID]
+		if !ok {
+			result = Result{CustomID: line.CustomID, Code: missing}
+		}
+		results = append(results, result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// scanFile calls fn for every non-empty line of a stored file.
+func (m *Manager) scanFile(id string, fn func(line []byte)) error {
+	f, err := os.Open(m.FilePath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("batch: open file %s: %w", id, err)
+	}
+	defer func() { _ = f.Close() }()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), int(m.maxFileSize.Load())+1)
+	for scanner.Scan() {
+		if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+			fn(line)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("batch: read file %s: %w", id, err)
+	}
+	return nil
+}
@@ -1,11 +1,18 @@
 package cache
 
 import (
+	"context"
 	"crypto/sha256"
+	"database/sql"
 	"encoding/hex"
+	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	log "github.com/sirupsen/logrus"
 )
 
 // SignatureEntry holds a cached thinking signature with timestamp
@@ -28,71 +35,153 @@ const (
 	CacheCleanupInterval = 10 * time.Minute
 )
 
-// signatureCache stores signatures by model group -> textHash -> SignatureEntry
-var signatureCache sync.Map
+// SignatureStore is a signature cache backend. Keys are model group and text hash.
+// Implementations handle expiry and size limits and must be safe for concurrent use.
+type SignatureStore interface {
+	// Get returns the signature and refreshes its expiration (sliding expiration).
+	Get(group, key string) (string, bool)
+	// Put stores the signature, replacing any previous one.
+	Put(group, key, signature string)
+	// Clear removes the entries of a model group, or every entry when group is empty.
+	Clear(group string)
+	// Purge removes expired entries.
+	Purge()
+	// Len returns the number of cached signatures.
+	Len() int
+	// Close releases backend resources.
+	Close() error
+}
 
-// cacheCleanupOnce ensures the background cleanup goroutine starts only once
-var cacheCleanupOnce sync.Once
+// Signature cache backend names accepted in SignatureCacheConfig.Backend.
+const (
+	SignatureBackendMemory   = "memory"
+	SignatureBackendFile     = "file"
+	SignatureBackendPostgres = "postgres"
+)
+
+// DefaultSignatureCacheMaxEntries bounds the cache when no limit is configured.
+const DefaultSignatureCacheMaxEntries = 10000
 
-// groupCache is the inner map type
-type groupCache struct {
+// signatureBackend holds the active SignatureStore and the configuration it was built from.
+var signatureBackend struct {
 	mu      sync.RWMutex
-	entries map[string]SignatureEntry
+	store   SignatureStore
+	name    string
+	cfg     config.SignatureCacheConfig
+	db      *sql.DB
+	schema  string
+	applied bool
 }
 
+// signatureStats counts cache activity since process start.
+var signatureStats struct {
+	hits      atomic.Int64
+	misses    atomic.Int64
+	stores    atomic.Int64
+	evictions atomic.Int64
+}
+
+// cacheCleanupOnce ensures the background cleanup goroutine starts only once
+var cacheCleanupOnce sync.Once
+
 // hashText creates a stable, Unicode-safe key from text content
 func hashText(text string) string {
 	h := sha256.Sum256([]byte(text))
 	return hex.EncodeToString(h[:])[:SignatureTextHashLen]
 }
 
-// getOrCreateGroupCache gets or creates a cache bucket for a model group
-func getOrCreateGroupCache(groupKey string) *groupCache {
+// activeSignatureStore returns the configured store, creating the default memory store
+// on first use.
+func activeSignatureStore() SignatureStore {
 	// Start background cleanup on first access
 	cacheCleanupOnce.Do(startCacheCleanup)
 
-	if val, ok := signatureCache.Load(groupKey); ok {
-		return val.(*groupCache)
+	signatureBackend.mu.RLock()
+	store := signatureBackend.store
+	signatureBackend.mu.RUnlock()
+	if store != nil {
+		return store
+	}
+	signatureBackend.mu.Lock()
+	defer signatureBackend.mu.Unlock()
+	if signatureBackend.store == nil {
+		signatureBackend.store = NewMemorySignatureStore(SignatureCacheTTL, DefaultSignatureCacheMaxEntries)
+		signatureBackend.name = SignatureBackendMemory
 	}
-	sc := &groupCache{entries: make(map[string]SignatureEntry)}
-	actual, _ := signatureCache.LoadOrStore(groupKey, sc)
-	return actual.(*groupCache)
+	return signatureBackend.store
+}
+
+// SetSignatureCacheDatabase registers an existing PostgreSQL connection, such as the one
+// of the PostgreSQL token store, for the postgres backend when no DSN is configured.
+// schema optionally qualifies the cache table.
+func SetSignatureCacheDatabase(db *sql.DB, schema string) {
+	signatureBackend.mu.Lock()
+	defer signatureBackend.mu.Unlock()
+	signatureBackend.db = db
+	signatureBackend.schema = schema
+}
+
+// ConfigureSignatureCache switches the cache to the configured backend. Cached signatures
+// are not carried over when the backend changes. On error the current backend is kept.
+func ConfigureSignatureCache(cfg config.SignatureCacheConfig) error {
+	signatureBackend.mu.Lock()
+	defer signatureBackend.mu.Unlock()
+	if signatureBackend.applied && signatureBackend.cfg == cfg {
+		return nil
+	}
+	maxEntries := cfg.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = DefaultSignatureCacheMaxEntries
+	}
+	var store SignatureStore
+	var err error
+	name := strings.ToLower(strings.TrimSpace(cfg.Backend))
+	switch name {
+	case "", SignatureBackendMemory:
+		name = SignatureBackendMemory
+		store = NewMemorySignatureStore(SignatureCacheTTL, maxEntries)
+	case SignatureBackendFile:
+		store, err = NewFileSignatureStore(cfg.Path, SignatureCacheTTL, maxEntries)
+	case SignatureBackendPostgres:
+		db, schema := signatureBackend.db, signatureBackend.schema
+		if strings.TrimSpace(cfg.DSN) != "" {
+			db, schema = nil, ""
+		} else if db == nil {
+			return fmt.Errorf("signature cache: postgres backend needs a DSN or the PostgreSQL token store")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		store, err = NewPostgresSignatureStore(ctx, db, cfg.DSN, schema, cfg.Table, SignatureCacheTTL, maxEntries)
+		cancel()
+	default:
+		return fmt.Errorf("signature cache: unknown backend %q", cfg.Backend)
+	}
+	if err != nil {
+		return err
+	}
+	if signatureBackend.store != nil {
+		if errClose := signatureBackend.store.Close(); errClose != nil {
+			log.Warnf("signature cache: close previous backend: %v", errClose)
+		}
+	}
+	signatureBackend.store = store
+	signatureBackend.name = name
+	signatureBackend.cfg = cfg
+	signatureBackend.applied = true
+	return nil
 }
 
 // startCacheCleanup launches a background goroutine that periodically
-// removes caches where all entries have expired.
+// removes expired entries.
 func startCacheCleanup() {
 	go func() {
 		ticker := time.NewTicker(CacheCleanupInterval)
 		defer ticker.Stop()
 		for range ticker.C {
-			purgeExpiredCaches()
+			activeSignatureStore().Purge()
 		}
 	}()
 }
 
-// purgeExpiredCaches removes caches with no valid (non-expired) entries.
-func purgeExpiredCaches() {
-	now := time.Now()
-	signatureCache.Range(func(key, value any) bool {
-		sc := value.(*groupCache)
-		sc.mu.Lock()
-		// Remove expired entries
-		for k, entry := range sc.entries {
-			if now.Sub(entry.Timestamp) > SignatureCacheTTL {
-				delete(sc.entries, k)
-			}
-		}
-		isEmpty := len(sc.entries) == 0
-		sc.mu.Unlock()
-		// Remove cache bucket if empty
-		if isEmpty {
-			signatureCache.Delete(key)
-		}
-		return true
-	})
-}
-
 // CacheSignature stores a thinking signature for a given model group and text.
 // Used for Claude models that require signed thinking blocks in multi-turn conversations.
 func CacheSignature(modelName, text, signature string) {
@@ -103,16 +192,8 @@ func CacheSignature(modelName, text, signature string) {
 		return
 	}
 
-	groupKey := GetModelGroup(modelName)
-	textHash := hashText(text)
-	sc := getOrCreateGroupCache(groupKey)
-	sc.mu.Lock()
-	defer sc.mu.Unlock()
-
-	sc.entries[textHash] = SignatureEntry{
-		Signature: signature,
-		Timestamp: time.Now(),
-	}
+	activeSignatureStore().Put(GetModelGroup(modelName), hashText(text), signature)
+	signatureStats.stores.Add(1)
 }
 
 // GetCachedSignature retrieves a cached signature for a given model group and text.
@@ -120,62 +201,61 @@ func CacheSignature(modelName, text, signature string) {
 func GetCachedSignature(modelName, text string) string {
 	groupKey := GetModelGroup(modelName)
 
-	if text == "" {
-		if groupKey == "gemini" {
-			return "skip_thought_signature_validator"
-		}
-		return ""
-	}
-	val, ok := signatureCache.Load(groupKey)
-	if !ok {
-		if groupKey == "gemini" {
-			return "skip_thought_signature_validator"
-		}
-		return ""
-	}
-	sc := val.(*groupCache)
-
-	textHash := hashText(text)
-
-	now := time.Now()
-
-	sc.mu.Lock()
-	entry, exists := sc.entries[textHash]
-	if !exists {
-		sc.mu.Unlock()
-		if groupKey == "gemini" {
-			return "skip_thought_signature_validator"
+	if text != "" {
+		if signature, ok := activeSignatureStore().Get(groupKey, hashText(text)); ok {
+			signatureStats.hits.Add(1)
+			return signature
 		}
-		return ""
+		signatureStats.misses.Add(1)
 	}
-	if now.Sub(entry.Timestamp) > SignatureCacheTTL {
-		delete(sc.entries, textHash)
-		sc.mu.Unlock()
-		if groupKey == "gemini" {
-			return "skip_thought_signature_validator"
-		}
-		return ""
+	if groupKey == "gemini" {
+		return "skip_thought_signature_validator"
 	}
-
-	// Refresh TTL on access (sliding expiration).
-	entry.Timestamp = now
-	sc.entries[textHash] = entry
-	sc.mu.Unlock()
-
-	return entry.Signature
+	return ""
 }
 
 // ClearSignatureCache clears signature cache for a specific model group or all groups.
 func ClearSignatureCache(modelName string) {
 	if modelName == "" {
-		signatureCache.Range(func(key, _ any) bool {
-			signatureCache.Delete(key)
-			return true
-		})
+		activeSignatureStore().Clear("")
 		return
 	}
-	groupKey := GetModelGroup(modelName)
-	signatureCache.Delete(groupKey)
+	activeSignatureStore().Clear(GetModelGroup(modelName))
+}
+
+// SignatureCacheStats reports signature cache activity since process start.
+type SignatureCacheStats struct {
+	Backend   string  `json:"backend"`
+	Entries   int     `json:"entries"`
+	Hits      int64   `json:"hits"`
+	Misses    int64   `json:"misses"`
+	Stores    int64   `json:"stores"`
+	Evictions int64   `json:"evictions"`
+	HitRate   float64 `json:"hit_rate"`
+}
+
+// SignatureCacheStatistics returns the current signature cache statistics.
+func SignatureCacheStatistics() SignatureCacheStats {
+	store := activeSignatureStore()
+	signatureBackend.mu.RLock()
+	name := signatureBackend.name
+	signatureBackend.mu.RUnlock()
+	stats := SignatureCacheStats{
+		Backend:   name,
+		Entries:   store.Len(),
+		Hits:      signatureStats.hits.Load(),
+		Misses:    signatureStats.misses.Load(),
+		Stores:    signatureStats.stores.Load(),
+		Evictions: signatureStats.evictions.Load(),
+	}
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(lookups)
+	}
+	return stats
+}
+
+func recordEvictions(n int64) {
+	signatureStats.evictions.Add(n)
 }
 
 // HasValidSignature checks if a signature is valid (non-empty and long enough)
@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const fileFlushInterval = 30 * time.Second
+
+// fileRecord is the on-disk form of one cached signature.
+type fileRecord struct {
+	Group     string    `json:"group"`
+	Key       string    `json:"key"`
+	Signature string    `json:"signature"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// FileSignatureStore serves signatures from memory and snapshots them to a JSON file, so
+// they survive restarts. Changes are written at most every fileFlushInterval and on Close.
+type FileSignatureStore struct {
+	*MemorySignatureStore
+	path  string
+	mu    sync.Mutex
+	dirty bool
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// NewFileSignatureStore loads the snapshot at path, if any, and starts the background
+// writer. An empty path defaults to "signature-cache.json" in the working directory.
+func NewFileSignatureStore(path string, ttl time.Duration, maxEntries int) (*FileSignatureStore, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		if cwd, err := os.Getwd(); err == nil {
+			path = filepath.Join(cwd, "signature-cache.json")
+		} else {
+			path = filepath.Join(os.TempDir(), "signature-cache.json")
+		}
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("signature cache: resolve path: %w", err)
+	}
+	if err = os.MkdirAll(filepath.Dir(absPath), 0o700); err != nil {
+		return nil, fmt.Errorf("signature cache: create directory: %w", err)
+	}
+	store := &FileSignatureStore{
+		MemorySignatureStore: NewMemorySignatureStore(ttl, maxEntries),
+		path:                 absPath,
+		stop:                 make(chan struct{}),
+		done:                 make(chan struct{}),
+	}
+	if err = store.load(); err != nil {
+		return nil, err
+	}
+	go store.run()
+	return store, nil
+}
+
+func (s *FileSignatureStore) load() error {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("signature cache: read snapshot: %w", err)
+	}
+	var records []fileRecord
+	if err = json.Unmarshal(data, &records); err != nil {
+		log.Warnf("signature cache: ignoring unreadable snapshot %s: %v", s.path, err)
+		return nil
+	}
+	now := time.Now()
+	for _, record := range records {
+		if now.Sub(record.Timestamp) > s.ttl || record.Signature == "" {
+			continue
+		}
+		s.MemorySignatureStore.put(record.Group, record.Key, SignatureEntry{Signature: record.Signature, Timestamp: record.Timestamp})
+	}
+	return nil
+}
+
+// Put stores the signature and schedules a snapshot.
+func (s *FileSignatureStore) Put(group, key, signature string) {
+	s.MemorySignatureStore.Put(group, key, signature)
+	s.markDirty()
+}
+
+// Clear removes entries and schedules a snapshot.
+func (s *FileSignatureStore) Clear(group string) {
+	s.MemorySignatureStore.Clear(group)
+	s.markDirty()
+}
+
+// Close writes the final snapshot and stops the background writer.
+func (s *FileSignatureStore) Close() error {
+	select {
+	case <-s.stop:
+		return nil
+	default:
+		close(s.stop)
+	}
+	<-s.done
+	return s.flush()
+}
+
+func (s *FileSignatureStore) markDirty() {
+	s.mu.Lock()
+	s.dirty = true
+	s.mu.Unlock()
+}
+
+func (s *FileSignatureStore) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(fileFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.flush(); err != nil {
+				log.Warnf("%v", err)
+			}
+		}
+	}
+}
+
+// flush writes the snapshot atomically when entries changed since the last write.
+// Sliding expiration refreshes are saved with the next change.
+func (s *FileSignatureStore) flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dirty {
+		return nil
+	}
+	entries := s.snapshot()
+	records := make([]fileRecord, 0, len(entries))
+	for _, entry := range entries {
+		records = append(records, fileRecord{Group: entry.group, Key: entry.key, Signature: entry.Signature, Timestamp: entry.Timestamp})
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("signature cache: encode snapshot: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("signature cache: write snapshot: %w", err)
+	}
+	if err = os.Rename(tmp, s.path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("signature cache: write snapshot: %w", err)
+	}
+	s.dirty = false
+	return nil
+}
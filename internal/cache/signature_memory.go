@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// memoryEntry is one signature held by MemorySignatureStore.
+type memoryEntry struct {
+	group string
+	key   string
+	SignatureEntry
+}
+
+// MemorySignatureStore keeps signatures in process memory. Entries are kept in least
+// recently used order; the oldest are evicted once maxEntries is reached.
+type MemorySignatureStore struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	order      *list.List
+	entries    map[string]*list.Element
+	// countEvictions reports evictions in the cache statistics. It is off when the store
+	// only fronts another backend.
+	countEvictions bool
+}
+
+// NewMemorySignatureStore creates an in-memory store. maxEntries <= 0 disables the limit.
+func NewMemorySignatureStore(ttl time.Duration, maxEntries int) *MemorySignatureStore {
+	return &MemorySignatureStore{
+		ttl:            ttl,
+		maxEntries:     maxEntries,
+		order:          list.New(),
+		entries:        make(map[string]*list.Element),
+		countEvictions: true,
+	}
+}
+
+func memoryKey(group, key string) string {
+	return group + "\x00" + key
+}
+
+// Get returns the signature and refreshes its expiration.
+func (s *MemorySignatureStore) Get(group, key string) (string, bool) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.entries[memoryKey(group, key)]
+	if !ok {
+		return "", false
+	}
+	entry := elem.Value.(*memoryEntry)
+	if now.Sub(entry.Timestamp) > s.ttl {
+		s.removeLocked(elem)
+		return "", false
+	}
+	// Refresh TTL on access (sliding expiration).
+	entry.Timestamp = now
+	s.order.MoveToFront(elem)
+	return entry.Signature, true
+}
+
+// Put stores the signature, evicting the least recently used entries over the limit.
+func (s *MemorySignatureStore) Put(group, key, signature string) {
+	s.put(group, key, SignatureEntry{Signature: signature, Timestamp: time.Now()})
+}
+
+func (s *MemorySignatureStore) put(group, key string, entry SignatureEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := memoryKey(group, key)
+	if elem, ok := s.entries[id]; ok {
+		elem.Value.(*memoryEntry).SignatureEntry = entry
+		s.order.MoveToFront(elem)
+		return
+	}
+	s.entries[id] = s.order.PushFront(&memoryEntry{group: group, key: key, SignatureEntry: entry})
+	for s.maxEntries > 0 && s.order.Len() > s.maxEntries {
+		s.removeLocked(s.order.Back())
+		if s.countEvictions {
+			recordEvictions(1)
+		}
+	}
+}
+
+// Clear removes the entries of a model group, or every entry when group is empty.
+func (s *MemorySignatureStore) Clear(group string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if group == "" {
+		s.order.Init()
+		s.entries = make(map[string]*list.Element)
+		return
+	}
+	for elem := s.order.Front(); elem != nil; {
+		next := elem.Next()
+		if elem.Value.(*memoryEntry).group == group {
+			s.removeLocked(elem)
+		}
+		elem = next
+	}
+}
+
+// Purge removes expired entries.
+func (s *MemorySignatureStore) Purge() {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Entries move to the front when touched, so the expired ones are at the back.
+	for elem := s.order.Back(); elem != nil; elem = s.order.Back() {
+		if now.Sub(elem.Value.(*memoryEntry).Timestamp) <= s.ttl {
+			break
+		}
+		s.removeLocked(elem)
+	}
+}
+
+// Len returns the number of cached signatures.
+func (s *MemorySignatureStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// Close is a no-op for the memory store.
+func (s *MemorySignatureStore) Close() error { return nil }
+
+// snapshot returns the cached entries from least to most recently used.
+func (s *MemorySignatureStore) snapshot() []memoryEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]memoryEntry, 0, s.order.Len())
+	for elem := s.order.Back(); elem != nil; elem = elem.Prev() {
+		out = append(out, *elem.Value.(*memoryEntry))
+	}
+	return out
+}
+
+func (s *MemorySignatureStore) removeLocked(elem *list.Element) {
+	entry := s.order.Remove(elem).(*memoryEntry)
+	delete(s.entries, memoryKey(entry.group, entry.key))
+}
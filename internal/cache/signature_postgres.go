@@ -0,0 +1,317 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultPostgresSignatureTable = "signature_cache"
+	postgresQueryTimeout          = 5 * time.Second
+	// postgresFlushInterval is how often queued writes are sent to the table.
+	postgresFlushInterval = 500 * time.Millisecond
+	// postgresTouchInterval is the minimum time between refreshes of a row's updated_at
+	// for signatures served from the local store.
+	postgresTouchInterval = time.Minute
+)
+
+// postgresWrite is a queued table write: a signature to store, or an updated_at refresh
+// when touch is set.
+type postgresWrite struct {
+	group     string
+	key       string
+	signature string
+	touch     bool
+}
+
+// PostgresSignatureStore shares signatures between replicas through a PostgreSQL table.
+// Lookups are served from a local memory store first and fall back to the table, so a
+// conversation that moves to another replica still finds its signatures. Writes are
+// queued and flushed in the background, off the response path.
+type PostgresSignatureStore struct {
+	local      *MemorySignatureStore
+	db         *sql.DB
+	ownsDB     bool
+	table      string
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	pending map[string]postgresWrite
+	touched map[string]time.Time
+	// flushMu serializes flushes so that writes reach the table in queue order.
+	flushMu   sync.Mutex
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewPostgresSignatureStore creates the cache table when missing. When db is nil a
+// connection is opened from dsn and closed with the store; a shared db is left open.
+// schema optionally qualifies the table name.
+func NewPostgresSignatureStore(ctx context.Context, db *sql.DB, dsn, schema, table string, ttl time.Duration, maxEntries int) (*PostgresSignatureStore, error) {
+	ownsDB := false
+	if db == nil {
+		dsn = strings.TrimSpace(dsn)
+		if dsn == "" {
+			return nil, fmt.Errorf("signature cache: postgres DSN is required")
+		}
+		var err error
+		if db, err = sql.Open("pgx", dsn); err != nil {
+			return nil, fmt.Errorf("signature cache: open database connection: %w", err)
+		}
+		ownsDB = true
+		pingCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		if err = db.PingContext(pingCtx); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("signature cache: ping database: %w", err)
+		}
+	}
+	if table = strings.TrimSpace(table); table == "" {
+		table = defaultPostgresSignatureTable
+	}
+	qualified := quoteIdentifier(table)
+	if schema = strings.TrimSpace(schema); schema != "" {
+		qualified = quoteIdentifier(schema) + "." + qualified
+	}
+	local := NewMemorySignatureStore(ttl, maxEntries)
+	local.countEvictions = false
+	store := &PostgresSignatureStore{
+		local:      local,
+		db:         db,
+		ownsDB:     ownsDB,
+		table:      qualified,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		pending:    make(map[string]postgresWrite),
+		touched:    make(map[string]time.Time),
+	}
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		group_key TEXT NOT NULL,
+		text_hash TEXT NOT NULL,
+		signature TEXT NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (group_key, text_hash)
+	)`, store.table)
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		_ = store.Close()
+		return nil, fmt.Errorf("signature cache: create table: %w", err)
+	}
+	store.stop = make(chan struct{})
+	store.done = make(chan struct{})
+	go store.flushLoop()
+	return store, nil
+}
+
+// Get returns the signature from the local store or the table and refreshes its
+// expiration in the table. Refreshes of local hits are queued at most once per
+// postgresTouchInterval, so other replicas do not purge signatures still in use here.
+func (s *PostgresSignatureStore) Get(group, key string) (string, bool) {
+	if signature, ok := s.local.Get(group, key); ok {
+		s.touch(group, key)
+		return signature, true
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), postgresQueryTimeout)
+	defer cancel()
+	query := fmt.Sprintf(`UPDATE %s SET updated_at = NOW()
+		WHERE group_key = $1 AND text_hash = $2 AND updated_at > NOW() - $3 * INTERVAL '1 second'
+		RETURNING signature`, s.table)
+	var signature string
+	if err := s.db.QueryRowContext(ctx, query, group, key, s.ttl.Seconds()).Scan(&signature); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Debugf("signature cache: load signature: %v", err)
+		}
+		return "", false
+	}
+	s.local.Put(group, key, signature)
+	s.mu.Lock()
+	s.touched[memoryKey(group, key)] = time.Now()
+	s.mu.Unlock()
+	return signature, true
+}
+
+// Put stores the signature locally and queues it for the table.
+func (s *PostgresSignatureStore) Put(group, key, signature string) {
+	s.local.Put(group, key, signature)
+	id := memoryKey(group, key)
+	s.mu.Lock()
+	s.pending[id] = postgresWrite{group: group, key: key, signature: signature}
+	s.touched[id] = time.Now()
+	s.mu.Unlock()
+}
+
+// touch queues an updated_at refresh unless the row was written or refreshed within
+// postgresTouchInterval.
+func (s *PostgresSignatureStore) touch(group, key string) {
+	id := memoryKey(group, key)
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if last, ok := s.touched[id]; ok && now.Sub(last) < postgresTouchInterval {
+		return
+	}
+	s.touched[id] = now
+	if _, queued := s.pending[id]; !queued {
+		s.pending[id] = postgresWrite{group: group, key: key, touch: true}
+	}
+}
+
+func (s *PostgresSignatureStore) flushLoop() {
+	defer close(s.done)
+	ticker := time.NewTicker(postgresFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.flush()
+		case <-s.stop:
+			s.flush()
+			return
+		}
+	}
+}
+
+// flush writes the queued signatures and refreshes in one transaction. Failed writes are
+// dropped; the signatures stay in the local store.
+func (s *PostgresSignatureStore) flush() {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+	s.mu.Lock()
+	writes := s.pending
+	if len(writes) > 0 {
+		s.pending = make(map[string]postgresWrite)
+	}
+	now := time.Now()
+	for id, last := range s.touched {
+		if now.Sub(last) >= postgresTouchInterval {
+			delete(s.touched, id)
+		}
+	}
+	s.mu.Unlock()
+	if len(writes) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), postgresQueryTimeout)
+	defer cancel()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Warnf("signature cache: save signatures: %v", err)
+		return
+	}
+	upsert := fmt.Sprintf(`INSERT INTO %s (group_key, text_hash, signature, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (group_key, text_hash) DO UPDATE SET signature = EXCLUDED.signature, updated_at = EXCLUDED.updated_at`, s.table)
+	refresh := fmt.Sprintf(`UPDATE %s SET updated_at = NOW() WHERE group_key = $1 AND text_hash = $2`, s.table)
+	for _, w := range writes {
+		if w.touch {
+			_, err = tx.ExecContext(ctx, refresh, w.group, w.key)
+		} else {
+			_, err = tx.ExecContext(ctx, upsert, w.group, w.key, w.signature)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+			log.Warnf("signature cache: save signatures: %v", err)
+			return
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		log.Warnf("signature cache: save signatures: %v", err)
+	}
+}
+
+// Clear removes the entries of a model group, or every entry when group is empty.
+func (s *PostgresSignatureStore) Clear(group string) {
+	s.local.Clear(group)
+	s.mu.Lock()
+	for id, w := range s.pending {
+		if group == "" || w.group == group {
+			delete(s.pending, id)
+		}
+	}
+	s.mu.Unlock()
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), postgresQueryTimeout)
+	defer cancel()
+	var err error
+	if group == "" {
+		_, err = s.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s`, s.table))
+	} else {
+		_, err = s.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE group_key = $1`, s.table), group)
+	}
+	if err != nil {
+		log.Warnf("signature cache: clear signatures: %v", err)
+	}
+}
+
+// Purge removes expired rows and trims the table to the most recently used maxEntries.
+func (s *PostgresSignatureStore) Purge() {
+	s.local.Purge()
+	s.flush()
+	ctx, cancel := context.WithTimeout(context.Background(), postgresQueryTimeout)
+	defer cancel()
+	query := fmt.Sprintf(`DELETE FROM %s WHERE updated_at <= NOW() - $1 * INTERVAL '1 second'`, s.table)
+	if _, err := s.db.ExecContext(ctx, query, s.ttl.Seconds()); err != nil {
+		log.Debugf("signature cache: purge expired rows: %v", err)
+		return
+	}
+	if s.maxEntries <= 0 {
+		return
+	}
+	query = fmt.Sprintf(`DELETE FROM %[1]s WHERE (group_key, text_hash) IN (
+		SELECT group_key, text_hash FROM %[1]s ORDER BY updated_at DESC OFFSET $1)`, s.table)
+	result, err := s.db.ExecContext(ctx, query, s.maxEntries)
+	if err != nil {
+		log.Debugf("signature cache: trim rows: %v", err)
+		return
+	}
+	if affected, errRows := result.RowsAffected(); errRows == nil && affected > 0 {
+		recordEvictions(affected)
+	}
+}
+
+// Len returns the number of rows in the table, once queued writes are flushed.
+func (s *PostgresSignatureStore) Len() int {
+	s.flush()
+	ctx, cancel := context.WithTimeout(context.Background(), postgresQueryTimeout)
+	defer cancel()
+	var count int
+	if err := s.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s`, s.table)).Scan(&count); err != nil {
+		log.Debugf("signature cache: count rows: %v", err)
+		return s.local.Len()
+	}
+	return count
+}
+
+// Close flushes queued writes and releases the database connection when the store
+// opened it.
+func (s *PostgresSignatureStore) Close() error {
+	if s == nil {
+		return nil
+	}
+	s.closeOnce.Do(func() {
+		if s.stop != nil {
+			close(s.stop)
+			<-s.done
+		}
+	})
+	if s.db == nil || !s.ownsDB {
+		return nil
+	}
+	return s.db.Close()
+}
+
+func quoteIdentifier(identifier string) string {
+	replaced := strings.ReplaceAll(identifier, "\"", "\"\"")
+	return "\"" + replaced + "\""
+}
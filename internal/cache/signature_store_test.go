@@ -0,0 +1,215 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestMemorySignatureStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	store := NewMemorySignatureStore(time.Hour, 2)
+	store.Put("claude", "a", "sig-a")
+	store.Put("claude", "b", "sig-b")
+	if _, ok := store.Get("claude", "a"); !ok {
+		t.Fatal("expected entry a")
+	}
+	store.Put("claude", "c", "sig-c")
+
+	if _, ok := store.Get("claude", "b"); ok {
+		t.Error("expected least recently used entry b to be evicted")
+	}
+	if got, _ := store.Get("claude", "a"); got != "sig-a" {
+		t.Errorf("entry a = %q, want sig-a", got)
+	}
+	if store.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", store.Len())
+	}
+}
+
+func TestMemorySignatureStore_PurgeRemovesExpired(t *testing.T) {
+	store := NewMemorySignatureStore(time.Hour, 0)
+	store.put("claude", "old", SignatureEntry{Signature: "sig-old", Timestamp: time.Now().Add(-2 * time.Hour)})
+	store.Put("claude", "new", "sig-new")
+	store.Purge()
+
+	if store.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", store.Len())
+	}
+	if _, ok := store.Get("claude", "new"); !ok {
+		t.Error("expected unexpired entry to survive purge")
+	}
+}
+
+func TestFileSignatureStore_SurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signatures.json")
+	store, err := NewFileSignatureStore(path, time.Hour, 10)
+	if err != nil {
+		t.Fatalf("NewFileSignatureStore: %v", err)
+	}
+	store.Put("claude", "key", "sig-persisted")
+	if err = store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reopened, err := NewFileSignatureStore(path, time.Hour, 10)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer func() { _ = reopened.Close() }()
+	if got, ok := reopened.Get("claude", "key"); !ok || got != "sig-persisted" {
+		t.Errorf("Get after reopen = %q, %v", got, ok)
+	}
+}
+
+func TestSignatureCacheStatistics_CountsHitsAndMisses(t *testing.T) {
+	if err := ConfigureSignatureCache(config.SignatureCacheConfig{MaxEntries: 100}); err != nil {
+		t.Fatalf("ConfigureSignatureCache: %v", err)
+	}
+	defer func() { _ = ConfigureSignatureCache(config.SignatureCacheConfig{}) }()
+	ClearSignatureCache("")
+	before := SignatureCacheStatistics()
+
+	signature := "statsSignature_12345678901234567890123456789012345678901"
+	CacheSignature(testModelName, "stats text", signature)
+	GetCachedSignature(testModelName, "stats text")
+	GetCachedSignature(testModelName, "unknown text")
+
+	after := SignatureCacheStatistics()
+	if after.Backend != SignatureBackendMemory {
+		t.Errorf("Backend = %q", after.Backend)
+	}
+	if after.Hits-before.Hits != 1 || after.Misses-before.Misses != 1 || after.Stores-before.Stores != 1 {
+		t.Errorf("stats delta hits=%d misses=%d stores=%d, want 1 each",
+			after.Hits-before.Hits, after.Misses-before.Misses, after.Stores-before.Stores)
+	}
+	if after.Entries != 1 {
+		t.Errorf("Entries = %d, want 1", after.Entries)
+	}
+}
+
+func TestConfigureSignatureCache_RejectsUnknownBackend(t *testing.T) {
+	if err := ConfigureSignatureCache(config.SignatureCacheConfig{Backend: "redis"}); err == nil {
+		t.Fatal("expected error for unknown backend")
+	}
+}
+
+// recordingConnector is a database/sql connector that records executed statements.
+// INSERTs wait for release when it is set.
+type recordingConnector struct {
+	mu      sync.Mutex
+	execs   []string
+	release chan struct{}
+}
+
+func (c *recordingConnector) Connect(context.Context) (driver.Conn, error) {
+	return &recordingConn{c: c}, nil
+}
+
+func (c *recordingConnector) Driver() driver.Driver { return nil }
+
+func (c *recordingConnector) statements(prefix string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, query := range c.execs {
+		if strings.HasPrefix(query, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+type recordingConn struct{ c *recordingConnector }
+
+func (r *recordingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+func (r *recordingConn) Close() error              { return nil }
+func (r *recordingConn) Begin() (driver.Tx, error) { return r, nil }
+func (r *recordingConn) Commit() error             { return nil }
+func (r *recordingConn) Rollback() error           { return nil }
+
+func (r *recordingConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	query = strings.TrimSpace(query)
+	if strings.HasPrefix(query, "INSERT") && r.c.release != nil {
+		<-r.c.release
+	}
+	r.c.mu.Lock()
+	r.c.execs = append(r.c.execs, query)
+	r.c.mu.Unlock()
+	return driver.RowsAffected(1), nil
+}
+
+func TestPostgresSignatureStore_PutDoesNotWaitForDatabase(t *testing.T) {
+	connector := &recordingConnector{release: make(chan struct{})}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	store, err := NewPostgresSignatureStore(context.Background(), db, "", "", "", time.Hour, 0)
+	if err != nil {
+		t.Fatalf("NewPostgresSignatureStore() error = %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		store.Put("claude", "a", "sig-a")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Put() waited for the database write")
+	}
+	if got, ok := store.Get("claude", "a"); !ok || got != "sig-a" {
+		t.Fatalf("Get() = %q, %v; want sig-a from the local store", got, ok)
+	}
+
+	close(connector.release)
+	if err = store.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if n := connector.statements("INSERT"); n != 1 {
+		t.Fatalf("INSERT statements after Close() = %d, want 1", n)
+	}
+}
+
+func TestPostgresSignatureStore_RefreshesLocalHitsOncePerInterval(t *testing.T) {
+	connector := &recordingConnector{}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	store, err := NewPostgresSignatureStore(context.Background(), db, "", "", "", time.Hour, 0)
+	if err != nil {
+		t.Fatalf("NewPostgresSignatureStore() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	store.Put("claude", "a", "sig-a")
+	store.flush()
+	if _, ok := store.Get("claude", "a"); !ok {
+		t.Fatal("expected a local hit")
+	}
+	store.flush()
+	if n := connector.statements("UPDATE"); n != 0 {
+		t.Fatalf("UPDATE statements right after the write = %d, want 0", n)
+	}
+
+	store.mu.Lock()
+	store.touched[memoryKey("claude", "a")] = time.Now().Add(-2 * postgresTouchInterval)
+	store.mu.Unlock()
+	for i := 0; i < 3; i++ {
+		if _, ok := store.Get("claude", "a"); !ok {
+			t.Fatal("expected a local hit")
+		}
+	}
+	store.flush()
+	if n := connector.statements("UPDATE"); n != 1 {
+		t.Fatalf("UPDATE statements after repeated local hits = %d, want 1", n)
+	}
+}
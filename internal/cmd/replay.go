@@ -0,0 +1,650 @@
+// Package cmd contains CLI helpers. This file implements replaying a captured request
+// log through the in-process handler stack to reproduce translation issues.
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/api"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	replayReadyTimeout    = 15 * time.Second
+	replayResponseTimeout = 10 * time.Minute
+	replayLogTimeout      = 5 * time.Second
+	replayMaxDiffCells    = 4_000_000
+	// replayMockAuthID identifies the synthetic credential mock replays route through.
+	replayMockAuthID = "replay-mock"
+)
+
+// replayVolatileFields lists response fields that differ between otherwise identical
+// responses and are removed before diffing.
+var replayVolatileFields = []string{
+	"id", "created", "created_at", "system_fingerprint", "responseId", "createTime",
+	"message.id", "response.id", "response.created_at", "response.responseId",
+}
+
+// ReplayOptions controls how DoReplay executes a captured request.
+type ReplayOptions struct {
+	// AuthID restricts execution to the credential with this ID or file name.
+	AuthID string
+	// Mock serves the recorded upstream response instead of contacting the provider.
+	// Without AuthID the request is routed through a synthetic credential for the
+	// recorded provider, so no real credentials are needed.
+	Mock bool
+}
+
+// DoReplay parses a request log (by file path or request ID), sends the reconstructed
+// client request through an in-process server, and prints the translated upstream
+// request together with a diff of the response against the recorded one.
+//
+// Parameters:
+//   - cfg: The application configuration
+//   - configPath: The path to the configuration file
+//   - ref: A request log file path or request ID
+//   - opts: Credential and mock selection
+func DoReplay(cfg *config.Config, configPath string, ref string, opts ReplayOptions) {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	var logPath string
+	var errResolve error
+	for _, dir := range replayLogDirectories(cfg, configPath) {
+		if logPath, errResolve = logging.ResolveRequestLogPath(dir, ref); errResolve == nil {
+			break
+		}
+	}
+	if errResolve != nil {
+		log.Errorf("replay: %v", errResolve)
+		return
+	}
+	recorded, errParse := logging.ReadRequestLogFile(logPath)
+	if errParse != nil {
+		log.Errorf("replay: failed to parse %s: %v", logPath, errParse)
+		return
+	}
+	fmt.Printf("Replaying %s %s from %s\n", recorded.Method, recorded.URL, logPath)
+
+	port, errPort := replayFreePort()
+	if errPort != nil {
+		log.Errorf("replay: failed to reserve a local port: %v", errPort)
+		return
+	}
+	replayCfg := *cfg
+	replayCfg.Host = "127.0.0.1"
+	replayCfg.Port = port
+	replayCfg.TLS.Enable = false
+	replayCfg.RequestLog = true
+	if opts.Mock {
+		replayCfg.ProxyURL = ""
+	}
+
+	capture := newReplayCaptureLogger()
+	selector := &replaySelector{authID: strings.TrimSpace(opts.AuthID)}
+	// A store-less manager keeps refreshed tokens and state changes in memory only.
+	coreManager := coreauth.NewManager(nil, selector, nil)
+
+	ready := make(chan struct{})
+	service, errBuild := cliproxy.NewBuilder().
+		WithConfig(&replayCfg).
+		WithConfigPath(configPath).
+		WithCoreAuthManager(coreManager).
+		WithServerOptions(api.WithRequestLoggerFactory(func(*config.Config, string) logging.RequestLogger {
+			return capture
+		})).
+		WithHooks(cliproxy.Hooks{OnAfterStart: func(*cliproxy.Service) { close(ready) }}).
+		Build()
+	if errBuild != nil {
+		log.Errorf("replay: failed to build proxy service: %v", errBuild)
+		return
+	}
+	var mock *replayMockTransport
+	if opts.Mock {
+		mock = newReplayMockTransport(recorded)
+		coreManager.SetRoundTripperProvider(mock)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- service.Run(ctx) }()
+	defer func() {
+		cancel()
+		select {
+		case <-runErr:
+		case <-time.After(replayLogTimeout):
+		}
+	}()
+
+	select {
+	case <-ready:
+	case errRun := <-runErr:
+		log.Errorf("replay: proxy service exited early: %v", errRun)
+		return
+	case <-time.After(replayReadyTimeout):
+		log.Errorf("replay: proxy service did not start within %s", replayReadyTimeout)
+		return
+	}
+	model := replayRequestedModel(recorded)
+	if opts.Mock && selector.authID == "" {
+		if authID := registerReplayMockAuth(service, coreManager, recorded.Provider, model); authID != "" {
+			selector.authID = authID
+		}
+	}
+	waitForReplayRouting(coreManager, model, selector.authID)
+
+	status, headers, body, errSend := sendReplayRequest(&replayCfg, recorded)
+	if errSend != nil {
+		log.Errorf("replay: request failed: %v", errSend)
+		return
+	}
+
+	select {
+	case <-capture.done:
+	case <-time.After(replayLogTimeout):
+		log.Warn("replay: timed out waiting for the upstream request capture")
+	}
+	apiRequest := capture.apiRequestSnapshot()
+
+	fmt.Println()
+	fmt.Println("=== TRANSLATED UPSTREAM REQUEST ===")
+	if len(apiRequest) == 0 {
+		fmt.Println("<not captured>")
+	} else {
+		fmt.Println(strings.TrimRight(string(apiRequest), "\n"))
+	}
+	if mock != nil {
+		fmt.Printf("\n(mock upstream served %d request(s) from the recorded response)\n", mock.served())
+	}
+
+	fmt.Println()
+	fmt.Println("=== REPLAYED RESPONSE ===")
+	fmt.Printf("Status: %d (recorded %d)\n", status, recorded.Status)
+	if contentType := headers.Get("Content-Type"); contentType != "" {
+		fmt.Printf("Content-Type: %s\n", contentType)
+	}
+	fmt.Println()
+	fmt.Println(strings.TrimRight(string(body), "\n"))
+
+	fmt.Println()
+	fmt.Println("=== RESPONSE DIFF (- recorded, + replayed) ===")
+	diff := diffReplayLines(normaliseReplayBody(recorded.Response), normaliseReplayBody(body))
+	if len(diff) == 0 {
+		fmt.Println("<no differences>")
+	}
+	for _, line := range diff {
+		fmt.Println(line)
+	}
+}
+
+// replayLogDirectories returns the directories request logs may have been written to:
+// the request logger's directory next to the config file, then the application log directory.
+func replayLogDirectories(cfg *config.Config, configPath string) []string {
+	var dirs []string
+	if base := util.WritablePath(); base != "" {
+		dirs = append(dirs, filepath.Join(base, "logs"))
+	} else if configPath != "" {
+		dirs = append(dirs, filepath.Join(filepath.Dir(configPath), "logs"))
+	}
+	if dir := logging.ResolveLogDirectory(cfg); len(dirs) == 0 || dir != dirs[0] {
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// replayFreePort asks the OS for an unused loopback port.
+func replayFreePort() (int, error) {
+	listener, errListen := net.Listen("tcp", "127.0.0.1:0")
+	if errListen != nil {
+		return 0, errListen
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	if errClose := listener.Close(); errClose != nil {
+		return 0, errClose
+	}
+	return port, nil
+}
+
+// waitForReplayRouting waits until the watcher has registered the credentials needed to
+// route the request, so the replay does not race the initial auth load.
+func waitForReplayRouting(manager *coreauth.Manager, model, authID string) {
+	deadline := time.Now().Add(replayReadyTimeout)
+	for time.Now().Before(deadline) {
+		routable := model == "" || len(util.GetProviderName(model)) > 0
+		if routable && (authID == "" || replayHasAuth(manager, authID)) {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if authID != "" && !replayHasAuth(manager, authID) {
+		log.Warnf("replay: credential %q was not loaded", authID)
+		return
+	}
+	log.Warnf("replay: no provider registered for model %q yet", model)
+}
+
+// registerReplayMockAuth registers a synthetic credential for the recorded provider and
+// the replayed model. It returns the credential ID, or "" when the recording does not
+// name a provider that can be mocked.
+func registerReplayMockAuth(service *cliproxy.Service, manager *coreauth.Manager, provider, model string) string {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	// AI Studio credentials are websocket channels, which the mock transport cannot serve.
+	if provider == "" || provider == "aistudio" {
+		log.Warnf("replay: recording has no mockable provider (%q); waiting for a loaded credential", provider)
+		return ""
+	}
+	now := time.Now().UTC()
+	auth := &coreauth.Auth{
+		ID:         replayMockAuthID,
+		Provider:   provider,
+		Label:      replayMockAuthID,
+		Status:     coreauth.StatusActive,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Attributes: map[string]string{"runtime_only": "true", "api_key": replayMockAuthID},
+		Metadata:   map[string]any{"type": provider, "email": replayMockAuthID, "access_token": replayMockAuthID},
+	}
+	if !service.GetWatcher().DispatchRuntimeAuthUpdate(watcher.AuthUpdate{Action: watcher.AuthUpdateActionAdd, ID: auth.ID, Auth: auth}) {
+		log.Warn("replay: failed to register the mock credential")
+		return ""
+	}
+	deadline := time.Now().Add(replayReadyTimeout)
+	for !replayHasAuth(manager, auth.ID) {
+		if time.Now().After(deadline) {
+			log.Warn("replay: mock credential was not registered in time")
+			return ""
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	// The provider's default model list may not contain the recorded model, so the mock
+	// credential serves exactly that model.
+	if model != "" {
+		if parsed := thinking.ParseSuffix(model); parsed.ModelName != "" {
+			model = parsed.ModelName
+		}
+		registry.GetGlobalRegistry().RegisterClient(auth.ID, provider, []*registry.ModelInfo{{
+			ID: model, Object: "model", OwnedBy: provider, Type: provider,
+		}})
+	}
+	return auth.ID
+}
+
+func replayHasAuth(manager *coreauth.Manager, authID string) bool {
+	for _, auth := range manager.List() {
+		if replayAuthMatches(auth, authID) {
+			return true
+		}
+	}
+	return false
+}
+
+func replayRequestedModel(recorded *logging.RequestLogRecord) string {
+	if model := gjson.GetBytes(recorded.Body, "model").String(); model != "" {
+		return model
+	}
+	path := recorded.URL
+	if idx := strings.Index(path, "?"); idx >= 0 {
+		path = path[:idx]
+	}
+	if idx := strings.Index(path, "/models/"); idx >= 0 {
+		model := path[idx+len("/models/"):]
+		if colon := strings.Index(model, ":"); colon >= 0 {
+			model = model[:colon]
+		}
+		return model
+	}
+	return ""
+}
+
+// sendReplayRequest rebuilds the client request without its recorded credentials and
+// sends it to the in-process server.
+func sendReplayRequest(cfg *config.Config, recorded *logging.RequestLogRecord) (int, http.Header, []byte, error) {
+	target, errParse := url.Parse(recorded.URL)
+	if errParse != nil {
+		return 0, nil, nil, errParse
+	}
+	query := target.Query()
+	for key := range query {
+		if isSensitiveReplayKey(key) {
+			query.Del(key)
+		}
+	}
+	target.RawQuery = query.Encode()
+	target.Scheme = "http"
+	target.Host = fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+
+	ctx, cancel := context.WithTimeout(context.Background(), replayResponseTimeout)
+	defer cancel()
+	req, errReq := http.NewRequestWithContext(ctx, recorded.Method, target.String(), bytes.NewReader(recorded.Body))
+	if errReq != nil {
+		return 0, nil, nil, errReq
+	}
+	for key, values := range recorded.Headers {
+		if isSensitiveReplayKey(key) || isHopReplayHeader(key) {
+			continue
+		}
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if len(cfg.APIKeys) > 0 {
+		req.Header.Set("Authorization", "Bearer "+cfg.APIKeys[0])
+	}
+
+	resp, errDo := http.DefaultClient.Do(req)
+	if errDo != nil {
+		return 0, nil, nil, errDo
+	}
+	defer func() {
+		if errClose := resp.Body.Close(); errClose != nil {
+			log.Errorf("replay: response body close error: %v", errClose)
+		}
+	}()
+	body, errRead := io.ReadAll(resp.Body)
+	if errRead != nil {
+		return resp.StatusCode, resp.Header, body, errRead
+	}
+	return resp.StatusCode, resp.Header, body, nil
+}
+
+func isSensitiveReplayKey(key string) bool {
+	lower := strings.ToLower(strings.TrimSpace(key))
+	if lower == "key" || lower == "cookie" {
+		return true
+	}
+	for _, marker := range []string{"authorization", "api-key", "apikey", "api_key", "token", "secret"} {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func isHopReplayHeader(key string) bool {
+	switch strings.ToLower(strings.TrimSpace(key)) {
+	case "content-length", "accept-encoding", "connection", "host", "transfer-encoding":
+		return true
+	default:
+		return false
+	}
+}
+
+// normaliseReplayBody splits a response into lines with volatile JSON fields removed.
+func normaliseReplayBody(body []byte) []string {
+	text := strings.TrimRight(strings.ReplaceAll(string(body), "\r\n", "\n"), "\n")
+	if text == "" {
+		return nil
+	}
+	if trimmed := strings.TrimSpace(text); gjson.Valid(trimmed) && (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) {
+		var pretty bytes.Buffer
+		if errIndent := json.Indent(&pretty, []byte(stripReplayVolatile(trimmed)), "", "  "); errIndent == nil {
+			return strings.Split(pretty.String(), "\n")
+		}
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		prefix, payload := "", line
+		if rest, ok := strings.CutPrefix(line, "data: "); ok {
+			prefix, payload = "data: ", rest
+		}
+		if strings.HasPrefix(payload, "{") && gjson.Valid(payload) {
+			lines[i] = prefix + stripReplayVolatile(payload)
+		}
+	}
+	return lines
+}
+
+func stripReplayVolatile(payload string) string {
+	for _, field := range replayVolatileFields {
+		if updated, errDelete := sjson.Delete(payload, field); errDelete == nil {
+			payload = updated
+		}
+	}
+	return payload
+}
+
+// diffReplayLines returns a line diff using the longest common subsequence. Very large
+// inputs fall back to reporting the first differing line.
+func diffReplayLines(a, b []string) []string {
+	if len(a)*len(b) > replayMaxDiffCells {
+		for i := 0; i < len(a) || i < len(b); i++ {
+			if i >= len(a) || i >= len(b) || a[i] != b[i] {
+				result := []string{fmt.Sprintf("responses differ from line %d (too large for a full diff)", i+1)}
+				if i < len(a) {
+					result = append(result, "- "+a[i])
+				}
+				if i < len(b) {
+					result = append(result, "+ "+b[i])
+				}
+				return result
+			}
+		}
+		return nil
+	}
+
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var result []string
+	changed := false
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			result = append(result, "  "+a[i])
+			i++
+			j++
+		case i < len(a) && (j >= len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			result = append(result, "- "+a[i])
+			changed = true
+			i++
+		default:
+			result = append(result, "+ "+b[j])
+			changed = true
+			j++
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return result
+}
+
+// replaySelector optionally pins execution to a single credential.
+type replaySelector struct {
+	authID   string
+	fallback coreauth.FillFirstSelector
+}
+
+// Pick implements coreauth.Selector.
+func (s *replaySelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*coreauth.Auth) (*coreauth.Auth, error) {
+	if s.authID == "" {
+		return s.fallback.Pick(ctx, provider, model, opts, auths)
+	}
+	for _, auth := range auths {
+		if replayAuthMatches(auth, s.authID) {
+			return auth, nil
+		}
+	}
+	return nil, &coreauth.Error{Code: "auth_not_found", Message: fmt.Sprintf("credential %q is not available for %s", s.authID, model)}
+}
+
+func replayAuthMatches(auth *coreauth.Auth, authID string) bool {
+	if auth == nil {
+		return false
+	}
+	if auth.ID == authID || auth.Label == authID {
+		return true
+	}
+	return auth.FileName != "" && filepath.Base(auth.FileName) == filepath.Base(authID)
+}
+
+// replayMockTransport answers every upstream call with the recorded upstream response.
+type replayMockTransport struct {
+	mu      sync.Mutex
+	count   int
+	status  int
+	headers http.Header
+	body    []byte
+}
+
+func newReplayMockTransport(recorded *logging.RequestLogRecord) *replayMockTransport {
+	transport := &replayMockTransport{status: http.StatusOK, headers: make(http.Header)}
+	if len(recorded.UpstreamResponses) == 0 {
+		transport.status = http.StatusBadGateway
+		transport.body = []byte(`{"error":{"message":"request log has no recorded upstream response"}}`)
+		return transport
+	}
+	upstream := recorded.UpstreamResponses[len(recorded.UpstreamResponses)-1]
+	if upstream.Status > 0 {
+		transport.status = upstream.Status
+	} else if upstream.Error != "" && len(upstream.Body) == 0 {
+		transport.status = http.StatusBadGateway
+	}
+	for key, values := range upstream.Headers {
+		switch strings.ToLower(key) {
+		case "content-encoding", "content-length", "transfer-encoding":
+			continue
+		}
+		transport.headers[key] = append([]string(nil), values...)
+	}
+	transport.body = upstream.Body
+	if transport.headers.Get("Content-Type") == "" {
+		if bytes.HasPrefix(transport.body, []byte("data:")) || bytes.HasPrefix(transport.body, []byte("event:")) {
+			transport.headers.Set("Content-Type", "text/event-stream")
+		} else {
+			transport.headers.Set("Content-Type", "application/json")
+		}
+	}
+	return transport
+}
+
+// RoundTripperFor implements coreauth.RoundTripperProvider.
+func (t *replayMockTransport) RoundTripperFor(*coreauth.Auth) http.RoundTripper { return t }
+
+// RoundTrip implements http.RoundTripper.
+func (t *replayMockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.count++
+	t.mu.Unlock()
+	if req.Body != nil {
+		_, _ = io.Copy(io.Discard, req.Body)
+		_ = req.Body.Close()
+	}
+	return &http.Response{
+		StatusCode:    t.status,
+		Status:        fmt.Sprintf("%d %s", t.status, http.StatusText(t.status)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        t.headers.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(t.body)),
+		ContentLength: int64(len(t.body)),
+		Request:       req,
+	}, nil
+}
+
+func (t *replayMockTransport) served() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.count
+}
+
+// replayCaptureLogger is an in-memory RequestLogger that keeps the upstream request of
+// the replayed call instead of writing a log file.
+type replayCaptureLogger struct {
+	mu         sync.Mutex
+	once       sync.Once
+	done       chan struct{}
+	apiRequest []byte
+}
+
+func newReplayCaptureLogger() *replayCaptureLogger {
+	return &replayCaptureLogger{done: make(chan struct{})}
+}
+
+func (l *replayCaptureLogger) capture(apiRequest []byte) {
+	l.mu.Lock()
+	if len(apiRequest) > 0 {
+		l.apiRequest = bytes.Clone(apiRequest)
+	}
+	l.mu.Unlock()
+	l.once.Do(func() { close(l.done) })
+}
+
+func (l *replayCaptureLogger) apiRequestSnapshot() []byte {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return bytes.Clone(l.apiRequest)
+}
+
+// LogRequest implements logging.RequestLogger.
+func (l *replayCaptureLogger) LogRequest(_, _ string, _ map[string][]string, _ []byte, _ int, _ map[string][]string, _, apiRequest, _ []byte, _ []*interfaces.ErrorMessage, _ string) error {
+	l.capture(apiRequest)
+	return nil
+}
+
+// LogStreamingRequest implements logging.RequestLogger.
+func (l *replayCaptureLogger) LogStreamingRequest(string, string, map[string][]string, []byte, string) (logging.StreamingLogWriter, error) {
+	return &replayCaptureStreamWriter{logger: l}, nil
+}
+
+// IsEnabled implements logging.RequestLogger.
+func (l *replayCaptureLogger) IsEnabled() bool { return true }
+
+type replayCaptureStreamWriter struct {
+	logger     *replayCaptureLogger
+	apiRequest []byte
+}
+
+func (w *replayCaptureStreamWriter) WriteChunkAsync([]byte) {}
+
+func (w *replayCaptureStreamWriter) WriteStatus(int, map[string][]string) error { return nil }
+
+func (w *replayCaptureStreamWriter) WriteAPIRequest(apiRequest []byte) error {
+	w.apiRequest = bytes.Clone(apiRequest)
+	return nil
+}
+
+func (w *replayCaptureStreamWriter) WriteAPIResponse([]byte) error { return nil }
+
+func (w *replayCaptureStreamWriter) Close() error {
+	w.logger.capture(w.apiRequest)
+	return nil
+}
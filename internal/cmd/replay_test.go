@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+)
+
+const replayTestUpstreamBody = `{"id":"msg_01","type":"message","role":"assistant","model":"claude-replay-test","content":[{"type":"text","text":"Hello from the recording."}],"stop_reason":"end_turn","stop_sequence":null,"usage":{"input_tokens":3,"output_tokens":5}}`
+
+func replayTestLog(provider string) string {
+	auth := ""
+	if provider != "" {
+		auth = "Auth: provider=" + provider + " auth_id=claude-1 type=api_key value=sk-...abcd\n"
+	}
+	return "=== REQUEST INFO ===\n" +
+		"Version: dev\nURL: /v1/messages?beta=true\nMethod: POST\nTimestamp: 2026-01-02T10:00:00Z\n\n" +
+		"=== HEADERS ===\nContent-Type: application/json\nX-Api-Key: sk-...abcd\n\n" +
+		"=== REQUEST BODY ===\n" +
+		`{"model":"claude-replay-test","max_tokens":64,"messages":[{"role":"user","content":"hi"}]}` + "\n\n" +
+		"=== API REQUEST 1 ===\nTimestamp: 2026-01-02T10:00:00Z\nUpstream URL: https://api.anthropic.com/v1/messages\n" + auth +
+		"\nHeaders:\nContent-Type: application/json\n\nBody:\n{}\n\n" +
+		"=== API RESPONSE 1 ===\nTimestamp: 2026-01-02T10:00:01Z\n\nStatus: 200\nHeaders:\nContent-Type: application/json\nContent-Length: 999\n\nBody:\n" +
+		replayTestUpstreamBody + "\n\n" +
+		"=== RESPONSE ===\nStatus: 200\nContent-Type: application/json\n\n" +
+		replayTestUpstreamBody + "\n"
+}
+
+func TestReplayRecordingParsing(t *testing.T) {
+	recorded, errParse := logging.ParseRequestLog([]byte(replayTestLog("claude")))
+	if errParse != nil {
+		t.Fatalf("ParseRequestLog() error = %v", errParse)
+	}
+	if got := replayRequestedModel(recorded); got != "claude-replay-test" {
+		t.Fatalf("replayRequestedModel() = %q", got)
+	}
+	if recorded.Provider != "claude" {
+		t.Fatalf("Provider = %q, want claude", recorded.Provider)
+	}
+
+	geminiRecord := &logging.RequestLogRecord{URL: "/v1beta/models/gemini-2.5-pro:streamGenerateContent?alt=sse"}
+	if got := replayRequestedModel(geminiRecord); got != "gemini-2.5-pro" {
+		t.Fatalf("replayRequestedModel(gemini) = %q", got)
+	}
+
+	transport := newReplayMockTransport(recorded)
+	req, _ := http.NewRequest(http.MethodPost, "https://api.anthropic.com/v1/messages", strings.NewReader("{}"))
+	resp, errTrip := transport.RoundTrip(req)
+	if errTrip != nil {
+		t.Fatalf("RoundTrip() error = %v", errTrip)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != replayTestUpstreamBody {
+		t.Fatalf("mock response = %d %s", resp.StatusCode, body)
+	}
+	if resp.Header.Get("Content-Length") != "" || resp.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected mock headers: %v", resp.Header)
+	}
+	if transport.served() != 1 {
+		t.Fatalf("served() = %d, want 1", transport.served())
+	}
+
+	if got := newReplayMockTransport(&logging.RequestLogRecord{}); got.status != http.StatusBadGateway {
+		t.Fatalf("missing upstream response status = %d, want 502", got.status)
+	}
+}
+
+func TestDiffReplayLinesIgnoresVolatileFields(t *testing.T) {
+	recorded := normaliseReplayBody([]byte("data: {\"id\":\"a\",\"created\":1,\"text\":\"x\"}\n\ndata: [DONE]\n"))
+	replayed := normaliseReplayBody([]byte("data: {\"id\":\"b\",\"created\":2,\"text\":\"x\"}\n\ndata: [DONE]\n"))
+	if diff := diffReplayLines(recorded, replayed); diff != nil {
+		t.Fatalf("expected no diff, got %v", diff)
+	}
+	replayed = normaliseReplayBody([]byte("data: {\"id\":\"b\",\"text\":\"y\"}\n\ndata: [DONE]\n"))
+	diff := diffReplayLines(recorded, replayed)
+	if len(diff) == 0 || !strings.Contains(strings.Join(diff, "\n"), `+ data: {"text":"y"}`) {
+		t.Fatalf("unexpected diff: %v", diff)
+	}
+}
+
+// TestDoReplayMockWithoutCredentials replays a Claude recording in mock mode with an
+// empty auth directory; the mock credential must route the request.
+func TestDoReplayMockWithoutCredentials(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "v1-messages-2026-01-02T100000-abcd1234.log")
+	if errWrite := os.WriteFile(logPath, []byte(replayTestLog("claude")), 0o600); errWrite != nil {
+		t.Fatalf("write log: %v", errWrite)
+	}
+	authDir := filepath.Join(dir, "auths")
+	if errMkdir := os.MkdirAll(authDir, 0o700); errMkdir != nil {
+		t.Fatalf("mkdir: %v", errMkdir)
+	}
+	configPath := filepath.Join(dir, "config.yaml")
+	if errWrite := os.WriteFile(configPath, []byte("auth-dir: "+authDir+"\n"), 0o600); errWrite != nil {
+		t.Fatalf("write config: %v", errWrite)
+	}
+	cfg := &config.Config{AuthDir: authDir}
+	cfg.RemoteManagement.DisableControlPanel = true
+
+	output := captureReplayStdout(t, func() {
+		DoReplay(cfg, configPath, logPath, ReplayOptions{Mock: true})
+	})
+
+	for _, want := range []string{
+		"=== TRANSLATED UPSTREAM REQUEST ===",
+		"mock upstream served 1 request(s)",
+		"Status: 200 (recorded 200)",
+		"Hello from the recording.",
+		"<no differences>",
+	} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("replay output missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func captureReplayStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	reader, writer, errPipe := os.Pipe()
+	if errPipe != nil {
+		t.Fatalf("pipe: %v", errPipe)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(reader)
+		done <- string(data)
+	}()
+	defer func() { os.Stdout = stdout }()
+	fn()
+	_ = writer.Close()
+	return <-done
+}
@@ -0,0 +1,26 @@
+package config
+
+import "testing"
+
+func TestSanitizeBedrockKeysRequiresCompleteCredentials(t *testing.T) {
+	cfg := &Config{BedrockKey: []BedrockKey{
+		{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+		{AccessKeyID: "AKID-ONLY"},
+		{AccessKeyID: "AKID-PROFILE", Profile: " work "},
+		{Profile: "default"},
+	}}
+	cfg.SanitizeBedrockKeys()
+
+	if len(cfg.BedrockKey) != 3 {
+		t.Fatalf("entries = %+v, want 3", cfg.BedrockKey)
+	}
+	if got := cfg.BedrockKey[0].GetAPIKey(); got != "AKID" {
+		t.Fatalf("static entry key = %q", got)
+	}
+	if got := cfg.BedrockKey[1]; got.AccessKeyID != "" || got.GetAPIKey() != "profile:work" {
+		t.Fatalf("incomplete static credentials kept: %+v", got)
+	}
+	if got := cfg.BedrockKey[2].GetAPIKey(); got != "profile:default" {
+		t.Fatalf("profile entry key = %q", got)
+	}
+}
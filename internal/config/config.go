@@ -92,6 +92,9 @@ type Config struct {
 	// ClaudeKey defines a list of Claude API key configurations as specified in the YAML configuration file.
 	ClaudeKey []ClaudeKey `yaml:"claude-api-key" json:"claude-api-key"`
 
+	// BedrockKey defines AWS Bedrock credentials served through the Converse API.
+	BedrockKey []BedrockKey `yaml:"bedrock" json:"bedrock"`
+
 	// OpenAICompatibility defines OpenAI API compatibility configurations for external providers.
 	OpenAICompatibility []OpenAICompatibility `yaml:"openai-compatibility" json:"openai-compatibility"`
 
@@ -117,6 +120,22 @@ type Config struct {
 	// Payload defines default and override rules for provider payload parameters.
 	Payload PayloadConfig `yaml:"payload" json:"payload"`
 
+	// Batch configures the local OpenAI Batch API emulation served under /v1/files and /v1/batches.
+	Batch BatchConfig `yaml:"batch,omitempty" json:"batch,omitempty"`
+
+	// Ollama configures the optional Ollama-compatible API served under /api.
+	Ollama OllamaConfig `yaml:"ollama,omitempty" json:"ollama,omitempty"`
+
+	// PromptCache maps Claude cache_control breakpoints to provider-native prompt caching.
+	PromptCache PromptCacheConfig `yaml:"prompt-cache,omitempty" json:"prompt-cache,omitempty"`
+
+	// Documents controls how PDF and text attachments reach providers that cannot read them.
+	Documents DocumentsConfig `yaml:"documents,omitempty" json:"documents,omitempty"`
+
+	// SignatureCache selects where Claude thinking signatures are cached so multi-turn
+	// conversations keep working across restarts and replicas.
+	SignatureCache SignatureCacheConfig `yaml:"signature-cache,omitempty" json:"signature-cache,omitempty"`
+
 	// IncognitoBrowser enables opening OAuth URLs in incognito/private browsing mode.
 	// This is useful when you want to login with a different account without logging out
 	// from your current session. Default: false.
@@ -232,6 +251,81 @@ type AmpUpstreamAPIKeyEntry struct {
 	APIKeys []string `yaml:"api-keys" json:"api-keys"`
 }
 
+// BatchConfig holds settings for locally executed batch jobs.
+type BatchConfig struct {
+	// Enable serves the /v1/files, /v1/batches and /v1/messages/batches endpoints. Files
+	// and batches are only visible to the client API key that created them.
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// Dir stores uploaded files and job state. Empty uses "batches" under WRITABLE_PATH
+	// or the working directory.
+	Dir string `yaml:"dir,omitempty" json:"dir,omitempty"`
+
+	// Concurrency bounds how many batch lines run at once. Each in-flight interactive
+	// request lowers the effective limit by one, down to a minimum of one. <= 0 uses 4.
+	Concurrency int `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
+
+	// MaxFileSizeMB limits the size of uploaded files. <= 0 uses 100.
+	MaxFileSizeMB int `yaml:"max-file-size-mb,omitempty" json:"max-file-size-mb,omitempty"`
+}
+
+// OllamaConfig holds settings for the Ollama-compatible API surface.
+type OllamaConfig struct {
+	// Enable serves /api/chat, /api/generate, /api/tags, /api/show and /api/version.
+	// Requests still require a configured API key. Changes apply without a restart.
+	Enable bool `yaml:"enable" json:"enable"`
+}
+
+// PromptCacheConfig holds settings for the cache_control bridge.
+type PromptCacheConfig struct {
+	// Enable turns on creating Gemini cachedContents and deriving prompt_cache_key values
+	// from Claude cache_control breakpoints. cachedContents are only created for the Gemini
+	// API executor; Vertex and AI Studio requests are sent uncached. Default: false.
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// TTLSeconds is the lifetime of Gemini cachedContents when the breakpoint does not ask
+	// for the one-hour TTL. <= 0 uses 300.
+	TTLSeconds int `yaml:"ttl-seconds,omitempty" json:"ttl-seconds,omitempty"`
+
+	// OpenAICompatKey also sends a prompt_cache_key to OpenAI-compatible providers. Off by
+	// default because some providers reject unknown fields.
+	OpenAICompatKey bool `yaml:"openai-compat-key,omitempty" json:"openai-compat-key,omitempty"`
+}
+
+// DocumentsConfig holds the fallback used for providers without native document input.
+type DocumentsConfig struct {
+	// Fallback is "extract" to send text extracted locally from each document, or "reject"
+	// to fail the request with 400 Bad Request. Empty uses "extract".
+	Fallback string `yaml:"fallback,omitempty" json:"fallback,omitempty"`
+
+	// MaxChars caps the extracted text per document. <= 0 uses 200000.
+	MaxChars int `yaml:"max-chars,omitempty" json:"max-chars,omitempty"`
+
+	// MaxDecodedBytes caps the decompressed PDF stream data per document. Documents over
+	// the cap are rejected with 400. <= 0 uses 64 MiB.
+	MaxDecodedBytes int64 `yaml:"max-decoded-bytes,omitempty" json:"max-decoded-bytes,omitempty"`
+}
+
+// SignatureCacheConfig holds the thinking signature cache backend configuration.
+type SignatureCacheConfig struct {
+	// Backend selects the cache backend: "memory" (default), "file", or "postgres".
+	Backend string `yaml:"backend,omitempty" json:"backend,omitempty"`
+
+	// MaxEntries bounds the number of cached signatures. <= 0 uses 10000.
+	MaxEntries int `yaml:"max-entries,omitempty" json:"max-entries,omitempty"`
+
+	// Path is the file used by the file backend (default "signature-cache.json" in the
+	// working directory).
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+
+	// DSN is the PostgreSQL connection string used by the postgres backend. Empty reuses
+	// the connection of the PostgreSQL token store (PGSTORE_DSN).
+	DSN string `yaml:"dsn,omitempty" json:"dsn,omitempty"`
+
+	// Table overrides the PostgreSQL table name (default "signature_cache").
+	Table string `yaml:"table,omitempty" json:"table,omitempty"`
+}
+
 // PayloadConfig defines default and override parameter rules applied to provider payloads.
 type PayloadConfig struct {
 	// Default defines rules that only set parameters when they are missing in the payload.
@@ -328,6 +422,72 @@ type ClaudeModel struct {
 func (m ClaudeModel) GetName() string  { return m.Name }
 func (m ClaudeModel) GetAlias() string { return m.Alias }
 
+// BedrockKey represents AWS credentials for Claude models on Amazon Bedrock.
+// Requests are sent to the Converse API and signed with Signature Version 4.
+type BedrockKey struct {
+	// AccessKeyID and SecretAccessKey are static AWS credentials.
+	AccessKeyID     string `yaml:"access-key-id,omitempty" json:"access-key-id,omitempty"`
+	SecretAccessKey string `yaml:"secret-access-key,omitempty" json:"secret-access-key,omitempty"`
+
+	// SessionToken is the optional token of temporary credentials.
+	SessionToken string `yaml:"session-token,omitempty" json:"session-token,omitempty"`
+
+	// Profile names a profile in the shared AWS credentials file, used when no static
+	// credentials are configured. The file is read on every request so rotated
+	// credentials are picked up.
+	Profile string `yaml:"profile,omitempty" json:"profile,omitempty"`
+
+	// Region is the AWS region of the Bedrock runtime endpoint (default: us-east-1).
+	Region string `yaml:"region,omitempty" json:"region,omitempty"`
+
+	// Priority controls selection preference when multiple credentials match.
+	// Higher values are preferred; defaults to 0.
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
+
+	// Prefix optionally namespaces models for this credential (e.g., "bedrock/claude-sonnet-4").
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+
+	// BaseURL overrides the regional Bedrock runtime endpoint.
+	BaseURL string `yaml:"base-url,omitempty" json:"base-url,omitempty"`
+
+	// ProxyURL overrides the global proxy setting for this credential if provided.
+	ProxyURL string `yaml:"proxy-url,omitempty" json:"proxy-url,omitempty"`
+
+	// Models maps client-facing aliases to Bedrock model or inference profile IDs
+	// (e.g., "us.anthropic.claude-sonnet-4-20250514-v1:0").
+	Models []ClaudeModel `yaml:"models" json:"models"`
+
+	// ExcludedModels lists model IDs that should be excluded for this provider.
+	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
+}
+
+// GetAPIKey identifies the credential: its access key ID, or "profile:<name>" for
+// profile-based entries.
+func (k BedrockKey) GetAPIKey() string {
+	if k.AccessKeyID != "" {
+		return k.AccessKeyID
+	}
+	if k.Profile != "" {
+		return "profile:" + k.Profile
+	}
+	return ""
+}
+
+// GetBaseURL returns the configured endpoint or the regional Bedrock runtime endpoint.
+func (k BedrockKey) GetBaseURL() string {
+	if k.BaseURL != "" {
+		return k.BaseURL
+	}
+	region := k.Region
+	if region == "" {
+		region = DefaultBedrockRegion
+	}
+	return "https://bedrock-runtime." + region + ".amazonaws.com"
+}
+
+// DefaultBedrockRegion is the region used by Bedrock entries that do not set one.
+const DefaultBedrockRegion = "us-east-1"
+
 // CodexKey represents the configuration for a Codex API key,
 // including the API key itself and an optional base URL for the API endpoint.
 type CodexKey struct {
@@ -412,10 +572,15 @@ type GeminiModel struct {
 
 	// Alias is the client-facing model name that maps to Name.
 	Alias string `yaml:"alias" json:"alias"`
+
+	// Type optionally classifies the model (e.g., "embedding"). Embedding models
+	// are only served by the embeddings endpoints and never offered for chat.
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
 }
 
 func (m GeminiModel) GetName() string  { return m.Name }
 func (m GeminiModel) GetAlias() string { return m.Alias }
+func (m GeminiModel) GetType() string  { return m.Type }
 
 // KiroKey represents the configuration for Kiro (AWS CodeWhisperer) authentication.
 type KiroKey struct {
@@ -470,6 +635,10 @@ type OpenAICompatibility struct {
 
 	// Headers optionally adds extra HTTP headers for requests sent to this provider.
 	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+
+	// SupportsDocuments forwards OpenAI "file" content parts unchanged. When false, documents
+	// go through the documents fallback.
+	SupportsDocuments bool `yaml:"supports-documents,omitempty" json:"supports-documents,omitempty"`
 }
 
 // OpenAICompatibilityAPIKey represents an API key configuration with optional proxy setting.
@@ -489,10 +658,19 @@ type OpenAICompatibilityModel struct {
 
 	// Alias is the model name alias that clients will use to reference this model.
 	Alias string `yaml:"alias" json:"alias"`
+
+	// Type optionally classifies the model (e.g., "embedding"). Embedding models
+	// are only served by the embeddings endpoints and never offered for chat.
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+
+	// ToolEmulation renders tool definitions into the system prompt and parses tool calls
+	// out of the model's text, for models that reject or ignore native tools.
+	ToolEmulation bool `yaml:"tool-emulation,omitempty" json:"tool-emulation,omitempty"`
 }
 
 func (m OpenAICompatibilityModel) GetName() string  { return m.Name }
 func (m OpenAICompatibilityModel) GetAlias() string { return m.Alias }
+func (m OpenAICompatibilityModel) GetType() string  { return m.Type }
 
 // LoadConfig reads a YAML configuration file from the given path,
 // unmarshals it into a Config struct, applies environment variable overrides,
@@ -611,6 +789,9 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 	// Sanitize Kiro keys: trim whitespace from credential fields
 	cfg.SanitizeKiroKeys()
 
+	// Sanitize Bedrock keys: drop entries without credentials
+	cfg.SanitizeBedrockKeys()
+
 	// Sanitize OpenAI compatibility providers: drop entries without base-url
 	cfg.SanitizeOpenAICompatibility()
 
@@ -623,6 +804,9 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 	// Validate raw payload rules and drop invalid entries.
 	cfg.SanitizePayloadRules()
 
+	// Drop incomplete or duplicate virtual models.
+	cfg.SanitizeVirtualModels()
+
 	if cfg.legacyMigrationPending {
 		fmt.Println("Detected legacy configuration keys, attempting to persist the normalized config...")
 		if !optional && configFile != "" {
@@ -694,6 +878,42 @@ func payloadRawString(value any) ([]byte, bool) {
 	}
 }
 
+// SanitizeVirtualModels trims virtual model entries and drops those without a name or
+// target model, those targeting themselves, and duplicate names (first entry wins).
+func (cfg *Config) SanitizeVirtualModels() {
+	if cfg == nil || len(cfg.VirtualModels) == 0 {
+		return
+	}
+	seen := make(map[string]struct{}, len(cfg.VirtualModels))
+	out := make([]VirtualModel, 0, len(cfg.VirtualModels))
+	for _, entry := range cfg.VirtualModels {
+		entry.Name = strings.TrimSpace(entry.Name)
+		entry.Model = strings.TrimSpace(entry.Model)
+		entry.Thinking = strings.TrimSpace(entry.Thinking)
+		if entry.Name == "" || entry.Model == "" || strings.EqualFold(entry.Name, entry.Model) {
+			continue
+		}
+		key := strings.ToLower(entry.Name)
+		if _, ok := seen[key]; ok {
+			log.WithField("name", entry.Name).Warn("virtual model dropped: duplicate name")
+			continue
+		}
+		seen[key] = struct{}{}
+		tools := make([]string, 0, len(entry.AllowedTools))
+		for _, tool := range entry.AllowedTools {
+			if tool = strings.TrimSpace(tool); tool != "" {
+				tools = append(tools, tool)
+			}
+		}
+		if len(tools) == 0 {
+			tools = nil
+		}
+		entry.AllowedTools = tools
+		out = append(out, entry)
+	}
+	cfg.VirtualModels = out
+}
+
 // SanitizeOAuthModelAlias normalizes and deduplicates global OAuth model name aliases.
 // It trims whitespace, normalizes channel keys to lower-case, drops empty entries,
 // allows multiple aliases per upstream name, and ensures aliases are unique within each channel.
@@ -806,6 +1026,36 @@ func (cfg *Config) SanitizeKiroKeys() {
 	}
 }
 
+// SanitizeBedrockKeys trims Bedrock credential fields and removes entries that have
+// neither complete static credentials nor a profile. Incomplete static credentials are
+// dropped in favour of the profile.
+func (cfg *Config) SanitizeBedrockKeys() {
+	if cfg == nil || len(cfg.BedrockKey) == 0 {
+		return
+	}
+	out := make([]BedrockKey, 0, len(cfg.BedrockKey))
+	for i := range cfg.BedrockKey {
+		e := cfg.BedrockKey[i]
+		e.AccessKeyID = strings.TrimSpace(e.AccessKeyID)
+		e.SecretAccessKey = strings.TrimSpace(e.SecretAccessKey)
+		e.SessionToken = strings.TrimSpace(e.SessionToken)
+		e.Profile = strings.TrimSpace(e.Profile)
+		e.Region = strings.TrimSpace(e.Region)
+		e.Prefix = normalizeModelPrefix(e.Prefix)
+		e.BaseURL = strings.TrimRight(strings.TrimSpace(e.BaseURL), "/")
+		e.ProxyURL = strings.TrimSpace(e.ProxyURL)
+		e.ExcludedModels = NormalizeExcludedModels(e.ExcludedModels)
+		if e.AccessKeyID == "" || e.SecretAccessKey == "" {
+			if e.Profile == "" {
+				continue
+			}
+			e.AccessKeyID, e.SecretAccessKey, e.SessionToken = "", "", ""
+		}
+		out = append(out, e)
+	}
+	cfg.BedrockKey = out
+}
+
 // SanitizeGeminiKeys deduplicates and normalizes Gemini credentials.
 func (cfg *Config) SanitizeGeminiKeys() {
 	if cfg == nil {
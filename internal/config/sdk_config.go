@@ -29,6 +29,160 @@ type SDKConfig struct {
 	// NonStreamKeepAliveInterval controls how often blank lines are emitted for non-streaming responses.
 	// <= 0 disables keep-alives. Value is in seconds.
 	NonStreamKeepAliveInterval int `yaml:"nonstream-keepalive-interval,omitempty" json:"nonstream-keepalive-interval,omitempty"`
+
+	// MaxChoices bounds the n parameter of /v1/chat/completions. Requests with n > 1 run n
+	// parallel single-choice executions whose results are merged, so every provider honours
+	// n. Each execution picks its own credential through the routing strategy; distinct
+	// credentials are not enforced. Models served through the Responses API reject n > 1.
+	// Requests above the bound are rejected with 400. <= 0 uses 8.
+	MaxChoices int `yaml:"max-choices,omitempty" json:"max-choices,omitempty"`
+
+	// StructuredOutput configures enforcement of response_format json_schema and
+	// json_object on /v1/chat/completions. Claude messages and Responses requests are not
+	// enforced.
+	StructuredOutput StructuredOutputConfig `yaml:"structured-output,omitempty" json:"structured-output,omitempty"`
+
+	// VirtualModels defines client-visible model names that resolve to a real model plus
+	// parameter overrides, a system prompt and tool restrictions. They are listed in
+	// /v1/models while the target model is available and applied before translation for
+	// every request format.
+	VirtualModels []VirtualModel `yaml:"virtual-models,omitempty" json:"virtual-models,omitempty"`
+
+	// ResponsesStore configures storage of Responses API results used to expand
+	// previous_response_id and to serve /v1/responses/{id}.
+	ResponsesStore ResponsesStoreConfig `yaml:"responses-store,omitempty" json:"responses-store,omitempty"`
+
+	// ReasoningOutput controls how model reasoning is returned to clients, globally, per
+	// client API key and per model.
+	ReasoningOutput ReasoningOutputConfig `yaml:"reasoning-output,omitempty" json:"reasoning-output,omitempty"`
+
+	// ContextGuard estimates request tokens before sending and handles requests that
+	// exceed the model's context window.
+	ContextGuard ContextGuardConfig `yaml:"context-guard,omitempty" json:"context-guard,omitempty"`
+}
+
+// ContextGuardConfig configures the pre-flight context window check.
+type ContextGuardConfig struct {
+	// Enabled turns on token estimation and the context window check.
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// Strategy handles requests over the window: "reject" (default), "drop-oldest" or
+	// "truncate-tool-results".
+	Strategy string `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+
+	// ToolResultMaxChars is the size tool results are cut to by "truncate-tool-results".
+	// <= 0 uses 2000.
+	ToolResultMaxChars int `yaml:"tool-result-max-chars,omitempty" json:"tool-result-max-chars,omitempty"`
+}
+
+// ReasoningOutputPolicy selects how reasoning is returned to clients.
+type ReasoningOutputPolicy struct {
+	// Mode is "native" (default), "field", "tags", "summarize" or "strip".
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty"`
+
+	// Field names the field that carries reasoning in "field" mode (default "reasoning").
+	Field string `yaml:"field,omitempty" json:"field,omitempty"`
+
+	// Tag names the tag that wraps reasoning in the content in "tags" mode (default "think").
+	Tag string `yaml:"tag,omitempty" json:"tag,omitempty"`
+
+	// SummaryChars bounds the reasoning kept in "summarize" mode. <= 0 uses 280.
+	SummaryChars int `yaml:"summary-chars,omitempty" json:"summary-chars,omitempty"`
+}
+
+// ReasoningOutputConfig holds the global reasoning output policy and its overrides. Client
+// overrides take precedence over model overrides; unset fields of an override fall back to
+// the broader policy.
+type ReasoningOutputConfig struct {
+	ReasoningOutputPolicy `yaml:",inline"`
+
+	// Clients overrides the policy for requests authenticated with the listed API keys.
+	Clients []ReasoningOutputClient `yaml:"clients,omitempty" json:"clients,omitempty"`
+
+	// Models overrides the policy for matching models. Names may use '*' wildcards.
+	Models []ReasoningOutputModel `yaml:"models,omitempty" json:"models,omitempty"`
+}
+
+// ReasoningOutputClient is a reasoning output override for client API keys.
+type ReasoningOutputClient struct {
+	APIKeys               []string `yaml:"api-keys" json:"api-keys"`
+	ReasoningOutputPolicy `yaml:",inline"`
+}
+
+// ReasoningOutputModel is a reasoning output override for a model name or pattern.
+type ReasoningOutputModel struct {
+	Name                  string `yaml:"name" json:"name"`
+	ReasoningOutputPolicy `yaml:",inline"`
+}
+
+// StructuredOutputConfig holds structured output enforcement configuration.
+type StructuredOutputConfig struct {
+	// Enforce validates the final assistant message against the client's JSON schema and
+	// retries with a repair instruction when it does not match. Streaming requests are
+	// buffered while enforcement is on. Providers without a native JSON mode (Claude, Kiro,
+	// Antigravity) are asked to answer through a forced tool call instead.
+	Enforce bool `yaml:"enforce,omitempty" json:"enforce,omitempty"`
+
+	// MaxRepairs bounds the repair retries per choice. <= 0 uses 2.
+	MaxRepairs int `yaml:"max-repairs,omitempty" json:"max-repairs,omitempty"`
+}
+
+// VirtualModel is a named preset bundling a target model with request overrides.
+type VirtualModel struct {
+	// Name is the model ID clients request and see in model listings.
+	Name string `yaml:"name" json:"name"`
+
+	// Model is the real model the preset resolves to. It may carry a thinking suffix,
+	// e.g. "gpt-5(high)".
+	Model string `yaml:"model" json:"model"`
+
+	// Description is shown in model listings.
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+
+	// Thinking is a thinking suffix value (a level such as "high" or a token budget)
+	// applied when Model has no suffix. A suffix on the requested name takes precedence.
+	Thinking string `yaml:"thinking,omitempty" json:"thinking,omitempty"`
+
+	// Temperature overrides the request temperature when set.
+	Temperature *float64 `yaml:"temperature,omitempty" json:"temperature,omitempty"`
+
+	// MaxTokens overrides the request output token limit when > 0.
+	MaxTokens int `yaml:"max-tokens,omitempty" json:"max-tokens,omitempty"`
+
+	// SystemPrompt is prepended to the request's system prompt.
+	SystemPrompt string `yaml:"system-prompt,omitempty" json:"system-prompt,omitempty"`
+
+	// AllowedTools restricts request tools to these names; other tools are dropped.
+	AllowedTools []string `yaml:"allowed-tools,omitempty" json:"allowed-tools,omitempty"`
+
+	// DisableTools drops all request tools.
+	DisableTools bool `yaml:"disable-tools,omitempty" json:"disable-tools,omitempty"`
+}
+
+// ResponsesStoreConfig holds Responses API storage configuration.
+type ResponsesStoreConfig struct {
+	// Enabled turns on response storage. While it is off, previous_response_id is only
+	// accepted for models served by Codex, which resolves it upstream; requests for other
+	// models carrying it are rejected with 400, and /v1/responses/{id} returns 404.
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// Backend selects the storage backend: "memory" (default), "file", or "postgres".
+	Backend string `yaml:"backend,omitempty" json:"backend,omitempty"`
+
+	// TTLSeconds controls how long stored responses remain retrievable. <= 0 uses 3600.
+	TTLSeconds int `yaml:"ttl-seconds,omitempty" json:"ttl-seconds,omitempty"`
+
+	// MaxEntries bounds the memory backend. <= 0 uses 1024.
+	MaxEntries int `yaml:"max-entries,omitempty" json:"max-entries,omitempty"`
+
+	// Dir is the directory used by the file backend (default "responses" in the working directory).
+	Dir string `yaml:"dir,omitempty" json:"dir,omitempty"`
+
+	// DSN is the PostgreSQL connection string used by the postgres backend.
+	DSN string `yaml:"dsn,omitempty" json:"dsn,omitempty"`
+
+	// Table overrides the PostgreSQL table name (default "responses_store").
+	Table string `yaml:"table,omitempty" json:"table,omitempty"`
 }
 
 // StreamingConfig holds server streaming behavior configuration.
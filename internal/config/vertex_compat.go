@@ -47,10 +47,15 @@ type VertexCompatModel struct {
 
 	// Alias is the model name alias that clients will use to reference this model.
 	Alias string `yaml:"alias" json:"alias"`
+
+	// Type optionally classifies the model (e.g., "embedding"). Embedding models
+	// are only served by the embeddings endpoints and never offered for chat.
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
 }
 
 func (m VertexCompatModel) GetName() string  { return m.Name }
 func (m VertexCompatModel) GetAlias() string { return m.Alias }
+func (m VertexCompatModel) GetType() string  { return m.Type }
 
 // SanitizeVertexCompatKeys deduplicates and normalizes Vertex-compatible API key credentials.
 func (cfg *Config) SanitizeVertexCompatKeys() {
@@ -22,9 +22,15 @@ const (
 	// OpenaiResponse represents the OpenAI response format identifier.
 	OpenaiResponse = "openai-response"
 
+	// OpenAIEmbeddings represents the OpenAI embeddings request format identifier.
+	OpenAIEmbeddings = "openai-embeddings"
+
 	// Antigravity represents the Antigravity response format identifier.
 	Antigravity = "antigravity"
 
 	// Kiro represents the AWS CodeWhisperer (Kiro) provider identifier.
 	Kiro = "kiro"
+
+	// Bedrock represents the AWS Bedrock Converse provider identifier.
+	Bedrock = "bedrock"
 )
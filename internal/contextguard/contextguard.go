@@ -0,0 +1,401 @@
+// Package contextguard keeps requests within a model's context window before they are
+// sent upstream. Requests over the limit are either rejected with a
+// context_length_exceeded error in the client's format or shortened by a strategy:
+// dropping the oldest turns or truncating large tool results.
+package contextguard
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Strategies applied to requests over the context window.
+const (
+	// StrategyReject rejects the request.
+	StrategyReject = "reject"
+	// StrategyDropOldest drops the oldest turns, keeping system messages, tool calls
+	// together with their results, and the latest turn.
+	StrategyDropOldest = "drop-oldest"
+	// StrategyTruncateToolResults cuts tool results longer than the configured size,
+	// oldest first.
+	StrategyTruncateToolResults = "truncate-tool-results"
+)
+
+// DefaultToolResultMaxChars is the size tool results are cut to when none is configured.
+const DefaultToolResultMaxChars = 2000
+
+// Counter estimates the prompt tokens of a request payload in the guarded format.
+type Counter func(payload []byte) (int64, error)
+
+// ExceededError reports a request that does not fit the context window.
+type ExceededError struct {
+	Estimated int64
+	Limit     int64
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("This model's maximum context length is %d tokens. However, your request is estimated at %d tokens. Please reduce the length of the messages.", e.Limit, e.Estimated)
+}
+
+// Options configures Fit.
+type Options struct {
+	// Strategy is one of the Strategy constants. Empty means StrategyReject.
+	Strategy string
+	// ToolResultMaxChars bounds tool results for StrategyTruncateToolResults. <= 0 uses
+	// DefaultToolResultMaxChars.
+	ToolResultMaxChars int
+}
+
+// Fit estimates the request and, when it exceeds limit, applies the configured strategy.
+// It returns the payload to send and its estimate, or an *ExceededError when the request
+// cannot be made to fit. A limit <= 0 only estimates.
+func Fit(format string, payload []byte, limit int64, opts Options, count Counter) ([]byte, int64, error) {
+	estimate, err := count(payload)
+	if err != nil {
+		return payload, 0, err
+	}
+	if limit <= 0 || estimate <= limit {
+		return payload, estimate, nil
+	}
+	spec, ok := specFor(format, payload)
+	if !ok {
+		return payload, estimate, &ExceededError{Estimated: estimate, Limit: limit}
+	}
+
+	out := payload
+	switch opts.Strategy {
+	case StrategyDropOldest:
+		out = dropOldest(spec, payload, estimate, limit, count)
+	case StrategyTruncateToolResults:
+		maxChars := opts.ToolResultMaxChars
+		if maxChars <= 0 {
+			maxChars = DefaultToolResultMaxChars
+		}
+		out = truncateToolResults(spec, payload, estimate, limit, maxChars, count)
+	default:
+		return payload, estimate, &ExceededError{Estimated: estimate, Limit: limit}
+	}
+	if estimate, err = count(out); err != nil {
+		return payload, 0, err
+	}
+	if estimate > limit {
+		return payload, estimate, &ExceededError{Estimated: estimate, Limit: limit}
+	}
+	return out, estimate, nil
+}
+
+// ErrorBody renders err as a context_length_exceeded error in the client format.
+func ErrorBody(format string, err *ExceededError) []byte {
+	var body string
+	switch format {
+	case "claude":
+		body = `{"type":"error","error":{"type":"invalid_request_error","message":""}}`
+		body, _ = sjson.Set(body, "error.message", "prompt is too long: "+err.Error())
+	case "gemini", "gemini-cli":
+		body = `{"error":{"code":400,"message":"","status":"INVALID_ARGUMENT"}}`
+		body, _ = sjson.Set(body, "error.message", err.Error())
+	default:
+		param := "messages"
+		if format == "openai-response" {
+			param = "input"
+		}
+		body = `{"error":{"message":"","type":"invalid_request_error","param":"","code":"context_length_exceeded"}}`
+		body, _ = sjson.Set(body, "error.message", err.Error())
+		body, _ = sjson.Set(body, "error.param", param)
+	}
+	return []byte(body)
+}
+
+// spec describes the conversation of one request format.
+type spec struct {
+	format string
+	// path is the conversation array.
+	path string
+	// pinned items are never dropped (system and developer messages).
+	pinned func(item gjson.Result) bool
+	// attached items belong with the item before them (tool results).
+	attached func(item gjson.Result) bool
+	// callID, when set, returns the call id of tool calls and their results. Consecutive
+	// calls share a group, and each result joins the group of the call it answers.
+	callID func(item gjson.Result) string
+	// userFirst requires the conversation to start with a user turn.
+	userFirst bool
+}
+
+func specFor(format string, payload []byte) (spec, bool) {
+	switch format {
+	case "openai":
+		return spec{
+			format:   format,
+			path:     "messages",
+			pinned:   func(item gjson.Result) bool { return isSystemRole(item.Get("role").String()) },
+			attached: func(item gjson.Result) bool { return item.Get("role").String() == "tool" },
+		}, true
+	case "claude":
+		return spec{
+			format:    format,
+			path:      "messages",
+			pinned:    func(gjson.Result) bool { return false },
+			attached:  func(item gjson.Result) bool { return hasBlock(item.Get("content"), "tool_result") },
+			userFirst: true,
+		}, true
+	case "openai-response":
+		if !gjson.GetBytes(payload, "input").IsArray() {
+			return spec{}, false
+		}
+		return spec{
+			format: format,
+			path:   "input",
+			pinned: func(item gjson.Result) bool {
+				typ := item.Get("type").String()
+				return (typ == "" || typ == "message") && isSystemRole(item.Get("role").String())
+			},
+			attached: func(item gjson.Result) bool {
+				return strings.HasSuffix(item.Get("type").String(), "_call_output")
+			},
+			callID: func(item gjson.Result) string { return item.Get("call_id").String() },
+		}, true
+	case "gemini", "gemini-cli":
+		path := "contents"
+		if gjson.GetBytes(payload, "request.contents").IsArray() {
+			path = "request.contents"
+		}
+		return spec{
+			format: format,
+			path:   path,
+			pinned: func(gjson.Result) bool { return false },
+			attached: func(item gjson.Result) bool {
+				found := false
+				item.Get("parts").ForEach(func(_, part gjson.Result) bool {
+					found = part.Get("functionResponse").Exists()
+					return !found
+				})
+				return found
+			},
+			userFirst: true,
+		}, true
+	}
+	return spec{}, false
+}
+
+// single builds a payload holding only the given conversation items, used to estimate
+// their share of the request.
+func (s spec) single(items ...string) []byte {
+	out, _ := sjson.SetRawBytes([]byte(`{}`), s.path, []byte("["+strings.Join(items, ",")+"]"))
+	return out
+}
+
+// dropOldest removes the oldest groups of turns until the estimate fits. Each group is a
+// turn together with the tool results attached to it; pinned items and the latest group
+// are kept.
+func dropOldest(s spec, payload []byte, estimate, limit int64, count Counter) []byte {
+	items := gjson.GetBytes(payload, s.path).Array()
+	var groups [][]int
+	callGroups := make(map[string]int)
+	prevCall := false
+	for i, item := range items {
+		if s.pinned(item) {
+			continue
+		}
+		id := ""
+		if s.callID != nil {
+			id = s.callID(item)
+		}
+		if len(groups) > 0 && s.attached(item) {
+			g, ok := callGroups[id]
+			if id == "" || !ok {
+				g = len(groups) - 1
+			}
+			groups[g] = append(groups[g], i)
+			prevCall = false
+			continue
+		}
+		isCall := id != ""
+		if isCall && prevCall {
+			groups[len(groups)-1] = append(groups[len(groups)-1], i)
+		} else {
+			groups = append(groups, []int{i})
+		}
+		if isCall {
+			callGroups[id] = len(groups) - 1
+		}
+		prevCall = isCall
+	}
+
+	dropped := make(map[int]bool)
+	drop := func(group []int) {
+		raw := make([]string, 0, len(group))
+		for _, i := range group {
+			dropped[i] = true
+			raw = append(raw, items[i].Raw)
+		}
+		if tokens, err := count(s.single(raw...)); err == nil {
+			estimate -= tokens
+		}
+	}
+	next := 0
+	for ; next < len(groups)-1 && estimate > limit; next++ {
+		drop(groups[next])
+	}
+	if next == 0 {
+		return payload
+	}
+	// Claude and Gemini conversations must start with a user turn.
+	for s.userFirst && next < len(groups)-1 && !isUserTurn(items[groups[next][0]]) {
+		drop(groups[next])
+		next++
+	}
+
+	kept := make([]string, 0, len(items))
+	for i, item := range items {
+		if !dropped[i] {
+			kept = append(kept, item.Raw)
+		}
+	}
+	out, _ := sjson.SetRawBytes(payload, s.path, []byte("["+strings.Join(kept, ",")+"]"))
+	return out
+}
+
+// truncateToolResults cuts tool results longer than maxChars, oldest first, until the
+// estimate fits.
+func truncateToolResults(s spec, payload []byte, estimate, limit int64, maxChars int, count Counter) []byte {
+	items := gjson.GetBytes(payload, s.path).Array()
+	raw := make([]string, len(items))
+	changed := false
+	for i, item := range items {
+		raw[i] = item.Raw
+		if estimate <= limit {
+			continue
+		}
+		truncated, ok := truncateItem(s.format, item, maxChars)
+		if !ok {
+			continue
+		}
+		before, errBefore := count(s.single(item.Raw))
+		after, errAfter := count(s.single(truncated))
+		if errBefore == nil && errAfter == nil {
+			estimate -= before - after
+		}
+		raw[i] = truncated
+		changed = true
+	}
+	if !changed {
+		return payload
+	}
+	out, _ := sjson.SetRawBytes(payload, s.path, []byte("["+strings.Join(raw, ",")+"]"))
+	return out
+}
+
+// truncateItem cuts the tool results carried by one conversation item.
+func truncateItem(format string, item gjson.Result, maxChars int) (string, bool) {
+	out := item.Raw
+	changed := false
+	switch format {
+	case "openai":
+		if item.Get("role").String() != "tool" {
+			return out, false
+		}
+		if text, ok := truncateText(textOf(item.Get("content")), maxChars); ok {
+			out, _ = sjson.Set(out, "content", text)
+			changed = true
+		}
+	case "claude":
+		item.Get("content").ForEach(func(key, block gjson.Result) bool {
+			if block.Get("type").String() != "tool_result" {
+				return true
+			}
+			path := "content." + key.String() + ".content"
+			content := block.Get("content")
+			if content.IsArray() && hasNonText(content) {
+				return true
+			}
+			if text, ok := truncateText(textOf(content), maxChars); ok {
+				out, _ = sjson.Set(out, path, text)
+				changed = true
+			}
+			return true
+		})
+	case "openai-response":
+		output := item.Get("output")
+		if !strings.HasSuffix(item.Get("type").String(), "_call_output") || output.Type != gjson.String {
+			return out, false
+		}
+		if text, ok := truncateText(output.String(), maxChars); ok {
+			out, _ = sjson.Set(out, "output", text)
+			changed = true
+		}
+	case "gemini", "gemini-cli":
+		item.Get("parts").ForEach(func(key, part gjson.Result) bool {
+			response := part.Get("functionResponse.response")
+			if !response.Exists() {
+				return true
+			}
+			if text, ok := truncateText(response.Raw, maxChars); ok {
+				out, _ = sjson.Set(out, "parts."+key.String()+".functionResponse.response", map[string]string{"result": text})
+				changed = true
+			}
+			return true
+		})
+	}
+	return out, changed
+}
+
+// truncateText cuts text to maxChars runes, noting how much was removed.
+func truncateText(text string, maxChars int) (string, bool) {
+	total := utf8.RuneCountInString(text)
+	if total <= maxChars {
+		return text, false
+	}
+	runes := []rune(text)
+	return fmt.Sprintf("%s\n[... %d characters truncated]", string(runes[:maxChars]), total-maxChars), true
+}
+
+// textOf returns the text of a string content or of the text parts of an array content.
+func textOf(content gjson.Result) string {
+	if !content.IsArray() {
+		return content.String()
+	}
+	var parts []string
+	content.ForEach(func(_, part gjson.Result) bool {
+		if text := part.Get("text"); text.Exists() {
+			parts = append(parts, text.String())
+		}
+		return true
+	})
+	return strings.Join(parts, "\n")
+}
+
+func hasNonText(content gjson.Result) bool {
+	found := false
+	content.ForEach(func(_, part gjson.Result) bool {
+		found = part.Get("type").String() != "text"
+		return !found
+	})
+	return found
+}
+
+func hasBlock(content gjson.Result, typ string) bool {
+	found := false
+	content.ForEach(func(_, block gjson.Result) bool {
+		found = block.Get("type").String() == typ
+		return !found
+	})
+	return found
+}
+
+func isSystemRole(role string) bool {
+	return role == "system" || role == "developer"
+}
+
+// isUserTurn reports whether a Claude or Gemini item is a user turn that is not a tool
+// result. Gemini contents without a role are user turns.
+func isUserTurn(item gjson.Result) bool {
+	role := item.Get("role").String()
+	return (role == "user" || role == "") &&
+		!hasBlock(item.Get("content"), "tool_result") &&
+		!strings.Contains(item.Get("parts").Raw, `"functionResponse"`)
+}
@@ -0,0 +1,152 @@
+package contextguard
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+// countChars is a deterministic stand-in for a tokenizer: one token per byte of the
+// conversation and system fields.
+func countChars(payload []byte) (int64, error) {
+	root := gjson.ParseBytes(payload)
+	var total int64
+	for _, path := range []string{"messages", "input", "contents", "request.contents", "system"} {
+		total += int64(len(root.Get(path).Raw))
+	}
+	return total, nil
+}
+
+func TestFitUnderLimitLeavesPayload(t *testing.T) {
+	payload := []byte(`{"messages":[{"role":"user","content":"hi"}]}`)
+	out, estimate, err := Fit("openai", payload, 1000, Options{}, countChars)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(out) != string(payload) || estimate <= 0 {
+		t.Fatalf("out=%s estimate=%d", out, estimate)
+	}
+}
+
+func TestFitRejectsByDefault(t *testing.T) {
+	payload := []byte(`{"messages":[{"role":"user","content":"` + strings.Repeat("x", 200) + `"}]}`)
+	_, _, err := Fit("openai", payload, 50, Options{}, countChars)
+	var exceeded *ExceededError
+	if !errors.As(err, &exceeded) {
+		t.Fatalf("expected ExceededError, got %v", err)
+	}
+	if exceeded.Limit != 50 || exceeded.Estimated <= 50 {
+		t.Fatalf("unexpected error values: %+v", exceeded)
+	}
+}
+
+func TestFitDropOldestKeepsSystemAndToolPairs(t *testing.T) {
+	long := strings.Repeat("a", 300)
+	payload := []byte(`{"messages":[
+		{"role":"system","content":"be brief"},
+		{"role":"user","content":"` + long + `"},
+		{"role":"assistant","content":null,"tool_calls":[{"id":"c1","type":"function","function":{"name":"f","arguments":"{}"}}]},
+		{"role":"tool","tool_call_id":"c1","content":"` + long + `"},
+		{"role":"user","content":"latest question"}
+	]}`)
+	out, _, err := Fit("openai", payload, 150, Options{Strategy: StrategyDropOldest}, countChars)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	messages := gjson.GetBytes(out, "messages").Array()
+	if len(messages) != 2 {
+		t.Fatalf("expected system and latest user message, got %s", gjson.GetBytes(out, "messages").Raw)
+	}
+	if messages[0].Get("role").String() != "system" || messages[1].Get("content").String() != "latest question" {
+		t.Fatalf("unexpected messages: %s", gjson.GetBytes(out, "messages").Raw)
+	}
+}
+
+func TestFitDropOldestClaudeStartsWithUser(t *testing.T) {
+	long := strings.Repeat("b", 200)
+	payload := []byte(`{"messages":[
+		{"role":"user","content":"` + long + `"},
+		{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"f","input":{}}]},
+		{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]},
+		{"role":"assistant","content":"done"},
+		{"role":"user","content":"next"}
+	]}`)
+	out, _, err := Fit("claude", payload, 200, Options{Strategy: StrategyDropOldest}, countChars)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	messages := gjson.GetBytes(out, "messages").Array()
+	if len(messages) == 0 || messages[0].Get("role").String() != "user" || messages[0].Get("content").IsArray() {
+		t.Fatalf("conversation must start with a plain user turn: %s", gjson.GetBytes(out, "messages").Raw)
+	}
+	for _, message := range messages {
+		if strings.Contains(message.Raw, "tool_result") || strings.Contains(message.Raw, "tool_use") {
+			t.Fatalf("tool pair only partially dropped: %s", gjson.GetBytes(out, "messages").Raw)
+		}
+	}
+}
+
+func TestFitDropOldestKeepsParallelResponsesCalls(t *testing.T) {
+	long := strings.Repeat("p", 200)
+	payload := []byte(`{"input":[
+		{"type":"message","role":"developer","content":"be brief"},
+		{"type":"function_call","call_id":"a","name":"f","arguments":"{\"q\":\"` + long + `\"}"},
+		{"type":"function_call","call_id":"b","name":"g","arguments":"{}"},
+		{"type":"function_call_output","call_id":"a","output":"ok"},
+		{"type":"function_call_output","call_id":"b","output":"ok"},
+		{"type":"message","role":"user","content":"latest question"}
+	]}`)
+	out, _, err := Fit("openai-response", payload, 350, Options{Strategy: StrategyDropOldest}, countChars)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	calls := make(map[string]bool)
+	for _, item := range gjson.GetBytes(out, "input").Array() {
+		switch item.Get("type").String() {
+		case "function_call":
+			calls[item.Get("call_id").String()] = true
+		case "function_call_output":
+			if !calls[item.Get("call_id").String()] {
+				t.Fatalf("output %s kept without its call: %s", item.Get("call_id"), gjson.GetBytes(out, "input").Raw)
+			}
+		}
+	}
+	input := gjson.GetBytes(out, "input").Array()
+	if len(input) != 2 || input[0].Get("role").String() != "developer" || input[1].Get("content").String() != "latest question" {
+		t.Fatalf("parallel calls and their outputs must be dropped together: %s", gjson.GetBytes(out, "input").Raw)
+	}
+}
+
+func TestFitTruncatesToolResults(t *testing.T) {
+	payload := []byte(`{"input":[
+		{"type":"message","role":"user","content":"run it"},
+		{"type":"function_call","call_id":"c1","name":"f","arguments":"{}"},
+		{"type":"function_call_output","call_id":"c1","output":"` + strings.Repeat("z", 500) + `"}
+	]}`)
+	out, _, err := Fit("openai-response", payload, 300, Options{Strategy: StrategyTruncateToolResults, ToolResultMaxChars: 50}, countChars)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	output := gjson.GetBytes(out, "input.2.output").String()
+	if !strings.HasPrefix(output, strings.Repeat("z", 50)+"\n[... 450 characters truncated]") {
+		t.Fatalf("unexpected truncated output: %q", output)
+	}
+}
+
+func TestErrorBodyPerFormat(t *testing.T) {
+	err := &ExceededError{Estimated: 120, Limit: 100}
+	if got := gjson.GetBytes(ErrorBody("openai", err), "error.code").String(); got != "context_length_exceeded" {
+		t.Fatalf("openai code = %q", got)
+	}
+	if got := gjson.GetBytes(ErrorBody("claude", err), "type").String(); got != "error" {
+		t.Fatalf("claude type = %q", got)
+	}
+	if got := gjson.GetBytes(ErrorBody("gemini", err), "error.status").String(); got != "INVALID_ARGUMENT" {
+		t.Fatalf("gemini status = %q", got)
+	}
+	if got := gjson.GetBytes(ErrorBody("openai-response", err), "error.param").String(); got != "input" {
+		t.Fatalf("responses param = %q", got)
+	}
+}
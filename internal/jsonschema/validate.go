@@ -0,0 +1,459 @@
+// Package jsonschema validates JSON documents against the subset of JSON Schema used for
+// structured outputs: types, enums, object properties, arrays, string and numeric
+// constraints, combinators and local $ref pointers. Unknown keywords such as format are
+// ignored.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// maxProblems bounds the number of reported problems per document.
+const maxProblems = 20
+
+// Schema is a compiled JSON schema. It is safe for concurrent use.
+type Schema struct {
+	root     any
+	mu       sync.Mutex
+	patterns map[string]*regexp.Regexp
+}
+
+// Compile parses a JSON schema document.
+func Compile(raw []byte) (*Schema, error) {
+	var root any
+	if err := json.Unmarshal(raw, &root); err != nil {
+		return nil, fmt.Errorf("jsonschema: invalid schema: %w", err)
+	}
+	switch root.(type) {
+	case map[string]any, bool:
+	default:
+		return nil, fmt.Errorf("jsonschema: schema must be an object or boolean")
+	}
+	return &Schema{root: root, patterns: make(map[string]*regexp.Regexp)}, nil
+}
+
+// Validate checks a JSON document against the schema. It returns the problems found,
+// each prefixed with the JSON path of the offending value, or nil when the document
+// is valid. A document that is not JSON yields a single problem.
+func (s *Schema) Validate(doc []byte) []string {
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return []string{fmt.Sprintf("invalid JSON: %v", err)}
+	}
+	if decoder.More() {
+		return []string{"invalid JSON: unexpected data after the top-level value"}
+	}
+	v := &validator{schema: s}
+	v.validate("$", s.root, value)
+	return v.problems
+}
+
+type validator struct {
+	schema   *Schema
+	problems []string
+	depth    int
+}
+
+func (v *validator) fail(path, format string, args ...any) {
+	if len(v.problems) < maxProblems {
+		v.problems = append(v.problems, path+": "+fmt.Sprintf(format, args...))
+	}
+}
+
+// valid reports whether value satisfies schema without recording problems.
+func (v *validator) valid(path string, schema, value any) bool {
+	sub := &validator{schema: v.schema, depth: v.depth}
+	sub.validate(path, schema, value)
+	return len(sub.problems) == 0
+}
+
+func (v *validator) validate(path string, schema, value any) {
+	switch typed := schema.(type) {
+	case bool:
+		if !typed {
+			v.fail(path, "no value is allowed here")
+		}
+		return
+	case map[string]any:
+		v.depth++
+		defer func() { v.depth-- }()
+		if v.depth > 64 {
+			v.fail(path, "schema nesting too deep")
+			return
+		}
+		v.validateObjectSchema(path, typed, value)
+	}
+}
+
+func (v *validator) validateObjectSchema(path string, schema map[string]any, value any) {
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := v.resolve(ref)
+		if err != nil {
+			v.fail(path, "%v", err)
+			return
+		}
+		v.validate(path, target, value)
+	}
+
+	if !v.checkType(path, schema, value) {
+		return
+	}
+	if enum, ok := schema["enum"].([]any); ok {
+		matched := false
+		for _, candidate := range enum {
+			if equal(candidate, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail(path, "value must be one of %s", compact(enum))
+		}
+	}
+	if constant, ok := schema["const"]; ok && !equal(constant, value) {
+		v.fail(path, "value must be %s", compact(constant))
+	}
+
+	for _, sub := range schemaList(schema["allOf"]) {
+		v.validate(path, sub, value)
+	}
+	if anyOf := schemaList(schema["anyOf"]); len(anyOf) > 0 {
+		matched := false
+		for _, sub := range anyOf {
+			if v.valid(path, sub, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail(path, "value does not match any allowed schema")
+		}
+	}
+	if oneOf := schemaList(schema["oneOf"]); len(oneOf) > 0 {
+		matches := 0
+		for _, sub := range oneOf {
+			if v.valid(path, sub, value) {
+				matches++
+			}
+		}
+		if matches != 1 {
+			v.fail(path, "value must match exactly one schema, matched %d", matches)
+		}
+	}
+	if not, ok := schema["not"]; ok && v.valid(path, not, value) {
+		v.fail(path, "value matches a disallowed schema")
+	}
+
+	switch typed := value.(type) {
+	case map[string]any:
+		v.validateObject(path, schema, typed)
+	case []any:
+		v.validateArray(path, schema, typed)
+	case string:
+		v.validateString(path, schema, typed)
+	case json.Number:
+		v.validateNumber(path, schema, typed)
+	}
+}
+
+// checkType validates the type keyword, including the OpenAPI nullable extension. It
+// returns false when the value has the wrong type, so that further keywords are skipped.
+func (v *validator) checkType(path string, schema map[string]any, value any) bool {
+	var allowed []string
+	switch typed := schema["type"].(type) {
+	case string:
+		allowed = []string{typed}
+	case []any:
+		for _, item := range typed {
+			if name, ok := item.(string); ok {
+				allowed = append(allowed, name)
+			}
+		}
+	default:
+		return true
+	}
+	if nullable, _ := schema["nullable"].(bool); nullable {
+		allowed = append(allowed, "null")
+	}
+	for _, name := range allowed {
+		if hasType(name, value) {
+			return true
+		}
+	}
+	v.fail(path, "expected %s, got %s", strings.Join(allowed, " or "), typeName(value))
+	return false
+}
+
+func (v *validator) validateObject(path string, schema map[string]any, object map[string]any) {
+	properties, _ := schema["properties"].(map[string]any)
+	for _, name := range stringList(schema["required"]) {
+		if _, ok := object[name]; !ok {
+			v.fail(path, "missing required property %q", name)
+		}
+	}
+	if minimum, ok := number(schema["minProperties"]); ok && float64(len(object)) < minimum {
+		v.fail(path, "expected at least %v properties", minimum)
+	}
+	if maximum, ok := number(schema["maxProperties"]); ok && float64(len(object)) > maximum {
+		v.fail(path, "expected at most %v properties", maximum)
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	additional, hasAdditional := schema["additionalProperties"]
+	for _, key := range keys {
+		childPath := path + "." + key
+		if sub, ok := properties[key]; ok {
+			v.validate(childPath, sub, object[key])
+			continue
+		}
+		if !hasAdditional {
+			continue
+		}
+		if allowed, ok := additional.(bool); ok {
+			if !allowed {
+				v.fail(path, "unexpected property %q", key)
+			}
+			continue
+		}
+		v.validate(childPath, additional, object[key])
+	}
+}
+
+func (v *validator) validateArray(path string, schema map[string]any, array []any) {
+	if minimum, ok := number(schema["minItems"]); ok && float64(len(array)) < minimum {
+		v.fail(path, "expected at least %v items, got %d", minimum, len(array))
+	}
+	if maximum, ok := number(schema["maxItems"]); ok && float64(len(array)) > maximum {
+		v.fail(path, "expected at most %v items, got %d", maximum, len(array))
+	}
+	prefix := schemaList(schema["prefixItems"])
+	items := schema["items"]
+	if tuple := schemaList(items); len(tuple) > 0 {
+		// Draft 7 tuple form.
+		prefix, items = tuple, schema["additionalItems"]
+	}
+	for i, item := range array {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i < len(prefix):
+			v.validate(itemPath, prefix[i], item)
+		case items != nil:
+			v.validate(itemPath, items, item)
+		}
+	}
+	if unique, _ := schema["uniqueItems"].(bool); unique {
+		for i := range array {
+			for j := i + 1; j < len(array); j++ {
+				if equal(array[i], array[j]) {
+					v.fail(path, "items %d and %d are equal but must be unique", i, j)
+					return
+				}
+			}
+		}
+	}
+}
+
+func (v *validator) validateString(path string, schema map[string]any, value string) {
+	length := float64(utf8.RuneCountInString(value))
+	if minimum, ok := number(schema["minLength"]); ok && length < minimum {
+		v.fail(path, "expected at least %v characters", minimum)
+	}
+	if maximum, ok := number(schema["maxLength"]); ok && length > maximum {
+		v.fail(path, "expected at most %v characters", maximum)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := v.schema.pattern(pattern)
+		if err == nil && !re.MatchString(value) {
+			v.fail(path, "value does not match pattern %q", pattern)
+		}
+	}
+}
+
+func (v *validator) validateNumber(path string, schema map[string]any, raw json.Number) {
+	value, err := raw.Float64()
+	if err != nil {
+		return
+	}
+	if minimum, ok := number(schema["minimum"]); ok && value < minimum {
+		v.fail(path, "expected a value >= %v", minimum)
+	}
+	if maximum, ok := number(schema["maximum"]); ok && value > maximum {
+		v.fail(path, "expected a value <= %v", maximum)
+	}
+	if minimum, ok := number(schema["exclusiveMinimum"]); ok && value <= minimum {
+		v.fail(path, "expected a value > %v", minimum)
+	}
+	if maximum, ok := number(schema["exclusiveMaximum"]); ok && value >= maximum {
+		v.fail(path, "expected a value < %v", maximum)
+	}
+	if factor, ok := number(schema["multipleOf"]); ok && factor > 0 {
+		if quotient := value / factor; math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			v.fail(path, "expected a multiple of %v", factor)
+		}
+	}
+}
+
+// resolve follows a local JSON pointer such as "#/$defs/item".
+func (v *validator) resolve(ref string) (any, error) {
+	if ref == "#" {
+		return v.schema.root, nil
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported $ref %q", ref)
+	}
+	current := v.schema.root
+	for _, token := range strings.Split(ref[2:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+		if current, ok = object[token]; !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+	}
+	return current, nil
+}
+
+func (s *Schema) pattern(expr string) (*regexp.Regexp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if re, ok := s.patterns[expr]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	s.patterns[expr] = re
+	return re, nil
+}
+
+func hasType(name string, value any) bool {
+	switch name {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		f, err := n.Float64()
+		return err == nil && f == math.Trunc(f)
+	}
+	return false
+}
+
+func typeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func schemaList(value any) []any {
+	list, _ := value.([]any)
+	return list
+}
+
+func stringList(value any) []string {
+	var out []string
+	for _, item := range schemaList(value) {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func number(value any) (float64, bool) {
+	switch typed := value.(type) {
+	case json.Number:
+		f, err := typed.Float64()
+		return f, err == nil
+	case float64:
+		return typed, true
+	}
+	return 0, false
+}
+
+// equal compares two decoded JSON values, treating numbers by value.
+func equal(a, b any) bool {
+	if na, ok := number(a); ok {
+		nb, ok := number(b)
+		return ok && na == nb
+	}
+	switch ta := a.(type) {
+	case map[string]any:
+		tb, ok := b.(map[string]any)
+		if !ok || len(ta) != len(tb) {
+			return false
+		}
+		for key, value := range ta {
+			if other, ok := tb[key]; !ok || !equal(value, other) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		tb, ok := b.([]any)
+		if !ok || len(ta) != len(tb) {
+			return false
+		}
+		for i := range ta {
+			if !equal(ta[i], tb[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+func compact(value any) string {
+	out, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(out)
+}
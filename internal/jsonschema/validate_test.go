@@ -0,0 +1,47 @@
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	schema, err := Compile([]byte(`{
+		"type": "object",
+		"required": ["name", "tags", "kind"],
+		"additionalProperties": false,
+		"properties": {
+			"name": {"type": "string", "minLength": 2, "pattern": "^[a-z]+$"},
+			"age": {"type": ["integer", "null"], "minimum": 0},
+			"tags": {"type": "array", "items": {"$ref": "#/$defs/tag"}, "maxItems": 2, "uniqueItems": true},
+			"kind": {"enum": ["a", "b"]},
+			"score": {"anyOf": [{"type": "number", "multipleOf": 0.5}, {"type": "string"}]}
+		},
+		"$defs": {"tag": {"type": "string", "maxLength": 3}}
+	}`))
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+
+	if problems := schema.Validate([]byte(`{"name":"bob","age":null,"tags":["x","yz"],"kind":"a","score":1.5}`)); problems != nil {
+		t.Fatalf("expected a valid document, got %v", problems)
+	}
+
+	cases := map[string]string{
+		`{"name":"bob","tags":[],"kind":"a","extra":1}`:   `unexpected property "extra"`,
+		`{"name":"bob","tags":[]}`:                        `missing required property "kind"`,
+		`{"name":"Bob","tags":[],"kind":"a"}`:             `$.name: value does not match pattern`,
+		`{"name":"bob","age":1.5,"tags":[],"kind":"a"}`:   `$.age: expected integer or null, got number`,
+		`{"name":"bob","tags":["abcd"],"kind":"a"}`:       `$.tags[0]: expected at most 3 characters`,
+		`{"name":"bob","tags":["a","a"],"kind":"a"}`:      `must be unique`,
+		`{"name":"bob","tags":[],"kind":"c"}`:             `$.kind: value must be one of ["a","b"]`,
+		`{"name":"bob","tags":[],"kind":"a","score":0.3}`: `$.score: value does not match any allowed schema`,
+		`{"name":"bob"`: `invalid JSON`,
+	}
+	for doc, want := range cases {
+		problems := schema.Validate([]byte(doc))
+		if !strings.Contains(strings.Join(problems, "\n"), want) {
+			t.Fatalf("validate %s: expected %q in %v", doc, want, problems)
+		}
+	}
+}
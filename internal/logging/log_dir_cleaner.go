@@ -153,6 +153,12 @@ func enforceLogDirSizeLimit(logDir string, maxBytes int64, protectedPath string)
 		deleted++
 	}
 
+	if deleted > 0 {
+		if _, errPrune := PruneRequestLogIndex(dir); errPrune != nil {
+			log.WithError(errPrune).Warn("logging: failed to prune request log index")
+		}
+	}
+
 	return deleted, nil
 }
 
@@ -0,0 +1,313 @@
+package logging
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// RequestLogIndexFileName is the JSONL sidecar stored next to request log files.
+// It intentionally does not use the ".log" suffix so the size-based cleaner never deletes it.
+const RequestLogIndexFileName = "request-logs-index.jsonl"
+
+const (
+	ginHandlerFormatKey = "__request_handler_format__"
+	ginRequestUsageKey  = "__request_usage__"
+
+	requestLogIndexScannerMaxBuffer = 1024 * 1024
+)
+
+// requestLogIndexMu serialises appends and rewrites of every index file in the process.
+var requestLogIndexMu sync.Mutex
+
+// RequestLogIndexEntry describes a single logged request in the index sidecar.
+type RequestLogIndexEntry struct {
+	RequestID       string    `json:"request_id"`
+	Timestamp       time.Time `json:"timestamp"`
+	File            string    `json:"file"`
+	Method          string    `json:"method"`
+	URL             string    `json:"url"`
+	ClientKey       string    `json:"client_key,omitempty"`
+	HandlerFormat   string    `json:"handler_format,omitempty"`
+	RequestedModel  string    `json:"requested_model,omitempty"`
+	UpstreamModel   string    `json:"upstream_model,omitempty"`
+	Provider        string    `json:"provider,omitempty"`
+	AuthID          string    `json:"auth_id,omitempty"`
+	AuthIndex       string    `json:"auth_index,omitempty"`
+	Status          int       `json:"status"`
+	Stream          bool      `json:"stream"`
+	LatencyMs       int64     `json:"latency_ms"`
+	InputTokens     int64     `json:"input_tokens"`
+	OutputTokens    int64     `json:"output_tokens"`
+	ReasoningTokens int64     `json:"reasoning_tokens"`
+	CachedTokens    int64     `json:"cached_tokens"`
+	TotalTokens     int64     `json:"total_tokens"`
+}
+
+// RequestUsage carries the upstream execution details captured by executors so that
+// the request logging middleware can attach them to the index entry.
+type RequestUsage struct {
+	Provider        string
+	Model           string
+	AuthID          string
+	AuthIndex       string
+	InputTokens     int64
+	OutputTokens    int64
+	ReasoningTokens int64
+	CachedTokens    int64
+	TotalTokens     int64
+}
+
+// RequestLogIndexFilter narrows the entries returned by QueryRequestLogIndex.
+// Empty fields do not filter.
+type RequestLogIndexFilter struct {
+	RequestID     string
+	ClientKey     string
+	Model         string
+	AuthID        string
+	HandlerFormat string
+	Status        int
+	FailedOnly    bool
+	From          time.Time
+	To            time.Time
+	Limit         int
+	Offset        int
+}
+
+// SetGinHandlerFormat stores the client-facing handler format (e.g. "openai", "claude") in the Gin context.
+func SetGinHandlerFormat(c *gin.Context, format string) {
+	if c != nil && format != "" {
+		c.Set(ginHandlerFormatKey, format)
+	}
+}
+
+// GetGinHandlerFormat retrieves the handler format from the Gin context.
+func GetGinHandlerFormat(c *gin.Context) string {
+	if c == nil {
+		return ""
+	}
+	if v, exists := c.Get(ginHandlerFormatKey); exists {
+		if s, ok := v.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// SetGinRequestUsage stores the latest upstream execution details in the Gin context.
+// Later attempts overwrite earlier ones so the index reflects the auth that produced the response.
+func SetGinRequestUsage(c *gin.Context, usage RequestUsage) {
+	if c != nil {
+		c.Set(ginRequestUsageKey, usage)
+	}
+}
+
+// GetGinRequestUsage retrieves the upstream execution details from the Gin context.
+func GetGinRequestUsage(c *gin.Context) (RequestUsage, bool) {
+	if c == nil {
+		return RequestUsage{}, false
+	}
+	if v, exists := c.Get(ginRequestUsageKey); exists {
+		if usage, ok := v.(RequestUsage); ok {
+			return usage, true
+		}
+	}
+	return RequestUsage{}, false
+}
+
+// AppendRequestLogIndex appends an entry to the index sidecar in dir.
+func AppendRequestLogIndex(dir string, entry RequestLogIndexEntry) error {
+	if strings.TrimSpace(dir) == "" {
+		return fmt.Errorf("log directory not configured")
+	}
+	data, errMarshal := json.Marshal(entry)
+	if errMarshal != nil {
+		return errMarshal
+	}
+	data = append(data, '\n')
+
+	requestLogIndexMu.Lock()
+	defer requestLogIndexMu.Unlock()
+
+	file, errOpen := os.OpenFile(filepath.Join(dir, RequestLogIndexFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if errOpen != nil {
+		return errOpen
+	}
+	_, errWrite := file.Write(data)
+	if errClose := file.Close(); errClose != nil && errWrite == nil {
+		errWrite = errClose
+	}
+	return errWrite
+}
+
+// QueryRequestLogIndex returns index entries in dir matching filter, newest first,
+// together with the total number of matches before pagination.
+func QueryRequestLogIndex(dir string, filter RequestLogIndexFilter) ([]RequestLogIndexEntry, int, error) {
+	requestLogIndexMu.Lock()
+	entries, errRead := readRequestLogIndexLocked(dir)
+	requestLogIndexMu.Unlock()
+	if errRead != nil {
+		return nil, 0, errRead
+	}
+
+	matched := make([]RequestLogIndexEntry, 0, len(entries))
+	for i := range entries {
+		if filter.matches(&entries[i]) {
+			matched = append(matched, entries[i])
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].Timestamp.After(matched[j].Timestamp)
+	})
+
+	total := len(matched)
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= total {
+		return []RequestLogIndexEntry{}, total, nil
+	}
+	end := total
+	if filter.Limit > 0 && offset+filter.Limit < end {
+		end = offset + filter.Limit
+	}
+	return matched[offset:end], total, nil
+}
+
+// PruneRequestLogIndex drops index entries whose log files no longer exist in dir.
+// It returns the number of removed entries.
+func PruneRequestLogIndex(dir string) (int, error) {
+	requestLogIndexMu.Lock()
+	defer requestLogIndexMu.Unlock()
+
+	entries, errRead := readRequestLogIndexLocked(dir)
+	if errRead != nil {
+		return 0, errRead
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	kept := make([]RequestLogIndexEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.File == "" {
+			continue
+		}
+		if _, errStat := os.Stat(filepath.Join(dir, entry.File)); errStat != nil {
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	removed := len(entries) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+
+	tmpFile, errCreate := os.CreateTemp(dir, "request-logs-index-*.tmp")
+	if errCreate != nil {
+		return 0, errCreate
+	}
+	tmpPath := tmpFile.Name()
+	writer := bufio.NewWriter(tmpFile)
+	encoder := json.NewEncoder(writer)
+	for i := range kept {
+		if errEncode := encoder.Encode(&kept[i]); errEncode != nil {
+			_ = tmpFile.Close()
+			_ = os.Remove(tmpPath)
+			return 0, errEncode
+		}
+	}
+	if errFlush := writer.Flush(); errFlush != nil {
+		_ = tmpFile.Close()
+		_ = os.Remove(tmpPath)
+		return 0, errFlush
+	}
+	if errClose := tmpFile.Close(); errClose != nil {
+		_ = os.Remove(tmpPath)
+		return 0, errClose
+	}
+	if errRename := os.Rename(tmpPath, filepath.Join(dir, RequestLogIndexFileName)); errRename != nil {
+		_ = os.Remove(tmpPath)
+		return 0, errRename
+	}
+	return removed, nil
+}
+
+func readRequestLogIndexLocked(dir string) ([]RequestLogIndexEntry, error) {
+	file, errOpen := os.Open(filepath.Join(dir, RequestLogIndexFileName))
+	if errOpen != nil {
+		if errors.Is(errOpen, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, errOpen
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var entries []RequestLogIndexEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), requestLogIndexScannerMaxBuffer)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry RequestLogIndexEntry
+		if errUnmarshal := json.Unmarshal([]byte(line), &entry); errUnmarshal != nil {
+			log.WithError(errUnmarshal).Debug("logging: skipping malformed request log index line")
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if errScan := scanner.Err(); errScan != nil {
+		return nil, errScan
+	}
+	return entries, nil
+}
+
+func (f RequestLogIndexFilter) matches(entry *RequestLogIndexEntry) bool {
+	if f.RequestID != "" && entry.RequestID != f.RequestID {
+		return false
+	}
+	if f.ClientKey != "" && entry.ClientKey != f.ClientKey {
+		return false
+	}
+	if f.Model != "" {
+		needle := strings.ToLower(f.Model)
+		if !strings.Contains(strings.ToLower(entry.RequestedModel), needle) &&
+			!strings.Contains(strings.ToLower(entry.UpstreamModel), needle) {
+			return false
+		}
+	}
+	if f.AuthID != "" && entry.AuthID != f.AuthID && entry.AuthIndex != f.AuthID {
+		return false
+	}
+	if f.HandlerFormat != "" && !strings.EqualFold(entry.HandlerFormat, f.HandlerFormat) {
+		return false
+	}
+	if f.Status != 0 && entry.Status != f.Status {
+		return false
+	}
+	if f.FailedOnly && entry.Status < 400 {
+		return false
+	}
+	if !f.From.IsZero() && entry.Timestamp.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && entry.Timestamp.After(f.To) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRequestLogIndexQueryFiltersAndPaginates(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	entries := []RequestLogIndexEntry{
+		{RequestID: "a", Timestamp: base, File: "a.log", ClientKey: "key-x", RequestedModel: "claude-opus-4", Status: 500},
+		{RequestID: "b", Timestamp: base.Add(time.Minute), File: "b.log", ClientKey: "key-x", RequestedModel: "claude-opus-4", Status: 200},
+		{RequestID: "c", Timestamp: base.Add(2 * time.Minute), File: "c.log", ClientKey: "key-y", RequestedModel: "gpt-5", UpstreamModel: "claude-opus-4", Status: 429},
+		{RequestID: "d", Timestamp: base.Add(3 * time.Minute), File: "d.log", ClientKey: "key-x", RequestedModel: "CLAUDE-OPUS-4", Status: 502},
+	}
+	for _, entry := range entries {
+		if err := AppendRequestLogIndex(dir, entry); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+
+	got, total, err := QueryRequestLogIndex(dir, RequestLogIndexFilter{ClientKey: "key-x", Model: "opus", FailedOnly: true})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if total != 2 || len(got) != 2 {
+		t.Fatalf("expected 2 matches, got total=%d len=%d", total, len(got))
+	}
+	if got[0].RequestID != "d" || got[1].RequestID != "a" {
+		t.Fatalf("expected newest first [d a], got [%s %s]", got[0].RequestID, got[1].RequestID)
+	}
+
+	got, total, err = QueryRequestLogIndex(dir, RequestLogIndexFilter{From: base.Add(30 * time.Second), Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if total != 3 || len(got) != 1 || got[0].RequestID != "c" {
+		t.Fatalf("unexpected page: total=%d entries=%+v", total, got)
+	}
+}
+
+func TestPruneRequestLogIndexDropsMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeLogFile(t, filepath.Join(dir, "kept.log"), 10, time.Unix(1, 0))
+
+	for _, entry := range []RequestLogIndexEntry{
+		{RequestID: "kept", File: "kept.log", Timestamp: time.Unix(1, 0)},
+		{RequestID: "gone", File: "gone.log", Timestamp: time.Unix(2, 0)},
+	} {
+		if err := AppendRequestLogIndex(dir, entry); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+
+	removed, err := PruneRequestLogIndex(dir)
+	if err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if removed != 1 {
+		t.Fatalf("expected 1 removed entry, got %d", removed)
+	}
+	got, total, err := QueryRequestLogIndex(dir, RequestLogIndexFilter{})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if total != 1 || got[0].RequestID != "kept" {
+		t.Fatalf("unexpected entries after prune: %+v", got)
+	}
+}
+
+func TestEnforceLogDirSizeLimitPrunesIndex(t *testing.T) {
+	dir := t.TempDir()
+	writeLogFile(t, filepath.Join(dir, "old.log"), 100, time.Unix(1, 0))
+	writeLogFile(t, filepath.Join(dir, "new.log"), 100, time.Unix(2, 0))
+	if err := AppendRequestLogIndex(dir, RequestLogIndexEntry{RequestID: "old", File: "old.log"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := AppendRequestLogIndex(dir, RequestLogIndexEntry{RequestID: "new", File: "new.log"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	if _, err := enforceLogDirSizeLimit(dir, 150, ""); err != nil {
+		t.Fatalf("enforce: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, RequestLogIndexFileName)); err != nil {
+		t.Fatalf("expected index to survive cleanup: %v", err)
+	}
+	got, _, err := QueryRequestLogIndex(dir, RequestLogIndexFilter{})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if len(got) != 1 || got[0].RequestID != "new" {
+		t.Fatalf("expected only new entry, got %+v", got)
+	}
+}
@@ -0,0 +1,240 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// requestLogSectionPattern matches the section headers written by FileRequestLogger and
+// by the executors' upstream request/response recording.
+var requestLogSectionPattern = regexp.MustCompile(`(?m)^=== (REQUEST INFO|HEADERS|REQUEST BODY|API REQUEST(?: \d+)?|API RESPONSE(?: \d+)?|API ERROR RESPONSE|RESPONSE) ===\r?\n`)
+
+// RequestLogRecord is the parsed content of a request log file.
+type RequestLogRecord struct {
+	URL       string
+	Method    string
+	Timestamp time.Time
+	Headers   http.Header
+	Body      []byte
+
+	// APIRequest holds the raw upstream request sections, one per attempt.
+	APIRequest []byte
+	// UpstreamResponses holds the parsed upstream response of each attempt.
+	UpstreamResponses []UpstreamLogResponse
+	// Provider is the provider recorded for the first upstream attempt, if any.
+	Provider string
+
+	Status          int
+	ResponseHeaders http.Header
+	Response        []byte
+}
+
+// UpstreamLogResponse is a single upstream response recorded in a request log.
+type UpstreamLogResponse struct {
+	Status  int
+	Headers http.Header
+	Body    []byte
+	Error   string
+}
+
+// ResolveRequestLogPath locates a request log file by path or by request ID.
+// Request IDs are resolved through the request log index first and then by file name.
+func ResolveRequestLogPath(dir, ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", fmt.Errorf("empty request log reference")
+	}
+	if info, errStat := os.Stat(ref); errStat == nil && !info.IsDir() {
+		return ref, nil
+	}
+	if dir == "" {
+		return "", fmt.Errorf("request log %q not found", ref)
+	}
+	candidate := filepath.Join(dir, filepath.Base(ref))
+	if info, errStat := os.Stat(candidate); errStat == nil && !info.IsDir() {
+		return candidate, nil
+	}
+
+	entries, _, errQuery := QueryRequestLogIndex(dir, RequestLogIndexFilter{RequestID: ref, Limit: 1})
+	if errQuery == nil && len(entries) > 0 && entries[0].File != "" {
+		candidate = filepath.Join(dir, entries[0].File)
+		if _, errStat := os.Stat(candidate); errStat == nil {
+			return candidate, nil
+		}
+	}
+
+	matches, errGlob := filepath.Glob(filepath.Join(dir, "*-"+ref+".log"))
+	if errGlob != nil {
+		return "", errGlob
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("request log %q not found in %s", ref, dir)
+	}
+	return matches[len(matches)-1], nil
+}
+
+// ReadRequestLogFile reads and parses a request log file.
+func ReadRequestLogFile(path string) (*RequestLogRecord, error) {
+	data, errRead := os.ReadFile(path)
+	if errRead != nil {
+		return nil, errRead
+	}
+	return ParseRequestLog(data)
+}
+
+// ParseRequestLog parses the content of a request log written by FileRequestLogger.
+func ParseRequestLog(data []byte) (*RequestLogRecord, error) {
+	matches := requestLogSectionPattern.FindAllSubmatchIndex(data, -1)
+	if len(matches) == 0 || string(data[matches[0][2]:matches[0][3]]) != "REQUEST INFO" {
+		return nil, fmt.Errorf("not a request log: missing REQUEST INFO section")
+	}
+
+	record := &RequestLogRecord{Headers: make(http.Header), ResponseHeaders: make(http.Header)}
+	var apiRequest bytes.Buffer
+	for i, match := range matches {
+		name := string(data[match[2]:match[3]])
+		end := len(data)
+		// The client response is always the last section and may contain arbitrary text.
+		if name != "RESPONSE" && i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		content := data[match[1]:end]
+
+		switch {
+		case name == "REQUEST INFO":
+			parseRequestInfoSection(record, content)
+		case name == "HEADERS":
+			parseHeaderLines(record.Headers, content)
+		case name == "REQUEST BODY":
+			record.Body = bytes.TrimSuffix(content, []byte("\n\n"))
+		case strings.HasPrefix(name, "API REQUEST"):
+			apiRequest.Write(data[match[0]:end])
+			if record.Provider == "" {
+				record.Provider = parseUpstreamProvider(content)
+			}
+		case strings.HasPrefix(name, "API RESPONSE"):
+			record.UpstreamResponses = append(record.UpstreamResponses, parseUpstreamResponseSection(content))
+		case name == "RESPONSE":
+			parseResponseSection(record, content)
+		}
+		if name == "RESPONSE" {
+			break
+		}
+	}
+	record.APIRequest = bytes.TrimRight(apiRequest.Bytes(), "\n")
+
+	if record.URL == "" || record.Method == "" {
+		return nil, fmt.Errorf("request log is missing URL or method")
+	}
+	return record, nil
+}
+
+func parseRequestInfoSection(record *RequestLogRecord, content []byte) {
+	for _, line := range strings.Split(string(content), "\n") {
+		key, value, ok := strings.Cut(strings.TrimRight(line, "\r"), ": ")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "URL":
+			record.URL = value
+		case "Method":
+			record.Method = value
+		case "Timestamp":
+			if ts, errParse := time.Parse(time.RFC3339Nano, value); errParse == nil {
+				record.Timestamp = ts
+			}
+		}
+	}
+}
+
+// parseUpstreamProvider extracts the provider from the "Auth: provider=..." line of an
+// upstream request section.
+func parseUpstreamProvider(content []byte) string {
+	for _, line := range strings.Split(string(content), "\n") {
+		auth, ok := strings.CutPrefix(strings.TrimRight(line, "\r"), "Auth: ")
+		if !ok {
+			if line == "" {
+				break
+			}
+			continue
+		}
+		for _, field := range strings.Fields(auth) {
+			if provider, okProvider := strings.CutPrefix(field, "provider="); okProvider {
+				return provider
+			}
+		}
+	}
+	return ""
+}
+
+func parseHeaderLines(headers http.Header, content []byte) {
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" || line == "<none>" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		headers.Add(strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+}
+
+func parseResponseSection(record *RequestLogRecord, content []byte) {
+	var head, body []byte
+	if bytes.HasPrefix(content, []byte("\n")) {
+		body = content[1:]
+	} else {
+		head, body, _ = bytes.Cut(content, []byte("\n\n"))
+	}
+	for _, line := range strings.Split(string(head), "\n") {
+		if value, ok := strings.CutPrefix(line, "Status: "); ok && record.Status == 0 {
+			record.Status, _ = strconv.Atoi(strings.TrimSpace(value))
+			continue
+		}
+		parseHeaderLines(record.ResponseHeaders, []byte(line))
+	}
+	record.Response = bytes.TrimRight(body, "\n")
+}
+
+func parseUpstreamResponseSection(content []byte) UpstreamLogResponse {
+	response := UpstreamLogResponse{Headers: make(http.Header)}
+	text := string(content)
+	if idx := strings.Index(text, "\nBody:\n"); idx >= 0 {
+		response.Body = bytes.TrimRight([]byte(text[idx+len("\nBody:\n"):]), "\n")
+		text = text[:idx]
+	} else if strings.HasPrefix(text, "Body:\n") {
+		response.Body = bytes.TrimRight([]byte(text[len("Body:\n"):]), "\n")
+		text = ""
+	}
+
+	inHeaders := false
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case line == "Headers:":
+			inHeaders = true
+		case line == "":
+			inHeaders = false
+		case inHeaders:
+			parseHeaderLines(response.Headers, []byte(line))
+		case strings.HasPrefix(line, "Status: "):
+			response.Status, _ = strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "Status: ")))
+		case strings.HasPrefix(line, "Error: "):
+			if response.Error != "" {
+				response.Error += "\n"
+			}
+			response.Error += strings.TrimPrefix(line, "Error: ")
+		}
+	}
+	return response
+}
@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseRequestLogRoundTrip(t *testing.T) {
+	apiRequest := "=== API REQUEST 1 ===\nTimestamp: 2026-01-02T10:00:00Z\nUpstream URL: https://example.com/v1/messages\nAuth: provider=claude auth_id=claude-1 type=oauth\n\nHeaders:\nContent-Type: application/json\n\nBody:\n{\"model\":\"claude\"}\n\n"
+	apiResponse := "=== API RESPONSE 1 ===\nTimestamp: 2026-01-02T10:00:01Z\n\nStatus: 200\nHeaders:\nContent-Type: text/event-stream\n\nBody:\nevent: message_start\ndata: {\"type\":\"message_start\"}\n\ndata: {\"type\":\"message_stop\"}\n"
+
+	var buf bytes.Buffer
+	logger := &FileRequestLogger{}
+	errWrite := logger.writeNonStreamingLog(&buf,
+		"/v1/chat/completions", "POST",
+		map[string][]string{"Content-Type": {"application/json"}, "Authorization": {"Bearer sk-secret-value"}},
+		[]byte(`{"model":"gpt-5","stream":true}`), "",
+		[]byte(apiRequest), []byte(apiResponse), nil,
+		200, map[string][]string{"Content-Type": {"text/event-stream"}},
+		[]byte("data: {\"choices\":[]}\n\ndata: [DONE]\n"), nil,
+	)
+	if errWrite != nil {
+		t.Fatalf("write: %v", errWrite)
+	}
+
+	record, errParse := ParseRequestLog(buf.Bytes())
+	if errParse != nil {
+		t.Fatalf("parse: %v", errParse)
+	}
+	if record.URL != "/v1/chat/completions" || record.Method != "POST" {
+		t.Fatalf("unexpected request line: %s %s", record.Method, record.URL)
+	}
+	if string(record.Body) != `{"model":"gpt-5","stream":true}` {
+		t.Fatalf("unexpected body: %q", record.Body)
+	}
+	if record.Headers.Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected headers: %v", record.Headers)
+	}
+	if !bytes.HasPrefix(record.APIRequest, []byte("=== API REQUEST 1 ===")) {
+		t.Fatalf("unexpected api request: %q", record.APIRequest)
+	}
+	if record.Provider != "claude" {
+		t.Fatalf("unexpected provider: %q", record.Provider)
+	}
+	if len(record.UpstreamResponses) != 1 {
+		t.Fatalf("expected 1 upstream response, got %d", len(record.UpstreamResponses))
+	}
+	upstream := record.UpstreamResponses[0]
+	if upstream.Status != 200 || upstream.Headers.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected upstream response: %+v", upstream)
+	}
+	if string(upstream.Body) != "event: message_start\ndata: {\"type\":\"message_start\"}\n\ndata: {\"type\":\"message_stop\"}" {
+		t.Fatalf("unexpected upstream body: %q", upstream.Body)
+	}
+	if record.Status != 200 || record.ResponseHeaders.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected response meta: %d %v", record.Status, record.ResponseHeaders)
+	}
+	if string(record.Response) != "data: {\"choices\":[]}\n\ndata: [DONE]" {
+		t.Fatalf("unexpected response: %q", record.Response)
+	}
+}
+
+func TestResolveRequestLogPathByRequestID(t *testing.T) {
+	dir := t.TempDir()
+	name := "v1-chat-completions-2026-01-02T100000-abc123.log"
+	writeLogFile(t, filepath.Join(dir, name), 10, time.Unix(1, 0))
+
+	got, errResolve := ResolveRequestLogPath(dir, "abc123")
+	if errResolve != nil {
+		t.Fatalf("resolve: %v", errResolve)
+	}
+	if filepath.Base(got) != name {
+		t.Fatalf("resolved %q, want %q", got, name)
+	}
+	if _, errResolve = ResolveRequestLogPath(dir, "missing"); errResolve == nil {
+		t.Fatalf("expected error for unknown request id")
+	}
+	if _, errStat := os.Stat(got); errStat != nil {
+		t.Fatalf("stat: %v", errStat)
+	}
+}
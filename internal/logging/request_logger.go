@@ -14,6 +14,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -124,6 +125,10 @@ type FileRequestLogger struct {
 
 	// logsDir is the directory where log files are stored.
 	logsDir string
+
+	// pendingIndexFiles maps request IDs to the log file written for them until
+	// the corresponding index entry is appended.
+	pendingIndexFiles sync.Map
 }
 
 // NewFileRequestLogger creates a new file-based request logger.
@@ -209,6 +214,7 @@ func (l *FileRequestLogger) logRequest(url, method string, requestHeaders map[st
 		filename = l.generateErrorFilename(url, requestID)
 	}
 	filePath := filepath.Join(l.logsDir, filename)
+	l.trackIndexFile(requestID, filename)
 
 	requestBodyPath, errTemp := l.writeRequestBodyTempFile(body)
 	if errTemp != nil {
@@ -292,6 +298,7 @@ func (l *FileRequestLogger) LogStreamingRequest(url, method string, headers map[
 	// Generate filename with request ID
 	filename := l.generateFilename(url, requestID)
 	filePath := filepath.Join(l.logsDir, filename)
+	l.trackIndexFile(requestID, filename)
 
 	requestHeaders := make(map[string][]string, len(headers))
 	for key, values := range headers {
@@ -333,6 +340,43 @@ func (l *FileRequestLogger) LogStreamingRequest(url, method string, headers map[
 	return writer, nil
 }
 
+// AppendIndexEntry records a logged request in the request log index sidecar.
+// The entry is matched to the log file written for the same request ID; entries
+// whose log file was never written (or failed to write) are ignored.
+//
+// Parameters:
+//   - entry: The index entry describing the request
+//
+// Returns:
+//   - error: An error if the index could not be written, nil otherwise
+func (l *FileRequestLogger) AppendIndexEntry(entry RequestLogIndexEntry) error {
+	if entry.RequestID == "" {
+		return nil
+	}
+	filename, ok := l.pendingIndexFiles.LoadAndDelete(entry.RequestID)
+	if !ok {
+		return nil
+	}
+	entry.File, _ = filename.(string)
+	if entry.File == "" {
+		return nil
+	}
+	if _, errStat := os.Stat(filepath.Join(l.logsDir, entry.File)); errStat != nil {
+		return nil
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	return AppendRequestLogIndex(l.logsDir, entry)
+}
+
+func (l *FileRequestLogger) trackIndexFile(requestID, filename string) {
+	if requestID == "" {
+		return
+	}
+	l.pendingIndexFiles.Store(requestID, filename)
+}
+
 // generateErrorFilename creates a filename with an error prefix to differentiate forced error logs.
 func (l *FileRequestLogger) generateErrorFilename(url string, requestID ...string) string {
 	return fmt.Sprintf("error-%s", l.generateFilename(url, requestID...))
@@ -464,6 +508,10 @@ func (l *FileRequestLogger) cleanupOldErrorLogs() error {
 		}
 	}
 
+	if _, errPrune := PruneRequestLogIndex(l.logsDir); errPrune != nil {
+		log.WithError(errPrune).Warn("failed to prune request log index")
+	}
+
 	return nil
 }
 
@@ -0,0 +1,366 @@
+package pdftext
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf16"
+)
+
+// spaceThreshold is the TJ kerning adjustment, in thousandths of an em, treated as a word gap.
+const spaceThreshold = 200
+
+// averageGlyphWidth approximates glyph advance as a fraction of the font size.
+const averageGlyphWidth = 0.5
+
+// wordGapWidth is the gap, as a fraction of the font size, beyond the estimated advance
+// that counts as a word break. It leaves room for wide glyphs such as "m" and "W".
+const wordGapWidth = 0.4
+
+// maxCMapCodes bounds the codes a single ToUnicode CMap may define, which covers every
+// two-byte code. Codes beyond it are ignored.
+const maxCMapCodes = 1 << 16
+
+// cmapCodeCost is the decoded-size budget, in bytes, charged for each CMap code.
+const cmapCodeCost = 32
+
+// font decodes shown strings for one font resource.
+type font struct {
+	cmap    *cmap
+	twoByte bool
+}
+
+// cmap is a parsed ToUnicode mapping.
+type cmap struct {
+	width int
+	codes map[uint32]string
+}
+
+// decode converts string bytes to text. Fonts without a ToUnicode map are read as
+// single-byte Latin text; two-byte Identity fonts without one cannot be decoded.
+func (f *font) decode(s []byte) string {
+	if f != nil && f.cmap != nil && len(f.cmap.codes) > 0 {
+		width := f.cmap.width
+		if width <= 0 {
+			width = 1
+		}
+		var b strings.Builder
+		for i := 0; i+width <= len(s); i += width {
+			var code uint32
+			for _, c := range s[i : i+width] {
+				code = code<<8 | uint32(c)
+			}
+			if text, ok := f.cmap.codes[code]; ok {
+				b.WriteString(text)
+			}
+		}
+		return b.String()
+	}
+	if f != nil && f.twoByte {
+		return ""
+	}
+	var b strings.Builder
+	for _, c := range s {
+		switch {
+		case c == '\t' || c == '\n' || c == '\r':
+			b.WriteByte(' ')
+		case c < 0x20:
+		case c < 0x80:
+			b.WriteByte(c)
+		default:
+			b.WriteRune(rune(c))
+		}
+	}
+	return b.String()
+}
+
+// parseCMap reads bfchar and bfrange sections of a ToUnicode CMap, keeping at most
+// maxCodes codes. It reports whether later codes were dropped to stay within maxCodes.
+func parseCMap(data []byte, maxCodes int) (*cmap, bool) {
+	m := &cmap{codes: make(map[uint32]string)}
+	set := func(code uint32, text string) bool {
+		if _, exists := m.codes[code]; !exists && len(m.codes) >= maxCodes {
+			return false
+		}
+		m.codes[code] = text
+		return true
+	}
+	l := &lexer{data: data}
+	var operands []any
+	for {
+		tok, ok := l.object()
+		if !ok {
+			break
+		}
+		kw, isKeyword := tok.(keyword)
+		if !isKeyword {
+			operands = append(operands, tok)
+			continue
+		}
+		switch kw {
+		case "begincodespacerange", "beginbfchar", "beginbfrange":
+			operands = operands[:0]
+			continue
+		case "endcodespacerange":
+			for i := 0; i+1 < len(operands); i += 2 {
+				if lo, isStr := operands[i].([]byte); isStr && len(lo) > m.width {
+					m.width = len(lo)
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].([]byte)
+				dst, ok2 := operands[i+1].([]byte)
+				if ok1 && ok2 {
+					m.setWidth(len(src))
+					if !set(codeOf(src), utf16String(dst)) {
+						return m, true
+					}
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].([]byte)
+				hi, ok2 := operands[i+1].([]byte)
+				if !ok1 || !ok2 {
+					continue
+				}
+				m.setWidth(len(lo))
+				start, end := codeOf(lo), codeOf(hi)
+				if end < start || end-start > 0xFFFF {
+					continue
+				}
+				switch dst := operands[i+2].(type) {
+				case []byte:
+					for offset := uint32(0); offset <= end-start; offset++ {
+						next := append([]byte(nil), dst...)
+						addToLastByte(next, offset)
+						if !set(start+offset, utf16String(next)) {
+							return m, true
+						}
+					}
+				case []any:
+					for k, item := range dst {
+						if s, isStr := item.([]byte); isStr && start+uint32(k) <= end {
+							if !set(start+uint32(k), utf16String(s)) {
+								return m, true
+							}
+						}
+					}
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+	return m, false
+}
+
+func (m *cmap) setWidth(n int) {
+	if m.width == 0 {
+		m.width = n
+	}
+}
+
+func codeOf(b []byte) uint32 {
+	var code uint32
+	for _, c := range b {
+		code = code<<8 | uint32(c)
+	}
+	return code
+}
+
+// addToLastByte increments a big-endian destination code by delta.
+func addToLastByte(b []byte, delta uint32) {
+	for i := len(b) - 1; i >= 0 && delta > 0; i-- {
+		sum := uint32(b[i]) + delta
+		b[i] = byte(sum)
+		delta = sum >> 8
+	}
+}
+
+func utf16String(b []byte) string {
+	if len(b)%2 != 0 {
+		return string(b)
+	}
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+	}
+	return string(utf16.Decode(units))
+}
+
+// textWriter accumulates shown text with approximate spacing and line breaks.
+type textWriter struct {
+	b strings.Builder
+}
+
+func (w *textWriter) last() byte {
+	s := w.b.String()
+	if s == "" {
+		return '\n'
+	}
+	return s[len(s)-1]
+}
+
+func (w *textWriter) write(s string) {
+	w.b.WriteString(s)
+}
+
+func (w *textWriter) space() {
+	if c := w.last(); c != ' ' && c != '\n' {
+		w.b.WriteByte(' ')
+	}
+}
+
+func (w *textWriter) newline() {
+	if w.last() != '\n' {
+		w.b.WriteByte('\n')
+	}
+}
+
+// interpret runs the text operators of a content stream.
+func interpret(content []byte, fonts map[name]*font) string {
+	w := &textWriter{}
+	l := &lexer{data: content}
+	var operands []any
+	var current *font
+	fontSize := 0.0
+	// x and y are the line origin set by Tm, scale its horizontal scaling, and advance the
+	// estimated width of text shown since the last move, in text space. Moves along the
+	// same line only insert a space when they leave a visible gap after that text.
+	x, y, scale, advance, haveY := 0.0, 0.0, 1.0, 0.0, false
+	show := func(s []byte) {
+		text := current.decode(s)
+		w.write(text)
+		advance += float64(len([]rune(text))) * fontSize * averageGlyphWidth
+	}
+	wordGap := func(gap float64) {
+		if gap > fontSize*wordGapWidth || gap < -fontSize {
+			w.space()
+		}
+	}
+
+	for {
+		tok, ok := l.object()
+		if !ok {
+			break
+		}
+		op, isOp := tok.(keyword)
+		if !isOp {
+			operands = append(operands, tok)
+			continue
+		}
+		switch op {
+		case "Tf":
+			if len(operands) >= 2 {
+				if fn, isName := operands[0].(name); isName {
+					current = fonts[fn]
+				}
+				fontSize = number(operands[1])
+			}
+		case "Tj":
+			if len(operands) >= 1 {
+				if s, isStr := operands[len(operands)-1].([]byte); isStr {
+					show(s)
+				}
+			}
+		case "'", "\"":
+			w.newline()
+			if len(operands) >= 1 {
+				if s, isStr := operands[len(operands)-1].([]byte); isStr {
+					show(s)
+				}
+			}
+		case "TJ":
+			if len(operands) >= 1 {
+				if arr, isArr := operands[len(operands)-1].([]any); isArr {
+					for _, item := range arr {
+						switch v := item.(type) {
+						case []byte:
+							show(v)
+						case float64:
+							if v < -spaceThreshold {
+								w.space()
+							}
+						}
+					}
+				}
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				if number(operands[1]) != 0 {
+					w.newline()
+				} else {
+					wordGap(number(operands[0]) - advance)
+				}
+				advance = 0
+			}
+		case "Tm":
+			if len(operands) >= 6 {
+				newX, newY := number(operands[4]), number(operands[5])
+				switch {
+				case !haveY:
+				case newY != y:
+					w.newline()
+				default:
+					wordGap((newX - x - advance*scale) / scale)
+				}
+				scale = number(operands[0])
+				if scale <= 0 {
+					scale = 1
+				}
+				x, y, advance, haveY = newX, newY, 0, true
+			}
+		case "T*":
+			w.newline()
+			advance = 0
+		case "ET":
+			w.space()
+		case "BI":
+			skipInlineImage(l)
+		}
+		operands = operands[:0]
+	}
+	return tidy(w.b.String())
+}
+
+// skipInlineImage moves past binary inline image data up to its EI operator.
+func skipInlineImage(l *lexer) {
+	i := bytes.Index(l.data[l.pos:], []byte("ID"))
+	if i < 0 {
+		l.pos = len(l.data)
+		return
+	}
+	l.pos += i + 2
+	for l.pos < len(l.data) {
+		j := bytes.Index(l.data[l.pos:], []byte("EI"))
+		if j < 0 {
+			l.pos = len(l.data)
+			return
+		}
+		at := l.pos + j
+		l.pos = at + 2
+		if at > 0 && isWhite(l.data[at-1]) && (l.pos >= len(l.data) || isWhite(l.data[l.pos])) {
+			return
+		}
+	}
+}
+
+// tidy trims trailing spaces on each line and collapses runs of blank lines.
+func tidy(s string) string {
+	lines := strings.Split(s, "\n")
+	out := make([]string, 0, len(lines))
+	blank := 0
+	for _, line := range lines {
+		line = strings.TrimRight(line, " ")
+		if strings.TrimSpace(line) == "" {
+			blank++
+			if blank > 1 {
+				continue
+			}
+		} else {
+			blank = 0
+		}
+		out = append(out, line)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
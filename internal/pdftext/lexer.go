@@ -0,0 +1,305 @@
+package pdftext
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// name is a PDF name object without its leading slash.
+type name string
+
+// keyword is a bare PDF token such as an operator, "obj", "R" or "stream".
+type keyword string
+
+// ref is an indirect object reference.
+type ref struct {
+	num int
+	gen int
+}
+
+// dict is a PDF dictionary keyed by name.
+type dict map[name]any
+
+// maxObjectDepth bounds the nesting of arrays and dictionaries read by object.
+const maxObjectDepth = 64
+
+// lexer reads PDF objects from a byte slice. It is shared by the file parser,
+// content stream interpreter and CMap reader.
+type lexer struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func isWhite(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+func isDelim(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+// skipSpace advances past whitespace and comments.
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isWhite(c) {
+			l.pos++
+			continue
+		}
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		return
+	}
+}
+
+// token is a single lexical element. Delimiters such as "[" and "<<" are returned as keywords.
+func (l *lexer) token() (any, bool) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, false
+	}
+	c := l.data[l.pos]
+	switch {
+	case c == '/':
+		l.pos++
+		start := l.pos
+		for l.pos < len(l.data) && !isWhite(l.data[l.pos]) && !isDelim(l.data[l.pos]) {
+			l.pos++
+		}
+		return name(decodeNameEscapes(l.data[start:l.pos])), true
+	case c == '(':
+		l.pos++
+		return l.literalString(), true
+	case c == '<':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '<' {
+			l.pos += 2
+			return keyword("<<"), true
+		}
+		l.pos++
+		return l.hexString(), true
+	case c == '>':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '>' {
+			l.pos += 2
+			return keyword(">>"), true
+		}
+		l.pos++
+		return keyword(">"), true
+	case c == '[' || c == ']' || c == '{' || c == '}':
+		l.pos++
+		return keyword(string(c)), true
+	case c == ')':
+		l.pos++
+		return keyword(")"), true
+	}
+
+	start := l.pos
+	for l.pos < len(l.data) && !isWhite(l.data[l.pos]) && !isDelim(l.data[l.pos]) {
+		l.pos++
+	}
+	word := l.data[start:l.pos]
+	if n, err := strconv.ParseFloat(string(word), 64); err == nil && (word[0] == '-' || word[0] == '+' || word[0] == '.' || (word[0] >= '0' && word[0] <= '9')) {
+		return n, true
+	}
+	switch string(word) {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	case "null":
+		return nil, true
+	}
+	return keyword(word), true
+}
+
+func decodeNameEscapes(raw []byte) string {
+	if bytes.IndexByte(raw, '#') < 0 {
+		return string(raw)
+	}
+	out := make([]byte, 0, len(raw))
+	for i := 0; i < len(raw); i++ {
+		if raw[i] == '#' && i+2 < len(raw) {
+			if v, err := strconv.ParseUint(string(raw[i+1:i+3]), 16, 8); err == nil {
+				out = append(out, byte(v))
+				i += 2
+				continue
+			}
+		}
+		out = append(out, raw[i])
+	}
+	return string(out)
+}
+
+// literalString reads a (...) string after its opening parenthesis.
+func (l *lexer) literalString() []byte {
+	var out []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for k := 0; k < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; k++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					out = append(out, byte(v))
+				} else {
+					out = append(out, e)
+				}
+			}
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// hexString reads a <...> string after its opening bracket.
+func (l *lexer) hexString() []byte {
+	var out []byte
+	var hi byte
+	half := false
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		if c == '>' {
+			break
+		}
+		v, ok := hexValue(c)
+		if !ok {
+			continue
+		}
+		if half {
+			out = append(out, hi<<4|v)
+		} else {
+			hi = v
+		}
+		half = !half
+	}
+	if half {
+		out = append(out, hi<<4)
+	}
+	return out
+}
+
+func hexValue(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// object reads a complete object: arrays and dictionaries are assembled and "n g R"
+// sequences become references. Operators are returned as keywords. Objects nested deeper
+// than maxObjectDepth end the enclosing array or dictionary.
+func (l *lexer) object() (any, bool) {
+	if l.depth >= maxObjectDepth {
+		return nil, false
+	}
+	l.depth++
+	defer func() { l.depth-- }()
+	tok, ok := l.token()
+	if !ok {
+		return nil, false
+	}
+	switch t := tok.(type) {
+	case float64:
+		save := l.pos
+		if gen, okGen := l.token(); okGen {
+			if g, isNum := gen.(float64); isNum {
+				if r, okR := l.token(); okR && r == keyword("R") {
+					return ref{num: int(t), gen: int(g)}, true
+				}
+			}
+		}
+		l.pos = save
+		return t, true
+	case keyword:
+		switch t {
+		case "[":
+			var arr []any
+			for {
+				l.skipSpace()
+				if l.pos < len(l.data) && l.data[l.pos] == ']' {
+					l.pos++
+					return arr, true
+				}
+				v, okV := l.object()
+				if !okV {
+					return arr, true
+				}
+				arr = append(arr, v)
+			}
+		case "<<":
+			d := dict{}
+			for {
+				k, okK := l.object()
+				if !okK || k == keyword(">>") {
+					return d, true
+				}
+				key, isName := k.(name)
+				if !isName {
+					continue
+				}
+				v, okV := l.object()
+				if !okV {
+					return d, true
+				}
+				if v == keyword(">>") {
+					return d, true
+				}
+				d[key] = v
+			}
+		}
+	}
+	return tok, true
+}
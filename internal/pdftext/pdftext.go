@@ -0,0 +1,429 @@
+// Package pdftext extracts plain text from PDF files without external dependencies.
+// It understands the subset of PDF needed to read text from typical generated documents:
+// indirect objects, object streams, Flate/ASCIIHex/ASCII85 filters, page trees and
+// ToUnicode CMaps. Layout is approximated with line breaks and spaces; scanned documents
+// and encrypted files yield an error.
+package pdftext
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/ascii85"
+	"errors"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrNotPDF is returned when the input does not start with a PDF header.
+	ErrNotPDF = errors.New("pdftext: not a PDF file")
+	// ErrEncrypted is returned for password-protected or encrypted files.
+	ErrEncrypted = errors.New("pdftext: encrypted PDF files are not supported")
+	// ErrNoText is returned when the document has no extractable text, e.g. scanned pages.
+	ErrNoText = errors.New("pdftext: no extractable text")
+	// ErrTooLarge is returned when the decoded streams of a document exceed the size limit.
+	ErrTooLarge = errors.New("pdftext: decoded content exceeds the size limit")
+)
+
+const (
+	// maxPageTreeDepth bounds recursion through malformed or cyclic page trees.
+	maxPageTreeDepth = 64
+
+	// DefaultMaxDecodedBytes bounds the decoded stream data of a document in Extract.
+	DefaultMaxDecodedBytes = 64 << 20
+)
+
+var objectHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// object is a parsed indirect object. Stream holds decoded stream data when present.
+type object struct {
+	value  any
+	stream []byte
+	isStrm bool
+}
+
+type document struct {
+	objects map[int]*object
+	trailer dict
+	// budget is the decoded stream data still allowed for the document. Parsed CMaps
+	// are charged cmapCodeCost per code.
+	budget int64
+	// cmaps caches parsed ToUnicode CMaps by object number, since fonts are shared
+	// across pages.
+	cmaps map[int]*cmap
+}
+
+// Extract returns the text of every page in data, with pages separated by blank lines.
+// The decoded stream data is limited to DefaultMaxDecodedBytes.
+func Extract(data []byte) (string, error) {
+	return ExtractLimit(data, DefaultMaxDecodedBytes)
+}
+
+// ExtractLimit is like Extract but fails with ErrTooLarge once the decoded stream data of
+// the document exceeds maxDecodedBytes. <= 0 uses DefaultMaxDecodedBytes.
+func ExtractLimit(data []byte, maxDecodedBytes int64) (string, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, "\x00\t\n\f\r "), []byte("%PDF-")) {
+		return "", ErrNotPDF
+	}
+	if maxDecodedBytes <= 0 {
+		maxDecodedBytes = DefaultMaxDecodedBytes
+	}
+	doc, err := parseDocument(data, maxDecodedBytes)
+	if err != nil {
+		return "", err
+	}
+	if _, encrypted := doc.trailer["Encrypt"]; encrypted {
+		return "", ErrEncrypted
+	}
+
+	var pages []string
+	for _, page := range doc.pages() {
+		text, err := doc.pageText(page)
+		if err != nil {
+			return "", err
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			pages = append(pages, text)
+		}
+	}
+	if len(pages) == 0 {
+		return "", ErrNoText
+	}
+	return strings.Join(pages, "\n\n"), nil
+}
+
+// parseDocument collects every indirect object in file order, so objects redefined by
+// incremental updates keep their latest version, then expands object streams. It fails
+// with ErrTooLarge when the decoded streams exceed maxDecodedBytes.
+func parseDocument(data []byte, maxDecodedBytes int64) (*document, error) {
+	doc := &document{objects: make(map[int]*object), trailer: dict{}, budget: maxDecodedBytes, cmaps: make(map[int]*cmap)}
+	var objStreams []*object
+
+	for _, loc := range objectHeader.FindAllSubmatchIndex(data, -1) {
+		num := atoi(data[loc[2]:loc[3]])
+		l := &lexer{data: data, pos: loc[1]}
+		value, ok := l.object()
+		if !ok {
+			continue
+		}
+		obj := &object{value: value}
+		if d, isDict := value.(dict); isDict {
+			save := l.pos
+			if tok, okTok := l.token(); okTok && tok == keyword("stream") {
+				obj.isStrm = true
+				decoded, err := doc.decodeStream(d, streamData(data, l.pos, d))
+				if err != nil {
+					return nil, err
+				}
+				obj.stream = decoded
+				switch d["Type"] {
+				case name("ObjStm"):
+					objStreams = append(objStreams, obj)
+				case name("XRef"):
+					mergeTrailer(doc.trailer, d)
+				}
+			} else {
+				l.pos = save
+			}
+		}
+		doc.objects[num] = obj
+	}
+
+	for _, obj := range objStreams {
+		d := obj.value.(dict)
+		first := int(number(d["First"]))
+		count := int(number(d["N"]))
+		header := &lexer{data: obj.stream}
+		for i := 0; i < count; i++ {
+			numTok, ok1 := header.token()
+			offTok, ok2 := header.token()
+			n, isNum := numTok.(float64)
+			off, isOff := offTok.(float64)
+			if !ok1 || !ok2 || !isNum || !isOff {
+				break
+			}
+			if _, exists := doc.objects[int(n)]; exists {
+				continue
+			}
+			start := first + int(off)
+			if start < 0 || start >= len(obj.stream) {
+				continue
+			}
+			inner := &lexer{data: obj.stream, pos: start}
+			if value, ok := inner.object(); ok {
+				doc.objects[int(n)] = &object{value: value}
+			}
+		}
+	}
+
+	for idx := 0; ; {
+		i := bytes.Index(data[idx:], []byte("trailer"))
+		if i < 0 {
+			break
+		}
+		l := &lexer{data: data, pos: idx + i + len("trailer")}
+		if value, ok := l.object(); ok {
+			if d, isDict := value.(dict); isDict {
+				mergeTrailer(doc.trailer, d)
+			}
+		}
+		idx += i + len("trailer")
+	}
+	return doc, nil
+}
+
+// mergeTrailer copies the keys text extraction relies on from a trailer or xref stream.
+func mergeTrailer(dst, src dict) {
+	for _, key := range []name{"Root", "Encrypt"} {
+		if v, ok := src[key]; ok {
+			dst[key] = v
+		}
+	}
+}
+
+// streamData returns the raw bytes of a stream whose "stream" keyword ends at pos.
+func streamData(data []byte, pos int, d dict) []byte {
+	if pos < len(data) && data[pos] == '\r' {
+		pos++
+	}
+	if pos < len(data) && data[pos] == '\n' {
+		pos++
+	}
+	if length, ok := d["Length"].(float64); ok {
+		end := pos + int(length)
+		if end <= len(data) && end >= pos {
+			rest := bytes.TrimLeft(data[end:min(end+32, len(data))], "\r\n ")
+			if bytes.HasPrefix(rest, []byte("endstream")) {
+				return data[pos:end]
+			}
+		}
+	}
+	end := bytes.Index(data[pos:], []byte("endstream"))
+	if end < 0 {
+		return data[pos:]
+	}
+	return bytes.TrimRight(data[pos:pos+end], "\r\n")
+}
+
+// decodeStream applies the stream's filters and charges the decoded size to the document
+// budget. Unsupported or corrupt filters yield nil; exceeding the budget yields ErrTooLarge.
+func (doc *document) decodeStream(d dict, raw []byte) ([]byte, error) {
+	var filters []any
+	switch f := d["Filter"].(type) {
+	case name:
+		filters = []any{f}
+	case []any:
+		filters = f
+	}
+	out := raw
+	for _, f := range filters {
+		switch f {
+		case name("FlateDecode"), name("Fl"):
+			r, err := zlib.NewReader(bytes.NewReader(out))
+			if err != nil {
+				return nil, nil
+			}
+			// Truncated streams keep the data decoded before the error.
+			decoded, _ := io.ReadAll(io.LimitReader(r, doc.budget+1))
+			if int64(len(decoded)) > doc.budget {
+				return nil, ErrTooLarge
+			}
+			out = decoded
+		case name("ASCIIHexDecode"), name("AHx"):
+			l := &lexer{data: out}
+			out = l.hexString()
+		case name("ASCII85Decode"), name("A85"):
+			src := bytes.TrimSpace(out)
+			src = bytes.TrimPrefix(src, []byte("<~"))
+			src = bytes.TrimSuffix(src, []byte("~>"))
+			dst := make([]byte, 4*len(src))
+			n, _, err := ascii85.Decode(dst, src, true)
+			if err != nil {
+				return nil, nil
+			}
+			out = dst[:n]
+		default:
+			return nil, nil
+		}
+	}
+	if int64(len(out)) > doc.budget {
+		return nil, ErrTooLarge
+	}
+	doc.budget -= int64(len(out))
+	return out, nil
+}
+
+// resolve follows indirect references.
+func (doc *document) resolve(v any) any {
+	for i := 0; i < 8; i++ {
+		r, ok := v.(ref)
+		if !ok {
+			return v
+		}
+		obj := doc.objects[r.num]
+		if obj == nil {
+			return nil
+		}
+		v = obj.value
+	}
+	return v
+}
+
+// streamOf returns the decoded data of the stream v refers to.
+func (doc *document) streamOf(v any) []byte {
+	r, ok := v.(ref)
+	if !ok {
+		return nil
+	}
+	if obj := doc.objects[r.num]; obj != nil && obj.isStrm {
+		return obj.stream
+	}
+	return nil
+}
+
+// pages returns page dictionaries in reading order. When the page tree cannot be
+// followed, pages are returned in object number order.
+func (doc *document) pages() []dict {
+	var out []dict
+	if root, ok := doc.resolve(doc.trailer["Root"]).(dict); ok {
+		doc.walkPages(doc.resolve(root["Pages"]), nil, 0, &out)
+	}
+	if len(out) > 0 {
+		return out
+	}
+
+	nums := make([]int, 0, len(doc.objects))
+	for num := range doc.objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	for _, num := range nums {
+		if d, ok := doc.objects[num].value.(dict); ok && d["Type"] == name("Page") {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func (doc *document) walkPages(node any, inherited any, depth int, out *[]dict) {
+	d, ok := node.(dict)
+	if !ok || depth > maxPageTreeDepth {
+		return
+	}
+	if res, has := d["Resources"]; has {
+		inherited = res
+	}
+	if kids, has := doc.resolve(d["Kids"]).([]any); has {
+		for _, kid := range kids {
+			doc.walkPages(doc.resolve(kid), inherited, depth+1, out)
+		}
+		return
+	}
+	if d["Type"] == name("Page") || d["Contents"] != nil {
+		page := dict{}
+		for k, v := range d {
+			page[k] = v
+		}
+		if _, has := page["Resources"]; !has && inherited != nil {
+			page["Resources"] = inherited
+		}
+		*out = append(*out, page)
+	}
+}
+
+// pageText concatenates the page's content streams and interprets them. It fails with
+// ErrTooLarge when the page fonts' CMaps exceed the remaining budget.
+func (doc *document) pageText(page dict) (string, error) {
+	var content []byte
+	switch c := page["Contents"].(type) {
+	case ref:
+		if arr, isArr := doc.resolve(c).([]any); isArr {
+			for _, item := range arr {
+				content = append(content, doc.streamOf(item)...)
+				content = append(content, '\n')
+			}
+		} else {
+			content = doc.streamOf(c)
+		}
+	case []any:
+		for _, item := range c {
+			content = append(content, doc.streamOf(item)...)
+			content = append(content, '\n')
+		}
+	}
+	if len(content) == 0 {
+		return "", nil
+	}
+	fonts, err := doc.pageFonts(page)
+	if err != nil {
+		return "", err
+	}
+	return interpret(content, fonts), nil
+}
+
+// pageFonts loads the text decoder for each font in the page resources.
+func (doc *document) pageFonts(page dict) (map[name]*font, error) {
+	fonts := make(map[name]*font)
+	res, _ := doc.resolve(page["Resources"]).(dict)
+	fontDict, _ := doc.resolve(res["Font"]).(dict)
+	for key, v := range fontDict {
+		fd, ok := doc.resolve(v).(dict)
+		if !ok {
+			continue
+		}
+		cm, err := doc.toUnicode(fd["ToUnicode"])
+		if err != nil {
+			return nil, err
+		}
+		f := &font{cmap: cm}
+		if enc, isName := fd["Encoding"].(name); isName && strings.HasPrefix(string(enc), "Identity") {
+			f.twoByte = true
+		}
+		fonts[key] = f
+	}
+	return fonts, nil
+}
+
+// toUnicode returns the parsed ToUnicode CMap v refers to. Each CMap is parsed once and
+// its codes are charged against the budget; ErrTooLarge is returned when they exceed it.
+func (doc *document) toUnicode(v any) (*cmap, error) {
+	r, ok := v.(ref)
+	if !ok {
+		return nil, nil
+	}
+	if cm, cached := doc.cmaps[r.num]; cached {
+		return cm, nil
+	}
+	var cm *cmap
+	if data := doc.streamOf(r); len(data) > 0 {
+		limit := int64(maxCMapCodes)
+		if affordable := doc.budget / cmapCodeCost; affordable < limit {
+			limit = affordable
+		}
+		var truncated bool
+		cm, truncated = parseCMap(data, int(limit))
+		if truncated && limit < maxCMapCodes {
+			return nil, ErrTooLarge
+		}
+		doc.budget -= int64(len(cm.codes)) * cmapCodeCost
+	}
+	doc.cmaps[r.num] = cm
+	return cm, nil
+}
+
+func number(v any) float64 {
+	if n, ok := v.(float64); ok {
+		return n
+	}
+	return 0
+}
+
+func atoi(b []byte) int {
+	n := 0
+	for _, c := range b {
+		n = n*10 + int(c-'0')
+	}
+	return n
+}
@@ -0,0 +1,196 @@
+package pdftext
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildPDF assembles a minimal PDF from object bodies numbered from 1. Streams are given
+// as their dictionary followed by the stream payload.
+func buildPDF(trailer string, objects ...string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.7\n")
+	for i, obj := range objects {
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	fmt.Fprintf(&b, "trailer\n%s\n%%%%EOF\n", trailer)
+	return b.Bytes()
+}
+
+func stream(dict string, data []byte) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func deflate(data string) []byte {
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	_, _ = w.Write([]byte(data))
+	_ = w.Close()
+	return b.Bytes()
+}
+
+func TestExtractPagesInTreeOrder(t *testing.T) {
+	page1 := "BT /F1 12 Tf 72 720 Td (Quarterly report) Tj 0 -14 Td [(Revenue) -300 (grew)] TJ ET"
+	page2 := "BT /F1 12 Tf 72 720 Td (Second \\(final\\) page) Tj ET"
+	data := buildPDF("<< /Root 1 0 R >>",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [4 0 R 3 0 R] /Count 2 /Resources << /Font << /F1 5 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 7 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 6 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		stream("", []byte(page1)),
+		stream("/Filter /FlateDecode", deflate(page2)),
+	)
+
+	got, err := Extract(data)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	want := "Quarterly report\nRevenue grew\n\nSecond (final) page"
+	if got != want {
+		t.Fatalf("Extract() = %q, want %q", got, want)
+	}
+}
+
+func TestExtractUsesToUnicodeCMap(t *testing.T) {
+	cmapData := `/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+1 begincodespacerange <0000> <FFFF> endcodespacerange
+2 beginbfchar
+<0001> <0048>
+<0002> <0069>
+endbfchar
+1 beginbfrange
+<0003> <0004> <00E9>
+endbfrange
+endcmap`
+	content := "BT /F1 10 Tf <000100020003> Tj ET"
+	data := buildPDF("<< /Root 1 0 R >>",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 4 0 R >> >> /Contents 6 0 R >>",
+		"<< /Type /Font /Subtype /Type0 /Encoding /Identity-H /ToUnicode 5 0 R >>",
+		stream("/Filter /FlateDecode", deflate(cmapData)),
+		stream("", []byte(content)),
+	)
+
+	got, err := Extract(data)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if got != "Hié" {
+		t.Fatalf("Extract() = %q, want %q", got, "Hié")
+	}
+}
+
+func TestExtractErrors(t *testing.T) {
+	if _, err := Extract([]byte("hello")); !errors.Is(err, ErrNotPDF) {
+		t.Fatalf("plain text error = %v, want ErrNotPDF", err)
+	}
+
+	encrypted := buildPDF("<< /Root 1 0 R /Encrypt 2 0 R >>",
+		"<< /Type /Catalog >>",
+		"<< /Filter /Standard >>",
+	)
+	if _, err := Extract(encrypted); !errors.Is(err, ErrEncrypted) {
+		t.Fatalf("encrypted error = %v, want ErrEncrypted", err)
+	}
+
+	imageOnly := buildPDF("<< /Root 1 0 R >>",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		stream("", []byte("q 100 0 0 100 0 0 cm BI /W 1 /H 1 /BPC 8 /CS /G ID \x00 EI Q")),
+	)
+	if _, err := Extract(imageOnly); !errors.Is(err, ErrNoText) {
+		t.Fatalf("image-only error = %v, want ErrNoText", err)
+	}
+}
+
+func TestExtractLimitsDecodedSize(t *testing.T) {
+	content := "BT /F1 12 Tf (" + string(bytes.Repeat([]byte("a"), 4096)) + ") Tj ET"
+	doc := buildPDF("<< /Root 1 0 R >>",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		stream("/Filter /FlateDecode", deflate(content)),
+	)
+	if _, err := ExtractLimit(doc, 1024); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("ExtractLimit() error = %v, want ErrTooLarge", err)
+	}
+	if _, err := ExtractLimit(doc, int64(len(content))); err != nil {
+		t.Fatalf("ExtractLimit() at the exact size error = %v", err)
+	}
+
+	// The limit covers the sum of all streams of the document.
+	twoStreams := buildPDF("<< /Root 1 0 R >>",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents [4 0 R 5 0 R] >>",
+		stream("/Filter /FlateDecode", deflate(content)),
+		stream("/Filter /FlateDecode", deflate(content)),
+	)
+	if _, err := ExtractLimit(twoStreams, int64(len(content))+1); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("ExtractLimit() on two streams error = %v, want ErrTooLarge", err)
+	}
+}
+
+func TestParseCMapCapsCodes(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("64 beginbfrange\n")
+	for i := 0; i < 64; i++ {
+		fmt.Fprintf(&b, "<%02X0000> <%02XFFFF> <0041>\n", i, i)
+	}
+	b.WriteString("endbfrange")
+
+	m, truncated := parseCMap([]byte(b.String()), maxCMapCodes)
+	if !truncated {
+		t.Fatal("parseCMap() should report the dropped codes")
+	}
+	if len(m.codes) != maxCMapCodes {
+		t.Fatalf("parseCMap() kept %d codes, want %d", len(m.codes), maxCMapCodes)
+	}
+}
+
+func TestExtractChargesCMapsOnce(t *testing.T) {
+	cmapData := "1 beginbfrange\n<0000> <03FF> <0041>\nendbfrange"
+	content := "BT /F1 10 Tf <0001> Tj ET"
+	data := buildPDF("<< /Root 1 0 R >>",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /Resources << /Font << /F1 5 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 7 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 7 0 R >>",
+		"<< /Type /Font /Subtype /Type0 /Encoding /Identity-H /ToUnicode 6 0 R >>",
+		stream("", []byte(cmapData)),
+		stream("", []byte(content)),
+	)
+	streams := int64(len(cmapData) + len(content))
+
+	// Both pages share the CMap, so it is charged once.
+	got, err := ExtractLimit(data, streams+0x400*cmapCodeCost)
+	if err != nil {
+		t.Fatalf("ExtractLimit() error = %v", err)
+	}
+	if got != "B\n\nB" {
+		t.Fatalf("ExtractLimit() = %q, want %q", got, "B\n\nB")
+	}
+	if _, err := ExtractLimit(data, streams+0x3FF*cmapCodeCost); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("ExtractLimit() below the CMap cost error = %v, want ErrTooLarge", err)
+	}
+}
+
+func TestLexerBoundsNesting(t *testing.T) {
+	deep := bytes.Repeat([]byte("["), 100000)
+	l := &lexer{data: deep}
+	if _, ok := l.object(); !ok {
+		t.Fatal("object() should return the truncated outer array")
+	}
+	if l.depth != 0 {
+		t.Fatalf("depth = %d after object(), want 0", l.depth)
+	}
+}
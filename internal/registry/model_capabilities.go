@@ -0,0 +1,172 @@
+package registry
+
+import "strings"
+
+// ModelCapabilities summarizes what a model supports so that clients can configure
+// themselves from the extended model listings.
+type ModelCapabilities struct {
+	// Vision reports whether the model accepts image input.
+	Vision bool `json:"vision"`
+	// Tools reports whether the model supports function calling.
+	Tools bool `json:"tools"`
+	// AudioInput reports whether the model accepts audio input.
+	AudioInput bool `json:"audio_input"`
+	// AudioOutput reports whether the model can generate speech.
+	AudioOutput bool `json:"audio_output"`
+	// Thinking reports whether the model supports extended reasoning.
+	Thinking bool `json:"thinking"`
+	// ThinkingLevels lists the discrete reasoning effort levels, when the model uses levels.
+	ThinkingLevels []string `json:"thinking_levels,omitempty"`
+	// ThinkingBudget is the supported reasoning budget range, when the model uses budgets.
+	ThinkingBudget *ThinkingBudget `json:"thinking_budget,omitempty"`
+	// ContextWindow is the maximum number of input tokens, when known.
+	ContextWindow int `json:"context_window,omitempty"`
+	// MaxOutputTokens is the maximum number of output tokens, when known.
+	MaxOutputTokens int `json:"max_output_tokens,omitempty"`
+	// Endpoints lists the proxy endpoints that can serve the model.
+	Endpoints []string `json:"endpoints"`
+}
+
+// ThinkingBudget is the reasoning budget range reported in ModelCapabilities.
+type ThinkingBudget struct {
+	Min            int  `json:"min"`
+	Max            int  `json:"max"`
+	ZeroAllowed    bool `json:"zero_allowed"`
+	DynamicAllowed bool `json:"dynamic_allowed"`
+}
+
+// capability returns a pointer for the optional ModelInfo capability flags.
+func capability(v bool) *bool {
+	return &v
+}
+
+// visionModelTypes are the provider families whose chat models all accept images.
+var visionModelTypes = map[string]bool{
+	"claude":      true,
+	"gemini":      true,
+	"vertex":      true,
+	"gemini-cli":  true,
+	"aistudio":    true,
+	"antigravity": true,
+	"codex":       true,
+	"kiro":        true,
+}
+
+// visionIDMarkers identify multimodal models of other providers by their ID.
+var visionIDMarkers = []string{"vision", "-vl", "gpt-4o", "gpt-4.1", "gpt-5", "claude", "gemini"}
+
+// audioModelTypes are the provider families whose Gemini chat models accept audio input.
+var audioModelTypes = map[string]bool{
+	"gemini":     true,
+	"vertex":     true,
+	"gemini-cli": true,
+	"aistudio":   true,
+}
+
+// audioInputIDMarkers identify audio-capable models of other providers by their ID.
+var audioInputIDMarkers = []string{"audio", "omni", "realtime"}
+
+// audioOutputIDMarkers identify speech-generating models by their ID.
+var audioOutputIDMarkers = []string{"tts", "native-audio", "audio-preview", "omni", "realtime"}
+
+var (
+	chatEndpoints = []string{
+		"/v1/chat/completions",
+		"/v1/completions",
+		"/v1/responses",
+		"/v1/messages",
+		"/v1beta/models/{model}:generateContent",
+		"/v1beta/models/{model}:streamGenerateContent",
+	}
+	embeddingEndpoints = []string{
+		"/v1/embeddings",
+		"/v1beta/models/{model}:embedContent",
+		"/v1beta/models/{model}:batchEmbedContents",
+	}
+)
+
+// Capabilities derives the capability summary of the model. Explicit Vision, Tools and
+// audio values win; otherwise vision and audio are inferred from the model family and ID,
+// and tools are assumed for every chat model.
+func (m *ModelInfo) Capabilities() ModelCapabilities {
+	if m == nil {
+		return ModelCapabilities{}
+	}
+	if m.IsEmbedding() {
+		return ModelCapabilities{
+			ContextWindow: m.contextWindow(),
+			Endpoints:     append([]string(nil), embeddingEndpoints...),
+		}
+	}
+
+	caps := ModelCapabilities{
+		Vision:          m.inferVision(),
+		Tools:           m.Tools == nil || *m.Tools,
+		AudioInput:      m.inferAudioInput(),
+		AudioOutput:     m.inferAudioOutput(),
+		ContextWindow:   m.contextWindow(),
+		MaxOutputTokens: m.MaxCompletionTokens,
+		Endpoints:       append([]string(nil), chatEndpoints...),
+	}
+	if caps.MaxOutputTokens == 0 {
+		caps.MaxOutputTokens = m.OutputTokenLimit
+	}
+	if strings.Contains(strings.ToLower(m.ID), "image") {
+		caps.Endpoints = append(caps.Endpoints, "/v1/images/generations", "/v1/images/edits")
+	}
+	if t := m.Thinking; t != nil {
+		caps.Thinking = true
+		if len(t.Levels) > 0 {
+			caps.ThinkingLevels = append([]string(nil), t.Levels...)
+		} else {
+			caps.ThinkingBudget = &ThinkingBudget{Min: t.Min, Max: t.Max, ZeroAllowed: t.ZeroAllowed, DynamicAllowed: t.DynamicAllowed}
+		}
+	}
+	return caps
+}
+
+func (m *ModelInfo) inferVision() bool {
+	if m.Vision != nil {
+		return *m.Vision
+	}
+	if visionModelTypes[strings.ToLower(m.Type)] {
+		return true
+	}
+	return containsAny(strings.ToLower(m.ID), visionIDMarkers)
+}
+
+// inferAudioInput treats Gemini chat models as audio-capable except for the image and
+// speech-only variants, which take text prompts.
+func (m *ModelInfo) inferAudioInput() bool {
+	if m.AudioInput != nil {
+		return *m.AudioInput
+	}
+	id := strings.ToLower(m.ID)
+	if audioModelTypes[strings.ToLower(m.Type)] && strings.Contains(id, "gemini") {
+		return !strings.Contains(id, "image") && !strings.Contains(id, "tts")
+	}
+	return containsAny(id, audioInputIDMarkers)
+}
+
+func (m *ModelInfo) inferAudioOutput() bool {
+	if m.AudioOutput != nil {
+		return *m.AudioOutput
+	}
+	return containsAny(strings.ToLower(m.ID), audioOutputIDMarkers)
+}
+
+func containsAny(s string, markers []string) bool {
+	for _, marker := range markers {
+		if strings.Contains(s, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *ModelInfo) contextWindow() int {
+	if m.ContextLength > 0 {
+		return m.ContextLength
+	}
+	return m.InputTokenLimit
+}
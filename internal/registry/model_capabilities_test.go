@@ -0,0 +1,90 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestModelCapabilities(t *testing.T) {
+	noVision := false
+	noTools := false
+	caps := (&ModelInfo{
+		ID:                  "custom-model",
+		Type:                "openai",
+		ContextLength:       128000,
+		MaxCompletionTokens: 16384,
+		Vision:              &noVision,
+		Tools:               &noTools,
+		Thinking:            &ThinkingSupport{Min: 1024, Max: 32768, ZeroAllowed: true},
+	}).Capabilities()
+	if caps.Vision || caps.Tools || !caps.Thinking {
+		t.Fatalf("explicit flags not honoured: %+v", caps)
+	}
+	if caps.ContextWindow != 128000 || caps.MaxOutputTokens != 16384 {
+		t.Fatalf("unexpected limits: %+v", caps)
+	}
+	if caps.ThinkingBudget == nil || caps.ThinkingBudget.Max != 32768 || !caps.ThinkingBudget.ZeroAllowed || len(caps.ThinkingLevels) != 0 {
+		t.Fatalf("unexpected thinking budget: %+v", caps)
+	}
+
+	caps = (&ModelInfo{ID: "claude-sonnet-4-5", Type: "claude", InputTokenLimit: 200000, OutputTokenLimit: 64000}).Capabilities()
+	if !caps.Vision || !caps.Tools || caps.Thinking || caps.ContextWindow != 200000 || caps.MaxOutputTokens != 64000 {
+		t.Fatalf("unexpected inferred capabilities: %+v", caps)
+	}
+
+	caps = (&ModelInfo{ID: "text-embedding-3-small", ModelType: ModelTypeEmbedding}).Capabilities()
+	if caps.Tools || caps.Vision || len(caps.Endpoints) == 0 || caps.Endpoints[0] != "/v1/embeddings" {
+		t.Fatalf("unexpected embedding capabilities: %+v", caps)
+	}
+}
+
+func TestModelCapabilitiesAudio(t *testing.T) {
+	caps := (&ModelInfo{ID: "gemini-2.5-flash", Type: "gemini"}).Capabilities()
+	if !caps.AudioInput || caps.AudioOutput {
+		t.Fatalf("gemini-2.5-flash audio = in:%v out:%v, want in only", caps.AudioInput, caps.AudioOutput)
+	}
+	caps = (&ModelInfo{ID: "gemini-2.5-flash-preview-tts", Type: "gemini"}).Capabilities()
+	if caps.AudioInput || !caps.AudioOutput {
+		t.Fatalf("tts model audio = in:%v out:%v, want out only", caps.AudioInput, caps.AudioOutput)
+	}
+	caps = (&ModelInfo{ID: "gpt-4o-audio-preview", Type: "openai"}).Capabilities()
+	if !caps.AudioInput || !caps.AudioOutput {
+		t.Fatalf("gpt-4o-audio-preview audio = in:%v out:%v, want both", caps.AudioInput, caps.AudioOutput)
+	}
+	enabled := true
+	caps = (&ModelInfo{ID: "custom", Type: "openai", AudioInput: &enabled}).Capabilities()
+	if !caps.AudioInput || caps.AudioOutput {
+		t.Fatalf("explicit audio flags not honoured: %+v", caps)
+	}
+}
+
+func TestStaticModelCapabilityFlags(t *testing.T) {
+	for _, model := range GetGeminiVertexModels() {
+		caps := model.Capabilities()
+		switch {
+		case strings.HasPrefix(model.ID, "imagen-"):
+			if caps.Vision || caps.Tools {
+				t.Fatalf("%s should report neither vision nor tools: %+v", model.ID, caps)
+			}
+		case strings.Contains(model.ID, "-image"):
+			if !caps.Vision || caps.Tools {
+				t.Fatalf("%s should accept images without tools: %+v", model.ID, caps)
+			}
+		}
+	}
+	for _, model := range GetIFlowModels() {
+		if model.ID == "tstars2.0" && !model.Capabilities().Vision {
+			t.Fatal("tstars2.0 is multimodal")
+		}
+	}
+}
+
+func TestClaudeModelOmitsUnknownCreatedAt(t *testing.T) {
+	r := &ModelRegistry{}
+	if got := r.convertModelToMap(&ModelInfo{ID: "claude-x", Type: "claude"}, "claude"); got["created_at"] != nil {
+		t.Fatalf("created_at should be omitted when unknown: %v", got)
+	}
+	if got := r.convertModelToMap(&ModelInfo{ID: "claude-x", Type: "claude", Created: 1750000000}, "claude"); got["created_at"] != "2025-06-15T15:06:40Z" {
+		t.Fatalf("created_at = %v", got["created_at"])
+	}
+}
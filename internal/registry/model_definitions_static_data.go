@@ -178,6 +178,7 @@ func GetGeminiModels() []*ModelInfo {
 		},
 		{
 			ID:                         "gemini-3-pro-image-preview",
+			Tools:                      capability(false),
 			Object:                     "model",
 			Created:                    1737158400,
 			OwnedBy:                    "google",
@@ -191,6 +192,36 @@ func GetGeminiModels() []*ModelInfo {
 			SupportedGenerationMethods: []string{"generateContent", "countTokens", "createCachedContent", "batchGenerateContent"},
 			Thinking:                   &ThinkingSupport{Min: 128, Max: 32768, ZeroAllowed: false, DynamicAllowed: true, Levels: []string{"low", "high"}},
 		},
+		{
+			ID:                         "gemini-embedding-001",
+			Object:                     "model",
+			Created:                    1752019200,
+			OwnedBy:                    "google",
+			Type:                       "gemini",
+			Name:                       "models/gemini-embedding-001",
+			Version:                    "001",
+			DisplayName:                "Gemini Embedding 001",
+			Description:                "Obtain a distributed representation of a text.",
+			InputTokenLimit:            2048,
+			OutputTokenLimit:           1,
+			SupportedGenerationMethods: []string{"embedContent", "countTextTokens", "countTokens", "asyncBatchEmbedContent"},
+			ModelType:                  ModelTypeEmbedding,
+		},
+		{
+			ID:                         "text-embedding-004",
+			Object:                     "model",
+			Created:                    1715644800,
+			OwnedBy:                    "google",
+			Type:                       "gemini",
+			Name:                       "models/text-embedding-004",
+			Version:                    "004",
+			DisplayName:                "Text Embedding 004",
+			Description:                "Obtain a distributed representation of a text.",
+			InputTokenLimit:            2048,
+			OutputTokenLimit:           1,
+			SupportedGenerationMethods: []string{"embedContent"},
+			ModelType:                  ModelTypeEmbedding,
+		},
 	}
 }
 
@@ -273,6 +304,7 @@ func GetGeminiVertexModels() []*ModelInfo {
 		},
 		{
 			ID:                         "gemini-3-pro-image-preview",
+			Tools:                      capability(false),
 			Object:                     "model",
 			Created:                    1737158400,
 			OwnedBy:                    "google",
@@ -298,6 +330,8 @@ func GetGeminiVertexModels() []*ModelInfo {
 			DisplayName:                "Imagen 4.0 Generate",
 			Description:                "Imagen 4.0 image generation model",
 			SupportedGenerationMethods: []string{"predict"},
+			Vision:                     capability(false),
+			Tools:                      capability(false),
 		},
 		{
 			ID:                         "imagen-4.0-ultra-generate-001",
@@ -310,6 +344,8 @@ func GetGeminiVertexModels() []*ModelInfo {
 			DisplayName:                "Imagen 4.0 Ultra Generate",
 			Description:                "Imagen 4.0 Ultra high-quality image generation model",
 			SupportedGenerationMethods: []string{"predict"},
+			Vision:                     capability(false),
+			Tools:                      capability(false),
 		},
 		{
 			ID:                         "imagen-3.0-generate-002",
@@ -322,6 +358,8 @@ func GetGeminiVertexModels() []*ModelInfo {
 			DisplayName:                "Imagen 3.0 Generate",
 			Description:                "Imagen 3.0 image generation model",
 			SupportedGenerationMethods: []string{"predict"},
+			Vision:                     capability(false),
+			Tools:                      capability(false),
 		},
 		{
 			ID:                         "imagen-3.0-fast-generate-001",
@@ -334,6 +372,8 @@ func GetGeminiVertexModels() []*ModelInfo {
 			DisplayName:                "Imagen 3.0 Fast Generate",
 			Description:                "Imagen 3.0 fast image generation model",
 			SupportedGenerationMethods: []string{"predict"},
+			Vision:                     capability(false),
+			Tools:                      capability(false),
 		},
 		{
 			ID:                         "imagen-4.0-fast-generate-001",
@@ -346,6 +386,38 @@ func GetGeminiVertexModels() []*ModelInfo {
 			DisplayName:                "Imagen 4.0 Fast Generate",
 			Description:                "Imagen 4.0 fast image generation model",
 			SupportedGenerationMethods: []string{"predict"},
+			Vision:                     capability(false),
+			Tools:                      capability(false),
+		},
+		{
+			ID:                         "gemini-embedding-001",
+			Object:                     "model",
+			Created:                    1752019200,
+			OwnedBy:                    "google",
+			Type:                       "gemini",
+			Name:                       "models/gemini-embedding-001",
+			Version:                    "001",
+			DisplayName:                "Gemini Embedding 001",
+			Description:                "Obtain a distributed representation of a text.",
+			InputTokenLimit:            2048,
+			OutputTokenLimit:           1,
+			SupportedGenerationMethods: []string{"predict"},
+			ModelType:                  ModelTypeEmbedding,
+		},
+		{
+			ID:                         "text-embedding-004",
+			Object:                     "model",
+			Created:                    1715644800,
+			OwnedBy:                    "google",
+			Type:                       "gemini",
+			Name:                       "models/text-embedding-004",
+			Version:                    "004",
+			DisplayName:                "Text Embedding 004",
+			Description:                "Obtain a distributed representation of a text.",
+			InputTokenLimit:            2048,
+			OutputTokenLimit:           1,
+			SupportedGenerationMethods: []string{"predict"},
+			ModelType:                  ModelTypeEmbedding,
 		},
 	}
 }
@@ -571,6 +643,7 @@ func GetAIStudioModels() []*ModelInfo {
 		// },
 		{
 			ID:                         "gemini-2.5-flash-image",
+			Tools:                      capability(false),
 			Object:                     "model",
 			Created:                    1759363200,
 			OwnedBy:                    "google",
@@ -779,8 +852,9 @@ func GetIFlowModels() []*ModelInfo {
 		Description string
 		Created     int64
 		Thinking    *ThinkingSupport
+		Vision      *bool
 	}{
-		{ID: "tstars2.0", DisplayName: "TStars-2.0", Description: "iFlow TStars-2.0 multimodal assistant", Created: 1746489600},
+		{ID: "tstars2.0", DisplayName: "TStars-2.0", Description: "iFlow TStars-2.0 multimodal assistant", Created: 1746489600, Vision: capability(true)},
 		{ID: "qwen3-coder-plus", DisplayName: "Qwen3-Coder-Plus", Description: "Qwen3 Coder Plus code generation", Created: 1753228800},
 		{ID: "qwen3-max", DisplayName: "Qwen3-Max", Description: "Qwen3 flagship model", Created: 1758672000},
 		{ID: "qwen3-vl-plus", DisplayName: "Qwen3-VL-Plus", Description: "Qwen3 multimodal vision-language", Created: 1758672000},
@@ -815,6 +889,7 @@ func GetIFlowModels() []*ModelInfo {
 			DisplayName: entry.DisplayName,
 			Description: entry.Description,
 			Thinking:    entry.Thinking,
+			Vision:      entry.Vision,
 		})
 	}
 	return models
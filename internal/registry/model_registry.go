@@ -49,6 +49,21 @@ type ModelInfo struct {
 	SupportedParameters []string `json:"supported_parameters,omitempty"`
 	// SupportedEndpoints lists supported API endpoints (e.g., "/chat/completions", "/responses").
 	SupportedEndpoints []string `json:"supported_endpoints,omitempty"`
+	// ModelType classifies what the model is used for (e.g., "embedding").
+	// Empty means a chat/generation model.
+	ModelType string `json:"model_type,omitempty"`
+	// Vision reports whether the model accepts image input.
+	// Nil means unknown; see Capabilities for how it is inferred.
+	Vision *bool `json:"vision,omitempty"`
+	// Tools reports whether the model supports function calling.
+	// Nil means supported unless the model only serves embeddings.
+	Tools *bool `json:"tools,omitempty"`
+	// AudioInput reports whether the model accepts audio input.
+	// Nil means unknown; see Capabilities for how it is inferred.
+	AudioInput *bool `json:"audio_input,omitempty"`
+	// AudioOutput reports whether the model can generate speech.
+	// Nil means unknown; see Capabilities for how it is inferred.
+	AudioOutput *bool `json:"audio_output,omitempty"`
 
 	// Thinking holds provider-specific reasoning/thinking budget capabilities.
 	// This is optional and currently used for Gemini thinking budget normalization.
@@ -60,6 +75,14 @@ type ModelInfo struct {
 	UserDefined bool `json:"-"`
 }
 
+// ModelTypeEmbedding marks models that only serve embedding requests.
+const ModelTypeEmbedding = "embedding"
+
+// IsEmbedding reports whether the model only serves embedding requests.
+func (m *ModelInfo) IsEmbedding() bool {
+	return m != nil && strings.EqualFold(m.ModelType, ModelTypeEmbedding)
+}
+
 // ThinkingSupport describes a model family's supported internal reasoning budget range.
 // Values are interpreted in provider-native token units.
 type ThinkingSupport struct {
@@ -116,6 +139,8 @@ type ModelRegistry struct {
 	mutex *sync.RWMutex
 	// hook is an optional callback sink for model registration changes
 	hook ModelRegistryHook
+	// virtualModels maps virtual model IDs to their definitions (see SetVirtualModels)
+	virtualModels map[string]VirtualModel
 }
 
 // Global model registry instance
@@ -707,52 +732,112 @@ func (r *ModelRegistry) ClientSupportsModel(clientID, modelID string) bool {
 // Returns:
 //   - []map[string]any: List of available models in the requested format
 func (r *ModelRegistry) GetAvailableModels(handlerType string) []map[string]any {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
+	return r.availableModels(handlerType, false)
+}
 
-	models := make([]map[string]any, 0)
-	quotaExpiredDuration := 5 * time.Minute
+// GetAvailableModelsExtended returns the same listing as GetAvailableModels with an added
+// "capabilities" object per model (see ModelInfo.Capabilities).
+func (r *ModelRegistry) GetAvailableModelsExtended(handlerType string) []map[string]any {
+	return r.availableModels(handlerType, true)
+}
 
-	for _, registration := range r.models {
-		// Check if model has any non-quota-exceeded clients
-		availableClients := registration.Count
-		now := time.Now()
+// GetAvailableModel returns a single available model in the requested format, or nil when
+// the model is unknown or has no usable clients. Gemini-style names with a "models/"
+// prefix are accepted.
+// Parameters:
+//   - modelID: The model ID or Gemini-style model name
+//   - handlerType: The handler type to format the model for
+//   - extended: Whether to include the "capabilities" object
+//
+// Returns:
+//   - map[string]any: The model in the requested format, or nil
+func (r *ModelRegistry) GetAvailableModel(modelID, handlerType string, extended bool) map[string]any {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
 
-		// Count clients that have exceeded quota but haven't recovered yet
-		expiredClients := 0
-		for _, quotaTime := range registration.QuotaExceededClients {
-			if quotaTime != nil && now.Sub(*quotaTime) < quotaExpiredDuration {
-				expiredClients++
+	modelID = strings.TrimPrefix(strings.TrimSpace(modelID), "models/")
+	registration, ok := r.models[modelID]
+	if !ok {
+		for _, candidate := range r.models {
+			if candidate.Info != nil && strings.TrimPrefix(candidate.Info.Name, "models/") == modelID {
+				registration = candidate
+				break
 			}
 		}
+	}
+	if registration == nil {
+		return r.convertModel(r.virtualModelInfoLocked(modelID, true, time.Now()), handlerType, extended)
+	}
+	if !registrationAvailable(registration, time.Now()) {
+		return nil
+	}
+	return r.convertModel(registration.Info, handlerType, extended)
+}
 
-		cooldownSuspended := 0
-		otherSuspended := 0
-		if registration.SuspendedClients != nil {
-			for _, reason := range registration.SuspendedClients {
-				if strings.EqualFold(reason, "quota") {
-					cooldownSuspended++
-					continue
-				}
-				otherSuspended++
-			}
+func (r *ModelRegistry) availableModels(handlerType string, extended bool) []map[string]any {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	models := make([]map[string]any, 0)
+	now := time.Now()
+	for _, registration := range r.models {
+		if !registrationAvailable(registration, now) {
+			continue
+		}
+		if model := r.convertModel(registration.Info, handlerType, extended); model != nil {
+			models = append(models, model)
+		}
+	}
+	for modelID := range r.virtualModels {
+		if model := r.convertModel(r.virtualModelInfoLocked(modelID, true, now), handlerType, extended); model != nil {
+			models = append(models, model)
 		}
+	}
 
-		effectiveClients := availableClients - expiredClients - otherSuspended
-		if effectiveClients < 0 {
-			effectiveClients = 0
+	return models
+}
+
+func (r *ModelRegistry) convertModel(model *ModelInfo, handlerType string, extended bool) map[string]any {
+	result := r.convertModelToMap(model, handlerType)
+	if result != nil && extended {
+		result["capabilities"] = model.Capabilities()
+	}
+	return result
+}
+
+// registrationAvailable reports whether a model has clients that are not quota-limited
+// or suspended, or whose only limited clients are cooling down.
+func registrationAvailable(registration *ModelRegistration, now time.Time) bool {
+	quotaExpiredDuration := 5 * time.Minute
+	availableClients := registration.Count
+
+	// Count clients that have exceeded quota but haven't recovered yet
+	expiredClients := 0
+	for _, quotaTime := range registration.QuotaExceededClients {
+		if quotaTime != nil && now.Sub(*quotaTime) < quotaExpiredDuration {
+			expiredClients++
 		}
+	}
 
-		// Include models that have available clients, or those solely cooling down.
-		if effectiveClients > 0 || (availableClients > 0 && (expiredClients > 0 || cooldownSuspended > 0) && otherSuspended == 0) {
-			model := r.convertModelToMap(registration.Info, handlerType)
-			if model != nil {
-				models = append(models, model)
+	cooldownSuspended := 0
+	otherSuspended := 0
+	if registration.SuspendedClients != nil {
+		for _, reason := range registration.SuspendedClients {
+			if strings.EqualFold(reason, "quota") {
+				cooldownSuspended++
+				continue
 			}
+			otherSuspended++
 		}
 	}
 
-	return models
+	effectiveClients := availableClients - expiredClients - otherSuspended
+	if effectiveClients < 0 {
+		effectiveClients = 0
+	}
+
+	// Include models that have available clients, or those solely cooling down.
+	return effectiveClients > 0 || (availableClients > 0 && (expiredClients > 0 || cooldownSuspended > 0) && otherSuspended == 0)
 }
 
 // GetAvailableModelsByProvider returns models available for the given provider identifier.
@@ -925,6 +1010,10 @@ func (r *ModelRegistry) GetModelProviders(modelID string) []string {
 	defer r.mutex.RUnlock()
 
 	registration, exists := r.models[modelID]
+	if vm, isVirtual := r.virtualModels[modelID]; !exists && isVirtual {
+		// Virtual models are served by the providers of their target.
+		registration, exists = r.models[vm.Target]
+	}
 	if !exists || registration == nil || len(registration.Providers) == 0 {
 		return nil
 	}
@@ -989,7 +1078,7 @@ func (r *ModelRegistry) GetModelInfo(modelID, provider string) *ModelInfo {
 		// Fallback to global info (last registered)
 		return reg.Info
 	}
-	return nil
+	return r.virtualModelInfoLocked(modelID, false, time.Time{})
 }
 
 // convertModelToMap converts ModelInfo to the appropriate format for different handler types
@@ -1036,19 +1125,25 @@ func (r *ModelRegistry) convertModelToMap(model *ModelInfo, handlerType string)
 
 	case "claude", "kiro", "antigravity":
 		// Claude, Kiro, and Antigravity all use Claude-compatible format for Claude Code client
-		result := map[string]any{
-			"id":       model.ID,
-			"object":   "model",
-			"owned_by": model.OwnedBy,
+		// Embedding models cannot serve messages requests, so they are not offered here.
+		if model.IsEmbedding() {
+			return nil
 		}
-		if model.Created > 0 {
-			result["created_at"] = model.Created
+		// The Anthropic Models API shape: type, id, display_name and an RFC 3339 created_at.
+		displayName := model.DisplayName
+		if displayName == "" {
+			displayName = model.ID
 		}
-		if model.Type != "" {
-			result["type"] = "model"
+		result := map[string]any{
+			"id":           model.ID,
+			"type":         "model",
+			"display_name": displayName,
+			"object":       "model",
+			"owned_by":     model.OwnedBy,
 		}
-		if model.DisplayName != "" {
-			result["display_name"] = model.DisplayName
+		// An unknown creation time is omitted rather than reported as the Unix epoch.
+		if model.Created > 0 {
+			result["created_at"] = time.Unix(model.Created, 0).UTC().Format(time.RFC3339)
 		}
 		// Add thinking support for Claude Code client
 		// Claude Code checks for "thinking" field (simple boolean) to enable tab toggle
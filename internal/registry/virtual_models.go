@@ -0,0 +1,62 @@
+package registry
+
+import (
+	"strings"
+	"time"
+)
+
+// VirtualModel is a client-visible model name served by another model.
+type VirtualModel struct {
+	// ID is the name clients request.
+	ID string
+	// Target is the base ID of the model the virtual model resolves to.
+	Target string
+	// Description replaces the target's description in listings when set.
+	Description string
+}
+
+// SetVirtualModels replaces the virtual models known to the registry. A virtual model is
+// listed only while its target has an available client, inherits the target's metadata,
+// and resolves to the target's providers. Real registrations take precedence over a
+// virtual model with the same ID.
+func (r *ModelRegistry) SetVirtualModels(models []VirtualModel) {
+	virtual := make(map[string]VirtualModel, len(models))
+	for _, vm := range models {
+		vm.ID = strings.TrimSpace(vm.ID)
+		vm.Target = strings.TrimSpace(vm.Target)
+		if vm.ID == "" || vm.Target == "" || vm.ID == vm.Target {
+			continue
+		}
+		virtual[vm.ID] = vm
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.virtualModels = virtual
+}
+
+// virtualModelInfoLocked returns the metadata of the virtual model with the given ID,
+// derived from its target, or nil when it is unknown, shadowed by a real registration, or
+// its target is not registered. With requireAvailable, a target without an available
+// client also yields nil. The caller must hold r.mutex.
+func (r *ModelRegistry) virtualModelInfoLocked(modelID string, requireAvailable bool, now time.Time) *ModelInfo {
+	vm, ok := r.virtualModels[modelID]
+	if !ok {
+		return nil
+	}
+	if _, shadowed := r.models[modelID]; shadowed {
+		return nil
+	}
+	target := r.models[vm.Target]
+	if target == nil || target.Info == nil || (requireAvailable && !registrationAvailable(target, now)) {
+		return nil
+	}
+	info := *target.Info
+	info.ID = vm.ID
+	info.Name = vm.ID
+	info.DisplayName = vm.ID
+	if vm.Description != "" {
+		info.Description = vm.Description
+	}
+	info.UserDefined = false
+	return &info
+}
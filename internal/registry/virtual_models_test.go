@@ -0,0 +1,60 @@
+package registry
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestVirtualModelsFollowTargetAvailability(t *testing.T) {
+	r := newTestModelRegistry()
+	r.SetVirtualModels([]VirtualModel{{ID: "review-bot", Target: "gpt-5", Description: "Code review assistant"}})
+
+	listed := func() bool {
+		for _, model := range r.GetAvailableModels("openai") {
+			if model["id"] == "review-bot" {
+				return true
+			}
+		}
+		return false
+	}
+	if listed() || r.GetAvailableModel("review-bot", "openai", false) != nil {
+		t.Fatal("virtual model listed without an available target")
+	}
+	if providers := r.GetModelProviders("review-bot"); len(providers) != 0 {
+		t.Fatalf("providers without target = %v", providers)
+	}
+
+	r.RegisterClient("codex-1", "codex", []*ModelInfo{{ID: "gpt-5", OwnedBy: "openai", ContextLength: 400000}})
+	if !listed() {
+		t.Fatal("virtual model not listed while its target is available")
+	}
+	model := r.GetAvailableModel("review-bot", "openai", true)
+	if model == nil || model["owned_by"] != "openai" {
+		t.Fatalf("virtual model should inherit target metadata: %v", model)
+	}
+	if providers := r.GetModelProviders("review-bot"); !slices.Equal(providers, []string{"codex"}) {
+		t.Fatalf("providers = %v, want the target's", providers)
+	}
+
+	r.SuspendClientModel("codex-1", "gpt-5", "disabled")
+	if listed() {
+		t.Fatal("virtual model listed while its target is suspended")
+	}
+	r.UnregisterClient("codex-1")
+	if listed() {
+		t.Fatal("virtual model listed after its target was removed")
+	}
+}
+
+func TestVirtualModelInfoResolvesThroughTarget(t *testing.T) {
+	r := newTestModelRegistry()
+	r.SetVirtualModels([]VirtualModel{{ID: "fast-embed", Target: "text-embedding-3-small"}})
+	if r.GetModelInfo("fast-embed", "") != nil {
+		t.Fatal("virtual model info without a registered target")
+	}
+	r.RegisterClient("openai-1", "openai", []*ModelInfo{{ID: "text-embedding-3-small", ModelType: ModelTypeEmbedding}})
+	info := r.GetModelInfo("fast-embed", "")
+	if info == nil || info.ID != "fast-embed" || !info.IsEmbedding() {
+		t.Fatalf("virtual model info = %+v", info)
+	}
+}
@@ -0,0 +1,156 @@
+package responsestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const fileSweepInterval = time.Minute
+
+// FileStore keeps one JSON file per record in a directory.
+type FileStore struct {
+	dir       string
+	ttl       time.Duration
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// NewFileStore creates a file-backed store rooted at dir. An empty dir defaults to
+// "responses" in the working directory.
+func NewFileStore(dir string, ttl time.Duration) (*FileStore, error) {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		if cwd, err := os.Getwd(); err == nil {
+			dir = filepath.Join(cwd, "responses")
+		} else {
+			dir = filepath.Join(os.TempDir(), "responses")
+		}
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("responsestore: resolve directory: %w", err)
+	}
+	if err = os.MkdirAll(absDir, 0o700); err != nil {
+		return nil, fmt.Errorf("responsestore: create directory: %w", err)
+	}
+	return &FileStore{dir: absDir, ttl: ttl}, nil
+}
+
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// Save writes the record atomically.
+func (s *FileStore) Save(_ context.Context, record *Record) error {
+	if record == nil || !validID(record.ID) {
+		return nil
+	}
+	stamp(record, s.ttl)
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("responsestore: encode record: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tmp := s.path(record.ID) + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("responsestore: write record: %w", err)
+	}
+	if err = os.Rename(tmp, s.path(record.ID)); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("responsestore: write record: %w", err)
+	}
+	s.sweepLocked()
+	return nil
+}
+
+// Load reads the record with the given ID.
+func (s *FileStore) Load(_ context.Context, id string) (*Record, error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, err := s.readLocked(s.path(id))
+	if err != nil {
+		return nil, err
+	}
+	if rec.expired(time.Now()) {
+		_ = os.Remove(s.path(id))
+		return nil, ErrNotFound
+	}
+	return rec, nil
+}
+
+// Delete removes the record with the given ID.
+func (s *FileStore) Delete(_ context.Context, id string) error {
+	if !validID(id) {
+		return ErrNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, err := s.readLocked(s.path(id))
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(s.path(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("responsestore: delete record: %w", err)
+	}
+	if rec.expired(time.Now()) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Close is a no-op for the file store.
+func (s *FileStore) Close() error { return nil }
+
+func (s *FileStore) readLocked(path string) (*Record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("responsestore: read record: %w", err)
+	}
+	var rec Record
+	if err = json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("responsestore: decode record: %w", err)
+	}
+	return &rec, nil
+}
+
+// sweepLocked removes expired records at most once per fileSweepInterval.
+func (s *FileStore) sweepLocked() {
+	now := time.Now()
+	if now.Sub(s.lastSweep) < fileSweepInterval {
+		return
+	}
+	s.lastSweep = now
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		log.Debugf("responsestore: sweep %s: %v", s.dir, err)
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(s.dir, entry.Name())
+		rec, errRead := s.readLocked(path)
+		if errRead != nil || rec.expired(now) {
+			_ = os.Remove(path)
+		}
+	}
+}
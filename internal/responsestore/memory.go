@@ -0,0 +1,102 @@
+package responsestore
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps records in process memory, evicting the oldest entries once
+// maxEntries is reached and dropping entries after their TTL.
+type MemoryStore struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	order      *list.List
+	entries    map[string]*list.Element
+}
+
+// NewMemoryStore creates an in-memory store.
+func NewMemoryStore(ttl time.Duration, maxEntries int) *MemoryStore {
+	return &MemoryStore{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Save stores the record.
+func (s *MemoryStore) Save(_ context.Context, record *Record) error {
+	if record == nil || !validID(record.ID) {
+		return nil
+	}
+	stamp(record, s.ttl)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.entries[record.ID]; ok {
+		s.order.Remove(elem)
+	}
+	s.entries[record.ID] = s.order.PushBack(record)
+	now := time.Now()
+	for elem := s.order.Front(); elem != nil; {
+		next := elem.Next()
+		rec := elem.Value.(*Record)
+		if !rec.expired(now) && s.order.Len() <= s.maxEntries {
+			break
+		}
+		s.order.Remove(elem)
+		delete(s.entries, rec.ID)
+		elem = next
+	}
+	return nil
+}
+
+// Load returns the record with the given ID.
+func (s *MemoryStore) Load(_ context.Context, id string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.entries[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	rec := elem.Value.(*Record)
+	if rec.expired(time.Now()) {
+		s.order.Remove(elem)
+		delete(s.entries, id)
+		return nil, ErrNotFound
+	}
+	clone := *rec
+	return &clone, nil
+}
+
+// Delete removes the record with the given ID.
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.entries[id]
+	if !ok {
+		return ErrNotFound
+	}
+	s.order.Remove(elem)
+	delete(s.entries, id)
+	if elem.Value.(*Record).expired(time.Now()) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Close is a no-op for the memory store.
+func (s *MemoryStore) Close() error { return nil }
+
+// stamp fills in the creation and expiry times of a record.
+func stamp(record *Record, ttl time.Duration) {
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now()
+	}
+	if record.ExpiresAt.IsZero() && ttl > 0 {
+		record.ExpiresAt = record.CreatedAt.Add(ttl)
+	}
+}
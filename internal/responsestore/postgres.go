@@ -0,0 +1,163 @@
+package responsestore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultPostgresTable  = "responses_store"
+	postgresSweepInterval = time.Minute
+)
+
+// PostgresStore keeps records in a PostgreSQL table.
+type PostgresStore struct {
+	db        *sql.DB
+	table     string
+	ttl       time.Duration
+	sweepMu   sync.Mutex
+	lastSweep time.Time
+}
+
+// NewPostgresStore connects to PostgreSQL and creates the table when missing.
+func NewPostgresStore(ctx context.Context, dsn, table string, ttl time.Duration) (*PostgresStore, error) {
+	dsn = strings.TrimSpace(dsn)
+	if dsn == "" {
+		return nil, fmt.Errorf("responsestore: postgres DSN is required")
+	}
+	if table = strings.TrimSpace(table); table == "" {
+		table = defaultPostgresTable
+	}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("responsestore: open database connection: %w", err)
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err = db.PingContext(pingCtx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("responsestore: ping database: %w", err)
+	}
+	store := &PostgresStore{db: db, table: quoteIdentifier(table), ttl: ttl}
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id TEXT PRIMARY KEY,
+		owner TEXT NOT NULL DEFAULT '',
+		history JSONB,
+		input JSONB NOT NULL,
+		response JSONB NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL,
+		expires_at TIMESTAMPTZ
+	)`, store.table)
+	if _, err = db.ExecContext(ctx, query); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("responsestore: create table: %w", err)
+	}
+	// Tables created before records were scoped to an API key lack the owner column.
+	query = fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS owner TEXT NOT NULL DEFAULT ''`, store.table)
+	if _, err = db.ExecContext(ctx, query); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("responsestore: add owner column: %w", err)
+	}
+	return store, nil
+}
+
+// Save upserts the record.
+func (s *PostgresStore) Save(ctx context.Context, record *Record) error {
+	if record == nil || !validID(record.ID) {
+		return nil
+	}
+	stamp(record, s.ttl)
+	var history any
+	if len(record.History) > 0 {
+		history = string(record.History)
+	}
+	var expiresAt any
+	if !record.ExpiresAt.IsZero() {
+		expiresAt = record.ExpiresAt
+	}
+	query := fmt.Sprintf(`INSERT INTO %s (id, owner, history, input, response, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO UPDATE SET owner = EXCLUDED.owner, history = EXCLUDED.history, input = EXCLUDED.input,
+			response = EXCLUDED.response, created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at`, s.table)
+	if _, err := s.db.ExecContext(ctx, query, record.ID, record.Owner, history, string(record.Input), string(record.Response), record.CreatedAt, expiresAt); err != nil {
+		return fmt.Errorf("responsestore: save record: %w", err)
+	}
+	s.sweep(ctx)
+	return nil
+}
+
+// Load returns the record with the given ID.
+func (s *PostgresStore) Load(ctx context.Context, id string) (*Record, error) {
+	query := fmt.Sprintf(`SELECT owner, history, input, response, created_at, expires_at FROM %s
+		WHERE id = $1 AND (expires_at IS NULL OR expires_at > NOW())`, s.table)
+	var history sql.NullString
+	var input, response string
+	var expiresAt sql.NullTime
+	rec := &Record{ID: id}
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&rec.Owner, &history, &input, &response, &rec.CreatedAt, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("responsestore: load record: %w", err)
+	}
+	if history.Valid {
+		rec.History = []byte(history.String)
+	}
+	rec.Input = []byte(input)
+	rec.Response = []byte(response)
+	if expiresAt.Valid {
+		rec.ExpiresAt = expiresAt.Time
+	}
+	return rec, nil
+}
+
+// Delete removes the record with the given ID.
+func (s *PostgresStore) Delete(ctx context.Context, id string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1 AND (expires_at IS NULL OR expires_at > NOW())`, s.table)
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("responsestore: delete record: %w", err)
+	}
+	if affected, errRows := result.RowsAffected(); errRows == nil && affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Close releases the database connection.
+func (s *PostgresStore) Close() error {
+	if s == nil || s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// sweep deletes expired rows at most once per postgresSweepInterval.
+func (s *PostgresStore) sweep(ctx context.Context) {
+	s.sweepMu.Lock()
+	now := time.Now()
+	if now.Sub(s.lastSweep) < postgresSweepInterval {
+		s.sweepMu.Unlock()
+		return
+	}
+	s.lastSweep = now
+	s.sweepMu.Unlock()
+	query := fmt.Sprintf(`DELETE FROM %s WHERE expires_at IS NOT NULL AND expires_at <= NOW()`, s.table)
+	if _, err := s.db.ExecContext(ctx, query); err != nil {
+		log.Debugf("responsestore: sweep expired rows: %v", err)
+	}
+}
+
+func quoteIdentifier(identifier string) string {
+	replaced := strings.ReplaceAll(identifier, "\"", "\"\"")
+	return "\"" + replaced + "\""
+}
@@ -0,0 +1,193 @@
+// Package responsestore persists Responses API results so follow-up requests can
+// reference them through previous_response_id and clients can retrieve or delete
+// them through /v1/responses/{id}. Records expire after a configurable TTL and can
+// be kept in memory, on disk, or in PostgreSQL.
+package responsestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultTTL        = time.Hour
+	defaultMaxEntries = 1024
+)
+
+// Backend names accepted in ResponsesStoreConfig.Backend.
+const (
+	BackendMemory   = "memory"
+	BackendFile     = "file"
+	BackendPostgres = "postgres"
+)
+
+// ErrNotFound is returned when a response is unknown or has expired.
+var ErrNotFound = errors.New("responsestore: response not found")
+
+// Record is a stored response together with the conversation items that produced it.
+type Record struct {
+	// ID is the response identifier returned to the client.
+	ID string `json:"id"`
+	// Owner identifies the client API key that created the response. Lookups from
+	// other keys treat the record as missing.
+	Owner string `json:"owner,omitempty"`
+	// History holds the items of earlier turns reached through previous_response_id.
+	History json.RawMessage `json:"history,omitempty"`
+	// Input holds the input items supplied with this request.
+	Input json.RawMessage `json:"input"`
+	// Response is the final Responses API response object.
+	Response json.RawMessage `json:"response"`
+	// CreatedAt is when the record was saved.
+	CreatedAt time.Time `json:"created_at"`
+	// ExpiresAt is when the record stops being retrievable.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (r *Record) expired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && now.After(r.ExpiresAt)
+}
+
+// Store persists response records.
+type Store interface {
+	// Save stores the record, replacing any record with the same ID.
+	Save(ctx context.Context, record *Record) error
+	// Load returns the record with the given ID or ErrNotFound.
+	Load(ctx context.Context, id string) (*Record, error)
+	// Delete removes the record with the given ID or returns ErrNotFound.
+	Delete(ctx context.Context, id string) error
+	// Close releases backend resources.
+	Close() error
+}
+
+// New builds a store for the given configuration. It returns a nil store unless storage is enabled.
+func New(ctx context.Context, cfg config.ResponsesStoreConfig) (Store, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	ttl := defaultTTL
+	if cfg.TTLSeconds > 0 {
+		ttl = time.Duration(cfg.TTLSeconds) * time.Second
+	}
+	switch backend := strings.ToLower(strings.TrimSpace(cfg.Backend)); backend {
+	case "", BackendMemory:
+		maxEntries := cfg.MaxEntries
+		if maxEntries <= 0 {
+			maxEntries = defaultMaxEntries
+		}
+		return NewMemoryStore(ttl, maxEntries), nil
+	case BackendFile:
+		return NewFileStore(cfg.Dir, ttl)
+	case BackendPostgres:
+		return NewPostgresStore(ctx, cfg.DSN, cfg.Table, ttl)
+	default:
+		return nil, fmt.Errorf("responsestore: unknown backend %q", cfg.Backend)
+	}
+}
+
+// newStore builds the shared store; tests replace it to observe Close.
+var newStore = New
+
+// sharedLease tracks the users of the shared store so a store replaced by a configuration
+// change is closed only after the requests still using it are done.
+type sharedLease struct {
+	store   Store
+	users   int
+	retired bool
+}
+
+var shared struct {
+	mu    sync.Mutex
+	ready bool
+	cfg   config.ResponsesStoreConfig
+	lease *sharedLease
+	err   error
+}
+
+// Shared returns the process-wide store for cfg, rebuilding it when the configuration
+// changes, and a release function the caller must call once it stops using the store.
+// A replaced store is closed after its last user releases it. Construction failures are
+// cached until the configuration changes so a broken backend does not add connection
+// latency to every request.
+func Shared(cfg config.ResponsesStoreConfig) (Store, func(), error) {
+	shared.mu.Lock()
+	var retired Store
+	if !shared.ready || shared.cfg != cfg {
+		if old := shared.lease; old != nil {
+			old.retired = true
+			if old.users == 0 {
+				retired = old.store
+			}
+		}
+		shared.lease = nil
+		store, err := newStore(context.Background(), cfg)
+		if err != nil {
+			log.Errorf("responsestore: %v", err)
+		}
+		if store != nil {
+			shared.lease = &sharedLease{store: store}
+		}
+		shared.err = err
+		shared.cfg = cfg
+		shared.ready = true
+	}
+	lease, err := shared.lease, shared.err
+	if lease != nil {
+		lease.users++
+	}
+	shared.mu.Unlock()
+	closeStore(retired)
+
+	if lease == nil {
+		return nil, func() {}, err
+	}
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			shared.mu.Lock()
+			lease.users--
+			done := lease.retired && lease.users == 0
+			shared.mu.Unlock()
+			if done {
+				closeStore(lease.store)
+			}
+		})
+	}
+	return lease.store, release, err
+}
+
+func closeStore(store Store) {
+	if store == nil {
+		return
+	}
+	if errClose := store.Close(); errClose != nil {
+		log.Warnf("responsestore: close previous store: %v", errClose)
+	}
+}
+
+// VisibleTo reports whether the record may be read or deleted by owner.
+func (r *Record) VisibleTo(owner string) bool {
+	return r.Owner == owner
+}
+
+// validID reports whether id is safe to use as a storage key.
+func validID(id string) bool {
+	if id == "" || len(id) > 256 {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,150 @@
+package responsestore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestMemoryStoreRoundTripAndEviction(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(time.Hour, 2)
+
+	for _, id := range []string{"resp_a", "resp_b", "resp_c"} {
+		if err := store.Save(ctx, &Record{ID: id, Input: []byte(`[]`), Response: []byte(`{"id":"` + id + `"}`)}); err != nil {
+			t.Fatalf("save %s: %v", id, err)
+		}
+	}
+	if _, err := store.Load(ctx, "resp_a"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("oldest entry should be evicted, got err=%v", err)
+	}
+	rec, err := store.Load(ctx, "resp_c")
+	if err != nil {
+		t.Fatalf("load resp_c: %v", err)
+	}
+	if string(rec.Response) != `{"id":"resp_c"}` {
+		t.Fatalf("unexpected response %s", rec.Response)
+	}
+	if err = store.Delete(ctx, "resp_c"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err = store.Delete(ctx, "resp_c"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("second delete should report not found, got %v", err)
+	}
+}
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(time.Hour, 10)
+	past := time.Now().Add(-2 * time.Hour)
+	if err := store.Save(ctx, &Record{ID: "resp_old", CreatedAt: past, ExpiresAt: past.Add(time.Hour)}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, err := store.Load(ctx, "resp_old"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expired record should not load, got %v", err)
+	}
+}
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("new file store: %v", err)
+	}
+	record := &Record{
+		ID:       "resp_file",
+		History:  []byte(`[{"type":"message","role":"user","content":"hi"}]`),
+		Input:    []byte(`[{"type":"message","role":"user","content":"again"}]`),
+		Response: []byte(`{"id":"resp_file","output":[]}`),
+	}
+	if err = store.Save(ctx, record); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	loaded, err := store.Load(ctx, "resp_file")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if string(loaded.Input) != string(record.Input) || string(loaded.History) != string(record.History) {
+		t.Fatalf("round trip mismatch: %+v", loaded)
+	}
+	if loaded.ExpiresAt.IsZero() {
+		t.Fatal("expected expiry to be set from TTL")
+	}
+	if _, err = store.Load(ctx, "../escape"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("invalid ids must not resolve, got %v", err)
+	}
+	if err = store.Delete(ctx, "resp_file"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err = store.Load(ctx, "resp_file"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("deleted record should not load, got %v", err)
+	}
+}
+
+func TestSharedRebuildsOnConfigChange(t *testing.T) {
+	first, release, err := Shared(config.ResponsesStoreConfig{Enabled: true, MaxEntries: 5})
+	if err != nil || first == nil {
+		t.Fatalf("shared memory store: %v", err)
+	}
+	release()
+	again, release, _ := Shared(config.ResponsesStoreConfig{Enabled: true, MaxEntries: 5})
+	if again != first {
+		t.Fatal("expected the same store for an unchanged config")
+	}
+	release()
+	disabled, release, err := Shared(config.ResponsesStoreConfig{MaxEntries: 5})
+	if err != nil || disabled != nil {
+		t.Fatalf("disabled config should yield no store, got %v, %v", disabled, err)
+	}
+	release()
+	if _, _, err = Shared(config.ResponsesStoreConfig{Enabled: true, Backend: "bogus"}); err == nil {
+		t.Fatal("expected an error for an unknown backend")
+	}
+}
+
+type closeCountingStore struct {
+	*MemoryStore
+	closed int
+}
+
+func (s *closeCountingStore) Close() error {
+	s.closed++
+	return nil
+}
+
+func TestSharedClosesReplacedStoreAfterLastRelease(t *testing.T) {
+	var built []*closeCountingStore
+	newStore = func(context.Context, config.ResponsesStoreConfig) (Store, error) {
+		store := &closeCountingStore{MemoryStore: NewMemoryStore(time.Minute, 5)}
+		built = append(built, store)
+		return store, nil
+	}
+	defer func() { newStore = New }()
+
+	_, releaseFirst, _ := Shared(config.ResponsesStoreConfig{Enabled: true, MaxEntries: 1})
+	_, releaseSecond, _ := Shared(config.ResponsesStoreConfig{Enabled: true, MaxEntries: 1})
+	_, releaseNew, _ := Shared(config.ResponsesStoreConfig{Enabled: true, MaxEntries: 2})
+	if len(built) != 2 || built[0].closed != 0 {
+		t.Fatalf("replaced store closed while in use: %+v", built)
+	}
+	releaseFirst()
+	releaseFirst()
+	if built[0].closed != 0 {
+		t.Fatal("replaced store closed before its last user released it")
+	}
+	releaseSecond()
+	if built[0].closed != 1 || built[1].closed != 0 {
+		t.Fatalf("close counts = %d, %d, want 1, 0", built[0].closed, built[1].closed)
+	}
+
+	// A store nobody holds is closed as soon as it is replaced.
+	releaseNew()
+	_, releaseLast, _ := Shared(config.ResponsesStoreConfig{Enabled: true, MaxEntries: 3})
+	defer releaseLast()
+	if built[1].closed != 1 {
+		t.Fatalf("idle replaced store close count = %d, want 1", built[1].closed)
+	}
+}
@@ -397,6 +397,9 @@ func (e *AIStudioExecutor) translateRequest(req cliproxyexecutor.Request, opts c
 	if err != nil {
 		return nil, translatedPayload{}, err
 	}
+	if err = checkAudioSupport(e.Identifier(), baseModel, payload); err != nil {
+		return nil, translatedPayload{}, err
+	}
 	payload = fixGeminiImageAspectRatio(baseModel, payload)
 	requestedModel := payloadRequestedModel(opts, req.Model)
 	payload = applyPayloadConfigWithRoot(e.cfg, baseModel, to.String(), "", payload, originalTranslated, requestedModel)
@@ -414,6 +417,7 @@ func (e *AIStudioExecutor) translateRequest(req cliproxyexecutor.Request, opts c
 		action = "streamGenerateContent"
 	}
 	payload, _ = sjson.DeleteBytes(payload, "session_id")
+	payload, _ = sjson.DeleteBytes(payload, "cache_control")
 	return payload, translatedPayload{payload: payload, action: action, toFormat: to}, nil
 }
 
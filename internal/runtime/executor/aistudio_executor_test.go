@@ -0,0 +1,28 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+func TestAIStudioExecutorStripsCacheControl(t *testing.T) {
+	exec := NewAIStudioExecutor(&config.Config{}, "aistudio", nil)
+	req := cliproxyexecutor.Request{
+		Model:   "gemini-2.5-flash",
+		Payload: []byte(`{"contents":[{"role":"user","parts":[{"text":"hi"}]}],"cache_control":{"type":"ephemeral"}}`),
+	}
+	opts := cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("gemini")}
+	for _, stream := range []bool{false, true} {
+		_, body, err := exec.translateRequest(req, opts, stream)
+		if err != nil {
+			t.Fatalf("translateRequest(stream=%v) error = %v", stream, err)
+		}
+		if gjson.GetBytes(body.payload, "cache_control").Exists() {
+			t.Fatalf("stream=%v request carries cache_control: %s", stream, body.payload)
+		}
+	}
+}
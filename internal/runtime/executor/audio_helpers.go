@@ -0,0 +1,66 @@
+package executor
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/tidwall/gjson"
+)
+
+// checkAudioSupport rejects a translated request with 400 when it carries audio input or
+// asks for audio output and the registry reports that the model cannot handle it. Both
+// Gemini- and OpenAI-shaped payloads are inspected. Models the registry does not know,
+// and user-defined models without explicit audio flags, are passed through so the
+// upstream decides.
+func checkAudioSupport(provider, model string, body []byte) error {
+	input, output := requestAudioUsage(body)
+	if !input && !output {
+		return nil
+	}
+	info := registry.LookupModelInfo(model, provider)
+	if info == nil || (info.UserDefined && info.AudioInput == nil && info.AudioOutput == nil) {
+		return nil
+	}
+	caps := info.Capabilities()
+	if input && !caps.AudioInput {
+		return statusErr{code: http.StatusBadRequest, msg: fmt.Sprintf("model %s does not support audio input", model)}
+	}
+	if output && !caps.AudioOutput {
+		return statusErr{code: http.StatusBadRequest, msg: fmt.Sprintf("model %s does not support audio output", model)}
+	}
+	return nil
+}
+
+// requestAudioUsage reports whether the payload contains audio parts and whether it
+// requests an audio response.
+func requestAudioUsage(body []byte) (input, output bool) {
+	gjson.GetBytes(body, "contents").ForEach(func(_, content gjson.Result) bool {
+		content.Get("parts").ForEach(func(_, part gjson.Result) bool {
+			_, input = util.GeminiAudio(part)
+			return !input
+		})
+		return !input
+	})
+	if !input {
+		gjson.GetBytes(body, "messages").ForEach(func(_, message gjson.Result) bool {
+			message.Get("content").ForEach(func(_, part gjson.Result) bool {
+				input = part.Get("type").String() == "input_audio"
+				return !input
+			})
+			return !input
+		})
+	}
+
+	modalities := gjson.GetBytes(body, "generationConfig.responseModalities")
+	if !modalities.Exists() {
+		modalities = gjson.GetBytes(body, "modalities")
+	}
+	modalities.ForEach(func(_, modality gjson.Result) bool {
+		output = strings.EqualFold(modality.String(), "audio")
+		return !output
+	})
+	return input, output
+}
@@ -0,0 +1,43 @@
+package executor
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+)
+
+func TestCheckAudioSupport(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("audio-test-client", "gemini", []*registry.ModelInfo{
+		{ID: "audio-test-gemini-2.5-flash", Type: "gemini"},
+		{ID: "audio-test-gemini-2.5-flash-image", Type: "gemini"},
+	})
+	t.Cleanup(func() { reg.UnregisterClient("audio-test-client") })
+
+	audioInput := []byte(`{"contents":[{"role":"user","parts":[{"inlineData":{"mimeType":"audio/wav","data":"UklGRg=="}}]}]}`)
+	if err := checkAudioSupport("gemini", "audio-test-gemini-2.5-flash", audioInput); err != nil {
+		t.Fatalf("audio input on audio model: %v", err)
+	}
+
+	var se statusErr
+	err := checkAudioSupport("gemini", "audio-test-gemini-2.5-flash-image", audioInput)
+	if !errors.As(err, &se) || se.code != http.StatusBadRequest {
+		t.Fatalf("audio input on image model error = %v, want 400", err)
+	}
+
+	speech := []byte(`{"contents":[{"role":"user","parts":[{"text":"hi"}]}],"generationConfig":{"responseModalities":["AUDIO"]}}`)
+	err = checkAudioSupport("gemini", "audio-test-gemini-2.5-flash", speech)
+	if !errors.As(err, &se) || se.code != http.StatusBadRequest {
+		t.Fatalf("audio output on text model error = %v, want 400", err)
+	}
+
+	openaiInput := []byte(`{"messages":[{"role":"user","content":[{"type":"input_audio","input_audio":{"data":"AA==","format":"wav"}}]}]}`)
+	if err = checkAudioSupport("openai", "audio-test-unknown-model", openaiInput); err != nil {
+		t.Fatalf("unknown model should pass through, got %v", err)
+	}
+	if err = checkAudioSupport("gemini", "audio-test-gemini-2.5-flash-image", []byte(`{"contents":[{"parts":[{"text":"hi"}]}]}`)); err != nil {
+		t.Fatalf("text-only request rejected: %v", err)
+	}
+}
@@ -0,0 +1,368 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	bedrockauth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/bedrock"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tokencount"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// BedrockExecutor serves Claude models on Amazon Bedrock through the Converse and
+// ConverseStream APIs, signing requests with AWS Signature Version 4.
+type BedrockExecutor struct {
+	cfg *config.Config
+}
+
+func NewBedrockExecutor(cfg *config.Config) *BedrockExecutor { return &BedrockExecutor{cfg: cfg} }
+
+func (e *BedrockExecutor) Identifier() string { return "bedrock" }
+
+// HttpRequest signs the request with the Bedrock credentials and executes it.
+func (e *BedrockExecutor) HttpRequest(ctx context.Context, auth *cliproxyauth.Auth, req *http.Request) (*http.Response, error) {
+	if req == nil {
+		return nil, fmt.Errorf("bedrock executor: request is nil")
+	}
+	if ctx == nil {
+		ctx = req.Context()
+	}
+	httpReq := req.WithContext(ctx)
+	var body []byte
+	if httpReq.Body != nil {
+		var err error
+		if body, err = io.ReadAll(httpReq.Body); err != nil {
+			return nil, err
+		}
+		_ = httpReq.Body.Close()
+		httpReq.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	creds, region, _, err := bedrockCreds(auth)
+	if err != nil {
+		return nil, err
+	}
+	if err = bedrockauth.SignRequest(httpReq, body, creds, region, bedrockauth.ServiceName, time.Now()); err != nil {
+		return nil, err
+	}
+	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
+	return httpClient.Do(httpReq)
+}
+
+func (e *BedrockExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (resp cliproxyexecutor.Response, err error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+
+	reporter := newUsageReporter(ctx, e.Identifier(), baseModel, auth)
+	defer reporter.trackFailure(ctx, &err)
+	from := opts.SourceFormat
+	to := sdktranslator.FromString("bedrock")
+	body, err := e.translateRequest(req, opts, baseModel, false)
+	if err != nil {
+		return resp, err
+	}
+
+	httpResp, err := e.send(ctx, auth, baseModel, "converse", body, false)
+	if err != nil {
+		return resp, err
+	}
+	defer func() {
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("response body close error: %v", errClose)
+		}
+	}()
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		recordAPIResponseError(ctx, e.cfg, err)
+		return resp, err
+	}
+	appendAPIResponseChunk(ctx, e.cfg, data)
+	if detail, ok := parseBedrockUsage(gjson.GetBytes(data, "usage")); ok {
+		reporter.publish(ctx, detail)
+	}
+	var param any
+	out := sdktranslator.TranslateNonStream(
+		ctx,
+		to,
+		from,
+		req.Model,
+		bytes.Clone(opts.OriginalRequest),
+		body,
+		data,
+		&param,
+	)
+	resp = cliproxyexecutor.Response{Payload: []byte(out)}
+	return resp, nil
+}
+
+func (e *BedrockExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (stream <-chan cliproxyexecutor.StreamChunk, err error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+
+	reporter := newUsageReporter(ctx, e.Identifier(), baseModel, auth)
+	defer reporter.trackFailure(ctx, &err)
+	from := opts.SourceFormat
+	to := sdktranslator.FromString("bedrock")
+	body, err := e.translateRequest(req, opts, baseModel, true)
+	if err != nil {
+		return nil, err
+	}
+
+	httpResp, err := e.send(ctx, auth, baseModel, "converse-stream", body, true)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan cliproxyexecutor.StreamChunk)
+	stream = out
+	go func() {
+		defer close(out)
+		defer func() {
+			if errClose := httpResp.Body.Close(); errClose != nil {
+				log.Errorf("response body close error: %v", errClose)
+			}
+		}()
+
+		reader := bufio.NewReader(httpResp.Body)
+		var param any
+		for {
+			msg, eventErr := readEventStreamMessage(reader)
+			if eventErr != nil {
+				recordAPIResponseError(ctx, e.cfg, eventErr)
+				reporter.publishFailure(ctx)
+				out <- cliproxyexecutor.StreamChunk{Err: eventErr}
+				return
+			}
+			if msg == nil {
+				return
+			}
+			appendAPIResponseChunk(ctx, e.cfg, msg.Payload)
+			if msg.MessageType == "exception" || msg.MessageType == "error" {
+				errStream := bedrockStreamError(msg)
+				recordAPIResponseError(ctx, e.cfg, errStream)
+				reporter.publishFailure(ctx)
+				out <- cliproxyexecutor.StreamChunk{Err: errStream}
+				return
+			}
+			if msg.EventType == "" || len(msg.Payload) == 0 {
+				continue
+			}
+			if msg.EventType == "metadata" {
+				if detail, ok := parseBedrockUsage(gjson.GetBytes(msg.Payload, "usage")); ok {
+					reporter.publish(ctx, detail)
+				}
+			}
+			event, errSet := sjson.SetRawBytes([]byte(`{}`), msg.EventType, msg.Payload)
+			if errSet != nil {
+				continue
+			}
+			chunks := sdktranslator.TranslateStream(
+				ctx,
+				to,
+				from,
+				req.Model,
+				bytes.Clone(opts.OriginalRequest),
+				body,
+				event,
+				&param,
+			)
+			for i := range chunks {
+				out <- cliproxyexecutor.StreamChunk{Payload: []byte(chunks[i])}
+			}
+		}
+	}()
+	return stream, nil
+}
+
+// CountTokens estimates the prompt tokens locally; Converse has no token counting
+// endpoint for every model.
+func (e *BedrockExecutor) CountTokens(ctx context.Context, _ *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+	from := opts.SourceFormat
+	count, err := tokencount.CountRequest(from.String(), baseModel, req.Payload)
+	if err != nil {
+		return cliproxyexecutor.Response{}, err
+	}
+	out := sdktranslator.TranslateTokenCount(ctx, sdktranslator.FromString("bedrock"), from, count, []byte(fmt.Sprintf(`{"count":%d}`, count)))
+	return cliproxyexecutor.Response{Payload: []byte(out)}, nil
+}
+
+// Refresh is a no-op: static credentials do not expire and profile credentials are
+// re-read on every request.
+func (e *BedrockExecutor) Refresh(ctx context.Context, auth *cliproxyauth.Auth) (*cliproxyauth.Auth, error) {
+	log.Debugf("bedrock executor: refresh called")
+	_ = ctx
+	return auth, nil
+}
+
+// translateRequest converts the client request into a Converse request body.
+func (e *BedrockExecutor) translateRequest(req cliproxyexecutor.Request, opts cliproxyexecutor.Options, baseModel string, stream bool) ([]byte, error) {
+	from := opts.SourceFormat
+	to := sdktranslator.FromString("bedrock")
+	originalPayload := bytes.Clone(req.Payload)
+	if len(opts.OriginalRequest) > 0 {
+		originalPayload = bytes.Clone(opts.OriginalRequest)
+	}
+	originalTranslated := sdktranslator.TranslateRequest(from, to, baseModel, originalPayload, stream)
+	body := sdktranslator.TranslateRequest(from, to, baseModel, bytes.Clone(req.Payload), stream)
+
+	body, err := applyBedrockThinking(body, req.Model, e.Identifier())
+	if err != nil {
+		return nil, err
+	}
+
+	requestedModel := payloadRequestedModel(opts, req.Model)
+	body = applyPayloadConfigWithRoot(e.cfg, baseModel, to.String(), "", body, originalTranslated, requestedModel)
+	return body, nil
+}
+
+// send signs and posts a Converse request, returning the response on success and a
+// statusErr carrying the upstream error body otherwise.
+func (e *BedrockExecutor) send(ctx context.Context, auth *cliproxyauth.Auth, model, action string, body []byte, stream bool) (*http.Response, error) {
+	creds, region, baseURL, err := bedrockCreds(auth)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/model/%s/%s", baseURL, bedrockauth.EscapePathSegment(model), action)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if stream {
+		httpReq.Header.Set("Accept", "application/vnd.amazon.eventstream")
+	} else {
+		httpReq.Header.Set("Accept", "application/json")
+	}
+	if err = bedrockauth.SignRequest(httpReq, body, creds, region, bedrockauth.ServiceName, time.Now()); err != nil {
+		return nil, err
+	}
+	var authID, authLabel, authType, authValue string
+	if auth != nil {
+		authID = auth.ID
+		authLabel = auth.Label
+		authType, authValue = auth.AccountInfo()
+	}
+	recordAPIRequest(ctx, e.cfg, upstreamRequestLog{
+		URL:       url,
+		Method:    http.MethodPost,
+		Headers:   httpReq.Header.Clone(),
+		Body:      body,
+		Provider:  e.Identifier(),
+		AuthID:    authID,
+		AuthLabel: authLabel,
+		AuthType:  authType,
+		AuthValue: authValue,
+	})
+
+	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		recordAPIResponseError(ctx, e.cfg, err)
+		return nil, err
+	}
+	recordAPIResponseMetadata(ctx, e.cfg, httpResp.StatusCode, httpResp.Header.Clone())
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		b, _ := io.ReadAll(httpResp.Body)
+		appendAPIResponseChunk(ctx, e.cfg, b)
+		logWithRequestID(ctx).Debugf("request error, error status: %d, error message: %s", httpResp.StatusCode, summarizeErrorBody(httpResp.Header.Get("Content-Type"), b))
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("response body close error: %v", errClose)
+		}
+		return nil, statusErr{code: httpResp.StatusCode, msg: string(b)}
+	}
+	return httpResp, nil
+}
+
+// bedrockCreds resolves the signing credentials, region and endpoint of an auth.
+// Profile-based credentials are read from the shared credentials file on every call.
+func bedrockCreds(a *cliproxyauth.Auth) (bedrockauth.Credentials, string, string, error) {
+	if a == nil || a.Attributes == nil {
+		return bedrockauth.Credentials{}, "", "", fmt.Errorf("bedrock executor: missing credentials")
+	}
+	region := strings.TrimSpace(a.Attributes["region"])
+	if region == "" {
+		region = config.DefaultBedrockRegion
+	}
+	baseURL := strings.TrimRight(strings.TrimSpace(a.Attributes["base_url"]), "/")
+	if baseURL == "" {
+		baseURL = "https://bedrock-runtime." + region + ".amazonaws.com"
+	}
+	creds := bedrockauth.Credentials{
+		AccessKeyID:     strings.TrimSpace(a.Attributes["access_key_id"]),
+		SecretAccessKey: strings.TrimSpace(a.Attributes["secret_access_key"]),
+		SessionToken:    strings.TrimSpace(a.Attributes["session_token"]),
+	}
+	if !creds.Valid() {
+		profile := strings.TrimSpace(a.Attributes["profile"])
+		if profile == "" {
+			return creds, region, baseURL, fmt.Errorf("bedrock executor: missing credentials")
+		}
+		var err error
+		if creds, err = bedrockauth.LoadProfileCredentials(profile); err != nil {
+			return creds, region, baseURL, err
+		}
+	}
+	return creds, region, baseURL, nil
+}
+
+// applyBedrockThinking applies the thinking configuration of the model suffix or of the
+// request to the Claude thinking fields Converse passes through. Validation runs on a
+// Claude-shaped view of the request so max_tokens stays above the thinking budget.
+func applyBedrockThinking(body []byte, model, provider string) ([]byte, error) {
+	view := []byte(`{}`)
+	if v := gjson.GetBytes(body, "additionalModelRequestFields.thinking"); v.Exists() {
+		view, _ = sjson.SetRawBytes(view, "thinking", []byte(v.Raw))
+	}
+	if v := gjson.GetBytes(body, "inferenceConfig.maxTokens"); v.Exists() {
+		view, _ = sjson.SetBytes(view, "max_tokens", v.Int())
+	}
+	view, err := thinking.ApplyThinking(view, model, "claude", "claude", provider)
+	if err != nil {
+		return body, err
+	}
+	if v := gjson.GetBytes(view, "thinking"); v.Exists() {
+		body, _ = sjson.SetRawBytes(body, "additionalModelRequestFields.thinking", []byte(v.Raw))
+	} else {
+		body, _ = sjson.DeleteBytes(body, "additionalModelRequestFields.thinking")
+	}
+	if v := gjson.GetBytes(view, "max_tokens"); v.Exists() {
+		body, _ = sjson.SetBytes(body, "inferenceConfig.maxTokens", v.Int())
+	}
+	if fields := gjson.GetBytes(body, "additionalModelRequestFields"); fields.Exists() && fields.Raw == "{}" {
+		body, _ = sjson.DeleteBytes(body, "additionalModelRequestFields")
+	}
+	return body, nil
+}
+
+// bedrockStreamError converts an exception frame of a Converse stream into a statusErr
+// with the status code AWS uses for the exception.
+func bedrockStreamError(msg *eventStreamMessage) error {
+	code := http.StatusInternalServerError
+	switch msg.ExceptionType {
+	case "throttlingException":
+		code = http.StatusTooManyRequests
+	case "validationException":
+		code = http.StatusBadRequest
+	case "serviceUnavailableException":
+		code = http.StatusServiceUnavailable
+	case "modelStreamErrorException":
+		code = http.StatusFailedDependency
+	case "modelTimeoutException":
+		code = http.StatusRequestTimeout
+	}
+	message := gjson.GetBytes(msg.Payload, "message").String()
+	if message == "" {
+		message = string(msg.Payload)
+	}
+	return statusErr{code: code, msg: fmt.Sprintf("bedrock %s: %s", msg.ExceptionType, message)}
+}
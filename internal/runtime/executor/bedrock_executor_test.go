@@ -0,0 +1,207 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+const bedrockTestModel = "anthropic.claude-sonnet-4-20250514-v1:0"
+
+// encodeEventStreamFrame builds an AWS event stream frame with string headers.
+func encodeEventStreamFrame(headers [][2]string, payload []byte) []byte {
+	var headerBytes bytes.Buffer
+	for _, header := range headers {
+		headerBytes.WriteByte(byte(len(header[0])))
+		headerBytes.WriteString(header[0])
+		headerBytes.WriteByte(7)
+		_ = binary.Write(&headerBytes, binary.BigEndian, uint16(len(header[1])))
+		headerBytes.WriteString(header[1])
+	}
+	total := 12 + headerBytes.Len() + len(payload) + 4
+	frame := make([]byte, 0, total)
+	frame = binary.BigEndian.AppendUint32(frame, uint32(total))
+	frame = binary.BigEndian.AppendUint32(frame, uint32(headerBytes.Len()))
+	frame = binary.BigEndian.AppendUint32(frame, crc32.ChecksumIEEE(frame))
+	frame = append(frame, headerBytes.Bytes()...)
+	frame = append(frame, payload...)
+	return binary.BigEndian.AppendUint32(frame, crc32.ChecksumIEEE(frame))
+}
+
+func bedrockEvent(eventType, payload string) []byte {
+	return encodeEventStreamFrame([][2]string{
+		{":event-type", eventType},
+		{":content-type", "application/json"},
+		{":message-type", "event"},
+	}, []byte(payload))
+}
+
+func newBedrockTestAuth(baseURL string) *cliproxyauth.Auth {
+	return &cliproxyauth.Auth{ID: "bedrock-test", Provider: "bedrock", Attributes: map[string]string{
+		"api_key":           "AKIDTEST",
+		"access_key_id":     "AKIDTEST",
+		"secret_access_key": "secret",
+		"session_token":     "session",
+		"region":            "us-west-2",
+		"base_url":          baseURL,
+	}}
+}
+
+func TestReadEventStreamMessage(t *testing.T) {
+	frame := encodeEventStreamFrame([][2]string{
+		{":message-type", "exception"},
+		{":exception-type", "throttlingException"},
+	}, []byte(`{"message":"slow down"}`))
+	msg, err := readEventStreamMessage(bufio.NewReader(bytes.NewReader(frame)))
+	if err != nil {
+		t.Fatalf("readEventStreamMessage: %v", err)
+	}
+	if msg.MessageType != "exception" || msg.ExceptionType != "throttlingException" || string(msg.Payload) != `{"message":"slow down"}` {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+}
+
+func TestBedrockExecutorStreamToClaude(t *testing.T) {
+	var upstream []byte
+	var path, authorization, token string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, authorization, token = r.URL.EscapedPath(), r.Header.Get("Authorization"), r.Header.Get("X-Amz-Security-Token")
+		upstream, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+		for _, frame := range [][]byte{
+			bedrockEvent("messageStart", `{"role":"assistant"}`),
+			bedrockEvent("contentBlockDelta", `{"contentBlockIndex":0,"delta":{"text":"Hel"}}`),
+			bedrockEvent("contentBlockDelta", `{"contentBlockIndex":0,"delta":{"text":"lo"}}`),
+			bedrockEvent("contentBlockStop", `{"contentBlockIndex":0}`),
+			bedrockEvent("contentBlockStart", `{"contentBlockIndex":1,"start":{"toolUse":{"toolUseId":"tu1","name":"lookup"}}}`),
+			bedrockEvent("contentBlockDelta", `{"contentBlockIndex":1,"delta":{"toolUse":{"input":"{\"q\":1}"}}}`),
+			bedrockEvent("contentBlockStop", `{"contentBlockIndex":1}`),
+			bedrockEvent("messageStop", `{"stopReason":"tool_use"}`),
+			bedrockEvent("metadata", `{"usage":{"inputTokens":12,"outputTokens":5,"totalTokens":17},"metrics":{"latencyMs":100}}`),
+		} {
+			_, _ = w.Write(frame)
+		}
+	}))
+	defer server.Close()
+
+	exec := NewBedrockExecutor(&config.Config{})
+	payload := []byte(`{"model":"claude","max_tokens":256,"stream":true,"system":"be brief","tools":[{"name":"lookup","input_schema":{"type":"object"}}],"messages":[{"role":"user","content":"hi"}]}`)
+	opts := cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("claude"), OriginalRequest: payload, Stream: true}
+	stream, err := exec.ExecuteStream(context.Background(), newBedrockTestAuth(server.URL), cliproxyexecutor.Request{Model: bedrockTestModel, Payload: payload}, opts)
+	if err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	var out strings.Builder
+	for chunk := range stream {
+		if chunk.Err != nil {
+			t.Fatalf("stream error: %v", chunk.Err)
+		}
+		out.Write(chunk.Payload)
+	}
+
+	if path != "/model/anthropic.claude-sonnet-4-20250514-v1%3A0/converse-stream" {
+		t.Fatalf("upstream path = %s", path)
+	}
+	if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKIDTEST/") || token != "session" {
+		t.Fatalf("request not signed: authorization=%q token=%q", authorization, token)
+	}
+	if gjson.GetBytes(upstream, "system.0.text").String() != "be brief" ||
+		gjson.GetBytes(upstream, "messages.0.content.0.text").String() != "hi" ||
+		gjson.GetBytes(upstream, "inferenceConfig.maxTokens").Int() != 256 ||
+		gjson.GetBytes(upstream, "toolConfig.tools.0.toolSpec.name").String() != "lookup" {
+		t.Fatalf("unexpected converse request: %s", upstream)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"event: message_start",
+		`"text_delta","text":"Hel"`,
+		`"type":"tool_use","id":"tu1","name":"lookup"`,
+		`"partial_json":"{\"q\":1}"`,
+		`"stop_reason":"tool_use"`,
+		`"output_tokens":5`,
+		"event: message_stop",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("claude stream missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestBedrockExecutorExecuteToOpenAI(t *testing.T) {
+	var upstream []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstream, _ = io.ReadAll(r.Body)
+		_, _ = io.WriteString(w, `{"output":{"message":{"role":"assistant","content":[{"text":"Checking."},{"toolUse":{"toolUseId":"tu1","name":"get_weather","input":{"city":"Paris"}}}]}},"stopReason":"tool_use","usage":{"inputTokens":20,"outputTokens":8,"totalTokens":28,"cacheReadInputTokens":4}}`)
+	}))
+	defer server.Close()
+
+	exec := NewBedrockExecutor(&config.Config{})
+	payload := []byte(`{"model":"claude","messages":[{"role":"system","content":"sys"},{"role":"user","content":"weather?"},{"role":"assistant","content":null,"tool_calls":[{"id":"c0","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Rome\"}"}}]},{"role":"tool","tool_call_id":"c0","content":"sunny"},{"role":"user","content":"and Paris?"}],"tools":[{"type":"function","function":{"name":"get_weather","parameters":{"type":"object"}}}],"tool_choice":"required"}`)
+	opts := cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("openai"), OriginalRequest: payload}
+	resp, err := exec.Execute(context.Background(), newBedrockTestAuth(server.URL), cliproxyexecutor.Request{Model: bedrockTestModel, Payload: payload}, opts)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	messages := gjson.GetBytes(upstream, "messages").Array()
+	if len(messages) != 3 || messages[2].Get("role").String() != "user" ||
+		messages[2].Get("content.0.toolResult.toolUseId").String() != "c0" ||
+		messages[2].Get("content.1.text").String() != "and Paris?" ||
+		messages[1].Get("content.0.toolUse.input.city").String() != "Rome" {
+		t.Fatalf("unexpected converse messages: %s", gjson.GetBytes(upstream, "messages").Raw)
+	}
+	if !gjson.GetBytes(upstream, "toolConfig.toolChoice.any").Exists() {
+		t.Fatalf("tool choice not mapped: %s", upstream)
+	}
+	out := gjson.ParseBytes(resp.Payload)
+	if out.Get("choices.0.finish_reason").String() != "tool_calls" ||
+		out.Get("choices.0.message.content").String() != "Checking." ||
+		out.Get("choices.0.message.tool_calls.0.function.arguments").String() != `{"city":"Paris"}` ||
+		out.Get("usage.prompt_tokens").Int() != 24 {
+		t.Fatalf("openai response = %s", resp.Payload)
+	}
+}
+
+func TestBedrockExecutorStreamException(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(bedrockEvent("messageStart", `{"role":"assistant"}`))
+		_, _ = w.Write(encodeEventStreamFrame([][2]string{
+			{":message-type", "exception"},
+			{":exception-type", "throttlingException"},
+		}, []byte(`{"message":"Too many requests"}`)))
+	}))
+	defer server.Close()
+
+	exec := NewBedrockExecutor(&config.Config{})
+	payload := []byte(`{"messages":[{"role":"user","content":"hi"}],"stream":true}`)
+	opts := cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("openai"), OriginalRequest: payload, Stream: true}
+	stream, err := exec.ExecuteStream(context.Background(), newBedrockTestAuth(server.URL), cliproxyexecutor.Request{Model: bedrockTestModel, Payload: payload}, opts)
+	if err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	var streamErr error
+	for chunk := range stream {
+		if chunk.Err != nil {
+			streamErr = chunk.Err
+		}
+	}
+	var status interface{ StatusCode() int }
+	if !errors.As(streamErr, &status) || status.StatusCode() != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 stream error, got %v", streamErr)
+	}
+}
@@ -1,8 +1,13 @@
 package executor
 
 import (
+	"bytes"
 	"sync"
 	"time"
+
+	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/tidwall/gjson"
 )
 
 type codexCache struct {
@@ -76,3 +81,55 @@ func deleteCodexCache(key string) {
 	delete(codexCacheMap, key)
 	codexCacheMu.Unlock()
 }
+
+// claudePromptCacheKey derives a prompt_cache_key from the first cache_control breakpoint of a
+// Claude request. The key hashes the model and everything up to that breakpoint in Claude's
+// cache order (tools, system, messages), so it stays stable while later turns are appended.
+// It returns "" when the request has no breakpoint or the bridge is not enabled.
+func claudePromptCacheKey(cfg *config.Config, model string, payload []byte) string {
+	if cfg == nil || !cfg.PromptCache.Enable {
+		return ""
+	}
+	var prefix bytes.Buffer
+	prefix.WriteString(model)
+	found := false
+	take := func(block gjson.Result) bool {
+		prefix.WriteByte('\n')
+		prefix.WriteString(block.Raw)
+		found = block.Get("cache_control").IsObject()
+		return !found
+	}
+
+	gjson.GetBytes(payload, "tools").ForEach(func(_, tool gjson.Result) bool {
+		return take(tool)
+	})
+	if !found {
+		system := gjson.GetBytes(payload, "system")
+		if system.IsArray() {
+			system.ForEach(func(_, block gjson.Result) bool {
+				return take(block)
+			})
+		} else if system.Exists() {
+			take(system)
+		}
+	}
+	if !found {
+		gjson.GetBytes(payload, "messages").ForEach(func(_, message gjson.Result) bool {
+			prefix.WriteByte('\n')
+			prefix.WriteString(message.Get("role").String())
+			content := message.Get("content")
+			if !content.IsArray() {
+				take(content)
+				return true
+			}
+			content.ForEach(func(_, block gjson.Result) bool {
+				return take(block)
+			})
+			return !found
+		})
+	}
+	if !found {
+		return ""
+	}
+	return uuid.NewSHA1(uuid.NameSpaceOID, prefix.Bytes()).String()
+}
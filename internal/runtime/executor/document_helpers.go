@@ -0,0 +1,105 @@
+package executor
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/pdftext"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// defaultDocumentMaxChars caps extracted text per document when documents.max-chars is unset.
+const defaultDocumentMaxChars = 200000
+
+// applyDocumentFallback rewrites document attachments for providers that cannot read them.
+// It handles Claude document blocks and OpenAI file parts in messages[].content, and
+// Responses input_file parts in input[].content, so it works on Claude-, OpenAI- and
+// Responses-shaped payloads. Depending on documents.fallback, each document is replaced by
+// a text part holding its extracted text, or the request is rejected with 400.
+func applyDocumentFallback(cfg *config.Config, body []byte) ([]byte, error) {
+	reject := false
+	maxChars := defaultDocumentMaxChars
+	var maxDecodedBytes int64
+	if cfg != nil {
+		reject = strings.EqualFold(strings.TrimSpace(cfg.Documents.Fallback), "reject")
+		if cfg.Documents.MaxChars > 0 {
+			maxChars = cfg.Documents.MaxChars
+		}
+		maxDecodedBytes = cfg.Documents.MaxDecodedBytes
+	}
+
+	replace := func(path string, doc util.Document, textType string) error {
+		label := doc.Filename
+		if label == "" {
+			label = doc.MimeType
+		}
+		if reject {
+			return statusErr{code: http.StatusBadRequest, msg: fmt.Sprintf("document %q is not supported by this model", label)}
+		}
+		text, err := extractDocumentText(doc, maxDecodedBytes)
+		if err != nil {
+			return statusErr{code: http.StatusBadRequest, msg: fmt.Sprintf("document %q could not be converted to text: %v", label, err)}
+		}
+		if runes := []rune(text); len(runes) > maxChars {
+			text = string(runes[:maxChars]) + "\n[truncated]"
+		}
+		replacement := `{"type":"","text":""}`
+		replacement, _ = sjson.Set(replacement, "type", textType)
+		replacement, _ = sjson.Set(replacement, "text", fmt.Sprintf("[Document: %s]\n%s", label, text))
+		body, _ = sjson.SetRawBytes(body, path, []byte(replacement))
+		return nil
+	}
+
+	for i, message := range gjson.GetBytes(body, "messages").Array() {
+		for j, part := range message.Get("content").Array() {
+			doc, ok := util.ClaudeDocument(part)
+			if !ok {
+				doc, ok = util.OpenAIFileDocument(part)
+			}
+			if !ok {
+				continue
+			}
+			if err := replace(fmt.Sprintf("messages.%d.content.%d", i, j), doc, "text"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for i, item := range gjson.GetBytes(body, "input").Array() {
+		for j, part := range item.Get("content").Array() {
+			doc, ok := util.ResponsesFileDocument(part)
+			if !ok {
+				continue
+			}
+			if err := replace(fmt.Sprintf("input.%d.content.%d", i, j), doc, "input_text"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return body, nil
+}
+
+// extractDocumentText returns the text of an inline document, decoding at most
+// maxDecodedBytes of PDF stream data. Remote URLs and Files API references cannot be read
+// locally.
+func extractDocumentText(doc util.Document, maxDecodedBytes int64) (string, error) {
+	if text, ok := doc.PlainText(); ok {
+		return text, nil
+	}
+	if doc.Data == "" {
+		return "", errors.New("only inline documents can be extracted")
+	}
+	if !strings.EqualFold(doc.MimeType, "application/pdf") {
+		return "", fmt.Errorf("unsupported document type %s", doc.MimeType)
+	}
+	data, err := base64.StdEncoding.DecodeString(doc.Data)
+	if err != nil {
+		return "", fmt.Errorf("invalid base64 data: %w", err)
+	}
+	return pdftext.ExtractLimit(data, maxDecodedBytes)
+}
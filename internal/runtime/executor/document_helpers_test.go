@@ -0,0 +1,79 @@
+package executor
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const samplePDF = "%PDF-1.4\n" +
+	"1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n" +
+	"2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj\n" +
+	"3 0 obj\n<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>\nendobj\n" +
+	"4 0 obj\n<< /Length 37 >>\nstream\nBT /F1 12 Tf (Invoice total 42) Tj ET\nendstream\nendobj\n" +
+	"trailer\n<< /Root 1 0 R >>\n%%EOF\n"
+
+func TestApplyDocumentFallbackExtractsText(t *testing.T) {
+	body := []byte(`{"messages":[{"role":"user","content":[{"type":"text","text":"What is the total?"},{"type":"document","title":"invoice.pdf","source":{"type":"base64","media_type":"application/pdf","data":""}}]}]}`)
+	body, _ = sjson.SetBytes(body, "messages.0.content.1.source.data", base64.StdEncoding.EncodeToString([]byte(samplePDF)))
+
+	out, err := applyDocumentFallback(&config.Config{}, body)
+	if err != nil {
+		t.Fatalf("applyDocumentFallback() error = %v", err)
+	}
+	part := gjson.GetBytes(out, "messages.0.content.1")
+	if part.Get("type").String() != "text" {
+		t.Fatalf("document part = %s, want text", part.Raw)
+	}
+	if got := part.Get("text").String(); !strings.Contains(got, "invoice.pdf") || !strings.Contains(got, "Invoice total 42") {
+		t.Fatalf("extracted text = %q", got)
+	}
+
+	openaiBody := []byte(`{"messages":[{"role":"user","content":[{"type":"file","file":{"filename":"a.txt","file_data":"data:text/plain;base64,aGVsbG8gd29ybGQ="}}]}]}`)
+	out, err = applyDocumentFallback(&config.Config{Documents: config.DocumentsConfig{MaxChars: 5}}, openaiBody)
+	if err != nil {
+		t.Fatalf("applyDocumentFallback() openai error = %v", err)
+	}
+	if got := gjson.GetBytes(out, "messages.0.content.0.text").String(); got != "[Document: a.txt]\nhello\n[truncated]" {
+		t.Fatalf("truncated text = %q", got)
+	}
+
+	responsesBody := []byte(`{"input":[{"type":"message","role":"user","content":[{"type":"input_text","text":"Summarize"},{"type":"input_file","filename":"invoice.pdf","file_data":""}]}]}`)
+	responsesBody, _ = sjson.SetBytes(responsesBody, "input.0.content.1.file_data", "data:application/pdf;base64,"+base64.StdEncoding.EncodeToString([]byte(samplePDF)))
+	out, err = applyDocumentFallback(&config.Config{}, responsesBody)
+	if err != nil {
+		t.Fatalf("applyDocumentFallback() responses error = %v", err)
+	}
+	part = gjson.GetBytes(out, "input.0.content.1")
+	if part.Get("type").String() != "input_text" || !strings.Contains(part.Get("text").String(), "Invoice total 42") {
+		t.Fatalf("responses input_file part = %s", part.Raw)
+	}
+}
+
+func TestApplyDocumentFallbackRejects(t *testing.T) {
+	body := []byte(`{"messages":[{"role":"user","content":[{"type":"document","source":{"type":"url","url":"https://example.com/a.pdf"}}]}]}`)
+
+	// Remote documents cannot be extracted locally.
+	_, err := applyDocumentFallback(&config.Config{}, body)
+	var se statusErr
+	if !errors.As(err, &se) || se.code != http.StatusBadRequest {
+		t.Fatalf("extract error = %v, want 400", err)
+	}
+
+	cfg := &config.Config{Documents: config.DocumentsConfig{Fallback: "reject"}}
+	_, err = applyDocumentFallback(cfg, []byte(`{"messages":[{"role":"user","content":[{"type":"document","source":{"type":"text","data":"x"}}]}]}`))
+	if !errors.As(err, &se) || se.code != http.StatusBadRequest {
+		t.Fatalf("reject error = %v, want 400", err)
+	}
+
+	plain := []byte(`{"messages":[{"role":"user","content":"hi"}]}`)
+	if out, errPlain := applyDocumentFallback(cfg, plain); errPlain != nil || string(out) != string(plain) {
+		t.Fatalf("payload without documents changed: %s, %v", out, errPlain)
+	}
+}
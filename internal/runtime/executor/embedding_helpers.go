@@ -0,0 +1,228 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tokencount"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Embedding actions carried in Request.Metadata["action"] by the embeddings handlers.
+const (
+	embeddingActionEmbed  = "embedContent"
+	embeddingActionBatch  = "batchEmbedContents"
+	embeddingActionOpenAI = "embeddings"
+)
+
+// embeddingAction returns the embedding action requested for req, or "" when the
+// request is not an embedding request.
+func embeddingAction(req cliproxyexecutor.Request) string {
+	if req.Metadata == nil {
+		return ""
+	}
+	action, _ := req.Metadata["action"].(string)
+	switch action {
+	case embeddingActionEmbed, embeddingActionBatch, embeddingActionOpenAI:
+		return action
+	}
+	return ""
+}
+
+// buildGeminiEmbeddingBody prepares a Gemini embedding request. Native Gemini payloads are
+// forwarded with the upstream model filled in; other formats are translated into a
+// batchEmbedContents request. It returns the body and the Gemini action to call.
+func buildGeminiEmbeddingBody(from sdktranslator.Format, baseModel, action string, payload []byte) ([]byte, string, error) {
+	modelPath := "models/" + baseModel
+	if from == sdktranslator.FromString("gemini") {
+		body := bytes.Clone(payload)
+		if action == embeddingActionBatch {
+			for i := range gjson.GetBytes(body, "requests").Array() {
+				body, _ = sjson.SetBytes(body, fmt.Sprintf("requests.%d.model", i), modelPath)
+			}
+			return body, embeddingActionBatch, nil
+		}
+		body, _ = sjson.SetBytes(body, "model", modelPath)
+		return body, embeddingActionEmbed, nil
+	}
+
+	body := sdktranslator.TranslateRequest(from, sdktranslator.FromString("gemini"), baseModel, bytes.Clone(payload), false)
+	if len(gjson.GetBytes(body, "requests").Array()) == 0 {
+		return nil, "", statusErr{code: http.StatusBadRequest, msg: "embedding input must be a string or an array of strings"}
+	}
+	return body, embeddingActionBatch, nil
+}
+
+// geminiEmbeddingRequests returns the individual embed requests of an embedContent or
+// batchEmbedContents body.
+func geminiEmbeddingRequests(body []byte, action string) []gjson.Result {
+	if action == embeddingActionBatch {
+		return gjson.GetBytes(body, "requests").Array()
+	}
+	return []gjson.Result{gjson.ParseBytes(body)}
+}
+
+// geminiEmbeddingText joins the text parts of a single embed request.
+func geminiEmbeddingText(request gjson.Result) string {
+	var parts []string
+	request.Get("content.parts").ForEach(func(_, part gjson.Result) bool {
+		if text := part.Get("text"); text.Exists() {
+			parts = append(parts, text.String())
+		}
+		return true
+	})
+	return strings.Join(parts, "\n")
+}
+
+// convertGeminiEmbeddingToVertexPredict converts a Gemini embedding body into the Vertex AI
+// text-embedding predict format.
+func convertGeminiEmbeddingToVertexPredict(body []byte, action string) []byte {
+	out := []byte(`{"instances":[]}`)
+	for _, request := range geminiEmbeddingRequests(body, action) {
+		instance := []byte(`{"content":""}`)
+		instance, _ = sjson.SetBytes(instance, "content", geminiEmbeddingText(request))
+		if taskType := request.Get("taskType"); taskType.Exists() {
+			instance, _ = sjson.SetBytes(instance, "task_type", taskType.String())
+		}
+		if title := request.Get("title"); title.Exists() {
+			instance, _ = sjson.SetBytes(instance, "title", title.String())
+		}
+		out, _ = sjson.SetRawBytes(out, "instances.-1", instance)
+		if dims := request.Get("outputDimensionality"); dims.Exists() && !gjson.GetBytes(out, "parameters.outputDimensionality").Exists() {
+			out, _ = sjson.SetBytes(out, "parameters.outputDimensionality", dims.Int())
+		}
+	}
+	return out
+}
+
+// convertVertexPredictToGeminiEmbedding converts a Vertex AI predict response back into the
+// Gemini embedContent or batchEmbedContents response shape, carrying the token statistics
+// as usageMetadata.
+func convertVertexPredictToGeminiEmbedding(data []byte, action string) []byte {
+	out := []byte(`{"embeddings":[]}`)
+	var promptTokens int64
+	for _, prediction := range gjson.GetBytes(data, "predictions").Array() {
+		embedding := []byte(`{"values":[]}`)
+		if values := prediction.Get("embeddings.values"); values.IsArray() {
+			embedding, _ = sjson.SetRawBytes(embedding, "values", []byte(values.Raw))
+		}
+		out, _ = sjson.SetRawBytes(out, "embeddings.-1", embedding)
+		promptTokens += prediction.Get("embeddings.statistics.token_count").Int()
+	}
+	if action == embeddingActionEmbed {
+		single := []byte(`{}`)
+		single, _ = sjson.SetRawBytes(single, "embedding", []byte(gjson.GetBytes(out, "embeddings.0").Raw))
+		out = single
+	}
+	if promptTokens > 0 {
+		out, _ = sjson.SetBytes(out, "usageMetadata.promptTokenCount", promptTokens)
+		out, _ = sjson.SetBytes(out, "usageMetadata.totalTokenCount", promptTokens)
+	}
+	return out
+}
+
+// ensureGeminiEmbeddingUsage fills usageMetadata with a local token estimate of the inputs
+// when the upstream response does not report one, so usage records count input tokens.
+func ensureGeminiEmbeddingUsage(data, body []byte, action, model string) []byte {
+	if gjson.GetBytes(data, "usageMetadata.promptTokenCount").Exists() {
+		return data
+	}
+	enc, err := tokencount.Get(model)
+	if err != nil {
+		return data
+	}
+	var total int64
+	for _, request := range geminiEmbeddingRequests(body, action) {
+		count, errCount := enc.Count(geminiEmbeddingText(request))
+		if errCount != nil {
+			continue
+		}
+		total += int64(count)
+	}
+	data, _ = sjson.SetBytes(data, "usageMetadata.promptTokenCount", total)
+	data, _ = sjson.SetBytes(data, "usageMetadata.totalTokenCount", total)
+	return data
+}
+
+// finishGeminiEmbedding publishes usage for a Gemini-format embedding response and
+// converts it back to the caller's format.
+func finishGeminiEmbedding(ctx context.Context, reporter *usageReporter, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, baseModel, action string, body, data []byte) cliproxyexecutor.Response {
+	withUsage := ensureGeminiEmbeddingUsage(data, body, action, baseModel)
+	reporter.publish(ctx, parseGeminiUsage(withUsage))
+	from := opts.SourceFormat
+	to := sdktranslator.FromString("gemini")
+	if from == to {
+		return cliproxyexecutor.Response{Payload: data}
+	}
+	var param any
+	out := sdktranslator.TranslateNonStream(ctx, to, from, req.Model, bytes.Clone(opts.OriginalRequest), body, withUsage, &param)
+	return cliproxyexecutor.Response{Payload: []byte(out)}
+}
+
+// doJSONPost posts a JSON body upstream with request logging and returns the raw response
+// body. prepare sets the provider-specific authentication headers.
+func doJSONPost(ctx context.Context, cfg *config.Config, auth *cliproxyauth.Auth, provider, url string, body []byte, prepare func(*http.Request) error) ([]byte, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if prepare != nil {
+		if err = prepare(httpReq); err != nil {
+			return nil, err
+		}
+	}
+	var authID, authLabel, authType, authValue string
+	if auth != nil {
+		authID = auth.ID
+		authLabel = auth.Label
+		authType, authValue = auth.AccountInfo()
+	}
+	recordAPIRequest(ctx, cfg, upstreamRequestLog{
+		URL:       url,
+		Method:    http.MethodPost,
+		Headers:   httpReq.Header.Clone(),
+		Body:      body,
+		Provider:  provider,
+		AuthID:    authID,
+		AuthLabel: authLabel,
+		AuthType:  authType,
+		AuthValue: authValue,
+	})
+
+	httpClient := newProxyAwareHTTPClient(ctx, cfg, auth, 0)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		recordAPIResponseError(ctx, cfg, err)
+		return nil, err
+	}
+	defer func() {
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("%s executor: close response body error: %v", provider, errClose)
+		}
+	}()
+	recordAPIResponseMetadata(ctx, cfg, httpResp.StatusCode, httpResp.Header.Clone())
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		b, _ := io.ReadAll(httpResp.Body)
+		appendAPIResponseChunk(ctx, cfg, b)
+		logWithRequestID(ctx).Debugf("request error, error status: %d, error message: %s", httpResp.StatusCode, summarizeErrorBody(httpResp.Header.Get("Content-Type"), b))
+		return nil, statusErr{code: httpResp.StatusCode, msg: string(b)}
+	}
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		recordAPIResponseError(ctx, cfg, err)
+		return nil, err
+	}
+	appendAPIResponseChunk(ctx, cfg, data)
+	return data, nil
+}
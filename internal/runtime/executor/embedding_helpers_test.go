@@ -0,0 +1,65 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertGeminiEmbeddingToVertexPredict(t *testing.T) {
+	body := []byte(`{"requests":[
+		{"model":"models/text-embedding-004","content":{"parts":[{"text":"alpha"}]},"taskType":"RETRIEVAL_QUERY","outputDimensionality":128},
+		{"model":"models/text-embedding-004","content":{"parts":[{"text":"beta"},{"text":"gamma"}]},"title":"doc"}
+	]}`)
+	out := convertGeminiEmbeddingToVertexPredict(body, embeddingActionBatch)
+
+	instances := gjson.GetBytes(out, "instances").Array()
+	if len(instances) != 2 {
+		t.Fatalf("instances = %d, want 2: %s", len(instances), out)
+	}
+	if got := instances[0].Get("task_type").String(); got != "RETRIEVAL_QUERY" {
+		t.Fatalf("task_type = %q", got)
+	}
+	if got := instances[1].Get("content").String(); got != "beta\ngamma" {
+		t.Fatalf("content = %q", got)
+	}
+	if got := instances[1].Get("title").String(); got != "doc" {
+		t.Fatalf("title = %q", got)
+	}
+	if got := gjson.GetBytes(out, "parameters.outputDimensionality").Int(); got != 128 {
+		t.Fatalf("outputDimensionality = %d, want 128", got)
+	}
+}
+
+func TestConvertVertexPredictToGeminiEmbedding(t *testing.T) {
+	data := []byte(`{"predictions":[
+		{"embeddings":{"values":[0.1,0.2],"statistics":{"token_count":3}}},
+		{"embeddings":{"values":[0.3],"statistics":{"token_count":4}}}
+	]}`)
+
+	batch := convertVertexPredictToGeminiEmbedding(data, embeddingActionBatch)
+	if got := gjson.GetBytes(batch, "embeddings.#").Int(); got != 2 {
+		t.Fatalf("embeddings = %d, want 2: %s", got, batch)
+	}
+	if got := gjson.GetBytes(batch, "usageMetadata.promptTokenCount").Int(); got != 7 {
+		t.Fatalf("promptTokenCount = %d, want 7", got)
+	}
+
+	single := convertVertexPredictToGeminiEmbedding(data, embeddingActionEmbed)
+	if got := gjson.GetBytes(single, "embedding.values.1").Float(); got != 0.2 {
+		t.Fatalf("embedding.values[1] = %v, want 0.2: %s", got, single)
+	}
+}
+
+func TestEnsureGeminiEmbeddingUsageEstimatesInputTokens(t *testing.T) {
+	body := []byte(`{"content":{"parts":[{"text":"hello world"}]}}`)
+	data := ensureGeminiEmbeddingUsage([]byte(`{"embedding":{"values":[1]}}`), body, embeddingActionEmbed, "gemini-embedding-001")
+	if got := gjson.GetBytes(data, "usageMetadata.promptTokenCount").Int(); got <= 0 {
+		t.Fatalf("expected estimated prompt tokens, got %d", got)
+	}
+
+	reported := []byte(`{"embedding":{"values":[1]},"usageMetadata":{"promptTokenCount":42}}`)
+	if got := gjson.GetBytes(ensureGeminiEmbeddingUsage(reported, body, embeddingActionEmbed, "gemini-embedding-001"), "usageMetadata.promptTokenCount").Int(); got != 42 {
+		t.Fatalf("reported usage overwritten: %d", got)
+	}
+}
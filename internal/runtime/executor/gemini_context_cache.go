@@ -0,0 +1,326 @@
+package executor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tokencount"
+	geminiclaude "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/claude"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// geminiContextCache records a cachedContents resource created for a prompt prefix.
+// Failed entries remember prefixes the upstream refused so they are not retried until
+// they expire.
+type geminiContextCache struct {
+	Name   string
+	Expire time.Time
+	Failed bool
+}
+
+// geminiContextCacheMap stores cachedContents names keyed by credential+model+prefix hash.
+// Protected by geminiContextCacheMu.
+var (
+	geminiContextCacheMap = make(map[string]geminiContextCache)
+	geminiContextCacheMu  sync.RWMutex
+)
+
+const (
+	// geminiContextCacheDefaultTTL is used when neither the breakpoint nor the config sets a TTL.
+	geminiContextCacheDefaultTTL = 5 * time.Minute
+	// geminiContextCacheLongTTL matches Claude's "1h" cache_control TTL.
+	geminiContextCacheLongTTL = time.Hour
+	// geminiContextCacheExpiryMargin drops local entries shortly before the upstream does.
+	geminiContextCacheExpiryMargin = 15 * time.Second
+	// geminiContextCacheMinTokens is Gemini's minimum cachedContents size for Flash models;
+	// geminiContextCacheMinTokensPro applies to Pro models.
+	geminiContextCacheMinTokens    = 1024
+	geminiContextCacheMinTokensPro = 4096
+	// geminiContextCacheCleanupInterval controls how often expired entries are purged.
+	geminiContextCacheCleanupInterval = 15 * time.Minute
+)
+
+// geminiContextCacheCleanupOnce ensures the background cleanup goroutine starts only once.
+var geminiContextCacheCleanupOnce sync.Once
+
+// startGeminiContextCacheCleanup launches a goroutine that periodically removes expired
+// entries from geminiContextCacheMap.
+func startGeminiContextCacheCleanup() {
+	go func() {
+		ticker := time.NewTicker(geminiContextCacheCleanupInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			purgeExpiredGeminiContextCache()
+		}
+	}()
+}
+
+// purgeExpiredGeminiContextCache removes entries that have expired.
+func purgeExpiredGeminiContextCache() {
+	now := time.Now()
+
+	geminiContextCacheMu.Lock()
+	defer geminiContextCacheMu.Unlock()
+
+	for key, cache := range geminiContextCacheMap {
+		if cache.Expire.Before(now) {
+			delete(geminiContextCacheMap, key)
+		}
+	}
+}
+
+// getGeminiContextCache retrieves an entry, returning ok=false if not found or expired.
+func getGeminiContextCache(key string) (geminiContextCache, bool) {
+	geminiContextCacheCleanupOnce.Do(startGeminiContextCacheCleanup)
+	geminiContextCacheMu.RLock()
+	cache, ok := geminiContextCacheMap[key]
+	geminiContextCacheMu.RUnlock()
+	if !ok || cache.Expire.Before(time.Now()) {
+		return geminiContextCache{}, false
+	}
+	return cache, true
+}
+
+// setGeminiContextCache stores an entry.
+func setGeminiContextCache(key string, cache geminiContextCache) {
+	geminiContextCacheCleanupOnce.Do(startGeminiContextCacheCleanup)
+	geminiContextCacheMu.Lock()
+	geminiContextCacheMap[key] = cache
+	geminiContextCacheMu.Unlock()
+}
+
+// deleteGeminiContextCache deletes an entry.
+func deleteGeminiContextCache(key string) {
+	geminiContextCacheMu.Lock()
+	delete(geminiContextCacheMap, key)
+	geminiContextCacheMu.Unlock()
+}
+
+// geminiCachePrefix describes one cache_control breakpoint of a translated request.
+type geminiCachePrefix struct {
+	contents int
+	key      string
+}
+
+// geminiCachedContentFields are moved into the cachedContents resource and must be removed
+// from generate requests that reference it.
+var geminiCachedContentFields = []string{"system_instruction", "systemInstruction", "tools", "toolConfig", "tool_config"}
+
+// applyGeminiContextCache rewrites body to reference a cachedContents resource holding the
+// longest cacheable prefix marked by the cache_control breakpoints of a Claude request,
+// when the bridge is enabled. It returns the rewritten body and the cache key in use, or
+// an empty key when the request is sent uncached.
+func (e *GeminiExecutor) applyGeminiContextCache(ctx context.Context, auth *cliproxyauth.Auth, baseModel string, from sdktranslator.Format, original, body []byte) ([]byte, string) {
+	if e.cfg == nil || !e.cfg.PromptCache.Enable || from != sdktranslator.FormatClaude {
+		return body, ""
+	}
+	breakpoints, ttlHint := geminiclaude.CacheBreakpoints(original)
+	prefixes := geminiCachePrefixes(auth, baseModel, body, breakpoints)
+	if len(prefixes) == 0 {
+		return body, ""
+	}
+	longest := prefixes[len(prefixes)-1]
+
+	var reuse *geminiCachePrefix
+	var reuseName string
+	for i := len(prefixes) - 1; i >= 0; i-- {
+		if cache, ok := getGeminiContextCache(prefixes[i].key); ok && !cache.Failed {
+			reuse = &prefixes[i]
+			reuseName = cache.Name
+			break
+		}
+	}
+
+	// A live entry for the longest prefix at this point can only be a failed attempt.
+	if _, known := getGeminiContextCache(longest.key); !known && (reuse == nil || reuse.key != longest.key) {
+		tokens := geminiPrefixTokens(baseModel, body, longest.contents)
+		if tokens >= geminiContextCacheMinTokensFor(baseModel) && (reuse == nil || geminiPrefixTokens(baseModel, body, reuse.contents)*2 < tokens) {
+			ttl := e.geminiContextCacheTTL(ttlHint)
+			name, expire, err := e.createGeminiCachedContent(ctx, auth, baseModel, body, longest.contents, ttl)
+			if err != nil {
+				logWithRequestID(ctx).Debugf("gemini executor: create cached content failed: %v", err)
+				setGeminiContextCache(longest.key, geminiContextCache{Failed: true, Expire: time.Now().Add(ttl)})
+			} else {
+				setGeminiContextCache(longest.key, geminiContextCache{Name: name, Expire: expire})
+				reuse = &longest
+				reuseName = name
+			}
+		}
+	}
+	if reuse == nil {
+		return body, ""
+	}
+
+	return geminiUseCachedContent(body, reuseName, reuse.contents), reuse.key
+}
+
+// geminiContextCacheMinTokensFor returns the smallest prefix Gemini accepts as cached content.
+func geminiContextCacheMinTokensFor(baseModel string) int64 {
+	if strings.Contains(strings.ToLower(baseModel), "pro") {
+		return geminiContextCacheMinTokensPro
+	}
+	return geminiContextCacheMinTokens
+}
+
+// geminiPrefixTokens estimates the tokens of the system instruction, tools and the first n
+// contents of body with the local tokenizer. It returns 0 when counting fails.
+func geminiPrefixTokens(baseModel string, body []byte, n int) int64 {
+	tokens, err := tokencount.CountRequest("gemini", baseModel, geminiCachedContentPayload(baseModel, body, n))
+	if err != nil {
+		return 0
+	}
+	return tokens
+}
+
+// geminiCachePrefixes hashes the request prefix at each breakpoint that leaves at least one
+// content to send. The hash covers the credential, model, system instruction, tools and the
+// contents before the breakpoint, so any change to earlier turns yields a new key.
+func geminiCachePrefixes(auth *cliproxyauth.Auth, baseModel string, body []byte, breakpoints []int) []geminiCachePrefix {
+	contents := gjson.GetBytes(body, "contents").Array()
+	var points []int
+	for _, n := range breakpoints {
+		if n >= 0 && n < len(contents) {
+			points = append(points, n)
+		}
+	}
+	if len(points) == 0 {
+		return nil
+	}
+
+	authID := ""
+	if auth != nil {
+		authID = auth.ID
+	}
+	h := sha256.New()
+	for _, field := range []string{"model", "system_instruction", "systemInstruction", "tools", "toolConfig", "tool_config"} {
+		raw := gjson.GetBytes(body, field).Raw
+		if field == "model" {
+			raw = baseModel
+		}
+		_, _ = fmt.Fprintf(h, "%s:%d:%s\n", field, len(raw), raw)
+	}
+
+	prefixes := make([]geminiCachePrefix, 0, len(points))
+	next := 0
+	for _, point := range points {
+		for ; next < point; next++ {
+			_, _ = fmt.Fprintf(h, "content:%d:%s\n", len(contents[next].Raw), contents[next].Raw)
+		}
+		if len(prefixes) > 0 && prefixes[len(prefixes)-1].contents == point {
+			continue
+		}
+		prefixes = append(prefixes, geminiCachePrefix{
+			contents: point,
+			key:      authID + ":" + baseModel + ":" + hex.EncodeToString(h.Sum(nil)),
+		})
+	}
+	return prefixes
+}
+
+// geminiContextCacheTTL resolves the cachedContents lifetime for a breakpoint TTL hint.
+func (e *GeminiExecutor) geminiContextCacheTTL(hint string) time.Duration {
+	if hint == "1h" {
+		return geminiContextCacheLongTTL
+	}
+	if e.cfg != nil && e.cfg.PromptCache.TTLSeconds > 0 {
+		return time.Duration(e.cfg.PromptCache.TTLSeconds) * time.Second
+	}
+	return geminiContextCacheDefaultTTL
+}
+
+// geminiCachedContentPayload builds a cachedContents resource holding the system
+// instruction, tools and the first n contents of body.
+func geminiCachedContentPayload(baseModel string, body []byte, n int) []byte {
+	payload := []byte(`{}`)
+	payload, _ = sjson.SetBytes(payload, "model", "models/"+baseModel)
+	contents := gjson.GetBytes(body, "contents").Array()
+	payload, _ = sjson.SetRawBytes(payload, "contents", []byte(`[]`))
+	for i := 0; i < n && i < len(contents); i++ {
+		payload, _ = sjson.SetRawBytes(payload, "contents.-1", []byte(contents[i].Raw))
+	}
+	if system := gjson.GetBytes(body, "system_instruction"); system.Exists() {
+		payload, _ = sjson.SetRawBytes(payload, "systemInstruction", []byte(system.Raw))
+	} else if system = gjson.GetBytes(body, "systemInstruction"); system.Exists() {
+		payload, _ = sjson.SetRawBytes(payload, "systemInstruction", []byte(system.Raw))
+	}
+	if tools := gjson.GetBytes(body, "tools"); tools.Exists() {
+		payload, _ = sjson.SetRawBytes(payload, "tools", []byte(tools.Raw))
+	}
+	if toolConfig := gjson.GetBytes(body, "toolConfig"); toolConfig.Exists() {
+		payload, _ = sjson.SetRawBytes(payload, "toolConfig", []byte(toolConfig.Raw))
+	} else if toolConfig = gjson.GetBytes(body, "tool_config"); toolConfig.Exists() {
+		payload, _ = sjson.SetRawBytes(payload, "toolConfig", []byte(toolConfig.Raw))
+	}
+	return payload
+}
+
+// createGeminiCachedContent creates a cachedContents resource holding the system instruction,
+// tools and the first n contents of body.
+func (e *GeminiExecutor) createGeminiCachedContent(ctx context.Context, auth *cliproxyauth.Auth, baseModel string, body []byte, n int, ttl time.Duration) (string, time.Time, error) {
+	payload := geminiCachedContentPayload(baseModel, body, n)
+	payload, _ = sjson.SetBytes(payload, "ttl", fmt.Sprintf("%ds", int(ttl.Seconds())))
+
+	apiKey, bearer := geminiCreds(auth)
+	url := fmt.Sprintf("%s/%s/cachedContents", resolveGeminiBaseURL(auth), glAPIVersion)
+	data, err := doJSONPost(ctx, e.cfg, auth, e.Identifier(), url, payload, func(httpReq *http.Request) error {
+		if apiKey != "" {
+			httpReq.Header.Set("x-goog-api-key", apiKey)
+		} else if bearer != "" {
+			httpReq.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		applyGeminiHeaders(httpReq, auth)
+		return nil
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	name := gjson.GetBytes(data, "name").String()
+	if name == "" {
+		return "", time.Time{}, fmt.Errorf("cached content response has no name")
+	}
+	expire := time.Now().Add(ttl)
+	if t, errParse := time.Parse(time.RFC3339Nano, gjson.GetBytes(data, "expireTime").String()); errParse == nil {
+		expire = t
+	}
+	return name, expire.Add(-geminiContextCacheExpiryMargin), nil
+}
+
+// geminiUseCachedContent points body at the named cachedContents resource and drops the
+// fields and leading contents it already holds.
+func geminiUseCachedContent(body []byte, name string, n int) []byte {
+	for _, field := range geminiCachedContentFields {
+		body, _ = sjson.DeleteBytes(body, field)
+	}
+	contents := gjson.GetBytes(body, "contents").Array()
+	rest := []byte(`[]`)
+	for i := n; i < len(contents); i++ {
+		rest, _ = sjson.SetRawBytes(rest, "-1", []byte(contents[i].Raw))
+	}
+	body, _ = sjson.SetRawBytes(body, "contents", rest)
+	body, _ = sjson.SetBytes(body, "cachedContent", name)
+	return body
+}
+
+// invalidateGeminiContextCache forgets key when the upstream reports that the referenced
+// cachedContents resource no longer exists, so the next turn creates a fresh one.
+func invalidateGeminiContextCache(key string, code int, msg string) {
+	if key == "" {
+		return
+	}
+	if code == http.StatusBadRequest || code == http.StatusForbidden || code == http.StatusNotFound {
+		if strings.Contains(strings.ToLower(msg), "cachedcontent") || strings.Contains(strings.ToLower(msg), "cached content") {
+			deleteGeminiContextCache(key)
+		}
+	}
+}
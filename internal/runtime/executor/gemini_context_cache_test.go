@@ -0,0 +1,137 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+func TestGeminiExecutorReusesCachedContentPerPrefix(t *testing.T) {
+	var mu sync.Mutex
+	var created []string
+	var generated []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/cachedContents"):
+			created = append(created, string(body))
+			_, _ = w.Write([]byte(`{"name":"cachedContents/abc","expireTime":"2999-01-01T00:00:00Z"}`))
+		case strings.HasSuffix(r.URL.Path, ":generateContent"):
+			generated = append(generated, string(body))
+			_, _ = w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"ok"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":10,"cachedContentTokenCount":8,"candidatesTokenCount":1}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	exec := NewGeminiExecutor(&config.Config{PromptCache: config.PromptCacheConfig{Enable: true}})
+	auth := &cliproxyauth.Auth{ID: "gemini-cache-test", Attributes: map[string]string{"api_key": "key", "base_url": server.URL}}
+
+	system := strings.Repeat("You are a careful assistant. ", 200)
+	payload := []byte(`{"model":"gemini-2.5-flash","max_tokens":100,"system":[{"type":"text","text":"","cache_control":{"type":"ephemeral"}}],` +
+		`"tools":[{"name":"lookup","input_schema":{"type":"object"}}],"messages":[` +
+		`{"role":"user","content":"first"},` +
+		`{"role":"assistant","content":[{"type":"text","text":"answer","cache_control":{"type":"ephemeral"}}]},` +
+		`{"role":"user","content":"second"}]}`)
+	payload, _ = sjson.SetBytes(payload, "system.0.text", system)
+
+	for i := 0; i < 2; i++ {
+		_, err := exec.Execute(context.Background(), auth, cliproxyexecutor.Request{Model: "gemini-2.5-flash", Payload: payload}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("claude")})
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	}
+
+	if len(created) != 1 {
+		t.Fatalf("cachedContents created %d times, want 1", len(created))
+	}
+	create := gjson.Parse(created[0])
+	if got := create.Get("model").String(); got != "models/gemini-2.5-flash" {
+		t.Fatalf("cached model = %q", got)
+	}
+	if got := create.Get("contents.#").Int(); got != 2 {
+		t.Fatalf("cached contents = %d, want 2", got)
+	}
+	if got := create.Get("ttl").String(); got != "300s" {
+		t.Fatalf("cached ttl = %q, want 300s", got)
+	}
+	if !create.Get("systemInstruction").Exists() || !create.Get("tools").Exists() {
+		t.Fatalf("cached content missing system instruction or tools: %s", created[0])
+	}
+
+	for _, body := range generated {
+		req := gjson.Parse(body)
+		if got := req.Get("cachedContent").String(); got != "cachedContents/abc" {
+			t.Fatalf("cachedContent = %q, want cachedContents/abc", got)
+		}
+		if req.Get("cache_control").Exists() || req.Get("system_instruction").Exists() || req.Get("tools").Exists() {
+			t.Fatalf("generate request still carries cached fields: %s", body)
+		}
+		if got := req.Get("contents.#").Int(); got != 1 || req.Get("contents.0.parts.0.text").String() != "second" {
+			t.Fatalf("generate contents = %s, want only the uncached turn", req.Get("contents").Raw)
+		}
+	}
+}
+
+func TestApplyGeminiContextCacheSkipsSmallPrefixes(t *testing.T) {
+	exec := NewGeminiExecutor(&config.Config{PromptCache: config.PromptCacheConfig{Enable: true}})
+	original := []byte(`{"messages":[{"role":"user","content":[{"type":"text","text":"hi","cache_control":{"type":"ephemeral"}}]},{"role":"user","content":"there"}]}`)
+	body := []byte(`{"contents":[{"role":"user","parts":[{"text":"hi"}]},{"role":"user","parts":[{"text":"there"}]}]}`)
+
+	out, key := exec.applyGeminiContextCache(context.Background(), nil, "gemini-2.5-flash", sdktranslator.FormatClaude, original, body)
+	if key != "" {
+		t.Fatalf("cache key = %q, want none", key)
+	}
+	if gjson.GetBytes(out, "cachedContent").Exists() {
+		t.Fatalf("unexpected cache fields: %s", out)
+	}
+	if got := gjson.GetBytes(out, "contents.#").Int(); got != 2 {
+		t.Fatalf("contents = %d, want 2", got)
+	}
+}
+
+func TestApplyGeminiContextCacheIsOptIn(t *testing.T) {
+	original := []byte(`{"messages":[{"role":"user","content":[{"type":"text","text":"hi","cache_control":{"type":"ephemeral"}}]},{"role":"user","content":"there"}]}`)
+	body := []byte(`{"contents":[{"role":"user","parts":[{"text":"hi"}]},{"role":"user","parts":[{"text":"there"}]}]}`)
+	exec := NewGeminiExecutor(&config.Config{})
+	if out, key := exec.applyGeminiContextCache(context.Background(), nil, "gemini-2.5-flash", sdktranslator.FormatClaude, original, body); key != "" || string(out) != string(body) {
+		t.Fatalf("cache bridge active without prompt-cache.enable: key %q, body %s", key, out)
+	}
+}
+
+func TestClaudePromptCacheKeyFollowsFirstBreakpoint(t *testing.T) {
+	first := []byte(`{"system":[{"type":"text","text":"sys","cache_control":{"type":"ephemeral"}}],"messages":[{"role":"user","content":"one"}]}`)
+	later := []byte(`{"system":[{"type":"text","text":"sys","cache_control":{"type":"ephemeral"}}],"messages":[{"role":"user","content":"one"},{"role":"assistant","content":"two"},{"role":"user","content":"three"}]}`)
+
+	cfg := &config.Config{PromptCache: config.PromptCacheConfig{Enable: true}}
+	key := claudePromptCacheKey(cfg, "gpt-5", first)
+	if key == "" {
+		t.Fatal("expected a key for a request with a breakpoint")
+	}
+	if got := claudePromptCacheKey(cfg, "gpt-5", later); got != key {
+		t.Fatalf("key changed across turns: %q != %q", got, key)
+	}
+	if got := claudePromptCacheKey(cfg, "gpt-5-codex", first); got == key {
+		t.Fatal("key should depend on the model")
+	}
+	if got := claudePromptCacheKey(cfg, "gpt-5", []byte(`{"messages":[{"role":"user","content":"one"}]}`)); got != "" {
+		t.Fatalf("key = %q, want none without breakpoints", got)
+	}
+	if got := claudePromptCacheKey(&config.Config{}, "gpt-5", first); got != "" {
+		t.Fatalf("key = %q, want none while the bridge is not enabled", got)
+	}
+}
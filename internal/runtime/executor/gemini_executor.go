@@ -103,6 +103,9 @@ func (e *GeminiExecutor) HttpRequest(ctx context.Context, auth *cliproxyauth.Aut
 //   - cliproxyexecutor.Response: The response from the API
 //   - error: An error if the request fails
 func (e *GeminiExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (resp cliproxyexecutor.Response, err error) {
+	if action := embeddingAction(req); action != "" {
+		return e.executeEmbedding(ctx, auth, req, opts, action)
+	}
 	baseModel := thinking.ParseSuffix(req.Model).ModelName
 
 	apiKey, bearer := geminiCreds(auth)
@@ -124,6 +127,9 @@ func (e *GeminiExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, r
 	if err != nil {
 		return resp, err
 	}
+	if err = checkAudioSupport(e.Identifier(), baseModel, body); err != nil {
+		return resp, err
+	}
 
 	body = fixGeminiImageAspectRatio(baseModel, body)
 	requestedModel := payloadRequestedModel(opts, req.Model)
@@ -143,6 +149,11 @@ func (e *GeminiExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, r
 	}
 
 	body, _ = sjson.DeleteBytes(body, "session_id")
+	body, _ = sjson.DeleteBytes(body, "cache_control")
+	cacheKey := ""
+	if action != "countTokens" {
+		body, cacheKey = e.applyGeminiContextCache(ctx, auth, baseModel, from, req.Payload, body)
+	}
 
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
@@ -189,6 +200,7 @@ func (e *GeminiExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, r
 		b, _ := io.ReadAll(httpResp.Body)
 		appendAPIResponseChunk(ctx, e.cfg, b)
 		logWithRequestID(ctx).Debugf("request error, error status: %d, error message: %s", httpResp.StatusCode, summarizeErrorBody(httpResp.Header.Get("Content-Type"), b))
+		invalidateGeminiContextCache(cacheKey, httpResp.StatusCode, string(b))
 		err = statusErr{code: httpResp.StatusCode, msg: string(b)}
 		return resp, err
 	}
@@ -205,6 +217,34 @@ func (e *GeminiExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, r
 	return resp, nil
 }
 
+// executeEmbedding calls embedContent or batchEmbedContents on the Gemini API.
+func (e *GeminiExecutor) executeEmbedding(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, action string) (resp cliproxyexecutor.Response, err error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+	apiKey, bearer := geminiCreds(auth)
+
+	reporter := newUsageReporter(ctx, e.Identifier(), baseModel, auth)
+	defer reporter.trackFailure(ctx, &err)
+
+	body, upstreamAction, err := buildGeminiEmbeddingBody(opts.SourceFormat, baseModel, action, req.Payload)
+	if err != nil {
+		return resp, err
+	}
+	url := fmt.Sprintf("%s/%s/models/%s:%s", resolveGeminiBaseURL(auth), glAPIVersion, baseModel, upstreamAction)
+	data, err := doJSONPost(ctx, e.cfg, auth, e.Identifier(), url, body, func(httpReq *http.Request) error {
+		if apiKey != "" {
+			httpReq.Header.Set("x-goog-api-key", apiKey)
+		} else if bearer != "" {
+			httpReq.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		applyGeminiHeaders(httpReq, auth)
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+	return finishGeminiEmbedding(ctx, reporter, req, opts, baseModel, upstreamAction, body, data), nil
+}
+
 // ExecuteStream performs a streaming request to the Gemini API.
 func (e *GeminiExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (stream <-chan cliproxyexecutor.StreamChunk, err error) {
 	baseModel := thinking.ParseSuffix(req.Model).ModelName
@@ -227,6 +267,9 @@ func (e *GeminiExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.A
 	if err != nil {
 		return nil, err
 	}
+	if err = checkAudioSupport(e.Identifier(), baseModel, body); err != nil {
+		return nil, err
+	}
 
 	body = fixGeminiImageAspectRatio(baseModel, body)
 	requestedModel := payloadRequestedModel(opts, req.Model)
@@ -242,6 +285,8 @@ func (e *GeminiExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.A
 	}
 
 	body, _ = sjson.DeleteBytes(body, "session_id")
+	body, _ = sjson.DeleteBytes(body, "cache_control")
+	body, cacheKey := e.applyGeminiContextCache(ctx, auth, baseModel, from, req.Payload, body)
 
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
@@ -286,6 +331,7 @@ func (e *GeminiExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.A
 		if errClose := httpResp.Body.Close(); errClose != nil {
 			log.Errorf("gemini executor: close response body error: %v", errClose)
 		}
+		invalidateGeminiContextCache(cacheKey, httpResp.StatusCode, string(b))
 		err = statusErr{code: httpResp.StatusCode, msg: string(b)}
 		return nil, err
 	}
@@ -350,6 +396,7 @@ func (e *GeminiExecutor) CountTokens(ctx context.Context, auth *cliproxyauth.Aut
 	translatedReq, _ = sjson.DeleteBytes(translatedReq, "tools")
 	translatedReq, _ = sjson.DeleteBytes(translatedReq, "generationConfig")
 	translatedReq, _ = sjson.DeleteBytes(translatedReq, "safetySettings")
+	translatedReq, _ = sjson.DeleteBytes(translatedReq, "cache_control")
 	translatedReq, _ = sjson.SetBytes(translatedReq, "model", baseModel)
 
 	baseURL := resolveGeminiBaseURL(auth)
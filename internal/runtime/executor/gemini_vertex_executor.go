@@ -233,6 +233,10 @@ func (e *GeminiVertexExecutor) HttpRequest(ctx context.Context, auth *cliproxyau
 
 // Execute performs a non-streaming request to the Vertex AI API.
 func (e *GeminiVertexExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (resp cliproxyexecutor.Response, err error) {
+	if action := embeddingAction(req); action != "" {
+		return e.executeEmbedding(ctx, auth, req, opts, action)
+	}
+
 	// Try API key authentication first
 	apiKey, baseURL := vertexAPICreds(auth)
 
@@ -249,6 +253,60 @@ func (e *GeminiVertexExecutor) Execute(ctx context.Context, auth *cliproxyauth.A
 	return e.executeWithAPIKey(ctx, auth, req, opts, apiKey, baseURL)
 }
 
+// executeEmbedding calls the Vertex AI predict action for text-embedding models, using
+// API key credentials when present and the service account otherwise.
+func (e *GeminiVertexExecutor) executeEmbedding(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, action string) (resp cliproxyexecutor.Response, err error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+
+	reporter := newUsageReporter(ctx, e.Identifier(), baseModel, auth)
+	defer reporter.trackFailure(ctx, &err)
+
+	body, upstreamAction, err := buildGeminiEmbeddingBody(opts.SourceFormat, baseModel, action, req.Payload)
+	if err != nil {
+		return resp, err
+	}
+	predictBody := convertGeminiEmbeddingToVertexPredict(body, upstreamAction)
+
+	var url string
+	var prepare func(*http.Request) error
+	if apiKey, baseURL := vertexAPICreds(auth); apiKey != "" {
+		if baseURL == "" {
+			baseURL = "https://generativelanguage.googleapis.com"
+		}
+		url = fmt.Sprintf("%s/%s/publishers/google/models/%s:predict", baseURL, vertexAPIVersion, baseModel)
+		prepare = func(httpReq *http.Request) error {
+			httpReq.Header.Set("x-goog-api-key", apiKey)
+			applyGeminiHeaders(httpReq, auth)
+			return nil
+		}
+	} else {
+		projectID, location, saJSON, errCreds := vertexCreds(auth)
+		if errCreds != nil {
+			return resp, errCreds
+		}
+		url = fmt.Sprintf("%s/%s/projects/%s/locations/%s/publishers/google/models/%s:predict", vertexBaseURL(location), vertexAPIVersion, projectID, location, baseModel)
+		prepare = func(httpReq *http.Request) error {
+			token, errTok := vertexAccessToken(ctx, e.cfg, auth, saJSON)
+			if errTok != nil {
+				log.Errorf("vertex executor: access token error: %v", errTok)
+				return statusErr{code: 500, msg: "internal server error"}
+			}
+			if token != "" {
+				httpReq.Header.Set("Authorization", "Bearer "+token)
+			}
+			applyGeminiHeaders(httpReq, auth)
+			return nil
+		}
+	}
+
+	data, err := doJSONPost(ctx, e.cfg, auth, e.Identifier(), url, predictBody, prepare)
+	if err != nil {
+		return resp, err
+	}
+	data = convertVertexPredictToGeminiEmbedding(data, upstreamAction)
+	return finishGeminiEmbedding(ctx, reporter, req, opts, baseModel, upstreamAction, body, data), nil
+}
+
 // ExecuteStream performs a streaming request to the Vertex AI API.
 func (e *GeminiVertexExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (stream <-chan cliproxyexecutor.StreamChunk, err error) {
 	// Try API key authentication first
@@ -323,6 +381,9 @@ func (e *GeminiVertexExecutor) executeWithServiceAccount(ctx context.Context, au
 		if err != nil {
 			return resp, err
 		}
+		if err = checkAudioSupport(e.Identifier(), baseModel, body); err != nil {
+			return resp, err
+		}
 
 		body = fixGeminiImageAspectRatio(baseModel, body)
 		requestedModel := payloadRequestedModel(opts, req.Model)
@@ -342,6 +403,7 @@ func (e *GeminiVertexExecutor) executeWithServiceAccount(ctx context.Context, au
 		url = url + fmt.Sprintf("?$alt=%s", opts.Alt)
 	}
 	body, _ = sjson.DeleteBytes(body, "session_id")
+	body, _ = sjson.DeleteBytes(body, "cache_control")
 
 	httpReq, errNewReq := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if errNewReq != nil {
@@ -437,6 +499,9 @@ func (e *GeminiVertexExecutor) executeWithAPIKey(ctx context.Context, auth *clip
 	if err != nil {
 		return resp, err
 	}
+	if err = checkAudioSupport(e.Identifier(), baseModel, body); err != nil {
+		return resp, err
+	}
 
 	body = fixGeminiImageAspectRatio(baseModel, body)
 	requestedModel := payloadRequestedModel(opts, req.Model)
@@ -459,6 +524,7 @@ func (e *GeminiVertexExecutor) executeWithAPIKey(ctx context.Context, auth *clip
 		url = url + fmt.Sprintf("?$alt=%s", opts.Alt)
 	}
 	body, _ = sjson.DeleteBytes(body, "session_id")
+	body, _ = sjson.DeleteBytes(body, "cache_control")
 
 	httpReq, errNewReq := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if errNewReq != nil {
@@ -541,6 +607,9 @@ func (e *GeminiVertexExecutor) executeStreamWithServiceAccount(ctx context.Conte
 	if err != nil {
 		return nil, err
 	}
+	if err = checkAudioSupport(e.Identifier(), baseModel, body); err != nil {
+		return nil, err
+	}
 
 	body = fixGeminiImageAspectRatio(baseModel, body)
 	requestedModel := payloadRequestedModel(opts, req.Model)
@@ -559,6 +628,7 @@ func (e *GeminiVertexExecutor) executeStreamWithServiceAccount(ctx context.Conte
 		}
 	}
 	body, _ = sjson.DeleteBytes(body, "session_id")
+	body, _ = sjson.DeleteBytes(body, "cache_control")
 
 	httpReq, errNewReq := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if errNewReq != nil {
@@ -665,6 +735,9 @@ func (e *GeminiVertexExecutor) executeStreamWithAPIKey(ctx context.Context, auth
 	if err != nil {
 		return nil, err
 	}
+	if err = checkAudioSupport(e.Identifier(), baseModel, body); err != nil {
+		return nil, err
+	}
 
 	body = fixGeminiImageAspectRatio(baseModel, body)
 	requestedModel := payloadRequestedModel(opts, req.Model)
@@ -686,6 +759,7 @@ func (e *GeminiVertexExecutor) executeStreamWithAPIKey(ctx context.Context, auth
 		}
 	}
 	body, _ = sjson.DeleteBytes(body, "session_id")
+	body, _ = sjson.DeleteBytes(body, "cache_control")
 
 	httpReq, errNewReq := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if errNewReq != nil {
@@ -788,6 +862,7 @@ func (e *GeminiVertexExecutor) countTokensWithServiceAccount(ctx context.Context
 	translatedReq, _ = sjson.DeleteBytes(translatedReq, "tools")
 	translatedReq, _ = sjson.DeleteBytes(translatedReq, "generationConfig")
 	translatedReq, _ = sjson.DeleteBytes(translatedReq, "safetySettings")
+	translatedReq, _ = sjson.DeleteBytes(translatedReq, "cache_control")
 
 	baseURL := vertexBaseURL(location)
 	url := fmt.Sprintf("%s/%s/projects/%s/locations/%s/publishers/google/models/%s:%s", baseURL, vertexAPIVersion, projectID, location, baseModel, "countTokens")
@@ -872,6 +947,7 @@ func (e *GeminiVertexExecutor) countTokensWithAPIKey(ctx context.Context, auth *
 	translatedReq, _ = sjson.DeleteBytes(translatedReq, "tools")
 	translatedReq, _ = sjson.DeleteBytes(translatedReq, "generationConfig")
 	translatedReq, _ = sjson.DeleteBytes(translatedReq, "safetySettings")
+	translatedReq, _ = sjson.DeleteBytes(translatedReq, "cache_control")
 
 	// For API key auth, use simpler URL format without project/location
 	if baseURL == "" {
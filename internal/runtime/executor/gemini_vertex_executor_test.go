@@ -0,0 +1,69 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+func TestGeminiVertexExecutorStripsCacheControl(t *testing.T) {
+	var mu sync.Mutex
+	bodies := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		action := r.URL.Path[strings.LastIndex(r.URL.Path, ":")+1:]
+		mu.Lock()
+		bodies[action] = string(body)
+		mu.Unlock()
+		switch action {
+		case "countTokens":
+			_, _ = w.Write([]byte(`{"totalTokens":3}`))
+		case "streamGenerateContent":
+			_, _ = w.Write([]byte("data: {\"candidates\":[{\"content\":{\"role\":\"model\",\"parts\":[{\"text\":\"ok\"}]},\"finishReason\":\"STOP\"}]}\n\n"))
+		default:
+			_, _ = w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"ok"}]},"finishReason":"STOP"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	exec := NewGeminiVertexExecutor(&config.Config{})
+	auth := &cliproxyauth.Auth{ID: "vertex-cache-control-test", Attributes: map[string]string{"api_key": "key", "base_url": server.URL}}
+	req := cliproxyexecutor.Request{
+		Model:   "gemini-2.5-flash",
+		Payload: []byte(`{"contents":[{"role":"user","parts":[{"text":"hi"}]}],"cache_control":{"type":"ephemeral"}}`),
+	}
+	opts := cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("gemini")}
+
+	if _, err := exec.Execute(context.Background(), auth, req, opts); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if _, err := exec.CountTokens(context.Background(), auth, req, opts); err != nil {
+		t.Fatalf("CountTokens() error = %v", err)
+	}
+	stream, err := exec.ExecuteStream(context.Background(), auth, req, opts)
+	if err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	for range stream {
+	}
+
+	for _, action := range []string{"generateContent", "countTokens", "streamGenerateContent"} {
+		body, ok := bodies[action]
+		if !ok {
+			t.Fatalf("no %s request sent", action)
+		}
+		if gjson.Get(body, "cache_control").Exists() {
+			t.Fatalf("%s request carries cache_control: %s", action, body)
+		}
+	}
+}
@@ -120,6 +120,10 @@ func (e *GitHubCopilotExecutor) Execute(ctx context.Context, auth *cliproxyauth.
 	}
 	originalTranslated := sdktranslator.TranslateRequest(from, to, req.Model, originalPayload, false)
 	body := sdktranslator.TranslateRequest(from, to, req.Model, bytes.Clone(req.Payload), false)
+	body, err = applyDocumentFallback(e.cfg, body)
+	if err != nil {
+		return resp, err
+	}
 	body = e.normalizeModel(req.Model, body)
 
   body, err = thinking.ApplyThinking(body, req.Model, from.String(), to.String(), e.Identifier())                                                                        
@@ -226,6 +230,10 @@ func (e *GitHubCopilotExecutor) ExecuteStream(ctx context.Context, auth *cliprox
 	}
 	originalTranslated := sdktranslator.TranslateRequest(from, to, req.Model, originalPayload, false)
 	body := sdktranslator.TranslateRequest(from, to, req.Model, bytes.Clone(req.Payload), true)
+	body, err = applyDocumentFallback(e.cfg, body)
+	if err != nil {
+		return nil, err
+	}
 	body = e.normalizeModel(req.Model, body)
 
   body, err = thinking.ApplyThinking(body, req.Model, from.String(), to.String(), e.Identifier())                                                                        
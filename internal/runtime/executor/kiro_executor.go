@@ -5,7 +5,6 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
-	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -22,6 +21,8 @@ import (
 	"github.com/google/uuid"
 	kiroauth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/kiro"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tokencount"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/toolemulation"
 	kiroclaude "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/kiro/claude"
 	kirocommon "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/kiro/common"
 	kiroopenai "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/kiro/openai"
@@ -38,15 +39,6 @@ const (
 	kiroContentType  = "application/x-amz-json-1.0"
 	kiroAcceptStream = "*/*"
 
-	// Event Stream frame size constants for boundary protection
-	// AWS Event Stream binary format: prelude (12 bytes) + headers + payload + message_crc (4 bytes)
-	// Prelude consists of: total_length (4) + headers_length (4) + prelude_crc (4)
-	minEventStreamFrameSize = 16       // Minimum: 4(total_len) + 4(headers_len) + 4(prelude_crc) + 4(message_crc)
-	maxEventStreamMsgSize   = 10 << 20 // Maximum message length: 10MB
-
-	// Event Stream error type constants
-	ErrStreamFatal     = "fatal"     // Connection/authentication errors, not recoverable
-	ErrStreamMalformed = "malformed" // Format errors, data cannot be parsed
 	// kiroUserAgent matches amq2api format for User-Agent header (Amazon Q CLI style)
 	kiroUserAgent = "aws-sdk-rust/1.3.9 os/macos lang/rust/1.87.0"
 	// kiroFullUserAgent is the complete x-amz-user-agent header matching amq2api (Amazon Q CLI style)
@@ -611,6 +603,10 @@ func (e *KiroExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, req
 	from := opts.SourceFormat
 	to := sdktranslator.FromString("kiro")
 	body := sdktranslator.TranslateRequest(from, to, req.Model, bytes.Clone(req.Payload), true)
+	body, err = applyDocumentFallback(e.cfg, body)
+	if err != nil {
+		return resp, err
+	}
 
 	kiroModelID := e.mapModelToKiro(req.Model)
 
@@ -911,14 +907,14 @@ func (e *KiroExecutor) executeWithRetry(ctx context.Context, auth *cliproxyauth.
 
 			// Fallback for usage if missing from upstream
 			if usageInfo.TotalTokens == 0 {
-				if enc, encErr := getTokenizer(req.Model); encErr == nil {
-					if inp, countErr := countOpenAIChatTokens(enc, opts.OriginalRequest); countErr == nil {
+				if enc, encErr := tokencount.Get(req.Model); encErr == nil {
+					if inp, countErr := tokencount.CountOpenAIChat(enc, opts.OriginalRequest); countErr == nil {
 						usageInfo.InputTokens = inp
 					}
 				}
 				if len(content) > 0 {
 					// Use tiktoken for more accurate output token calculation
-					if enc, encErr := getTokenizer(req.Model); encErr == nil {
+					if enc, encErr := tokencount.Get(req.Model); encErr == nil {
 						if tokenCount, countErr := enc.Count(content); countErr == nil {
 							usageInfo.OutputTokens = int64(tokenCount)
 						}
@@ -1021,6 +1017,10 @@ func (e *KiroExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.Aut
 	from := opts.SourceFormat
 	to := sdktranslator.FromString("kiro")
 	body := sdktranslator.TranslateRequest(from, to, req.Model, bytes.Clone(req.Payload), true)
+	body, err = applyDocumentFallback(e.cfg, body)
+	if err != nil {
+		return nil, err
+	}
 
 	kiroModelID := e.mapModelToKiro(req.Model)
 
@@ -1646,26 +1646,6 @@ func (e *KiroExecutor) mapModelToKiro(model string) string {
 	return "claude-sonnet-4.5"
 }
 
-// EventStreamError represents an Event Stream processing error
-type EventStreamError struct {
-	Type    string // "fatal", "malformed"
-	Message string
-	Cause   error
-}
-
-func (e *EventStreamError) Error() string {
-	if e.Cause != nil {
-		return fmt.Sprintf("event stream %s: %s: %v", e.Type, e.Message, e.Cause)
-	}
-	return fmt.Sprintf("event stream %s: %s", e.Type, e.Message)
-}
-
-// eventStreamMessage represents a parsed AWS Event Stream message
-type eventStreamMessage struct {
-	EventType string // Event type from headers (e.g., "assistantResponseEvent")
-	Payload   []byte // JSON payload of the message
-}
-
 // NOTE: Request building functions moved to internal/translator/kiro/claude/kiro_claude_request.go
 // The executor now uses kiroclaude.BuildKiroPayload() instead
 
@@ -1688,7 +1668,7 @@ func (e *KiroExecutor) parseEventStream(body io.Reader) (string, []kiroclaude.Ki
 	var upstreamContextPercentage float64 // Context usage percentage from upstream (e.g., 78.56)
 
 	for {
-		msg, eventErr := e.readEventStreamMessage(reader)
+		msg, eventErr := readEventStreamMessage(reader)
 		if eventErr != nil {
 			log.Errorf("kiro: parseEventStream error: %v", eventErr)
 			return content.String(), toolUses, usageInfo, stopReason, eventErr
@@ -2132,194 +2112,6 @@ func (e *KiroExecutor) parseEventStream(body io.Reader) (string, []kiroclaude.Ki
 	return cleanedContent, toolUses, usageInfo, stopReason, nil
 }
 
-// readEventStreamMessage reads and validates a single AWS Event Stream message.
-// Returns the parsed message or a structured error for different failure modes.
-// This function implements boundary protection and detailed error classification.
-//
-// AWS Event Stream binary format:
-// - Prelude (12 bytes): total_length (4) + headers_length (4) + prelude_crc (4)
-// - Headers (variable): header entries
-// - Payload (variable): JSON data
-// - Message CRC (4 bytes): CRC32C of entire message (not validated, just skipped)
-func (e *KiroExecutor) readEventStreamMessage(reader *bufio.Reader) (*eventStreamMessage, *EventStreamError) {
-	// Read prelude (first 12 bytes: total_len + headers_len + prelude_crc)
-	prelude := make([]byte, 12)
-	_, err := io.ReadFull(reader, prelude)
-	if err == io.EOF {
-		return nil, nil // Normal end of stream
-	}
-	if err != nil {
-		return nil, &EventStreamError{
-			Type:    ErrStreamFatal,
-			Message: "failed to read prelude",
-			Cause:   err,
-		}
-	}
-
-	totalLength := binary.BigEndian.Uint32(prelude[0:4])
-	headersLength := binary.BigEndian.Uint32(prelude[4:8])
-	// Note: prelude[8:12] is prelude_crc - we read it but don't validate (no CRC check per requirements)
-
-	// Boundary check: minimum frame size
-	if totalLength < minEventStreamFrameSize {
-		return nil, &EventStreamError{
-			Type:    ErrStreamMalformed,
-			Message: fmt.Sprintf("invalid message length: %d (minimum is %d)", totalLength, minEventStreamFrameSize),
-		}
-	}
-
-	// Boundary check: maximum message size
-	if totalLength > maxEventStreamMsgSize {
-		return nil, &EventStreamError{
-			Type:    ErrStreamMalformed,
-			Message: fmt.Sprintf("message too large: %d bytes (maximum is %d)", totalLength, maxEventStreamMsgSize),
-		}
-	}
-
-	// Boundary check: headers length within message bounds
-	// Message structure: prelude(12) + headers(headersLength) + payload + message_crc(4)
-	// So: headersLength must be <= totalLength - 16 (12 for prelude + 4 for message_crc)
-	if headersLength > totalLength-16 {
-		return nil, &EventStreamError{
-			Type:    ErrStreamMalformed,
-			Message: fmt.Sprintf("headers length %d exceeds message bounds (total: %d)", headersLength, totalLength),
-		}
-	}
-
-	// Read the rest of the message (total - 12 bytes already read)
-	remaining := make([]byte, totalLength-12)
-	_, err = io.ReadFull(reader, remaining)
-	if err != nil {
-		return nil, &EventStreamError{
-			Type:    ErrStreamFatal,
-			Message: "failed to read message body",
-			Cause:   err,
-		}
-	}
-
-	// Extract event type from headers
-	// Headers start at beginning of 'remaining', length is headersLength
-	var eventType string
-	if headersLength > 0 && headersLength <= uint32(len(remaining)) {
-		eventType = e.extractEventTypeFromBytes(remaining[:headersLength])
-	}
-
-	// Calculate payload boundaries
-	// Payload starts after headers, ends before message_crc (last 4 bytes)
-	payloadStart := headersLength
-	payloadEnd := uint32(len(remaining)) - 4 // Skip message_crc at end
-
-	// Validate payload boundaries
-	if payloadStart >= payloadEnd {
-		// No payload, return empty message
-		return &eventStreamMessage{
-			EventType: eventType,
-			Payload:   nil,
-		}, nil
-	}
-
-	payload := remaining[payloadStart:payloadEnd]
-
-	return &eventStreamMessage{
-		EventType: eventType,
-		Payload:   payload,
-	}, nil
-}
-
-func skipEventStreamHeaderValue(headers []byte, offset int, valueType byte) (int, bool) {
-	switch valueType {
-	case 0, 1: // bool true / bool false
-		return offset, true
-	case 2: // byte
-		if offset+1 > len(headers) {
-			return offset, false
-		}
-		return offset + 1, true
-	case 3: // short
-		if offset+2 > len(headers) {
-			return offset, false
-		}
-		return offset + 2, true
-	case 4: // int
-		if offset+4 > len(headers) {
-			return offset, false
-		}
-		return offset + 4, true
-	case 5: // long
-		if offset+8 > len(headers) {
-			return offset, false
-		}
-		return offset + 8, true
-	case 6: // byte array (2-byte length + data)
-		if offset+2 > len(headers) {
-			return offset, false
-		}
-		valueLen := int(binary.BigEndian.Uint16(headers[offset : offset+2]))
-		offset += 2
-		if offset+valueLen > len(headers) {
-			return offset, false
-		}
-		return offset + valueLen, true
-	case 8: // timestamp
-		if offset+8 > len(headers) {
-			return offset, false
-		}
-		return offset + 8, true
-	case 9: // uuid
-		if offset+16 > len(headers) {
-			return offset, false
-		}
-		return offset + 16, true
-	default:
-		return offset, false
-	}
-}
-
-// extractEventTypeFromBytes extracts the event type from raw header bytes (without prelude CRC prefix)
-func (e *KiroExecutor) extractEventTypeFromBytes(headers []byte) string {
-	offset := 0
-	for offset < len(headers) {
-		nameLen := int(headers[offset])
-		offset++
-		if offset+nameLen > len(headers) {
-			break
-		}
-		name := string(headers[offset : offset+nameLen])
-		offset += nameLen
-
-		if offset >= len(headers) {
-			break
-		}
-		valueType := headers[offset]
-		offset++
-
-		if valueType == 7 { // String type
-			if offset+2 > len(headers) {
-				break
-			}
-			valueLen := int(binary.BigEndian.Uint16(headers[offset : offset+2]))
-			offset += 2
-			if offset+valueLen > len(headers) {
-				break
-			}
-			value := string(headers[offset : offset+valueLen])
-			offset += valueLen
-
-			if name == ":event-type" {
-				return value
-			}
-			continue
-		}
-
-		nextOffset, ok := skipEventStreamHeaderValue(headers, offset, valueType)
-		if !ok {
-			break
-		}
-		offset = nextOffset
-	}
-	return ""
-}
-
 // NOTE: Response building functions moved to internal/translator/kiro/claude/kiro_claude_response.go
 // The executor now uses kiroclaude.BuildClaudeResponse() and kiroclaude.ExtractThinkingFromContent() instead
 
@@ -2375,15 +2167,15 @@ func (e *KiroExecutor) streamToChannel(ctx context.Context, body io.Reader, out
 
 	// Pre-calculate input tokens from request if possible
 	// Kiro uses Claude format, so try Claude format first, then OpenAI format, then fallback
-	if enc, err := getTokenizer(model); err == nil {
+	if enc, err := tokencount.Get(model); err == nil {
 		var inputTokens int64
 		var countMethod string
 
 		// Try Claude format first (Kiro uses Claude API format)
-		if inp, err := countClaudeChatTokens(enc, claudeBody); err == nil && inp > 0 {
+		if inp, err := tokencount.CountClaude(enc, claudeBody); err == nil && inp > 0 {
 			inputTokens = inp
 			countMethod = "claude"
-		} else if inp, err := countOpenAIChatTokens(enc, originalReq); err == nil && inp > 0 {
+		} else if inp, err := tokencount.CountOpenAIChat(enc, originalReq); err == nil && inp > 0 {
 			// Fallback to OpenAI format (for OpenAI-compatible requests)
 			inputTokens = inp
 			countMethod = "openai"
@@ -2418,7 +2210,7 @@ func (e *KiroExecutor) streamToChannel(ctx context.Context, body io.Reader, out
 		default:
 		}
 
-		msg, eventErr := e.readEventStreamMessage(reader)
+		msg, eventErr := readEventStreamMessage(reader)
 		if eventErr != nil {
 			// Log the error
 			log.Errorf("kiro: streamToChannel error: %v", eventErr)
@@ -2433,7 +2225,7 @@ func (e *KiroExecutor) streamToChannel(ctx context.Context, body io.Reader, out
 			if currentToolUse != nil && !processedIDs[currentToolUse.ToolUseID] {
 				log.Warnf("kiro: flushing incomplete tool use at EOF: %s (ID: %s)", currentToolUse.Name, currentToolUse.ToolUseID)
 				fullInput := currentToolUse.InputBuffer.String()
-				repairedJSON := kiroclaude.RepairJSON(fullInput)
+				repairedJSON := toolemulation.RepairJSON(fullInput)
 				var finalInput map[string]interface{}
 				if err := json.Unmarshal([]byte(repairedJSON), &finalInput); err != nil {
 					log.Warnf("kiro: failed to parse incomplete tool input at EOF: %v", err)
@@ -2754,7 +2546,7 @@ func (e *KiroExecutor) streamToChannel(ctx context.Context, body io.Reader, out
 				if shouldSendUsageUpdate {
 					// Calculate current output tokens using tiktoken
 					var currentOutputTokens int64
-					if enc, encErr := getTokenizer(model); encErr == nil {
+					if enc, encErr := tokencount.Get(model); encErr == nil {
 						if tokenCount, countErr := enc.Count(accumulatedContent.String()); countErr == nil {
 							currentOutputTokens = int64(tokenCount)
 						}
@@ -3358,7 +3150,7 @@ func (e *KiroExecutor) streamToChannel(ctx context.Context, body io.Reader, out
 	// Only use local estimation if server didn't provide usage (server-side usage takes priority)
 	if totalUsage.OutputTokens == 0 && accumulatedContent.Len() > 0 {
 		// Try to use tiktoken for accurate counting
-		if enc, err := getTokenizer(model); err == nil {
+		if enc, err := tokencount.Get(model); err == nil {
 			if tokenCount, countErr := enc.Count(accumulatedContent.String()); countErr == nil {
 				totalUsage.OutputTokens = int64(tokenCount)
 				log.Debugf("kiro: streamToChannel calculated output tokens using tiktoken: %d", totalUsage.OutputTokens)
@@ -3458,7 +3250,7 @@ func (e *KiroExecutor) streamToChannel(ctx context.Context, body io.Reader, out
 // This provides approximate token counts for client requests.
 func (e *KiroExecutor) CountTokens(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
 	// Use tiktoken for local token counting
-	enc, err := getTokenizer(req.Model)
+	enc, err := tokencount.Get(req.Model)
 	if err != nil {
 		log.Warnf("kiro: CountTokens failed to get tokenizer: %v, falling back to estimate", err)
 		// Fallback: estimate from payload size (roughly 4 chars per token)
@@ -3475,7 +3267,7 @@ func (e *KiroExecutor) CountTokens(ctx context.Context, auth *cliproxyauth.Auth,
 	var totalTokens int64
 
 	// Try OpenAI chat format first
-	if tokens, countErr := countOpenAIChatTokens(enc, req.Payload); countErr == nil && tokens > 0 {
+	if tokens, countErr := tokencount.CountOpenAIChat(enc, req.Payload); countErr == nil && tokens > 0 {
 		totalTokens = tokens
 		log.Debugf("kiro: CountTokens counted %d tokens using OpenAI chat format", totalTokens)
 	} else {
@@ -12,11 +12,14 @@ import (
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tokencount"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/toolemulation"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
 	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
 	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
 
@@ -70,6 +73,9 @@ func (e *OpenAICompatExecutor) HttpRequest(ctx context.Context, auth *cliproxyau
 }
 
 func (e *OpenAICompatExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (resp cliproxyexecutor.Response, err error) {
+	if embeddingAction(req) != "" {
+		return e.executeEmbedding(ctx, auth, req, opts)
+	}
 	baseModel := thinking.ParseSuffix(req.Model).ModelName
 
 	reporter := newUsageReporter(ctx, e.Identifier(), baseModel, auth)
@@ -97,9 +103,24 @@ func (e *OpenAICompatExecutor) Execute(ctx context.Context, auth *cliproxyauth.A
 	if err != nil {
 		return resp, err
 	}
+	if err = checkAudioSupport(e.Identifier(), baseModel, translated); err != nil {
+		return resp, err
+	}
+	translated = e.applyClaudePromptCacheKey(from, req, translated)
+	if compat := e.resolveCompatConfig(auth); compat == nil || !compat.SupportsDocuments {
+		if translated, err = applyDocumentFallback(e.cfg, translated); err != nil {
+			return resp, err
+		}
+	}
+	upstreamPayload := translated
+	var emulatedTools []string
+	emulateTools := e.toolEmulationEnabled(auth, baseModel)
+	if emulateTools {
+		upstreamPayload, emulatedTools = toolemulation.Request(translated)
+	}
 
 	url := strings.TrimSuffix(baseURL, "/") + "/chat/completions"
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(translated))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(upstreamPayload))
 	if err != nil {
 		return resp, err
 	}
@@ -123,7 +144,7 @@ func (e *OpenAICompatExecutor) Execute(ctx context.Context, auth *cliproxyauth.A
 		URL:       url,
 		Method:    http.MethodPost,
 		Headers:   httpReq.Header.Clone(),
-		Body:      translated,
+		Body:      upstreamPayload,
 		Provider:  e.Identifier(),
 		AuthID:    authID,
 		AuthLabel: authLabel,
@@ -159,6 +180,9 @@ func (e *OpenAICompatExecutor) Execute(ctx context.Context, auth *cliproxyauth.A
 	reporter.publish(ctx, parseOpenAIUsage(body))
 	// Ensure we at least record the request even if upstream doesn't return usage
 	reporter.ensurePublished(ctx)
+	if emulateTools && len(emulatedTools) > 0 {
+		body = toolemulation.Response(body, emulatedTools)
+	}
 	// Translate response back to source format when needed
 	var param any
 	out := sdktranslator.TranslateNonStream(ctx, to, from, req.Model, bytes.Clone(opts.OriginalRequest), translated, body, &param)
@@ -166,6 +190,46 @@ func (e *OpenAICompatExecutor) Execute(ctx context.Context, auth *cliproxyauth.A
 	return resp, nil
 }
 
+// executeEmbedding passes an OpenAI embeddings request through to the provider's
+// /embeddings endpoint.
+func (e *OpenAICompatExecutor) executeEmbedding(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (resp cliproxyexecutor.Response, err error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+
+	reporter := newUsageReporter(ctx, e.Identifier(), baseModel, auth)
+	defer reporter.trackFailure(ctx, &err)
+
+	if opts.SourceFormat != sdktranslator.FromString("openai-embeddings") {
+		err = statusErr{code: http.StatusBadRequest, msg: fmt.Sprintf("embeddings from %s format are not supported by %s", opts.SourceFormat, e.Identifier())}
+		return resp, err
+	}
+	baseURL, apiKey := e.resolveCredentials(auth)
+	if baseURL == "" {
+		err = statusErr{code: http.StatusUnauthorized, msg: "missing provider baseURL"}
+		return resp, err
+	}
+
+	body := e.overrideModel(bytes.Clone(req.Payload), baseModel)
+	url := strings.TrimSuffix(baseURL, "/") + "/embeddings"
+	data, err := doJSONPost(ctx, e.cfg, auth, e.Identifier(), url, body, func(httpReq *http.Request) error {
+		if apiKey != "" {
+			httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+		httpReq.Header.Set("User-Agent", "cli-proxy-openai-compat")
+		var attrs map[string]string
+		if auth != nil {
+			attrs = auth.Attributes
+		}
+		util.ApplyCustomHeadersFromAttrs(httpReq, attrs)
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+	reporter.publish(ctx, parseOpenAIUsage(data))
+	reporter.ensurePublished(ctx)
+	return cliproxyexecutor.Response{Payload: data}, nil
+}
+
 func (e *OpenAICompatExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (stream <-chan cliproxyexecutor.StreamChunk, err error) {
 	baseModel := thinking.ParseSuffix(req.Model).ModelName
 
@@ -193,9 +257,27 @@ func (e *OpenAICompatExecutor) ExecuteStream(ctx context.Context, auth *cliproxy
 	if err != nil {
 		return nil, err
 	}
+	if err = checkAudioSupport(e.Identifier(), baseModel, translated); err != nil {
+		return nil, err
+	}
+	translated = e.applyClaudePromptCacheKey(from, req, translated)
+	if compat := e.resolveCompatConfig(auth); compat == nil || !compat.SupportsDocuments {
+		if translated, err = applyDocumentFallback(e.cfg, translated); err != nil {
+			return nil, err
+		}
+	}
+	upstreamPayload := translated
+	var emulation *toolemulation.Stream
+	if e.toolEmulationEnabled(auth, baseModel) {
+		var emulatedTools []string
+		upstreamPayload, emulatedTools = toolemulation.Request(translated)
+		if len(emulatedTools) > 0 {
+			emulation = toolemulation.NewStream(emulatedTools)
+		}
+	}
 
 	url := strings.TrimSuffix(baseURL, "/") + "/chat/completions"
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(translated))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(upstreamPayload))
 	if err != nil {
 		return nil, err
 	}
@@ -221,7 +303,7 @@ func (e *OpenAICompatExecutor) ExecuteStream(ctx context.Context, auth *cliproxy
 		URL:       url,
 		Method:    http.MethodPost,
 		Headers:   httpReq.Header.Clone(),
-		Body:      translated,
+		Body:      upstreamPayload,
 		Provider:  e.Identifier(),
 		AuthID:    authID,
 		AuthLabel: authLabel,
@@ -274,9 +356,23 @@ func (e *OpenAICompatExecutor) ExecuteStream(ctx context.Context, auth *cliproxy
 
 			// OpenAI-compatible streams are SSE: lines typically prefixed with "data: ".
 			// Pass through translator; it yields one or more chunks for the target schema.
-			chunks := sdktranslator.TranslateStream(ctx, to, from, req.Model, bytes.Clone(opts.OriginalRequest), translated, bytes.Clone(line), &param)
-			for i := range chunks {
-				out <- cliproxyexecutor.StreamChunk{Payload: []byte(chunks[i])}
+			lines := [][]byte{bytes.Clone(line)}
+			if emulation != nil {
+				lines = emulation.Process(line)
+			}
+			for _, l := range lines {
+				chunks := sdktranslator.TranslateStream(ctx, to, from, req.Model, bytes.Clone(opts.OriginalRequest), translated, l, &param)
+				for i := range chunks {
+					out <- cliproxyexecutor.StreamChunk{Payload: []byte(chunks[i])}
+				}
+			}
+		}
+		if emulation != nil {
+			for _, l := range emulation.Close() {
+				chunks := sdktranslator.TranslateStream(ctx, to, from, req.Model, bytes.Clone(opts.OriginalRequest), translated, l, &param)
+				for i := range chunks {
+					out <- cliproxyexecutor.StreamChunk{Payload: []byte(chunks[i])}
+				}
 			}
 		}
 		if errScan := scanner.Err(); errScan != nil {
@@ -304,12 +400,12 @@ func (e *OpenAICompatExecutor) CountTokens(ctx context.Context, auth *cliproxyau
 		return cliproxyexecutor.Response{}, err
 	}
 
-	enc, err := tokenizerForModel(modelForCounting)
+	enc, err := tokencount.ForModel(modelForCounting)
 	if err != nil {
 		return cliproxyexecutor.Response{}, fmt.Errorf("openai compat executor: tokenizer init failed: %w", err)
 	}
 
-	count, err := countOpenAIChatTokens(enc, translated)
+	count, err := tokencount.CountOpenAIChat(enc, translated)
 	if err != nil {
 		return cliproxyexecutor.Response{}, fmt.Errorf("openai compat executor: token counting failed: %w", err)
 	}
@@ -364,6 +460,36 @@ func (e *OpenAICompatExecutor) resolveCompatConfig(auth *cliproxyauth.Auth) *con
 	return nil
 }
 
+// toolEmulationEnabled reports whether the compat model is configured with tool-emulation.
+func (e *OpenAICompatExecutor) toolEmulationEnabled(auth *cliproxyauth.Auth, model string) bool {
+	compat := e.resolveCompatConfig(auth)
+	if compat == nil {
+		return false
+	}
+	for i := range compat.Models {
+		m := compat.Models[i]
+		if m.ToolEmulation && (strings.EqualFold(m.Name, model) || strings.EqualFold(m.Alias, model)) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyClaudePromptCacheKey sets prompt_cache_key from Claude cache_control breakpoints when
+// prompt-cache.openai-compat-key is enabled and the client did not send a key.
+func (e *OpenAICompatExecutor) applyClaudePromptCacheKey(from sdktranslator.Format, req cliproxyexecutor.Request, translated []byte) []byte {
+	if from != "claude" || e.cfg == nil || !e.cfg.PromptCache.OpenAICompatKey {
+		return translated
+	}
+	if gjson.GetBytes(translated, "prompt_cache_key").Exists() {
+		return translated
+	}
+	if key := claudePromptCacheKey(e.cfg, req.Model, req.Payload); key != "" {
+		translated, _ = sjson.SetBytes(translated, "prompt_cache_key", key)
+	}
+	return translated
+}
+
 func (e *OpenAICompatExecutor) overrideModel(payload []byte, model string) []byte {
 	if len(payload) == 0 || model == "" {
 		return payload
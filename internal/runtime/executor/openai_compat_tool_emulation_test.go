@@ -0,0 +1,74 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+func TestOpenAICompatToolEmulationToClaude(t *testing.T) {
+	const reply = `I'll look it up.\n[Called get_weather with args: {\"city\": \"Paris\"}]`
+	var upstream []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstream, _ = io.ReadAll(r.Body)
+		if gjson.GetBytes(upstream, "stream").Bool() {
+			w.Header().Set("Content-Type", "text/event-stream")
+			for _, delta := range []string{`I'll look it up.\n[Called get_wea`, `ther with args: {\"city\": \"Paris\"}]`} {
+				_, _ = io.WriteString(w, `data: {"id":"c1","model":"base","choices":[{"index":0,"delta":{"content":"`+delta+`"},"finish_reason":null}]}`+"\n\n")
+			}
+			_, _ = io.WriteString(w, `data: {"id":"c1","model":"base","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`+"\n\ndata: [DONE]\n\n")
+			return
+		}
+		_, _ = io.WriteString(w, `{"id":"c1","model":"base","choices":[{"index":0,"message":{"role":"assistant","content":"`+reply+`"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{OpenAICompatibility: []config.OpenAICompatibility{{
+		Name:   "emu",
+		Models: []config.OpenAICompatibilityModel{{Name: "base", ToolEmulation: true}},
+	}}}
+	exec := NewOpenAICompatExecutor("emu", cfg)
+	auth := &cliproxyauth.Auth{ID: "emu-test", Provider: "emu", Attributes: map[string]string{"api_key": "key", "base_url": server.URL, "compat_name": "emu"}}
+	payload := []byte(`{"model":"base","max_tokens":100,"tools":[{"name":"get_weather","description":"Current weather","input_schema":{"type":"object","properties":{"city":{"type":"string"}}}}],"messages":[{"role":"user","content":"Weather in Paris?"}]}`)
+	opts := cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("claude"), OriginalRequest: payload}
+
+	resp, err := exec.Execute(context.Background(), auth, cliproxyexecutor.Request{Model: "base", Payload: payload}, opts)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if gjson.GetBytes(upstream, "tools").Exists() || !strings.Contains(gjson.GetBytes(upstream, "messages.0.content").String(), "get_weather") {
+		t.Fatalf("upstream request not emulated: %s", upstream)
+	}
+	out := gjson.ParseBytes(resp.Payload)
+	if out.Get("stop_reason").String() != "tool_use" || out.Get("content.1.type").String() != "tool_use" || out.Get("content.1.input.city").String() != "Paris" {
+		t.Fatalf("claude response = %s", resp.Payload)
+	}
+
+	payload, _ = sjson.SetBytes(payload, "stream", true)
+	opts.Stream, opts.OriginalRequest = true, payload
+	stream, err := exec.ExecuteStream(context.Background(), auth, cliproxyexecutor.Request{Model: "base", Payload: payload}, opts)
+	if err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	var events strings.Builder
+	for chunk := range stream {
+		if chunk.Err != nil {
+			t.Fatalf("stream error = %v", chunk.Err)
+		}
+		events.Write(chunk.Payload)
+	}
+	if got := events.String(); !strings.Contains(got, `"type":"tool_use"`) || !strings.Contains(got, `"stop_reason":"tool_use"`) || strings.Contains(got, "[Called") {
+		t.Fatalf("claude stream = %s", got)
+	}
+}
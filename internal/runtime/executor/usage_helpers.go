@@ -9,7 +9,9 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
 	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
@@ -73,7 +75,7 @@ func (r *usageReporter) publishWithOutcome(ctx context.Context, detail usage.Det
 		return
 	}
 	r.once.Do(func() {
-		usage.PublishRecord(ctx, usage.Record{
+		record := usage.Record{
 			Provider:    r.provider,
 			Model:       r.model,
 			Source:      r.source,
@@ -83,7 +85,8 @@ func (r *usageReporter) publishWithOutcome(ctx context.Context, detail usage.Det
 			RequestedAt: r.requestedAt,
 			Failed:      failed,
 			Detail:      detail,
-		})
+		}
+		publishUsageRecord(ctx, record)
 	})
 }
 
@@ -96,7 +99,7 @@ func (r *usageReporter) ensurePublished(ctx context.Context) {
 		return
 	}
 	r.once.Do(func() {
-		usage.PublishRecord(ctx, usage.Record{
+		record := usage.Record{
 			Provider:    r.provider,
 			Model:       r.model,
 			Source:      r.source,
@@ -106,7 +109,38 @@ func (r *usageReporter) ensurePublished(ctx context.Context) {
 			RequestedAt: r.requestedAt,
 			Failed:      false,
 			Detail:      usage.Detail{},
-		})
+		}
+		publishUsageRecord(ctx, record)
+	})
+}
+
+// publishUsageRecord publishes record immediately, or hands it to the request's stream
+// tracker so it is published with stream timing metrics once the stream completes.
+func publishUsageRecord(ctx context.Context, record usage.Record) {
+	recordRequestLogUsage(ctx, record)
+	if tracker := usage.StreamTrackerFromContext(ctx); tracker != nil && tracker.Hold(ctx, record) {
+		return
+	}
+	usage.PublishRecord(ctx, record)
+}
+
+// recordRequestLogUsage exposes the record to the request logging middleware so the
+// request log index can show which auth and upstream model served the request.
+func recordRequestLogUsage(ctx context.Context, record usage.Record) {
+	ginCtx := ginContextFrom(ctx)
+	if ginCtx == nil {
+		return
+	}
+	logging.SetGinRequestUsage(ginCtx, logging.RequestUsage{
+		Provider:        record.Provider,
+		Model:           record.Model,
+		AuthID:          record.AuthID,
+		AuthIndex:       record.AuthIndex,
+		InputTokens:     record.Detail.InputTokens,
+		OutputTokens:    record.Detail.OutputTokens,
+		ReasoningTokens: record.Detail.ReasoningTokens,
+		CachedTokens:    record.Detail.CachedTokens,
+		TotalTokens:     record.Detail.TotalTokens,
 	})
 }
 
@@ -114,6 +148,9 @@ func apiKeyFromContext(ctx context.Context) string {
 	if ctx == nil {
 		return ""
 	}
+	if key := cliproxyexecutor.ClientAPIKey(ctx); key != "" {
+		return key
+	}
 	ginCtx, ok := ctx.Value("gin").(*gin.Context)
 	if !ok || ginCtx == nil {
 		return ""
@@ -313,6 +350,28 @@ func parseClaudeStreamUsage(line []byte) (usage.Detail, bool) {
 	return detail, true
 }
 
+// parseBedrockUsage reads a Converse usage object, found at "usage" of a Converse
+// response and of the metadata stream event.
+func parseBedrockUsage(usageNode gjson.Result) (usage.Detail, bool) {
+	if !usageNode.Exists() {
+		return usage.Detail{}, false
+	}
+	detail := usage.Detail{
+		InputTokens:  usageNode.Get("inputTokens").Int(),
+		OutputTokens: usageNode.Get("outputTokens").Int(),
+		CachedTokens: usageNode.Get("cacheReadInputTokens").Int(),
+		TotalTokens:  usageNode.Get("totalTokens").Int(),
+	}
+	if detail.CachedTokens == 0 {
+		// fall back to cache write tokens when read tokens are absent
+		detail.CachedTokens = usageNode.Get("cacheWriteInputTokens").Int()
+	}
+	if detail.TotalTokens == 0 {
+		detail.TotalTokens = detail.InputTokens + detail.OutputTokens
+	}
+	return detail, true
+}
+
 func parseGeminiFamilyUsageDetail(node gjson.Result) usage.Detail {
 	detail := usage.Detail{
 		InputTokens:     node.Get("promptTokenCount").Int(),
@@ -584,3 +643,8 @@ func jsonPayload(line []byte) []byte {
 	}
 	return trimmed
 }
+
+// buildOpenAIUsageJSON returns a minimal usage structure understood by downstream translators.
+func buildOpenAIUsageJSON(count int64) []byte {
+	return []byte(fmt.Sprintf(`{"usage":{"prompt_tokens":%d,"completion_tokens":0,"total_tokens":%d}}`, count, count))
+}
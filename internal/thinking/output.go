@@ -0,0 +1,403 @@
+package thinking
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Reasoning output modes of an OutputPolicy.
+const (
+	// OutputNative returns reasoning in the client format's own representation.
+	OutputNative = "native"
+	// OutputField moves reasoning into a plain string field named by OutputPolicy.Field.
+	OutputField = "field"
+	// OutputTags wraps reasoning in tags at the start of the visible content.
+	OutputTags = "tags"
+	// OutputSummarize cuts reasoning to OutputPolicy.SummaryChars. It stays native except
+	// for Claude, where the summary is sent as a text block because a cut thinking block
+	// has no valid signature.
+	OutputSummarize = "summarize"
+	// OutputStrip removes reasoning.
+	OutputStrip = "strip"
+)
+
+const (
+	defaultOutputField        = "reasoning"
+	defaultOutputTag          = "think"
+	defaultOutputSummaryChars = 280
+)
+
+// OutputPolicy controls how reasoning in responses is returned to the client. It is
+// applied to translated responses, so it behaves the same whichever provider served them.
+//
+// OpenAI Chat Completions carries reasoning in reasoning_content (or reasoning), Claude in
+// thinking blocks, Gemini in thought parts and the Responses API in reasoning items. In
+// field mode the reasoning text moves to Field on the message (OpenAI), the message or
+// message_delta (Claude), the candidate (Gemini) or the response (Responses).
+//
+// Every mode but native is lossy for multi-turn Claude conversations: clients cannot send
+// back the signed thinking blocks, so Claude rejects a replayed tool-use turn when thinking
+// is enabled.
+type OutputPolicy struct {
+	// Mode is one of the Output* modes; "" means OutputNative.
+	Mode string
+	// Field names the field used by OutputField; "" means "reasoning".
+	Field string
+	// Tag names the tag used by OutputTags; "" means "think".
+	Tag string
+	// SummaryChars bounds the reasoning kept by OutputSummarize; <= 0 means 280.
+	SummaryChars int
+}
+
+// Active reports whether the policy changes responses at all.
+func (p OutputPolicy) Active() bool {
+	switch strings.ToLower(strings.TrimSpace(p.Mode)) {
+	case OutputField, OutputTags, OutputSummarize, OutputStrip:
+		return true
+	}
+	return false
+}
+
+func (p OutputPolicy) normalized() OutputPolicy {
+	p.Mode = strings.ToLower(strings.TrimSpace(p.Mode))
+	if p.Field = strings.TrimSpace(p.Field); p.Field == "" {
+		p.Field = defaultOutputField
+	}
+	if p.Tag = strings.Trim(strings.TrimSpace(p.Tag), "<>"); p.Tag == "" {
+		p.Tag = defaultOutputTag
+	}
+	if p.SummaryChars <= 0 {
+		p.SummaryChars = defaultOutputSummaryChars
+	}
+	return p
+}
+
+func (p OutputPolicy) open() string  { return "<" + p.Tag + ">" }
+func (p OutputPolicy) close() string { return "</" + p.Tag + ">\n\n" }
+
+// wrap returns reasoning wrapped in the policy tags.
+func (p OutputPolicy) wrap(reasoning string) string {
+	return p.open() + reasoning + p.close()
+}
+
+// summarize cuts reasoning to SummaryChars, marking the cut with an ellipsis. Streams cut
+// the same way, so both modes return the same summary.
+func (p OutputPolicy) summarize(reasoning string) string {
+	if utf8.RuneCountInString(reasoning) <= p.SummaryChars {
+		return reasoning
+	}
+	return strings.TrimRight(string([]rune(reasoning)[:p.SummaryChars]), " \n\t") + " …"
+}
+
+// ApplyOutputPolicy rewrites the reasoning of a non-streaming response in the given client
+// format ("openai", "claude", "gemini", "gemini-cli" or "openai-response"). Other formats
+// and inactive policies leave the payload unchanged.
+func ApplyOutputPolicy(format string, payload []byte, policy OutputPolicy) []byte {
+	if !policy.Active() || !gjson.ValidBytes(payload) {
+		return payload
+	}
+	policy = policy.normalized()
+	switch format {
+	case "openai":
+		return applyOpenAIOutput(payload, policy)
+	case "claude":
+		return applyClaudeOutput(payload, policy)
+	case "gemini", "gemini-cli":
+		return applyGeminiOutput(payload, policy)
+	case "openai-response":
+		return applyResponsesOutput(payload, policy)
+	}
+	return payload
+}
+
+// openAIReasoning returns the reasoning text of an OpenAI message or delta.
+func openAIReasoning(message gjson.Result) string {
+	if r := message.Get("reasoning_content"); r.Type == gjson.String {
+		return r.String()
+	}
+	if r := message.Get("reasoning"); r.Type == gjson.String {
+		return r.String()
+	}
+	return ""
+}
+
+// deleteOpenAIReasoning removes the string reasoning fields under path.
+func deleteOpenAIReasoning(payload []byte, path string) []byte {
+	payload, _ = sjson.DeleteBytes(payload, path+".reasoning_content")
+	if gjson.GetBytes(payload, path+".reasoning").Type == gjson.String {
+		payload, _ = sjson.DeleteBytes(payload, path+".reasoning")
+	}
+	return payload
+}
+
+func applyOpenAIOutput(payload []byte, p OutputPolicy) []byte {
+	gjson.GetBytes(payload, "choices").ForEach(func(key, choice gjson.Result) bool {
+		path := "choices." + key.String() + ".message"
+		reasoning := openAIReasoning(choice.Get("message"))
+		if reasoning == "" {
+			return true
+		}
+		payload = deleteOpenAIReasoning(payload, path)
+		switch p.Mode {
+		case OutputField:
+			payload, _ = sjson.SetBytes(payload, path+"."+p.Field, reasoning)
+		case OutputTags:
+			payload, _ = sjson.SetBytes(payload, path+".content", p.wrap(reasoning)+choice.Get("message.content").String())
+		case OutputSummarize:
+			payload, _ = sjson.SetBytes(payload, path+".reasoning_content", p.summarize(reasoning))
+		}
+		return true
+	})
+	return payload
+}
+
+func applyClaudeOutput(payload []byte, p OutputPolicy) []byte {
+	content := gjson.GetBytes(payload, "content")
+	if !content.IsArray() {
+		return payload
+	}
+	var blocks []string
+	var collected []string
+	changed := false
+	content.ForEach(func(_, block gjson.Result) bool {
+		switch block.Get("type").String() {
+		case "thinking":
+			changed = true
+			text := block.Get("thinking").String()
+			switch p.Mode {
+			case OutputField:
+				collected = append(collected, text)
+			case OutputTags:
+				tagged, _ := sjson.Set(`{"type":"text","text":""}`, "text", p.wrap(text))
+				blocks = append(blocks, tagged)
+			case OutputSummarize:
+				summary, _ := sjson.Set(`{"type":"text","text":""}`, "text", p.summarize(text))
+				blocks = append(blocks, summary)
+			}
+		case "redacted_thinking":
+			if p.Mode == OutputSummarize {
+				blocks = append(blocks, block.Raw)
+			} else {
+				changed = true
+			}
+		default:
+			blocks = append(blocks, block.Raw)
+		}
+		return true
+	})
+	if !changed {
+		return payload
+	}
+	payload, _ = sjson.SetRawBytes(payload, "content", []byte("["+strings.Join(blocks, ",")+"]"))
+	if p.Mode == OutputField && len(collected) > 0 {
+		payload, _ = sjson.SetBytes(payload, p.Field, strings.Join(collected, "\n\n"))
+	}
+	return payload
+}
+
+// geminiCandidatesPath returns the path of the candidates array, which gemini-cli responses
+// nest under "response".
+func geminiCandidatesPath(payload []byte) string {
+	if gjson.GetBytes(payload, "response.candidates").Exists() {
+		return "response.candidates"
+	}
+	return "candidates"
+}
+
+func applyGeminiOutput(payload []byte, p OutputPolicy) []byte {
+	candidatesPath := geminiCandidatesPath(payload)
+	gjson.GetBytes(payload, candidatesPath).ForEach(func(key, candidate gjson.Result) bool {
+		path := candidatesPath + "." + key.String()
+		parts := candidate.Get("content.parts")
+		var kept []string
+		var collected []string
+		tagAt := -1
+		changed := false
+		parts.ForEach(func(_, part gjson.Result) bool {
+			if !part.Get("thought").Bool() {
+				kept = append(kept, part.Raw)
+				return true
+			}
+			changed = true
+			text := part.Get("text").String()
+			switch p.Mode {
+			case OutputField:
+				collected = append(collected, text)
+			case OutputTags:
+				if tagAt < 0 {
+					tagAt = len(kept)
+				}
+				collected = append(collected, text)
+			case OutputSummarize:
+				collected = append(collected, text)
+				kept = append(kept, part.Raw)
+			}
+			return true
+		})
+		if !changed {
+			return true
+		}
+		reasoning := strings.Join(collected, "")
+		switch p.Mode {
+		case OutputField:
+			payload, _ = sjson.SetBytes(payload, path+"."+p.Field, reasoning)
+		case OutputTags:
+			tagged, _ := sjson.Set(`{"text":""}`, "text", p.wrap(reasoning))
+			kept = append(kept[:tagAt], append([]string{tagged}, kept[tagAt:]...)...)
+		case OutputSummarize:
+			kept = summarizeGeminiParts(kept, p)
+		}
+		payload, _ = sjson.SetRawBytes(payload, path+".content.parts", []byte("["+strings.Join(kept, ",")+"]"))
+		return true
+	})
+	return payload
+}
+
+// summarizeGeminiParts cuts the text of thought parts to the summary budget, dropping the
+// thought parts past it.
+func summarizeGeminiParts(parts []string, p OutputPolicy) []string {
+	budget := p.SummaryChars
+	out := parts[:0]
+	for _, raw := range parts {
+		part := gjson.Parse(raw)
+		if !part.Get("thought").Bool() {
+			out = append(out, raw)
+			continue
+		}
+		if budget <= 0 {
+			continue
+		}
+		text := part.Get("text").String()
+		if n := utf8.RuneCountInString(text); n > budget {
+			summary := p
+			summary.SummaryChars = budget
+			text = summary.summarize(text)
+			budget = 0
+		} else {
+			budget -= n
+		}
+		raw, _ = sjson.Set(raw, "text", text)
+		out = append(out, raw)
+	}
+	return out
+}
+
+// responsesReasoningText joins the summary and content texts of a Responses reasoning item.
+func responsesReasoningText(item gjson.Result) string {
+	var texts []string
+	for _, field := range []string{"summary", "content"} {
+		item.Get(field).ForEach(func(_, part gjson.Result) bool {
+			if text := part.Get("text").String(); text != "" {
+				texts = append(texts, text)
+			}
+			return true
+		})
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+func applyResponsesOutput(payload []byte, p OutputPolicy) []byte {
+	path := "output"
+	if !gjson.GetBytes(payload, path).Exists() && gjson.GetBytes(payload, "response.output").Exists() {
+		path = "response.output"
+	}
+	output, reasoning := filterResponsesOutput(gjson.GetBytes(payload, path), p, "")
+	if output == "" {
+		return payload
+	}
+	payload, _ = sjson.SetRawBytes(payload, path, []byte(output))
+	if p.Mode == OutputField && reasoning != "" {
+		payload, _ = sjson.SetBytes(payload, strings.TrimSuffix(path, "output")+p.Field, reasoning)
+	}
+	return payload
+}
+
+// filterResponsesOutput applies the policy to a Responses output array and returns the new
+// array, or "" when it has no reasoning, with the collected reasoning text. In tags mode
+// the reasoning, after any streamed prefix, is put in front of the first output_text.
+func filterResponsesOutput(output gjson.Result, p OutputPolicy, prefix string) (string, string) {
+	var items []string
+	var collected []string
+	changed := false
+	output.ForEach(func(_, item gjson.Result) bool {
+		if item.Get("type").String() != "reasoning" {
+			items = append(items, item.Raw)
+			return true
+		}
+		changed = true
+		text := responsesReasoningText(item)
+		if text != "" {
+			collected = append(collected, text)
+		}
+		if p.Mode == OutputSummarize {
+			items = append(items, summarizeResponsesItem(item, p))
+		}
+		return true
+	})
+	if !changed && prefix == "" {
+		return "", ""
+	}
+	reasoning := strings.Join(collected, "\n\n")
+	if p.Mode == OutputTags {
+		if prefix == "" && reasoning != "" {
+			prefix = p.wrap(reasoning)
+		}
+		items = prefixResponsesText(items, prefix)
+	}
+	return "[" + strings.Join(items, ",") + "]", reasoning
+}
+
+// prefixResponsesText puts prefix in front of the first output_text of the first message.
+func prefixResponsesText(items []string, prefix string) []string {
+	if prefix == "" {
+		return items
+	}
+	for i, raw := range items {
+		item := gjson.Parse(raw)
+		if item.Get("type").String() != "message" {
+			continue
+		}
+		done := false
+		item.Get("content").ForEach(func(key, part gjson.Result) bool {
+			if part.Get("type").String() == "output_text" {
+				raw, _ = sjson.Set(raw, "content."+key.String()+".text", prefix+part.Get("text").String())
+				done = true
+			}
+			return !done
+		})
+		if done {
+			items[i] = raw
+			break
+		}
+	}
+	return items
+}
+
+// summarizeResponsesItem cuts the texts of a reasoning item to the summary budget.
+func summarizeResponsesItem(item gjson.Result, p OutputPolicy) string {
+	raw := item.Raw
+	budget := p.SummaryChars
+	for _, field := range []string{"summary", "content"} {
+		item.Get(field).ForEach(func(key, part gjson.Result) bool {
+			text := part.Get("text").String()
+			n := utf8.RuneCountInString(text)
+			switch {
+			case budget <= 0:
+				text = ""
+			case n > budget:
+				summary := p
+				summary.SummaryChars = budget
+				text = summary.summarize(text)
+				budget = 0
+			default:
+				budget -= n
+			}
+			raw, _ = sjson.Set(raw, field+"."+key.String()+".text", text)
+			return true
+		})
+	}
+	return raw
+}
@@ -0,0 +1,570 @@
+package thinking
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// claudeBlock is how a streamed Claude content block is rewritten.
+type claudeBlock int
+
+const (
+	claudeBlockKept claudeBlock = iota
+	claudeBlockDropped
+	claudeBlockTagged
+	claudeBlockSummarized
+)
+
+// OutputStream applies an OutputPolicy to a streamed response in a client format, one
+// chunk at a time. Chunks may hold several SSE lines, or a single line as in passthrough
+// streams; an "event:" line is held until its data line arrives. Dropping Claude thinking
+// blocks or Responses reasoning items renumbers the blocks and output items that follow.
+type OutputStream struct {
+	format       string
+	policy       OutputPolicy
+	pendingEvent string
+
+	// tagOpen and used track tags mode and the summary budget per choice, candidate or
+	// output item.
+	tagOpen map[int]bool
+	used    map[int]int
+
+	// Claude content blocks by upstream index.
+	blocks      map[int]claudeBlock
+	blockIndex  map[int]int
+	droppedSeen int
+
+	// Responses reasoning items by upstream output index, and the reasoning text sent or
+	// held back.
+	reasoningItems map[int]bool
+	emitted        map[string]*strings.Builder
+	collected      strings.Builder
+	prefix         string
+	taggedItem     string
+}
+
+// NewOutputStream returns a stream rewriter for the client format, or nil when the policy
+// is inactive or the format has no reasoning to rewrite.
+func NewOutputStream(format string, policy OutputPolicy) *OutputStream {
+	if !policy.Active() {
+		return nil
+	}
+	switch format {
+	case "openai", "claude", "gemini", "gemini-cli", "openai-response":
+	default:
+		return nil
+	}
+	return &OutputStream{
+		format:         format,
+		policy:         policy.normalized(),
+		tagOpen:        make(map[int]bool),
+		used:           make(map[int]int),
+		blocks:         make(map[int]claudeBlock),
+		blockIndex:     make(map[int]int),
+		reasoningItems: make(map[int]bool),
+		emitted:        make(map[string]*strings.Builder),
+	}
+}
+
+// Process rewrites one stream chunk. It returns nil when nothing is left to send.
+func (s *OutputStream) Process(chunk []byte) []byte {
+	text := string(chunk)
+	trailing := strings.HasSuffix(text, "\n")
+	text = strings.TrimSuffix(text, "\n")
+
+	var out []string
+	sent := false
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "event:") {
+			if s.pendingEvent != "" {
+				out = append(out, s.pendingEvent)
+				sent = true
+			}
+			s.pendingEvent = line
+			continue
+		}
+		data, prefixed := trimmed, false
+		if strings.HasPrefix(trimmed, "data:") {
+			data, prefixed = strings.TrimSpace(trimmed[len("data:"):]), true
+		}
+		if !strings.HasPrefix(data, "{") || !gjson.Valid(data) {
+			if s.pendingEvent != "" {
+				out = append(out, s.pendingEvent)
+				s.pendingEvent = ""
+				sent = true
+			}
+			out = append(out, line)
+			sent = sent || trimmed != ""
+			continue
+		}
+
+		event := s.pendingEvent
+		s.pendingEvent = ""
+		for i, result := range s.rewrite(data) {
+			if i > 0 {
+				out = append(out, "")
+			}
+			if event != "" {
+				if typ := gjson.Get(result, "type").String(); typ != "" {
+					out = append(out, "event: "+typ)
+				} else {
+					out = append(out, event)
+				}
+			}
+			if prefixed {
+				out = append(out, "data: "+result)
+			} else {
+				out = append(out, result)
+			}
+			sent = true
+		}
+	}
+	if !sent {
+		return nil
+	}
+	result := strings.Join(out, "\n")
+	if trailing {
+		result += "\n"
+	}
+	return []byte(result)
+}
+
+// rewrite applies the policy to one JSON event and returns the events to send instead.
+func (s *OutputStream) rewrite(data string) []string {
+	switch s.format {
+	case "openai":
+		return s.rewriteOpenAI(data)
+	case "claude":
+		return s.rewriteClaude(data)
+	case "gemini", "gemini-cli":
+		return s.rewriteGemini(data)
+	case "openai-response":
+		return s.rewriteResponses(data)
+	}
+	return []string{data}
+}
+
+// budget returns the part of a reasoning delta that fits the summary budget of key,
+// marking the cut with an ellipsis.
+func (s *OutputStream) budget(key int, text string) string {
+	used, limit := s.used[key], s.policy.SummaryChars
+	if used >= limit {
+		return ""
+	}
+	n := utf8.RuneCountInString(text)
+	if used+n <= limit {
+		s.used[key] = used + n
+		return text
+	}
+	s.used[key] = limit
+	return strings.TrimRight(string([]rune(text)[:limit-used]), " \n\t") + " …"
+}
+
+func (s *OutputStream) rewriteOpenAI(data string) []string {
+	choices := gjson.Get(data, "choices")
+	if !choices.IsArray() {
+		return []string{data}
+	}
+	changed := false
+	choices.ForEach(func(key, choice gjson.Result) bool {
+		i := int(choice.Get("index").Int())
+		path := "choices." + key.String() + ".delta"
+		delta := choice.Get("delta")
+		reasoning := openAIReasoning(delta)
+		if reasoning == "" && !s.tagOpen[i] {
+			return true
+		}
+		changed = true
+		payload := deleteOpenAIReasoning([]byte(data), path)
+		switch s.policy.Mode {
+		case OutputField:
+			if reasoning != "" {
+				payload, _ = sjson.SetBytes(payload, path+"."+s.policy.Field, reasoning)
+			}
+		case OutputSummarize:
+			if summary := s.budget(i, reasoning); summary != "" {
+				payload, _ = sjson.SetBytes(payload, path+".reasoning_content", summary)
+			}
+		case OutputTags:
+			var content strings.Builder
+			if reasoning != "" {
+				if !s.tagOpen[i] {
+					content.WriteString(s.policy.open())
+					s.tagOpen[i] = true
+				}
+				content.WriteString(reasoning)
+			}
+			finish := choice.Get("finish_reason")
+			if s.tagOpen[i] && (delta.Get("content").String() != "" || delta.Get("tool_calls").Exists() || (finish.Exists() && finish.Type != gjson.Null)) {
+				content.WriteString(s.policy.close())
+				s.tagOpen[i] = false
+			}
+			content.WriteString(delta.Get("content").String())
+			if content.Len() > 0 {
+				payload, _ = sjson.SetBytes(payload, path+".content", content.String())
+			}
+		}
+		data = string(payload)
+		return true
+	})
+	if !changed {
+		return []string{data}
+	}
+	// Drop chunks that only carried reasoning.
+	result := gjson.Parse(data)
+	if len(result.Get("choices").Array()) == 1 && !result.Get("usage").Exists() {
+		finish := result.Get("choices.0.finish_reason")
+		if (!finish.Exists() || finish.Type == gjson.Null) && !hasValues(result.Get("choices.0.delta")) {
+			return nil
+		}
+	}
+	return []string{data}
+}
+
+func (s *OutputStream) rewriteClaude(data string) []string {
+	event := gjson.Parse(data)
+	index := int(event.Get("index").Int())
+	switch event.Get("type").String() {
+	case "message_start":
+		s.blocks = make(map[int]claudeBlock)
+		s.blockIndex = make(map[int]int)
+		s.droppedSeen = 0
+		s.used = make(map[int]int)
+		s.collected.Reset()
+		return []string{data}
+	case "content_block_start":
+		kind := claudeBlockKept
+		switch event.Get("content_block.type").String() {
+		case "thinking":
+			switch s.policy.Mode {
+			case OutputTags:
+				kind = claudeBlockTagged
+			case OutputSummarize:
+				kind = claudeBlockSummarized
+			default:
+				kind = claudeBlockDropped
+			}
+		case "redacted_thinking":
+			if s.policy.Mode != OutputSummarize {
+				kind = claudeBlockDropped
+			}
+		}
+		s.blocks[index] = kind
+		if kind == claudeBlockDropped {
+			s.droppedSeen++
+			return nil
+		}
+		s.blockIndex[index] = index - s.droppedSeen
+		switch kind {
+		case claudeBlockTagged:
+			start, _ := sjson.Set(`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`, "index", s.blockIndex[index])
+			return []string{start, s.claudeTextDelta(index, s.policy.open())}
+		case claudeBlockSummarized:
+			start, _ := sjson.Set(`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`, "index", s.blockIndex[index])
+			return []string{start}
+		}
+		return []string{s.claudeIndex(data, index)}
+	case "content_block_delta":
+		deltaType := event.Get("delta.type").String()
+		switch s.blocks[index] {
+		case claudeBlockDropped:
+			if deltaType == "thinking_delta" && s.policy.Mode == OutputField {
+				s.collected.WriteString(event.Get("delta.thinking").String())
+			}
+			return nil
+		case claudeBlockTagged:
+			if deltaType != "thinking_delta" {
+				return nil
+			}
+			return []string{s.claudeTextDelta(index, event.Get("delta.thinking").String())}
+		case claudeBlockSummarized:
+			if deltaType != "thinking_delta" {
+				return nil
+			}
+			if summary := s.budget(0, event.Get("delta.thinking").String()); summary != "" {
+				return []string{s.claudeTextDelta(index, summary)}
+			}
+			return nil
+		}
+		return []string{s.claudeIndex(data, index)}
+	case "content_block_stop":
+		switch s.blocks[index] {
+		case claudeBlockDropped:
+			if s.policy.Mode == OutputField && s.collected.Len() > 0 {
+				s.collected.WriteString("\n\n")
+			}
+			return nil
+		case claudeBlockTagged:
+			return []string{s.claudeTextDelta(index, s.policy.close()), s.claudeIndex(data, index)}
+		}
+		return []string{s.claudeIndex(data, index)}
+	case "message_delta":
+		if s.policy.Mode == OutputField && s.collected.Len() > 0 {
+			data, _ = sjson.Set(data, "delta."+s.policy.Field, strings.TrimSpace(s.collected.String()))
+			s.collected.Reset()
+		}
+	}
+	return []string{data}
+}
+
+// claudeIndex sets the client-side block index of an event.
+func (s *OutputStream) claudeIndex(data string, index int) string {
+	clientIndex, ok := s.blockIndex[index]
+	if !ok {
+		clientIndex = index - s.droppedSeen
+	}
+	if clientIndex == index {
+		return data
+	}
+	data, _ = sjson.Set(data, "index", clientIndex)
+	return data
+}
+
+func (s *OutputStream) claudeTextDelta(index int, text string) string {
+	delta, _ := sjson.Set(`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":""}}`, "index", s.blockIndex[index])
+	delta, _ = sjson.Set(delta, "delta.text", text)
+	return delta
+}
+
+func (s *OutputStream) rewriteGemini(data string) []string {
+	payload := []byte(data)
+	candidatesPath := geminiCandidatesPath(payload)
+	candidates := gjson.GetBytes(payload, candidatesPath)
+	if !candidates.IsArray() {
+		return []string{data}
+	}
+	changed := false
+	empty := true
+	candidates.ForEach(func(key, candidate gjson.Result) bool {
+		i := int(key.Int())
+		if idx := candidate.Get("index"); idx.Exists() {
+			i = int(idx.Int())
+		}
+		path := candidatesPath + "." + key.String()
+		var parts []string
+		var collected strings.Builder
+		touched := false
+		candidate.Get("content.parts").ForEach(func(_, part gjson.Result) bool {
+			if part.Get("thought").Bool() {
+				touched = true
+				text := part.Get("text").String()
+				switch s.policy.Mode {
+				case OutputField:
+					collected.WriteString(text)
+				case OutputTags:
+					if !s.tagOpen[i] {
+						text = s.policy.open() + text
+						s.tagOpen[i] = true
+					}
+					tagged, _ := sjson.Set(`{"text":""}`, "text", text)
+					parts = append(parts, tagged)
+				case OutputSummarize:
+					if summary := s.budget(i, text); summary != "" {
+						raw, _ := sjson.Set(part.Raw, "text", summary)
+						parts = append(parts, raw)
+					}
+				}
+				return true
+			}
+			if s.tagOpen[i] {
+				touched = true
+				s.tagOpen[i] = false
+				if text := part.Get("text"); text.Exists() {
+					raw, _ := sjson.Set(part.Raw, "text", s.policy.close()+text.String())
+					parts = append(parts, raw)
+					return true
+				}
+				closing, _ := sjson.Set(`{"text":""}`, "text", s.policy.close())
+				parts = append(parts, closing)
+			}
+			parts = append(parts, part.Raw)
+			return true
+		})
+		if s.tagOpen[i] && candidate.Get("finishReason").Exists() {
+			touched = true
+			s.tagOpen[i] = false
+			closing, _ := sjson.Set(`{"text":""}`, "text", s.policy.close())
+			parts = append(parts, closing)
+		}
+		if len(parts) > 0 || candidate.Get("finishReason").Exists() {
+			empty = false
+		}
+		if !touched {
+			return true
+		}
+		changed = true
+		payload, _ = sjson.SetRawBytes(payload, path+".content.parts", []byte("["+strings.Join(parts, ",")+"]"))
+		if collected.Len() > 0 {
+			payload, _ = sjson.SetBytes(payload, path+"."+s.policy.Field, collected.String())
+			empty = false
+		}
+		return true
+	})
+	if !changed {
+		return []string{data}
+	}
+	if empty {
+		return nil
+	}
+	return []string{string(payload)}
+}
+
+func (s *OutputStream) rewriteResponses(data string) []string {
+	event := gjson.Parse(data)
+	typ := event.Get("type").String()
+	outputIndex := event.Get("output_index")
+	index := int(outputIndex.Int())
+
+	if s.policy.Mode == OutputSummarize {
+		if data = s.summarizeResponsesEvent(data, event, typ, index); data == "" {
+			return nil
+		}
+		return []string{data}
+	}
+
+	if typ == "response.output_item.added" && event.Get("item.type").String() == "reasoning" {
+		s.reasoningItems[index] = true
+	}
+	if outputIndex.Exists() && s.reasoningItems[index] {
+		switch typ {
+		case "response.reasoning_summary_part.added":
+			if s.collected.Len() > 0 {
+				s.collected.WriteString("\n\n")
+			}
+		case "response.reasoning_summary_text.delta", "response.reasoning_text.delta":
+			s.collected.WriteString(event.Get("delta").String())
+		}
+		return nil
+	}
+	if outputIndex.Exists() {
+		if shift := s.reasoningBefore(index); shift > 0 {
+			data, _ = sjson.Set(data, "output_index", index-shift)
+		}
+	}
+
+	switch typ {
+	case "response.output_text.delta":
+		if s.policy.Mode == OutputTags && s.prefix == "" && s.collected.Len() > 0 {
+			s.prefix = s.policy.wrap(strings.TrimSpace(s.collected.String()))
+			s.taggedItem = event.Get("item_id").String()
+			data, _ = sjson.Set(data, "delta", s.prefix+event.Get("delta").String())
+		}
+	case "response.output_text.done":
+		if s.isTagged(event.Get("item_id").String()) {
+			data, _ = sjson.Set(data, "text", s.prefix+event.Get("text").String())
+		}
+	case "response.content_part.done":
+		if s.isTagged(event.Get("item_id").String()) && event.Get("part.type").String() == "output_text" {
+			data, _ = sjson.Set(data, "part.text", s.prefix+event.Get("part.text").String())
+		}
+	case "response.output_item.done":
+		if s.isTagged(event.Get("item.id").String()) {
+			items := prefixResponsesText([]string{event.Get("item").Raw}, s.prefix)
+			data, _ = sjson.SetRaw(data, "item", items[0])
+		}
+	case "response.completed", "response.incomplete", "response.failed":
+		output, _ := filterResponsesOutput(event.Get("response.output"), s.policy, s.prefix)
+		if output != "" {
+			data, _ = sjson.SetRaw(data, "response.output", output)
+		}
+		if s.policy.Mode == OutputField && s.collected.Len() > 0 {
+			data, _ = sjson.Set(data, "response."+s.policy.Field, strings.TrimSpace(s.collected.String()))
+		}
+	}
+	return []string{data}
+}
+
+func (s *OutputStream) isTagged(itemID string) bool {
+	return s.prefix != "" && itemID != "" && itemID == s.taggedItem
+}
+
+// reasoningBefore counts the dropped reasoning items before an output index.
+func (s *OutputStream) reasoningBefore(index int) int {
+	n := 0
+	for i := range s.reasoningItems {
+		if i < index {
+			n++
+		}
+	}
+	return n
+}
+
+// summarizeResponsesEvent cuts streamed reasoning to the summary budget, returning "" for
+// deltas past it. Done events and the final response repeat exactly the text that was sent.
+func (s *OutputStream) summarizeResponsesEvent(data string, event gjson.Result, typ string, index int) string {
+	textKey := func(kind string, part int64) string {
+		return strconv.Itoa(index) + ":" + kind + ":" + strconv.FormatInt(part, 10)
+	}
+	sent := func(key string) string {
+		if b := s.emitted[key]; b != nil {
+			return b.String()
+		}
+		return ""
+	}
+	switch typ {
+	case "response.reasoning_summary_text.delta", "response.reasoning_text.delta":
+		kind, part := "summary", event.Get("summary_index").Int()
+		if typ == "response.reasoning_text.delta" {
+			kind, part = "content", event.Get("content_index").Int()
+		}
+		summary := s.budget(index, event.Get("delta").String())
+		key := textKey(kind, part)
+		if s.emitted[key] == nil {
+			s.emitted[key] = &strings.Builder{}
+		}
+		if summary == "" {
+			return ""
+		}
+		s.emitted[key].WriteString(summary)
+		data, _ = sjson.Set(data, "delta", summary)
+	case "response.reasoning_summary_text.done":
+		data, _ = sjson.Set(data, "text", sent(textKey("summary", event.Get("summary_index").Int())))
+	case "response.reasoning_text.done":
+		data, _ = sjson.Set(data, "text", sent(textKey("content", event.Get("content_index").Int())))
+	case "response.reasoning_summary_part.done":
+		data, _ = sjson.Set(data, "part.text", sent(textKey("summary", event.Get("summary_index").Int())))
+	case "response.output_item.done":
+		if event.Get("item.type").String() == "reasoning" {
+			data, _ = sjson.SetRaw(data, "item", s.sentReasoningItem(event.Get("item"), index))
+		}
+	case "response.completed", "response.incomplete", "response.failed":
+		event.Get("response.output").ForEach(func(key, item gjson.Result) bool {
+			if item.Get("type").String() == "reasoning" {
+				data, _ = sjson.SetRaw(data, "response.output."+key.String(), s.sentReasoningItem(item, int(key.Int())))
+			}
+			return true
+		})
+	}
+	return data
+}
+
+// sentReasoningItem replaces the texts of a reasoning item with the streamed summaries.
+func (s *OutputStream) sentReasoningItem(item gjson.Result, index int) string {
+	raw := item.Raw
+	for _, kind := range []string{"summary", "content"} {
+		item.Get(kind).ForEach(func(key, part gjson.Result) bool {
+			text := ""
+			if b := s.emitted[strconv.Itoa(index)+":"+kind+":"+key.String()]; b != nil {
+				text = b.String()
+			}
+			raw, _ = sjson.Set(raw, kind+"."+key.String()+".text", text)
+			return true
+		})
+	}
+	return raw
+}
+
+// hasValues reports whether an object has a field with a non-empty value.
+func hasValues(obj gjson.Result) bool {
+	has := false
+	obj.ForEach(func(_, value gjson.Result) bool {
+		has = value.Type != gjson.Null && value.String() != "" && value.Raw != "[]" && value.Raw != "{}"
+		return !has
+	})
+	return has
+}
@@ -0,0 +1,153 @@
+package thinking
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestApplyOutputPolicy_OpenAI(t *testing.T) {
+	payload := []byte(`{"choices":[{"index":0,"message":{"role":"assistant","content":"Answer","reasoning_content":"Because."}}]}`)
+
+	tagged := ApplyOutputPolicy("openai", payload, OutputPolicy{Mode: OutputTags})
+	if got := gjson.GetBytes(tagged, "choices.0.message.content").String(); got != "<think>Because.</think>\n\nAnswer" {
+		t.Fatalf("tags content = %q", got)
+	}
+	if gjson.GetBytes(tagged, "choices.0.message.reasoning_content").Exists() {
+		t.Fatalf("tags mode kept reasoning_content: %s", tagged)
+	}
+
+	field := ApplyOutputPolicy("openai", payload, OutputPolicy{Mode: OutputField, Field: "thoughts"})
+	if got := gjson.GetBytes(field, "choices.0.message.thoughts").String(); got != "Because." {
+		t.Fatalf("field = %q", got)
+	}
+
+	stripped := ApplyOutputPolicy("openai", payload, OutputPolicy{Mode: OutputStrip})
+	if gjson.GetBytes(stripped, "choices.0.message.reasoning_content").Exists() {
+		t.Fatalf("strip kept reasoning: %s", stripped)
+	}
+
+	if native := ApplyOutputPolicy("openai", payload, OutputPolicy{}); string(native) != string(payload) {
+		t.Fatalf("inactive policy changed payload: %s", native)
+	}
+}
+
+func TestApplyOutputPolicy_ClaudeSummarize(t *testing.T) {
+	payload := []byte(`{"content":[{"type":"thinking","thinking":"abcdefghij","signature":"sig"},{"type":"text","text":"Hi"}]}`)
+	out := ApplyOutputPolicy("claude", payload, OutputPolicy{Mode: OutputSummarize, SummaryChars: 4})
+	block := gjson.GetBytes(out, "content.0")
+	if block.Get("type").String() != "text" || block.Get("signature").Exists() {
+		t.Fatalf("summary must be an unsigned text block: %s", out)
+	}
+	if got := block.Get("text").String(); got != "abcd …" {
+		t.Fatalf("summary = %q", got)
+	}
+	if got := gjson.GetBytes(out, "content.1.text").String(); got != "Hi" {
+		t.Fatalf("text block = %q", got)
+	}
+}
+
+func TestApplyOutputPolicy_GeminiStrip(t *testing.T) {
+	payload := []byte(`{"response":{"candidates":[{"content":{"role":"model","parts":[{"text":"plan","thought":true},{"text":"Done"}]}}]}}`)
+	out := ApplyOutputPolicy("gemini-cli", payload, OutputPolicy{Mode: OutputStrip})
+	parts := gjson.GetBytes(out, "response.candidates.0.content.parts").Array()
+	if len(parts) != 1 || parts[0].Get("text").String() != "Done" {
+		t.Fatalf("parts = %s", gjson.GetBytes(out, "response.candidates.0.content.parts").Raw)
+	}
+}
+
+func TestApplyOutputPolicy_ResponsesStrip(t *testing.T) {
+	payload := []byte(`{"output":[{"type":"reasoning","id":"rs_1","summary":[{"type":"summary_text","text":"plan"}]},{"type":"message","id":"msg_1","content":[{"type":"output_text","text":"Done"}]}]}`)
+	out := ApplyOutputPolicy("openai-response", payload, OutputPolicy{Mode: OutputStrip})
+	output := gjson.GetBytes(out, "output").Array()
+	if len(output) != 1 || output[0].Get("type").String() != "message" {
+		t.Fatalf("output = %s", gjson.GetBytes(out, "output").Raw)
+	}
+}
+
+func TestOutputStream_OpenAITags(t *testing.T) {
+	s := NewOutputStream("openai", OutputPolicy{Mode: OutputTags})
+	chunks := []string{
+		`{"choices":[{"index":0,"delta":{"role":"assistant","reasoning_content":"Let me "}}]}`,
+		`{"choices":[{"index":0,"delta":{"reasoning_content":"think."}}]}`,
+		`{"choices":[{"index":0,"delta":{"content":"Answer"}}]}`,
+		`{"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+	}
+	var content strings.Builder
+	for _, chunk := range chunks {
+		out := s.Process([]byte(chunk))
+		if out == nil {
+			continue
+		}
+		if gjson.GetBytes(out, "choices.0.delta.reasoning_content").Exists() {
+			t.Fatalf("reasoning leaked: %s", out)
+		}
+		content.WriteString(gjson.GetBytes(out, "choices.0.delta.content").String())
+	}
+	if got := content.String(); got != "<think>Let me think.</think>\n\nAnswer" {
+		t.Fatalf("content = %q", got)
+	}
+}
+
+func TestOutputStream_ClaudeStripRenumbers(t *testing.T) {
+	s := NewOutputStream("claude", OutputPolicy{Mode: OutputStrip})
+	events := []string{
+		"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"thinking\",\"thinking\":\"\"}}\n\n",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"thinking_delta\",\"thinking\":\"hmm\"}}\n\n",
+		"event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}\n\n",
+		"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":1,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":1,\"delta\":{\"type\":\"text_delta\",\"text\":\"Hi\"}}\n\n",
+		"event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":1}\n\n",
+	}
+	var sent []string
+	for _, event := range events {
+		if out := s.Process([]byte(event)); out != nil {
+			sent = append(sent, string(out))
+		}
+	}
+	if len(sent) != 3 {
+		t.Fatalf("sent %d events, want 3: %q", len(sent), sent)
+	}
+	for _, event := range sent {
+		if strings.Contains(event, "thinking") {
+			t.Fatalf("thinking leaked: %q", event)
+		}
+		data := event[strings.Index(event, "data: ")+len("data: "):]
+		if idx := gjson.Get(strings.TrimSpace(data), "index").Int(); idx != 0 {
+			t.Fatalf("index = %d, want 0: %q", idx, event)
+		}
+	}
+}
+
+func TestOutputStream_ClaudeSummarizeSendsText(t *testing.T) {
+	s := NewOutputStream("claude", OutputPolicy{Mode: OutputSummarize, SummaryChars: 4})
+	events := []string{
+		"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"thinking\",\"thinking\":\"\"}}\n\n",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"thinking_delta\",\"thinking\":\"abcdefghij\"}}\n\n",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"signature_delta\",\"signature\":\"sig\"}}\n\n",
+		"event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}\n\n",
+	}
+	var sent []string
+	for _, event := range events {
+		if out := s.Process([]byte(event)); out != nil {
+			sent = append(sent, string(out))
+		}
+	}
+	joined := strings.Join(sent, "")
+	if strings.Contains(joined, "thinking") || strings.Contains(joined, "signature") {
+		t.Fatalf("summary must be sent as an unsigned text block: %q", sent)
+	}
+	if !strings.Contains(joined, `"content_block":{"type":"text"`) || !strings.Contains(joined, `"text":"abcd …"`) {
+		t.Fatalf("summary text block missing: %q", sent)
+	}
+}
+
+func TestNewOutputStream_Inactive(t *testing.T) {
+	if s := NewOutputStream("openai", OutputPolicy{Mode: OutputNative}); s != nil {
+		t.Fatal("native policy returned a stream rewriter")
+	}
+	if s := NewOutputStream("unknown", OutputPolicy{Mode: OutputStrip}); s != nil {
+		t.Fatal("unknown format returned a stream rewriter")
+	}
+}
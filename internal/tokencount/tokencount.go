@@ -1,4 +1,7 @@
-package executor
+// Package tokencount estimates prompt tokens of requests locally with tiktoken
+// tokenizers. It is used by executors whose upstreams have no token counting endpoint and
+// by the API handlers' context window guard.
+package tokencount
 
 import (
 	"fmt"
@@ -14,15 +17,15 @@ import (
 // tokenizerCache stores tokenizer instances to avoid repeated creation
 var tokenizerCache sync.Map
 
-// TokenizerWrapper wraps a tokenizer codec with an adjustment factor for models
+// Tokenizer wraps a tokenizer codec with an adjustment factor for models
 // where tiktoken may not accurately estimate token counts (e.g., Claude models)
-type TokenizerWrapper struct {
+type Tokenizer struct {
 	Codec            tokenizer.Codec
 	AdjustmentFactor float64 // 1.0 means no adjustment, >1.0 means tiktoken underestimates
 }
 
 // Count returns the token count with adjustment factor applied
-func (tw *TokenizerWrapper) Count(text string) (int, error) {
+func (tw *Tokenizer) Count(text string) (int, error) {
 	count, err := tw.Codec.Count(text)
 	if err != nil {
 		return 0, err
@@ -33,28 +36,28 @@ func (tw *TokenizerWrapper) Count(text string) (int, error) {
 	return count, nil
 }
 
-// getTokenizer returns a cached tokenizer for the given model.
+// Get returns a cached tokenizer for the given model.
 // This improves performance by avoiding repeated tokenizer creation.
-func getTokenizer(model string) (*TokenizerWrapper, error) {
+func Get(model string) (*Tokenizer, error) {
 	// Check cache first
 	if cached, ok := tokenizerCache.Load(model); ok {
-		return cached.(*TokenizerWrapper), nil
+		return cached.(*Tokenizer), nil
 	}
 
 	// Cache miss, create new tokenizer
-	wrapper, err := tokenizerForModel(model)
+	wrapper, err := ForModel(model)
 	if err != nil {
 		return nil, err
 	}
 
 	// Store in cache (use LoadOrStore to handle race conditions)
 	actual, _ := tokenizerCache.LoadOrStore(model, wrapper)
-	return actual.(*TokenizerWrapper), nil
+	return actual.(*Tokenizer), nil
 }
 
-// tokenizerForModel returns a tokenizer codec suitable for an OpenAI-style model id.
+// ForModel returns a new tokenizer suitable for an OpenAI-style model id.
 // For Claude models, applies a 1.1 adjustment factor since tiktoken may underestimate.
-func tokenizerForModel(model string) (*TokenizerWrapper, error) {
+func ForModel(model string) (*Tokenizer, error) {
 	sanitized := strings.ToLower(strings.TrimSpace(model))
 
 	// Claude models use cl100k_base with 1.1 adjustment factor
@@ -64,7 +67,7 @@ func tokenizerForModel(model string) (*TokenizerWrapper, error) {
 		if err != nil {
 			return nil, err
 		}
-		return &TokenizerWrapper{Codec: enc, AdjustmentFactor: 1.1}, nil
+		return &Tokenizer{Codec: enc, AdjustmentFactor: 1.1}, nil
 	}
 
 	var enc tokenizer.Codec
@@ -100,11 +103,11 @@ func tokenizerForModel(model string) (*TokenizerWrapper, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &TokenizerWrapper{Codec: enc, AdjustmentFactor: 1.0}, nil
+	return &Tokenizer{Codec: enc, AdjustmentFactor: 1.0}, nil
 }
 
-// countOpenAIChatTokens approximates prompt tokens for OpenAI chat completions payloads.
-func countOpenAIChatTokens(enc *TokenizerWrapper, payload []byte) (int64, error) {
+// CountOpenAIChat approximates prompt tokens for OpenAI chat completions payloads.
+func CountOpenAIChat(enc *Tokenizer, payload []byte) (int64, error) {
 	if enc == nil {
 		return 0, fmt.Errorf("encoder is nil")
 	}
@@ -140,10 +143,10 @@ func countOpenAIChatTokens(enc *TokenizerWrapper, payload []byte) (int64, error)
 	return int64(count) + int64(imageTokens), nil
 }
 
-// countClaudeChatTokens approximates prompt tokens for Claude API chat completions payloads.
+// CountClaude approximates prompt tokens for Claude API chat completions payloads.
 // This handles Claude's message format with system, messages, and tools.
 // Image tokens are estimated based on image dimensions when available.
-func countClaudeChatTokens(enc *TokenizerWrapper, payload []byte) (int64, error) {
+func CountClaude(enc *Tokenizer, payload []byte) (int64, error) {
 	if enc == nil {
 		return 0, fmt.Errorf("encoder is nil")
 	}
@@ -180,6 +183,118 @@ func countClaudeChatTokens(enc *TokenizerWrapper, payload []byte) (int64, error)
 	return int64(count) + int64(imageTokens), nil
 }
 
+// CountRequest estimates the prompt tokens of a request in a client format
+// ("openai", "claude", "openai-response", "gemini" or "gemini-cli") for the given model.
+func CountRequest(format, model string, payload []byte) (int64, error) {
+	enc, err := Get(model)
+	if err != nil {
+		return 0, err
+	}
+	switch format {
+	case "claude":
+		return CountClaude(enc, payload)
+	case "openai-response":
+		return countResponsesTokens(enc, payload)
+	case "gemini", "gemini-cli":
+		return countGeminiTokens(enc, payload)
+	default:
+		return CountOpenAIChat(enc, payload)
+	}
+}
+
+// countResponsesTokens approximates prompt tokens for OpenAI Responses API payloads.
+func countResponsesTokens(enc *Tokenizer, payload []byte) (int64, error) {
+	if enc == nil {
+		return 0, fmt.Errorf("encoder is nil")
+	}
+	root := gjson.ParseBytes(payload)
+	segments := make([]string, 0, 32)
+	addIfNotEmpty(&segments, root.Get("instructions").String())
+	input := root.Get("input")
+	if input.Type == gjson.String {
+		addIfNotEmpty(&segments, input.String())
+	}
+	input.ForEach(func(_, item gjson.Result) bool {
+		switch item.Get("type").String() {
+		case "function_call":
+			addIfNotEmpty(&segments, item.Get("name").String())
+			addIfNotEmpty(&segments, item.Get("arguments").String())
+		case "function_call_output":
+			collectOpenAIContent(item.Get("output"), &segments)
+		case "reasoning":
+			item.Get("summary").ForEach(func(_, part gjson.Result) bool {
+				addIfNotEmpty(&segments, part.Get("text").String())
+				return true
+			})
+		default:
+			addIfNotEmpty(&segments, item.Get("role").String())
+			collectOpenAIContent(item.Get("content"), &segments)
+		}
+		return true
+	})
+	collectOpenAITools(root.Get("tools"), &segments)
+	if format := root.Get("text.format"); format.Exists() {
+		collectOpenAIResponseFormat(format, &segments)
+	}
+	return countSegments(enc, segments)
+}
+
+// countGeminiTokens approximates prompt tokens for Gemini payloads, including the
+// gemini-cli envelope that nests the request under "request".
+func countGeminiTokens(enc *Tokenizer, payload []byte) (int64, error) {
+	if enc == nil {
+		return 0, fmt.Errorf("encoder is nil")
+	}
+	root := gjson.ParseBytes(payload)
+	if request := root.Get("request"); request.IsObject() {
+		root = request
+	}
+	segments := make([]string, 0, 32)
+	collectGeminiParts(root.Get("systemInstruction.parts"), &segments)
+	collectGeminiParts(root.Get("system_instruction.parts"), &segments)
+	root.Get("contents").ForEach(func(_, content gjson.Result) bool {
+		addIfNotEmpty(&segments, content.Get("role").String())
+		collectGeminiParts(content.Get("parts"), &segments)
+		return true
+	})
+	root.Get("tools").ForEach(func(_, tool gjson.Result) bool {
+		addIfNotEmpty(&segments, tool.Raw)
+		return true
+	})
+	return countSegments(enc, segments)
+}
+
+func collectGeminiParts(parts gjson.Result, segments *[]string) {
+	parts.ForEach(func(_, part gjson.Result) bool {
+		switch {
+		case part.Get("text").Exists():
+			addIfNotEmpty(segments, part.Get("text").String())
+		case part.Get("functionCall").Exists():
+			addIfNotEmpty(segments, part.Get("functionCall.name").String())
+			addIfNotEmpty(segments, part.Get("functionCall.args").Raw)
+		case part.Get("functionResponse").Exists():
+			addIfNotEmpty(segments, part.Get("functionResponse.name").String())
+			addIfNotEmpty(segments, part.Get("functionResponse.response").Raw)
+		case part.Get("inlineData").Exists(), part.Get("fileData").Exists():
+			addIfNotEmpty(segments, fmt.Sprintf("[IMAGE:%d tokens]", estimateImageTokens(0, 0)))
+		}
+		return true
+	})
+}
+
+// countSegments counts the joined segments, adding image placeholder estimates.
+func countSegments(enc *Tokenizer, segments []string) (int64, error) {
+	joined := strings.TrimSpace(strings.Join(segments, "\n"))
+	if joined == "" {
+		return 0, nil
+	}
+	count, err := enc.Count(joined)
+	if err != nil {
+		return 0, err
+	}
+	return int64(count) + int64(extractImageTokens(joined)), nil
+}
+
 // imageTokenPattern matches [IMAGE:xxx tokens] format for extracting estimated image tokens
 var imageTokenPattern = regexp.MustCompile(`\[IMAGE:(\d+) tokens\]`)
 
@@ -330,11 +445,6 @@ func collectClaudeTools(tools gjson.Result, segments *[]string) {
 	})
 }
 
-// buildOpenAIUsageJSON returns a minimal usage structure understood by downstream translators.
-func buildOpenAIUsageJSON(count int64) []byte {
-	return []byte(fmt.Sprintf(`{"usage":{"prompt_tokens":%d,"completion_tokens":0,"total_tokens":%d}}`, count, count))
-}
-
 func collectOpenAIMessages(messages gjson.Result, segments *[]string) {
 	if !messages.Exists() || !messages.IsArray() {
 		return
@@ -364,7 +474,9 @@ func collectOpenAIContent(content gjson.Result, segments *[]string) {
 			case "text", "input_text", "output_text":
 				addIfNotEmpty(segments, part.Get("text").String())
 			case "image_url":
-				addIfNotEmpty(segments, part.Get("image_url.url").String())
+				addImageURL(segments, part.Get("image_url.url").String())
+			case "input_image":
+				addImageURL(segments, part.Get("image_url").String())
 			case "input_audio", "output_audio", "audio":
 				addIfNotEmpty(segments, part.Get("id").String())
 			case "tool_result":
@@ -390,6 +502,16 @@ func collectOpenAIContent(content gjson.Result, segments *[]string) {
 	}
 }
 
+// addImageURL adds an image reference. Inline images are estimated rather than counted
+// as base64 text.
+func addImageURL(segments *[]string, url string) {
+	if strings.HasPrefix(url, "data:") {
+		addIfNotEmpty(segments, fmt.Sprintf("[IMAGE:%d tokens]", estimateImageTokens(0, 0)))
+		return
+	}
+	addIfNotEmpty(segments, url)
+}
+
 func collectOpenAIToolCalls(calls gjson.Result, segments *[]string) {
 	if !calls.Exists() || !calls.IsArray() {
 		return
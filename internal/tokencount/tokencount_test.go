@@ -0,0 +1,37 @@
+package tokencount
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCountRequestFormats(t *testing.T) {
+	cases := map[string]string{
+		"openai":          `{"messages":[{"role":"user","content":"Hello there, how are you?"}]}`,
+		"claude":          `{"system":"Be brief.","messages":[{"role":"user","content":"Hello there, how are you?"}]}`,
+		"openai-response": `{"instructions":"Be brief.","input":[{"type":"message","role":"user","content":[{"type":"input_text","text":"Hello there, how are you?"}]}]}`,
+		"gemini":          `{"contents":[{"role":"user","parts":[{"text":"Hello there, how are you?"}]}]}`,
+		"gemini-cli":      `{"request":{"contents":[{"role":"user","parts":[{"text":"Hello there, how are you?"}]}]}}`,
+	}
+	for format, payload := range cases {
+		count, err := CountRequest(format, "gpt-4o", []byte(payload))
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if count <= 0 || count > 50 {
+			t.Fatalf("%s: count = %d", format, count)
+		}
+	}
+}
+
+func TestCountRequestEstimatesInlineImages(t *testing.T) {
+	data := "data:image/png;base64," + strings.Repeat("QUJD", 20000)
+	payload := `{"messages":[{"role":"user","content":[{"type":"image_url","image_url":{"url":"` + data + `"}}]}]}`
+	count, err := CountRequest("openai", "gpt-4o", []byte(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count > 2000 {
+		t.Fatalf("inline image counted as text: %d tokens", count)
+	}
+}
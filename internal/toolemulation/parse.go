@@ -0,0 +1,297 @@
+// Package toolemulation lets models without native function calling use tools. Tool
+// schemas are rendered into the system prompt, earlier tool calls and results are replayed
+// as text, and the calls the model writes into its reply are parsed back into OpenAI Chat
+// Completions tool_calls, both for whole responses and for streams.
+//
+// Calls use the text form some upstreams already fall back to on their own:
+//
+//	[Called tool_name with args: {"key": "value"}]
+package toolemulation
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/tidwall/gjson"
+)
+
+// markerPrefix starts every emulated tool call.
+const markerPrefix = "[Called"
+
+// maxMarkerHead bounds how far a streamed "[Called ..." may run before "with args:" shows
+// up; longer text is treated as prose.
+const maxMarkerHead = 160
+
+var (
+	// markerPattern matches the head of a call up to its arguments. Models sometimes quote
+	// the tool name, so backticks and quotes around it are accepted.
+	markerPattern = regexp.MustCompile("^\\[Called\\s+[`\"']?([A-Za-z0-9_.:-]+)[`\"']?\\s+with\\s+args:\\s*")
+	// trailingCommaPattern matches trailing commas before closing braces/brackets.
+	trailingCommaPattern = regexp.MustCompile(`,\s*([}\]])`)
+)
+
+// Call is a tool call parsed from model output.
+type Call struct {
+	// ID is a generated OpenAI tool call id.
+	ID string
+	// Name is the called tool.
+	Name string
+	// Arguments is the JSON object of arguments.
+	Arguments string
+}
+
+// markerStatus is the outcome of parsing a call marker.
+type markerStatus int
+
+const (
+	// markerInvalid means the text is not a tool call and should be kept as text.
+	markerInvalid markerStatus = iota
+	// markerIncomplete means the text may still become a call once more of it arrives.
+	markerIncomplete
+	// markerComplete means a call was parsed.
+	markerComplete
+)
+
+// Parse extracts the tool calls from text and returns the remaining text. When names is
+// not empty, calls to other tools are left in the text. It accepts whitespace and newlines
+// before the arguments, quoted tool names, a missing closing bracket and arguments cut off
+// at the end of the text. The Kiro translator uses it for the calls Kiro embeds in text.
+func Parse(text string, names []string) (string, []Call) {
+	if !strings.Contains(text, markerPrefix) {
+		return text, nil
+	}
+	allowed := nameSet(names)
+	var clean strings.Builder
+	var calls []Call
+	pos := 0
+	for {
+		i := strings.Index(text[pos:], markerPrefix)
+		if i < 0 {
+			clean.WriteString(text[pos:])
+			break
+		}
+		start := pos + i
+		call, end, status := parseMarker(text, start, allowed, true)
+		if status != markerComplete {
+			clean.WriteString(text[pos : start+1])
+			pos = start + 1
+			continue
+		}
+		clean.WriteString(text[pos:start])
+		calls = append(calls, call)
+		pos = end
+	}
+	if len(calls) == 0 {
+		return text, nil
+	}
+	return tidyText(clean.String()), calls
+}
+
+// parseMarker parses the call starting at text[start], which begins with markerPrefix.
+// final tells whether text is complete; otherwise a call cut off by the end of text is
+// reported as incomplete instead of being repaired.
+func parseMarker(text string, start int, allowed map[string]bool, final bool) (Call, int, markerStatus) {
+	rest := text[start:]
+	match := markerPattern.FindStringSubmatchIndex(rest)
+	if match == nil {
+		if !final && len(rest) < maxMarkerHead && !strings.Contains(rest, "\n") {
+			return Call{}, 0, markerIncomplete
+		}
+		return Call{}, 0, markerInvalid
+	}
+	name := rest[match[2]:match[3]]
+	if len(allowed) > 0 && !allowed[name] {
+		return Call{}, 0, markerInvalid
+	}
+	argsStart := start + match[1]
+	if argsStart >= len(text) {
+		if final {
+			return Call{}, 0, markerInvalid
+		}
+		return Call{}, 0, markerIncomplete
+	}
+	if text[argsStart] != '{' {
+		return Call{}, 0, markerInvalid
+	}
+
+	var args string
+	end := len(text)
+	if argsEnd := findMatchingBrace(text, argsStart); argsEnd >= 0 {
+		args = text[argsStart : argsEnd+1]
+		end = argsEnd + 1
+		k := end
+		for k < len(text) && isSpace(text[k]) {
+			k++
+		}
+		switch {
+		case k < len(text) && text[k] == ']':
+			end = k + 1
+		case k == len(text) && !final:
+			// The closing bracket may still be on its way.
+			return Call{}, 0, markerIncomplete
+		}
+	} else {
+		if !final {
+			return Call{}, 0, markerIncomplete
+		}
+		args = text[argsStart:]
+	}
+
+	args = RepairJSON(args)
+	if !gjson.Valid(args) || !gjson.Parse(args).IsObject() {
+		return Call{}, 0, markerInvalid
+	}
+	return Call{ID: "call_" + strings.ReplaceAll(uuid.New().String(), "-", "")[:24], Name: name, Arguments: args}, end, markerComplete
+}
+
+// findMatchingBrace returns the index of the brace closing the object that opens at
+// text[start], or -1 when text ends first. Braces inside strings are ignored.
+func findMatchingBrace(text string, start int) int {
+	depth := 0
+	inString := false
+	escaped := false
+	for i := start; i < len(text); i++ {
+		c := text[i]
+		if escaped {
+			escaped = false
+			continue
+		}
+		if inString {
+			switch c {
+			case '\\':
+				escaped = true
+			case '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// RepairJSON fixes the mistakes models make in hand-written arguments: literal control
+// characters inside strings, trailing commas and missing closing braces or brackets. Valid
+// JSON is returned unchanged, and input that cannot be repaired is returned as is.
+func RepairJSON(raw string) string {
+	str := strings.TrimSpace(raw)
+	if str == "" {
+		return "{}"
+	}
+	if json.Valid([]byte(str)) {
+		return str
+	}
+	repaired := trailingCommaPattern.ReplaceAllString(escapeControlCharacters(str), "$1")
+
+	var closers []byte
+	inString := false
+	escaped := false
+	for i := 0; i < len(repaired); i++ {
+		c := repaired[i]
+		if escaped {
+			escaped = false
+			continue
+		}
+		if inString {
+			switch c {
+			case '\\':
+				escaped = true
+			case '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{':
+			closers = append(closers, '}')
+		case '[':
+			closers = append(closers, ']')
+		case '}', ']':
+			if len(closers) > 0 && closers[len(closers)-1] == c {
+				closers = closers[:len(closers)-1]
+			}
+		}
+	}
+	if inString {
+		repaired += `"`
+	}
+	for i := len(closers) - 1; i >= 0; i-- {
+		repaired += string(closers[i])
+	}
+	repaired = trailingCommaPattern.ReplaceAllString(repaired, "$1")
+	if !json.Valid([]byte(repaired)) {
+		return str
+	}
+	return repaired
+}
+
+// escapeControlCharacters escapes literal newlines, tabs and carriage returns that appear
+// inside JSON strings.
+func escapeControlCharacters(raw string) string {
+	var b strings.Builder
+	b.Grow(len(raw))
+	inString := false
+	escaped := false
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		if escaped {
+			escaped = false
+			b.WriteByte(c)
+			continue
+		}
+		switch {
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString && c == '\n':
+			b.WriteString(`\n`)
+			continue
+		case inString && c == '\r':
+			b.WriteString(`\r`)
+			continue
+		case inString && c == '\t':
+			b.WriteString(`\t`)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// tidyText collapses the blank lines left behind by removed calls.
+func tidyText(text string) string {
+	for strings.Contains(text, "\n\n\n") {
+		text = strings.ReplaceAll(text, "\n\n\n", "\n\n")
+	}
+	return strings.TrimSpace(text)
+}
+
+func nameSet(names []string) map[string]bool {
+	if len(names) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,160 @@
+package toolemulation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Request rewrites an OpenAI Chat Completions request for a model without native tool
+// support. The tool definitions move into the system prompt, assistant tool_calls become
+// call markers and tool messages become user messages carrying the result. It returns the
+// rewritten body and the names of the declared tools, which the response parser accepts.
+// Requests without tools or tool history are returned unchanged.
+func Request(body []byte) ([]byte, []string) {
+	tools := gjson.GetBytes(body, "tools")
+	if !tools.IsArray() && !hasToolHistory(body) {
+		return body, nil
+	}
+
+	var names []string
+	var definitions []string
+	tools.ForEach(func(_, tool gjson.Result) bool {
+		fn := tool.Get("function")
+		if tool.Get("type").String() != "function" || fn.Get("name").String() == "" {
+			return true
+		}
+		names = append(names, fn.Get("name").String())
+		definitions = append(definitions, describeTool(fn))
+		return true
+	})
+
+	messages := gjson.GetBytes(body, "messages").Array()
+	toolNames := make(map[string]string)
+	rewritten := make([]string, 0, len(messages)+1)
+	for _, message := range messages {
+		switch message.Get("role").String() {
+		case "assistant":
+			calls := message.Get("tool_calls")
+			if !calls.IsArray() {
+				rewritten = append(rewritten, message.Raw)
+				continue
+			}
+			var text strings.Builder
+			text.WriteString(contentText(message.Get("content")))
+			calls.ForEach(func(_, call gjson.Result) bool {
+				name := call.Get("function.name").String()
+				toolNames[call.Get("id").String()] = name
+				args := call.Get("function.arguments").String()
+				if strings.TrimSpace(args) == "" {
+					args = "{}"
+				}
+				if text.Len() > 0 {
+					text.WriteString("\n")
+				}
+				fmt.Fprintf(&text, "%s %s with args: %s]", markerPrefix, name, args)
+				return true
+			})
+			msg := `{"role":"assistant","content":""}`
+			msg, _ = sjson.Set(msg, "content", text.String())
+			rewritten = append(rewritten, msg)
+		case "tool":
+			id := message.Get("tool_call_id").String()
+			name := toolNames[id]
+			if name == "" {
+				name = "tool"
+			}
+			msg := `{"role":"user","content":""}`
+			msg, _ = sjson.Set(msg, "content", fmt.Sprintf("[Result of %s call %s]\n%s", name, id, contentText(message.Get("content"))))
+			rewritten = append(rewritten, msg)
+		default:
+			rewritten = append(rewritten, message.Raw)
+		}
+	}
+
+	if prompt := systemPrompt(definitions, gjson.GetBytes(body, "tool_choice")); prompt != "" {
+		if len(rewritten) > 0 && gjson.Get(rewritten[0], "role").String() == "system" && gjson.Get(rewritten[0], "content").Type == gjson.String {
+			rewritten[0], _ = sjson.Set(rewritten[0], "content", gjson.Get(rewritten[0], "content").String()+"\n\n"+prompt)
+		} else {
+			msg := `{"role":"system","content":""}`
+			msg, _ = sjson.Set(msg, "content", prompt)
+			rewritten = append([]string{msg}, rewritten...)
+		}
+	}
+
+	out, _ := sjson.SetRawBytes(body, "messages", []byte("["+strings.Join(rewritten, ",")+"]"))
+	out, _ = sjson.DeleteBytes(out, "tools")
+	out, _ = sjson.DeleteBytes(out, "tool_choice")
+	out, _ = sjson.DeleteBytes(out, "parallel_tool_calls")
+	return out, names
+}
+
+// hasToolHistory reports whether the conversation contains tool calls or results.
+func hasToolHistory(body []byte) bool {
+	found := false
+	gjson.GetBytes(body, "messages").ForEach(func(_, message gjson.Result) bool {
+		found = message.Get("role").String() == "tool" || message.Get("tool_calls").IsArray()
+		return !found
+	})
+	return found
+}
+
+// systemPrompt renders the tool instructions, or "" when no tool may be called.
+func systemPrompt(definitions []string, choice gjson.Result) string {
+	if len(definitions) == 0 || choice.String() == "none" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("You can call tools to help answer. To call a tool, write a line of exactly this form and nothing else on it:\n")
+	b.WriteString(markerPrefix + " tool_name with args: {\"argument\": \"value\"}]\n")
+	b.WriteString("The arguments must be a single JSON object that matches the tool's parameters. ")
+	b.WriteString("You may call several tools by writing one line per call. After calling tools, stop and wait: ")
+	b.WriteString("the results will be sent to you in a message starting with \"[Result of\". ")
+	b.WriteString("Never write tool results yourself.\n\n")
+	switch {
+	case choice.String() == "required":
+		b.WriteString("You must call at least one tool in your reply.\n\n")
+	case choice.Get("function.name").String() != "":
+		fmt.Fprintf(&b, "You must call the tool %s in your reply.\n\n", choice.Get("function.name").String())
+	}
+	b.WriteString("Available tools:\n")
+	b.WriteString(strings.Join(definitions, "\n"))
+	return b.String()
+}
+
+// describeTool renders one function definition for the system prompt.
+func describeTool(fn gjson.Result) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "- %s", fn.Get("name").String())
+	if desc := strings.TrimSpace(fn.Get("description").String()); desc != "" {
+		fmt.Fprintf(&b, ": %s", desc)
+	}
+	params := fn.Get("parameters")
+	if params.Exists() {
+		fmt.Fprintf(&b, "\n  Parameters (JSON schema): %s", compactJSON(params.Raw))
+	} else {
+		b.WriteString("\n  Parameters: none, use {}")
+	}
+	return b.String()
+}
+
+// contentText returns the text of a string or array message content.
+func contentText(content gjson.Result) string {
+	if !content.IsArray() {
+		return content.String()
+	}
+	var parts []string
+	content.ForEach(func(_, part gjson.Result) bool {
+		if text := part.Get("text"); text.Exists() {
+			parts = append(parts, text.String())
+		}
+		return true
+	})
+	return strings.Join(parts, "\n")
+}
+
+func compactJSON(raw string) string {
+	return gjson.Get(raw, "@ugly").Raw
+}
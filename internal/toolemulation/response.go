@@ -0,0 +1,213 @@
+package toolemulation
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Response rewrites an OpenAI Chat Completions response, turning the calls written into
+// each choice's message content into tool_calls with finish_reason "tool_calls".
+func Response(body []byte, names []string) []byte {
+	out := body
+	gjson.GetBytes(body, "choices").ForEach(func(key, choice gjson.Result) bool {
+		content := choice.Get("message.content")
+		if content.Type != gjson.String {
+			return true
+		}
+		text, calls := Parse(content.String(), names)
+		if len(calls) == 0 {
+			return true
+		}
+		path := "choices." + key.String()
+		if text == "" {
+			out, _ = sjson.SetRawBytes(out, path+".message.content", []byte("null"))
+		} else {
+			out, _ = sjson.SetBytes(out, path+".message.content", text)
+		}
+		toolCalls := make([]string, 0, len(calls))
+		for _, call := range calls {
+			toolCalls = append(toolCalls, callJSON(call))
+		}
+		out, _ = sjson.SetRawBytes(out, path+".message.tool_calls", []byte("["+strings.Join(toolCalls, ",")+"]"))
+		out, _ = sjson.SetBytes(out, path+".finish_reason", "tool_calls")
+		return true
+	})
+	return out
+}
+
+// Stream rewrites an OpenAI Chat Completions SSE stream. Text that may be the start of a
+// call is held back until it is either a complete call, which is sent as a tool_calls
+// delta, or known to be prose. Only the first choice is rewritten.
+type Stream struct {
+	allowed  map[string]bool
+	pending  string
+	calls    int
+	template []byte
+}
+
+// NewStream creates a stream rewriter accepting calls to the named tools.
+func NewStream(names []string) *Stream {
+	return &Stream{allowed: nameSet(names)}
+}
+
+// Process takes one upstream SSE line and returns the lines to forward in its place.
+func (s *Stream) Process(line []byte) [][]byte {
+	if !bytes.HasPrefix(line, []byte("data:")) {
+		return [][]byte{line}
+	}
+	payload := bytes.Clone(bytes.TrimSpace(line[len("data:"):]))
+	if bytes.Equal(payload, []byte("[DONE]")) {
+		return append(s.Close(), line)
+	}
+	choice := gjson.GetBytes(payload, "choices.0")
+	if !gjson.ValidBytes(payload) || !choice.Exists() {
+		return [][]byte{line}
+	}
+	s.template = payload
+
+	finish := choice.Get("finish_reason")
+	finished := finish.Exists() && finish.Type != gjson.Null
+	if choice.Get("delta.content").Type == gjson.String {
+		s.pending += choice.Get("delta.content").String()
+	} else if !finished {
+		return [][]byte{line}
+	}
+
+	text, calls := s.drain(finished)
+	content := payload
+	if text != "" {
+		content, _ = sjson.SetBytes(content, "choices.0.delta.content", text)
+	} else {
+		content, _ = sjson.DeleteBytes(content, "choices.0.delta.content")
+	}
+	if !finished && len(calls) == 0 {
+		if !hasDelta(content) {
+			return nil
+		}
+		return [][]byte{sseLine(content)}
+	}
+
+	// Text first, then the calls, then the finish reason and usage.
+	var out [][]byte
+	content, _ = sjson.SetRawBytes(content, "choices.0.finish_reason", []byte("null"))
+	content, _ = sjson.DeleteBytes(content, "usage")
+	if hasDelta(content) {
+		out = append(out, sseLine(content))
+	}
+	for _, call := range calls {
+		out = append(out, sseLine(s.callChunk(call)))
+	}
+	if finished {
+		done, _ := sjson.SetRawBytes(payload, "choices.0.delta", []byte("{}"))
+		if s.calls > 0 {
+			done, _ = sjson.SetBytes(done, "choices.0.finish_reason", "tool_calls")
+		}
+		out = append(out, sseLine(done))
+	}
+	return out
+}
+
+// Close flushes text still held back when the stream ends without a finish reason.
+func (s *Stream) Close() [][]byte {
+	if s.pending == "" || s.template == nil {
+		return nil
+	}
+	text, calls := s.drain(true)
+	var out [][]byte
+	if text != "" {
+		chunk, _ := sjson.SetRawBytes(s.template, "choices.0", []byte(`{"index":0,"delta":{"content":""},"finish_reason":null}`))
+		chunk, _ = sjson.SetBytes(chunk, "choices.0.delta.content", text)
+		chunk, _ = sjson.DeleteBytes(chunk, "usage")
+		out = append(out, sseLine(chunk))
+	}
+	for _, call := range calls {
+		out = append(out, sseLine(s.callChunk(call)))
+	}
+	return out
+}
+
+// drain returns the pending text that is safe to send and the calls completed so far.
+// When final is set, everything pending is resolved.
+func (s *Stream) drain(final bool) (string, []Call) {
+	var text strings.Builder
+	var calls []Call
+	for {
+		i := strings.Index(s.pending, markerPrefix)
+		if i < 0 {
+			keep := 0
+			if !final {
+				keep = partialMarker(s.pending)
+			}
+			text.WriteString(s.pending[:len(s.pending)-keep])
+			s.pending = s.pending[len(s.pending)-keep:]
+			break
+		}
+		call, end, status := parseMarker(s.pending, i, s.allowed, final)
+		switch status {
+		case markerIncomplete:
+			text.WriteString(s.pending[:i])
+			s.pending = s.pending[i:]
+			return text.String(), calls
+		case markerInvalid:
+			text.WriteString(s.pending[:i+1])
+			s.pending = s.pending[i+1:]
+		case markerComplete:
+			text.WriteString(s.pending[:i])
+			calls = append(calls, call)
+			s.pending = strings.TrimLeft(s.pending[end:], "\r\n")
+		}
+	}
+	return text.String(), calls
+}
+
+// callChunk builds a chunk announcing a complete call.
+func (s *Stream) callChunk(call Call) []byte {
+	chunk := []byte(`{"id":"","object":"chat.completion.chunk","created":0,"model":"","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[]},"finish_reason":null}]}`)
+	for _, field := range []string{"id", "created", "model"} {
+		if v := gjson.GetBytes(s.template, field); v.Exists() {
+			chunk, _ = sjson.SetRawBytes(chunk, field, []byte(v.Raw))
+		}
+	}
+	toolCall, _ := sjson.Set(callJSON(call), "index", s.calls)
+	chunk, _ = sjson.SetRawBytes(chunk, "choices.0.delta.tool_calls.-1", []byte(toolCall))
+	s.calls++
+	return chunk
+}
+
+// partialMarker returns the length of the longest suffix of text that starts markerPrefix.
+func partialMarker(text string) int {
+	for n := len(markerPrefix) - 1; n > 0; n-- {
+		if strings.HasSuffix(text, markerPrefix[:n]) {
+			return n
+		}
+	}
+	return 0
+}
+
+// hasDelta reports whether the first choice of chunk still carries something to send.
+func hasDelta(chunk []byte) bool {
+	if gjson.GetBytes(chunk, "usage").Exists() {
+		return true
+	}
+	has := false
+	gjson.GetBytes(chunk, "choices.0.delta").ForEach(func(_, value gjson.Result) bool {
+		has = value.Type != gjson.Null && value.String() != ""
+		return !has
+	})
+	return has
+}
+
+func callJSON(call Call) string {
+	out := `{"id":"","type":"function","function":{"name":"","arguments":""}}`
+	out, _ = sjson.Set(out, "id", call.ID)
+	out, _ = sjson.Set(out, "function.name", call.Name)
+	out, _ = sjson.Set(out, "function.arguments", call.Arguments)
+	return out
+}
+
+func sseLine(payload []byte) []byte {
+	return []byte("data: " + string(payload))
+}
@@ -0,0 +1,113 @@
+package toolemulation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+func TestParseHardenedMarkers(t *testing.T) {
+	text := "Let me check.\n[Called `get_weather` with args:\n{\"city\": \"Paris\",}]\nDone.\n" +
+		"[Called unknown_tool with args: {\"a\": 1}]\n" +
+		"[Called read_file with args: {\"path\": \"a]b.txt\", \"note\": \"line1\nline2\"}]"
+	clean, calls := Parse(text, []string{"get_weather", "read_file"})
+	if len(calls) != 2 {
+		t.Fatalf("Parse() calls = %+v", calls)
+	}
+	if calls[0].Name != "get_weather" || gjson.Get(calls[0].Arguments, "city").String() != "Paris" {
+		t.Fatalf("first call = %+v", calls[0])
+	}
+	if calls[1].Name != "read_file" || gjson.Get(calls[1].Arguments, "path").String() != "a]b.txt" || gjson.Get(calls[1].Arguments, "note").String() != "line1\nline2" {
+		t.Fatalf("second call = %+v", calls[1])
+	}
+	// Calls to undeclared tools stay in the text.
+	if !strings.Contains(clean, "unknown_tool") || strings.Contains(clean, "get_weather") || !strings.HasPrefix(clean, "Let me check.") {
+		t.Fatalf("clean text = %q", clean)
+	}
+
+	// Arguments cut off at the end of the reply are repaired.
+	_, calls = Parse(`[Called search with args: {"query": "go generics", "filters": ["recent"`, nil)
+	if len(calls) != 1 || gjson.Get(calls[0].Arguments, "filters.0").String() != "recent" {
+		t.Fatalf("truncated call = %+v", calls)
+	}
+}
+
+func TestRequestRendersToolsAndHistory(t *testing.T) {
+	body := []byte(`{"model":"m","tool_choice":"required","parallel_tool_calls":true,
+		"tools":[{"type":"function","function":{"name":"get_weather","description":"Current weather","parameters":{"type":"object","properties":{"city":{"type":"string"}}}}}],
+		"messages":[{"role":"system","content":"Be brief."},{"role":"user","content":"Weather in Paris?"},
+		{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Paris\"}"}}]},
+		{"role":"tool","tool_call_id":"call_1","content":"18C"}]}`)
+
+	out, names := Request(body)
+	if len(names) != 1 || names[0] != "get_weather" {
+		t.Fatalf("names = %v", names)
+	}
+	for _, field := range []string{"tools", "tool_choice", "parallel_tool_calls"} {
+		if gjson.GetBytes(out, field).Exists() {
+			t.Fatalf("%s not removed: %s", field, out)
+		}
+	}
+	system := gjson.GetBytes(out, "messages.0.content").String()
+	if !strings.HasPrefix(system, "Be brief.") || !strings.Contains(system, "get_weather: Current weather") || !strings.Contains(system, "must call at least one tool") {
+		t.Fatalf("system prompt = %q", system)
+	}
+	if got := gjson.GetBytes(out, "messages.2.content").String(); got != `[Called get_weather with args: {"city":"Paris"}]` {
+		t.Fatalf("assistant history = %q", got)
+	}
+	if role, got := gjson.GetBytes(out, "messages.3.role").String(), gjson.GetBytes(out, "messages.3.content").String(); role != "user" || got != "[Result of get_weather call call_1]\n18C" {
+		t.Fatalf("tool result = %s %q", role, got)
+	}
+
+	plain := []byte(`{"messages":[{"role":"user","content":"hi"}]}`)
+	if out, names = Request(plain); string(out) != string(plain) || names != nil {
+		t.Fatalf("request without tools changed: %s", out)
+	}
+}
+
+func TestResponseAndStream(t *testing.T) {
+	body := []byte(`{"id":"c1","choices":[{"index":0,"message":{"role":"assistant","content":"[Called get_weather with args: {\"city\":\"Paris\"}]"},"finish_reason":"stop"}]}`)
+	out := Response(body, []string{"get_weather"})
+	if gjson.GetBytes(out, "choices.0.finish_reason").String() != "tool_calls" || gjson.GetBytes(out, "choices.0.message.content").Type != gjson.Null {
+		t.Fatalf("Response() = %s", out)
+	}
+	if got := gjson.GetBytes(out, "choices.0.message.tool_calls.0.function.arguments").String(); got != `{"city":"Paris"}` {
+		t.Fatalf("arguments = %q", got)
+	}
+
+	deltas := []string{"Checking", " now.\n[Cal", "led get_weather with args: {\"ci", "ty\": \"Paris\"}", "]"}
+	s := NewStream([]string{"get_weather"})
+	var text strings.Builder
+	var calls []gjson.Result
+	var finish string
+	feed := func(line string) {
+		for _, chunk := range s.Process([]byte(line)) {
+			payload := gjson.ParseBytes(chunk[len("data: "):])
+			text.WriteString(payload.Get("choices.0.delta.content").String())
+			calls = append(calls, payload.Get("choices.0.delta.tool_calls").Array()...)
+			if f := payload.Get("choices.0.finish_reason").String(); f != "" {
+				finish = f
+			}
+		}
+	}
+	for _, delta := range deltas {
+		chunk, _ := sjson.Set(`{"id":"c1","model":"m","choices":[{"index":0,"delta":{"content":""},"finish_reason":null}]}`, "choices.0.delta.content", delta)
+		feed("data: " + chunk)
+		if strings.Contains(text.String(), "[Cal") {
+			t.Fatalf("marker leaked into text: %q", text.String())
+		}
+	}
+	feed(`data: {"id":"c1","model":"m","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`)
+
+	if text.String() != "Checking now.\n" {
+		t.Fatalf("streamed text = %q", text.String())
+	}
+	if len(calls) != 1 || calls[0].Get("function.name").String() != "get_weather" || calls[0].Get("index").Int() != 0 {
+		t.Fatalf("streamed calls = %v", calls)
+	}
+	if finish != "tool_calls" {
+		t.Fatalf("finish_reason = %q", finish)
+	}
+}
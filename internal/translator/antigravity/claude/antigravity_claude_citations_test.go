@@ -0,0 +1,34 @@
+package claude
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+const groundedAntigravityResponse = `{"response":{"responseId":"r1","candidates":[{"content":{"role":"model","parts":[{"text":"Paris is the capital."}]},"finishReason":"STOP",
+	"groundingMetadata":{"groundingChunks":[{"web":{"uri":"https://example.com/paris","title":"example.com"}}],
+	"groundingSupports":[{"segment":{"startIndex":0,"endIndex":5,"text":"Paris"},"groundingChunkIndices":[0]}]}}],
+	"usageMetadata":{"promptTokenCount":3,"candidatesTokenCount":5}}}`
+
+func TestConvertAntigravityGroundingToClaudeCitations(t *testing.T) {
+	requestJSON := []byte(`{"model":"gemini-3-pro"}`)
+	out := gjson.Parse(ConvertAntigravityResponseToClaudeNonStream(context.Background(), "", requestJSON, requestJSON, []byte(groundedAntigravityResponse), nil))
+	citation := out.Get("content.0.citations.0")
+	if citation.Get("type").String() != "web_search_result_location" || citation.Get("url").String() != "https://example.com/paris" {
+		t.Fatalf("content = %s", out.Get("content").Raw)
+	}
+	if got := citation.Get("cited_text").String(); got != "Paris" {
+		t.Fatalf("cited_text = %q", got)
+	}
+
+	var param any
+	stream := strings.Join(ConvertAntigravityResponseToClaude(context.Background(), "", requestJSON, requestJSON, []byte(groundedAntigravityResponse), &param), "")
+	delta := strings.Index(stream, `"citations_delta"`)
+	stop := strings.Index(stream, `"content_block_stop"`)
+	if delta < 0 || stop < delta || !strings.Contains(stream, `"url":"https://example.com/paris"`) {
+		t.Fatalf("stream without citations_delta before the block stops: %s", stream)
+	}
+}
@@ -252,6 +252,10 @@ func ConvertClaudeRequestToAntigravity(modelName string, inputRawJSON []byte, _
 							partJSON, _ = sjson.SetRaw(partJSON, "inlineData", inlineDataJSON)
 							clientContentJSON, _ = sjson.SetRaw(clientContentJSON, "parts.-1", partJSON)
 						}
+					} else if contentTypeResult.Type == gjson.String && contentTypeResult.String() == "document" {
+						if doc, ok := util.ClaudeDocument(contentResult); ok {
+							clientContentJSON, _ = sjson.SetRaw(clientContentJSON, "parts.-1", doc.GeminiPart())
+						}
 					}
 				}
 
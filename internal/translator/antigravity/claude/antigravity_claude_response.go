@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/cache"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/tidwall/gjson"
@@ -42,6 +43,9 @@ type Params struct {
 
 	// Signature caching support
 	CurrentThinkingText strings.Builder // Accumulates thinking text for signature caching
+
+	// Text accumulates the visible text so grounding offsets can be resolved.
+	Text strings.Builder
 }
 
 // toolUseIDCounter provides a process-wide unique counter for tool use identifiers.
@@ -185,6 +189,7 @@ func ConvertAntigravityResponseToClaude(_ context.Context, _ string, originalReq
 					finishReasonResult := gjson.GetBytes(rawJSON, "response.candidates.0.finishReason")
 					if partTextResult.String() != "" || !finishReasonResult.Exists() {
 						// Process regular text content (user-visible output)
+						params.Text.WriteString(partTextResult.String())
 						// Continue existing text block if already in content state
 						if params.ResponseType == 1 {
 							output = output + "event: content_block_delta\n"
@@ -271,6 +276,16 @@ func ConvertAntigravityResponseToClaude(_ context.Context, _ string, originalReq
 		}
 	}
 
+	// Grounding arrives with the last chunks and cites the text streamed so far; attach it
+	// to the open text block as citations_delta events.
+	if grounding := gjson.GetBytes(rawJSON, "response.candidates.0.groundingMetadata"); grounding.Exists() && params.ResponseType == 1 {
+		for _, citation := range util.GeminiCitations(grounding, params.Text.String()) {
+			output = output + "event: content_block_delta\n"
+			data, _ := sjson.SetRaw(fmt.Sprintf(`{"type":"content_block_delta","index":%d,"delta":{"type":"citations_delta","citation":{}}}`, params.ResponseIndex), "delta.citation", citation.ClaudeCitation())
+			output = output + fmt.Sprintf("data: %s\n\n\n", data)
+		}
+	}
+
 	if finishReasonResult := gjson.GetBytes(rawJSON, "response.candidates.0.finishReason"); finishReasonResult.Exists() {
 		params.HasFinishReason = true
 		params.FinishReason = finishReasonResult.String()
@@ -492,6 +507,25 @@ func ConvertAntigravityResponseToClaudeNonStream(_ context.Context, _ string, or
 	flushThinking()
 	flushText()
 
+	// Claude cites whole text blocks, so grounding sources go on the last text block.
+	if grounding := root.Get("response.candidates.0.groundingMetadata"); grounding.Exists() {
+		var text strings.Builder
+		lastText := -1
+		for i, block := range gjson.Get(responseJSON, "content").Array() {
+			if block.Get("type").String() == "text" {
+				text.WriteString(block.Get("text").String())
+				lastText = i
+			}
+		}
+		if citations := util.GeminiCitations(grounding, text.String()); lastText >= 0 && len(citations) > 0 {
+			path := fmt.Sprintf("content.%d.citations", lastText)
+			responseJSON, _ = sjson.SetRaw(responseJSON, path, "[]")
+			for _, citation := range citations {
+				responseJSON, _ = sjson.SetRaw(responseJSON, path+".-1", citation.ClaudeCitation())
+			}
+		}
+	}
+
 	stopReason := "end_turn"
 	if hasToolCall {
 		stopReason = "tool_use"
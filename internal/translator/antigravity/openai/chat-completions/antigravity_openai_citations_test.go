@@ -0,0 +1,33 @@
+package chat_completions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+const groundedAntigravityResponse = `{"response":{"responseId":"r1","candidates":[{"content":{"role":"model","parts":[{"text":"Paris is the capital."}]},"finishReason":"STOP",
+	"groundingMetadata":{"groundingChunks":[{"web":{"uri":"https://example.com/paris","title":"example.com"}}],
+	"groundingSupports":[{"segment":{"startIndex":0,"endIndex":5,"text":"Paris"},"groundingChunkIndices":[0]}]}}]}}`
+
+func TestConvertAntigravityGroundingToOpenAIAnnotations(t *testing.T) {
+	out := gjson.Parse(ConvertAntigravityResponseToOpenAINonStream(context.Background(), "", nil, nil, []byte(groundedAntigravityResponse), nil))
+	annotation := out.Get("choices.0.message.annotations.0")
+	if got := annotation.Get("url_citation.url").String(); got != "https://example.com/paris" {
+		t.Fatalf("annotations = %s", out.Get("choices.0.message.annotations").Raw)
+	}
+	if annotation.Get("url_citation.start_index").Int() != 0 || annotation.Get("url_citation.end_index").Int() != 5 {
+		t.Fatalf("span = %s", annotation.Raw)
+	}
+
+	var param any
+	chunks := ConvertAntigravityResponseToOpenAI(context.Background(), "", nil, nil, []byte(groundedAntigravityResponse), &param)
+	if len(chunks) != 1 {
+		t.Fatalf("chunks = %v", chunks)
+	}
+	annotation = gjson.Get(chunks[0], "choices.0.delta.annotations.0")
+	if annotation.Get("url_citation.url").String() != "https://example.com/paris" || annotation.Get("url_citation.end_index").Int() != 5 {
+		t.Fatalf("stream chunk without annotations: %s", chunks[0])
+	}
+}
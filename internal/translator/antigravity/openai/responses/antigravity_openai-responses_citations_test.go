@@ -0,0 +1,29 @@
+package responses
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+const groundedAntigravityResponse = `{"response":{"responseId":"r1","candidates":[{"content":{"role":"model","parts":[{"text":"Paris is the capital."}]},"finishReason":"STOP",
+	"groundingMetadata":{"groundingChunks":[{"web":{"uri":"https://example.com/paris","title":"example.com"}}],
+	"groundingSupports":[{"segment":{"startIndex":0,"endIndex":5,"text":"Paris"},"groundingChunkIndices":[0]}]}}],
+	"usageMetadata":{"promptTokenCount":3,"candidatesTokenCount":5}}}`
+
+func TestConvertAntigravityGroundingToResponsesAnnotations(t *testing.T) {
+	request := []byte(`{"model":"gemini-2.5-pro","input":"capital of France?"}`)
+	out := gjson.Parse(ConvertAntigravityResponseToOpenAIResponsesNonStream(context.Background(), "", request, request, []byte(groundedAntigravityResponse), nil))
+	annotation := out.Get(`output.#(type=="message").content.0.annotations.0`)
+	if annotation.Get("url").String() != "https://example.com/paris" || annotation.Get("end_index").Int() != 5 {
+		t.Fatalf("output = %s", out.Get("output").Raw)
+	}
+
+	var param any
+	stream := strings.Join(ConvertAntigravityResponseToOpenAIResponses(context.Background(), "", request, request, []byte(groundedAntigravityResponse), &param), "\n")
+	if !strings.Contains(stream, "response.output_text.annotation.added") || !strings.Contains(stream, `"url":"https://example.com/paris"`) {
+		t.Fatalf("stream without annotation events: %s", stream)
+	}
+}
@@ -0,0 +1,211 @@
+// Package claude provides translation between Claude Messages and Bedrock Converse formats.
+package claude
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/bedrock/common"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ConvertClaudeRequestToBedrock converts a Claude Messages request into a Bedrock
+// Converse request. The model is addressed by the request URL and is not part of the
+// body; Claude parameters without a Converse equivalent (thinking, top_k) are passed
+// through additionalModelRequestFields.
+func ConvertClaudeRequestToBedrock(modelName string, inputRawJSON []byte, _ bool) []byte {
+	rawJSON := bytes.Clone(inputRawJSON)
+	root := gjson.ParseBytes(rawJSON)
+	out := `{"messages":[]}`
+
+	// System prompt
+	system := `[]`
+	if sys := root.Get("system"); sys.IsArray() {
+		sys.ForEach(func(_, block gjson.Result) bool {
+			if block.Get("type").String() == "text" && block.Get("text").String() != "" {
+				system, _ = sjson.SetRaw(system, "-1", common.TextBlock(block.Get("text").String()))
+				if block.Get("cache_control").Exists() {
+					system, _ = sjson.SetRaw(system, "-1", common.CachePointBlock)
+				}
+			}
+			return true
+		})
+	} else if text := sys.String(); text != "" {
+		system, _ = sjson.SetRaw(system, "-1", common.TextBlock(text))
+	}
+	if system != `[]` {
+		out, _ = sjson.SetRaw(out, "system", system)
+	}
+
+	// Conversation
+	var conversation common.Conversation
+	root.Get("messages").ForEach(func(_, message gjson.Result) bool {
+		role := message.Get("role").String()
+		if role != "user" && role != "assistant" {
+			return true
+		}
+		content := message.Get("content")
+		if !content.IsArray() {
+			if text := content.String(); text != "" {
+				conversation.Add(role, common.TextBlock(text))
+			}
+			return true
+		}
+		var blocks []string
+		content.ForEach(func(_, block gjson.Result) bool {
+			blocks = append(blocks, convertClaudeContentBlock(block)...)
+			return true
+		})
+		conversation.Add(role, blocks...)
+		return true
+	})
+	out, _ = sjson.SetRaw(out, "messages", conversation.JSON())
+
+	// Inference parameters
+	if v := root.Get("max_tokens"); v.Exists() {
+		out, _ = sjson.Set(out, "inferenceConfig.maxTokens", v.Int())
+	}
+	if v := root.Get("temperature"); v.Exists() {
+		out, _ = sjson.Set(out, "inferenceConfig.temperature", v.Float())
+	}
+	if v := root.Get("top_p"); v.Exists() {
+		out, _ = sjson.Set(out, "inferenceConfig.topP", v.Float())
+	}
+	if v := root.Get("stop_sequences"); v.IsArray() && len(v.Array()) > 0 {
+		out, _ = sjson.SetRaw(out, "inferenceConfig.stopSequences", v.Raw)
+	}
+	if v := root.Get("top_k"); v.Exists() {
+		out, _ = sjson.Set(out, "additionalModelRequestFields.top_k", v.Int())
+	}
+	if v := root.Get("thinking"); v.IsObject() {
+		out, _ = sjson.SetRaw(out, "additionalModelRequestFields.thinking", v.Raw)
+	}
+
+	// Tools
+	tools := `[]`
+	root.Get("tools").ForEach(func(_, tool gjson.Result) bool {
+		// Server tools such as web_search have no Converse equivalent.
+		if typ := tool.Get("type").String(); typ != "" && typ != "custom" {
+			log.Debugf("bedrock: dropping unsupported Claude tool type %q for model %s", typ, modelName)
+			return true
+		}
+		tools, _ = sjson.SetRaw(tools, "-1", common.ToolSpec(tool.Get("name").String(), tool.Get("description").String(), tool.Get("input_schema")))
+		if tool.Get("cache_control").Exists() {
+			tools, _ = sjson.SetRaw(tools, "-1", common.CachePointBlock)
+		}
+		return true
+	})
+	if tools != `[]` {
+		out, _ = sjson.SetRaw(out, "toolConfig.tools", tools)
+		switch choice := root.Get("tool_choice"); choice.Get("type").String() {
+		case "auto":
+			out, _ = sjson.SetRaw(out, "toolConfig.toolChoice", `{"auto":{}}`)
+		case "any":
+			out, _ = sjson.SetRaw(out, "toolConfig.toolChoice", `{"any":{}}`)
+		case "tool":
+			out, _ = sjson.Set(out, "toolConfig.toolChoice.tool.name", choice.Get("name").String())
+		}
+	}
+
+	return []byte(out)
+}
+
+// convertClaudeContentBlock converts one Claude content block into Converse blocks,
+// followed by a cache point when the block carries cache_control.
+func convertClaudeContentBlock(block gjson.Result) []string {
+	var converted string
+	switch block.Get("type").String() {
+	case "text":
+		text := block.Get("text").String()
+		if text == "" {
+			return nil
+		}
+		converted = common.TextBlock(text)
+	case "image":
+		source := block.Get("source")
+		if source.Get("type").String() != "base64" {
+			log.Debugf("bedrock: dropping image with unsupported source type %q", source.Get("type").String())
+			return nil
+		}
+		var ok bool
+		if converted, ok = common.ImageBlock(source.Get("media_type").String(), source.Get("data").String()); !ok {
+			return nil
+		}
+	case "document":
+		source := block.Get("source")
+		mediaType, data := source.Get("media_type").String(), source.Get("data").String()
+		if source.Get("type").String() == "text" {
+			mediaType, data = "text/plain", base64.StdEncoding.EncodeToString([]byte(data))
+		} else if source.Get("type").String() != "base64" {
+			return nil
+		}
+		name := block.Get("title").String()
+		var ok bool
+		if converted, ok = common.DocumentBlock(mediaType, name, data); !ok {
+			return nil
+		}
+	case "tool_use":
+		converted = `{"toolUse":{"toolUseId":"","name":"","input":{}}}`
+		converted, _ = sjson.Set(converted, "toolUse.toolUseId", block.Get("id").String())
+		converted, _ = sjson.Set(converted, "toolUse.name", block.Get("name").String())
+		if input := block.Get("input"); input.IsObject() {
+			converted, _ = sjson.SetRaw(converted, "toolUse.input", input.Raw)
+		}
+	case "tool_result":
+		converted = `{"toolResult":{"toolUseId":"","content":[]}}`
+		converted, _ = sjson.Set(converted, "toolResult.toolUseId", block.Get("tool_use_id").String())
+		converted, _ = sjson.SetRaw(converted, "toolResult.content", convertClaudeToolResultContent(block.Get("content")))
+		if block.Get("is_error").Bool() {
+			converted, _ = sjson.Set(converted, "toolResult.status", "error")
+		}
+	case "thinking":
+		converted = `{"reasoningContent":{"reasoningText":{"text":""}}}`
+		converted, _ = sjson.Set(converted, "reasoningContent.reasoningText.text", block.Get("thinking").String())
+		if signature := block.Get("signature").String(); signature != "" {
+			converted, _ = sjson.Set(converted, "reasoningContent.reasoningText.signature", signature)
+		}
+	case "redacted_thinking":
+		converted, _ = sjson.Set(`{"reasoningContent":{"redactedContent":""}}`, "reasoningContent.redactedContent", block.Get("data").String())
+	default:
+		return nil
+	}
+	if block.Get("cache_control").Exists() {
+		return []string{converted, common.CachePointBlock}
+	}
+	return []string{converted}
+}
+
+// convertClaudeToolResultContent converts tool_result content into Converse tool result
+// blocks. Converse rejects empty results, so an empty result becomes an empty text.
+func convertClaudeToolResultContent(content gjson.Result) string {
+	out := `[]`
+	if content.IsArray() {
+		content.ForEach(func(_, part gjson.Result) bool {
+			switch part.Get("type").String() {
+			case "text":
+				out, _ = sjson.SetRaw(out, "-1", common.TextBlock(part.Get("text").String()))
+			case "image":
+				source := part.Get("source")
+				if block, ok := common.ImageBlock(source.Get("media_type").String(), source.Get("data").String()); ok {
+					out, _ = sjson.SetRaw(out, "-1", block)
+				}
+			default:
+				if part.IsObject() {
+					out, _ = sjson.SetRaw(out, "-1", fmt.Sprintf(`{"json":%s}`, part.Raw))
+				}
+			}
+			return true
+		})
+	} else if content.IsObject() {
+		out, _ = sjson.SetRaw(out, "-1", fmt.Sprintf(`{"json":%s}`, content.Raw))
+	} else if content.Exists() {
+		out, _ = sjson.SetRaw(out, "-1", common.TextBlock(content.String()))
+	}
+	if out == `[]` {
+		out, _ = sjson.SetRaw(out, "-1", common.TextBlock(""))
+	}
+	return out
+}
@@ -0,0 +1,183 @@
+package claude
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/bedrock/common"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// convertBedrockResponseToClaudeParams holds the state of a streamed response.
+type convertBedrockResponseToClaudeParams struct {
+	MessageID      string
+	MessageStarted bool
+	// OpenBlocks maps started Converse content block indexes to their Claude block type.
+	OpenBlocks map[int64]string
+	StopReason string
+}
+
+// ConvertBedrockStreamToClaude converts one Converse stream event into Claude SSE events.
+// The usage of the response arrives in the final metadata event, so message_delta and
+// message_stop are emitted from there.
+func ConvertBedrockStreamToClaude(_ context.Context, modelName string, _, _, rawJSON []byte, param *any) []string {
+	if *param == nil {
+		*param = &convertBedrockResponseToClaudeParams{OpenBlocks: make(map[int64]string)}
+	}
+	p := (*param).(*convertBedrockResponseToClaudeParams)
+
+	eventType, body := common.Event(rawJSON)
+	var results []string
+	if !p.MessageStarted && eventType != common.EventException {
+		p.MessageID = newMessageID()
+		start := `{"type":"message_start","message":{"id":"","type":"message","role":"assistant","model":"","content":[],"stop_reason":null,"stop_sequence":null,"usage":{"input_tokens":0,"output_tokens":0}}}`
+		start, _ = sjson.Set(start, "message.id", p.MessageID)
+		start, _ = sjson.Set(start, "message.model", modelName)
+		results = append(results, sseEvent("message_start", start))
+		p.MessageStarted = true
+	}
+
+	switch eventType {
+	case common.EventContentBlockStart:
+		index := body.Get("contentBlockIndex").Int()
+		if toolUse := body.Get("start.toolUse"); toolUse.Exists() {
+			block := `{"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"","name":"","input":{}}}`
+			block, _ = sjson.Set(block, "index", index)
+			block, _ = sjson.Set(block, "content_block.id", toolUse.Get("toolUseId").String())
+			block, _ = sjson.Set(block, "content_block.name", toolUse.Get("name").String())
+			results = append(results, sseEvent("content_block_start", block))
+			p.OpenBlocks[index] = "tool_use"
+		}
+	case common.EventContentBlockDelta:
+		index := body.Get("contentBlockIndex").Int()
+		delta := body.Get("delta")
+		switch {
+		case delta.Get("text").Exists():
+			results = append(results, p.startBlock(index, "text", `{"type":"text","text":""}`)...)
+			event, _ := sjson.Set(`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":""}}`, "delta.text", delta.Get("text").String())
+			event, _ = sjson.Set(event, "index", index)
+			results = append(results, sseEvent("content_block_delta", event))
+		case delta.Get("toolUse.input").Exists():
+			event, _ := sjson.Set(`{"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":""}}`, "delta.partial_json", delta.Get("toolUse.input").String())
+			event, _ = sjson.Set(event, "index", index)
+			results = append(results, sseEvent("content_block_delta", event))
+		case delta.Get("reasoningContent.redactedContent").Exists():
+			block, _ := sjson.Set(`{"type":"redacted_thinking","data":""}`, "data", delta.Get("reasoningContent.redactedContent").String())
+			results = append(results, p.startBlock(index, "redacted_thinking", block)...)
+		case delta.Get("reasoningContent.text").Exists():
+			results = append(results, p.startBlock(index, "thinking", `{"type":"thinking","thinking":""}`)...)
+			event, _ := sjson.Set(`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":""}}`, "delta.thinking", delta.Get("reasoningContent.text").String())
+			event, _ = sjson.Set(event, "index", index)
+			results = append(results, sseEvent("content_block_delta", event))
+		case delta.Get("reasoningContent.signature").Exists():
+			results = append(results, p.startBlock(index, "thinking", `{"type":"thinking","thinking":""}`)...)
+			event, _ := sjson.Set(`{"type":"content_block_delta","index":0,"delta":{"type":"signature_delta","signature":""}}`, "delta.signature", delta.Get("reasoningContent.signature").String())
+			event, _ = sjson.Set(event, "index", index)
+			results = append(results, sseEvent("content_block_delta", event))
+		}
+	case common.EventContentBlockStop:
+		index := body.Get("contentBlockIndex").Int()
+		if _, ok := p.OpenBlocks[index]; ok {
+			delete(p.OpenBlocks, index)
+			event, _ := sjson.Set(`{"type":"content_block_stop","index":0}`, "index", index)
+			results = append(results, sseEvent("content_block_stop", event))
+		}
+	case common.EventMessageStop:
+		p.StopReason = common.ClaudeStopReason(body.Get("stopReason").String())
+	case common.EventMetadata:
+		stopReason := p.StopReason
+		if stopReason == "" {
+			stopReason = "end_turn"
+		}
+		event := `{"type":"message_delta","delta":{"stop_reason":"","stop_sequence":null},"usage":{}}`
+		event, _ = sjson.Set(event, "delta.stop_reason", stopReason)
+		event, _ = sjson.SetRaw(event, "usage", claudeUsage(body.Get("usage")))
+		results = append(results, sseEvent("message_delta", event))
+		results = append(results, sseEvent("message_stop", `{"type":"message_stop"}`))
+	case common.EventException:
+		event := `{"type":"error","error":{"type":"api_error","message":""}}`
+		event, _ = sjson.Set(event, "error.message", body.Get("message").String())
+		results = append(results, sseEvent("error", event))
+	}
+	return results
+}
+
+// startBlock emits content_block_start the first time a Converse block is seen. Converse
+// only announces tool use blocks, so text and reasoning blocks start on their first delta.
+func (p *convertBedrockResponseToClaudeParams) startBlock(index int64, blockType, contentBlock string) []string {
+	if _, ok := p.OpenBlocks[index]; ok {
+		return nil
+	}
+	p.OpenBlocks[index] = blockType
+	event := `{"type":"content_block_start","index":0,"content_block":{}}`
+	event, _ = sjson.Set(event, "index", index)
+	event, _ = sjson.SetRaw(event, "content_block", contentBlock)
+	return []string{sseEvent("content_block_start", event)}
+}
+
+// ConvertBedrockNonStreamToClaude converts a Converse response into a Claude message.
+func ConvertBedrockNonStreamToClaude(_ context.Context, modelName string, _, _, rawJSON []byte, _ *any) string {
+	root := gjson.ParseBytes(rawJSON)
+	out := `{"id":"","type":"message","role":"assistant","model":"","content":[],"stop_reason":"","stop_sequence":null,"usage":{}}`
+	out, _ = sjson.Set(out, "id", newMessageID())
+	out, _ = sjson.Set(out, "model", modelName)
+
+	root.Get("output.message.content").ForEach(func(_, block gjson.Result) bool {
+		switch {
+		case block.Get("text").Exists():
+			text, _ := sjson.Set(`{"type":"text","text":""}`, "text", block.Get("text").String())
+			out, _ = sjson.SetRaw(out, "content.-1", text)
+		case block.Get("toolUse").Exists():
+			toolUse := `{"type":"tool_use","id":"","name":"","input":{}}`
+			toolUse, _ = sjson.Set(toolUse, "id", block.Get("toolUse.toolUseId").String())
+			toolUse, _ = sjson.Set(toolUse, "name", block.Get("toolUse.name").String())
+			if input := block.Get("toolUse.input"); input.IsObject() {
+				toolUse, _ = sjson.SetRaw(toolUse, "input", input.Raw)
+			}
+			out, _ = sjson.SetRaw(out, "content.-1", toolUse)
+		case block.Get("reasoningContent.reasoningText").Exists():
+			reasoning := block.Get("reasoningContent.reasoningText")
+			thinking, _ := sjson.Set(`{"type":"thinking","thinking":"","signature":""}`, "thinking", reasoning.Get("text").String())
+			thinking, _ = sjson.Set(thinking, "signature", reasoning.Get("signature").String())
+			out, _ = sjson.SetRaw(out, "content.-1", thinking)
+		case block.Get("reasoningContent.redactedContent").Exists():
+			redacted, _ := sjson.Set(`{"type":"redacted_thinking","data":""}`, "data", block.Get("reasoningContent.redactedContent").String())
+			out, _ = sjson.SetRaw(out, "content.-1", redacted)
+		}
+		return true
+	})
+
+	out, _ = sjson.Set(out, "stop_reason", common.ClaudeStopReason(root.Get("stopReason").String()))
+	out, _ = sjson.SetRaw(out, "usage", claudeUsage(root.Get("usage")))
+	return out
+}
+
+// ClaudeTokenCount renders a token count as a Claude count_tokens response.
+func ClaudeTokenCount(_ context.Context, count int64) string {
+	return fmt.Sprintf(`{"input_tokens":%d}`, count)
+}
+
+// claudeUsage converts Converse usage into a Claude usage object.
+func claudeUsage(usage gjson.Result) string {
+	out := `{"input_tokens":0,"output_tokens":0}`
+	out, _ = sjson.Set(out, "input_tokens", usage.Get("inputTokens").Int())
+	out, _ = sjson.Set(out, "output_tokens", usage.Get("outputTokens").Int())
+	if v := usage.Get("cacheReadInputTokens"); v.Exists() {
+		out, _ = sjson.Set(out, "cache_read_input_tokens", v.Int())
+	}
+	if v := usage.Get("cacheWriteInputTokens"); v.Exists() {
+		out, _ = sjson.Set(out, "cache_creation_input_tokens", v.Int())
+	}
+	return out
+}
+
+func sseEvent(event, data string) string {
+	return "event: " + event + "\ndata: " + data + "\n\n"
+}
+
+func newMessageID() string {
+	return "msg_bdrk_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+}
@@ -0,0 +1,20 @@
+package claude
+
+import (
+	. "github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/translator"
+)
+
+func init() {
+	translator.Register(
+		Claude,
+		Bedrock,
+		ConvertClaudeRequestToBedrock,
+		interfaces.TranslateResponse{
+			Stream:     ConvertBedrockStreamToClaude,
+			NonStream:  ConvertBedrockNonStreamToClaude,
+			TokenCount: ClaudeTokenCount,
+		},
+	)
+}
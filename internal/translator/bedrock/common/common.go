@@ -0,0 +1,215 @@
+// Package common provides helpers shared by the Bedrock Converse translators.
+package common
+
+import (
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Converse stream event types, as found in the ":event-type" header. The executor
+// forwards each event as a JSON object keyed by its type, e.g.
+// {"contentBlockDelta":{"contentBlockIndex":0,"delta":{"text":"Hi"}}}.
+const (
+	EventMessageStart      = "messageStart"
+	EventContentBlockStart = "contentBlockStart"
+	EventContentBlockDelta = "contentBlockDelta"
+	EventContentBlockStop  = "contentBlockStop"
+	EventMessageStop       = "messageStop"
+	EventMetadata          = "metadata"
+	// EventException carries stream errors reported with an ":exception-type" header.
+	EventException = "exception"
+)
+
+// Event returns the type and body of a stream event forwarded by the executor.
+func Event(rawJSON []byte) (string, gjson.Result) {
+	var eventType string
+	var body gjson.Result
+	gjson.ParseBytes(rawJSON).ForEach(func(key, value gjson.Result) bool {
+		eventType = key.String()
+		body = value
+		return false
+	})
+	return eventType, body
+}
+
+// Conversation accumulates Converse messages, merging consecutive turns of the same
+// role because Converse requires user and assistant turns to alternate.
+type Conversation struct {
+	roles    []string
+	contents [][]string
+}
+
+// Add appends content blocks to the conversation under role.
+func (c *Conversation) Add(role string, blocks ...string) {
+	if len(blocks) == 0 {
+		return
+	}
+	if n := len(c.roles); n > 0 && c.roles[n-1] == role {
+		c.contents[n-1] = append(c.contents[n-1], blocks...)
+		return
+	}
+	c.roles = append(c.roles, role)
+	c.contents = append(c.contents, blocks)
+}
+
+// JSON renders the conversation as a Converse messages array.
+func (c *Conversation) JSON() string {
+	out := `[]`
+	for i, role := range c.roles {
+		message := `{"role":"","content":[]}`
+		message, _ = sjson.Set(message, "role", role)
+		message, _ = sjson.SetRaw(message, "content", "["+strings.Join(c.contents[i], ",")+"]")
+		out, _ = sjson.SetRaw(out, "-1", message)
+	}
+	return out
+}
+
+// TextBlock builds a text content block.
+func TextBlock(text string) string {
+	block, _ := sjson.Set(`{"text":""}`, "text", text)
+	return block
+}
+
+// CachePointBlock marks the end of a cacheable prompt prefix.
+const CachePointBlock = `{"cachePoint":{"type":"default"}}`
+
+// ImageBlock builds an image content block from base64 data, reporting false for media
+// types Converse does not accept.
+func ImageBlock(mediaType, data string) (string, bool) {
+	format := ImageFormat(mediaType)
+	if format == "" || data == "" {
+		return "", false
+	}
+	block := `{"image":{"format":"","source":{"bytes":""}}}`
+	block, _ = sjson.Set(block, "image.format", format)
+	block, _ = sjson.Set(block, "image.source.bytes", data)
+	return block, true
+}
+
+// DocumentBlock builds a document content block from base64 data, reporting false for
+// media types Converse does not accept.
+func DocumentBlock(mediaType, name, data string) (string, bool) {
+	format := DocumentFormat(mediaType)
+	if format == "" || data == "" {
+		return "", false
+	}
+	if name = sanitizeDocumentName(name); name == "" {
+		name = "document"
+	}
+	block := `{"document":{"format":"","name":"","source":{"bytes":""}}}`
+	block, _ = sjson.Set(block, "document.format", format)
+	block, _ = sjson.Set(block, "document.name", name)
+	block, _ = sjson.Set(block, "document.source.bytes", data)
+	return block, true
+}
+
+// ToolSpec builds a tool definition.
+func ToolSpec(name, description string, schema gjson.Result) string {
+	spec := `{"toolSpec":{"name":"","inputSchema":{"json":{}}}}`
+	spec, _ = sjson.Set(spec, "toolSpec.name", name)
+	if description != "" {
+		spec, _ = sjson.Set(spec, "toolSpec.description", description)
+	}
+	if schema.IsObject() {
+		spec, _ = sjson.SetRaw(spec, "toolSpec.inputSchema.json", schema.Raw)
+	} else {
+		spec, _ = sjson.SetRaw(spec, "toolSpec.inputSchema.json", `{"type":"object","properties":{}}`)
+	}
+	return spec
+}
+
+// ImageFormat maps an image media type to its Converse format name.
+func ImageFormat(mediaType string) string {
+	switch strings.ToLower(strings.TrimSpace(mediaType)) {
+	case "image/png":
+		return "png"
+	case "image/jpeg", "image/jpg":
+		return "jpeg"
+	case "image/gif":
+		return "gif"
+	case "image/webp":
+		return "webp"
+	}
+	return ""
+}
+
+// DocumentFormat maps a document media type to its Converse format name.
+func DocumentFormat(mediaType string) string {
+	switch strings.ToLower(strings.TrimSpace(mediaType)) {
+	case "application/pdf":
+		return "pdf"
+	case "text/plain":
+		return "txt"
+	case "text/csv":
+		return "csv"
+	case "text/html":
+		return "html"
+	case "text/markdown":
+		return "md"
+	case "application/msword":
+		return "doc"
+	case "application/vnd.openxmlformats-officedocument.wordprocessingml.document":
+		return "docx"
+	case "application/vnd.ms-excel":
+		return "xls"
+	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		return "xlsx"
+	}
+	return ""
+}
+
+// ParseDataURL splits a base64 data URL into its media type and data.
+func ParseDataURL(url string) (string, string, bool) {
+	if !strings.HasPrefix(url, "data:") {
+		return "", "", false
+	}
+	meta, data, ok := strings.Cut(strings.TrimPrefix(url, "data:"), ",")
+	if !ok || !strings.HasSuffix(meta, ";base64") {
+		return "", "", false
+	}
+	return strings.TrimSuffix(meta, ";base64"), data, true
+}
+
+// ClaudeStopReason maps a Converse stop reason to a Claude stop_reason.
+func ClaudeStopReason(stopReason string) string {
+	switch stopReason {
+	case "tool_use", "max_tokens", "stop_sequence":
+		return stopReason
+	case "guardrail_intervened", "content_filtered":
+		return "refusal"
+	default:
+		return "end_turn"
+	}
+}
+
+// OpenAIFinishReason maps a Converse stop reason to an OpenAI finish_reason.
+func OpenAIFinishReason(stopReason string) string {
+	switch stopReason {
+	case "tool_use":
+		return "tool_calls"
+	case "max_tokens":
+		return "length"
+	case "guardrail_intervened", "content_filtered":
+		return "content_filter"
+	default:
+		return "stop"
+	}
+}
+
+// sanitizeDocumentName keeps the characters Converse allows in document names:
+// alphanumerics, whitespace, hyphens, parentheses and square brackets.
+func sanitizeDocumentName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9',
+			r == ' ', r == '-', r == '(', r == ')', r == '[', r == ']':
+			b.WriteRune(r)
+		case r == '.' || r == '_':
+			b.WriteByte('-')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
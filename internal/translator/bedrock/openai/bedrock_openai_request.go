@@ -0,0 +1,177 @@
+// Package openai provides translation between OpenAI Chat Completions and Bedrock Converse formats.
+package openai
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/bedrock/common"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ConvertOpenAIRequestToBedrock converts an OpenAI Chat Completions request into a
+// Bedrock Converse request. System and developer messages become the system prompt,
+// tool messages become tool results of the following user turn, and reasoning_effort
+// is mapped to a Claude thinking budget.
+func ConvertOpenAIRequestToBedrock(modelName string, inputRawJSON []byte, _ bool) []byte {
+	rawJSON := bytes.Clone(inputRawJSON)
+	root := gjson.ParseBytes(rawJSON)
+	out := `{"messages":[]}`
+
+	system := `[]`
+	var conversation common.Conversation
+	root.Get("messages").ForEach(func(_, message gjson.Result) bool {
+		content := message.Get("content")
+		switch message.Get("role").String() {
+		case "system", "developer":
+			if text := openAIText(content); text != "" {
+				system, _ = sjson.SetRaw(system, "-1", common.TextBlock(text))
+			}
+		case "user":
+			conversation.Add("user", convertOpenAIUserContent(content)...)
+		case "assistant":
+			var blocks []string
+			if text := openAIText(content); text != "" {
+				blocks = append(blocks, common.TextBlock(text))
+			}
+			message.Get("tool_calls").ForEach(func(_, call gjson.Result) bool {
+				if call.Get("type").String() != "" && call.Get("type").String() != "function" {
+					return true
+				}
+				toolUse := `{"toolUse":{"toolUseId":"","name":"","input":{}}}`
+				toolUse, _ = sjson.Set(toolUse, "toolUse.toolUseId", call.Get("id").String())
+				toolUse, _ = sjson.Set(toolUse, "toolUse.name", call.Get("function.name").String())
+				if args := gjson.Parse(call.Get("function.arguments").String()); args.IsObject() {
+					toolUse, _ = sjson.SetRaw(toolUse, "toolUse.input", args.Raw)
+				}
+				blocks = append(blocks, toolUse)
+				return true
+			})
+			conversation.Add("assistant", blocks...)
+		case "tool":
+			result := `{"toolResult":{"toolUseId":"","content":[]}}`
+			result, _ = sjson.Set(result, "toolResult.toolUseId", message.Get("tool_call_id").String())
+			result, _ = sjson.SetRaw(result, "toolResult.content", "["+common.TextBlock(openAIText(content))+"]")
+			conversation.Add("user", result)
+		}
+		return true
+	})
+	if system != `[]` {
+		out, _ = sjson.SetRaw(out, "system", system)
+	}
+	out, _ = sjson.SetRaw(out, "messages", conversation.JSON())
+
+	// Inference parameters
+	if v := root.Get("max_completion_tokens"); v.Exists() {
+		out, _ = sjson.Set(out, "inferenceConfig.maxTokens", v.Int())
+	} else if v = root.Get("max_tokens"); v.Exists() {
+		out, _ = sjson.Set(out, "inferenceConfig.maxTokens", v.Int())
+	}
+	if v := root.Get("temperature"); v.Exists() {
+		out, _ = sjson.Set(out, "inferenceConfig.temperature", v.Float())
+	}
+	if v := root.Get("top_p"); v.Exists() {
+		out, _ = sjson.Set(out, "inferenceConfig.topP", v.Float())
+	}
+	if stop := root.Get("stop"); stop.IsArray() && len(stop.Array()) > 0 {
+		out, _ = sjson.SetRaw(out, "inferenceConfig.stopSequences", stop.Raw)
+	} else if stop.Type == gjson.String && stop.String() != "" {
+		out, _ = sjson.Set(out, "inferenceConfig.stopSequences", []string{stop.String()})
+	}
+
+	// Convert OpenAI reasoning_effort to Claude thinking config.
+	if effort := strings.ToLower(strings.TrimSpace(root.Get("reasoning_effort").String())); effort != "" {
+		if budget, ok := thinking.ConvertLevelToBudget(effort); ok {
+			switch {
+			case budget == 0:
+				out, _ = sjson.Set(out, "additionalModelRequestFields.thinking.type", "disabled")
+			case budget > 0:
+				out, _ = sjson.Set(out, "additionalModelRequestFields.thinking.type", "enabled")
+				out, _ = sjson.Set(out, "additionalModelRequestFields.thinking.budget_tokens", budget)
+			}
+		}
+	}
+
+	// Tools
+	tools := `[]`
+	root.Get("tools").ForEach(func(_, tool gjson.Result) bool {
+		if tool.Get("type").String() != "function" {
+			log.Debugf("bedrock: dropping unsupported OpenAI tool type %q for model %s", tool.Get("type").String(), modelName)
+			return true
+		}
+		fn := tool.Get("function")
+		tools, _ = sjson.SetRaw(tools, "-1", common.ToolSpec(fn.Get("name").String(), fn.Get("description").String(), fn.Get("parameters")))
+		return true
+	})
+	if tools != `[]` {
+		out, _ = sjson.SetRaw(out, "toolConfig.tools", tools)
+		choice := root.Get("tool_choice")
+		switch {
+		case choice.String() == "auto":
+			out, _ = sjson.SetRaw(out, "toolConfig.toolChoice", `{"auto":{}}`)
+		case choice.String() == "required":
+			out, _ = sjson.SetRaw(out, "toolConfig.toolChoice", `{"any":{}}`)
+		case choice.Get("function.name").Exists():
+			out, _ = sjson.Set(out, "toolConfig.toolChoice.tool.name", choice.Get("function.name").String())
+		}
+	}
+
+	return []byte(out)
+}
+
+// convertOpenAIUserContent converts user message content into Converse blocks. Images
+// and files must be inline data URLs; remote URLs are not supported by Converse.
+func convertOpenAIUserContent(content gjson.Result) []string {
+	if !content.IsArray() {
+		if text := content.String(); text != "" {
+			return []string{common.TextBlock(text)}
+		}
+		return nil
+	}
+	var blocks []string
+	content.ForEach(func(_, part gjson.Result) bool {
+		switch part.Get("type").String() {
+		case "text":
+			if text := part.Get("text").String(); text != "" {
+				blocks = append(blocks, common.TextBlock(text))
+			}
+		case "image_url":
+			mediaType, data, ok := common.ParseDataURL(part.Get("image_url.url").String())
+			if !ok {
+				log.Debug("bedrock: dropping image that is not an inline data URL")
+				return true
+			}
+			if block, okBlock := common.ImageBlock(mediaType, data); okBlock {
+				blocks = append(blocks, block)
+			}
+		case "file":
+			mediaType, data, ok := common.ParseDataURL(part.Get("file.file_data").String())
+			if !ok {
+				return true
+			}
+			if block, okBlock := common.DocumentBlock(mediaType, part.Get("file.filename").String(), data); okBlock {
+				blocks = append(blocks, block)
+			}
+		}
+		return true
+	})
+	return blocks
+}
+
+// openAIText returns the text of string content or the joined text parts of array content.
+func openAIText(content gjson.Result) string {
+	if !content.IsArray() {
+		return content.String()
+	}
+	var parts []string
+	content.ForEach(func(_, part gjson.Result) bool {
+		if part.Get("type").String() == "text" {
+			parts = append(parts, part.Get("text").String())
+		}
+		return true
+	})
+	return strings.Join(parts, "\n")
+}
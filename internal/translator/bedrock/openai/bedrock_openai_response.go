@@ -0,0 +1,158 @@
+package openai
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/bedrock/common"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// convertBedrockResponseToOpenAIParams holds the state of a streamed response.
+type convertBedrockResponseToOpenAIParams struct {
+	ResponseID string
+	CreatedAt  int64
+	// ToolCallIndexes maps Converse content block indexes to OpenAI tool call indexes.
+	ToolCallIndexes map[int64]int
+	FinishReason    string
+}
+
+// ConvertBedrockStreamToOpenAI converts one Converse stream event into OpenAI chat
+// completion chunks. The finish reason is held back until the metadata event so it is
+// sent together with the usage.
+func ConvertBedrockStreamToOpenAI(_ context.Context, modelName string, _, _, rawJSON []byte, param *any) []string {
+	if *param == nil {
+		*param = &convertBedrockResponseToOpenAIParams{
+			ResponseID:      newCompletionID(),
+			CreatedAt:       time.Now().Unix(),
+			ToolCallIndexes: make(map[int64]int),
+		}
+	}
+	p := (*param).(*convertBedrockResponseToOpenAIParams)
+
+	chunk := `{"id":"","object":"chat.completion.chunk","created":0,"model":"","choices":[{"index":0,"delta":{},"finish_reason":null}]}`
+	chunk, _ = sjson.Set(chunk, "id", p.ResponseID)
+	chunk, _ = sjson.Set(chunk, "created", p.CreatedAt)
+	chunk, _ = sjson.Set(chunk, "model", modelName)
+
+	eventType, body := common.Event(rawJSON)
+	switch eventType {
+	case common.EventMessageStart:
+		chunk, _ = sjson.Set(chunk, "choices.0.delta.role", "assistant")
+		chunk, _ = sjson.Set(chunk, "choices.0.delta.content", "")
+		return []string{chunk}
+	case common.EventContentBlockStart:
+		toolUse := body.Get("start.toolUse")
+		if !toolUse.Exists() {
+			return nil
+		}
+		index := len(p.ToolCallIndexes)
+		p.ToolCallIndexes[body.Get("contentBlockIndex").Int()] = index
+		call := `{"index":0,"id":"","type":"function","function":{"name":"","arguments":""}}`
+		call, _ = sjson.Set(call, "index", index)
+		call, _ = sjson.Set(call, "id", toolUse.Get("toolUseId").String())
+		call, _ = sjson.Set(call, "function.name", toolUse.Get("name").String())
+		chunk, _ = sjson.SetRaw(chunk, "choices.0.delta.tool_calls", "["+call+"]")
+		return []string{chunk}
+	case common.EventContentBlockDelta:
+		delta := body.Get("delta")
+		switch {
+		case delta.Get("text").Exists():
+			chunk, _ = sjson.Set(chunk, "choices.0.delta.content", delta.Get("text").String())
+		case delta.Get("toolUse.input").Exists():
+			index, ok := p.ToolCallIndexes[body.Get("contentBlockIndex").Int()]
+			if !ok {
+				return nil
+			}
+			call := `{"index":0,"function":{"arguments":""}}`
+			call, _ = sjson.Set(call, "index", index)
+			call, _ = sjson.Set(call, "function.arguments", delta.Get("toolUse.input").String())
+			chunk, _ = sjson.SetRaw(chunk, "choices.0.delta.tool_calls", "["+call+"]")
+		case delta.Get("reasoningContent.text").Exists():
+			chunk, _ = sjson.Set(chunk, "choices.0.delta.reasoning_content", delta.Get("reasoningContent.text").String())
+		default:
+			return nil
+		}
+		return []string{chunk}
+	case common.EventMessageStop:
+		p.FinishReason = common.OpenAIFinishReason(body.Get("stopReason").String())
+		return nil
+	case common.EventMetadata:
+		finishReason := p.FinishReason
+		if finishReason == "" {
+			finishReason = "stop"
+		}
+		chunk, _ = sjson.Set(chunk, "choices.0.finish_reason", finishReason)
+		chunk, _ = sjson.SetRaw(chunk, "usage", openAIUsage(body.Get("usage")))
+		return []string{chunk}
+	case common.EventException:
+		errorJSON := `{"error":{"message":"","type":"server_error"}}`
+		errorJSON, _ = sjson.Set(errorJSON, "error.message", body.Get("message").String())
+		return []string{errorJSON}
+	}
+	return nil
+}
+
+// ConvertBedrockNonStreamToOpenAI converts a Converse response into an OpenAI chat completion.
+func ConvertBedrockNonStreamToOpenAI(_ context.Context, modelName string, _, _, rawJSON []byte, _ *any) string {
+	root := gjson.ParseBytes(rawJSON)
+	out := `{"id":"","object":"chat.completion","created":0,"model":"","choices":[{"index":0,"message":{"role":"assistant","content":null},"finish_reason":""}]}`
+	out, _ = sjson.Set(out, "id", newCompletionID())
+	out, _ = sjson.Set(out, "created", time.Now().Unix())
+	out, _ = sjson.Set(out, "model", modelName)
+
+	var text, reasoning strings.Builder
+	toolCalls := `[]`
+	root.Get("output.message.content").ForEach(func(_, block gjson.Result) bool {
+		switch {
+		case block.Get("text").Exists():
+			text.WriteString(block.Get("text").String())
+		case block.Get("reasoningContent.reasoningText.text").Exists():
+			reasoning.WriteString(block.Get("reasoningContent.reasoningText.text").String())
+		case block.Get("toolUse").Exists():
+			call := `{"id":"","type":"function","function":{"name":"","arguments":"{}"}}`
+			call, _ = sjson.Set(call, "id", block.Get("toolUse.toolUseId").String())
+			call, _ = sjson.Set(call, "function.name", block.Get("toolUse.name").String())
+			if input := block.Get("toolUse.input"); input.IsObject() {
+				call, _ = sjson.Set(call, "function.arguments", input.Raw)
+			}
+			toolCalls, _ = sjson.SetRaw(toolCalls, "-1", call)
+		}
+		return true
+	})
+	if text.Len() > 0 {
+		out, _ = sjson.Set(out, "choices.0.message.content", text.String())
+	}
+	if reasoning.Len() > 0 {
+		out, _ = sjson.Set(out, "choices.0.message.reasoning_content", reasoning.String())
+	}
+	if toolCalls != `[]` {
+		out, _ = sjson.SetRaw(out, "choices.0.message.tool_calls", toolCalls)
+	}
+	out, _ = sjson.Set(out, "choices.0.finish_reason", common.OpenAIFinishReason(root.Get("stopReason").String()))
+	out, _ = sjson.SetRaw(out, "usage", openAIUsage(root.Get("usage")))
+	return out
+}
+
+// openAIUsage converts Converse usage into an OpenAI usage object. Cached and
+// cache-written tokens are part of the prompt.
+func openAIUsage(usage gjson.Result) string {
+	cacheRead := usage.Get("cacheReadInputTokens").Int()
+	prompt := usage.Get("inputTokens").Int() + cacheRead + usage.Get("cacheWriteInputTokens").Int()
+	completion := usage.Get("outputTokens").Int()
+	out := `{"prompt_tokens":0,"completion_tokens":0,"total_tokens":0}`
+	out, _ = sjson.Set(out, "prompt_tokens", prompt)
+	out, _ = sjson.Set(out, "completion_tokens", completion)
+	out, _ = sjson.Set(out, "total_tokens", prompt+completion)
+	if cacheRead > 0 {
+		out, _ = sjson.Set(out, "prompt_tokens_details.cached_tokens", cacheRead)
+	}
+	return out
+}
+
+func newCompletionID() string {
+	return "chatcmpl-" + strings.ReplaceAll(uuid.NewString(), "-", "")
+}
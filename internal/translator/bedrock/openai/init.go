@@ -0,0 +1,19 @@
+package openai
+
+import (
+	. "github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/translator"
+)
+
+func init() {
+	translator.Register(
+		OpenAI,  // source format
+		Bedrock, // target format
+		ConvertOpenAIRequestToBedrock,
+		interfaces.TranslateResponse{
+			Stream:    ConvertBedrockStreamToOpenAI,
+			NonStream: ConvertBedrockNonStreamToOpenAI,
+		},
+	)
+}
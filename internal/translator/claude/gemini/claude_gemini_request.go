@@ -253,6 +253,12 @@ func ConvertGeminiRequestToClaude(modelName string, inputRawJSON []byte, stream
 						return true
 					}
 
+					// PDF and text attachments become Claude document blocks
+					if doc, ok := util.GeminiDocument(part); ok {
+						msg, _ = sjson.SetRaw(msg, "content.-1", doc.ClaudeBlock())
+						return true
+					}
+
 					// Image content (inline_data) conversion to Claude Code format
 					if inlineData := part.Get("inline_data"); inlineData.Exists() {
 						imageContent := `{"type":"image","source":{"type":"base64","media_type":"","data":""}}`
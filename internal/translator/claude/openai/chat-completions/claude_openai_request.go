@@ -16,6 +16,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -200,6 +201,11 @@ func ConvertOpenAIRequestToClaude(modelName string, inputRawJSON []byte, stream
 									msg, _ = sjson.SetRaw(msg, "content.-1", imagePart)
 								}
 							}
+
+						case "file":
+							if doc, ok := util.OpenAIFileDocument(part); ok {
+								msg, _ = sjson.SetRaw(msg, "content.-1", doc.ClaudeBlock())
+							}
 						}
 						return true
 					})
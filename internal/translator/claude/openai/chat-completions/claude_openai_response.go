@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -27,6 +28,8 @@ type ConvertAnthropicResponseToOpenAIParams struct {
 	FinishReason string
 	// Tool calls accumulator for streaming
 	ToolCallsAccumulator map[int]*ToolCallAccumulator
+	// Citations maps web citations of text blocks to annotation spans
+	Citations util.ClaudeCitationStream
 }
 
 // ToolCallAccumulator holds the state for accumulating tool call data
@@ -130,6 +133,9 @@ func ConvertClaudeResponseToOpenAI(_ context.Context, modelName string, original
 				// Don't output anything yet - wait for complete tool call
 				return []string{}
 			}
+			if blockType == "text" {
+				(*param).(*ConvertAnthropicResponseToOpenAIParams).Citations.StartBlock(contentBlock)
+			}
 		}
 		return []string{}
 
@@ -144,8 +150,13 @@ func ConvertClaudeResponseToOpenAI(_ context.Context, modelName string, original
 				// Text content delta - send incremental text updates
 				if text := delta.Get("text"); text.Exists() {
 					template, _ = sjson.Set(template, "choices.0.delta.content", text.String())
+					(*param).(*ConvertAnthropicResponseToOpenAIParams).Citations.AddText(text.String())
 					hasContent = true
 				}
+			case "citations_delta":
+				// Citations are emitted as annotations once the text block is complete
+				(*param).(*ConvertAnthropicResponseToOpenAIParams).Citations.AddCitation(delta.Get("citation"))
+				return []string{}
 			case "thinking_delta":
 				// Accumulate reasoning/thinking content
 				if thinking := delta.Get("thinking"); thinking.Exists() {
@@ -194,6 +205,14 @@ func ConvertClaudeResponseToOpenAI(_ context.Context, modelName string, original
 				return []string{template}
 			}
 		}
+		// End of a text block - emit its citations as url_citation annotations
+		if citations := (*param).(*ConvertAnthropicResponseToOpenAIParams).Citations.StopBlock(); len(citations) > 0 {
+			template, _ = sjson.SetRaw(template, "choices.0.delta.annotations", `[]`)
+			for _, citation := range citations {
+				template, _ = sjson.SetRaw(template, "choices.0.delta.annotations.-1", citation.OpenAIAnnotation())
+			}
+			return []string{template}
+		}
 		return []string{}
 
 	case "message_delta":
@@ -291,6 +310,8 @@ func ConvertClaudeResponseToOpenAINonStream(_ context.Context, _ string, origina
 	var stopReason string
 	var contentParts []string
 	var reasoningParts []string
+	var citationStream util.ClaudeCitationStream
+	var annotations []string
 	toolCallsAccumulator := make(map[int]*ToolCallAccumulator)
 
 	for _, chunk := range chunks {
@@ -320,6 +341,8 @@ func ConvertClaudeResponseToOpenAINonStream(_ context.Context, _ string, origina
 						ID:   contentBlock.Get("id").String(),
 						Name: contentBlock.Get("name").String(),
 					}
+				} else if blockType == "text" {
+					citationStream.StartBlock(contentBlock)
 				}
 			}
 
@@ -332,7 +355,10 @@ func ConvertClaudeResponseToOpenAINonStream(_ context.Context, _ string, origina
 					// Accumulate text content
 					if text := delta.Get("text"); text.Exists() {
 						contentParts = append(contentParts, text.String())
+						citationStream.AddText(text.String())
 					}
+				case "citations_delta":
+					citationStream.AddCitation(delta.Get("citation"))
 				case "thinking_delta":
 					// Accumulate reasoning/thinking content
 					if thinking := delta.Get("thinking"); thinking.Exists() {
@@ -357,6 +383,9 @@ func ConvertClaudeResponseToOpenAINonStream(_ context.Context, _ string, origina
 					accumulator.Arguments.WriteString("{}")
 				}
 			}
+			for _, citation := range citationStream.StopBlock() {
+				annotations = append(annotations, citation.OpenAIAnnotation())
+			}
 
 		case "message_delta":
 			// Extract stop reason and output token count when message ends
@@ -386,6 +415,9 @@ func ConvertClaudeResponseToOpenAINonStream(_ context.Context, _ string, origina
 	// Set message content by combining all text parts
 	messageContent := strings.Join(contentParts, "")
 	out, _ = sjson.Set(out, "choices.0.message.content", messageContent)
+	if len(annotations) > 0 {
+		out, _ = sjson.SetRaw(out, "choices.0.message.annotations", "["+strings.Join(annotations, ",")+"]")
+	}
 
 	// Add reasoning content if available (following OpenAI reasoning format)
 	if len(reasoningParts) > 0 {
@@ -11,6 +11,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -155,7 +156,7 @@ func ConvertOpenAIResponsesRequestToClaude(modelName string, inputRawJSON []byte
 				var role string
 				var textAggregate strings.Builder
 				var partsJSON []string
-				hasImage := false
+				hasMedia := false
 				if parts := item.Get("content"); parts.Exists() && parts.IsArray() {
 					parts.ForEach(func(_, part gjson.Result) bool {
 						ptype := part.Get("type").String()
@@ -205,9 +206,17 @@ func ConvertOpenAIResponsesRequestToClaude(modelName string, inputRawJSON []byte
 									if role == "" {
 										role = "user"
 									}
-									hasImage = true
+									hasMedia = true
 								}
 							}
+						case "input_file":
+							if doc, ok := util.ResponsesFileDocument(part); ok {
+								partsJSON = append(partsJSON, doc.ClaudeBlock())
+								if role == "" {
+									role = "user"
+								}
+								hasMedia = true
+							}
 						}
 						return true
 					})
@@ -229,7 +238,7 @@ func ConvertOpenAIResponsesRequestToClaude(modelName string, inputRawJSON []byte
 				if len(partsJSON) > 0 {
 					msg := `{"role":"","content":[]}`
 					msg, _ = sjson.Set(msg, "role", role)
-					if len(partsJSON) == 1 && !hasImage {
+					if len(partsJSON) == 1 && !hasMedia {
 						// Preserve legacy behavior for single text content
 						msg, _ = sjson.Delete(msg, "content")
 						textPart := gjson.Parse(partsJSON[0])
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -26,6 +27,9 @@ type claudeToResponsesState struct {
 	FuncCallIDs map[int]string // index -> call id
 	// message text aggregation
 	TextBuf strings.Builder
+	// web citations of text blocks, as Responses annotations
+	Citations   util.ClaudeCitationStream
+	Annotations []string
 	// reasoning state
 	ReasoningActive    bool
 	ReasoningItemID    string
@@ -50,6 +54,11 @@ func pickRequestJSON(originalRequestRawJSON, requestRawJSON []byte) []byte {
 	return nil
 }
 
+// annotationsJSON joins Responses annotation objects into a JSON array.
+func annotationsJSON(annotations []string) string {
+	return "[" + strings.Join(annotations, ",") + "]"
+}
+
 func emitEvent(event string, payload string) string {
 	return fmt.Sprintf("event: %s\ndata: %s", event, payload)
 }
@@ -137,6 +146,7 @@ func ConvertClaudeResponseToOpenAIResponses(ctx context.Context, modelName strin
 			part, _ = sjson.Set(part, "sequence_number", nextSeq())
 			part, _ = sjson.Set(part, "item_id", st.CurrentMsgID)
 			out = append(out, emitEvent("response.content_part.added", part))
+			st.Citations.StartBlock(cb)
 		} else if typ == "tool_use" {
 			st.InFuncBlock = true
 			st.CurrentFCID = cb.Get("id").String()
@@ -188,7 +198,10 @@ func ConvertClaudeResponseToOpenAIResponses(ctx context.Context, modelName strin
 				out = append(out, emitEvent("response.output_text.delta", msg))
 				// aggregate text for response.output
 				st.TextBuf.WriteString(t.String())
+				st.Citations.AddText(t.String())
 			}
+		} else if dt == "citations_delta" {
+			st.Citations.AddCitation(d.Get("citation"))
 		} else if dt == "input_json_delta" {
 			idx := int(root.Get("index").Int())
 			if pj := d.Get("partial_json"); pj.Exists() {
@@ -219,6 +232,18 @@ func ConvertClaudeResponseToOpenAIResponses(ctx context.Context, modelName strin
 	case "content_block_stop":
 		idx := int(root.Get("index").Int())
 		if st.InTextBlock {
+			var blockAnnotations []string
+			for _, citation := range st.Citations.StopBlock() {
+				annotation := citation.ResponsesAnnotation()
+				added := `{"type":"response.output_text.annotation.added","sequence_number":0,"item_id":"","output_index":0,"content_index":0,"annotation_index":0,"annotation":{}}`
+				added, _ = sjson.Set(added, "sequence_number", nextSeq())
+				added, _ = sjson.Set(added, "item_id", st.CurrentMsgID)
+				added, _ = sjson.Set(added, "annotation_index", len(blockAnnotations))
+				added, _ = sjson.SetRaw(added, "annotation", annotation)
+				out = append(out, emitEvent("response.output_text.annotation.added", added))
+				blockAnnotations = append(blockAnnotations, annotation)
+			}
+			st.Annotations = append(st.Annotations, blockAnnotations...)
 			done := `{"type":"response.output_text.done","sequence_number":0,"item_id":"","output_index":0,"content_index":0,"text":"","logprobs":[]}`
 			done, _ = sjson.Set(done, "sequence_number", nextSeq())
 			done, _ = sjson.Set(done, "item_id", st.CurrentMsgID)
@@ -226,10 +251,12 @@ func ConvertClaudeResponseToOpenAIResponses(ctx context.Context, modelName strin
 			partDone := `{"type":"response.content_part.done","sequence_number":0,"item_id":"","output_index":0,"content_index":0,"part":{"type":"output_text","annotations":[],"logprobs":[],"text":""}}`
 			partDone, _ = sjson.Set(partDone, "sequence_number", nextSeq())
 			partDone, _ = sjson.Set(partDone, "item_id", st.CurrentMsgID)
+			partDone, _ = sjson.SetRaw(partDone, "part.annotations", annotationsJSON(blockAnnotations))
 			out = append(out, emitEvent("response.content_part.done", partDone))
 			final := `{"type":"response.output_item.done","sequence_number":0,"output_index":0,"item":{"id":"","type":"message","status":"completed","content":[{"type":"output_text","text":""}],"role":"assistant"}}`
 			final, _ = sjson.Set(final, "sequence_number", nextSeq())
 			final, _ = sjson.Set(final, "item.id", st.CurrentMsgID)
+			final, _ = sjson.SetRaw(final, "item.content.0.annotations", annotationsJSON(blockAnnotations))
 			out = append(out, emitEvent("response.output_item.done", final))
 			st.InTextBlock = false
 		} else if st.InFuncBlock {
@@ -369,6 +396,7 @@ func ConvertClaudeResponseToOpenAIResponses(ctx context.Context, modelName strin
 			item := `{"id":"","type":"message","status":"completed","content":[{"type":"output_text","annotations":[],"logprobs":[],"text":""}],"role":"assistant"}`
 			item, _ = sjson.Set(item, "id", st.CurrentMsgID)
 			item, _ = sjson.Set(item, "content.0.text", st.TextBuf.String())
+			item, _ = sjson.SetRaw(item, "content.0.annotations", annotationsJSON(st.Annotations))
 			outputsWrapper, _ = sjson.SetRaw(outputsWrapper, "arr.-1", item)
 		}
 		// function_call items (in ascending index order for determinism)
@@ -469,6 +497,8 @@ func ConvertClaudeResponseToOpenAIResponsesNonStream(_ context.Context, _ string
 		reasoningItemID string
 		inputTokens     int64
 		outputTokens    int64
+		citations       util.ClaudeCitationStream
+		annotations     []string
 	)
 
 	// Per-index tool call aggregation
@@ -504,6 +534,7 @@ func ConvertClaudeResponseToOpenAIResponsesNonStream(_ context.Context, _ string
 			switch typ {
 			case "text":
 				currentMsgID = "msg_" + responseID + "_0"
+				citations.StartBlock(cb)
 			case "tool_use":
 				currentFCID = cb.Get("id").String()
 				name := cb.Get("name").String()
@@ -528,7 +559,10 @@ func ConvertClaudeResponseToOpenAIResponsesNonStream(_ context.Context, _ string
 			case "text_delta":
 				if t := d.Get("text"); t.Exists() {
 					textBuf.WriteString(t.String())
+					citations.AddText(t.String())
 				}
+			case "citations_delta":
+				citations.AddCitation(d.Get("citation"))
 			case "input_json_delta":
 				if pj := d.Get("partial_json"); pj.Exists() {
 					idx := int(root.Get("index").Int())
@@ -546,8 +580,9 @@ func ConvertClaudeResponseToOpenAIResponsesNonStream(_ context.Context, _ string
 			}
 
 		case "content_block_stop":
-			// Nothing special to finalize for non-stream aggregation
-			_ = root
+			for _, citation := range citations.StopBlock() {
+				annotations = append(annotations, citation.ResponsesAnnotation())
+			}
 
 		case "message_delta":
 			if usage := root.Get("usage"); usage.Exists() {
@@ -638,6 +673,7 @@ func ConvertClaudeResponseToOpenAIResponsesNonStream(_ context.Context, _ string
 		item := `{"id":"","type":"message","status":"completed","content":[{"type":"output_text","annotations":[],"logprobs":[],"text":""}],"role":"assistant"}`
 		item, _ = sjson.Set(item, "id", currentMsgID)
 		item, _ = sjson.Set(item, "content.0.text", textBuf.String())
+		item, _ = sjson.SetRaw(item, "content.0.annotations", annotationsJSON(annotations))
 		outputsWrapper, _ = sjson.SetRaw(outputsWrapper, "arr.-1", item)
 	}
 	if len(toolCalls) > 0 {
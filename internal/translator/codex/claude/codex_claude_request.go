@@ -13,6 +13,7 @@ import (
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -109,6 +110,12 @@ func ConvertClaudeRequestToCodex(modelName string, inputRawJSON []byte, _ bool)
 				hasContent = true
 			}
 
+			appendDocumentContent := func(doc util.Document) {
+				message, _ = sjson.SetRaw(message, fmt.Sprintf("content.%d", contentIndex), doc.ResponsesContentPart())
+				contentIndex++
+				hasContent = true
+			}
+
 			messageContentsResult := messageResult.Get("content")
 			if messageContentsResult.IsArray() {
 				messageContentResults := messageContentsResult.Array()
@@ -138,6 +145,10 @@ func ConvertClaudeRequestToCodex(modelName string, inputRawJSON []byte, _ bool)
 								appendImageContent(dataURL)
 							}
 						}
+					case "document":
+						if doc, ok := util.ClaudeDocument(messageContentResult); ok {
+							appendDocumentContent(doc)
+						}
 					case "tool_use":
 						flushMessage()
 						functionCallMessage := `{"type":"function_call"}`
@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -24,6 +25,8 @@ var (
 type ConvertCodexResponseToClaudeParams struct {
 	HasToolCall bool
 	BlockIndex  int
+	// BlockText accumulates the open text block, to fill in the cited text of annotations.
+	BlockText strings.Builder
 }
 
 // ConvertCodexResponseToClaude performs sophisticated streaming response format conversion.
@@ -92,6 +95,7 @@ func ConvertCodexResponseToClaude(_ context.Context, _ string, originalRequestRa
 	} else if typeStr == "response.content_part.added" {
 		template = `{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`
 		template, _ = sjson.Set(template, "index", (*param).(*ConvertCodexResponseToClaudeParams).BlockIndex)
+		(*param).(*ConvertCodexResponseToClaudeParams).BlockText.Reset()
 
 		output = "event: content_block_start\n"
 		output += fmt.Sprintf("data: %s\n\n", template)
@@ -99,6 +103,19 @@ func ConvertCodexResponseToClaude(_ context.Context, _ string, originalRequestRa
 		template = `{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":""}}`
 		template, _ = sjson.Set(template, "index", (*param).(*ConvertCodexResponseToClaudeParams).BlockIndex)
 		template, _ = sjson.Set(template, "delta.text", rootResult.Get("delta").String())
+		(*param).(*ConvertCodexResponseToClaudeParams).BlockText.WriteString(rootResult.Get("delta").String())
+
+		output = "event: content_block_delta\n"
+		output += fmt.Sprintf("data: %s\n\n", template)
+	} else if typeStr == "response.output_text.annotation.added" {
+		citation, ok := util.ResponsesAnnotationCitation(rootResult.Get("annotation"))
+		if !ok {
+			return []string{}
+		}
+		citation.CitedText = citation.Span((*param).(*ConvertCodexResponseToClaudeParams).BlockText.String())
+		template = `{"type":"content_block_delta","index":0,"delta":{"type":"citations_delta","citation":{}}}`
+		template, _ = sjson.Set(template, "index", (*param).(*ConvertCodexResponseToClaudeParams).BlockIndex)
+		template, _ = sjson.SetRaw(template, "delta.citation", citation.ClaudeCitation())
 
 		output = "event: content_block_delta\n"
 		output += fmt.Sprintf("data: %s\n\n", template)
@@ -266,6 +283,13 @@ func ConvertCodexResponseToClaudeNonStream(_ context.Context, _ string, original
 								if text != "" {
 									block := `{"type":"text","text":""}`
 									block, _ = sjson.Set(block, "text", text)
+									part.Get("annotations").ForEach(func(_, annotation gjson.Result) bool {
+										if citation, ok := util.ResponsesAnnotationCitation(annotation); ok {
+											citation.CitedText = citation.Span(text)
+											block, _ = sjson.SetRaw(block, "citations.-1", citation.ClaudeCitation())
+										}
+										return true
+									})
 									out, _ = sjson.SetRaw(out, "content.-1", block)
 								}
 							}
@@ -144,6 +144,14 @@ func ConvertGeminiRequestToCodex(modelName string, inputRawJSON []byte, _ bool)
 					continue
 				}
 
+				// PDF and text attachments from the user
+				if doc, ok := util.GeminiDocument(p); ok && role == "user" {
+					msg := `{"type":"message","role":"user","content":[]}`
+					msg, _ = sjson.SetRaw(msg, "content.-1", doc.ResponsesContentPart())
+					out, _ = sjson.SetRaw(out, "input.-1", msg)
+					continue
+				}
+
 				// function call from model
 				if fc := p.Get("functionCall"); fc.Exists() {
 					fn := `{"type":"function_call"}`
@@ -8,8 +8,10 @@ package chat_completions
 import (
 	"bytes"
 	"context"
+	"strings"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -108,6 +110,13 @@ func ConvertCodexResponseToOpenAI(_ context.Context, modelName string, originalR
 			template, _ = sjson.Set(template, "choices.0.delta.role", "assistant")
 			template, _ = sjson.Set(template, "choices.0.delta.content", deltaResult.String())
 		}
+	} else if dataType == "response.output_text.annotation.added" {
+		citation, ok := util.ResponsesAnnotationCitation(rootResult.Get("annotation"))
+		if !ok {
+			return []string{}
+		}
+		template, _ = sjson.Set(template, "choices.0.delta.role", "assistant")
+		template, _ = sjson.SetRaw(template, "choices.0.delta.annotations", "["+citation.OpenAIAnnotation()+"]")
 	} else if dataType == "response.completed" {
 		finishReason := "stop"
 		if (*param).(*ConvertCliToOpenAIParams).FunctionCallIndex != -1 {
@@ -217,6 +226,7 @@ func ConvertCodexResponseToOpenAINonStream(_ context.Context, _ string, original
 		var contentText string
 		var reasoningText string
 		var toolCalls []string
+		var annotations []string
 
 		for _, outputItem := range outputArray {
 			outputType := outputItem.Get("type").String()
@@ -240,6 +250,12 @@ func ConvertCodexResponseToOpenAINonStream(_ context.Context, _ string, original
 					for _, contentItem := range contentArray {
 						if contentItem.Get("type").String() == "output_text" {
 							contentText = contentItem.Get("text").String()
+							contentItem.Get("annotations").ForEach(func(_, annotation gjson.Result) bool {
+								if citation, ok := util.ResponsesAnnotationCitation(annotation); ok {
+									annotations = append(annotations, citation.OpenAIAnnotation())
+								}
+								return true
+							})
 							break
 						}
 					}
@@ -274,6 +290,9 @@ func ConvertCodexResponseToOpenAINonStream(_ context.Context, _ string, original
 			template, _ = sjson.Set(template, "choices.0.message.content", contentText)
 			template, _ = sjson.Set(template, "choices.0.message.role", "assistant")
 		}
+		if len(annotations) > 0 {
+			template, _ = sjson.SetRaw(template, "choices.0.message.annotations", "["+strings.Join(annotations, ",")+"]")
+		}
 
 		if reasoningText != "" {
 			template, _ = sjson.Set(template, "choices.0.message.reasoning_content", reasoningText)
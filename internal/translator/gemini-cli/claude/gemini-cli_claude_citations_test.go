@@ -0,0 +1,33 @@
+package claude
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+const groundedCLIResponse = `{"response":{"responseId":"r1","candidates":[{"content":{"role":"model","parts":[{"text":"Paris is the capital."}]},"finishReason":"STOP",
+	"groundingMetadata":{"groundingChunks":[{"web":{"uri":"https://example.com/paris","title":"example.com"}}],
+	"groundingSupports":[{"segment":{"startIndex":0,"endIndex":5,"text":"Paris"},"groundingChunkIndices":[0]}]}}],
+	"usageMetadata":{"promptTokenCount":3,"candidatesTokenCount":5}}}`
+
+func TestConvertGeminiCLIGroundingToClaudeCitations(t *testing.T) {
+	out := gjson.Parse(ConvertGeminiCLIResponseToClaudeNonStream(context.Background(), "", nil, nil, []byte(groundedCLIResponse), nil))
+	citation := out.Get("content.0.citations.0")
+	if citation.Get("type").String() != "web_search_result_location" || citation.Get("url").String() != "https://example.com/paris" {
+		t.Fatalf("content = %s", out.Get("content").Raw)
+	}
+	if got := citation.Get("cited_text").String(); got != "Paris" {
+		t.Fatalf("cited_text = %q", got)
+	}
+
+	var param any
+	stream := strings.Join(ConvertGeminiCLIResponseToClaude(context.Background(), "", nil, nil, []byte(groundedCLIResponse), &param), "")
+	delta := strings.Index(stream, `"citations_delta"`)
+	stop := strings.Index(stream, `"content_block_stop"`)
+	if delta < 0 || stop < delta || !strings.Contains(stream, `"url":"https://example.com/paris"`) {
+		t.Fatalf("stream without citations_delta before the block stops: %s", stream)
+	}
+}
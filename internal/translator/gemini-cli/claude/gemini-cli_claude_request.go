@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/common"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -89,6 +90,11 @@ func ConvertClaudeRequestToCLI(modelName string, inputRawJSON []byte, _ bool) []
 						part, _ = sjson.Set(part, "text", contentResult.Get("text").String())
 						contentJSON, _ = sjson.SetRaw(contentJSON, "parts.-1", part)
 
+					case "document":
+						if doc, ok := util.ClaudeDocument(contentResult); ok {
+							contentJSON, _ = sjson.SetRaw(contentJSON, "parts.-1", doc.GeminiPart())
+						}
+
 					case "tool_use":
 						functionName := contentResult.Get("name").String()
 						functionArgs := contentResult.Get("input").String()
@@ -14,6 +14,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -26,6 +27,8 @@ type Params struct {
 	ResponseType     int  // Current response type: 0=none, 1=content, 2=thinking, 3=function
 	ResponseIndex    int  // Index counter for content blocks in the streaming response
 	HasContent       bool // Tracks whether any content (text, thinking, or tool use) has been output
+	// Text accumulates the visible text so grounding offsets can be resolved.
+	Text strings.Builder
 }
 
 // toolUseIDCounter provides a process-wide unique counter for tool use identifiers.
@@ -140,6 +143,7 @@ func ConvertGeminiCLIResponseToClaude(_ context.Context, _ string, originalReque
 					}
 				} else {
 					// Process regular text content (user-visible output)
+					(*param).(*Params).Text.WriteString(partTextResult.String())
 					// Continue existing text block if already in content state
 					if (*param).(*Params).ResponseType == 1 {
 						output = output + "event: content_block_delta\n"
@@ -224,6 +228,16 @@ func ConvertGeminiCLIResponseToClaude(_ context.Context, _ string, originalReque
 		}
 	}
 
+	// Grounding arrives with the last chunks and cites the text streamed so far; attach it
+	// to the open text block as citations_delta events.
+	if grounding := gjson.GetBytes(rawJSON, "response.candidates.0.groundingMetadata"); grounding.Exists() && (*param).(*Params).ResponseType == 1 {
+		for _, citation := range util.GeminiCitations(grounding, (*param).(*Params).Text.String()) {
+			output = output + "event: content_block_delta\n"
+			data, _ := sjson.SetRaw(fmt.Sprintf(`{"type":"content_block_delta","index":%d,"delta":{"type":"citations_delta","citation":{}}}`, (*param).(*Params).ResponseIndex), "delta.citation", citation.ClaudeCitation())
+			output = output + fmt.Sprintf("data: %s\n\n\n", data)
+		}
+	}
+
 	usageResult := gjson.GetBytes(rawJSON, "response.usageMetadata")
 	// Process usage metadata and finish reason when present in the response
 	if usageResult.Exists() && bytes.Contains(rawJSON, []byte(`"finishReason"`)) {
@@ -347,6 +361,25 @@ func ConvertGeminiCLIResponseToClaudeNonStream(_ context.Context, _ string, orig
 	flushThinking()
 	flushText()
 
+	// Claude cites whole text blocks, so grounding sources go on the last text block.
+	if grounding := root.Get("response.candidates.0.groundingMetadata"); grounding.Exists() {
+		var text strings.Builder
+		lastText := -1
+		for i, block := range gjson.Get(out, "content").Array() {
+			if block.Get("type").String() == "text" {
+				text.WriteString(block.Get("text").String())
+				lastText = i
+			}
+		}
+		if citations := util.GeminiCitations(grounding, text.String()); lastText >= 0 && len(citations) > 0 {
+			path := fmt.Sprintf("content.%d.citations", lastText)
+			out, _ = sjson.SetRaw(out, path, "[]")
+			for _, citation := range citations {
+				out, _ = sjson.SetRaw(out, path+".-1", citation.ClaudeCitation())
+			}
+		}
+	}
+
 	stopReason := "end_turn"
 	if hasToolCall {
 		stopReason = "tool_use"
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/common"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	log "github.com/sirupsen/logrus"
@@ -98,6 +97,17 @@ func ConvertOpenAIRequestToGeminiCLI(modelName string, inputRawJSON []byte, _ bo
 		}
 	}
 
+	// Map OpenAI response_format -> Gemini JSON mode
+	switch format := gjson.GetBytes(rawJSON, "response_format"); format.Get("type").String() {
+	case "json_schema":
+		if schema := format.Get("json_schema.schema"); schema.IsObject() {
+			out, _ = sjson.SetRawBytes(out, "request.generationConfig.responseJsonSchema", []byte(schema.Raw))
+		}
+		out, _ = sjson.SetBytes(out, "request.generationConfig.responseMimeType", "application/json")
+	case "json_object":
+		out, _ = sjson.SetBytes(out, "request.generationConfig.responseMimeType", "application/json")
+	}
+
 	// messages -> systemInstruction + contents
 	messages := gjson.GetBytes(rawJSON, "messages")
 	if messages.IsArray() {
@@ -189,18 +199,11 @@ func ConvertOpenAIRequestToGeminiCLI(modelName string, inputRawJSON []byte, _ bo
 								}
 							}
 						case "file":
-							filename := item.Get("file.filename").String()
-							fileData := item.Get("file.file_data").String()
-							ext := ""
-							if sp := strings.Split(filename, "."); len(sp) > 1 {
-								ext = sp[len(sp)-1]
-							}
-							if mimeType, ok := misc.MimeTypes[ext]; ok {
-								node, _ = sjson.SetBytes(node, "parts."+itoa(p)+".inlineData.mime_type", mimeType)
-								node, _ = sjson.SetBytes(node, "parts."+itoa(p)+".inlineData.data", fileData)
+							if doc, ok := util.OpenAIFileDocument(item); ok {
+								node, _ = sjson.SetRawBytes(node, "parts."+itoa(p), []byte(doc.GeminiPart()))
 								p++
 							} else {
-								log.Warnf("Unknown file name extension '%s' in user message, skip", ext)
+								log.Warnf("File content part without file_data or file_id in user message, skip")
 							}
 						}
 					}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	. "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/openai/chat-completions"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -22,6 +23,8 @@ import (
 type convertCliResponseToOpenAIChatParams struct {
 	UnixTimestamp int64
 	FunctionIndex int
+	// Text accumulates the visible text so grounding offsets can be resolved.
+	Text strings.Builder
 }
 
 // functionCallIDCounter provides a process-wide unique counter for function call identifiers.
@@ -133,6 +136,7 @@ func ConvertCliResponseToOpenAI(_ context.Context, _ string, originalRequestRawJ
 					template, _ = sjson.Set(template, "choices.0.delta.reasoning_content", textContent)
 				} else {
 					template, _ = sjson.Set(template, "choices.0.delta.content", textContent)
+					(*param).(*convertCliResponseToOpenAIChatParams).Text.WriteString(textContent)
 				}
 				template, _ = sjson.Set(template, "choices.0.delta.role", "assistant")
 			} else if functionCallResult.Exists() {
@@ -184,6 +188,16 @@ func ConvertCliResponseToOpenAI(_ context.Context, _ string, originalRequestRawJ
 		}
 	}
 
+	// Grounding arrives with the last chunks and cites the text streamed so far.
+	if grounding := gjson.GetBytes(rawJSON, "response.candidates.0.groundingMetadata"); grounding.Exists() {
+		for _, citation := range util.GeminiCitations(grounding, (*param).(*convertCliResponseToOpenAIChatParams).Text.String()) {
+			if !gjson.Get(template, "choices.0.delta.annotations").Exists() {
+				template, _ = sjson.SetRaw(template, "choices.0.delta.annotations", `[]`)
+			}
+			template, _ = sjson.SetRaw(template, "choices.0.delta.annotations.-1", citation.OpenAIAnnotation())
+		}
+	}
+
 	if hasFunctionCall {
 		template, _ = sjson.Set(template, "choices.0.finish_reason", "tool_calls")
 		template, _ = sjson.Set(template, "choices.0.native_finish_reason", "tool_calls")
@@ -7,9 +7,12 @@ package claude
 
 import (
 	"bytes"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/common"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -82,6 +85,11 @@ func ConvertClaudeRequestToGemini(modelName string, inputRawJSON []byte, _ bool)
 						part, _ = sjson.Set(part, "text", contentResult.Get("text").String())
 						contentJSON, _ = sjson.SetRaw(contentJSON, "parts.-1", part)
 
+					case "document":
+						if doc, ok := util.ClaudeDocument(contentResult); ok {
+							contentJSON, _ = sjson.SetRaw(contentJSON, "parts.-1", doc.GeminiPart())
+						}
+
 					case "tool_use":
 						functionName := contentResult.Get("name").String()
 						functionArgs := contentResult.Get("input").String()
@@ -177,3 +185,53 @@ func ConvertClaudeRequestToGemini(modelName string, inputRawJSON []byte, _ bool)
 
 	return result
 }
+
+// CacheBreakpoints returns the cache_control breakpoints of a Claude request as the number
+// of leading Gemini contents each one covers, in ascending order, and "1h" when any of them
+// asks for the one-hour TTL. Breakpoints on the system prompt or tools cover no contents.
+// Contents are counted as ConvertClaudeRequestToGemini emits them.
+func CacheBreakpoints(inputRawJSON []byte) ([]int, string) {
+	var breakpoints []int
+	ttl := ""
+	mark := func(block gjson.Result, contents int) {
+		cacheControl := block.Get("cache_control")
+		if !cacheControl.IsObject() {
+			return
+		}
+		if n := len(breakpoints); n == 0 || breakpoints[n-1] != contents {
+			breakpoints = append(breakpoints, contents)
+		}
+		if cacheControl.Get("ttl").String() == "1h" {
+			ttl = "1h"
+		}
+	}
+
+	gjson.GetBytes(inputRawJSON, "tools").ForEach(func(_, tool gjson.Result) bool {
+		mark(tool, 0)
+		return true
+	})
+	gjson.GetBytes(inputRawJSON, "system").ForEach(func(_, block gjson.Result) bool {
+		mark(block, 0)
+		return true
+	})
+	contents := 0
+	gjson.GetBytes(inputRawJSON, "messages").ForEach(func(_, message gjson.Result) bool {
+		if message.Get("role").Type != gjson.String {
+			return true
+		}
+		content := message.Get("content")
+		switch {
+		case content.IsArray():
+			content.ForEach(func(_, block gjson.Result) bool {
+				mark(block, contents+1)
+				return true
+			})
+			contents++
+		case content.Type == gjson.String:
+			contents++
+		}
+		return true
+	})
+	sort.Ints(breakpoints)
+	return slices.Compact(breakpoints), ttl
+}
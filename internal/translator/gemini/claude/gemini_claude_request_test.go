@@ -0,0 +1,48 @@
+package claude
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestCacheBreakpoints(t *testing.T) {
+	input := []byte(`{
+		"model":"claude-sonnet-4",
+		"system":[{"type":"text","text":"sys","cache_control":{"type":"ephemeral","ttl":"1h"}}],
+		"messages":[
+			{"role":"user","content":[{"type":"text","text":"one"}]},
+			{"role":"assistant","content":[{"type":"text","text":"two","cache_control":{"type":"ephemeral"}}]},
+			{"role":"user","content":"three"}
+		]
+	}`)
+
+	breakpoints, ttl := CacheBreakpoints(input)
+	if len(breakpoints) != 2 || breakpoints[0] != 0 || breakpoints[1] != 2 {
+		t.Fatalf("breakpoints = %v, want [0 2]", breakpoints)
+	}
+	if ttl != "1h" {
+		t.Fatalf("ttl = %q, want 1h", ttl)
+	}
+	if out := ConvertClaudeRequestToGemini("gemini-2.5-pro", input, false); gjson.GetBytes(out, "cache_control").Exists() {
+		t.Fatalf("translated request carries cache_control: %s", out)
+	}
+
+	if breakpoints, _ = CacheBreakpoints([]byte(`{"messages":[{"role":"user","content":"hi"}]}`)); len(breakpoints) != 0 {
+		t.Fatalf("breakpoints = %v, want none", breakpoints)
+	}
+}
+
+func TestConvertGeminiResponseToClaudeNonStreamReportsCachedTokens(t *testing.T) {
+	raw := []byte(`{"responseId":"r1","modelVersion":"gemini-2.5-pro","candidates":[{"content":{"parts":[{"text":"ok"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":100,"cachedContentTokenCount":80,"candidatesTokenCount":5}}`)
+
+	out := ConvertGeminiResponseToClaudeNonStream(context.Background(), "", nil, nil, raw, nil)
+
+	if got := gjson.Get(out, "usage.input_tokens").Int(); got != 20 {
+		t.Fatalf("input_tokens = %d, want 20", got)
+	}
+	if got := gjson.Get(out, "usage.cache_read_input_tokens").Int(); got != 80 {
+		t.Fatalf("cache_read_input_tokens = %d, want 80", got)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -25,6 +26,8 @@ type Params struct {
 	ResponseType     int
 	ResponseIndex    int
 	HasContent       bool // Tracks whether any content (text, thinking, or tool use) has been output
+	// Text accumulates the visible text so grounding offsets can be resolved.
+	Text strings.Builder
 }
 
 // toolUseIDCounter provides a process-wide unique counter for tool use identifiers.
@@ -140,6 +143,7 @@ func ConvertGeminiResponseToClaude(_ context.Context, _ string, originalRequestR
 					}
 				} else {
 					// Process regular text content (user-visible output)
+					(*param).(*Params).Text.WriteString(partTextResult.String())
 					// Continue existing text block
 					if (*param).(*Params).ResponseType == 1 {
 						output = output + "event: content_block_delta\n"
@@ -236,6 +240,16 @@ func ConvertGeminiResponseToClaude(_ context.Context, _ string, originalRequestR
 		}
 	}
 
+	// Grounding arrives with the last chunks and cites the text streamed so far; attach it
+	// to the open text block as citations_delta events.
+	if grounding := gjson.GetBytes(rawJSON, "candidates.0.groundingMetadata"); grounding.Exists() && (*param).(*Params).ResponseType == 1 {
+		for _, citation := range util.GeminiCitations(grounding, (*param).(*Params).Text.String()) {
+			output = output + "event: content_block_delta\n"
+			data, _ := sjson.SetRaw(fmt.Sprintf(`{"type":"content_block_delta","index":%d,"delta":{"type":"citations_delta","citation":{}}}`, (*param).(*Params).ResponseIndex), "delta.citation", citation.ClaudeCitation())
+			output = output + fmt.Sprintf("data: %s\n\n\n", data)
+		}
+	}
+
 	usageResult := gjson.GetBytes(rawJSON, "usageMetadata")
 	if usageResult.Exists() && bytes.Contains(rawJSON, []byte(`"finishReason"`)) {
 		if candidatesTokenCountResult := usageResult.Get("candidatesTokenCount"); candidatesTokenCountResult.Exists() {
@@ -255,7 +269,12 @@ func ConvertGeminiResponseToClaude(_ context.Context, _ string, originalRequestR
 
 				thoughtsTokenCount := usageResult.Get("thoughtsTokenCount").Int()
 				template, _ = sjson.Set(template, "usage.output_tokens", candidatesTokenCountResult.Int()+thoughtsTokenCount)
-				template, _ = sjson.Set(template, "usage.input_tokens", usageResult.Get("promptTokenCount").Int())
+				promptTokens := usageResult.Get("promptTokenCount").Int()
+				if cachedTokens := usageResult.Get("cachedContentTokenCount").Int(); cachedTokens > 0 {
+					promptTokens -= cachedTokens
+					template, _ = sjson.Set(template, "usage.cache_read_input_tokens", cachedTokens)
+				}
+				template, _ = sjson.Set(template, "usage.input_tokens", promptTokens)
 
 				output = output + template + "\n\n\n"
 			}
@@ -287,6 +306,10 @@ func ConvertGeminiResponseToClaudeNonStream(_ context.Context, _ string, origina
 
 	inputTokens := root.Get("usageMetadata.promptTokenCount").Int()
 	outputTokens := root.Get("usageMetadata.candidatesTokenCount").Int() + root.Get("usageMetadata.thoughtsTokenCount").Int()
+	if cachedTokens := root.Get("usageMetadata.cachedContentTokenCount").Int(); cachedTokens > 0 {
+		inputTokens -= cachedTokens
+		out, _ = sjson.Set(out, "usage.cache_read_input_tokens", cachedTokens)
+	}
 	out, _ = sjson.Set(out, "usage.input_tokens", inputTokens)
 	out, _ = sjson.Set(out, "usage.output_tokens", outputTokens)
 
@@ -353,6 +376,25 @@ func ConvertGeminiResponseToClaudeNonStream(_ context.Context, _ string, origina
 	flushThinking()
 	flushText()
 
+	// Claude cites whole text blocks, so grounding sources go on the last text block.
+	if grounding := root.Get("candidates.0.groundingMetadata"); grounding.Exists() {
+		var text strings.Builder
+		lastText := -1
+		for i, block := range gjson.Get(out, "content").Array() {
+			if block.Get("type").String() == "text" {
+				text.WriteString(block.Get("text").String())
+				lastText = i
+			}
+		}
+		if citations := util.GeminiCitations(grounding, text.String()); lastText >= 0 && len(citations) > 0 {
+			path := fmt.Sprintf("content.%d.citations", lastText)
+			out, _ = sjson.SetRaw(out, path, "[]")
+			for _, citation := range citations {
+				out, _ = sjson.SetRaw(out, path+".-1", citation.ClaudeCitation())
+			}
+		}
+	}
+
 	stopReason := "end_turn"
 	if hasToolCall {
 		stopReason = "tool_use"
@@ -0,0 +1,60 @@
+package chat_completions
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertOpenAIRequestToGeminiAudio(t *testing.T) {
+	raw := []byte(`{"model":"gemini-2.5-flash","modalities":["text","audio"],"audio":{"voice":"Kore","format":"wav"},"messages":[{"role":"user","content":[{"type":"text","text":"Transcribe"},{"type":"input_audio","input_audio":{"data":"SUQzBA==","format":"mp3"}}]}]}`)
+	out := gjson.ParseBytes(ConvertOpenAIRequestToGemini("gemini-2.5-flash", raw, false))
+
+	if got := out.Get("contents.0.parts.1.inlineData.mimeType").String(); got != "audio/mp3" {
+		t.Fatalf("audio mimeType = %q, want audio/mp3", got)
+	}
+	if got := out.Get("contents.0.parts.1.inlineData.data").String(); got != "SUQzBA==" {
+		t.Fatalf("audio data = %q", got)
+	}
+	if got := out.Get("generationConfig.responseModalities").Raw; got != `["AUDIO"]` {
+		t.Fatalf("responseModalities = %s", got)
+	}
+	if got := out.Get("generationConfig.speechConfig.voiceConfig.prebuiltVoiceConfig.voiceName").String(); got != "Kore" {
+		t.Fatalf("voiceName = %q", got)
+	}
+
+	// OpenAI voice names are dropped in favour of the Gemini default voice.
+	raw = []byte(`{"modalities":["audio"],"audio":{"voice":"alloy"},"messages":[{"role":"user","content":"hi"}]}`)
+	out = gjson.ParseBytes(ConvertOpenAIRequestToGemini("gemini-2.5-flash-preview-tts", raw, false))
+	if out.Get("generationConfig.speechConfig").Exists() {
+		t.Fatalf("speechConfig set for OpenAI voice: %s", out.Raw)
+	}
+}
+
+func TestConvertGeminiAudioResponseToOpenAI(t *testing.T) {
+	pcm := base64.StdEncoding.EncodeToString([]byte{1, 0, 2, 0})
+	response := []byte(`{"responseId":"r1","candidates":[{"content":{"role":"model","parts":[{"inlineData":{"mimeType":"audio/L16;codec=pcm;rate=24000","data":"` + pcm + `"}}]},"finishReason":"STOP"}]}`)
+	original := []byte(`{"modalities":["text","audio"],"audio":{"voice":"alloy","format":"wav"}}`)
+
+	out := gjson.Parse(ConvertGeminiResponseToOpenAINonStream(context.Background(), "", original, nil, response, nil))
+	audio := out.Get("choices.0.message.audio")
+	if audio.Get("id").String() != "audio_r1" {
+		t.Fatalf("audio id = %s", audio.Raw)
+	}
+	wav, _ := base64.StdEncoding.DecodeString(audio.Get("data").String())
+	if samples, rate, ok := util.PCMFromWAV(wav); !ok || rate != 24000 || len(samples) != 4 {
+		t.Fatalf("audio data is not the expected WAV: ok=%v rate=%d samples=%v", ok, rate, samples)
+	}
+
+	var param any
+	chunks := ConvertGeminiResponseToOpenAI(context.Background(), "", original, nil, response, &param)
+	if len(chunks) != 1 {
+		t.Fatalf("stream chunks = %d, want 1", len(chunks))
+	}
+	if got := gjson.Get(chunks[0], "choices.0.delta.audio.data").String(); got != pcm {
+		t.Fatalf("streamed audio = %q, want raw pcm %q", got, pcm)
+	}
+}
@@ -0,0 +1,38 @@
+package chat_completions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+const groundedGeminiResponse = `{"responseId":"r1","candidates":[{"content":{"role":"model","parts":[{"text":"Paris is the capital."}]},"finishReason":"STOP",
+	"groundingMetadata":{"groundingChunks":[{"web":{"uri":"https://example.com/paris","title":"example.com"}}],
+	"groundingSupports":[{"segment":{"startIndex":0,"endIndex":5,"text":"Paris"},"groundingChunkIndices":[0]}]}}]}`
+
+func TestConvertGeminiGroundingToOpenAIAnnotations(t *testing.T) {
+	out := gjson.Parse(ConvertGeminiResponseToOpenAINonStream(context.Background(), "", nil, nil, []byte(groundedGeminiResponse), nil))
+	annotation := out.Get("choices.0.message.annotations.0")
+	if annotation.Get("type").String() != "url_citation" {
+		t.Fatalf("annotations = %s", out.Get("choices.0.message.annotations").Raw)
+	}
+	if got := annotation.Get("url_citation.url").String(); got != "https://example.com/paris" {
+		t.Fatalf("url = %q", got)
+	}
+	if annotation.Get("url_citation.start_index").Int() != 0 || annotation.Get("url_citation.end_index").Int() != 5 {
+		t.Fatalf("span = %s", annotation.Raw)
+	}
+
+	var param any
+	chunks := ConvertGeminiResponseToOpenAI(context.Background(), "", nil, nil, []byte(groundedGeminiResponse), &param)
+	found := false
+	for _, chunk := range chunks {
+		if gjson.Get(chunk, "choices.0.delta.annotations.0.url_citation.end_index").Int() == 5 {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("stream chunks without annotations: %v", chunks)
+	}
+}
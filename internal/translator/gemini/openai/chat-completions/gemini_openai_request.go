@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/common"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	log "github.com/sirupsen/logrus"
@@ -17,6 +16,13 @@ import (
 
 const geminiFunctionThoughtSignature = "skip_thought_signature_validator"
 
+// openAIVoices are OpenAI's built-in voice names. Gemini has its own voice set, so these
+// fall back to the model's default voice instead of being rejected upstream.
+var openAIVoices = map[string]bool{
+	"alloy": true, "ash": true, "ballad": true, "cedar": true, "coral": true, "echo": true,
+	"fable": true, "marin": true, "nova": true, "onyx": true, "sage": true, "shimmer": true, "verse": true,
+}
+
 // ConvertOpenAIRequestToGemini converts an OpenAI Chat Completions request (raw JSON)
 // into a complete Gemini request JSON. All JSON construction uses sjson and lookups use gjson.
 //
@@ -72,14 +78,24 @@ func ConvertOpenAIRequestToGemini(modelName string, inputRawJSON []byte, _ bool)
 
 	// Map OpenAI modalities -> Gemini generationConfig.responseModalities
 	// e.g. "modalities": ["image", "text"] -> ["IMAGE", "TEXT"]
+	// Gemini speech models only accept ["AUDIO"], so audio replaces the other modalities.
 	if mods := gjson.GetBytes(rawJSON, "modalities"); mods.Exists() && mods.IsArray() {
 		var responseMods []string
+		wantsAudio := false
 		for _, m := range mods.Array() {
 			switch strings.ToLower(m.String()) {
 			case "text":
 				responseMods = append(responseMods, "TEXT")
 			case "image":
 				responseMods = append(responseMods, "IMAGE")
+			case "audio":
+				wantsAudio = true
+			}
+		}
+		if wantsAudio {
+			responseMods = []string{"AUDIO"}
+			if voice := gjson.GetBytes(rawJSON, "audio.voice").String(); voice != "" && !openAIVoices[strings.ToLower(voice)] {
+				out, _ = sjson.SetBytes(out, "generationConfig.speechConfig.voiceConfig.prebuiltVoiceConfig.voiceName", voice)
 			}
 		}
 		if len(responseMods) > 0 {
@@ -98,6 +114,17 @@ func ConvertOpenAIRequestToGemini(modelName string, inputRawJSON []byte, _ bool)
 		}
 	}
 
+	// Map OpenAI response_format -> Gemini JSON mode
+	switch format := gjson.GetBytes(rawJSON, "response_format"); format.Get("type").String() {
+	case "json_schema":
+		if schema := format.Get("json_schema.schema"); schema.IsObject() {
+			out, _ = sjson.SetRawBytes(out, "generationConfig.responseJsonSchema", []byte(schema.Raw))
+		}
+		out, _ = sjson.SetBytes(out, "generationConfig.responseMimeType", "application/json")
+	case "json_object":
+		out, _ = sjson.SetBytes(out, "generationConfig.responseMimeType", "application/json")
+	}
+
 	// messages -> systemInstruction + contents
 	messages := gjson.GetBytes(rawJSON, "messages")
 	if messages.IsArray() {
@@ -192,18 +219,18 @@ func ConvertOpenAIRequestToGemini(modelName string, inputRawJSON []byte, _ bool)
 								}
 							}
 						case "file":
-							filename := item.Get("file.filename").String()
-							fileData := item.Get("file.file_data").String()
-							ext := ""
-							if sp := strings.Split(filename, "."); len(sp) > 1 {
-								ext = sp[len(sp)-1]
+							if doc, ok := util.OpenAIFileDocument(item); ok {
+								node, _ = sjson.SetRawBytes(node, "parts."+itoa(p), []byte(doc.GeminiPart()))
+								p++
+							} else {
+								log.Warnf("File content part without file_data or file_id in user message, skip")
 							}
-							if mimeType, ok := misc.MimeTypes[ext]; ok {
-								node, _ = sjson.SetBytes(node, "parts."+itoa(p)+".inlineData.mime_type", mimeType)
-								node, _ = sjson.SetBytes(node, "parts."+itoa(p)+".inlineData.data", fileData)
+						case "input_audio":
+							if audio, ok := util.OpenAIInputAudio(item); ok {
+								node, _ = sjson.SetRawBytes(node, "parts."+itoa(p), []byte(audio.GeminiPart()))
 								p++
 							} else {
-								log.Warnf("Unknown file name extension '%s' in user message, skip", ext)
+								log.Warnf("Audio content part without data in user message, skip")
 							}
 						}
 					}
@@ -8,11 +8,13 @@ package chat_completions
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"fmt"
 	"strings"
 	"sync/atomic"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	log "github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
@@ -23,6 +25,8 @@ type convertGeminiResponseToOpenAIChatParams struct {
 	UnixTimestamp int64
 	// FunctionIndex tracks tool call indices per candidate index to support multiple candidates.
 	FunctionIndex map[int]int
+	// Text accumulates the visible text per candidate index so grounding offsets can be resolved.
+	Text map[int]*strings.Builder
 }
 
 // functionCallIDCounter provides a process-wide unique counter for function call identifiers.
@@ -56,6 +60,9 @@ func ConvertGeminiResponseToOpenAI(_ context.Context, _ string, originalRequestR
 	if p.FunctionIndex == nil {
 		p.FunctionIndex = make(map[int]int)
 	}
+	if p.Text == nil {
+		p.Text = make(map[int]*strings.Builder)
+	}
 
 	if bytes.HasPrefix(rawJSON, []byte("data:")) {
 		rawJSON = bytes.TrimSpace(rawJSON[5:])
@@ -168,6 +175,10 @@ func ConvertGeminiResponseToOpenAI(_ context.Context, _ string, originalRequestR
 							template, _ = sjson.Set(template, "choices.0.delta.reasoning_content", text)
 						} else {
 							template, _ = sjson.Set(template, "choices.0.delta.content", text)
+							if p.Text[candidateIndex] == nil {
+								p.Text[candidateIndex] = &strings.Builder{}
+							}
+							p.Text[candidateIndex].WriteString(text)
 						}
 						template, _ = sjson.Set(template, "choices.0.delta.role", "assistant")
 					} else if functionCallResult.Exists() {
@@ -195,6 +206,12 @@ func ConvertGeminiResponseToOpenAI(_ context.Context, _ string, originalRequestR
 						}
 						template, _ = sjson.Set(template, "choices.0.delta.role", "assistant")
 						template, _ = sjson.SetRaw(template, "choices.0.delta.tool_calls.-1", functionCallTemplate)
+					} else if audio, ok := util.GeminiAudio(partResult); ok {
+						// OpenAI only streams pcm16 audio, so streamed clips are always raw PCM.
+						audio = audio.InFormat("pcm16")
+						template, _ = sjson.Set(template, "choices.0.delta.role", "assistant")
+						template, _ = sjson.Set(template, "choices.0.delta.audio.id", openAIAudioID(rawJSON))
+						template, _ = sjson.Set(template, "choices.0.delta.audio.data", audio.Data)
 					} else if inlineDataResult.Exists() {
 						data := inlineDataResult.Get("data").String()
 						if data == "" {
@@ -222,6 +239,20 @@ func ConvertGeminiResponseToOpenAI(_ context.Context, _ string, originalRequestR
 				}
 			}
 
+			// Grounding arrives with the last chunks and cites the text streamed so far.
+			if grounding := candidate.Get("groundingMetadata"); grounding.Exists() {
+				streamed := ""
+				if p.Text[candidateIndex] != nil {
+					streamed = p.Text[candidateIndex].String()
+				}
+				for _, citation := range util.GeminiCitations(grounding, streamed) {
+					if !gjson.Get(template, "choices.0.delta.annotations").Exists() {
+						template, _ = sjson.SetRaw(template, "choices.0.delta.annotations", `[]`)
+					}
+					template, _ = sjson.SetRaw(template, "choices.0.delta.annotations.-1", citation.OpenAIAnnotation())
+				}
+			}
+
 			if hasFunctionCall {
 				template, _ = sjson.Set(template, "choices.0.finish_reason", "tool_calls")
 				template, _ = sjson.Set(template, "choices.0.native_finish_reason", "tool_calls")
@@ -318,6 +349,7 @@ func ConvertGeminiResponseToOpenAINonStream(_ context.Context, _ string, origina
 
 			partsResult := candidate.Get("content.parts")
 			hasFunctionCall := false
+			var audioParts []util.Audio
 			if partsResult.IsArray() {
 				partsResults := partsResult.Array()
 				for i := 0; i < len(partsResults); i++ {
@@ -355,6 +387,8 @@ func ConvertGeminiResponseToOpenAINonStream(_ context.Context, _ string, origina
 						}
 						choiceTemplate, _ = sjson.Set(choiceTemplate, "message.role", "assistant")
 						choiceTemplate, _ = sjson.SetRaw(choiceTemplate, "message.tool_calls.-1", functionCallItemTemplate)
+					} else if audio, ok := util.GeminiAudio(partResult); ok {
+						audioParts = append(audioParts, audio)
 					} else if inlineDataResult.Exists() {
 						data := inlineDataResult.Get("data").String()
 						if data != "" {
@@ -381,6 +415,24 @@ func ConvertGeminiResponseToOpenAINonStream(_ context.Context, _ string, origina
 				}
 			}
 
+			if grounding := candidate.Get("groundingMetadata"); grounding.Exists() {
+				for _, citation := range util.GeminiCitations(grounding, gjson.Get(choiceTemplate, "message.content").String()) {
+					if !gjson.Get(choiceTemplate, "message.annotations").Exists() {
+						choiceTemplate, _ = sjson.SetRaw(choiceTemplate, "message.annotations", `[]`)
+					}
+					choiceTemplate, _ = sjson.SetRaw(choiceTemplate, "message.annotations.-1", citation.OpenAIAnnotation())
+				}
+			}
+
+			if audio, ok := joinAudioParts(audioParts); ok {
+				audio = audio.InFormat(gjson.GetBytes(originalRequestRawJSON, "audio.format").String())
+				audioTemplate := `{"id":"","data":"","expires_at":0,"transcript":""}`
+				audioTemplate, _ = sjson.Set(audioTemplate, "id", openAIAudioID(rawJSON))
+				audioTemplate, _ = sjson.Set(audioTemplate, "data", audio.Data)
+				audioTemplate, _ = sjson.Set(audioTemplate, "expires_at", time.Now().Add(time.Hour).Unix())
+				choiceTemplate, _ = sjson.SetRaw(choiceTemplate, "message.audio", audioTemplate)
+			}
+
 			if hasFunctionCall {
 				choiceTemplate, _ = sjson.Set(choiceTemplate, "finish_reason", "tool_calls")
 				choiceTemplate, _ = sjson.Set(choiceTemplate, "native_finish_reason", "tool_calls")
@@ -394,3 +446,32 @@ func ConvertGeminiResponseToOpenAINonStream(_ context.Context, _ string, origina
 
 	return template
 }
+
+// openAIAudioID derives a stable message.audio id from the Gemini response id.
+func openAIAudioID(rawJSON []byte) string {
+	return "audio_" + gjson.GetBytes(rawJSON, "responseId").String()
+}
+
+// joinAudioParts merges the audio parts of one candidate into a single clip. Raw PCM
+// parts are concatenated; otherwise only the first clip is kept, since container formats
+// cannot be joined byte-wise.
+func joinAudioParts(parts []util.Audio) (util.Audio, bool) {
+	if len(parts) == 0 {
+		return util.Audio{}, false
+	}
+	if len(parts) == 1 || !util.IsPCMAudio(parts[0].MimeType) {
+		return parts[0], true
+	}
+	var pcm []byte
+	for _, part := range parts {
+		if part.MimeType != parts[0].MimeType {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(part.Data)
+		if err != nil {
+			continue
+		}
+		pcm = append(pcm, data...)
+	}
+	return util.Audio{MimeType: parts[0].MimeType, Data: base64.StdEncoding.EncodeToString(pcm)}, true
+}
@@ -0,0 +1,33 @@
+package chat_completions
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertOpenAIRequestToGeminiResponseFormat(t *testing.T) {
+	raw := []byte(`{"model":"gemini-2.5-flash","response_format":{"type":"json_schema","json_schema":{"name":"r","schema":{"type":"object","properties":{"name":{"type":"string"}}}}},"messages":[{"role":"user","content":"hi"}]}`)
+	out := gjson.ParseBytes(ConvertOpenAIRequestToGemini("gemini-2.5-flash", raw, false))
+	if got := out.Get("generationConfig.responseMimeType").String(); got != "application/json" {
+		t.Fatalf("responseMimeType = %q, want application/json", got)
+	}
+	if got := out.Get("generationConfig.responseJsonSchema.properties.name.type").String(); got != "string" {
+		t.Fatalf("responseJsonSchema = %s", out.Get("generationConfig.responseJsonSchema").Raw)
+	}
+
+	raw = []byte(`{"model":"gemini-2.5-flash","response_format":{"type":"json_object"},"messages":[{"role":"user","content":"hi"}]}`)
+	out = gjson.ParseBytes(ConvertOpenAIRequestToGemini("gemini-2.5-flash", raw, false))
+	if got := out.Get("generationConfig.responseMimeType").String(); got != "application/json" {
+		t.Fatalf("responseMimeType = %q, want application/json", got)
+	}
+	if out.Get("generationConfig.responseJsonSchema").Exists() {
+		t.Fatalf("json_object must not set a schema: %s", out.Raw)
+	}
+
+	raw = []byte(`{"model":"gemini-2.5-flash","response_format":{"type":"text"},"messages":[{"role":"user","content":"hi"}]}`)
+	out = gjson.ParseBytes(ConvertOpenAIRequestToGemini("gemini-2.5-flash", raw, false))
+	if out.Get("generationConfig.responseMimeType").Exists() {
+		t.Fatalf("text format must not set responseMimeType: %s", out.Raw)
+	}
+}
@@ -0,0 +1,61 @@
+// Package embeddings provides translation between OpenAI embedding requests and the
+// Gemini batchEmbedContents API.
+package embeddings
+
+import (
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ConvertOpenAIEmbeddingsRequestToGemini converts an OpenAI /v1/embeddings request into a
+// Gemini batchEmbedContents request. Each string input becomes one embed request; the
+// OpenAI "dimensions" field maps to outputDimensionality.
+//
+// Parameters:
+//   - modelName: The upstream model name
+//   - inputRawJSON: The OpenAI embeddings request
+//   - stream: Unused; embeddings are never streamed
+//
+// Returns:
+//   - []byte: The Gemini batchEmbedContents request
+func ConvertOpenAIEmbeddingsRequestToGemini(modelName string, inputRawJSON []byte, _ bool) []byte {
+	out := []byte(`{"requests":[]}`)
+	root := gjson.ParseBytes(inputRawJSON)
+
+	model := "models/" + strings.TrimPrefix(modelName, "models/")
+	dimensions := root.Get("dimensions")
+
+	for _, text := range EmbeddingInputs(inputRawJSON) {
+		request := []byte(`{"model":"","content":{"parts":[{"text":""}]}}`)
+		request, _ = sjson.SetBytes(request, "model", model)
+		request, _ = sjson.SetBytes(request, "content.parts.0.text", text)
+		if dimensions.Exists() && dimensions.Int() > 0 {
+			request, _ = sjson.SetBytes(request, "outputDimensionality", dimensions.Int())
+		}
+		out, _ = sjson.SetRawBytes(out, "requests.-1", request)
+	}
+	return out
+}
+
+// EmbeddingInputs returns the text inputs of an OpenAI embeddings request. A single
+// string yields one entry; arrays yield their string elements in order. Token-array
+// inputs are not representable as text and are skipped.
+func EmbeddingInputs(rawJSON []byte) []string {
+	input := gjson.GetBytes(rawJSON, "input")
+	switch {
+	case input.Type == gjson.String:
+		return []string{input.String()}
+	case input.IsArray():
+		var texts []string
+		input.ForEach(func(_, value gjson.Result) bool {
+			if value.Type == gjson.String {
+				texts = append(texts, value.String())
+			}
+			return true
+		})
+		return texts
+	}
+	return nil
+}
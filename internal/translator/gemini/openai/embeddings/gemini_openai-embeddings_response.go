@@ -0,0 +1,64 @@
+package embeddings
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"math"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ConvertGeminiResponseToOpenAIEmbeddingsNonStream converts a Gemini embedContent or
+// batchEmbedContents response into an OpenAI embeddings list. When the original request
+// asked for encoding_format=base64 the vectors are emitted as little-endian float32 bytes,
+// matching OpenAI's encoding. Usage is taken from usageMetadata.promptTokenCount.
+//
+// Parameters:
+//   - ctx: The context for the request
+//   - modelName: The client-facing model name
+//   - originalRequestRawJSON: The original OpenAI embeddings request
+//   - requestRawJSON: The translated Gemini request
+//   - rawJSON: The Gemini response
+//   - param: Unused
+//
+// Returns:
+//   - string: The OpenAI embeddings response
+func ConvertGeminiResponseToOpenAIEmbeddingsNonStream(_ context.Context, modelName string, originalRequestRawJSON, _, rawJSON []byte, _ *any) string {
+	out := []byte(`{"object":"list","data":[],"model":"","usage":{"prompt_tokens":0,"total_tokens":0}}`)
+	out, _ = sjson.SetBytes(out, "model", modelName)
+
+	base64Encoding := gjson.GetBytes(originalRequestRawJSON, "encoding_format").String() == "base64"
+
+	root := gjson.ParseBytes(rawJSON)
+	embeddings := root.Get("embeddings").Array()
+	if single := root.Get("embedding"); len(embeddings) == 0 && single.Exists() {
+		embeddings = []gjson.Result{single}
+	}
+	for i, embedding := range embeddings {
+		item := []byte(`{"object":"embedding","index":0,"embedding":[]}`)
+		item, _ = sjson.SetBytes(item, "index", i)
+		values := embedding.Get("values")
+		if base64Encoding {
+			item, _ = sjson.SetBytes(item, "embedding", encodeFloat32Base64(values.Array()))
+		} else if values.IsArray() {
+			item, _ = sjson.SetRawBytes(item, "embedding", []byte(values.Raw))
+		}
+		out, _ = sjson.SetRawBytes(out, "data.-1", item)
+	}
+
+	if promptTokens := root.Get("usageMetadata.promptTokenCount"); promptTokens.Exists() {
+		out, _ = sjson.SetBytes(out, "usage.prompt_tokens", promptTokens.Int())
+		out, _ = sjson.SetBytes(out, "usage.total_tokens", promptTokens.Int())
+	}
+	return string(out)
+}
+
+func encodeFloat32Base64(values []gjson.Result) string {
+	buf := make([]byte, 4*len(values))
+	for i, value := range values {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(float32(value.Float())))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,84 @@
+package embeddings
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertOpenAIEmbeddingsRequestToGemini(t *testing.T) {
+	in := []byte(`{"model":"gemini-embedding-001","input":["hello","world",[1,2]],"dimensions":256}`)
+	out := ConvertOpenAIEmbeddingsRequestToGemini("gemini-embedding-001", in, false)
+
+	requests := gjson.GetBytes(out, "requests").Array()
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 embed requests (token arrays skipped), got %d: %s", len(requests), out)
+	}
+	for i, want := range []string{"hello", "world"} {
+		if got := requests[i].Get("content.parts.0.text").String(); got != want {
+			t.Fatalf("request %d text = %q, want %q", i, got, want)
+		}
+		if got := requests[i].Get("model").String(); got != "models/gemini-embedding-001" {
+			t.Fatalf("request %d model = %q", i, got)
+		}
+		if got := requests[i].Get("outputDimensionality").Int(); got != 256 {
+			t.Fatalf("request %d outputDimensionality = %d, want 256", i, got)
+		}
+	}
+
+	single := ConvertOpenAIEmbeddingsRequestToGemini("text-embedding-004", []byte(`{"input":"only"}`), false)
+	if got := gjson.GetBytes(single, "requests.#").Int(); got != 1 {
+		t.Fatalf("expected 1 request for string input, got %d", got)
+	}
+	if gjson.GetBytes(single, "requests.0.outputDimensionality").Exists() {
+		t.Fatalf("outputDimensionality should be omitted without dimensions: %s", single)
+	}
+}
+
+func TestConvertGeminiResponseToOpenAIEmbeddingsNonStream(t *testing.T) {
+	raw := []byte(`{"embeddings":[{"values":[0.5,-1]},{"values":[0.25]}],"usageMetadata":{"promptTokenCount":7}}`)
+	out := ConvertGeminiResponseToOpenAIEmbeddingsNonStream(context.Background(), "gemini-embedding-001", []byte(`{"input":["a","b"]}`), nil, raw, nil)
+
+	root := gjson.Parse(out)
+	if root.Get("object").String() != "list" || root.Get("model").String() != "gemini-embedding-001" {
+		t.Fatalf("unexpected envelope: %s", out)
+	}
+	if got := root.Get("data.#").Int(); got != 2 {
+		t.Fatalf("data length = %d, want 2", got)
+	}
+	if got := root.Get("data.1.index").Int(); got != 1 {
+		t.Fatalf("data[1].index = %d, want 1", got)
+	}
+	if got := root.Get("data.0.embedding.1").Float(); got != -1 {
+		t.Fatalf("data[0].embedding[1] = %v, want -1", got)
+	}
+	if got := root.Get("usage.prompt_tokens").Int(); got != 7 {
+		t.Fatalf("usage.prompt_tokens = %d, want 7", got)
+	}
+	if got := root.Get("usage.total_tokens").Int(); got != 7 {
+		t.Fatalf("usage.total_tokens = %d, want 7", got)
+	}
+}
+
+func TestConvertGeminiResponseToOpenAIEmbeddingsBase64(t *testing.T) {
+	raw := []byte(`{"embedding":{"values":[1.5,-2]}}`)
+	out := ConvertGeminiResponseToOpenAIEmbeddingsNonStream(context.Background(), "m", []byte(`{"input":"x","encoding_format":"base64"}`), nil, raw, nil)
+
+	encoded := gjson.Get(out, "data.0.embedding").String()
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("decode base64: %v", err)
+	}
+	if len(decoded) != 8 {
+		t.Fatalf("decoded length = %d, want 8", len(decoded))
+	}
+	for i, want := range []float32{1.5, -2} {
+		if got := math.Float32frombits(binary.LittleEndian.Uint32(decoded[4*i:])); got != want {
+			t.Fatalf("value %d = %v, want %v", i, got, want)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -25,6 +26,7 @@ type geminiToResponsesState struct {
 	CurrentMsgID string
 	TextBuf      strings.Builder
 	ItemTextBuf  strings.Builder
+	Annotations  []string
 
 	// reasoning aggregation
 	ReasoningOpened bool
@@ -81,6 +83,11 @@ func unwrapGeminiResponseRoot(root gjson.Result) gjson.Result {
 	return root
 }
 
+// annotationsJSON joins Responses annotation objects into a JSON array.
+func annotationsJSON(annotations []string) string {
+	return "[" + strings.Join(annotations, ",") + "]"
+}
+
 func emitEvent(event string, payload string) string {
 	return fmt.Sprintf("event: %s\ndata: %s", event, payload)
 }
@@ -179,12 +186,14 @@ func ConvertGeminiResponseToOpenAIResponses(_ context.Context, modelName string,
 		partDone, _ = sjson.Set(partDone, "item_id", st.CurrentMsgID)
 		partDone, _ = sjson.Set(partDone, "output_index", st.MsgIndex)
 		partDone, _ = sjson.Set(partDone, "part.text", fullText)
+		partDone, _ = sjson.SetRaw(partDone, "part.annotations", annotationsJSON(st.Annotations))
 		out = append(out, emitEvent("response.content_part.done", partDone))
 		final := `{"type":"response.output_item.done","sequence_number":0,"output_index":0,"item":{"id":"","type":"message","status":"completed","content":[{"type":"output_text","text":""}],"role":"assistant"}}`
 		final, _ = sjson.Set(final, "sequence_number", nextSeq())
 		final, _ = sjson.Set(final, "output_index", st.MsgIndex)
 		final, _ = sjson.Set(final, "item.id", st.CurrentMsgID)
 		final, _ = sjson.Set(final, "item.content.0.text", fullText)
+		final, _ = sjson.SetRaw(final, "item.content.0.annotations", annotationsJSON(st.Annotations))
 		out = append(out, emitEvent("response.output_item.done", final))
 
 		st.MsgClosed = true
@@ -373,6 +382,21 @@ func ConvertGeminiResponseToOpenAIResponses(_ context.Context, modelName string,
 		})
 	}
 
+	// Grounding arrives with the last chunks and cites the text streamed so far.
+	if grounding := root.Get("candidates.0.groundingMetadata"); grounding.Exists() && st.MsgOpened && !st.MsgClosed {
+		for _, citation := range util.GeminiCitations(grounding, st.ItemTextBuf.String()) {
+			annotation := citation.ResponsesAnnotation()
+			added := `{"type":"response.output_text.annotation.added","sequence_number":0,"item_id":"","output_index":0,"content_index":0,"annotation_index":0,"annotation":{}}`
+			added, _ = sjson.Set(added, "sequence_number", nextSeq())
+			added, _ = sjson.Set(added, "item_id", st.CurrentMsgID)
+			added, _ = sjson.Set(added, "output_index", st.MsgIndex)
+			added, _ = sjson.Set(added, "annotation_index", len(st.Annotations))
+			added, _ = sjson.SetRaw(added, "annotation", annotation)
+			out = append(out, emitEvent("response.output_text.annotation.added", added))
+			st.Annotations = append(st.Annotations, annotation)
+		}
+	}
+
 	// Finalization on finishReason
 	if fr := root.Get("candidates.0.finishReason"); fr.Exists() && fr.String() != "" {
 		// Finalize reasoning first to keep ordering tight with last delta
@@ -508,6 +532,7 @@ func ConvertGeminiResponseToOpenAIResponses(_ context.Context, modelName string,
 				item := `{"id":"","type":"message","status":"completed","content":[{"type":"output_text","annotations":[],"logprobs":[],"text":""}],"role":"assistant"}`
 				item, _ = sjson.Set(item, "id", st.CurrentMsgID)
 				item, _ = sjson.Set(item, "content.0.text", st.TextBuf.String())
+				item, _ = sjson.SetRaw(item, "content.0.annotations", annotationsJSON(st.Annotations))
 				outputsWrapper, _ = sjson.SetRaw(outputsWrapper, "arr.-1", item)
 				continue
 			}
@@ -732,6 +757,11 @@ func ConvertGeminiResponseToOpenAIResponsesNonStream(_ context.Context, _ string
 		itemJSON := `{"id":"","type":"message","status":"completed","content":[{"type":"output_text","annotations":[],"logprobs":[],"text":""}],"role":"assistant"}`
 		itemJSON, _ = sjson.Set(itemJSON, "id", fmt.Sprintf("msg_%s_0", strings.TrimPrefix(id, "resp_")))
 		itemJSON, _ = sjson.Set(itemJSON, "content.0.text", messageText.String())
+		if grounding := root.Get("candidates.0.groundingMetadata"); grounding.Exists() {
+			for _, citation := range util.GeminiCitations(grounding, messageText.String()) {
+				itemJSON, _ = sjson.SetRaw(itemJSON, "content.0.annotations.-1", citation.ResponsesAnnotation())
+			}
+		}
 		appendOutput(itemJSON)
 	}
 
@@ -21,6 +21,7 @@ import (
 	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/gemini"
 	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/gemini-cli"
 	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/openai/chat-completions"
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/openai/embeddings"
 	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/openai/responses"
 
 	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/openai/claude"
@@ -36,4 +37,7 @@ import (
 
 	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/kiro/claude"
 	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/kiro/openai"
+
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/bedrock/claude"
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/bedrock/openai"
 )
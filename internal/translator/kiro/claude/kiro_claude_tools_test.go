@@ -0,0 +1,23 @@
+package claude
+
+import "testing"
+
+func TestParseEmbeddedToolCallsDeduplicates(t *testing.T) {
+	processed := make(map[string]bool)
+	text := "Looking.\n[Called read_file with args: {\"path\": \"a.go\"}]\n[Called read_file with args: {\"path\": \"a.go\"}]"
+
+	clean, toolUses := ParseEmbeddedToolCalls(text, processed)
+	if clean != "Looking." {
+		t.Fatalf("clean text = %q", clean)
+	}
+	if len(toolUses) != 1 || toolUses[0].Name != "read_file" || toolUses[0].Input["path"] != "a.go" {
+		t.Fatalf("tool uses = %+v", toolUses)
+	}
+
+	if _, again := ParseEmbeddedToolCalls(text, processed); len(again) != 0 {
+		t.Fatalf("already processed calls returned again: %+v", again)
+	}
+	if clean, none := ParseEmbeddedToolCalls("no calls here", processed); clean != "no calls here" || none != nil {
+		t.Fatalf("plain text = %q, %+v", clean, none)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -146,7 +147,7 @@ func ConvertClaudeRequestToOpenAI(modelName string, inputRawJSON []byte, stream
 					case "redacted_thinking":
 						// Explicitly ignore redacted_thinking - never map to reasoning_content (AC2)
 
-					case "text", "image":
+					case "text", "image", "document":
 						if contentItem, ok := convertClaudeContentPart(part); ok {
 							contentItems = append(contentItems, contentItem)
 						}
@@ -358,6 +359,13 @@ func convertClaudeContentPart(part gjson.Result) (string, bool) {
 
 		return imageContent, true
 
+	case "document":
+		doc, ok := util.ClaudeDocument(part)
+		if !ok {
+			return "", false
+		}
+		return doc.OpenAIContentPart(), true
+
 	default:
 		return "", false
 	}
@@ -588,3 +588,33 @@ func TestConvertClaudeRequestToOpenAI_AssistantThinkingToolUseThinkingSplit(t *t
 		t.Fatalf("Expected reasoning_content %q, got %q", "t1\n\nt2", got)
 	}
 }
+
+func TestConvertClaudeRequestToOpenAI_DocumentBlocks(t *testing.T) {
+	inputJSON := `{
+		"model": "claude-3-opus",
+		"messages": [{
+			"role": "user",
+			"content": [
+				{"type": "document", "title": "report.pdf", "source": {"type": "base64", "media_type": "application/pdf", "data": "JVBERi0="}},
+				{"type": "document", "source": {"type": "text", "media_type": "text/plain", "data": "plain notes"}},
+				{"type": "text", "text": "Summarize these."}
+			]
+		}]
+	}`
+
+	result := ConvertClaudeRequestToOpenAI("gpt-4o", []byte(inputJSON), false)
+	content := gjson.GetBytes(result, "messages.0.content")
+
+	if got := content.Get("0.type").String(); got != "file" {
+		t.Fatalf("content[0].type = %q, want file: %s", got, content.Raw)
+	}
+	if got := content.Get("0.file.file_data").String(); got != "data:application/pdf;base64,JVBERi0=" {
+		t.Fatalf("file_data = %q", got)
+	}
+	if got := content.Get("0.file.filename").String(); got != "report.pdf" {
+		t.Fatalf("filename = %q", got)
+	}
+	if got := content.Get("1.text").String(); got != "plain notes" {
+		t.Fatalf("text document = %q, want plain notes", got)
+	}
+}
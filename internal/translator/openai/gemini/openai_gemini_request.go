@@ -13,6 +13,7 @@ import (
 	"strings"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -82,6 +83,24 @@ func ConvertGeminiRequestToOpenAI(modelName string, inputRawJSON []byte, stream
 			out, _ = sjson.Set(out, "n", candidateCount.Int())
 		}
 
+		// Response modalities: AUDIO asks for speech, which OpenAI returns alongside a transcript.
+		// Gemini voice names are not valid OpenAI voices, so the default voice is used.
+		// OpenAI only streams pcm16 audio; non-streaming responses use wav.
+		if modalities := genConfig.Get("responseModalities"); modalities.IsArray() {
+			for _, modality := range modalities.Array() {
+				if strings.EqualFold(modality.String(), "AUDIO") {
+					out, _ = sjson.Set(out, "modalities", []string{"text", "audio"})
+					out, _ = sjson.Set(out, "audio.voice", "alloy")
+					format := "wav"
+					if stream {
+						format = "pcm16"
+					}
+					out, _ = sjson.Set(out, "audio.format", format)
+					break
+				}
+			}
+		}
+
 		// Map Gemini thinkingConfig to OpenAI reasoning_effort.
 		// Always perform conversion to support allowCompat models that may not be in registry
 		if thinkingConfig := genConfig.Get("thinkingConfig"); thinkingConfig.Exists() && thinkingConfig.IsObject() {
@@ -180,6 +199,22 @@ func ConvertGeminiRequestToOpenAI(modelName string, inputRawJSON []byte, stream
 						contentPartsCount++
 					}
 
+					// Handle PDF and text attachments as file parts
+					if doc, ok := util.GeminiDocument(part); ok {
+						onlyTextContent = false
+						contentWrapper, _ = sjson.SetRaw(contentWrapper, "arr.-1", doc.OpenAIContentPart())
+						contentPartsCount++
+						return true
+					}
+
+					// Handle audio clips as input_audio parts
+					if audio, ok := util.GeminiAudio(part); ok {
+						onlyTextContent = false
+						contentWrapper, _ = sjson.SetRaw(contentWrapper, "arr.-1", audio.OpenAIContentPart())
+						contentPartsCount++
+						return true
+					}
+
 					// Handle inline data (e.g., images)
 					if inlineData := part.Get("inlineData"); inlineData.Exists() {
 						onlyTextContent = false
@@ -12,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -146,6 +147,20 @@ func ConvertOpenAIResponseToGemini(_ context.Context, _ string, originalRequestR
 				chunkOutputs = append(chunkOutputs, contentTemplate)
 			}
 
+			// Handle audio delta; the transcript is the spoken text
+			if audio := delta.Get("audio"); audio.Exists() {
+				if transcript := audio.Get("transcript").String(); transcript != "" {
+					transcriptTemplate := baseTemplate
+					transcriptTemplate, _ = sjson.Set(transcriptTemplate, "candidates.0.content.parts.0.text", transcript)
+					chunkOutputs = append(chunkOutputs, transcriptTemplate)
+				}
+				if data := audio.Get("data").String(); data != "" {
+					audioTemplate := baseTemplate
+					audioTemplate, _ = sjson.SetRaw(audioTemplate, "candidates.0.content.parts.0", geminiAudioPart(requestRawJSON, data))
+					chunkOutputs = append(chunkOutputs, audioTemplate)
+				}
+			}
+
 			if len(chunkOutputs) > 0 {
 				results = append(results, chunkOutputs...)
 				return true
@@ -576,6 +591,18 @@ func ConvertOpenAIResponseToGeminiNonStream(_ context.Context, _ string, origina
 				partIndex++
 			}
 
+			// Handle audio output; the transcript stands in for the text when there is none
+			if audio := message.Get("audio"); audio.Exists() {
+				if transcript := audio.Get("transcript").String(); transcript != "" && message.Get("content").String() == "" {
+					out, _ = sjson.Set(out, fmt.Sprintf("candidates.0.content.parts.%d.text", partIndex), transcript)
+					partIndex++
+				}
+				if data := audio.Get("data").String(); data != "" {
+					out, _ = sjson.SetRaw(out, fmt.Sprintf("candidates.0.content.parts.%d", partIndex), geminiAudioPart(requestRawJSON, data))
+					partIndex++
+				}
+			}
+
 			// Handle tool calls
 			if toolCalls := message.Get("tool_calls"); toolCalls.Exists() && toolCalls.IsArray() {
 				toolCalls.ForEach(func(_, toolCall gjson.Result) bool {
@@ -620,6 +647,15 @@ func ConvertOpenAIResponseToGeminiNonStream(_ context.Context, _ string, origina
 	return out
 }
 
+// geminiAudioPart encodes OpenAI audio output as a Gemini inlineData part, using the
+// audio format of the translated request to label the data.
+func geminiAudioPart(requestRawJSON []byte, data string) string {
+	part := `{"inlineData":{"mimeType":"","data":""}}`
+	part, _ = sjson.Set(part, "inlineData.mimeType", util.AudioMimeType(gjson.GetBytes(requestRawJSON, "audio.format").String()))
+	part, _ = sjson.Set(part, "inlineData.data", data)
+	return part
+}
+
 func GeminiTokenCount(ctx context.Context, count int64) string {
 	return fmt.Sprintf(`{"totalTokens":%d,"promptTokensDetails":[{"modality":"TEXT","tokenCount":%d}]}`, count, count)
 }
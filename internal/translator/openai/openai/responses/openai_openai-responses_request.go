@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"strings"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -73,6 +74,7 @@ func ConvertOpenAIResponsesRequestToOpenAIChatCompletions(modelName string, inpu
 
 				if content := item.Get("content"); content.Exists() && content.IsArray() {
 					var messageContent string
+					var fileParts []string
 					var toolCalls []interface{}
 
 					content.ForEach(func(_, contentItem gjson.Result) bool {
@@ -96,11 +98,26 @@ func ConvertOpenAIResponsesRequestToOpenAIChatCompletions(modelName string, inpu
 							} else {
 								messageContent = text
 							}
+						case "input_file":
+							if doc, ok := util.ResponsesFileDocument(contentItem); ok {
+								fileParts = append(fileParts, doc.OpenAIContentPart())
+							}
 						}
 						return true
 					})
 
-					if messageContent != "" {
+					if len(fileParts) > 0 {
+						// Files need the array form of content; keep the text as the leading part.
+						parts := `[]`
+						if messageContent != "" {
+							parts, _ = sjson.SetRaw(parts, "-1", `{"type":"text","text":""}`)
+							parts, _ = sjson.Set(parts, "0.text", messageContent)
+						}
+						for _, part := range fileParts {
+							parts, _ = sjson.SetRaw(parts, "-1", part)
+						}
+						message, _ = sjson.SetRaw(message, "content", parts)
+					} else if messageContent != "" {
 						message, _ = sjson.Set(message, "content", messageContent)
 					}
 
@@ -94,6 +94,25 @@ type RequestDetail struct {
 	AuthIndex string     `json:"auth_index"`
 	Tokens    TokenStats `json:"tokens"`
 	Failed    bool       `json:"failed"`
+	// Stream is populated for streaming requests only.
+	Stream *StreamStats `json:"stream,omitempty"`
+}
+
+// StreamStats captures latency metrics for a single streaming request.
+type StreamStats struct {
+	TimeToFirstByteMs    int64   `json:"ttfb_ms"`
+	TimeToFirstContentMs int64   `json:"ttft_ms"`
+	DurationMs           int64   `json:"duration_ms"`
+	TokensPerSecond      float64 `json:"tokens_per_second"`
+}
+
+// StreamingSummary aggregates streaming latency across a set of requests.
+type StreamingSummary struct {
+	Count                   int64   `json:"count"`
+	AvgTimeToFirstByteMs    float64 `json:"avg_ttfb_ms"`
+	AvgTimeToFirstContentMs float64 `json:"avg_ttft_ms"`
+	AvgDurationMs           float64 `json:"avg_duration_ms"`
+	AvgTokensPerSecond      float64 `json:"avg_tokens_per_second"`
 }
 
 // TokenStats captures the token usage breakdown for a request.
@@ -118,6 +137,9 @@ type StatisticsSnapshot struct {
 	RequestsByHour map[string]int64 `json:"requests_by_hour"`
 	TokensByDay    map[string]int64 `json:"tokens_by_day"`
 	TokensByHour   map[string]int64 `json:"tokens_by_hour"`
+
+	// StreamingByAuth summarises streaming latency per auth index.
+	StreamingByAuth map[string]StreamingSummary `json:"streaming_by_auth,omitempty"`
 }
 
 // APISnapshot summarises metrics for a single API key.
@@ -132,6 +154,8 @@ type ModelSnapshot struct {
 	TotalRequests int64           `json:"total_requests"`
 	TotalTokens   int64           `json:"total_tokens"`
 	Details       []RequestDetail `json:"details"`
+	// Streaming summarises latency of the streaming requests among Details.
+	Streaming *StreamingSummary `json:"streaming,omitempty"`
 }
 
 var defaultRequestStatistics = NewRequestStatistics()
@@ -202,6 +226,7 @@ func (s *RequestStatistics) Record(ctx context.Context, record coreusage.Record)
 		AuthIndex: record.AuthIndex,
 		Tokens:    detail,
 		Failed:    failed,
+		Stream:    newStreamStats(record.Stream),
 	})
 
 	s.requestsByDay[dayKey]++
@@ -239,6 +264,7 @@ func (s *RequestStatistics) Snapshot() StatisticsSnapshot {
 	result.TotalTokens = s.totalTokens
 
 	result.APIs = make(map[string]APISnapshot, len(s.apis))
+	byAuth := make(map[string]*streamingAccumulator)
 	for apiName, stats := range s.apis {
 		apiSnapshot := APISnapshot{
 			TotalRequests: stats.TotalRequests,
@@ -248,14 +274,36 @@ func (s *RequestStatistics) Snapshot() StatisticsSnapshot {
 		for modelName, modelStatsValue := range stats.Models {
 			requestDetails := make([]RequestDetail, len(modelStatsValue.Details))
 			copy(requestDetails, modelStatsValue.Details)
+			var perModel streamingAccumulator
+			for _, detail := range requestDetails {
+				if detail.Stream == nil {
+					continue
+				}
+				perModel.add(detail.Stream)
+				if detail.AuthIndex != "" {
+					acc, ok := byAuth[detail.AuthIndex]
+					if !ok {
+						acc = &streamingAccumulator{}
+						byAuth[detail.AuthIndex] = acc
+					}
+					acc.add(detail.Stream)
+				}
+			}
 			apiSnapshot.Models[modelName] = ModelSnapshot{
 				TotalRequests: modelStatsValue.TotalRequests,
 				TotalTokens:   modelStatsValue.TotalTokens,
 				Details:       requestDetails,
+				Streaming:     perModel.summary(),
 			}
 		}
 		result.APIs[apiName] = apiSnapshot
 	}
+	if len(byAuth) > 0 {
+		result.StreamingByAuth = make(map[string]StreamingSummary, len(byAuth))
+		for authIndex, acc := range byAuth {
+			result.StreamingByAuth[authIndex] = *acc.summary()
+		}
+	}
 
 	result.RequestsByDay = make(map[string]int64, len(s.requestsByDay))
 	for k, v := range s.requestsByDay {
@@ -463,6 +511,60 @@ func normaliseTokenStats(tokens TokenStats) TokenStats {
 	return tokens
 }
 
+func newStreamStats(metrics *coreusage.StreamMetrics) *StreamStats {
+	if metrics == nil {
+		return nil
+	}
+	return &StreamStats{
+		TimeToFirstByteMs:    metrics.TimeToFirstByte.Milliseconds(),
+		TimeToFirstContentMs: metrics.TimeToFirstContent.Milliseconds(),
+		DurationMs:           metrics.Duration.Milliseconds(),
+		TokensPerSecond:      metrics.OutputTokensPerSecond,
+	}
+}
+
+type streamingAccumulator struct {
+	count           int64
+	ttfb            int64
+	ttft            int64
+	ttftCount       int64
+	duration        int64
+	tokensPerSecond float64
+	tpsCount        int64
+}
+
+func (a *streamingAccumulator) add(stats *StreamStats) {
+	a.count++
+	a.ttfb += stats.TimeToFirstByteMs
+	a.duration += stats.DurationMs
+	if stats.TimeToFirstContentMs > 0 {
+		a.ttft += stats.TimeToFirstContentMs
+		a.ttftCount++
+	}
+	if stats.TokensPerSecond > 0 {
+		a.tokensPerSecond += stats.TokensPerSecond
+		a.tpsCount++
+	}
+}
+
+func (a *streamingAccumulator) summary() *StreamingSummary {
+	if a.count == 0 {
+		return nil
+	}
+	summary := &StreamingSummary{
+		Count:                a.count,
+		AvgTimeToFirstByteMs: float64(a.ttfb) / float64(a.count),
+		AvgDurationMs:        float64(a.duration) / float64(a.count),
+	}
+	if a.ttftCount > 0 {
+		summary.AvgTimeToFirstContentMs = float64(a.ttft) / float64(a.ttftCount)
+	}
+	if a.tpsCount > 0 {
+		summary.AvgTokensPerSecond = a.tokensPerSecond / float64(a.tpsCount)
+	}
+	return summary
+}
+
 func formatHour(hour int) string {
 	if hour < 0 {
 		hour = 0
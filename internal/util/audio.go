@@ -0,0 +1,247 @@
+package util
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"strconv"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// DefaultPCMSampleRate is the sample rate of OpenAI pcm16 audio and Gemini speech output.
+const DefaultPCMSampleRate = 24000
+
+// Audio is an audio clip read from any client format, so a translator can re-encode it
+// for the target provider.
+type Audio struct {
+	// MimeType is the normalized media type, e.g. "audio/wav" or "audio/L16;codec=pcm;rate=24000".
+	MimeType string
+	// Data is the base64-encoded audio.
+	Data string
+}
+
+// canonicalAudioMimeTypes maps the registered media types from misc.MimeTypes, and a few
+// common aliases, to the names Gemini documents for audio input.
+var canonicalAudioMimeTypes = map[string]string{
+	"audio/x-wav":  "audio/wav",
+	"audio/wave":   "audio/wav",
+	"audio/mpeg":   "audio/mp3",
+	"audio/x-flac": "audio/flac",
+	"audio/x-aac":  "audio/aac",
+	"audio/x-aiff": "audio/aiff",
+}
+
+// IsAudioMimeType reports whether mimeType names audio content.
+func IsAudioMimeType(mimeType string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(mimeType)), "audio/")
+}
+
+// AudioMimeType resolves an OpenAI input_audio format ("wav", "mp3", "pcm16", ...) or a
+// media type to a normalized audio media type. Raw PCM maps to 16-bit little-endian PCM
+// at DefaultPCMSampleRate.
+func AudioMimeType(format string) string {
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch format {
+	case "":
+		return "audio/wav"
+	case "pcm", "pcm16", "l16":
+		return pcmMimeType(DefaultPCMSampleRate)
+	}
+	mimeType := format
+	if !strings.Contains(format, "/") {
+		var ok bool
+		if mimeType, ok = misc.MimeTypes[format]; !ok {
+			mimeType = "audio/" + format
+		}
+	}
+	base, _, _ := strings.Cut(mimeType, ";")
+	if canonical, ok := canonicalAudioMimeTypes[base]; ok {
+		return canonical
+	}
+	return mimeType
+}
+
+// AudioFormat returns the OpenAI audio format name for a media type, e.g. "wav" for
+// audio/x-wav and "pcm16" for raw L16 PCM.
+func AudioFormat(mimeType string) string {
+	if pcmSampleRate(mimeType) > 0 {
+		return "pcm16"
+	}
+	base, _, _ := strings.Cut(AudioMimeType(mimeType), ";")
+	return strings.TrimPrefix(strings.TrimPrefix(base, "audio/"), "x-")
+}
+
+// IsPCMAudio reports whether mimeType names raw 16-bit PCM without a container.
+func IsPCMAudio(mimeType string) bool {
+	return pcmSampleRate(mimeType) > 0
+}
+
+func pcmMimeType(sampleRate int) string {
+	return "audio/L16;codec=pcm;rate=" + strconv.Itoa(sampleRate)
+}
+
+// pcmSampleRate returns the sample rate of a raw PCM media type such as
+// "audio/L16;codec=pcm;rate=24000", or 0 when mimeType is not raw PCM.
+func pcmSampleRate(mimeType string) int {
+	base, params, _ := strings.Cut(strings.ToLower(mimeType), ";")
+	base = strings.TrimSpace(base)
+	if base != "audio/l16" && base != "audio/pcm" {
+		return 0
+	}
+	for _, param := range strings.Split(params, ";") {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		if key == "rate" {
+			if rate, err := strconv.Atoi(value); err == nil && rate > 0 {
+				return rate
+			}
+		}
+	}
+	return DefaultPCMSampleRate
+}
+
+// OpenAIInputAudio reads an OpenAI Chat Completions "input_audio" content part.
+func OpenAIInputAudio(part gjson.Result) (Audio, bool) {
+	if part.Get("type").String() != "input_audio" {
+		return Audio{}, false
+	}
+	data := part.Get("input_audio.data").String()
+	if data == "" {
+		return Audio{}, false
+	}
+	return Audio{MimeType: AudioMimeType(part.Get("input_audio.format").String()), Data: data}, true
+}
+
+// GeminiAudio reads a Gemini inlineData part whose media type is audio.
+func GeminiAudio(part gjson.Result) (Audio, bool) {
+	inline := part.Get("inlineData")
+	if !inline.Exists() {
+		inline = part.Get("inline_data")
+	}
+	mimeType := inline.Get("mimeType").String()
+	if mimeType == "" {
+		mimeType = inline.Get("mime_type").String()
+	}
+	data := inline.Get("data").String()
+	if !IsAudioMimeType(mimeType) || data == "" {
+		return Audio{}, false
+	}
+	if pcmSampleRate(mimeType) == 0 {
+		mimeType = AudioMimeType(mimeType)
+	}
+	return Audio{MimeType: mimeType, Data: data}, true
+}
+
+// InFormat converts the clip to an OpenAI audio format where that only needs a container
+// change: raw PCM is wrapped in a WAV header for "wav", and a PCM WAV file is unwrapped
+// for "pcm16". Other combinations are returned unchanged since no transcoding is done.
+func (a Audio) InFormat(format string) Audio {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", "wav":
+		rate := pcmSampleRate(a.MimeType)
+		if rate == 0 {
+			return a
+		}
+		pcm, err := base64.StdEncoding.DecodeString(a.Data)
+		if err != nil {
+			return a
+		}
+		return Audio{MimeType: "audio/wav", Data: base64.StdEncoding.EncodeToString(WAVFromPCM(pcm, rate))}
+	case "pcm16":
+		if AudioMimeType(a.MimeType) != "audio/wav" {
+			return a
+		}
+		wav, err := base64.StdEncoding.DecodeString(a.Data)
+		if err != nil {
+			return a
+		}
+		pcm, rate, ok := PCMFromWAV(wav)
+		if !ok {
+			return a
+		}
+		return Audio{MimeType: pcmMimeType(rate), Data: base64.StdEncoding.EncodeToString(pcm)}
+	}
+	return a
+}
+
+// GeminiPart encodes the clip as a Gemini inlineData part. OpenAI pcm16 input is wrapped
+// in a WAV header, since Gemini only documents container formats for audio input.
+func (a Audio) GeminiPart() string {
+	a = a.InFormat("wav")
+	part := `{"inlineData":{"mimeType":"","data":""}}`
+	part, _ = sjson.Set(part, "inlineData.mimeType", a.MimeType)
+	part, _ = sjson.Set(part, "inlineData.data", a.Data)
+	return part
+}
+
+// OpenAIContentPart encodes the clip as an OpenAI Chat Completions "input_audio" part.
+// Raw PCM is wrapped in a WAV header because input_audio only takes wav and mp3.
+func (a Audio) OpenAIContentPart() string {
+	a = a.InFormat("wav")
+	part := `{"type":"input_audio","input_audio":{"data":"","format":""}}`
+	part, _ = sjson.Set(part, "input_audio.data", a.Data)
+	part, _ = sjson.Set(part, "input_audio.format", AudioFormat(a.MimeType))
+	return part
+}
+
+// WAVFromPCM wraps 16-bit little-endian mono PCM samples in a WAV header.
+func WAVFromPCM(pcm []byte, sampleRate int) []byte {
+	const channels, bitsPerSample = 1, 16
+	blockAlign := channels * bitsPerSample / 8
+	var b bytes.Buffer
+	b.Grow(44 + len(pcm))
+	b.WriteString("RIFF")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(36+len(pcm)))
+	b.WriteString("WAVEfmt ")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(16))
+	_ = binary.Write(&b, binary.LittleEndian, uint16(1))
+	_ = binary.Write(&b, binary.LittleEndian, uint16(channels))
+	_ = binary.Write(&b, binary.LittleEndian, uint32(sampleRate))
+	_ = binary.Write(&b, binary.LittleEndian, uint32(sampleRate*blockAlign))
+	_ = binary.Write(&b, binary.LittleEndian, uint16(blockAlign))
+	_ = binary.Write(&b, binary.LittleEndian, uint16(bitsPerSample))
+	b.WriteString("data")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(len(pcm)))
+	b.Write(pcm)
+	return b.Bytes()
+}
+
+// PCMFromWAV returns the samples and sample rate of a 16-bit mono PCM WAV file. It reports
+// false for any other encoding.
+func PCMFromWAV(wav []byte) ([]byte, int, bool) {
+	if len(wav) < 12 || string(wav[0:4]) != "RIFF" || string(wav[8:12]) != "WAVE" {
+		return nil, 0, false
+	}
+	rate := 0
+	for pos := 12; pos+8 <= len(wav); {
+		id := string(wav[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(wav[pos+4 : pos+8]))
+		body := wav[pos+8:]
+		if size > len(body) {
+			size = len(body)
+		}
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, 0, false
+			}
+			format := binary.LittleEndian.Uint16(body[0:2])
+			channels := binary.LittleEndian.Uint16(body[2:4])
+			bits := binary.LittleEndian.Uint16(body[14:16])
+			if format != 1 || channels != 1 || bits != 16 {
+				return nil, 0, false
+			}
+			rate = int(binary.LittleEndian.Uint32(body[4:8]))
+		case "data":
+			if rate == 0 {
+				return nil, 0, false
+			}
+			return body[:size], rate, true
+		}
+		pos += 8 + size + size%2
+	}
+	return nil, 0, false
+}
@@ -0,0 +1,63 @@
+package util
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestAudioMimeTypeNormalization(t *testing.T) {
+	cases := map[string]string{
+		"wav":         "audio/wav",
+		"mp3":         "audio/mp3",
+		"flac":        "audio/flac",
+		"audio/x-wav": "audio/wav",
+		"audio/mpeg":  "audio/mp3",
+		"ogg":         "audio/ogg",
+		"pcm16":       "audio/L16;codec=pcm;rate=24000",
+	}
+	for format, want := range cases {
+		if got := AudioMimeType(format); got != want {
+			t.Errorf("AudioMimeType(%q) = %q, want %q", format, got, want)
+		}
+	}
+	if got := AudioFormat("audio/L16;codec=pcm;rate=24000"); got != "pcm16" {
+		t.Errorf("AudioFormat(L16) = %q, want pcm16", got)
+	}
+	if got := AudioFormat("audio/x-aiff"); got != "aiff" {
+		t.Errorf("AudioFormat(audio/x-aiff) = %q, want aiff", got)
+	}
+}
+
+func TestAudioPCMAndWAVConversion(t *testing.T) {
+	pcm := []byte{1, 0, 2, 0, 3, 0, 4, 0}
+	raw := Audio{MimeType: "audio/L16;codec=pcm;rate=16000", Data: base64.StdEncoding.EncodeToString(pcm)}
+
+	wav := raw.InFormat("wav")
+	if wav.MimeType != "audio/wav" {
+		t.Fatalf("InFormat(wav) mime = %q", wav.MimeType)
+	}
+	back := wav.InFormat("pcm16")
+	if back != raw {
+		t.Fatalf("InFormat(pcm16) = %+v, want %+v", back, raw)
+	}
+
+	// OpenAI pcm16 input reaches Gemini as WAV, and Gemini audio reaches OpenAI as input_audio.
+	input, ok := OpenAIInputAudio(gjson.Parse(`{"type":"input_audio","input_audio":{"data":"AQACAA==","format":"pcm16"}}`))
+	if !ok {
+		t.Fatal("OpenAIInputAudio() ok = false")
+	}
+	part := gjson.Parse(input.GeminiPart())
+	if part.Get("inlineData.mimeType").String() != "audio/wav" {
+		t.Fatalf("GeminiPart() = %s", part.Raw)
+	}
+	fromGemini, ok := GeminiAudio(part)
+	if !ok {
+		t.Fatal("GeminiAudio() ok = false")
+	}
+	openaiPart := gjson.Parse(fromGemini.OpenAIContentPart())
+	if openaiPart.Get("input_audio.format").String() != "wav" || openaiPart.Get("input_audio.data").String() != part.Get("inlineData.data").String() {
+		t.Fatalf("OpenAIContentPart() = %s", openaiPart.Raw)
+	}
+}
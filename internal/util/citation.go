@@ -0,0 +1,197 @@
+package util
+
+import (
+	"unicode/utf8"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Citation is a web source backing a span of response text, read from any provider
+// format so a translator can re-encode it for the client.
+type Citation struct {
+	// URL is the cited page.
+	URL string
+	// Title is the page title, if known.
+	Title string
+	// StartIndex and EndIndex delimit the supported span in characters (runes) of the
+	// response text.
+	StartIndex int
+	EndIndex   int
+	// CitedText is the supported text, if known.
+	CitedText string
+}
+
+// GeminiCitations reads the citations of a Gemini groundingMetadata object. Gemini
+// measures segments in bytes of text, the full candidate text the metadata refers to;
+// the result uses character offsets instead. Sources that no support refers to are cited
+// for the whole text.
+func GeminiCitations(metadata gjson.Result, text string) []Citation {
+	chunks := metadata.Get("groundingChunks").Array()
+	if len(chunks) == 0 {
+		return nil
+	}
+	source := func(i int) (string, string, bool) {
+		if i < 0 || i >= len(chunks) {
+			return "", "", false
+		}
+		web := chunks[i].Get("web")
+		if !web.Exists() {
+			web = chunks[i].Get("retrievedContext")
+		}
+		uri := web.Get("uri").String()
+		return uri, web.Get("title").String(), uri != ""
+	}
+
+	var citations []Citation
+	used := make(map[int]bool)
+	metadata.Get("groundingSupports").ForEach(func(_, support gjson.Result) bool {
+		segment := support.Get("segment")
+		start := runeOffset(text, int(segment.Get("startIndex").Int()))
+		end := runeOffset(text, int(segment.Get("endIndex").Int()))
+		support.Get("groundingChunkIndices").ForEach(func(_, index gjson.Result) bool {
+			i := int(index.Int())
+			if uri, title, ok := source(i); ok {
+				used[i] = true
+				citations = append(citations, Citation{URL: uri, Title: title, StartIndex: start, EndIndex: end, CitedText: segment.Get("text").String()})
+			}
+			return true
+		})
+		return true
+	})
+	for i := range chunks {
+		if uri, title, ok := source(i); ok && !used[i] {
+			citations = append(citations, Citation{URL: uri, Title: title, EndIndex: utf8.RuneCountInString(text)})
+		}
+	}
+	return citations
+}
+
+// runeOffset converts a byte offset in text to a character offset, clamping it to text.
+func runeOffset(text string, byteOffset int) int {
+	if byteOffset <= 0 {
+		return 0
+	}
+	if byteOffset > len(text) {
+		byteOffset = len(text)
+	}
+	return utf8.RuneCountInString(text[:byteOffset])
+}
+
+// ClaudeCitation reads a single Claude citation that points at a URL, as sent in text
+// blocks and citations_delta events. Document citations have no URL and are skipped.
+func ClaudeCitation(citation gjson.Result) (Citation, bool) {
+	url := citation.Get("url").String()
+	if url == "" {
+		return Citation{}, false
+	}
+	return Citation{URL: url, Title: citation.Get("title").String(), CitedText: citation.Get("cited_text").String()}, true
+}
+
+// OpenAIAnnotationCitation reads an OpenAI Chat Completions url_citation annotation.
+func OpenAIAnnotationCitation(annotation gjson.Result) (Citation, bool) {
+	if annotation.Get("type").String() != "url_citation" {
+		return Citation{}, false
+	}
+	c := annotation.Get("url_citation")
+	return Citation{
+		URL:        c.Get("url").String(),
+		Title:      c.Get("title").String(),
+		StartIndex: int(c.Get("start_index").Int()),
+		EndIndex:   int(c.Get("end_index").Int()),
+	}, true
+}
+
+// ResponsesAnnotationCitation reads an OpenAI Responses url_citation annotation.
+func ResponsesAnnotationCitation(annotation gjson.Result) (Citation, bool) {
+	if annotation.Get("type").String() != "url_citation" {
+		return Citation{}, false
+	}
+	return Citation{
+		URL:        annotation.Get("url").String(),
+		Title:      annotation.Get("title").String(),
+		StartIndex: int(annotation.Get("start_index").Int()),
+		EndIndex:   int(annotation.Get("end_index").Int()),
+	}, true
+}
+
+// OpenAIAnnotation encodes the citation as an OpenAI Chat Completions url_citation annotation.
+func (c Citation) OpenAIAnnotation() string {
+	annotation := `{"type":"url_citation","url_citation":{"start_index":0,"end_index":0,"url":"","title":""}}`
+	annotation, _ = sjson.Set(annotation, "url_citation.start_index", c.StartIndex)
+	annotation, _ = sjson.Set(annotation, "url_citation.end_index", c.EndIndex)
+	annotation, _ = sjson.Set(annotation, "url_citation.url", c.URL)
+	annotation, _ = sjson.Set(annotation, "url_citation.title", c.Title)
+	return annotation
+}
+
+// ResponsesAnnotation encodes the citation as an OpenAI Responses url_citation annotation.
+func (c Citation) ResponsesAnnotation() string {
+	annotation := `{"type":"url_citation","start_index":0,"end_index":0,"url":"","title":""}`
+	annotation, _ = sjson.Set(annotation, "start_index", c.StartIndex)
+	annotation, _ = sjson.Set(annotation, "end_index", c.EndIndex)
+	annotation, _ = sjson.Set(annotation, "url", c.URL)
+	annotation, _ = sjson.Set(annotation, "title", c.Title)
+	return annotation
+}
+
+// ClaudeCitation encodes the citation as a Claude web_search_result_location citation.
+// The span is not part of Claude's format, which cites whole text blocks.
+func (c Citation) ClaudeCitation() string {
+	citation := `{"type":"web_search_result_location","url":"","title":"","cited_text":"","encrypted_index":""}`
+	citation, _ = sjson.Set(citation, "url", c.URL)
+	citation, _ = sjson.Set(citation, "title", c.Title)
+	citation, _ = sjson.Set(citation, "cited_text", c.CitedText)
+	return citation
+}
+
+// ClaudeCitationStream tracks the web citations of streamed Claude text blocks. Claude
+// sends a block's citations in content_block_start or citations_delta events, ahead of or
+// alongside its text, so spans are only known once the block stops.
+type ClaudeCitationStream struct {
+	length  int
+	start   int
+	pending []Citation
+}
+
+// StartBlock begins a text block from its content_block_start payload.
+func (s *ClaudeCitationStream) StartBlock(block gjson.Result) {
+	s.start = s.length
+	s.pending = nil
+	block.Get("citations").ForEach(func(_, citation gjson.Result) bool {
+		s.AddCitation(citation)
+		return true
+	})
+}
+
+// AddCitation records a citation of the current block, as sent in a citations_delta.
+func (s *ClaudeCitationStream) AddCitation(citation gjson.Result) {
+	if c, ok := ClaudeCitation(citation); ok {
+		s.pending = append(s.pending, c)
+	}
+}
+
+// AddText records streamed text of the current block.
+func (s *ClaudeCitationStream) AddText(text string) {
+	s.length += utf8.RuneCountInString(text)
+}
+
+// StopBlock ends the current block and returns its citations spanning the block text.
+func (s *ClaudeCitationStream) StopBlock() []Citation {
+	citations := s.pending
+	for i := range citations {
+		citations[i].StartIndex, citations[i].EndIndex = s.start, s.length
+	}
+	s.pending = nil
+	s.start = s.length
+	return citations
+}
+
+// Span returns the part of text the citation covers, or "" when the span is out of range.
+func (c Citation) Span(text string) string {
+	runes := []rune(text)
+	if c.StartIndex < 0 || c.StartIndex >= c.EndIndex || c.EndIndex > len(runes) {
+		return ""
+	}
+	return string(runes[c.StartIndex:c.EndIndex])
+}
@@ -0,0 +1,56 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestGeminiCitationsUseCharacterOffsets(t *testing.T) {
+	// "é" is two bytes, so the byte segment 7..13 is the character span 6..12.
+	text := "Café: Paris is the capital."
+	metadata := gjson.Parse(`{
+		"groundingChunks":[{"web":{"uri":"https://a.example","title":"a.example"}},{"web":{"uri":"https://b.example","title":"b.example"}}],
+		"groundingSupports":[{"segment":{"startIndex":7,"endIndex":13,"text":"Paris "},"groundingChunkIndices":[0]}]
+	}`)
+
+	citations := GeminiCitations(metadata, text)
+	if len(citations) != 2 {
+		t.Fatalf("GeminiCitations() = %+v, want 2 citations", citations)
+	}
+	if c := citations[0]; c.URL != "https://a.example" || c.StartIndex != 6 || c.EndIndex != 12 || c.Span(text) != "Paris " {
+		t.Fatalf("supported citation = %+v", c)
+	}
+	// A source without a support covers the whole text.
+	if c := citations[1]; c.URL != "https://b.example" || c.StartIndex != 0 || c.EndIndex != 27 {
+		t.Fatalf("unsupported citation = %+v", c)
+	}
+}
+
+func TestClaudeCitationStreamSpansBlocks(t *testing.T) {
+	var s ClaudeCitationStream
+
+	s.StartBlock(gjson.Parse(`{"type":"text","text":""}`))
+	s.AddText("Intro. ")
+	if got := s.StopBlock(); len(got) != 0 {
+		t.Fatalf("uncited block citations = %+v", got)
+	}
+
+	s.StartBlock(gjson.Parse(`{"type":"text","text":""}`))
+	s.AddCitation(gjson.Parse(`{"type":"web_search_result_location","url":"https://a.example","title":"A","cited_text":"x"}`))
+	s.AddCitation(gjson.Parse(`{"type":"char_location","document_index":0,"cited_text":"y"}`))
+	s.AddText("Cited text.")
+	got := s.StopBlock()
+	if len(got) != 1 || got[0].StartIndex != 7 || got[0].EndIndex != 18 || got[0].Title != "A" {
+		t.Fatalf("StopBlock() = %+v", got)
+	}
+
+	annotation := gjson.Parse(got[0].OpenAIAnnotation())
+	back, ok := OpenAIAnnotationCitation(annotation)
+	if !ok || back.URL != "https://a.example" || back.StartIndex != 7 || back.EndIndex != 18 {
+		t.Fatalf("OpenAI annotation round trip = %+v (ok=%v)", back, ok)
+	}
+	if back, ok = ResponsesAnnotationCitation(gjson.Parse(got[0].ResponsesAnnotation())); !ok || back.EndIndex != 18 {
+		t.Fatalf("Responses annotation round trip = %+v (ok=%v)", back, ok)
+	}
+}
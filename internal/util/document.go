@@ -0,0 +1,343 @@
+package util
+
+import (
+	"encoding/base64"
+	"path"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Document is a file attachment (PDF or plain text) read from any client format, so a
+// translator can re-encode it for the target provider. Exactly one of Data, URL, FileID
+// or Text is normally set.
+type Document struct {
+	// MimeType is the media type, e.g. "application/pdf".
+	MimeType string
+	// Data is the base64-encoded file content.
+	Data string
+	// URL is a remote URL or provider file URI.
+	URL string
+	// FileID references a file uploaded through a provider Files API.
+	FileID string
+	// Filename is the original file name or document title, if known.
+	Filename string
+	// Text is inline plain-text content.
+	Text string
+}
+
+// IsDocumentMimeType reports whether mimeType is handled as a document rather than as
+// image, audio or video input.
+func IsDocumentMimeType(mimeType string) bool {
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+	return mimeType == "application/pdf" || strings.HasPrefix(mimeType, "text/")
+}
+
+// DocumentMimeType guesses a media type from a file name using misc.MimeTypes, falling
+// back to application/pdf.
+func DocumentMimeType(filename string) string {
+	ext := strings.TrimPrefix(strings.ToLower(path.Ext(filename)), ".")
+	if mimeType, ok := misc.MimeTypes[ext]; ok && ext != "" {
+		return mimeType
+	}
+	return "application/pdf"
+}
+
+// parseDataURL splits a base64 data URL into media type and payload.
+func parseDataURL(url string) (mimeType, data string, ok bool) {
+	if !strings.HasPrefix(url, "data:") {
+		return "", "", false
+	}
+	header, payload, found := strings.Cut(strings.TrimPrefix(url, "data:"), ",")
+	if !found || !strings.HasSuffix(header, ";base64") {
+		return "", "", false
+	}
+	return strings.TrimSuffix(header, ";base64"), payload, true
+}
+
+// ClaudeDocument reads a Claude "document" content block.
+func ClaudeDocument(block gjson.Result) (Document, bool) {
+	if block.Get("type").String() != "document" {
+		return Document{}, false
+	}
+	doc := Document{Filename: block.Get("title").String()}
+	source := block.Get("source")
+	switch source.Get("type").String() {
+	case "base64":
+		doc.MimeType = source.Get("media_type").String()
+		doc.Data = source.Get("data").String()
+	case "text":
+		doc.MimeType = source.Get("media_type").String()
+		doc.Text = source.Get("data").String()
+	case "content":
+		var parts []string
+		source.Get("content").ForEach(func(_, item gjson.Result) bool {
+			if item.Type == gjson.String {
+				parts = append(parts, item.String())
+			} else if item.Get("type").String() == "text" {
+				parts = append(parts, item.Get("text").String())
+			}
+			return true
+		})
+		doc.Text = strings.Join(parts, "\n")
+	case "url":
+		doc.URL = source.Get("url").String()
+	case "file":
+		doc.FileID = source.Get("file_id").String()
+	default:
+		return Document{}, false
+	}
+	if doc.MimeType == "" {
+		if doc.Text != "" {
+			doc.MimeType = "text/plain"
+		} else {
+			doc.MimeType = DocumentMimeType(doc.URL)
+		}
+	}
+	return doc, true
+}
+
+// OpenAIFileDocument reads an OpenAI Chat Completions "file" content part.
+func OpenAIFileDocument(part gjson.Result) (Document, bool) {
+	if part.Get("type").String() != "file" {
+		return Document{}, false
+	}
+	file := part.Get("file")
+	doc := Document{Filename: file.Get("filename").String(), FileID: file.Get("file_id").String()}
+	if mimeType, data, ok := parseDataURL(file.Get("file_data").String()); ok {
+		doc.MimeType, doc.Data = mimeType, data
+	} else if raw := file.Get("file_data").String(); raw != "" {
+		doc.Data = raw
+	}
+	if doc.Data == "" && doc.FileID == "" {
+		return Document{}, false
+	}
+	if doc.MimeType == "" {
+		doc.MimeType = DocumentMimeType(doc.Filename)
+	}
+	return doc, true
+}
+
+// ResponsesFileDocument reads an OpenAI Responses "input_file" content part.
+func ResponsesFileDocument(part gjson.Result) (Document, bool) {
+	if part.Get("type").String() != "input_file" {
+		return Document{}, false
+	}
+	doc := Document{
+		Filename: part.Get("filename").String(),
+		FileID:   part.Get("file_id").String(),
+		URL:      part.Get("file_url").String(),
+	}
+	if mimeType, data, ok := parseDataURL(part.Get("file_data").String()); ok {
+		doc.MimeType, doc.Data = mimeType, data
+	} else if raw := part.Get("file_data").String(); raw != "" {
+		doc.Data = raw
+	}
+	if doc.Data == "" && doc.FileID == "" && doc.URL == "" {
+		return Document{}, false
+	}
+	if doc.MimeType == "" {
+		name := doc.Filename
+		if name == "" {
+			name = doc.URL
+		}
+		doc.MimeType = DocumentMimeType(name)
+	}
+	return doc, true
+}
+
+// GeminiDocument reads a Gemini inlineData or fileData part whose media type is a
+// document. Image, audio and video parts are left to the existing media handling.
+func GeminiDocument(part gjson.Result) (Document, bool) {
+	inline := part.Get("inlineData")
+	if !inline.Exists() {
+		inline = part.Get("inline_data")
+	}
+	if inline.Exists() {
+		mimeType := inline.Get("mimeType").String()
+		if mimeType == "" {
+			mimeType = inline.Get("mime_type").String()
+		}
+		if !IsDocumentMimeType(mimeType) {
+			return Document{}, false
+		}
+		return Document{MimeType: mimeType, Data: inline.Get("data").String()}, true
+	}
+
+	file := part.Get("fileData")
+	if !file.Exists() {
+		file = part.Get("file_data")
+	}
+	if file.Exists() {
+		mimeType := file.Get("mimeType").String()
+		if mimeType == "" {
+			mimeType = file.Get("mime_type").String()
+		}
+		uri := file.Get("fileUri").String()
+		if uri == "" {
+			uri = file.Get("file_uri").String()
+		}
+		if !IsDocumentMimeType(mimeType) || uri == "" {
+			return Document{}, false
+		}
+		return Document{MimeType: mimeType, URL: uri}, true
+	}
+	return Document{}, false
+}
+
+// PlainText returns the document content when it is text, decoding base64 text files.
+func (d Document) PlainText() (string, bool) {
+	if d.Text != "" {
+		return d.Text, true
+	}
+	if d.Data != "" && strings.HasPrefix(strings.ToLower(d.MimeType), "text/") {
+		if decoded, err := base64.StdEncoding.DecodeString(d.Data); err == nil {
+			return string(decoded), true
+		}
+	}
+	return "", false
+}
+
+// reference describes a document the target format cannot carry, so the model at least
+// sees that an attachment was sent.
+func (d Document) reference() string {
+	label := d.Filename
+	if label == "" {
+		label = d.URL
+	}
+	if label == "" {
+		label = d.FileID
+	}
+	if label == "" {
+		label = d.MimeType
+	}
+	return "[Attached document not supported by this model: " + label + "]"
+}
+
+// ClaudeBlock encodes the document as a Claude "document" content block.
+func (d Document) ClaudeBlock() string {
+	block := `{"type":"document","source":{}}`
+	if text, ok := d.PlainText(); ok {
+		block, _ = sjson.Set(block, "source.type", "text")
+		block, _ = sjson.Set(block, "source.media_type", "text/plain")
+		block, _ = sjson.Set(block, "source.data", text)
+	} else {
+		switch {
+		case d.Data != "":
+			block, _ = sjson.Set(block, "source.type", "base64")
+			block, _ = sjson.Set(block, "source.media_type", d.MimeType)
+			block, _ = sjson.Set(block, "source.data", d.Data)
+		case d.FileID != "":
+			block, _ = sjson.Set(block, "source.type", "file")
+			block, _ = sjson.Set(block, "source.file_id", d.FileID)
+		case strings.HasPrefix(d.URL, "http://") || strings.HasPrefix(d.URL, "https://"):
+			block, _ = sjson.Set(block, "source.type", "url")
+			block, _ = sjson.Set(block, "source.url", d.URL)
+		default:
+			block = `{"type":"text","text":""}`
+			block, _ = sjson.Set(block, "text", d.reference())
+			return block
+		}
+	}
+	if d.Filename != "" {
+		block, _ = sjson.Set(block, "title", d.Filename)
+	}
+	return block
+}
+
+// OpenAIContentPart encodes the document as an OpenAI Chat Completions content part.
+// Text documents become text parts; remote URLs, which Chat Completions cannot fetch,
+// become a text reference.
+func (d Document) OpenAIContentPart() string {
+	if text, ok := d.PlainText(); ok {
+		part := `{"type":"text","text":""}`
+		part, _ = sjson.Set(part, "text", text)
+		return part
+	}
+	if d.Data == "" && d.FileID == "" {
+		part := `{"type":"text","text":""}`
+		part, _ = sjson.Set(part, "text", d.reference())
+		return part
+	}
+	part := `{"type":"file","file":{}}`
+	if d.Data != "" {
+		part, _ = sjson.Set(part, "file.file_data", "data:"+d.MimeType+";base64,"+d.Data)
+		filename := d.Filename
+		if filename == "" {
+			filename = "document" + documentExtension(d.MimeType)
+		}
+		part, _ = sjson.Set(part, "file.filename", filename)
+	} else {
+		part, _ = sjson.Set(part, "file.file_id", d.FileID)
+	}
+	return part
+}
+
+// ResponsesContentPart encodes the document as an OpenAI Responses input part.
+func (d Document) ResponsesContentPart() string {
+	if text, ok := d.PlainText(); ok {
+		part := `{"type":"input_text","text":""}`
+		part, _ = sjson.Set(part, "text", text)
+		return part
+	}
+	part := `{"type":"input_file"}`
+	switch {
+	case d.Data != "":
+		filename := d.Filename
+		if filename == "" {
+			filename = "document" + documentExtension(d.MimeType)
+		}
+		part, _ = sjson.Set(part, "filename", filename)
+		part, _ = sjson.Set(part, "file_data", "data:"+d.MimeType+";base64,"+d.Data)
+	case d.FileID != "":
+		part, _ = sjson.Set(part, "file_id", d.FileID)
+	case strings.HasPrefix(d.URL, "http://") || strings.HasPrefix(d.URL, "https://"):
+		part, _ = sjson.Set(part, "file_url", d.URL)
+	default:
+		part = `{"type":"input_text","text":""}`
+		part, _ = sjson.Set(part, "text", d.reference())
+	}
+	return part
+}
+
+// GeminiPart encodes the document as a Gemini content part. Files API IDs from other
+// providers cannot be resolved and become a text reference.
+func (d Document) GeminiPart() string {
+	switch {
+	case d.Text != "":
+		part := `{"text":""}`
+		part, _ = sjson.Set(part, "text", d.Text)
+		return part
+	case d.Data != "":
+		part := `{"inlineData":{"mimeType":"","data":""}}`
+		part, _ = sjson.Set(part, "inlineData.mimeType", d.MimeType)
+		part, _ = sjson.Set(part, "inlineData.data", d.Data)
+		return part
+	case d.URL != "":
+		part := `{"fileData":{"mimeType":"","fileUri":""}}`
+		part, _ = sjson.Set(part, "fileData.mimeType", d.MimeType)
+		part, _ = sjson.Set(part, "fileData.fileUri", d.URL)
+		return part
+	}
+	part := `{"text":""}`
+	part, _ = sjson.Set(part, "text", d.reference())
+	return part
+}
+
+func documentExtension(mimeType string) string {
+	switch strings.ToLower(mimeType) {
+	case "application/pdf":
+		return ".pdf"
+	case "text/plain":
+		return ".txt"
+	case "text/markdown":
+		return ".md"
+	case "text/csv":
+		return ".csv"
+	case "text/html":
+		return ".html"
+	}
+	return ""
+}
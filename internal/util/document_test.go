@@ -0,0 +1,67 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestDocumentRoundTripsAcrossFormats(t *testing.T) {
+	claude := gjson.Parse(`{"type":"document","title":"report.pdf","source":{"type":"base64","media_type":"application/pdf","data":"JVBERi0="}}`)
+	doc, ok := ClaudeDocument(claude)
+	if !ok {
+		t.Fatal("ClaudeDocument() ok = false")
+	}
+
+	openaiPart := gjson.Parse(doc.OpenAIContentPart())
+	fromOpenAI, ok := OpenAIFileDocument(openaiPart)
+	if !ok || fromOpenAI.Data != doc.Data || fromOpenAI.MimeType != "application/pdf" || fromOpenAI.Filename != "report.pdf" {
+		t.Fatalf("OpenAI round trip = %+v (ok=%v), part %s", fromOpenAI, ok, openaiPart.Raw)
+	}
+
+	responsesPart := gjson.Parse(fromOpenAI.ResponsesContentPart())
+	fromResponses, ok := ResponsesFileDocument(responsesPart)
+	if !ok || fromResponses.Data != doc.Data || fromResponses.MimeType != "application/pdf" {
+		t.Fatalf("Responses round trip = %+v (ok=%v), part %s", fromResponses, ok, responsesPart.Raw)
+	}
+
+	geminiPart := gjson.Parse(fromResponses.GeminiPart())
+	fromGemini, ok := GeminiDocument(geminiPart)
+	if !ok || fromGemini.Data != doc.Data || fromGemini.MimeType != "application/pdf" {
+		t.Fatalf("Gemini round trip = %+v (ok=%v), part %s", fromGemini, ok, geminiPart.Raw)
+	}
+
+	block := gjson.Parse(fromGemini.ClaudeBlock())
+	if block.Get("source.type").String() != "base64" || block.Get("source.data").String() != "JVBERi0=" {
+		t.Fatalf("ClaudeBlock() = %s", block.Raw)
+	}
+}
+
+func TestDocumentReferencesAndText(t *testing.T) {
+	if _, ok := GeminiDocument(gjson.Parse(`{"inlineData":{"mimeType":"image/png","data":"AAAA"}}`)); ok {
+		t.Fatal("image inlineData should not be treated as a document")
+	}
+
+	// Base64 text files are sent as text to formats that only take PDFs as files.
+	textDoc, ok := OpenAIFileDocument(gjson.Parse(`{"type":"file","file":{"filename":"notes.txt","file_data":"aGVsbG8="}}`))
+	if !ok || textDoc.MimeType != "text/plain" {
+		t.Fatalf("OpenAIFileDocument() = %+v, ok=%v", textDoc, ok)
+	}
+	if got := gjson.Parse(textDoc.ClaudeBlock()).Get("source.data").String(); got != "hello" {
+		t.Fatalf("text document data = %q, want hello", got)
+	}
+
+	// A Files API id cannot be resolved by Gemini, so it becomes a visible reference.
+	fileRef := Document{FileID: "file-123", MimeType: "application/pdf"}
+	if got := gjson.Parse(fileRef.GeminiPart()).Get("text").String(); got == "" {
+		t.Fatalf("GeminiPart() for file id = %s, want a text reference", fileRef.GeminiPart())
+	}
+
+	url, ok := ResponsesFileDocument(gjson.Parse(`{"type":"input_file","file_url":"https://example.com/a.pdf"}`))
+	if !ok {
+		t.Fatal("ResponsesFileDocument() with file_url ok = false")
+	}
+	if got := gjson.Parse(url.ClaudeBlock()).Get("source.url").String(); got != "https://example.com/a.pdf" {
+		t.Fatalf("ClaudeBlock() url = %q", got)
+	}
+}
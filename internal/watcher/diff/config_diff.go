@@ -141,6 +141,46 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 		}
 	}
 
+	// Bedrock credentials (do not print key material)
+	if len(oldCfg.BedrockKey) != len(newCfg.BedrockKey) {
+		changes = append(changes, fmt.Sprintf("bedrock count: %d -> %d", len(oldCfg.BedrockKey), len(newCfg.BedrockKey)))
+	} else {
+		for i := range oldCfg.BedrockKey {
+			o := oldCfg.BedrockKey[i]
+			n := newCfg.BedrockKey[i]
+			if strings.TrimSpace(o.Region) != strings.TrimSpace(n.Region) {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].region: %s -> %s", i, strings.TrimSpace(o.Region), strings.TrimSpace(n.Region)))
+			}
+			if strings.TrimSpace(o.BaseURL) != strings.TrimSpace(n.BaseURL) {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].base-url: %s -> %s", i, strings.TrimSpace(o.BaseURL), strings.TrimSpace(n.BaseURL)))
+			}
+			if strings.TrimSpace(o.ProxyURL) != strings.TrimSpace(n.ProxyURL) {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].proxy-url: %s -> %s", i, formatProxyURL(o.ProxyURL), formatProxyURL(n.ProxyURL)))
+			}
+			if strings.TrimSpace(o.Prefix) != strings.TrimSpace(n.Prefix) {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].prefix: %s -> %s", i, strings.TrimSpace(o.Prefix), strings.TrimSpace(n.Prefix)))
+			}
+			if strings.TrimSpace(o.Profile) != strings.TrimSpace(n.Profile) {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].profile: %s -> %s", i, strings.TrimSpace(o.Profile), strings.TrimSpace(n.Profile)))
+			}
+			if strings.TrimSpace(o.AccessKeyID) != strings.TrimSpace(n.AccessKeyID) ||
+				strings.TrimSpace(o.SecretAccessKey) != strings.TrimSpace(n.SecretAccessKey) ||
+				strings.TrimSpace(o.SessionToken) != strings.TrimSpace(n.SessionToken) {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].credentials: updated", i))
+			}
+			oldModels := SummarizeClaudeModels(o.Models)
+			newModels := SummarizeClaudeModels(n.Models)
+			if oldModels.hash != newModels.hash {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].models: updated (%d -> %d entries)", i, oldModels.count, newModels.count))
+			}
+			oldExcluded := SummarizeExcludedModels(o.ExcludedModels)
+			newExcluded := SummarizeExcludedModels(n.ExcludedModels)
+			if oldExcluded.hash != newExcluded.hash {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].excluded-models: updated (%d -> %d entries)", i, oldExcluded.count, newExcluded.count))
+			}
+		}
+	}
+
 	// Codex keys (do not print key material)
 	if len(oldCfg.CodexKey) != len(newCfg.CodexKey) {
 		changes = append(changes, fmt.Sprintf("codex-api-key count: %d -> %d", len(oldCfg.CodexKey), len(newCfg.CodexKey)))
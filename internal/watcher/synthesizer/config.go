@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	kiroauth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/kiro"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher/diff"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	log "github.com/sirupsen/logrus"
@@ -35,6 +36,8 @@ func (s *ConfigSynthesizer) Synthesize(ctx *SynthesisContext) ([]*coreauth.Auth,
 	out = append(out, s.synthesizeCodexKeys(ctx)...)
 	// Kiro (AWS CodeWhisperer)
 	out = append(out, s.synthesizeKiroKeys(ctx)...)
+	// AWS Bedrock
+	out = append(out, s.synthesizeBedrockKeys(ctx)...)
 	// OpenAI-compat
 	out = append(out, s.synthesizeOpenAICompat(ctx)...)
 	// Vertex-compat
@@ -322,6 +325,63 @@ func (s *ConfigSynthesizer) synthesizeVertexCompat(ctx *SynthesisContext) []*cor
 	return out
 }
 
+// synthesizeBedrockKeys creates Auth entries for AWS Bedrock credentials.
+func (s *ConfigSynthesizer) synthesizeBedrockKeys(ctx *SynthesisContext) []*coreauth.Auth {
+	cfg := ctx.Config
+	now := ctx.Now
+	idGen := ctx.IDGenerator
+
+	out := make([]*coreauth.Auth, 0, len(cfg.BedrockKey))
+	for i := range cfg.BedrockKey {
+		bk := cfg.BedrockKey[i]
+		key := bk.GetAPIKey()
+		if key == "" {
+			continue
+		}
+		region := strings.TrimSpace(bk.Region)
+		if region == "" {
+			region = config.DefaultBedrockRegion
+		}
+		base := bk.GetBaseURL()
+		id, token := idGen.Next("bedrock:apikey", key, base)
+		attrs := map[string]string{
+			"source":   fmt.Sprintf("config:bedrock[%s]", token),
+			"api_key":  key,
+			"base_url": base,
+			"region":   region,
+		}
+		if bk.AccessKeyID != "" {
+			attrs["access_key_id"] = bk.AccessKeyID
+			attrs["secret_access_key"] = bk.SecretAccessKey
+			if bk.SessionToken != "" {
+				attrs["session_token"] = bk.SessionToken
+			}
+		} else {
+			attrs["profile"] = bk.Profile
+		}
+		if bk.Priority != 0 {
+			attrs["priority"] = strconv.Itoa(bk.Priority)
+		}
+		if hash := diff.ComputeClaudeModelsHash(bk.Models); hash != "" {
+			attrs["models_hash"] = hash
+		}
+		a := &coreauth.Auth{
+			ID:         id,
+			Provider:   "bedrock",
+			Label:      "bedrock-apikey",
+			Prefix:     strings.TrimSpace(bk.Prefix),
+			Status:     coreauth.StatusActive,
+			ProxyURL:   strings.TrimSpace(bk.ProxyURL),
+			Attributes: attrs,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		ApplyAuthExcludedModelsMeta(a, cfg, bk.ExcludedModels, "apikey")
+		out = append(out, a)
+	}
+	return out
+}
+
 // synthesizeKiroKeys creates Auth entries for Kiro (AWS CodeWhisperer) tokens.
 func (s *ConfigSynthesizer) synthesizeKiroKeys(ctx *SynthesisContext) []*coreauth.Auth {
 	cfg := ctx.Config
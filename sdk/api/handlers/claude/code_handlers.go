@@ -14,6 +14,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	. "github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
@@ -123,12 +125,27 @@ func (h *ClaudeCodeAPIHandler) ClaudeCountTokens(c *gin.Context) {
 }
 
 // ClaudeModels handles the Claude models listing endpoint.
-// It returns a JSON response containing available Claude models and their specifications.
+// It returns a JSON response containing available Claude models and their specifications,
+// newest first as in the Anthropic Models API. With ?extended=true every model carries a
+// capabilities object.
 //
 // Parameters:
 //   - c: The Gin context for the request.
 func (h *ClaudeCodeAPIHandler) ClaudeModels(c *gin.Context) {
 	models := h.Models()
+	if h.ExtendedModels(c) {
+		models = registry.GetGlobalRegistry().GetAvailableModelsExtended("claude")
+	}
+	sort.SliceStable(models, func(i, j int) bool {
+		ci, _ := models[i]["created_at"].(string)
+		cj, _ := models[j]["created_at"].(string)
+		if ci != cj {
+			return ci > cj
+		}
+		idI, _ := models[i]["id"].(string)
+		idJ, _ := models[j]["id"].(string)
+		return idI < idJ
+	})
 	firstID := ""
 	lastID := ""
 	if len(models) > 0 {
@@ -148,6 +165,21 @@ func (h *ClaudeCodeAPIHandler) ClaudeModels(c *gin.Context) {
 	})
 }
 
+// ClaudeModel handles GET /v1/models/{model_id} for Anthropic clients, returning a single
+// model object or a not_found_error.
+//
+// Parameters:
+//   - c: The Gin context for the request.
+func (h *ClaudeCodeAPIHandler) ClaudeModel(c *gin.Context) {
+	modelID := strings.TrimPrefix(c.Param("model"), "/")
+	model := registry.GetGlobalRegistry().GetAvailableModel(modelID, "claude", h.ExtendedModels(c))
+	if model == nil {
+		writeClaudeError(c, http.StatusNotFound, fmt.Sprintf("model: %s", modelID))
+		return
+	}
+	c.JSON(http.StatusOK, model)
+}
+
 // handleNonStreamingResponse handles non-streaming content generation requests for Claude models.
 // This function processes the request synchronously and returns the complete generated
 // response in a single API call. It supports various generation parameters and
@@ -173,29 +205,33 @@ func (h *ClaudeCodeAPIHandler) handleNonStreamingResponse(c *gin.Context, rawJSO
 		return
 	}
 
-	// Decompress gzipped responses - Claude API sometimes returns gzip without Content-Encoding header
-	// This fixes title generation and other non-streaming responses that arrive compressed
-	if len(resp) >= 2 && resp[0] == 0x1f && resp[1] == 0x8b {
-		gzReader, errGzip := gzip.NewReader(bytes.NewReader(resp))
-		if errGzip != nil {
-			log.Warnf("failed to decompress gzipped Claude response: %v", errGzip)
-		} else {
-			defer func() {
-				if errClose := gzReader.Close(); errClose != nil {
-					log.Warnf("failed to close Claude gzip reader: %v", errClose)
-				}
-			}()
-			decompressed, errRead := io.ReadAll(gzReader)
-			if errRead != nil {
-				log.Warnf("failed to read decompressed Claude response: %v", errRead)
-			} else {
-				resp = decompressed
-			}
+	_, _ = c.Writer.Write(decompressClaudeResponse(resp))
+	cliCancel()
+}
+
+// decompressClaudeResponse decompresses gzipped responses - Claude API sometimes returns gzip
+// without Content-Encoding header. This fixes title generation and other non-streaming
+// responses that arrive compressed.
+func decompressClaudeResponse(resp []byte) []byte {
+	if len(resp) < 2 || resp[0] != 0x1f || resp[1] != 0x8b {
+		return resp
+	}
+	gzReader, errGzip := gzip.NewReader(bytes.NewReader(resp))
+	if errGzip != nil {
+		log.Warnf("failed to decompress gzipped Claude response: %v", errGzip)
+		return resp
+	}
+	defer func() {
+		if errClose := gzReader.Close(); errClose != nil {
+			log.Warnf("failed to close Claude gzip reader: %v", errClose)
 		}
+	}()
+	decompressed, errRead := io.ReadAll(gzReader)
+	if errRead != nil {
+		log.Warnf("failed to read decompressed Claude response: %v", errRead)
+		return resp
 	}
-
-	_, _ = c.Writer.Write(resp)
-	cliCancel()
+	return decompressed
 }
 
 // handleStreamingResponse streams Claude-compatible responses backed by Gemini.
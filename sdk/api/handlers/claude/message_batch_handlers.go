@@ -0,0 +1,424 @@
+package claude
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/batch"
+	. "github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	messageBatchEndpoint         = "/v1/messages"
+	messageBatchIDPrefix         = "msgbatch_"
+	maxMessageBatchRequests      = 100000
+	defaultMessageBatchListLimit = 20
+	maxMessageBatchListLimit     = 1000
+)
+
+var messageBatchCustomIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// ClaudeMessageBatchAPIHandler serves the Anthropic Message Batches endpoints. Batches are
+// scheduled and persisted by the shared batch manager and each request runs through the
+// same execution path as /v1/messages.
+type ClaudeMessageBatchAPIHandler struct {
+	*handlers.BaseAPIHandler
+	manager *batch.Manager
+}
+
+// NewClaudeMessageBatchAPIHandler creates the message batch handler and registers the
+// /v1/messages executor with the shared batch manager.
+//
+// Parameters:
+//   - apiHandlers: The base API handler instance.
+//   - manager: The batch manager that stores requests and schedules jobs.
+//
+// Returns:
+//   - *ClaudeMessageBatchAPIHandler: A new Claude message batch API handler instance.
+func NewClaudeMessageBatchAPIHandler(apiHandlers *handlers.BaseAPIHandler, manager *batch.Manager) *ClaudeMessageBatchAPIHandler {
+	h := &ClaudeMessageBatchAPIHandler{BaseAPIHandler: apiHandlers, manager: manager}
+	manager.Handle(messageBatchEndpoint, h.executeRequest)
+	return h
+}
+
+// HandlerType returns the identifier for this handler implementation.
+func (h *ClaudeMessageBatchAPIHandler) HandlerType() string {
+	return Claude
+}
+
+// Models returns a list of models supported by this handler.
+func (h *ClaudeMessageBatchAPIHandler) Models() []map[string]any {
+	return registry.GetGlobalRegistry().GetAvailableModels("claude")
+}
+
+// messageBatchCounts mirrors the request_counts of an Anthropic message batch.
+type messageBatchCounts struct {
+	Processing int `json:"processing"`
+	Succeeded  int `json:"succeeded"`
+	Errored    int `json:"errored"`
+	Canceled   int `json:"canceled"`
+	Expired    int `json:"expired"`
+}
+
+// messageBatch is the Anthropic message batch object.
+type messageBatch struct {
+	ID                string             `json:"id"`
+	Type              string             `json:"type"`
+	ProcessingStatus  string             `json:"processing_status"`
+	RequestCounts     messageBatchCounts `json:"request_counts"`
+	EndedAt           *string            `json:"ended_at"`
+	CreatedAt         string             `json:"created_at"`
+	ExpiresAt         *string            `json:"expires_at"`
+	ArchivedAt        *string            `json:"archived_at"`
+	CancelInitiatedAt *string            `json:"cancel_initiated_at"`
+	ResultsURL        *string            `json:"results_url"`
+}
+
+// executeRequest runs one batch item as a non-streaming /v1/messages request under the
+// batch owner's API key. Items of an owner whose key has been removed fail.
+func (h *ClaudeMessageBatchAPIHandler) executeRequest(ctx context.Context, owner string, body []byte) (int, []byte) {
+	var resp []byte
+	var errMsg *interfaces.ErrorMessage
+	if h.OwnerAPIKeyConfigured(owner) {
+		body, _ = sjson.DeleteBytes(body, "stream")
+		modelName := gjson.GetBytes(body, "model").String()
+		resp, errMsg = h.ExecuteWithAuthManager(h.WithOwnerAPIKey(ctx, owner), Claude, modelName, body, "")
+	} else {
+		errMsg = &interfaces.ErrorMessage{StatusCode: http.StatusUnauthorized, Error: handlers.ErrOwnerAPIKeyRemoved}
+	}
+	if errMsg != nil {
+		status := errMsg.StatusCode
+		if status <= 0 {
+			status = http.StatusInternalServerError
+		}
+		message := http.StatusText(status)
+		if errMsg.Error != nil {
+			message = errMsg.Error.Error()
+		}
+		errorBody, _ := json.Marshal(claudeErrorResponse{
+			Type:  "error",
+			Error: claudeErrorDetail{Type: claudeErrorType(status), Message: message},
+		})
+		return status, errorBody
+	}
+	return http.StatusOK, decompressClaudeResponse(resp)
+}
+
+// claudeErrorType maps an HTTP status onto an Anthropic error type.
+func claudeErrorType(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "invalid_request_error"
+	case http.StatusUnauthorized:
+		return "authentication_error"
+	case http.StatusForbidden:
+		return "permission_error"
+	case http.StatusNotFound:
+		return "not_found_error"
+	case http.StatusRequestEntityTooLarge:
+		return "request_too_large"
+	case http.StatusTooManyRequests:
+		return "rate_limit_error"
+	case 529:
+		return "overloaded_error"
+	}
+	return "api_error"
+}
+
+func writeClaudeError(c *gin.Context, status int, message string) {
+	c.JSON(status, claudeErrorResponse{
+		Type:  "error",
+		Error: claudeErrorDetail{Type: claudeErrorType(status), Message: message},
+	})
+}
+
+// writeBatchError maps batch package errors onto Anthropic error responses.
+func writeBatchError(c *gin.Context, err error, id string) {
+	switch {
+	case errors.Is(err, batch.ErrNotFound):
+		writeClaudeError(c, http.StatusNotFound, fmt.Sprintf("No message batch found with id '%s'.", id))
+	case errors.Is(err, batch.ErrInvalidRequest):
+		writeClaudeError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, batch.ErrFileTooLarge):
+		writeClaudeError(c, http.StatusRequestEntityTooLarge, err.Error())
+	default:
+		writeClaudeError(c, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// getBatch returns the batch with the given ID if it is a message batch created with the
+// request's API key.
+func (h *ClaudeMessageBatchAPIHandler) getBatch(c *gin.Context, id string) (*batch.Batch, error) {
+	b, err := h.manager.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !b.VisibleTo(handlers.RequestOwner(c)) || b.Endpoint != messageBatchEndpoint {
+		return nil, batch.ErrNotFound
+	}
+	return b, nil
+}
+
+// toMessageBatch renders b as an Anthropic message batch object.
+func (h *ClaudeMessageBatchAPIHandler) toMessageBatch(c *gin.Context, b *batch.Batch) messageBatch {
+	out := messageBatch{
+		ID:                b.ID,
+		Type:              "message_batch",
+		ProcessingStatus:  "in_progress",
+		CreatedAt:         formatBatchTime(&b.CreatedAt),
+		CancelInitiatedAt: optionalBatchTime(b.CancellingAt),
+	}
+	if b.ExpiresAt != nil {
+		out.ExpiresAt = optionalBatchTime(b.ExpiresAt)
+	}
+	switch b.Status {
+	case batch.StatusCancelling:
+		out.ProcessingStatus = "canceling"
+	case batch.StatusCompleted, batch.StatusFailed, batch.StatusExpired, batch.StatusCancelled:
+		out.ProcessingStatus = "ended"
+	}
+
+	if out.ProcessingStatus != "ended" {
+		counts := b.RequestCounts
+		out.RequestCounts = messageBatchCounts{
+			Processing: max(counts.Total-counts.Completed-counts.Failed, 0),
+			Succeeded:  counts.Completed,
+			Errored:    counts.Failed,
+		}
+		return out
+	}
+
+	for _, ts := range []*int64{b.CompletedAt, b.CancelledAt, b.ExpiredAt, b.FailedAt} {
+		if ts != nil {
+			out.EndedAt = optionalBatchTime(ts)
+			break
+		}
+	}
+	if counts := b.ResultCounts; counts != nil {
+		out.RequestCounts = messageBatchCounts{
+			Succeeded: counts.Succeeded,
+			Errored:   counts.Failed,
+			Canceled:  counts.Cancelled,
+			Expired:   counts.Expired,
+		}
+	}
+	resultsURL := handlers.RequestBaseURL(c) + "/v1/messages/batches/" + b.ID + "/results"
+	out.ResultsURL = &resultsURL
+	return out
+}
+
+func formatBatchTime(ts *int64) string {
+	return time.Unix(*ts, 0).UTC().Format(time.RFC3339)
+}
+
+func optionalBatchTime(ts *int64) *string {
+	if ts == nil {
+		return nil
+	}
+	formatted := formatBatchTime(ts)
+	return &formatted
+}
+
+// resultType classifies a batch result using Anthropic's result types.
+func resultType(result batch.Result) string {
+	switch {
+	case result.StatusCode >= 200 && result.StatusCode < 300:
+		return "succeeded"
+	case result.Code == batch.CodeCancelled:
+		return "canceled"
+	case result.Code == batch.CodeExpired:
+		return "expired"
+	}
+	return "errored"
+}
+
+// CreateMessageBatch handles POST /v1/messages/batches.
+func (h *ClaudeMessageBatchAPIHandler) CreateMessageBatch(c *gin.Context) {
+	rawJSON, err := c.GetRawData()
+	if err != nil {
+		writeClaudeError(c, http.StatusBadRequest, fmt.Sprintf("Invalid request: %v", err))
+		return
+	}
+	requests := gjson.GetBytes(rawJSON, "requests")
+	if !requests.IsArray() || len(requests.Array()) == 0 {
+		writeClaudeError(c, http.StatusBadRequest, "requests: must be a non-empty array")
+		return
+	}
+	items := requests.Array()
+	if len(items) > maxMessageBatchRequests {
+		writeClaudeError(c, http.StatusBadRequest, fmt.Sprintf("requests: must contain at most %d items", maxMessageBatchRequests))
+		return
+	}
+
+	var input bytes.Buffer
+	seen := make(map[string]bool, len(items))
+	for i, item := range items {
+		customID := item.Get("custom_id").String()
+		if !messageBatchCustomIDPattern.MatchString(customID) {
+			writeClaudeError(c, http.StatusBadRequest, fmt.Sprintf("requests.%d.custom_id: must be 1-64 characters of letters, digits, '_' or '-'", i))
+			return
+		}
+		if seen[customID] {
+			writeClaudeError(c, http.StatusBadRequest, fmt.Sprintf("requests.%d.custom_id: duplicate custom_id %q", i, customID))
+			return
+		}
+		seen[customID] = true
+		params := item.Get("params")
+		if !params.IsObject() || params.Get("model").String() == "" {
+			writeClaudeError(c, http.StatusBadRequest, fmt.Sprintf("requests.%d.params: must be an object with a model", i))
+			return
+		}
+		line := []byte(`{"method":"POST"}`)
+		line, _ = sjson.SetBytes(line, "custom_id", customID)
+		line, _ = sjson.SetBytes(line, "url", messageBatchEndpoint)
+		line, _ = sjson.SetRawBytes(line, "body", []byte(params.Raw))
+		input.Write(line)
+		input.WriteByte('\n')
+	}
+
+	owner := handlers.RequestOwner(c)
+	file, err := h.manager.CreateFile(owner, "message_batch.jsonl", batch.PurposeMessageBatch, &input)
+	if err != nil {
+		writeBatchError(c, err, "")
+		return
+	}
+	created, err := h.manager.Create(batch.CreateParams{
+		InputFileID:      file.ID,
+		Endpoint:         messageBatchEndpoint,
+		CompletionWindow: "24h",
+		IDPrefix:         messageBatchIDPrefix,
+		Owner:            owner,
+	})
+	if err != nil {
+		_ = h.manager.DeleteFile(file.ID)
+		writeBatchError(c, err, "")
+		return
+	}
+	c.JSON(http.StatusOK, h.toMessageBatch(c, created))
+}
+
+// GetMessageBatch handles GET /v1/messages/batches/{id}.
+func (h *ClaudeMessageBatchAPIHandler) GetMessageBatch(c *gin.Context) {
+	id := c.Param("id")
+	b, err := h.getBatch(c, id)
+	if err != nil {
+		writeBatchError(c, err, id)
+		return
+	}
+	c.JSON(http.StatusOK, h.toMessageBatch(c, b))
+}
+
+// ListMessageBatches handles GET /v1/messages/batches. It supports the limit (1-1000,
+// default 20), after_id and before_id query parameters; batches are listed newest first.
+func (h *ClaudeMessageBatchAPIHandler) ListMessageBatches(c *gin.Context) {
+	owner := handlers.RequestOwner(c)
+	batches := slices.DeleteFunc(h.manager.List(messageBatchEndpoint), func(b *batch.Batch) bool {
+		return !b.VisibleTo(owner)
+	})
+	limit := defaultMessageBatchListLimit
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 {
+		limit = min(v, maxMessageBatchListLimit)
+	}
+	hasMore := false
+	if beforeID := c.Query("before_id"); beforeID != "" {
+		if i := slices.IndexFunc(batches, func(b *batch.Batch) bool { return b.ID == beforeID }); i >= 0 {
+			batches = batches[:i]
+		}
+		if len(batches) > limit {
+			batches, hasMore = batches[len(batches)-limit:], true
+		}
+	} else {
+		if afterID := c.Query("after_id"); afterID != "" {
+			if i := slices.IndexFunc(batches, func(b *batch.Batch) bool { return b.ID == afterID }); i >= 0 {
+				batches = batches[i+1:]
+			}
+		}
+		if len(batches) > limit {
+			batches, hasMore = batches[:limit], true
+		}
+	}
+
+	data := make([]messageBatch, 0, len(batches))
+	for _, b := range batches {
+		data = append(data, h.toMessageBatch(c, b))
+	}
+	var firstID, lastID any
+	if len(data) > 0 {
+		firstID, lastID = data[0].ID, data[len(data)-1].ID
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data, "has_more": hasMore, "first_id": firstID, "last_id": lastID})
+}
+
+// CancelMessageBatch handles POST /v1/messages/batches/{id}/cancel.
+func (h *ClaudeMessageBatchAPIHandler) CancelMessageBatch(c *gin.Context) {
+	id := c.Param("id")
+	b, err := h.getBatch(c, id)
+	if err == nil && b.Status != batch.StatusCancelling {
+		b, err = h.manager.Cancel(id)
+	}
+	if err != nil {
+		writeBatchError(c, err, id)
+		return
+	}
+	c.JSON(http.StatusOK, h.toMessageBatch(c, b))
+}
+
+// MessageBatchResults handles GET /v1/messages/batches/{id}/results. Results are streamed
+// as JSONL in request order once the batch has ended.
+func (h *ClaudeMessageBatchAPIHandler) MessageBatchResults(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := h.getBatch(c, id); err != nil {
+		writeBatchError(c, err, id)
+		return
+	}
+	results, err := h.manager.Results(id)
+	if err != nil {
+		if errors.Is(err, batch.ErrInvalidRequest) {
+			writeClaudeError(c, http.StatusBadRequest, fmt.Sprintf("Message batch %s is still processing; results are available once processing has ended.", id))
+			return
+		}
+		writeBatchError(c, err, id)
+		return
+	}
+
+	c.Header("Content-Type", "application/x-jsonl")
+	c.Status(http.StatusOK)
+	for _, result := range results {
+		line := []byte(`{"custom_id":"","result":{}}`)
+		line, _ = sjson.SetBytes(line, "custom_id", result.CustomID)
+		kind := resultType(result)
+		line, _ = sjson.SetBytes(line, "result.type", kind)
+		switch kind {
+		case "succeeded":
+			line, _ = sjson.SetRawBytes(line, "result.message", result.Body)
+		case "errored":
+			errorBody := []byte(result.Body)
+			if gjson.GetBytes(errorBody, "type").String() != "error" {
+				message := "The request was not processed."
+				if result.StatusCode > 0 {
+					message = fmt.Sprintf("The request failed with status %d.", result.StatusCode)
+				}
+				errorBody, _ = json.Marshal(claudeErrorResponse{
+					Type:  "error",
+					Error: claudeErrorDetail{Type: claudeErrorType(result.StatusCode), Message: message},
+				})
+			}
+			line, _ = sjson.SetRawBytes(line, "result.error", errorBody)
+		}
+		_, _ = c.Writer.Write(append(line, '\n'))
+	}
+}
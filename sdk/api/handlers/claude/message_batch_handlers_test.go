@@ -0,0 +1,93 @@
+package claude
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/batch"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestMessageBatchLifecycle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager, err := batch.NewManager(t.TempDir(), batch.Options{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("new batch manager: %v", err)
+	}
+	h := NewClaudeMessageBatchAPIHandler(handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, nil), manager)
+	manager.Start()
+	defer manager.Close()
+
+	router := gin.New()
+	router.POST("/v1/messages/batches", h.CreateMessageBatch)
+	router.GET("/v1/messages/batches", h.ListMessageBatches)
+	router.GET("/v1/messages/batches/:id", h.GetMessageBatch)
+	router.GET("/v1/messages/batches/:id/results", h.MessageBatchResults)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-Forwarded-Proto", "javascript")
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do(http.MethodPost, "/v1/messages/batches", `{"requests":[{"custom_id":"a","params":{"model":"m"}},{"custom_id":"a","params":{"model":"m"}}]}`)
+	if rr.Code != http.StatusBadRequest || gjson.Get(rr.Body.String(), "error.type").String() != "invalid_request_error" {
+		t.Fatalf("duplicate custom_id should be rejected: %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = do(http.MethodPost, "/v1/messages/batches", `{"requests":[{"custom_id":"first","params":{"model":"unknown-claude-model","max_tokens":16,"messages":[{"role":"user","content":"hi"}]}},{"custom_id":"second","params":{"model":"unknown-claude-model","max_tokens":16,"messages":[]}}]}`)
+	created := rr.Body.String()
+	if rr.Code != http.StatusOK || !strings.HasPrefix(gjson.Get(created, "id").String(), "msgbatch_") {
+		t.Fatalf("create: %d %s", rr.Code, created)
+	}
+	if gjson.Get(created, "type").String() != "message_batch" || gjson.Get(created, "processing_status").String() != "in_progress" || gjson.Get(created, "request_counts.processing").Int() != 2 {
+		t.Fatalf("unexpected created batch: %s", created)
+	}
+	id := gjson.Get(created, "id").String()
+
+	var body string
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		body = do(http.MethodGet, "/v1/messages/batches/"+id, "").Body.String()
+		if gjson.Get(body, "processing_status").String() == "ended" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if gjson.Get(body, "processing_status").String() != "ended" || gjson.Get(body, "request_counts.errored").Int() != 2 {
+		t.Fatalf("unexpected ended batch: %s", body)
+	}
+	if url := gjson.Get(body, "results_url").String(); url != "http://example.com/v1/messages/batches/"+id+"/results" {
+		t.Fatalf("results_url = %q", url)
+	}
+
+	rr = do(http.MethodGet, "/v1/messages/batches/"+id+"/results", "")
+	lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+	if rr.Code != http.StatusOK || len(lines) != 2 {
+		t.Fatalf("results: %d %s", rr.Code, rr.Body.String())
+	}
+	if gjson.Get(lines[0], "custom_id").String() != "first" || gjson.Get(lines[0], "result.type").String() != "errored" || gjson.Get(lines[0], "result.error.type").String() != "error" {
+		t.Fatalf("unexpected result line: %s", lines[0])
+	}
+
+	// Request counts of an ended batch come from its persisted state, not the result files.
+	ended, _ := manager.Get(id)
+	for _, fileID := range []*string{&ended.InputFileID, ended.OutputFileID, ended.ErrorFileID} {
+		if fileID != nil {
+			_ = manager.DeleteFile(*fileID)
+		}
+	}
+	if list := do(http.MethodGet, "/v1/messages/batches?limit=1", "").Body.String(); gjson.Get(list, "data.0.id").String() != id || gjson.Get(list, "data.0.request_counts.errored").Int() != 2 {
+		t.Fatalf("list: %s", list)
+	}
+	if rr = do(http.MethodGet, "/v1/messages/batches/msgbatch_missing", ""); rr.Code != http.StatusNotFound || gjson.Get(rr.Body.String(), "error.type").String() != "not_found_error" {
+		t.Fatalf("expected not_found_error, got %d %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/contextguard"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tokencount"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+// EstimatedTokensHeader carries the pre-flight prompt token estimate of a request.
+const EstimatedTokensHeader = "X-CPA-Estimated-Input-Tokens"
+
+// guardContextWindow estimates the request tokens and checks them against the model's
+// context window, applying the configured strategy to requests that do not fit. It
+// returns the payload to send, or an error in the client format when the request is
+// rejected. Models without a known window are only estimated.
+func (h *BaseAPIHandler) guardContextWindow(ctx context.Context, handlerType, modelName string, rawJSON []byte) ([]byte, *interfaces.ErrorMessage) {
+	if h == nil || h.Cfg == nil || !h.Cfg.ContextGuard.Enabled {
+		return rawJSON, nil
+	}
+	cfg := h.Cfg.ContextGuard
+	baseModel := strings.TrimSpace(thinking.ParseSuffix(modelName).ModelName)
+	limit := contextWindowLimit(baseModel, rawJSON)
+	count := func(payload []byte) (int64, error) {
+		return tokencount.CountRequest(handlerType, baseModel, payload)
+	}
+	opts := contextguard.Options{Strategy: strings.ToLower(strings.TrimSpace(cfg.Strategy)), ToolResultMaxChars: cfg.ToolResultMaxChars}
+	out, estimate, err := contextguard.Fit(handlerType, rawJSON, limit, opts, count)
+
+	var exceeded *contextguard.ExceededError
+	switch {
+	case errors.As(err, &exceeded):
+		setEstimatedTokensHeader(ctx, estimate)
+		return rawJSON, &interfaces.ErrorMessage{
+			StatusCode: http.StatusBadRequest,
+			Error:      errors.New(string(contextguard.ErrorBody(handlerType, exceeded))),
+		}
+	case err != nil:
+		log.Debugf("context guard: estimate tokens for %s: %v", baseModel, err)
+		return rawJSON, nil
+	}
+	setEstimatedTokensHeader(ctx, estimate)
+	if !bytes.Equal(out, rawJSON) {
+		log.Debugf("context guard: applied %s to %s request, estimated %d of %d tokens", opts.Strategy, baseModel, estimate, limit)
+	}
+	return out, nil
+}
+
+// contextWindowLimit returns the input token budget of a model: its input limit, or its
+// context length less the output tokens the request asks for. It returns 0 when unknown.
+func contextWindowLimit(model string, rawJSON []byte) int64 {
+	info := registry.LookupModelInfo(model)
+	if info == nil {
+		return 0
+	}
+	if info.InputTokenLimit > 0 {
+		return int64(info.InputTokenLimit)
+	}
+	if info.ContextLength <= 0 {
+		return 0
+	}
+	limit := int64(info.ContextLength)
+	for _, path := range []string{"max_tokens", "max_completion_tokens", "max_output_tokens", "generationConfig.maxOutputTokens", "request.generationConfig.maxOutputTokens"} {
+		if output := gjson.GetBytes(rawJSON, path).Int(); output > 0 {
+			if output < limit {
+				limit -= output
+			}
+			break
+		}
+	}
+	return limit
+}
+
+// TokenEstimate collects the context guard estimate of executions that run concurrently
+// for one client request. Those executions must not write response headers themselves,
+// so the estimate is recorded here and written once by the caller after they finish.
+type TokenEstimate struct {
+	mu       sync.Mutex
+	estimate int64
+	set      bool
+}
+
+type tokenEstimateKey struct{}
+
+// WithTokenEstimate returns a context whose executions record their context guard
+// estimate in e instead of setting the response header.
+func WithTokenEstimate(ctx context.Context, e *TokenEstimate) context.Context {
+	return context.WithValue(ctx, tokenEstimateKey{}, e)
+}
+
+// WriteHeader sets the estimate header on the response when an estimate was recorded.
+func (e *TokenEstimate) WriteHeader(c *gin.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.set && c != nil {
+		c.Header(EstimatedTokensHeader, strconv.FormatInt(e.estimate, 10))
+	}
+}
+
+func (e *TokenEstimate) record(estimate int64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.estimate, e.set = estimate, true
+}
+
+func setEstimatedTokensHeader(ctx context.Context, estimate int64) {
+	if ctx == nil {
+		return
+	}
+	if e, ok := ctx.Value(tokenEstimateKey{}).(*TokenEstimate); ok && e != nil {
+		e.record(estimate)
+		return
+	}
+	if ginCtx, ok := ctx.Value("gin").(*gin.Context); ok && ginCtx != nil {
+		ginCtx.Header(EstimatedTokensHeader, strconv.FormatInt(estimate, 10))
+	}
+}
@@ -49,36 +49,46 @@ func (h *GeminiAPIHandler) Models() []map[string]any {
 // It returns a JSON response containing available Gemini models and their specifications.
 func (h *GeminiAPIHandler) GeminiModels(c *gin.Context) {
 	rawModels := h.Models()
+	if h.ExtendedModels(c) {
+		rawModels = registry.GetGlobalRegistry().GetAvailableModelsExtended("gemini")
+	}
 	normalizedModels := make([]map[string]any, 0, len(rawModels))
-	defaultMethods := []string{"generateContent"}
 	for _, model := range rawModels {
-		normalizedModel := make(map[string]any, len(model))
-		for k, v := range model {
-			normalizedModel[k] = v
-		}
-		if name, ok := normalizedModel["name"].(string); ok && name != "" {
-			if !strings.HasPrefix(name, "models/") {
-				normalizedModel["name"] = "models/" + name
-			}
-			if displayName, _ := normalizedModel["displayName"].(string); displayName == "" {
-				normalizedModel["displayName"] = name
-			}
-			if description, _ := normalizedModel["description"].(string); description == "" {
-				normalizedModel["description"] = name
-			}
-		}
-		if _, ok := normalizedModel["supportedGenerationMethods"]; !ok {
-			normalizedModel["supportedGenerationMethods"] = defaultMethods
-		}
-		normalizedModels = append(normalizedModels, normalizedModel)
+		normalizedModels = append(normalizedModels, normalizeGeminiModel(model))
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"models": normalizedModels,
 	})
 }
 
+// normalizeGeminiModel returns a copy of a registry model in the Gemini models.get shape:
+// a "models/" name prefix and defaults for displayName, description and
+// supportedGenerationMethods.
+func normalizeGeminiModel(model map[string]any) map[string]any {
+	normalizedModel := make(map[string]any, len(model))
+	for k, v := range model {
+		normalizedModel[k] = v
+	}
+	if name, ok := normalizedModel["name"].(string); ok && name != "" {
+		if !strings.HasPrefix(name, "models/") {
+			normalizedModel["name"] = "models/" + name
+		}
+		if displayName, _ := normalizedModel["displayName"].(string); displayName == "" {
+			normalizedModel["displayName"] = name
+		}
+		if description, _ := normalizedModel["description"].(string); description == "" {
+			normalizedModel["description"] = name
+		}
+	}
+	if _, ok := normalizedModel["supportedGenerationMethods"]; !ok {
+		normalizedModel["supportedGenerationMethods"] = []string{"generateContent"}
+	}
+	return normalizedModel
+}
+
 // GeminiGetHandler handles GET requests for specific Gemini model information.
-// It returns detailed information about a specific Gemini model based on the action parameter.
+// It returns the model named by the action parameter in the same shape as the listing,
+// or a Gemini NOT_FOUND error.
 func (h *GeminiAPIHandler) GeminiGetHandler(c *gin.Context) {
 	var request struct {
 		Action string `uri:"action" binding:"required"`
@@ -94,32 +104,17 @@ func (h *GeminiAPIHandler) GeminiGetHandler(c *gin.Context) {
 	}
 	action := strings.TrimPrefix(request.Action, "/")
 
-	// Get dynamic models from the global registry and find the matching one
-	availableModels := h.Models()
-	var targetModel map[string]any
-
-	for _, model := range availableModels {
-		name, _ := model["name"].(string)
-		// Match name with or without 'models/' prefix
-		if name == action || name == "models/"+action {
-			targetModel = model
-			break
-		}
-	}
-
-	if targetModel != nil {
-		// Ensure the name has 'models/' prefix in the output if it's a Gemini model
-		if name, ok := targetModel["name"].(string); ok && name != "" && !strings.HasPrefix(name, "models/") {
-			targetModel["name"] = "models/" + name
-		}
-		c.JSON(http.StatusOK, targetModel)
+	model := registry.GetGlobalRegistry().GetAvailableModel(action, "gemini", h.ExtendedModels(c))
+	if model != nil {
+		c.JSON(http.StatusOK, normalizeGeminiModel(model))
 		return
 	}
 
-	c.JSON(http.StatusNotFound, handlers.ErrorResponse{
-		Error: handlers.ErrorDetail{
-			Message: "Not Found",
-			Type:    "not_found",
+	c.JSON(http.StatusNotFound, gin.H{
+		"error": gin.H{
+			"code":    http.StatusNotFound,
+			"message": fmt.Sprintf("models/%s is not found for API version v1beta, or is not supported for generateContent.", strings.TrimPrefix(action, "models/")),
+			"status":  "NOT_FOUND",
 		},
 	})
 }
@@ -160,6 +155,8 @@ func (h *GeminiAPIHandler) GeminiHandler(c *gin.Context) {
 		h.handleStreamGenerateContent(c, action[0], rawJSON)
 	case "countTokens":
 		h.handleCountTokens(c, action[0], rawJSON)
+	case "embedContent", "batchEmbedContents":
+		h.handleEmbedContent(c, action[0], method, rawJSON)
 	}
 }
 
@@ -250,6 +247,27 @@ func (h *GeminiAPIHandler) handleStreamGenerateContent(c *gin.Context, modelName
 	}
 }
 
+// handleEmbedContent handles the embedContent and batchEmbedContents actions for
+// embedding models.
+//
+// Parameters:
+//   - c: The Gin context for the request
+//   - modelName: The name of the model to use
+//   - method: The Gemini embedding action
+//   - rawJSON: The raw JSON request body
+func (h *GeminiAPIHandler) handleEmbedContent(c *gin.Context, modelName, method string, rawJSON []byte) {
+	c.Header("Content-Type", "application/json")
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
+	resp, errMsg := h.ExecuteEmbeddingWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, method)
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+		return
+	}
+	_, _ = c.Writer.Write(resp)
+	cliCancel()
+}
+
 // handleCountTokens handles token counting requests for Gemini models.
 // This function counts the number of tokens in the provided content without
 // generating a response. It's useful for quota management and content validation.
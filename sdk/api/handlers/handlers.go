@@ -8,18 +8,22 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
 	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
 	"golang.org/x/net/context"
@@ -178,6 +182,9 @@ type BaseAPIHandler struct {
 
 	// Cfg holds the current application configuration.
 	Cfg *config.SDKConfig
+
+	// inFlight counts interactive requests currently being served.
+	inFlight atomic.Int64
 }
 
 // NewBaseAPIHandlers creates a new API handlers instance.
@@ -205,6 +212,16 @@ func NewBaseAPIHandlers(cfg *config.SDKConfig, authManager *coreauth.Manager) *B
 //   - cfg: The new application configuration
 func (h *BaseAPIHandler) UpdateClients(cfg *config.SDKConfig) { h.Cfg = cfg }
 
+// InteractiveInFlight reports how many client requests obtained a context via
+// GetContextWithCancel and have not finished yet. Background work such as batch
+// jobs uses it to yield capacity to interactive traffic.
+func (h *BaseAPIHandler) InteractiveInFlight() int64 {
+	if h == nil {
+		return 0
+	}
+	return h.inFlight.Load()
+}
+
 // GetAlt extracts the 'alt' parameter from the request query string.
 // It checks both 'alt' and '$alt' parameters and returns the appropriate value.
 //
@@ -226,6 +243,19 @@ func (h *BaseAPIHandler) GetAlt(c *gin.Context) string {
 	return alt
 }
 
+// ExtendedModels reports whether a model listing or retrieval request asked for the
+// extended form with per-model capabilities, via ?extended=true.
+//
+// Parameters:
+//   - c: The Gin context containing the HTTP request
+//
+// Returns:
+//   - bool: True when the extended form was requested
+func (h *BaseAPIHandler) ExtendedModels(c *gin.Context) bool {
+	extended, err := strconv.ParseBool(c.Query("extended"))
+	return err == nil && extended
+}
+
 // GetContextWithCancel creates a new context with cancellation capabilities.
 // It embeds the Gin context and the API handler into the new context for later use.
 // The returned cancel function also handles logging the API response if request logging is enabled.
@@ -266,8 +296,16 @@ func (h *BaseAPIHandler) GetContextWithCancel(handler interfaces.APIHandler, c *
 			}
 		}()
 	}
+	h.inFlight.Add(1)
+	go func(done <-chan struct{}) {
+		<-done
+		h.inFlight.Add(-1)
+	}(newCtx.Done())
 	newCtx = context.WithValue(newCtx, "gin", c)
 	newCtx = context.WithValue(newCtx, "handler", handler)
+	if handler != nil {
+		logging.SetGinHandlerFormat(c, handler.HandlerType())
+	}
 	return newCtx, func(params ...interface{}) {
 		if h.Cfg.RequestLog && len(params) == 1 {
 			if existing, exists := c.Get("API_RESPONSE"); exists {
@@ -381,10 +419,18 @@ func appendAPIResponse(c *gin.Context, data []byte) {
 // ExecuteWithAuthManager executes a non-streaming request via the core auth manager.
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
+	requestedModel := modelName
+	modelName, rawJSON = h.applyVirtualModel(handlerType, modelName, rawJSON)
 	providers, normalizedModel, errMsg := h.getRequestDetails(modelName)
 	if errMsg != nil {
 		return nil, errMsg
 	}
+	if isEmbeddingModel(normalizedModel) {
+		return nil, embeddingModelError(modelName)
+	}
+	if rawJSON, errMsg = h.guardContextWindow(ctx, handlerType, normalizedModel, rawJSON); errMsg != nil {
+		return nil, errMsg
+	}
 	reqMeta := requestExecutionMetadata(ctx)
 	reqMeta[coreexecutor.RequestedModelMetadataKey] = normalizedModel
 	req := coreexecutor.Request{
@@ -414,12 +460,14 @@ func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType
 		}
 		return nil, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
 	}
-	return cloneBytes(resp.Payload), nil
+	policy := h.reasoningOutputPolicy(ctx, requestedModel, normalizedModel)
+	return thinking.ApplyOutputPolicy(handlerType, cloneBytes(resp.Payload), policy), nil
 }
 
 // ExecuteCountWithAuthManager executes a non-streaming request via the core auth manager.
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
+	modelName, rawJSON = h.applyVirtualModel(handlerType, modelName, rawJSON)
 	providers, normalizedModel, errMsg := h.getRequestDetails(modelName)
 	if errMsg != nil {
 		return nil, errMsg
@@ -456,9 +504,54 @@ func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handle
 	return cloneBytes(resp.Payload), nil
 }
 
+// ExecuteEmbeddingWithAuthManager executes an embedding request via the core auth manager.
+// The action (e.g. "embeddings", "embedContent", "batchEmbedContents") is passed to
+// executors through Request.Metadata. Only models registered as embedding models are accepted.
+func (h *BaseAPIHandler) ExecuteEmbeddingWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, action string) ([]byte, *interfaces.ErrorMessage) {
+	providers, normalizedModel, errMsg := h.getRequestDetails(modelName)
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	if !isEmbeddingModel(normalizedModel) {
+		return nil, &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("model %s does not support embeddings", modelName)}
+	}
+	reqMeta := requestExecutionMetadata(ctx)
+	reqMeta[coreexecutor.RequestedModelMetadataKey] = normalizedModel
+	req := coreexecutor.Request{
+		Model:    normalizedModel,
+		Payload:  cloneBytes(rawJSON),
+		Metadata: map[string]any{"action": action},
+	}
+	opts := coreexecutor.Options{
+		Stream:          false,
+		OriginalRequest: cloneBytes(rawJSON),
+		SourceFormat:    sdktranslator.FromString(handlerType),
+	}
+	opts.Metadata = reqMeta
+	resp, err := h.AuthManager.Execute(ctx, providers, req, opts)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
+			if code := se.StatusCode(); code > 0 {
+				status = code
+			}
+		}
+		var addon http.Header
+		if he, ok := err.(interface{ Headers() http.Header }); ok && he != nil {
+			if hdr := he.Headers(); hdr != nil {
+				addon = hdr.Clone()
+			}
+		}
+		return nil, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+	}
+	return cloneBytes(resp.Payload), nil
+}
+
 // ExecuteStreamWithAuthManager executes a streaming request via the core auth manager.
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
+	requestedModel := modelName
+	modelName, rawJSON = h.applyVirtualModel(handlerType, modelName, rawJSON)
 	providers, normalizedModel, errMsg := h.getRequestDetails(modelName)
 	if errMsg != nil {
 		errChan := make(chan *interfaces.ErrorMessage, 1)
@@ -466,6 +559,18 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 		close(errChan)
 		return nil, errChan
 	}
+	if isEmbeddingModel(normalizedModel) {
+		errChan := make(chan *interfaces.ErrorMessage, 1)
+		errChan <- embeddingModelError(modelName)
+		close(errChan)
+		return nil, errChan
+	}
+	if rawJSON, errMsg = h.guardContextWindow(ctx, handlerType, normalizedModel, rawJSON); errMsg != nil {
+		errChan := make(chan *interfaces.ErrorMessage, 1)
+		errChan <- errMsg
+		close(errChan)
+		return nil, errChan
+	}
 	reqMeta := requestExecutionMetadata(ctx)
 	reqMeta[coreexecutor.RequestedModelMetadataKey] = normalizedModel
 	req := coreexecutor.Request{
@@ -479,8 +584,11 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 		SourceFormat:    sdktranslator.FromString(handlerType),
 	}
 	opts.Metadata = reqMeta
+	tracker := coreusage.NewStreamTracker()
+	ctx = coreusage.WithStreamTracker(ctx, tracker)
 	chunks, err := h.AuthManager.ExecuteStream(ctx, providers, req, opts)
 	if err != nil {
+		h.finishStreamTracking(tracker, normalizedModel)
 		errChan := make(chan *interfaces.ErrorMessage, 1)
 		status := http.StatusInternalServerError
 		if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
@@ -498,12 +606,15 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 		close(errChan)
 		return nil, errChan
 	}
+	reasoningOutput := thinking.NewOutputStream(handlerType, h.reasoningOutputPolicy(ctx, requestedModel, normalizedModel))
 	dataChan := make(chan []byte)
 	errChan := make(chan *interfaces.ErrorMessage, 1)
 	go func() {
 		defer close(dataChan)
 		defer close(errChan)
+		defer h.finishStreamTracking(tracker, normalizedModel)
 		sentPayload := false
+		sentContent := false
 		bootstrapRetries := 0
 		maxBootstrapRetries := StreamingBootstrapRetries(h.Cfg)
 
@@ -570,8 +681,19 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 					return
 				}
 				if len(chunk.Payload) > 0 {
+					tracker.MarkFirstByte()
+					if !sentContent && isContentChunk(chunk.Payload) {
+						sentContent = true
+						tracker.MarkFirstContent()
+					}
 					sentPayload = true
-					dataChan <- cloneBytes(chunk.Payload)
+					payload := cloneBytes(chunk.Payload)
+					if reasoningOutput != nil {
+						if payload = reasoningOutput.Process(payload); len(payload) == 0 {
+							continue
+						}
+					}
+					dataChan <- payload
 				}
 			}
 		}
@@ -579,6 +701,17 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 	return dataChan, errChan
 }
 
+// finishStreamTracking publishes the held usage records with stream metrics and reports
+// the measured latency to the auth manager as a selection quality signal. The latency is
+// reported for the routed model, which is the key the selector picks auths by.
+func (h *BaseAPIHandler) finishStreamTracking(tracker *coreusage.StreamTracker, model string) {
+	record, ok := tracker.Finish()
+	if !ok || record.Stream == nil || h.AuthManager == nil {
+		return
+	}
+	h.AuthManager.ReportStreamMetrics(record.AuthID, model, *record.Stream)
+}
+
 func statusFromError(err error) int {
 	if err == nil {
 		return 0
@@ -627,6 +760,16 @@ func (h *BaseAPIHandler) getRequestDetails(modelName string) (providers []string
 	return providers, resolvedModelName, nil
 }
 
+// isEmbeddingModel reports whether modelName resolves to an embedding-only model.
+func isEmbeddingModel(modelName string) bool {
+	baseModel := strings.TrimSpace(thinking.ParseSuffix(modelName).ModelName)
+	return registry.GetGlobalRegistry().GetModelInfo(baseModel, "").IsEmbedding()
+}
+
+func embeddingModelError(modelName string) *interfaces.ErrorMessage {
+	return &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("model %s is an embedding model and cannot be used for generation", modelName)}
+}
+
 func cloneBytes(src []byte) []byte {
 	if len(src) == 0 {
 		return nil
@@ -647,6 +790,17 @@ func cloneMetadata(src map[string]any) map[string]any {
 	return dst
 }
 
+// RequestBaseURL returns the origin that URLs pointing back at this server, such as image
+// and batch result URLs, are built on. It uses the scheme of the connection itself;
+// forwarding headers are client-controlled and are not trusted.
+func RequestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
+
 // WriteErrorResponse writes an error message to the response writer using the HTTP status embedded in the message.
 func (h *BaseAPIHandler) WriteErrorResponse(c *gin.Context, msg *interfaces.ErrorMessage) {
 	status := http.StatusInternalServerError
@@ -1,6 +1,17 @@
 package openai
 
-import "github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	. "github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	codexconverter "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/codex/openai/chat-completions"
+	responsesconverter "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/openai/openai/responses"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+)
 
 const (
 	openAIChatEndpoint      = "/chat/completions"
@@ -35,3 +46,54 @@ func endpointListContains(items []string, value string) bool {
 	}
 	return false
 }
+
+// chatRequestViaResponses returns the Responses request that serves a chat completions
+// request when the model is only reachable through /responses.
+func chatRequestViaResponses(modelName string, rawJSON []byte, stream bool) ([]byte, bool) {
+	if overrideEndpoint, ok := resolveEndpointOverride(modelName, openAIChatEndpoint); !ok || overrideEndpoint != openAIResponsesEndpoint {
+		return nil, false
+	}
+	if shouldTreatAsResponsesFormat(rawJSON) {
+		// Already responses-style payload; no conversion needed.
+		return rawJSON, true
+	}
+	return codexconverter.ConvertOpenAIRequestToCodex(modelName, rawJSON, stream), true
+}
+
+// responsesRequestViaChat returns the chat completions request that serves a Responses
+// request when the model is only reachable through /chat/completions.
+func responsesRequestViaChat(modelName string, rawJSON []byte, stream bool) ([]byte, bool) {
+	if overrideEndpoint, ok := resolveEndpointOverride(modelName, openAIResponsesEndpoint); !ok || overrideEndpoint != openAIChatEndpoint {
+		return nil, false
+	}
+	return responsesconverter.ConvertOpenAIResponsesRequestToOpenAIChatCompletions(modelName, rawJSON, stream), true
+}
+
+// executeChatViaResponses runs the Responses form of a chat completions request and
+// converts the result back into a chat completion.
+func executeChatViaResponses(ctx context.Context, h *handlers.BaseAPIHandler, modelName string, originalChatJSON, responsesJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
+	resp, errMsg := h.ExecuteWithAuthManager(ctx, OpenaiResponse, modelName, responsesJSON, alt)
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	converted := convertResponsesObjectToChatCompletion(ctx, modelName, originalChatJSON, responsesJSON, resp)
+	if converted == nil {
+		return nil, &interfaces.ErrorMessage{StatusCode: http.StatusInternalServerError, Error: fmt.Errorf("failed to convert response to chat completion format")}
+	}
+	return converted, nil
+}
+
+// executeResponsesViaChat runs the chat completions form of a Responses request and
+// converts the result back into a Responses object.
+func executeResponsesViaChat(ctx context.Context, h *handlers.BaseAPIHandler, modelName string, originalResponsesJSON, chatJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
+	resp, errMsg := h.ExecuteWithAuthManager(ctx, OpenAI, modelName, chatJSON, alt)
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	var param any
+	converted := responsesconverter.ConvertOpenAIChatCompletionsResponseToOpenAIResponsesNonStream(ctx, modelName, originalResponsesJSON, originalResponsesJSON, resp, &param)
+	if converted == "" {
+		return nil, &interfaces.ErrorMessage{StatusCode: http.StatusInternalServerError, Error: fmt.Errorf("failed to convert chat completion response to responses format")}
+	}
+	return []byte(converted), nil
+}
@@ -0,0 +1,391 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/batch"
+	. "github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	responsesconverter "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/openai/openai/responses"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	defaultBatchListLimit = 20
+	maxBatchListLimit     = 100
+	maxFileListLimit      = 10000
+)
+
+// openAIBatchEndpoints lists the request URLs an OpenAI batch may target.
+var openAIBatchEndpoints = []string{"/v1/chat/completions", "/v1/responses", "/v1/embeddings"}
+
+// OpenAIBatchAPIHandler serves the /v1/files and /v1/batches endpoints. Batches run in the
+// background through the same execution path as interactive requests.
+type OpenAIBatchAPIHandler struct {
+	*handlers.BaseAPIHandler
+	manager *batch.Manager
+}
+
+// NewOpenAIBatchAPIHandler creates the batch handler and registers its endpoints with the
+// shared batch manager.
+//
+// Parameters:
+//   - apiHandlers: The base API handlers instance
+//   - manager: The batch manager that stores files and schedules jobs
+//
+// Returns:
+//   - *OpenAIBatchAPIHandler: A new OpenAI batch API handlers instance
+func NewOpenAIBatchAPIHandler(apiHandlers *handlers.BaseAPIHandler, manager *batch.Manager) *OpenAIBatchAPIHandler {
+	h := &OpenAIBatchAPIHandler{BaseAPIHandler: apiHandlers, manager: manager}
+	for _, endpoint := range openAIBatchEndpoints {
+		manager.Handle(endpoint, func(ctx context.Context, owner string, body []byte) (int, []byte) {
+			return h.executeRequest(ctx, owner, endpoint, body)
+		})
+	}
+	return h
+}
+
+// HandlerType returns the identifier for this handler implementation.
+func (h *OpenAIBatchAPIHandler) HandlerType() string {
+	return OpenAI
+}
+
+// Models returns the OpenAI-compatible model metadata supported by this handler.
+func (h *OpenAIBatchAPIHandler) Models() []map[string]any {
+	return registry.GetGlobalRegistry().GetAvailableModels("openai")
+}
+
+// executeRequest runs one batch line as a non-streaming request under the batch owner's
+// API key, applying the same endpoint conversions as the interactive handlers. Lines of an
+// owner whose key has been removed fail.
+func (h *OpenAIBatchAPIHandler) executeRequest(ctx context.Context, owner, endpoint string, body []byte) (int, []byte) {
+	body, _ = sjson.DeleteBytes(body, "stream")
+	modelName := gjson.GetBytes(body, "model").String()
+	ctx = h.WithOwnerAPIKey(ctx, owner)
+
+	var resp []byte
+	var errMsg *interfaces.ErrorMessage
+	switch {
+	case !h.OwnerAPIKeyConfigured(owner):
+		errMsg = &interfaces.ErrorMessage{StatusCode: http.StatusUnauthorized, Error: handlers.ErrOwnerAPIKeyRemoved}
+	case endpoint == "/v1/chat/completions":
+		if responsesJSON, ok := chatRequestViaResponses(modelName, body, false); ok {
+			resp, errMsg = executeChatViaResponses(ctx, h.BaseAPIHandler, modelName, body, responsesJSON, "")
+			break
+		}
+		if shouldTreatAsResponsesFormat(body) {
+			body = responsesconverter.ConvertOpenAIResponsesRequestToOpenAIChatCompletions(modelName, body, false)
+		}
+		resp, errMsg = h.ExecuteWithAuthManager(ctx, OpenAI, modelName, body, "")
+	case endpoint == "/v1/responses":
+		if chatJSON, ok := responsesRequestViaChat(modelName, body, false); ok {
+			resp, errMsg = executeResponsesViaChat(ctx, h.BaseAPIHandler, modelName, body, chatJSON, "")
+			break
+		}
+		resp, errMsg = h.ExecuteWithAuthManager(ctx, OpenaiResponse, modelName, body, "")
+	case endpoint == "/v1/embeddings":
+		if errValidate := validateEmbeddingInput(body); errValidate != nil {
+			errMsg = &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("Invalid request: %v", errValidate)}
+			break
+		}
+		resp, errMsg = h.ExecuteEmbeddingWithAuthManager(ctx, OpenAIEmbeddings, modelName, body, "embeddings")
+	default:
+		errMsg = &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("unsupported endpoint %s", endpoint)}
+	}
+
+	if errMsg != nil {
+		status := errMsg.StatusCode
+		if status <= 0 {
+			status = http.StatusInternalServerError
+		}
+		errText := http.StatusText(status)
+		if errMsg.Error != nil {
+			errText = errMsg.Error.Error()
+		}
+		return status, handlers.BuildErrorResponseBody(status, errText)
+	}
+	return http.StatusOK, resp
+}
+
+// writeBatchError maps batch package errors onto OpenAI-style error responses.
+func writeBatchError(c *gin.Context, err error, notFound string) {
+	status := http.StatusInternalServerError
+	errType := "server_error"
+	message := err.Error()
+	switch {
+	case errors.Is(err, batch.ErrNotFound):
+		status, errType, message = http.StatusNotFound, "invalid_request_error", notFound
+	case errors.Is(err, batch.ErrInvalidRequest):
+		status, errType = http.StatusBadRequest, "invalid_request_error"
+	case errors.Is(err, batch.ErrFileTooLarge):
+		status, errType = http.StatusRequestEntityTooLarge, "invalid_request_error"
+	}
+	c.JSON(status, handlers.ErrorResponse{
+		Error: handlers.ErrorDetail{
+			Message: message,
+			Type:    errType,
+		},
+	})
+}
+
+func writeInvalidBatchRequest(c *gin.Context, format string, args ...any) {
+	c.JSON(http.StatusBadRequest, handlers.ErrorResponse{
+		Error: handlers.ErrorDetail{
+			Message: fmt.Sprintf(format, args...),
+			Type:    "invalid_request_error",
+		},
+	})
+}
+
+// listLimit parses the limit query parameter, falling back to def and capping at maxLimit.
+func listLimit(c *gin.Context, def, maxLimit int) int {
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 {
+		return min(v, maxLimit)
+	}
+	return def
+}
+
+// getFile returns the file with the given ID unless it belongs to a message batch or to
+// another API key.
+func (h *OpenAIBatchAPIHandler) getFile(c *gin.Context, id string) (*batch.File, error) {
+	file, err := h.manager.GetFile(id)
+	if err != nil {
+		return nil, err
+	}
+	if !file.VisibleTo(handlers.RequestOwner(c)) || file.Purpose == batch.PurposeMessageBatch || file.Purpose == batch.PurposeMessageBatchOutput {
+		return nil, batch.ErrNotFound
+	}
+	return file, nil
+}
+
+// getBatch returns the batch with the given ID unless it is a message batch or belongs to
+// another API key.
+func (h *OpenAIBatchAPIHandler) getBatch(c *gin.Context, id string) (*batch.Batch, error) {
+	b, err := h.manager.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !b.VisibleTo(handlers.RequestOwner(c)) || !slices.Contains(openAIBatchEndpoints, b.Endpoint) {
+		return nil, batch.ErrNotFound
+	}
+	return b, nil
+}
+
+// UploadFile handles POST /v1/files. It accepts a multipart form with "file" and "purpose".
+func (h *OpenAIBatchAPIHandler) UploadFile(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.manager.MaxFileSize()+1<<20)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			writeBatchError(c, batch.ErrFileTooLarge, "")
+			return
+		}
+		writeInvalidBatchRequest(c, "Invalid request: %v", err)
+		return
+	}
+	purpose := c.PostForm("purpose")
+	if purpose != batch.PurposeBatch {
+		writeInvalidBatchRequest(c, "Invalid request: purpose must be %q", batch.PurposeBatch)
+		return
+	}
+	src, err := header.Open()
+	if err != nil {
+		writeInvalidBatchRequest(c, "Invalid request: %v", err)
+		return
+	}
+	defer func() { _ = src.Close() }()
+	file, err := h.manager.CreateFile(handlers.RequestOwner(c), header.Filename, purpose, src)
+	if err != nil {
+		writeBatchError(c, err, "")
+		return
+	}
+	c.JSON(http.StatusOK, file)
+}
+
+// ListFiles handles GET /v1/files. It supports the purpose, limit and after query parameters.
+func (h *OpenAIBatchAPIHandler) ListFiles(c *gin.Context) {
+	files, err := h.manager.ListFiles(c.Query("purpose"))
+	if err != nil {
+		writeBatchError(c, err, "")
+		return
+	}
+	owner := handlers.RequestOwner(c)
+	files = slices.DeleteFunc(files, func(file *batch.File) bool {
+		return !file.VisibleTo(owner) || file.Purpose == batch.PurposeMessageBatch || file.Purpose == batch.PurposeMessageBatchOutput
+	})
+	if after := c.Query("after"); after != "" {
+		for i, file := range files {
+			if file.ID == after {
+				files = files[i+1:]
+				break
+			}
+		}
+	}
+	limit := listLimit(c, maxFileListLimit, maxFileListLimit)
+	hasMore := len(files) > limit
+	if hasMore {
+		files = files[:limit]
+	}
+	if files == nil {
+		files = []*batch.File{}
+	}
+	c.JSON(http.StatusOK, gin.H{"object": "list", "data": files, "has_more": hasMore})
+}
+
+// GetFile handles GET /v1/files/{id}.
+func (h *OpenAIBatchAPIHandler) GetFile(c *gin.Context) {
+	id := c.Param("id")
+	file, err := h.getFile(c, id)
+	if err != nil {
+		writeBatchError(c, err, fmt.Sprintf("No such File object: %s", id))
+		return
+	}
+	c.JSON(http.StatusOK, file)
+}
+
+// DeleteFile handles DELETE /v1/files/{id}.
+func (h *OpenAIBatchAPIHandler) DeleteFile(c *gin.Context) {
+	id := c.Param("id")
+	_, err := h.getFile(c, id)
+	if err == nil {
+		err = h.manager.DeleteFile(id)
+	}
+	if err != nil {
+		writeBatchError(c, err, fmt.Sprintf("No such File object: %s", id))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": id, "object": "file", "deleted": true})
+}
+
+// FileContent handles GET /v1/files/{id}/content.
+func (h *OpenAIBatchAPIHandler) FileContent(c *gin.Context) {
+	id := c.Param("id")
+	file, err := h.getFile(c, id)
+	if err != nil {
+		writeBatchError(c, err, fmt.Sprintf("No such File object: %s", id))
+		return
+	}
+	f, err := os.Open(h.manager.FilePath(file.ID))
+	if err != nil {
+		writeBatchError(c, err, "")
+		return
+	}
+	defer func() { _ = f.Close() }()
+	c.DataFromReader(http.StatusOK, file.Bytes, "application/octet-stream", f, nil)
+}
+
+// CreateBatch handles POST /v1/batches.
+func (h *OpenAIBatchAPIHandler) CreateBatch(c *gin.Context) {
+	var req struct {
+		InputFileID      string            `json:"input_file_id"`
+		Endpoint         string            `json:"endpoint"`
+		CompletionWindow string            `json:"completion_window"`
+		Metadata         map[string]string `json:"metadata"`
+	}
+	rawJSON, err := c.GetRawData()
+	if err == nil {
+		err = json.Unmarshal(rawJSON, &req)
+	}
+	if err != nil {
+		writeInvalidBatchRequest(c, "Invalid request: %v", err)
+		return
+	}
+	if req.InputFileID == "" || req.Endpoint == "" || req.CompletionWindow == "" {
+		writeInvalidBatchRequest(c, "Invalid request: input_file_id, endpoint and completion_window are required")
+		return
+	}
+	if !slices.Contains(openAIBatchEndpoints, req.Endpoint) {
+		writeInvalidBatchRequest(c, "Invalid request: unsupported endpoint %q", req.Endpoint)
+		return
+	}
+	file, errFile := h.getFile(c, req.InputFileID)
+	if errFile != nil {
+		if errors.Is(errFile, batch.ErrNotFound) {
+			writeInvalidBatchRequest(c, "Invalid request: input file %q not found", req.InputFileID)
+			return
+		}
+		writeBatchError(c, errFile, "")
+		return
+	}
+	if file.Purpose != batch.PurposeBatch {
+		writeInvalidBatchRequest(c, "Invalid request: input file must have purpose %q", batch.PurposeBatch)
+		return
+	}
+	created, err := h.manager.Create(batch.CreateParams{
+		InputFileID:      req.InputFileID,
+		Endpoint:         req.Endpoint,
+		CompletionWindow: req.CompletionWindow,
+		Metadata:         req.Metadata,
+		Owner:            handlers.RequestOwner(c),
+	})
+	if err != nil {
+		writeBatchError(c, err, "")
+		return
+	}
+	c.JSON(http.StatusOK, created)
+}
+
+// ListBatches handles GET /v1/batches. It supports the limit (1-100, default 20) and
+// after query parameters.
+func (h *OpenAIBatchAPIHandler) ListBatches(c *gin.Context) {
+	owner := handlers.RequestOwner(c)
+	batches := slices.DeleteFunc(h.manager.List(openAIBatchEndpoints...), func(b *batch.Batch) bool {
+		return !b.VisibleTo(owner)
+	})
+	if after := c.Query("after"); after != "" {
+		for i, b := range batches {
+			if b.ID == after {
+				batches = batches[i+1:]
+				break
+			}
+		}
+	}
+	limit := listLimit(c, defaultBatchListLimit, maxBatchListLimit)
+	hasMore := len(batches) > limit
+	if hasMore {
+		batches = batches[:limit]
+	}
+	var firstID, lastID any
+	if len(batches) > 0 {
+		firstID, lastID = batches[0].ID, batches[len(batches)-1].ID
+	}
+	c.JSON(http.StatusOK, gin.H{"object": "list", "data": batches, "first_id": firstID, "last_id": lastID, "has_more": hasMore})
+}
+
+// GetBatch handles GET /v1/batches/{id}.
+func (h *OpenAIBatchAPIHandler) GetBatch(c *gin.Context) {
+	id := c.Param("id")
+	b, err := h.getBatch(c, id)
+	if err != nil {
+		writeBatchError(c, err, fmt.Sprintf("No batch found with id '%s'.", id))
+		return
+	}
+	c.JSON(http.StatusOK, b)
+}
+
+// CancelBatch handles POST /v1/batches/{id}/cancel.
+func (h *OpenAIBatchAPIHandler) CancelBatch(c *gin.Context) {
+	id := c.Param("id")
+	_, err := h.getBatch(c, id)
+	var b *batch.Batch
+	if err == nil {
+		b, err = h.manager.Cancel(id)
+	}
+	if err != nil {
+		writeBatchError(c, err, fmt.Sprintf("No batch found with id '%s'.", id))
+		return
+	}
+	c.JSON(http.StatusOK, b)
+}
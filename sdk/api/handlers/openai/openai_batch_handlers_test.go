@@ -0,0 +1,193 @@
+package openai
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/batch"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestBatchEndpointsRunUploadedFile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager, err := batch.NewManager(t.TempDir(), batch.Options{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("new batch manager: %v", err)
+	}
+	h := NewOpenAIBatchAPIHandler(handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, nil), manager)
+	manager.Start()
+	defer manager.Close()
+
+	router := gin.New()
+	router.POST("/v1/files", h.UploadFile)
+	router.GET("/v1/files/:id/content", h.FileContent)
+	router.POST("/v1/batches", h.CreateBatch)
+	router.GET("/v1/batches", h.ListBatches)
+	router.GET("/v1/batches/:id", h.GetBatch)
+	do := func(req *http.Request) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	_ = writer.WriteField("purpose", "batch")
+	part, _ := writer.CreateFormFile("file", "input.jsonl")
+	_, _ = part.Write([]byte(`{"custom_id":"one","method":"POST","url":"/v1/embeddings","body":{"model":"unknown-embedding-model","input":"hi"}}` + "\n" +
+		`{"custom_id":"two","method":"POST","url":"/v1/embeddings","body":{"model":"unknown-embedding-model"}}` + "\n"))
+	_ = writer.Close()
+	upload := httptest.NewRequest(http.MethodPost, "/v1/files", &form)
+	upload.Header.Set("Content-Type", writer.FormDataContentType())
+	rr := do(upload)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("upload: %d %s", rr.Code, rr.Body.String())
+	}
+	fileID := gjson.Get(rr.Body.String(), "id").String()
+
+	rr = do(httptest.NewRequest(http.MethodPost, "/v1/batches", strings.NewReader(`{"input_file_id":"`+fileID+`","endpoint":"/v1/embeddings","completion_window":"24h"}`)))
+	if rr.Code != http.StatusOK || gjson.Get(rr.Body.String(), "status").String() != "validating" {
+		t.Fatalf("create: %d %s", rr.Code, rr.Body.String())
+	}
+	batchID := gjson.Get(rr.Body.String(), "id").String()
+
+	var body string
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		body = do(httptest.NewRequest(http.MethodGet, "/v1/batches/"+batchID, nil)).Body.String()
+		if gjson.Get(body, "status").String() == "completed" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if gjson.Get(body, "status").String() != "completed" || gjson.Get(body, "request_counts.failed").Int() != 2 {
+		t.Fatalf("unexpected batch: %s", body)
+	}
+
+	rr = do(httptest.NewRequest(http.MethodGet, "/v1/files/"+gjson.Get(body, "error_file_id").String()+"/content", nil))
+	lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+	if rr.Code != http.StatusOK || len(lines) != 2 {
+		t.Fatalf("error file: %d %s", rr.Code, rr.Body.String())
+	}
+	for _, line := range lines {
+		if code := gjson.Get(line, "response.status_code").Int(); code < 400 {
+			t.Fatalf("expected an error status in %s", line)
+		}
+		if gjson.Get(line, "response.body.error.message").String() == "" {
+			t.Fatalf("expected an OpenAI error body in %s", line)
+		}
+	}
+
+	rr = do(httptest.NewRequest(http.MethodGet, "/v1/batches?limit=1", nil))
+	if gjson.Get(rr.Body.String(), "data.0.id").String() != batchID {
+		t.Fatalf("list: %s", rr.Body.String())
+	}
+	if rr = do(httptest.NewRequest(http.MethodGet, "/v1/batches/batch_missing", nil)); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown batch, got %d", rr.Code)
+	}
+}
+
+func TestBatchEndpointsAreScopedToAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager, err := batch.NewManager(t.TempDir(), batch.Options{})
+	if err != nil {
+		t.Fatalf("new batch manager: %v", err)
+	}
+	h := NewOpenAIBatchAPIHandler(handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, nil), manager)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("apiKey", c.GetHeader("Authorization"))
+	})
+	router.POST("/v1/files", h.UploadFile)
+	router.GET("/v1/files", h.ListFiles)
+	router.GET("/v1/files/:id", h.GetFile)
+	router.DELETE("/v1/files/:id", h.DeleteFile)
+	router.GET("/v1/files/:id/content", h.FileContent)
+	router.POST("/v1/batches", h.CreateBatch)
+	router.GET("/v1/batches", h.ListBatches)
+	router.GET("/v1/batches/:id", h.GetBatch)
+	router.POST("/v1/batches/:id/cancel", h.CancelBatch)
+	do := func(method, path, key string, body *bytes.Buffer, contentType string) *httptest.ResponseRecorder {
+		if body == nil {
+			body = &bytes.Buffer{}
+		}
+		req := httptest.NewRequest(method, path, body)
+		req.Header.Set("Authorization", key)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	_ = writer.WriteField("purpose", "batch")
+	part, _ := writer.CreateFormFile("file", "input.jsonl")
+	_, _ = part.Write([]byte(`{"custom_id":"one","method":"POST","url":"/v1/embeddings","body":{"model":"m","input":"hi"}}` + "\n"))
+	_ = writer.Close()
+	rr := do(http.MethodPost, "/v1/files", "key-a", &form, writer.FormDataContentType())
+	fileID := gjson.Get(rr.Body.String(), "id").String()
+	if rr.Code != http.StatusOK || gjson.Get(rr.Body.String(), "owner").Exists() {
+		t.Fatalf("upload: %d %s", rr.Code, rr.Body.String())
+	}
+	createBody := `{"input_file_id":"` + fileID + `","endpoint":"/v1/embeddings","completion_window":"24h"}`
+	if rr = do(http.MethodPost, "/v1/batches", "key-b", bytes.NewBufferString(createBody), ""); rr.Code != http.StatusBadRequest {
+		t.Fatalf("create with another key's file: %d %s", rr.Code, rr.Body.String())
+	}
+	rr = do(http.MethodPost, "/v1/batches", "key-a", bytes.NewBufferString(createBody), "")
+	batchID := gjson.Get(rr.Body.String(), "id").String()
+	if rr.Code != http.StatusOK {
+		t.Fatalf("create: %d %s", rr.Code, rr.Body.String())
+	}
+
+	for _, probe := range []struct{ method, path string }{
+		{http.MethodGet, "/v1/files/" + fileID},
+		{http.MethodGet, "/v1/files/" + fileID + "/content"},
+		{http.MethodDelete, "/v1/files/" + fileID},
+		{http.MethodGet, "/v1/batches/" + batchID},
+		{http.MethodPost, "/v1/batches/" + batchID + "/cancel"},
+	} {
+		if rr = do(probe.method, probe.path, "key-b", nil, ""); rr.Code != http.StatusNotFound {
+			t.Fatalf("%s %s with another key: %d %s", probe.method, probe.path, rr.Code, rr.Body.String())
+		}
+	}
+	for _, path := range []string{"/v1/files", "/v1/batches"} {
+		if rr = do(http.MethodGet, path, "key-b", nil, ""); gjson.Get(rr.Body.String(), "data.#").Int() != 0 {
+			t.Fatalf("%s lists another key's objects: %s", path, rr.Body.String())
+		}
+		if rr = do(http.MethodGet, path, "key-a", nil, ""); gjson.Get(rr.Body.String(), "data.#").Int() != 1 {
+			t.Fatalf("%s hides the owner's objects: %s", path, rr.Body.String())
+		}
+	}
+	if rr = do(http.MethodGet, "/v1/batches/"+batchID, "key-a", nil, ""); rr.Code != http.StatusOK {
+		t.Fatalf("owner lost access: %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestBatchLinesFailOnceOwnerKeyIsRemoved(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager, err := batch.NewManager(t.TempDir(), batch.Options{})
+	if err != nil {
+		t.Fatalf("new batch manager: %v", err)
+	}
+	base := handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, nil)
+	h := NewOpenAIBatchAPIHandler(base, manager)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set("apiKey", "removed-key")
+
+	status, body := h.executeRequest(c, handlers.RequestOwner(c), "/v1/embeddings", []byte(`{"model":"m","input":"hi"}`))
+	if status != http.StatusUnauthorized || !strings.Contains(gjson.GetBytes(body, "error.message").String(), "no longer configured") {
+		t.Fatalf("status = %d, body %s", status, body)
+	}
+}
@@ -0,0 +1,354 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// defaultMaxChoices bounds n when max-choices is not configured.
+const defaultMaxChoices = 8
+
+// Most providers have no native equivalent of the n parameter and their translators drop
+// it. Chat completion requests with n > 1 therefore run as n parallel single-choice
+// executions whose choices are merged by index, with usage summed across executions.
+
+// requestedChoices returns the n parameter of a chat completion request, validated
+// against the configured maximum.
+func (h *OpenAIAPIHandler) requestedChoices(rawJSON []byte) (int, error) {
+	n := gjson.GetBytes(rawJSON, "n")
+	if !n.Exists() || n.Type == gjson.Null {
+		return 1, nil
+	}
+	if n.Type != gjson.Number || n.Int() < 1 || float64(n.Int()) != n.Float() {
+		return 0, fmt.Errorf("n must be a positive integer")
+	}
+	maxChoices := defaultMaxChoices
+	if h.Cfg != nil && h.Cfg.MaxChoices > 0 {
+		maxChoices = h.Cfg.MaxChoices
+	}
+	if n.Int() > int64(maxChoices) {
+		return 0, fmt.Errorf("n must be an integer between 1 and %d", maxChoices)
+	}
+	return int(n.Int()), nil
+}
+
+// handleFanOutNonStreaming runs n executions in parallel and merges them into one chat
+// completion. The first failure cancels the remaining executions and is returned.
+func (h *OpenAIAPIHandler) handleFanOutNonStreaming(c *gin.Context, rawJSON []byte, n int) {
+	c.Header("Content-Type", "application/json")
+
+	modelName := gjson.GetBytes(rawJSON, "model").String()
+	single, _ := sjson.DeleteBytes(rawJSON, "n")
+	alt := h.GetAlt(c)
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
+	var estimate handlers.TokenEstimate
+	responses, errMsg := executeParallel(handlers.WithTokenEstimate(cliCtx, &estimate), n, func(ctx context.Context) ([]byte, *interfaces.ErrorMessage) {
+		return h.ExecuteWithAuthManager(ctx, h.HandlerType(), modelName, single, alt)
+	})
+	estimate.WriteHeader(c)
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+		return
+	}
+	merged := mergeChatCompletions(responses)
+	_, _ = c.Writer.Write(merged)
+	cliCancel()
+}
+
+// executeParallel runs execute n times concurrently and returns the responses in order.
+// The first failure cancels the remaining executions and is returned. Callers pass a
+// context carrying a handlers.TokenEstimate, since the executions share one response.
+func executeParallel(ctx context.Context, n int, execute func(context.Context) ([]byte, *interfaces.ErrorMessage)) ([][]byte, *interfaces.ErrorMessage) {
+	runCtx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	responses := make([][]byte, n)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr *interfaces.ErrorMessage
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, errMsg := execute(runCtx)
+			if errMsg != nil {
+				errOnce.Do(func() {
+					firstErr = errMsg
+					stop()
+				})
+				return
+			}
+			responses[i] = resp
+		}(i)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return responses, nil
+}
+
+// mergeChatCompletions combines single-choice chat completions into one response. The
+// first response supplies the envelope; choices are re-indexed in execution order.
+func mergeChatCompletions(responses [][]byte) []byte {
+	out := responses[0]
+	out, _ = sjson.SetRawBytes(out, "choices", []byte(`[]`))
+	usage := []byte(`{}`)
+	hasUsage := false
+	for i, resp := range responses {
+		for _, choice := range gjson.GetBytes(resp, "choices").Array() {
+			indexed, _ := sjson.SetBytes([]byte(choice.Raw), "index", i)
+			out, _ = sjson.SetRawBytes(out, "choices.-1", indexed)
+		}
+		if u := gjson.GetBytes(resp, "usage"); u.IsObject() {
+			usage = addUsage(usage, u)
+			hasUsage = true
+		}
+	}
+	if hasUsage {
+		out, _ = sjson.SetRawBytes(out, "usage", usage)
+	}
+	return out
+}
+
+// addUsage adds every numeric field of u, including nested detail objects, into sum.
+func addUsage(sum []byte, u gjson.Result) []byte {
+	var walk func(prefix string, value gjson.Result)
+	walk = func(prefix string, value gjson.Result) {
+		value.ForEach(func(key, field gjson.Result) bool {
+			path := prefix + key.String()
+			switch {
+			case field.IsObject():
+				walk(path+".", field)
+			case field.Type == gjson.Number:
+				sum, _ = sjson.SetBytes(sum, path, gjson.GetBytes(sum, path).Int()+field.Int())
+			}
+			return true
+		})
+	}
+	walk("", u)
+	return sum
+}
+
+// choiceStream is one of the parallel executions of a fanned-out streaming request.
+type choiceStream struct {
+	data  <-chan []byte
+	errs  <-chan *interfaces.ErrorMessage
+	first []byte
+}
+
+// handleFanOutStreaming starts n streaming executions and interleaves their chunks as they
+// arrive, rewriting each choice index to the execution number. Usage chunks are summed
+// and emitted once before [DONE]. Failures before the first chunk of every execution are
+// returned as an error response.
+func (h *OpenAIAPIHandler) handleFanOutStreaming(c *gin.Context, rawJSON []byte, n int) {
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, handlers.ErrorResponse{
+			Error: handlers.ErrorDetail{
+				Message: "Streaming not supported",
+				Type:    "server_error",
+			},
+		})
+		return
+	}
+
+	modelName := gjson.GetBytes(rawJSON, "model").String()
+	single, _ := sjson.DeleteBytes(rawJSON, "n")
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
+
+	streams := make([]*choiceStream, n)
+	for i := range streams {
+		data, errs := h.ExecuteStreamWithAuthManager(cliCtx, h.HandlerType(), modelName, single, h.GetAlt(c))
+		streams[i] = &choiceStream{data: data, errs: errs}
+	}
+	for _, s := range streams {
+		if errMsg, aborted := s.bootstrap(c); errMsg != nil || aborted {
+			if aborted {
+				cliCancel(c.Request.Context().Err())
+				return
+			}
+			h.WriteErrorResponse(c, errMsg)
+			cliCancel(errMsg.Error)
+			return
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	merger := &chunkMerger{}
+	data := make(chan []byte)
+	errs := make(chan *interfaces.ErrorMessage, 1)
+	var wg sync.WaitGroup
+	for i, s := range streams {
+		wg.Add(1)
+		go func(i int, s *choiceStream) {
+			defer wg.Done()
+			forward := func(chunk []byte) bool {
+				if rewritten := merger.rewrite(i, chunk); rewritten != nil {
+					select {
+					case data <- rewritten:
+					case <-cliCtx.Done():
+						return false
+					}
+				}
+				return true
+			}
+			if s.first != nil && !forward(s.first) {
+				return
+			}
+			dataCh, errCh := s.data, s.errs
+			for dataCh != nil || errCh != nil {
+				select {
+				case chunk, ok := <-dataCh:
+					if !ok {
+						dataCh = nil
+						continue
+					}
+					if !forward(chunk) {
+						return
+					}
+				case errMsg, ok := <-errCh:
+					if !ok {
+						errCh = nil
+						continue
+					}
+					if errMsg != nil {
+						select {
+						case errs <- errMsg:
+						default:
+						}
+						return
+					}
+				}
+			}
+		}(i, s)
+	}
+	go func() {
+		wg.Wait()
+		close(data)
+	}()
+
+	h.ForwardStream(c, flusher, func(err error) { cliCancel(err) }, data, errs, handlers.StreamForwardOptions{
+		WriteChunk: func(chunk []byte) {
+			_, _ = fmt.Fprintf(c.Writer, "data: %s\n\n", string(chunk))
+		},
+		WriteTerminalError: func(errMsg *interfaces.ErrorMessage) {
+			if errMsg == nil {
+				return
+			}
+			status := http.StatusInternalServerError
+			if errMsg.StatusCode > 0 {
+				status = errMsg.StatusCode
+			}
+			errText := http.StatusText(status)
+			if errMsg.Error != nil && errMsg.Error.Error() != "" {
+				errText = errMsg.Error.Error()
+			}
+			_, _ = fmt.Fprintf(c.Writer, "data: %s\n\n", string(handlers.BuildErrorResponseBody(status, errText)))
+		},
+		WriteDone: func() {
+			if usage := merger.usageChunk(); usage != nil {
+				_, _ = fmt.Fprintf(c.Writer, "data: %s\n\n", string(usage))
+			}
+			_, _ = fmt.Fprint(c.Writer, "data: [DONE]\n\n")
+		},
+	})
+}
+
+// bootstrap waits for the first chunk or error of the stream. It reports aborted when the
+// client went away first.
+func (s *choiceStream) bootstrap(c *gin.Context) (*interfaces.ErrorMessage, bool) {
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return nil, true
+		case errMsg, ok := <-s.errs:
+			if !ok {
+				s.errs = nil
+				continue
+			}
+			if errMsg == nil {
+				errMsg = &interfaces.ErrorMessage{StatusCode: http.StatusInternalServerError, Error: fmt.Errorf("stream failed")}
+			}
+			return errMsg, false
+		case chunk, ok := <-s.data:
+			if !ok {
+				s.data = nil
+				return nil, false
+			}
+			s.first = chunk
+			return nil, false
+		}
+	}
+}
+
+// chunkMerger rewrites chunks of parallel streams into one stream with a shared ID.
+type chunkMerger struct {
+	mu       sync.Mutex
+	id       string
+	envelope []byte
+	usage    []byte
+}
+
+// rewrite sets the shared chunk ID and the choice index of a chunk and strips its usage,
+// which is accumulated instead. It returns nil when nothing is left to send.
+func (m *chunkMerger) rewrite(index int, chunk []byte) []byte {
+	if !gjson.ValidBytes(chunk) {
+		return chunk
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.id == "" {
+		m.id = gjson.GetBytes(chunk, "id").String()
+		m.envelope = chunk
+	}
+	if u := gjson.GetBytes(chunk, "usage"); u.IsObject() {
+		if m.usage == nil {
+			m.usage = []byte(`{}`)
+		}
+		m.usage = addUsage(m.usage, u)
+		chunk, _ = sjson.DeleteBytes(chunk, "usage")
+	}
+	choices := gjson.GetBytes(chunk, "choices").Array()
+	if len(choices) == 0 {
+		return nil
+	}
+	for j := range choices {
+		chunk, _ = sjson.SetBytes(chunk, fmt.Sprintf("choices.%d.index", j), index)
+	}
+	if m.id != "" {
+		chunk, _ = sjson.SetBytes(chunk, "id", m.id)
+	}
+	return chunk
+}
+
+// usageChunk returns a final chunk carrying the summed usage, or nil when no stream
+// reported usage.
+func (m *chunkMerger) usageChunk() []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.usage == nil {
+		return nil
+	}
+	out := []byte(`{"object":"chat.completion.chunk","choices":[]}`)
+	out, _ = sjson.SetBytes(out, "id", m.id)
+	out, _ = sjson.SetBytes(out, "created", gjson.GetBytes(m.envelope, "created").Int())
+	out, _ = sjson.SetBytes(out, "model", gjson.GetBytes(m.envelope, "model").String())
+	out, _ = sjson.SetRawBytes(out, "usage", m.usage)
+	return out
+}
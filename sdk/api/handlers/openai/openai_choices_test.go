@@ -0,0 +1,212 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+// choiceExecutor answers every request with a single choice numbered by call order.
+type choiceExecutor struct {
+	calls atomic.Int64
+	withN atomic.Int64
+}
+
+func (e *choiceExecutor) Identifier() string { return "fanout-test" }
+
+func (e *choiceExecutor) Execute(_ context.Context, _ *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (coreexecutor.Response, error) {
+	call := e.calls.Add(1)
+	if gjson.GetBytes(req.Payload, "n").Exists() {
+		e.withN.Add(1)
+	}
+	return coreexecutor.Response{Payload: []byte(fmt.Sprintf(`{"id":"chatcmpl-%d","object":"chat.completion","model":"fanout-model","choices":[{"index":0,"message":{"role":"assistant","content":"answer %d"},"finish_reason":"stop"}],"usage":{"prompt_tokens":10,"completion_tokens":2,"total_tokens":12,"completion_tokens_details":{"reasoning_tokens":1}}}`, call, call))}, nil
+}
+
+func (e *choiceExecutor) ExecuteStream(_ context.Context, _ *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (<-chan coreexecutor.StreamChunk, error) {
+	call := e.calls.Add(1)
+	ch := make(chan coreexecutor.StreamChunk, 3)
+	ch <- coreexecutor.StreamChunk{Payload: []byte(fmt.Sprintf(`{"id":"chatcmpl-%d","object":"chat.completion.chunk","created":1,"model":"fanout-model","choices":[{"index":0,"delta":{"content":"answer %d"}}]}`, call, call))}
+	ch <- coreexecutor.StreamChunk{Payload: []byte(fmt.Sprintf(`{"id":"chatcmpl-%d","object":"chat.completion.chunk","created":1,"model":"fanout-model","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`, call))}
+	ch <- coreexecutor.StreamChunk{Payload: []byte(fmt.Sprintf(`{"id":"chatcmpl-%d","object":"chat.completion.chunk","created":1,"model":"fanout-model","choices":[],"usage":{"prompt_tokens":10,"completion_tokens":2,"total_tokens":12}}`, call))}
+	close(ch)
+	return ch, nil
+}
+
+func (e *choiceExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *choiceExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, &coreauth.Error{Code: "not_implemented", Message: "CountTokens not implemented"}
+}
+
+func (e *choiceExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "HttpRequest not implemented", HTTPStatus: http.StatusNotImplemented}
+}
+
+func newFanOutRouter(t *testing.T, cfg *sdkconfig.SDKConfig) (*gin.Engine, *choiceExecutor) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	executor := &choiceExecutor{}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	for _, id := range []string{"fanout-auth-1", "fanout-auth-2"} {
+		auth := &coreauth.Auth{ID: id, Provider: "fanout-test", Status: coreauth.StatusActive}
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register auth: %v", err)
+		}
+		registry.GetGlobalRegistry().RegisterClient(id, auth.Provider, []*registry.ModelInfo{{ID: "fanout-model"}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+	}
+	h := NewOpenAIAPIHandler(handlers.NewBaseAPIHandlers(cfg, manager))
+	router := gin.New()
+	router.POST("/v1/chat/completions", h.ChatCompletions)
+	return router, executor
+}
+
+func TestChatCompletionsFanOutNonStreaming(t *testing.T) {
+	router, executor := newFanOutRouter(t, &sdkconfig.SDKConfig{})
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"fanout-model","n":3,"messages":[{"role":"user","content":"hi"}]}`)))
+	body := rr.Body.String()
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rr.Code, body)
+	}
+	if executor.calls.Load() != 3 || executor.withN.Load() != 0 {
+		t.Fatalf("expected 3 single-choice executions, got %d calls (%d with n)", executor.calls.Load(), executor.withN.Load())
+	}
+	choices := gjson.Get(body, "choices").Array()
+	if len(choices) != 3 {
+		t.Fatalf("expected 3 choices: %s", body)
+	}
+	for i, choice := range choices {
+		if choice.Get("index").Int() != int64(i) {
+			t.Fatalf("choice %d has index %d", i, choice.Get("index").Int())
+		}
+	}
+	if gjson.Get(body, "usage.total_tokens").Int() != 36 || gjson.Get(body, "usage.completion_tokens_details.reasoning_tokens").Int() != 3 {
+		t.Fatalf("usage not summed: %s", body)
+	}
+}
+
+func TestChatCompletionsFanOutStreaming(t *testing.T) {
+	router, _ := newFanOutRouter(t, &sdkconfig.SDKConfig{})
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"fanout-model","n":2,"stream":true,"messages":[{"role":"user","content":"hi"}]}`)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var events []string
+	for _, line := range strings.Split(rr.Body.String(), "\n") {
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			events = append(events, data)
+		}
+	}
+	if len(events) < 2 || events[len(events)-1] != "[DONE]" {
+		t.Fatalf("unexpected events: %v", events)
+	}
+	usage := gjson.Get(events[len(events)-2], "usage")
+	if usage.Get("total_tokens").Int() != 24 {
+		t.Fatalf("expected one summed usage chunk before [DONE], got %s", events[len(events)-2])
+	}
+	id := gjson.Get(events[0], "id").String()
+	finished := map[int64]bool{}
+	for _, event := range events[:len(events)-2] {
+		if gjson.Get(event, "usage").Exists() {
+			t.Fatalf("per-execution usage should be merged: %s", event)
+		}
+		if gjson.Get(event, "id").String() != id {
+			t.Fatalf("chunks should share one id: %s", event)
+		}
+		if choice := gjson.Get(event, "choices.0"); choice.Get("finish_reason").String() == "stop" {
+			finished[choice.Get("index").Int()] = true
+		}
+	}
+	if !finished[0] || !finished[1] {
+		t.Fatalf("expected both choices to finish, got %v", finished)
+	}
+}
+
+func TestChatCompletionsRejectsTooManyChoices(t *testing.T) {
+	router, executor := newFanOutRouter(t, &sdkconfig.SDKConfig{MaxChoices: 2})
+	for _, body := range []string{
+		`{"model":"fanout-model","n":3,"messages":[]}`,
+		`{"model":"fanout-model","n":0,"messages":[]}`,
+		`{"model":"fanout-model","n":1.5,"messages":[]}`,
+	} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body)))
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", body, rr.Code)
+		}
+	}
+	if executor.calls.Load() != 0 {
+		t.Fatalf("rejected requests must not reach providers")
+	}
+}
+
+func TestChatCompletionsChoicesBoundedByDefault(t *testing.T) {
+	router, executor := newFanOutRouter(t, &sdkconfig.SDKConfig{})
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"fanout-model","n":9,"messages":[]}`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for n above the default bound, got %d %s", rr.Code, rr.Body.String())
+	}
+	if executor.calls.Load() != 0 {
+		t.Fatalf("rejected requests must not reach providers")
+	}
+
+	router, executor = newFanOutRouter(t, &sdkconfig.SDKConfig{MaxChoices: 12})
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"fanout-model","n":12,"messages":[]}`)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected n=12 to be served with max-choices 12, got %d %s", rr.Code, rr.Body.String())
+	}
+	if got := len(gjson.Get(rr.Body.String(), "choices").Array()); got != 12 || executor.calls.Load() != 12 {
+		t.Fatalf("choices = %d, executions = %d, want 12", got, executor.calls.Load())
+	}
+}
+
+func TestChatCompletionsViaResponsesRejectsChoices(t *testing.T) {
+	router, executor := newFanOutRouter(t, &sdkconfig.SDKConfig{})
+	registry.GetGlobalRegistry().RegisterClient("fanout-responses-auth", "fanout-test", []*registry.ModelInfo{{ID: "fanout-responses-model", SupportedEndpoints: []string{"/responses"}}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient("fanout-responses-auth") })
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"fanout-responses-model","n":2,"messages":[{"role":"user","content":"hi"}]}`)))
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "Responses API") {
+		t.Fatalf("expected 400 for n > 1 through the Responses API, got %d %s", rr.Code, rr.Body.String())
+	}
+	if executor.calls.Load() != 0 {
+		t.Fatalf("rejected requests must not reach providers")
+	}
+}
+
+func TestChatCompletionsFanOutWithContextGuard(t *testing.T) {
+	cfg := &sdkconfig.SDKConfig{ContextGuard: sdkconfig.ContextGuardConfig{Enabled: true}}
+	router, executor := newFanOutRouter(t, cfg)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"fanout-model","n":8,"messages":[{"role":"user","content":"hi"}]}`)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rr.Code, rr.Body.String())
+	}
+	if executor.calls.Load() != 8 {
+		t.Fatalf("expected 8 executions, got %d", executor.calls.Load())
+	}
+	if rr.Header().Get(handlers.EstimatedTokensHeader) == "" {
+		t.Fatalf("missing %s header", handlers.EstimatedTokensHeader)
+	}
+}
@@ -0,0 +1,107 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	"github.com/tidwall/gjson"
+)
+
+// OpenAIEmbeddingsAPIHandler contains the handlers for the OpenAI embeddings endpoint.
+type OpenAIEmbeddingsAPIHandler struct {
+	*handlers.BaseAPIHandler
+}
+
+// NewOpenAIEmbeddingsAPIHandler creates a new OpenAI embeddings API handlers instance.
+//
+// Parameters:
+//   - apiHandlers: The base API handlers instance
+//
+// Returns:
+//   - *OpenAIEmbeddingsAPIHandler: A new OpenAI embeddings API handlers instance
+func NewOpenAIEmbeddingsAPIHandler(apiHandlers *handlers.BaseAPIHandler) *OpenAIEmbeddingsAPIHandler {
+	return &OpenAIEmbeddingsAPIHandler{
+		BaseAPIHandler: apiHandlers,
+	}
+}
+
+// HandlerType returns the identifier for this handler implementation.
+func (h *OpenAIEmbeddingsAPIHandler) HandlerType() string {
+	return OpenAIEmbeddings
+}
+
+// Models returns the OpenAI-compatible model metadata supported by this handler.
+func (h *OpenAIEmbeddingsAPIHandler) Models() []map[string]any {
+	return registry.GetGlobalRegistry().GetAvailableModels("openai")
+}
+
+// Embeddings handles the /v1/embeddings endpoint.
+// The request is routed to the providers serving the requested embedding model and the
+// result is returned in OpenAI embeddings format.
+//
+// Parameters:
+//   - c: The Gin context containing the HTTP request and response
+func (h *OpenAIEmbeddingsAPIHandler) Embeddings(c *gin.Context) {
+	rawJSON, err := c.GetRawData()
+	// If data retrieval fails, return a 400 Bad Request error.
+	if err != nil {
+		c.JSON(http.StatusBadRequest, handlers.ErrorResponse{
+			Error: handlers.ErrorDetail{
+				Message: fmt.Sprintf("Invalid request: %v", err),
+				Type:    "invalid_request_error",
+			},
+		})
+		return
+	}
+	if errValidate := validateEmbeddingInput(rawJSON); errValidate != nil {
+		c.JSON(http.StatusBadRequest, handlers.ErrorResponse{
+			Error: handlers.ErrorDetail{
+				Message: fmt.Sprintf("Invalid request: %v", errValidate),
+				Type:    "invalid_request_error",
+			},
+		})
+		return
+	}
+
+	c.Header("Content-Type", "application/json")
+	modelName := gjson.GetBytes(rawJSON, "model").String()
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
+	resp, errMsg := h.ExecuteEmbeddingWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, "embeddings")
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+		return
+	}
+	_, _ = c.Writer.Write(resp)
+	cliCancel()
+}
+
+// validateEmbeddingInput checks that the request names a model and carries a non-empty
+// string or array input.
+func validateEmbeddingInput(rawJSON []byte) error {
+	if !gjson.ValidBytes(rawJSON) {
+		return fmt.Errorf("body must be valid JSON")
+	}
+	if gjson.GetBytes(rawJSON, "model").String() == "" {
+		return fmt.Errorf("model is required")
+	}
+	input := gjson.GetBytes(rawJSON, "input")
+	switch {
+	case input.Type == gjson.String:
+		if input.String() == "" {
+			return fmt.Errorf("input must not be empty")
+		}
+	case input.IsArray():
+		if len(input.Array()) == 0 {
+			return fmt.Errorf("input must not be empty")
+		}
+	default:
+		return fmt.Errorf("input must be a string or an array")
+	}
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 
 	"github.com/gin-gonic/gin"
@@ -58,30 +59,23 @@ func (h *OpenAIAPIHandler) Models() []map[string]any {
 
 // OpenAIModels handles the /v1/models endpoint.
 // It returns a list of available AI models with their capabilities
-// and specifications in OpenAI-compatible format.
+// and specifications in OpenAI-compatible format. With ?extended=true every
+// model carries its full metadata and a capabilities object.
 func (h *OpenAIAPIHandler) OpenAIModels(c *gin.Context) {
+	if h.ExtendedModels(c) {
+		c.JSON(http.StatusOK, gin.H{
+			"object": "list",
+			"data":   registry.GetGlobalRegistry().GetAvailableModelsExtended("openai"),
+		})
+		return
+	}
+
 	// Get all available models
 	allModels := h.Models()
 
-	// Filter to only include the 4 required fields: id, object, created, owned_by
 	filteredModels := make([]map[string]any, len(allModels))
 	for i, model := range allModels {
-		filteredModel := map[string]any{
-			"id":     model["id"],
-			"object": model["object"],
-		}
-
-		// Add created field if it exists
-		if created, exists := model["created"]; exists {
-			filteredModel["created"] = created
-		}
-
-		// Add owned_by field if it exists
-		if ownedBy, exists := model["owned_by"]; exists {
-			filteredModel["owned_by"] = ownedBy
-		}
-
-		filteredModels[i] = filteredModel
+		filteredModels[i] = openAIModelFields(model)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -90,6 +84,48 @@ func (h *OpenAIAPIHandler) OpenAIModels(c *gin.Context) {
 	})
 }
 
+// OpenAIModel handles GET /v1/models/{model}, returning a single model object in the
+// same shape as the listing, or a model_not_found error.
+func (h *OpenAIAPIHandler) OpenAIModel(c *gin.Context) {
+	modelID := strings.TrimPrefix(c.Param("model"), "/")
+	extended := h.ExtendedModels(c)
+	model := registry.GetGlobalRegistry().GetAvailableModel(modelID, "openai", extended)
+	if model == nil {
+		c.JSON(http.StatusNotFound, handlers.ErrorResponse{
+			Error: handlers.ErrorDetail{
+				Message: fmt.Sprintf("The model '%s' does not exist or you do not have access to it.", modelID),
+				Type:    "invalid_request_error",
+				Code:    "model_not_found",
+			},
+		})
+		return
+	}
+	if !extended {
+		model = openAIModelFields(model)
+	}
+	c.JSON(http.StatusOK, model)
+}
+
+// openAIModelFields keeps only the fields of the OpenAI model object: id, object,
+// created and owned_by.
+func openAIModelFields(model map[string]any) map[string]any {
+	filteredModel := map[string]any{
+		"id":     model["id"],
+		"object": model["object"],
+	}
+
+	// Add created field if it exists
+	if created, exists := model["created"]; exists {
+		filteredModel["created"] = created
+	}
+
+	// Add owned_by field if it exists
+	if ownedBy, exists := model["owned_by"]; exists {
+		filteredModel["owned_by"] = ownedBy
+	}
+	return filteredModel
+}
+
 // ChatCompletions handles the /v1/chat/completions endpoint.
 // It determines whether the request is for a streaming or non-streaming response
 // and calls the appropriate handler based on the model provider.
@@ -113,14 +149,31 @@ func (h *OpenAIAPIHandler) ChatCompletions(c *gin.Context) {
 	streamResult := gjson.GetBytes(rawJSON, "stream")
 	stream := streamResult.Type == gjson.True
 
+	n, errChoices := h.requestedChoices(rawJSON)
+	if errChoices != nil {
+		c.JSON(http.StatusBadRequest, handlers.ErrorResponse{
+			Error: handlers.ErrorDetail{
+				Message: fmt.Sprintf("Invalid request: %v", errChoices),
+				Type:    "invalid_request_error",
+			},
+		})
+		return
+	}
+
 	modelName := gjson.GetBytes(rawJSON, "model").String()
-	if overrideEndpoint, ok := resolveEndpointOverride(modelName, openAIChatEndpoint); ok && overrideEndpoint == openAIResponsesEndpoint {
-		originalChat := rawJSON
-		if shouldTreatAsResponsesFormat(rawJSON) {
-			// Already responses-style payload; no conversion needed.
-		} else {
-			rawJSON = codexconverter.ConvertOpenAIRequestToCodex(modelName, rawJSON, stream)
+	if responsesJSON, ok := chatRequestViaResponses(modelName, rawJSON, stream); ok {
+		// The Responses API has no n, and this path does not fan out.
+		if n > 1 {
+			c.JSON(http.StatusBadRequest, handlers.ErrorResponse{
+				Error: handlers.ErrorDetail{
+					Message: fmt.Sprintf("Invalid request: n > 1 is not supported for model %s, which is served through the Responses API", modelName),
+					Type:    "invalid_request_error",
+				},
+			})
+			return
 		}
+		originalChat := rawJSON
+		rawJSON = responsesJSON
 		stream = gjson.GetBytes(rawJSON, "stream").Bool()
 		if stream {
 			h.handleStreamingResponseViaResponses(c, rawJSON, originalChat)
@@ -138,9 +191,21 @@ func (h *OpenAIAPIHandler) ChatCompletions(c *gin.Context) {
 		stream = gjson.GetBytes(rawJSON, "stream").Bool()
 	}
 
-	if stream {
+	so, errFormat := h.structuredOutputFor(rawJSON)
+	if errFormat != nil {
+		invalidResponseFormat(c, errFormat)
+		return
+	}
+	switch {
+	case so != nil:
+		h.handleStructuredOutput(c, rawJSON, so, n, stream)
+	case n > 1 && stream:
+		h.handleFanOutStreaming(c, rawJSON, n)
+	case n > 1:
+		h.handleFanOutNonStreaming(c, rawJSON, n)
+	case stream:
 		h.handleStreamingResponse(c, rawJSON)
-	} else {
+	default:
 		h.handleNonStreamingResponse(c, rawJSON)
 	}
 
@@ -528,21 +593,12 @@ func (h *OpenAIAPIHandler) handleNonStreamingResponseViaResponses(c *gin.Context
 
 	modelName := gjson.GetBytes(rawJSON, "model").String()
 	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
-	resp, errMsg := h.ExecuteWithAuthManager(cliCtx, OpenaiResponse, modelName, rawJSON, h.GetAlt(c))
+	converted, errMsg := executeChatViaResponses(cliCtx, h.BaseAPIHandler, modelName, originalChatJSON, rawJSON, h.GetAlt(c))
 	if errMsg != nil {
 		h.WriteErrorResponse(c, errMsg)
 		cliCancel(errMsg.Error)
 		return
 	}
-	converted := convertResponsesObjectToChatCompletion(cliCtx, modelName, originalChatJSON, rawJSON, resp)
-	if converted == nil {
-		h.WriteErrorResponse(c, &interfaces.ErrorMessage{
-			StatusCode: http.StatusInternalServerError,
-			Error:      fmt.Errorf("failed to convert response to chat completion format"),
-		})
-		cliCancel(fmt.Errorf("response conversion failed"))
-		return
-	}
 	_, _ = c.Writer.Write(converted)
 	cliCancel()
 }
@@ -0,0 +1,502 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	. "github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	maxImagesPerRequest = 10
+	// maxImageEditFormBytes bounds the multipart body of /v1/images/edits.
+	maxImageEditFormBytes = 64 << 20
+	imageURLTTL           = time.Hour
+	maxCachedImages       = 64
+)
+
+// geminiAspectRatios lists the aspect ratios accepted by Gemini image models.
+var geminiAspectRatios = []string{"1:1", "2:3", "3:2", "3:4", "4:3", "4:5", "5:4", "9:16", "16:9", "21:9"}
+
+// OpenAIImagesAPIHandler contains the handlers for the OpenAI images endpoints.
+// Requests are translated into Gemini generateContent calls with image output, so they
+// are served by the Gemini, Vertex and Antigravity providers of image-capable models.
+type OpenAIImagesAPIHandler struct {
+	*handlers.BaseAPIHandler
+	images *imageCache
+}
+
+// NewOpenAIImagesAPIHandler creates a new OpenAI images API handlers instance.
+//
+// Parameters:
+//   - apiHandlers: The base API handlers instance
+//
+// Returns:
+//   - *OpenAIImagesAPIHandler: A new OpenAI images API handlers instance
+func NewOpenAIImagesAPIHandler(apiHandlers *handlers.BaseAPIHandler) *OpenAIImagesAPIHandler {
+	return &OpenAIImagesAPIHandler{
+		BaseAPIHandler: apiHandlers,
+		images:         newImageCache(imageURLTTL, maxCachedImages),
+	}
+}
+
+// HandlerType returns the identifier for this handler implementation.
+func (h *OpenAIImagesAPIHandler) HandlerType() string {
+	return OpenAI
+}
+
+// Models returns the OpenAI-compatible model metadata supported by this handler.
+func (h *OpenAIImagesAPIHandler) Models() []map[string]any {
+	return registry.GetGlobalRegistry().GetAvailableModels("openai")
+}
+
+// imageRequest is the provider-independent form of an images generation or edit request.
+type imageRequest struct {
+	Model          string
+	Prompt         string
+	N              int
+	AspectRatio    string
+	ResponseFormat string
+	Images         []inlineImage
+	Mask           *inlineImage
+}
+
+// inlineImage is an image sent to or returned by the model.
+type inlineImage struct {
+	MIMEType string
+	// Data is the base64 encoded image content.
+	Data string
+}
+
+// imageUsage accumulates token usage over every generation call of a request.
+type imageUsage struct {
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
+	TotalTokens  int64 `json:"total_tokens"`
+}
+
+// ImageGenerations handles the /v1/images/generations endpoint.
+//
+// Parameters:
+//   - c: The Gin context containing the HTTP request and response
+func (h *OpenAIImagesAPIHandler) ImageGenerations(c *gin.Context) {
+	rawJSON, err := c.GetRawData()
+	if err != nil {
+		writeInvalidImageRequest(c, err)
+		return
+	}
+	if !gjson.ValidBytes(rawJSON) {
+		writeInvalidImageRequest(c, fmt.Errorf("body must be valid JSON"))
+		return
+	}
+	n := 1
+	if v := gjson.GetBytes(rawJSON, "n"); v.Exists() {
+		n = int(v.Int())
+	}
+	req, err := newImageRequest(
+		gjson.GetBytes(rawJSON, "model").String(),
+		gjson.GetBytes(rawJSON, "prompt").String(),
+		n,
+		gjson.GetBytes(rawJSON, "size").String(),
+		gjson.GetBytes(rawJSON, "response_format").String(),
+	)
+	if err != nil {
+		writeInvalidImageRequest(c, err)
+		return
+	}
+	h.generate(c, req)
+}
+
+// ImageEdits handles the /v1/images/edits endpoint. It accepts a multipart form with one
+// or more "image" (or "image[]") files, an optional "mask" and the generation fields.
+//
+// Parameters:
+//   - c: The Gin context containing the HTTP request and response
+func (h *OpenAIImagesAPIHandler) ImageEdits(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImageEditFormBytes)
+	form, err := c.MultipartForm()
+	if err != nil {
+		writeInvalidImageRequest(c, err)
+		return
+	}
+	n := 1
+	if v := strings.TrimSpace(formValue(form, "n")); v != "" {
+		if n, err = strconv.Atoi(v); err != nil {
+			writeInvalidImageRequest(c, fmt.Errorf("n must be an integer"))
+			return
+		}
+	}
+	req, err := newImageRequest(formValue(form, "model"), formValue(form, "prompt"), n, formValue(form, "size"), formValue(form, "response_format"))
+	if err != nil {
+		writeInvalidImageRequest(c, err)
+		return
+	}
+	for _, header := range append(form.File["image"], form.File["image[]"]...) {
+		img, errRead := readFormImage(header)
+		if errRead != nil {
+			writeInvalidImageRequest(c, errRead)
+			return
+		}
+		req.Images = append(req.Images, img)
+	}
+	if len(req.Images) == 0 {
+		writeInvalidImageRequest(c, fmt.Errorf("image is required"))
+		return
+	}
+	if masks := form.File["mask"]; len(masks) > 0 {
+		mask, errRead := readFormImage(masks[0])
+		if errRead != nil {
+			writeInvalidImageRequest(c, errRead)
+			return
+		}
+		req.Mask = &mask
+	}
+	h.generate(c, req)
+}
+
+// ImageFile handles GET /v1/images/files/{id}, serving images generated with
+// response_format "url". The route is unauthenticated; the random ID acts as the
+// capability and the image expires after an hour.
+func (h *OpenAIImagesAPIHandler) ImageFile(c *gin.Context) {
+	img, ok := h.images.get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, handlers.ErrorResponse{
+			Error: handlers.ErrorDetail{Message: "image not found or expired", Type: "invalid_request_error"},
+		})
+		return
+	}
+	data, err := base64.StdEncoding.DecodeString(img.Data)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.Header("Cache-Control", "private, max-age=3600")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, servedImageType(img.MIMEType), data)
+}
+
+// rasterImageTypes lists the upstream image types served under their own content type.
+// Anything else, such as SVG or HTML, could run script in the proxy's origin.
+var rasterImageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+	"image/avif": true,
+	"image/heic": true,
+	"image/heif": true,
+	"image/bmp":  true,
+}
+
+// servedImageType returns the content type an image file is served with.
+func servedImageType(mimeType string) string {
+	mediaType := strings.ToLower(strings.TrimSpace(mimeType))
+	if i := strings.IndexByte(mediaType, ';'); i >= 0 {
+		mediaType = strings.TrimSpace(mediaType[:i])
+	}
+	if rasterImageTypes[mediaType] {
+		return mediaType
+	}
+	return "application/octet-stream"
+}
+
+// generate runs one Gemini call per requested image and writes the OpenAI images response.
+// Each call goes through the auth manager on its own, so usage is recorded per image.
+func (h *OpenAIImagesAPIHandler) generate(c *gin.Context, req *imageRequest) {
+	payload := buildGeminiImageRequest(req)
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
+
+	type outcome struct {
+		images []inlineImage
+		text   string
+		usage  imageUsage
+		err    *interfaces.ErrorMessage
+	}
+	outcomes := make([]outcome, req.N)
+	var estimate handlers.TokenEstimate
+	execCtx := handlers.WithTokenEstimate(cliCtx, &estimate)
+	var wg sync.WaitGroup
+	for i := range outcomes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, errMsg := h.ExecuteWithAuthManager(execCtx, Gemini, req.Model, payload, "")
+			if errMsg != nil {
+				outcomes[i].err = errMsg
+				return
+			}
+			outcomes[i].images, outcomes[i].text, outcomes[i].usage = parseGeminiImageResponse(resp)
+		}(i)
+	}
+	wg.Wait()
+	estimate.WriteHeader(c)
+
+	var usage imageUsage
+	var texts []string
+	data := make([]map[string]any, 0, req.N)
+	for _, o := range outcomes {
+		if o.err != nil {
+			h.WriteErrorResponse(c, o.err)
+			cliCancel(o.err.Error)
+			return
+		}
+		usage.InputTokens += o.usage.InputTokens
+		usage.OutputTokens += o.usage.OutputTokens
+		usage.TotalTokens += o.usage.TotalTokens
+		if o.text != "" {
+			texts = append(texts, o.text)
+		}
+		for _, img := range o.images {
+			item := map[string]any{}
+			if req.ResponseFormat == "url" {
+				item["url"] = handlers.RequestBaseURL(c) + "/v1/images/files/" + h.images.put(img)
+			} else {
+				item["b64_json"] = img.Data
+			}
+			if o.text != "" {
+				item["revised_prompt"] = o.text
+			}
+			data = append(data, item)
+		}
+	}
+	if len(data) == 0 {
+		msg := fmt.Sprintf("model %s returned no image data", req.Model)
+		if len(texts) > 0 {
+			msg += ": " + texts[0]
+		}
+		errMsg := &interfaces.ErrorMessage{StatusCode: http.StatusBadGateway, Error: fmt.Errorf("%s", msg)}
+		h.WriteErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"created": time.Now().Unix(), "data": data, "usage": usage})
+	cliCancel()
+}
+
+// newImageRequest validates the fields shared by the generation and edit endpoints.
+func newImageRequest(model, prompt string, n int, size, responseFormat string) (*imageRequest, error) {
+	req := &imageRequest{
+		Model:          strings.TrimSpace(model),
+		Prompt:         strings.TrimSpace(prompt),
+		N:              n,
+		ResponseFormat: strings.TrimSpace(responseFormat),
+	}
+	if req.Model == "" {
+		return nil, fmt.Errorf("model is required")
+	}
+	if req.Prompt == "" {
+		return nil, fmt.Errorf("prompt is required")
+	}
+	if req.N < 1 || req.N > maxImagesPerRequest {
+		return nil, fmt.Errorf("n must be between 1 and %d", maxImagesPerRequest)
+	}
+	switch req.ResponseFormat {
+	case "":
+		req.ResponseFormat = "b64_json"
+	case "b64_json", "url":
+	default:
+		return nil, fmt.Errorf("response_format must be \"b64_json\" or \"url\"")
+	}
+	ratio, err := imageAspectRatio(size)
+	if err != nil {
+		return nil, err
+	}
+	req.AspectRatio = ratio
+	return req, nil
+}
+
+// imageAspectRatio maps an OpenAI size ("1024x1536") or an explicit ratio ("16:9") to the
+// closest aspect ratio supported by Gemini. An empty or "auto" size leaves the choice to
+// the model.
+func imageAspectRatio(size string) (string, error) {
+	size = strings.ToLower(strings.TrimSpace(size))
+	if size == "" || size == "auto" {
+		return "", nil
+	}
+	for _, ratio := range geminiAspectRatios {
+		if size == ratio {
+			return ratio, nil
+		}
+	}
+	w, h, ok := strings.Cut(size, "x")
+	width, errW := strconv.Atoi(w)
+	height, errH := strconv.Atoi(h)
+	if !ok || errW != nil || errH != nil || width <= 0 || height <= 0 {
+		return "", fmt.Errorf("size must be WIDTHxHEIGHT, an aspect ratio such as 16:9, or auto")
+	}
+	target := math.Log(float64(width) / float64(height))
+	best, bestDiff := "", math.Inf(1)
+	for _, ratio := range geminiAspectRatios {
+		a, b, _ := strings.Cut(ratio, ":")
+		num, _ := strconv.Atoi(a)
+		den, _ := strconv.Atoi(b)
+		if diff := math.Abs(math.Log(float64(num)/float64(den)) - target); diff < bestDiff {
+			best, bestDiff = ratio, diff
+		}
+	}
+	return best, nil
+}
+
+// buildGeminiImageRequest converts an image request into a Gemini generateContent body
+// that asks for image output only.
+func buildGeminiImageRequest(req *imageRequest) []byte {
+	out := []byte(`{"contents":[{"role":"user","parts":[]}],"generationConfig":{"responseModalities":["IMAGE"]}}`)
+	for _, img := range req.Images {
+		out = appendInlineImagePart(out, img)
+	}
+	if req.Mask != nil {
+		out, _ = sjson.SetBytes(out, "contents.0.parts.-1.text", "The next image is an edit mask. Only change the areas that are fully transparent in the mask.")
+		out = appendInlineImagePart(out, *req.Mask)
+	}
+	out, _ = sjson.SetBytes(out, "contents.0.parts.-1.text", req.Prompt)
+	if req.AspectRatio != "" {
+		out, _ = sjson.SetBytes(out, "generationConfig.imageConfig.aspectRatio", req.AspectRatio)
+	}
+	return out
+}
+
+func appendInlineImagePart(out []byte, img inlineImage) []byte {
+	part := []byte(`{"inlineData":{}}`)
+	part, _ = sjson.SetBytes(part, "inlineData.mimeType", img.MIMEType)
+	part, _ = sjson.SetBytes(part, "inlineData.data", img.Data)
+	out, _ = sjson.SetRawBytes(out, "contents.0.parts.-1", part)
+	return out
+}
+
+// parseGeminiImageResponse extracts the inline images, any accompanying text and the
+// token usage from a Gemini generateContent response. Thought parts are skipped.
+func parseGeminiImageResponse(resp []byte) ([]inlineImage, string, imageUsage) {
+	var images []inlineImage
+	var text strings.Builder
+	gjson.GetBytes(resp, "candidates").ForEach(func(_, candidate gjson.Result) bool {
+		candidate.Get("content.parts").ForEach(func(_, part gjson.Result) bool {
+			if part.Get("thought").Bool() {
+				return true
+			}
+			inline := part.Get("inlineData")
+			if !inline.Exists() {
+				inline = part.Get("inline_data")
+			}
+			if data := inline.Get("data").String(); data != "" {
+				mimeType := inline.Get("mimeType").String()
+				if mimeType == "" {
+					mimeType = inline.Get("mime_type").String()
+				}
+				if mimeType == "" {
+					mimeType = "image/png"
+				}
+				images = append(images, inlineImage{MIMEType: mimeType, Data: data})
+			} else if t := part.Get("text").String(); t != "" {
+				text.WriteString(t)
+			}
+			return true
+		})
+		return true
+	})
+	meta := gjson.GetBytes(resp, "usageMetadata")
+	usage := imageUsage{
+		InputTokens:  meta.Get("promptTokenCount").Int(),
+		OutputTokens: meta.Get("candidatesTokenCount").Int() + meta.Get("thoughtsTokenCount").Int(),
+		TotalTokens:  meta.Get("totalTokenCount").Int(),
+	}
+	return images, strings.TrimSpace(text.String()), usage
+}
+
+func readFormImage(header *multipart.FileHeader) (inlineImage, error) {
+	src, err := header.Open()
+	if err != nil {
+		return inlineImage{}, err
+	}
+	defer func() { _ = src.Close() }()
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return inlineImage{}, err
+	}
+	mimeType := header.Header.Get("Content-Type")
+	if !strings.HasPrefix(mimeType, "image/") {
+		mimeType = http.DetectContentType(data)
+	}
+	if !strings.HasPrefix(mimeType, "image/") {
+		return inlineImage{}, fmt.Errorf("%s is not an image", header.Filename)
+	}
+	return inlineImage{MIMEType: mimeType, Data: base64.StdEncoding.EncodeToString(data)}, nil
+}
+
+func formValue(form *multipart.Form, key string) string {
+	if values := form.Value[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func writeInvalidImageRequest(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, handlers.ErrorResponse{
+		Error: handlers.ErrorDetail{
+			Message: fmt.Sprintf("Invalid request: %v", err),
+			Type:    "invalid_request_error",
+		},
+	})
+}
+
+// imageCache keeps generated images in memory for a limited time so they can be served
+// by URL. The oldest image is evicted once the cache is full.
+type imageCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	limit   int
+	order   []string
+	entries map[string]cachedImage
+}
+
+type cachedImage struct {
+	image   inlineImage
+	expires time.Time
+}
+
+func newImageCache(ttl time.Duration, limit int) *imageCache {
+	return &imageCache{ttl: ttl, limit: limit, entries: make(map[string]cachedImage)}
+}
+
+func (c *imageCache) put(img inlineImage) string {
+	id := "img_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for len(c.order) > 0 {
+		oldest := c.order[0]
+		if len(c.order) < c.limit && c.entries[oldest].expires.After(now) {
+			break
+		}
+		delete(c.entries, oldest)
+		c.order = c.order[1:]
+	}
+	c.entries[id] = cachedImage{image: img, expires: now.Add(c.ttl)}
+	c.order = append(c.order, id)
+	return id
+}
+
+func (c *imageCache) get(id string) (inlineImage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[id]
+	if !ok || time.Now().After(entry.expires) {
+		return inlineImage{}, false
+	}
+	return entry.image, true
+}
@@ -0,0 +1,157 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+type imageExecutor struct {
+	mu       sync.Mutex
+	payloads [][]byte
+}
+
+func (e *imageExecutor) Identifier() string { return "gemini" }
+
+func (e *imageExecutor) Execute(_ context.Context, _ *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (coreexecutor.Response, error) {
+	e.mu.Lock()
+	e.payloads = append(e.payloads, req.Payload)
+	e.mu.Unlock()
+	return coreexecutor.Response{Payload: []byte(`{"candidates":[{"content":{"parts":[` +
+		`{"text":"draft","thought":true},{"inlineData":{"mimeType":"image/png","data":"dGhvdWdodA=="},"thought":true},` +
+		`{"text":"A red cube"},{"inlineData":{"mimeType":"image/png","data":"aW1hZ2U="}}]}}],` +
+		`"usageMetadata":{"promptTokenCount":5,"candidatesTokenCount":1290,"totalTokenCount":1295}}`)}, nil
+}
+
+func (e *imageExecutor) ExecuteStream(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (<-chan coreexecutor.StreamChunk, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "ExecuteStream not implemented"}
+}
+
+func (e *imageExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *imageExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, &coreauth.Error{Code: "not_implemented", Message: "CountTokens not implemented"}
+}
+
+func (e *imageExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "HttpRequest not implemented", HTTPStatus: http.StatusNotImplemented}
+}
+
+func TestImageGenerationsTranslatesToGemini(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	executor := &imageExecutor{}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	auth := &coreauth.Auth{ID: "image-auth", Provider: "gemini", Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("register auth: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "test-image-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+	h := NewOpenAIImagesAPIHandler(handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, manager))
+	router := gin.New()
+	router.POST("/v1/images/generations", h.ImageGenerations)
+	router.GET("/v1/images/files/:id", h.ImageFile)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rr
+	}
+
+	rr := do(http.MethodPost, "/v1/images/generations", `{"model":"test-image-model","prompt":"a red cube","n":2,"size":"1536x1024"}`)
+	body := rr.Body.String()
+	if rr.Code != http.StatusOK {
+		t.Fatalf("generations: %d %s", rr.Code, body)
+	}
+	if got := gjson.Get(body, "data.#").Int(); got != 2 {
+		t.Fatalf("expected 2 images, got %d: %s", got, body)
+	}
+	if gjson.Get(body, "data.0.b64_json").String() != "aW1hZ2U=" || gjson.Get(body, "data.0.revised_prompt").String() != "A red cube" {
+		t.Fatalf("unexpected image data: %s", body)
+	}
+	if gjson.Get(body, "usage.input_tokens").Int() != 10 || gjson.Get(body, "usage.total_tokens").Int() != 2590 {
+		t.Fatalf("usage should sum over both calls: %s", body)
+	}
+	if len(executor.payloads) != 2 {
+		t.Fatalf("expected one upstream call per image, got %d", len(executor.payloads))
+	}
+	payload := executor.payloads[0]
+	if gjson.GetBytes(payload, "generationConfig.responseModalities.0").String() != "IMAGE" ||
+		gjson.GetBytes(payload, "generationConfig.imageConfig.aspectRatio").String() != "3:2" ||
+		gjson.GetBytes(payload, "contents.0.parts.0.text").String() != "a red cube" {
+		t.Fatalf("unexpected Gemini request: %s", payload)
+	}
+
+	rr = do(http.MethodPost, "/v1/images/generations", `{"model":"test-image-model","prompt":"a red cube","response_format":"url"}`)
+	url := gjson.Get(rr.Body.String(), "data.0.url").String()
+	path, ok := strings.CutPrefix(url, "http://example.com")
+	if rr.Code != http.StatusOK || !ok || !strings.HasPrefix(path, "/v1/images/files/") {
+		t.Fatalf("url response: %d %s", rr.Code, rr.Body.String())
+	}
+	rr = do(http.MethodGet, path, "")
+	if rr.Code != http.StatusOK || rr.Body.String() != "image" || rr.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("image file: %d %q", rr.Code, rr.Body.String())
+	}
+	if rr.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Fatalf("image file served without nosniff: %v", rr.Header())
+	}
+
+	for _, mimeType := range []string{"text/html", "image/svg+xml"} {
+		id := h.images.put(inlineImage{MIMEType: mimeType, Data: "PHNjcmlwdD4="})
+		if rr = do(http.MethodGet, "/v1/images/files/"+id, ""); rr.Header().Get("Content-Type") != "application/octet-stream" {
+			t.Fatalf("%s image served as %q", mimeType, rr.Header().Get("Content-Type"))
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/images/generations", strings.NewReader(`{"model":"test-image-model","prompt":"a red cube","response_format":"url"}`))
+	req.Header.Set("X-Forwarded-Proto", "javascript")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if url := gjson.Get(rr.Body.String(), "data.0.url").String(); !strings.HasPrefix(url, "http://example.com/") {
+		t.Fatalf("image url trusted X-Forwarded-Proto: %q", url)
+	}
+
+	for _, invalid := range []string{
+		`{"prompt":"a red cube"}`,
+		`{"model":"test-image-model"}`,
+		`{"model":"test-image-model","prompt":"x","n":11}`,
+		`{"model":"test-image-model","prompt":"x","size":"large"}`,
+		`{"model":"test-image-model","prompt":"x","response_format":"png"}`,
+	} {
+		if rr = do(http.MethodPost, "/v1/images/generations", invalid); rr.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", invalid, rr.Code)
+		}
+	}
+}
+
+func TestImageAspectRatio(t *testing.T) {
+	cases := map[string]string{
+		"":          "",
+		"auto":      "",
+		"1024x1024": "1:1",
+		"1024x1536": "2:3",
+		"1792x1024": "16:9",
+		"1024x1792": "9:16",
+		"4:3":       "4:3",
+	}
+	for size, want := range cases {
+		got, err := imageAspectRatio(size)
+		if err != nil || got != want {
+			t.Fatalf("imageAspectRatio(%q) = %q, %v; want %q", size, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/responsestore"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	defaultInputItemsLimit = 20
+	maxInputItemsLimit     = 100
+)
+
+// responseSession carries the stored-conversation state of a single Responses request.
+type responseSession struct {
+	store      responsestore.Store
+	owner      string
+	previousID string
+	// history is the JSON array of items from earlier turns.
+	history []byte
+	// input is the JSON array of items supplied with this request.
+	input   []byte
+	persist bool
+	release func()
+}
+
+// close releases the session's hold on the response store.
+func (s *responseSession) close() {
+	if s != nil && s.release != nil {
+		s.release()
+	}
+}
+
+// responseStore returns the store configured for this handler, or nil when storage is
+// disabled, and the function that releases it once the caller is done.
+func (h *OpenAIResponsesAPIHandler) responseStore() (responsestore.Store, func(), error) {
+	var cfg config.ResponsesStoreConfig
+	if h.Cfg != nil {
+		cfg = h.Cfg.ResponsesStore
+	}
+	return responsestore.Shared(cfg)
+}
+
+// prepareResponseSession expands previous_response_id into the full conversation input so
+// the request can be translated for any provider, and prepares the response for storage.
+// Only responses stored by owner can be referenced. While storage is disabled the request
+// is passed through unchanged when every provider of the model resolves previous_response_id
+// upstream, and rejected otherwise rather than silently losing the earlier turns. The caller
+// closes the returned session once the response has been written.
+func (h *OpenAIResponsesAPIHandler) prepareResponseSession(ctx context.Context, owner string, rawJSON []byte) ([]byte, *responseSession, *interfaces.ErrorMessage) {
+	store, release, errStore := h.responseStore()
+	expanded, session, errMsg := h.expandResponseSession(ctx, store, errStore, owner, rawJSON)
+	if errMsg != nil {
+		release()
+		return nil, nil, errMsg
+	}
+	session.release = release
+	return expanded, session, nil
+}
+
+func (h *OpenAIResponsesAPIHandler) expandResponseSession(ctx context.Context, store responsestore.Store, errStore error, owner string, rawJSON []byte) ([]byte, *responseSession, *interfaces.ErrorMessage) {
+	previousID := strings.TrimSpace(gjson.GetBytes(rawJSON, "previous_response_id").String())
+	session := &responseSession{
+		store:   store,
+		owner:   owner,
+		history: []byte("[]"),
+		input:   normalizeResponsesInput(gjson.GetBytes(rawJSON, "input")),
+	}
+	if store == nil {
+		if errStore != nil && previousID != "" {
+			return nil, nil, &interfaces.ErrorMessage{StatusCode: http.StatusServiceUnavailable, Error: fmt.Errorf("response store unavailable: %w", errStore)}
+		}
+		if previousID != "" {
+			model := gjson.GetBytes(rawJSON, "model").String()
+			if !upstreamResolvesPreviousResponse(h.ResolveVirtualModel(model)) {
+				return nil, nil, &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("previous_response_id is not supported for model %s unless responses-store is enabled", model)}
+			}
+		}
+		return rawJSON, session, nil
+	}
+	session.previousID = previousID
+	session.persist = gjson.GetBytes(rawJSON, "store").Type != gjson.False
+
+	if session.previousID == "" {
+		return rawJSON, session, nil
+	}
+	record, errLoad := store.Load(ctx, session.previousID)
+	if errLoad == nil && !record.VisibleTo(owner) {
+		errLoad = responsestore.ErrNotFound
+	}
+	if errLoad != nil {
+		if errors.Is(errLoad, responsestore.ErrNotFound) {
+			return nil, nil, &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("Previous response with id '%s' not found.", session.previousID)}
+		}
+		return nil, nil, &interfaces.ErrorMessage{StatusCode: http.StatusInternalServerError, Error: errLoad}
+	}
+
+	session.history = joinItemArrays(record.History, record.Input, []byte(gjson.GetBytes(record.Response, "output").Raw))
+	expanded, _ := sjson.SetRawBytes(rawJSON, "input", joinItemArrays(session.history, session.input))
+	expanded, _ = sjson.DeleteBytes(expanded, "previous_response_id")
+	return expanded, session, nil
+}
+
+// previousResponseProviders forward previous_response_id to an upstream Responses API that
+// resolves it. The translators of every other provider drop the field.
+var previousResponseProviders = map[string]bool{
+	"codex": true,
+}
+
+// upstreamResolvesPreviousResponse reports whether every provider of model forwards
+// previous_response_id upstream. Unknown models are left to the usual routing error.
+func upstreamResolvesPreviousResponse(model string) bool {
+	if _, ok := resolveEndpointOverride(model, openAIResponsesEndpoint); ok {
+		return false
+	}
+	providers := util.GetProviderName(strings.TrimSpace(thinking.ParseSuffix(model).ModelName))
+	for _, provider := range providers {
+		if !previousResponseProviders[provider] {
+			return false
+		}
+	}
+	return true
+}
+
+// finishResponse echoes previous_response_id into a completed response object and stores it.
+func (s *responseSession) finishResponse(response []byte) []byte {
+	if s == nil || !gjson.ValidBytes(response) {
+		return response
+	}
+	if s.previousID != "" {
+		response, _ = sjson.SetBytes(response, "previous_response_id", s.previousID)
+	}
+	s.save(response)
+	return response
+}
+
+// rewriteStreamChunk echoes previous_response_id into response lifecycle events and stores
+// the final response carried by response.completed.
+func (s *responseSession) rewriteStreamChunk(chunk []byte) []byte {
+	if s == nil || (s.previousID == "" && !s.persist) || !bytes.Contains(chunk, []byte(`"response`)) {
+		return chunk
+	}
+	lines := bytes.Split(chunk, []byte("\n"))
+	changed := false
+	for i, line := range lines {
+		payload, ok := bytes.CutPrefix(bytes.TrimSpace(line), []byte("data:"))
+		if !ok {
+			continue
+		}
+		payload = bytes.TrimSpace(payload)
+		if !gjson.ValidBytes(payload) {
+			continue
+		}
+		eventType := gjson.GetBytes(payload, "type").String()
+		if !strings.HasPrefix(eventType, "response.") || !gjson.GetBytes(payload, "response").IsObject() {
+			continue
+		}
+		if s.previousID != "" {
+			payload, _ = sjson.SetBytes(payload, "response.previous_response_id", s.previousID)
+			lines[i] = append([]byte("data: "), payload...)
+			changed = true
+		}
+		if eventType == "response.completed" {
+			s.save([]byte(gjson.GetBytes(payload, "response").Raw))
+		}
+	}
+	if !changed {
+		return chunk
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
+
+func (s *responseSession) save(response []byte) {
+	if !s.persist {
+		return
+	}
+	id := gjson.GetBytes(response, "id").String()
+	if id == "" {
+		return
+	}
+	record := &responsestore.Record{
+		ID:       id,
+		Owner:    s.owner,
+		History:  s.history,
+		Input:    s.input,
+		Response: bytes.Clone(response),
+	}
+	if err := s.store.Save(context.Background(), record); err != nil {
+		log.Warnf("responses: store response %s: %v", id, err)
+	}
+}
+
+// normalizeResponsesInput returns the request input as a JSON array of items. A string
+// input becomes a single user message.
+func normalizeResponsesInput(input gjson.Result) []byte {
+	switch {
+	case input.IsArray():
+		return []byte(input.Raw)
+	case input.Type == gjson.String:
+		item := []byte(`{"type":"message","role":"user","content":[{"type":"input_text","text":""}]}`)
+		item, _ = sjson.SetBytes(item, "content.0.text", input.String())
+		return append(append([]byte("["), item...), ']')
+	}
+	return []byte("[]")
+}
+
+// joinItemArrays concatenates JSON arrays of items.
+func joinItemArrays(arrays ...[]byte) []byte {
+	out := []byte("[")
+	first := true
+	for _, array := range arrays {
+		for _, item := range gjson.ParseBytes(array).Array() {
+			if !first {
+				out = append(out, ',')
+			}
+			out = append(out, item.Raw...)
+			first = false
+		}
+	}
+	return append(out, ']')
+}
+
+// loadStoredResponse fetches the response named by the :id path parameter, writing a
+// not-found error when it is unknown or belongs to another API key.
+func (h *OpenAIResponsesAPIHandler) loadStoredResponse(c *gin.Context) (*responsestore.Record, bool) {
+	id := c.Param("id")
+	store, release, errStore := h.responseStore()
+	defer release()
+	if store == nil {
+		if errStore != nil {
+			h.WriteErrorResponse(c, &interfaces.ErrorMessage{StatusCode: http.StatusServiceUnavailable, Error: fmt.Errorf("response store unavailable: %w", errStore)})
+			return nil, false
+		}
+		writeResponseNotFound(c, id)
+		return nil, false
+	}
+	record, err := store.Load(c.Request.Context(), id)
+	if err == nil && !record.VisibleTo(handlers.RequestOwner(c)) {
+		err = responsestore.ErrNotFound
+	}
+	if err != nil {
+		if errors.Is(err, responsestore.ErrNotFound) {
+			writeResponseNotFound(c, id)
+			return nil, false
+		}
+		h.WriteErrorResponse(c, &interfaces.ErrorMessage{StatusCode: http.StatusInternalServerError, Error: err})
+		return nil, false
+	}
+	return record, true
+}
+
+func writeResponseNotFound(c *gin.Context, id string) {
+	c.JSON(http.StatusNotFound, handlers.ErrorResponse{
+		Error: handlers.ErrorDetail{
+			Message: fmt.Sprintf("Response with id '%s' not found.", id),
+			Type:    "invalid_request_error",
+		},
+	})
+}
+
+// GetResponse handles GET /v1/responses/{id}.
+func (h *OpenAIResponsesAPIHandler) GetResponse(c *gin.Context) {
+	record, ok := h.loadStoredResponse(c)
+	if !ok {
+		return
+	}
+	c.Data(http.StatusOK, "application/json", record.Response)
+}
+
+// DeleteResponse handles DELETE /v1/responses/{id}.
+func (h *OpenAIResponsesAPIHandler) DeleteResponse(c *gin.Context) {
+	if _, ok := h.loadStoredResponse(c); !ok {
+		return
+	}
+	id := c.Param("id")
+	store, release, _ := h.responseStore()
+	defer release()
+	if store == nil {
+		writeResponseNotFound(c, id)
+		return
+	}
+	if err := store.Delete(c.Request.Context(), id); err != nil {
+		if errors.Is(err, responsestore.ErrNotFound) {
+			writeResponseNotFound(c, id)
+			return
+		}
+		h.WriteErrorResponse(c, &interfaces.ErrorMessage{StatusCode: http.StatusInternalServerError, Error: err})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": id, "object": "response.deleted", "deleted": true})
+}
+
+// ResponseInputItems handles GET /v1/responses/{id}/input_items. It supports the
+// order (asc|desc, default desc), limit (1-100, default 20) and after query parameters.
+func (h *OpenAIResponsesAPIHandler) ResponseInputItems(c *gin.Context) {
+	record, ok := h.loadStoredResponse(c)
+	if !ok {
+		return
+	}
+	items := gjson.ParseBytes(record.Input).Array()
+	raw := make([]string, 0, len(items))
+	ids := make([]string, 0, len(items))
+	for i, item := range items {
+		id := item.Get("id").String()
+		if id == "" {
+			id = fmt.Sprintf("%s_input_%d", record.ID, i)
+		}
+		value, _ := sjson.Set(item.Raw, "id", id)
+		raw = append(raw, value)
+		ids = append(ids, id)
+	}
+	if !strings.EqualFold(c.Query("order"), "asc") {
+		for i, j := 0, len(raw)-1; i < j; i, j = i+1, j-1 {
+			raw[i], raw[j] = raw[j], raw[i]
+			ids[i], ids[j] = ids[j], ids[i]
+		}
+	}
+	if after := c.Query("after"); after != "" {
+		for i, id := range ids {
+			if id == after {
+				raw, ids = raw[i+1:], ids[i+1:]
+				break
+			}
+		}
+	}
+	limit := defaultInputItemsLimit
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 {
+		limit = min(v, maxInputItemsLimit)
+	}
+	hasMore := len(raw) > limit
+	if hasMore {
+		raw, ids = raw[:limit], ids[:limit]
+	}
+
+	out := []byte(`{"object":"list","data":[],"first_id":null,"last_id":null,"has_more":false}`)
+	out, _ = sjson.SetRawBytes(out, "data", []byte("["+strings.Join(raw, ",")+"]"))
+	if len(ids) > 0 {
+		out, _ = sjson.SetBytes(out, "first_id", ids[0])
+		out, _ = sjson.SetBytes(out, "last_id", ids[len(ids)-1])
+	}
+	out, _ = sjson.SetBytes(out, "has_more", hasMore)
+	c.Data(http.StatusOK, "application/json", out)
+}
@@ -0,0 +1,219 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func newResponsesStoreTestHandler(t *testing.T) *OpenAIResponsesAPIHandler {
+	t.Helper()
+	cfg := &sdkconfig.SDKConfig{}
+	cfg.ResponsesStore.Enabled = true
+	cfg.ResponsesStore.Backend = "file"
+	cfg.ResponsesStore.Dir = t.TempDir()
+	return NewOpenAIResponsesAPIHandler(handlers.NewBaseAPIHandlers(cfg, nil))
+}
+
+func TestResponseSessionExpandsPreviousResponse(t *testing.T) {
+	h := newResponsesStoreTestHandler(t)
+	ctx := context.Background()
+
+	_, first, errMsg := h.prepareResponseSession(ctx, "", []byte(`{"model":"m","input":"hello"}`))
+	if errMsg != nil {
+		t.Fatalf("first request: %v", errMsg.Error)
+	}
+	first.finishResponse([]byte(`{"id":"resp_1","object":"response","output":[{"type":"message","role":"assistant","content":[{"type":"output_text","text":"hi there"}]}]}`))
+
+	expanded, second, errMsg := h.prepareResponseSession(ctx, "", []byte(`{"model":"m","previous_response_id":"resp_1","input":[{"type":"message","role":"user","content":[{"type":"input_text","text":"how are you"}]}]}`))
+	if errMsg != nil {
+		t.Fatalf("second request: %v", errMsg.Error)
+	}
+	if gjson.GetBytes(expanded, "previous_response_id").Exists() {
+		t.Fatalf("previous_response_id should be removed before translation: %s", expanded)
+	}
+	input := gjson.GetBytes(expanded, "input").Array()
+	if len(input) != 3 {
+		t.Fatalf("expected 3 expanded input items, got %d: %s", len(input), expanded)
+	}
+	if got := input[0].Get("content.0.text").String(); got != "hello" {
+		t.Fatalf("first item text = %q", got)
+	}
+	if got := input[1].Get("role").String(); got != "assistant" {
+		t.Fatalf("second item role = %q", got)
+	}
+
+	chunk := []byte("event: response.completed\ndata: {\"type\":\"response.completed\",\"response\":{\"id\":\"resp_2\",\"output\":[]}}")
+	rewritten := second.rewriteStreamChunk(chunk)
+	if !strings.Contains(string(rewritten), `"previous_response_id":"resp_1"`) {
+		t.Fatalf("stream chunk should echo previous_response_id: %s", rewritten)
+	}
+
+	store, release, _ := h.responseStore()
+	defer release()
+	record, err := store.Load(ctx, "resp_2")
+	if err != nil {
+		t.Fatalf("streamed response not stored: %v", err)
+	}
+	if got := len(gjson.ParseBytes(record.History).Array()); got != 2 {
+		t.Fatalf("history items = %d, want 2", got)
+	}
+}
+
+func TestResponseSessionHonorsStoreFalse(t *testing.T) {
+	h := newResponsesStoreTestHandler(t)
+	ctx := context.Background()
+
+	_, session, errMsg := h.prepareResponseSession(ctx, "", []byte(`{"model":"m","input":"x","store":false}`))
+	if errMsg != nil {
+		t.Fatalf("prepare: %v", errMsg.Error)
+	}
+	session.finishResponse([]byte(`{"id":"resp_nostore","output":[]}`))
+
+	store, release, _ := h.responseStore()
+	defer release()
+	if _, err := store.Load(ctx, "resp_nostore"); err == nil {
+		t.Fatal("store:false responses must not be recorded")
+	}
+	if _, _, errMsg = h.prepareResponseSession(ctx, "", []byte(`{"model":"m","previous_response_id":"resp_nostore","input":"y"}`)); errMsg == nil || errMsg.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown previous_response_id, got %+v", errMsg)
+	}
+}
+
+func TestStoredResponseEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := newResponsesStoreTestHandler(t)
+	_, session, _ := h.prepareResponseSession(context.Background(), "", []byte(`{"model":"m","input":[{"type":"message","role":"user","content":"a"},{"type":"message","role":"user","content":"b"}]}`))
+	session.finishResponse([]byte(`{"id":"resp_http","object":"response","output":[]}`))
+
+	router := gin.New()
+	router.GET("/v1/responses/:id", h.GetResponse)
+	router.DELETE("/v1/responses/:id", h.DeleteResponse)
+	router.GET("/v1/responses/:id/input_items", h.ResponseInputItems)
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(method, path, nil))
+		return rr
+	}
+
+	if rr := do(http.MethodGet, "/v1/responses/resp_http"); rr.Code != http.StatusOK || gjson.Get(rr.Body.String(), "id").String() != "resp_http" {
+		t.Fatalf("get: %d %s", rr.Code, rr.Body.String())
+	}
+	rr := do(http.MethodGet, "/v1/responses/resp_http/input_items?order=asc&limit=1")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("input_items: %d %s", rr.Code, rr.Body.String())
+	}
+	body := rr.Body.String()
+	if gjson.Get(body, "data.0.content").String() != "a" || !gjson.Get(body, "has_more").Bool() {
+		t.Fatalf("unexpected input_items page: %s", body)
+	}
+	if rr = do(http.MethodDelete, "/v1/responses/resp_http"); rr.Code != http.StatusOK || !gjson.Get(rr.Body.String(), "deleted").Bool() {
+		t.Fatalf("delete: %d %s", rr.Code, rr.Body.String())
+	}
+	if rr = do(http.MethodGet, "/v1/responses/resp_http"); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 after delete, got %d", rr.Code)
+	}
+}
+
+func TestStoredResponsesAreScopedToAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := newResponsesStoreTestHandler(t)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("apiKey", c.GetHeader("Authorization"))
+	})
+	var owner string
+	router.GET("/owner", func(c *gin.Context) { owner = handlers.RequestOwner(c) })
+	router.GET("/v1/responses/:id", h.GetResponse)
+	router.DELETE("/v1/responses/:id", h.DeleteResponse)
+	router.GET("/v1/responses/:id/input_items", h.ResponseInputItems)
+
+	do := func(method, path, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", key)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	do(http.MethodGet, "/owner", "key-a")
+	ownerA := owner
+	do(http.MethodGet, "/owner", "key-b")
+	if ownerA == "" || ownerA == owner || strings.Contains(ownerA, "key-a") {
+		t.Fatalf("unexpected owner ids %q and %q", ownerA, owner)
+	}
+
+	_, session, _ := h.prepareResponseSession(context.Background(), ownerA, []byte(`{"model":"m","input":"secret"}`))
+	session.finishResponse([]byte(`{"id":"resp_owned","object":"response","output":[]}`))
+
+	for _, probe := range []struct{ method, path string }{
+		{http.MethodGet, "/v1/responses/resp_owned"},
+		{http.MethodGet, "/v1/responses/resp_owned/input_items"},
+		{http.MethodDelete, "/v1/responses/resp_owned"},
+	} {
+		if rr := do(probe.method, probe.path, "key-b"); rr.Code != http.StatusNotFound {
+			t.Fatalf("%s %s with another key: %d %s", probe.method, probe.path, rr.Code, rr.Body.String())
+		}
+	}
+	if _, _, errMsg := h.prepareResponseSession(context.Background(), owner, []byte(`{"model":"m","previous_response_id":"resp_owned","input":"x"}`)); errMsg == nil || errMsg.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for another key's previous_response_id, got %+v", errMsg)
+	}
+	if rr := do(http.MethodGet, "/v1/responses/resp_owned", "key-a"); rr.Code != http.StatusOK {
+		t.Fatalf("owner lost access: %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestResponsesStoreIsOptIn(t *testing.T) {
+	h := NewOpenAIResponsesAPIHandler(handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, nil))
+	_, session, errMsg := h.prepareResponseSession(context.Background(), "", []byte(`{"model":"m","input":"x"}`))
+	if errMsg != nil {
+		t.Fatalf("prepare: %v", errMsg.Error)
+	}
+	if session.persist {
+		t.Fatal("responses must not be stored unless responses-store.enabled is set")
+	}
+
+}
+
+func TestPreviousResponseIDWithoutStore(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("previous-codex", "codex", []*registry.ModelInfo{{ID: "previous-codex-model"}})
+	reg.RegisterClient("previous-claude", "claude", []*registry.ModelInfo{{ID: "previous-claude-model"}})
+	t.Cleanup(func() {
+		reg.UnregisterClient("previous-codex")
+		reg.UnregisterClient("previous-claude")
+	})
+	cfg := &sdkconfig.SDKConfig{
+		VirtualModels: []sdkconfig.VirtualModel{{Name: "previous-virtual-claude", Model: "previous-claude-model"}},
+	}
+	h := NewOpenAIResponsesAPIHandler(handlers.NewBaseAPIHandlers(cfg, nil))
+
+	raw := []byte(`{"model":"previous-codex-model","previous_response_id":"resp_upstream","input":"y"}`)
+	out, session, errMsg := h.prepareResponseSession(context.Background(), "", raw)
+	if errMsg != nil {
+		t.Fatalf("previous_response_id must pass through to codex while storage is disabled: %v", errMsg.Error)
+	}
+	if string(out) != string(raw) || session.previousID != "" {
+		t.Fatalf("request changed while storage is disabled: %s", out)
+	}
+
+	for _, model := range []string{"previous-claude-model", "previous-virtual-claude"} {
+		raw = []byte(`{"model":"` + model + `","previous_response_id":"resp_upstream","input":"y"}`)
+		if _, _, errMsg = h.prepareResponseSession(context.Background(), "", raw); errMsg == nil || errMsg.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400 for previous_response_id without storage, got %+v", model, errMsg)
+		}
+	}
+	if _, _, errMsg = h.prepareResponseSession(context.Background(), "", []byte(`{"model":"previous-claude-model","input":"y"}`)); errMsg != nil {
+		t.Fatalf("request without previous_response_id rejected: %v", errMsg.Error)
+	}
+}
@@ -0,0 +1,306 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/jsonschema"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	defaultMaxRepairs = 2
+
+	// structuredOutputTool is the forced tool through which providers without a native
+	// JSON mode return structured output.
+	structuredOutputTool = "json_response"
+)
+
+// toolForcedProviders lack a native response_format equivalent in their translators.
+// Requests for them are answered through a forced tool call when possible. Gemini
+// providers map response_format to responseSchema and are enforced natively. Bedrock
+// Converse has no JSON mode but honours a forced toolChoice.
+var toolForcedProviders = map[string]bool{
+	"claude":      true,
+	"kiro":        true,
+	"antigravity": true,
+	"bedrock":     true,
+}
+
+// structuredOutput describes the enforcement of one chat completion request's
+// response_format.
+type structuredOutput struct {
+	schema     *jsonschema.Schema
+	maxRepairs int
+	viaTool    bool
+}
+
+// structuredOutputFor returns the enforcement settings for a request, or nil when
+// enforcement is disabled or the request does not ask for JSON output. Enforcement covers
+// /v1/chat/completions only; Claude messages and Responses requests pass through as sent.
+func (h *OpenAIAPIHandler) structuredOutputFor(rawJSON []byte) (*structuredOutput, error) {
+	if h.Cfg == nil || !h.Cfg.StructuredOutput.Enforce {
+		return nil, nil
+	}
+	format := gjson.GetBytes(rawJSON, "response_format")
+	so := &structuredOutput{maxRepairs: defaultMaxRepairs}
+	if h.Cfg.StructuredOutput.MaxRepairs > 0 {
+		so.maxRepairs = h.Cfg.StructuredOutput.MaxRepairs
+	}
+	switch format.Get("type").String() {
+	case "json_schema":
+		schema := format.Get("json_schema.schema")
+		if !schema.Exists() {
+			return nil, fmt.Errorf("response_format.json_schema.schema is required")
+		}
+		compiled, err := jsonschema.Compile([]byte(schema.Raw))
+		if err != nil {
+			return nil, err
+		}
+		so.schema = compiled
+	case "json_object":
+		so.schema, _ = jsonschema.Compile([]byte(`{"type":"object"}`))
+	default:
+		return nil, nil
+	}
+	model := h.ResolveVirtualModel(gjson.GetBytes(rawJSON, "model").String())
+	so.viaTool = useToolForcing(model, rawJSON)
+	return so, nil
+}
+
+// useToolForcing reports whether JSON mode should be emulated with a forced tool call:
+// every provider of the resolved model lacks native JSON mode, the client sent no tools of
+// its own and did not ask for reasoning, which forced tool calls cannot be combined with.
+func useToolForcing(model string, rawJSON []byte) bool {
+	if gjson.GetBytes(rawJSON, "tools.#").Int() > 0 || gjson.GetBytes(rawJSON, "reasoning_effort").Exists() {
+		return false
+	}
+	parsed := thinking.ParseSuffix(model)
+	if parsed.HasSuffix {
+		return false
+	}
+	providers := util.GetProviderName(strings.TrimSpace(parsed.ModelName))
+	if len(providers) == 0 {
+		return false
+	}
+	for _, provider := range providers {
+		if !toolForcedProviders[provider] {
+			return false
+		}
+	}
+	return true
+}
+
+// prepare rewrites a single-choice, non-streaming request for enforcement, replacing
+// response_format by a forced tool when JSON mode is emulated.
+func (so *structuredOutput) prepare(rawJSON []byte) []byte {
+	out := rawJSON
+	for _, path := range []string{"n", "stream", "stream_options"} {
+		out, _ = sjson.DeleteBytes(out, path)
+	}
+	if !so.viaTool {
+		return out
+	}
+	format := gjson.GetBytes(out, "response_format")
+	parameters := format.Get("json_schema.schema").Raw
+	if parameters == "" {
+		parameters = `{"type":"object"}`
+	}
+	description := "Return the final answer as the arguments of this call, matching the parameters schema."
+	if desc := format.Get("json_schema.description").String(); desc != "" {
+		description += " " + desc
+	}
+	tool := []byte(`{"type":"function","function":{}}`)
+	tool, _ = sjson.SetBytes(tool, "function.name", structuredOutputTool)
+	tool, _ = sjson.SetBytes(tool, "function.description", description)
+	tool, _ = sjson.SetRawBytes(tool, "function.parameters", []byte(parameters))
+	out, _ = sjson.SetRawBytes(out, "tools", append(append([]byte("["), tool...), ']'))
+	out, _ = sjson.SetRawBytes(out, "tool_choice", []byte(`{"type":"function","function":{"name":"`+structuredOutputTool+`"}}`))
+	out, _ = sjson.DeleteBytes(out, "response_format")
+	return out
+}
+
+// normalize turns a forced tool call back into message content and strips markdown code
+// fences around JSON content. It returns the updated response and the content.
+func (so *structuredOutput) normalize(resp []byte) ([]byte, string) {
+	message := gjson.GetBytes(resp, "choices.0.message")
+	if so.viaTool {
+		for _, call := range message.Get("tool_calls").Array() {
+			if call.Get("function.name").String() != structuredOutputTool {
+				continue
+			}
+			resp, _ = sjson.SetBytes(resp, "choices.0.message.content", call.Get("function.arguments").String())
+			resp, _ = sjson.DeleteBytes(resp, "choices.0.message.tool_calls")
+			resp, _ = sjson.SetBytes(resp, "choices.0.finish_reason", "stop")
+			break
+		}
+	}
+	content := gjson.GetBytes(resp, "choices.0.message.content").String()
+	if stripped := stripCodeFence(content); stripped != content {
+		content = stripped
+		resp, _ = sjson.SetBytes(resp, "choices.0.message.content", content)
+	}
+	return resp, content
+}
+
+// stripCodeFence removes a markdown code fence wrapping the whole content.
+func stripCodeFence(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, "```") || !strings.HasSuffix(trimmed, "```") || len(trimmed) < 6 {
+		return content
+	}
+	body := strings.TrimSuffix(trimmed[3:], "```")
+	if newline := strings.IndexByte(body, '\n'); newline >= 0 && !strings.ContainsAny(body[:newline], "{[") {
+		body = body[newline+1:]
+	}
+	return strings.TrimSpace(body)
+}
+
+// executeStructured runs a prepared request and validates the answer, retrying with a
+// repair instruction while it does not match the schema. Usage is summed over attempts.
+// After the last repair the final answer is returned even when it is still invalid.
+func (h *OpenAIAPIHandler) executeStructured(ctx context.Context, so *structuredOutput, modelName string, request []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
+	usage := []byte(`{}`)
+	hasUsage := false
+	for attempt := 0; ; attempt++ {
+		resp, errMsg := h.ExecuteWithAuthManager(ctx, h.HandlerType(), modelName, request, alt)
+		if errMsg != nil {
+			return nil, errMsg
+		}
+		if u := gjson.GetBytes(resp, "usage"); u.IsObject() {
+			usage = addUsage(usage, u)
+			hasUsage = true
+		}
+		var content string
+		resp, content = so.normalize(resp)
+		problems := so.schema.Validate([]byte(content))
+		if len(problems) == 0 || attempt >= so.maxRepairs || ctx.Err() != nil {
+			if len(problems) > 0 {
+				log.Warnf("structured output for model %s still invalid after %d repairs: %s", modelName, attempt, strings.Join(problems, "; "))
+			}
+			if hasUsage {
+				resp, _ = sjson.SetRawBytes(resp, "usage", usage)
+			}
+			return resp, nil
+		}
+		log.Debugf("structured output for model %s invalid, requesting repair %d: %s", modelName, attempt+1, strings.Join(problems, "; "))
+		request = appendRepairTurn(request, content, problems)
+	}
+}
+
+// appendRepairTurn appends the invalid answer and a repair instruction to the messages.
+func appendRepairTurn(request []byte, content string, problems []string) []byte {
+	assistant, _ := sjson.SetBytes([]byte(`{"role":"assistant"}`), "content", content)
+	instruction := "Your previous response did not satisfy the required JSON schema:\n- " +
+		strings.Join(problems, "\n- ") +
+		"\nRespond again with only the corrected JSON value, without any surrounding text."
+	user, _ := sjson.SetBytes([]byte(`{"role":"user"}`), "content", instruction)
+	out, _ := sjson.SetRawBytes(request, "messages.-1", assistant)
+	out, _ = sjson.SetRawBytes(out, "messages.-1", user)
+	return out
+}
+
+// handleStructuredOutput serves a chat completion with structured output enforcement.
+// Each of the n choices is enforced independently; streaming requests are buffered and
+// replayed as a chunk stream once every choice is final.
+func (h *OpenAIAPIHandler) handleStructuredOutput(c *gin.Context, rawJSON []byte, so *structuredOutput, n int, stream bool) {
+	modelName := gjson.GetBytes(rawJSON, "model").String()
+	request := so.prepare(rawJSON)
+	alt := h.GetAlt(c)
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
+	var estimate handlers.TokenEstimate
+	responses, errMsg := executeParallel(handlers.WithTokenEstimate(cliCtx, &estimate), n, func(ctx context.Context) ([]byte, *interfaces.ErrorMessage) {
+		return h.executeStructured(ctx, so, modelName, request, alt)
+	})
+	estimate.WriteHeader(c)
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+		return
+	}
+	merged := responses[0]
+	if n > 1 {
+		merged = mergeChatCompletions(responses)
+	}
+
+	if !stream {
+		c.Header("Content-Type", "application/json")
+		_, _ = c.Writer.Write(merged)
+		cliCancel()
+		return
+	}
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("Access-Control-Allow-Origin", "*")
+	includeUsage := gjson.GetBytes(rawJSON, "stream_options.include_usage").Bool()
+	for _, chunk := range completionToChunks(merged, includeUsage) {
+		_, _ = fmt.Fprintf(c.Writer, "data: %s\n\n", chunk)
+	}
+	_, _ = fmt.Fprint(c.Writer, "data: [DONE]\n\n")
+	if flusher, ok := c.Writer.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	cliCancel()
+}
+
+// completionToChunks replays a chat completion as stream chunks: a content delta and a
+// finish chunk per choice, followed by a usage chunk when requested.
+func completionToChunks(completion []byte, includeUsage bool) [][]byte {
+	envelope := []byte(`{"object":"chat.completion.chunk"}`)
+	for _, field := range []string{"id", "created", "model", "system_fingerprint"} {
+		if value := gjson.GetBytes(completion, field); value.Exists() {
+			envelope, _ = sjson.SetRawBytes(envelope, field, []byte(value.Raw))
+		}
+	}
+	withChoice := func(choice []byte) []byte {
+		chunk, _ := sjson.SetRawBytes(bytes.Clone(envelope), "choices", append(append([]byte("["), choice...), ']'))
+		return chunk
+	}
+
+	var chunks [][]byte
+	for _, choice := range gjson.GetBytes(completion, "choices").Array() {
+		index := choice.Get("index").Int()
+		delta := []byte(`{"role":"assistant"}`)
+		message := choice.Get("message")
+		message.ForEach(func(key, value gjson.Result) bool {
+			if key.String() != "role" {
+				delta, _ = sjson.SetRawBytes(delta, key.String(), []byte(value.Raw))
+			}
+			return true
+		})
+		content, _ := sjson.SetRawBytes([]byte(`{"finish_reason":null}`), "delta", delta)
+		content, _ = sjson.SetBytes(content, "index", index)
+		chunks = append(chunks, withChoice(content))
+
+		finish, _ := sjson.SetBytes([]byte(`{"delta":{}}`), "index", index)
+		finish, _ = sjson.SetBytes(finish, "finish_reason", choice.Get("finish_reason").String())
+		chunks = append(chunks, withChoice(finish))
+	}
+	if usage := gjson.GetBytes(completion, "usage"); includeUsage && usage.Exists() {
+		chunk, _ := sjson.SetRawBytes(bytes.Clone(envelope), "choices", []byte(`[]`))
+		chunk, _ = sjson.SetRawBytes(chunk, "usage", []byte(usage.Raw))
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// invalidResponseFormat writes the 400 response for an unusable response_format.
+func invalidResponseFormat(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, handlers.ErrorResponse{
+		Error: handlers.ErrorDetail{
+			Message: fmt.Sprintf("Invalid request: %v", err),
+			Type:    "invalid_request_error",
+		},
+	})
+}
@@ -0,0 +1,179 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+// structuredExecutor answers through the forced tool, first with an invalid and then with
+// a valid document.
+type structuredExecutor struct {
+	mu       sync.Mutex
+	requests [][]byte
+}
+
+func (e *structuredExecutor) Identifier() string { return "claude" }
+
+func (e *structuredExecutor) Execute(_ context.Context, _ *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (coreexecutor.Response, error) {
+	e.mu.Lock()
+	e.requests = append(e.requests, req.Payload)
+	attempt := len(e.requests)
+	e.mu.Unlock()
+	arguments := `{\"name\":\"x\"}`
+	if attempt > 1 {
+		arguments = `{\"name\":\"x\",\"count\":2}`
+	}
+	return coreexecutor.Response{Payload: []byte(`{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"structured-model","choices":[{"index":0,"message":{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function","function":{"name":"json_response","arguments":"` + arguments + `"}}]},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":5,"completion_tokens":3,"total_tokens":8}}`)}, nil
+}
+
+func (e *structuredExecutor) ExecuteStream(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (<-chan coreexecutor.StreamChunk, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "streaming must be buffered"}
+}
+
+func (e *structuredExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *structuredExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, &coreauth.Error{Code: "not_implemented", Message: "CountTokens not implemented"}
+}
+
+func (e *structuredExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "HttpRequest not implemented", HTTPStatus: http.StatusNotImplemented}
+}
+
+func newStructuredRouter(t *testing.T) (*gin.Engine, *structuredExecutor) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	executor := &structuredExecutor{}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	auth := &coreauth.Auth{ID: "structured-auth", Provider: "claude", Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("register auth: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "structured-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+	cfg := &sdkconfig.SDKConfig{StructuredOutput: sdkconfig.StructuredOutputConfig{Enforce: true}}
+	h := NewOpenAIAPIHandler(handlers.NewBaseAPIHandlers(cfg, manager))
+	router := gin.New()
+	router.POST("/v1/chat/completions", h.ChatCompletions)
+	return router, executor
+}
+
+const structuredRequest = `{"model":"structured-model","messages":[{"role":"user","content":"count"}],"response_format":{"type":"json_schema","json_schema":{"name":"result","schema":{"type":"object","required":["name","count"],"properties":{"name":{"type":"string"},"count":{"type":"integer"}}}}}`
+
+func TestStructuredOutputRepairsViaForcedTool(t *testing.T) {
+	router, executor := newStructuredRouter(t)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(structuredRequest+`}`)))
+	body := rr.Body.String()
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rr.Code, body)
+	}
+	if len(executor.requests) != 2 {
+		t.Fatalf("expected one repair, got %d executions", len(executor.requests))
+	}
+	first := executor.requests[0]
+	if gjson.GetBytes(first, "response_format").Exists() || gjson.GetBytes(first, "tool_choice.function.name").String() != "json_response" {
+		t.Fatalf("JSON mode should be emulated through a forced tool: %s", first)
+	}
+	repair := executor.requests[1]
+	if !strings.Contains(gjson.GetBytes(repair, "messages.2.content").String(), `missing required property "count"`) {
+		t.Fatalf("repair turn lacks the validation problems: %s", repair)
+	}
+	if gjson.Get(body, "choices.0.message.content").String() != `{"name":"x","count":2}` || gjson.Get(body, "choices.0.message.tool_calls").Exists() {
+		t.Fatalf("unexpected final message: %s", body)
+	}
+	if gjson.Get(body, "choices.0.finish_reason").String() != "stop" || gjson.Get(body, "usage.total_tokens").Int() != 16 {
+		t.Fatalf("unexpected finish or usage: %s", body)
+	}
+}
+
+func TestStructuredOutputBuffersStreaming(t *testing.T) {
+	router, _ := newStructuredRouter(t)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(structuredRequest+`,"stream":true,"stream_options":{"include_usage":true}}`)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rr.Code, rr.Body.String())
+	}
+	var events []string
+	for _, line := range strings.Split(rr.Body.String(), "\n") {
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			events = append(events, data)
+		}
+	}
+	if len(events) != 4 || events[3] != "[DONE]" {
+		t.Fatalf("unexpected events: %v", events)
+	}
+	if gjson.Get(events[0], "choices.0.delta.content").String() != `{"name":"x","count":2}` {
+		t.Fatalf("unexpected content chunk: %s", events[0])
+	}
+	if gjson.Get(events[1], "choices.0.finish_reason").String() != "stop" || gjson.Get(events[2], "usage.total_tokens").Int() != 16 {
+		t.Fatalf("unexpected finish or usage chunks: %v", events)
+	}
+}
+
+func TestStripCodeFence(t *testing.T) {
+	cases := map[string]string{
+		"```json\n{\"a\":1}\n```": `{"a":1}`,
+		"```{\"a\":1}```":         `{"a":1}`,
+		`{"a":1}`:                 `{"a":1}`,
+	}
+	for in, want := range cases {
+		if got := stripCodeFence(in); got != want {
+			t.Fatalf("stripCodeFence(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestStructuredOutputToolForcingUsesVirtualModelTarget(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("forcing-claude", "claude", []*registry.ModelInfo{{ID: "forcing-claude-model"}})
+	reg.RegisterClient("forcing-gemini", "gemini", []*registry.ModelInfo{{ID: "forcing-gemini-model"}})
+	reg.RegisterClient("forcing-bedrock", "bedrock", []*registry.ModelInfo{{ID: "forcing-bedrock-model"}})
+	t.Cleanup(func() {
+		reg.UnregisterClient("forcing-claude")
+		reg.UnregisterClient("forcing-gemini")
+		reg.UnregisterClient("forcing-bedrock")
+	})
+
+	cfg := &sdkconfig.SDKConfig{
+		StructuredOutput: sdkconfig.StructuredOutputConfig{Enforce: true},
+		VirtualModels: []sdkconfig.VirtualModel{
+			{Name: "forcing-virtual-claude", Model: "forcing-claude-model"},
+			{Name: "forcing-virtual-gemini", Model: "forcing-gemini-model"},
+			{Name: "forcing-virtual-thinking", Model: "forcing-claude-model", Thinking: "high"},
+		},
+	}
+	h := NewOpenAIAPIHandler(handlers.NewBaseAPIHandlers(cfg, nil))
+	for model, want := range map[string]bool{
+		"forcing-claude-model":     true,
+		"forcing-gemini-model":     false,
+		"forcing-bedrock-model":    true,
+		"forcing-virtual-claude":   true,
+		"forcing-virtual-gemini":   false,
+		"forcing-virtual-thinking": false,
+	} {
+		so, err := h.structuredOutputFor([]byte(`{"model":"` + model + `","response_format":{"type":"json_object"}}`))
+		if err != nil || so == nil {
+			t.Fatalf("%s: structuredOutputFor = %v, %v", model, so, err)
+		}
+		if so.viaTool != want {
+			t.Fatalf("%s: viaTool = %v, want %v", model, so.viaTool, want)
+		}
+	}
+}
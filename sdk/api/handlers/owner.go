@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// RequestOwner identifies the client API key of the request for resources that are only
+// visible to their creator, such as stored responses and batches. The key is hashed so
+// persisted state never contains it in clear text; unauthenticated requests share the
+// empty owner.
+func RequestOwner(c *gin.Context) string {
+	return ownerOf(c.GetString("apiKey"))
+}
+
+// WithOwnerAPIKey returns a context that runs a request under the configured client API
+// key whose owner is owner, so per-key settings and usage attribution apply to work that
+// is executed outside the HTTP request that created it, such as batch lines. The context
+// is returned unchanged when the key is no longer configured.
+func (h *BaseAPIHandler) WithOwnerAPIKey(ctx context.Context, owner string) context.Context {
+	if apiKey, ok := h.ownerAPIKey(owner); ok && apiKey != "" {
+		return coreexecutor.WithClientAPIKey(ctx, apiKey)
+	}
+	return ctx
+}
+
+// ErrOwnerAPIKeyRemoved fails work queued by a client API key that is no longer configured.
+var ErrOwnerAPIKeyRemoved = errors.New("the API key that queued this request is no longer configured")
+
+// OwnerAPIKeyConfigured reports whether the client API key of owner is still accepted by
+// the live configuration, either in api-keys or among the inline keys of an auth provider.
+// Work queued by a key that an operator has since removed must not run. The empty owner
+// of unauthenticated requests is always accepted.
+func (h *BaseAPIHandler) OwnerAPIKeyConfigured(owner string) bool {
+	_, ok := h.ownerAPIKey(owner)
+	return ok
+}
+
+// ownerAPIKey looks up the configured client API key whose owner is owner.
+func (h *BaseAPIHandler) ownerAPIKey(owner string) (string, bool) {
+	if owner == "" {
+		return "", true
+	}
+	if h == nil || h.Cfg == nil {
+		return "", false
+	}
+	keys := h.Cfg.APIKeys
+	for i := range h.Cfg.Access.Providers {
+		keys = append(keys[:len(keys):len(keys)], h.Cfg.Access.Providers[i].APIKeys...)
+	}
+	for _, apiKey := range keys {
+		if ownerOf(apiKey) == owner {
+			return strings.TrimSpace(apiKey), true
+		}
+	}
+	return "", false
+}
+
+func ownerOf(apiKey string) string {
+	apiKey = strings.TrimSpace(apiKey)
+	if apiKey == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func TestOwnerAPIKeyFollowsLiveAccessConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set("apiKey", "provider-key")
+	owner := RequestOwner(c)
+
+	// The key is accepted by an auth provider, not by the top-level api-keys.
+	h := NewBaseAPIHandlers(&sdkconfig.SDKConfig{
+		APIKeys: []string{"other"},
+		Access: sdkconfig.AccessConfig{Providers: []sdkconfig.AccessProvider{{
+			Name:    "team",
+			Type:    sdkconfig.AccessProviderTypeConfigAPIKey,
+			APIKeys: []string{"provider-key"},
+		}}},
+	}, nil)
+	if !h.OwnerAPIKeyConfigured(owner) {
+		t.Fatal("provider key should be configured")
+	}
+	if got := coreexecutor.ClientAPIKey(h.WithOwnerAPIKey(context.Background(), owner)); got != "provider-key" {
+		t.Fatalf("api key = %q, want provider-key", got)
+	}
+
+	h.UpdateClients(&sdkconfig.SDKConfig{APIKeys: []string{"other"}})
+	if h.OwnerAPIKeyConfigured(owner) {
+		t.Fatal("removed key should no longer be configured")
+	}
+	if got := coreexecutor.ClientAPIKey(h.WithOwnerAPIKey(context.Background(), owner)); got != "" {
+		t.Fatalf("removed key resolved to %q", got)
+	}
+	if !h.OwnerAPIKeyConfigured("") {
+		t.Fatal("unauthenticated owner should always be accepted")
+	}
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// reasoningOutputPolicy resolves the reasoning output policy for a request: the client API
+// key override, then the first model override matching one of the model names, then the
+// global policy. Unset fields of an override inherit from the broader policy.
+func (h *BaseAPIHandler) reasoningOutputPolicy(ctx context.Context, modelNames ...string) thinking.OutputPolicy {
+	if h == nil || h.Cfg == nil {
+		return thinking.OutputPolicy{}
+	}
+	cfg := h.Cfg.ReasoningOutput
+	policy := cfg.ReasoningOutputPolicy
+
+	for _, rule := range cfg.Models {
+		if matchesAnyModel(rule.Name, modelNames) {
+			policy = overlayReasoningPolicy(policy, rule.ReasoningOutputPolicy)
+			break
+		}
+	}
+	if key := requestAPIKey(ctx); key != "" {
+	clients:
+		for _, client := range cfg.Clients {
+			for _, k := range client.APIKeys {
+				if strings.TrimSpace(k) == key {
+					policy = overlayReasoningPolicy(policy, client.ReasoningOutputPolicy)
+					break clients
+				}
+			}
+		}
+	}
+	return thinking.OutputPolicy{Mode: policy.Mode, Field: policy.Field, Tag: policy.Tag, SummaryChars: policy.SummaryChars}
+}
+
+func overlayReasoningPolicy(base, override config.ReasoningOutputPolicy) config.ReasoningOutputPolicy {
+	if override.Mode != "" {
+		base.Mode = override.Mode
+	}
+	if override.Field != "" {
+		base.Field = override.Field
+	}
+	if override.Tag != "" {
+		base.Tag = override.Tag
+	}
+	if override.SummaryChars > 0 {
+		base.SummaryChars = override.SummaryChars
+	}
+	return base
+}
+
+// requestAPIKey returns the client API key the request was authenticated with.
+func requestAPIKey(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if key := coreexecutor.ClientAPIKey(ctx); key != "" {
+		return strings.TrimSpace(key)
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil {
+		return ""
+	}
+	if v, exists := ginCtx.Get("apiKey"); exists {
+		if key, isString := v.(string); isString {
+			return strings.TrimSpace(key)
+		}
+	}
+	return ""
+}
+
+// matchesAnyModel reports whether the pattern matches one of the model names, ignoring
+// thinking suffixes. '*' matches any run of characters.
+func matchesAnyModel(pattern string, modelNames []string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "" {
+		return false
+	}
+	for _, name := range modelNames {
+		name = strings.ToLower(strings.TrimSpace(thinking.ParseSuffix(name).ModelName))
+		if name != "" && matchModelWildcard(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchModelWildcard(pattern, value string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == value
+	}
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(value, part)
+		if i < 0 {
+			return false
+		}
+		value = value[i+len(part):]
+	}
+	return strings.HasSuffix(value, last)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func TestReasoningOutputPolicyPrecedence(t *testing.T) {
+	h := NewBaseAPIHandlers(&sdkconfig.SDKConfig{ReasoningOutput: sdkconfig.ReasoningOutputConfig{
+		ReasoningOutputPolicy: sdkconfig.ReasoningOutputPolicy{Mode: "field", Field: "thoughts"},
+		Models: []sdkconfig.ReasoningOutputModel{{
+			Name:                  "deepseek-*",
+			ReasoningOutputPolicy: sdkconfig.ReasoningOutputPolicy{Mode: "tags"},
+		}},
+		Clients: []sdkconfig.ReasoningOutputClient{{
+			APIKeys:               []string{"strip-key"},
+			ReasoningOutputPolicy: sdkconfig.ReasoningOutputPolicy{Mode: "strip"},
+		}},
+	}}, nil)
+
+	withKey := func(key string) context.Context {
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		if key != "" {
+			c.Set("apiKey", key)
+		}
+		return context.WithValue(context.Background(), "gin", c)
+	}
+
+	cases := []struct {
+		name  string
+		key   string
+		model string
+		want  thinking.OutputPolicy
+	}{
+		{"global", "", "gpt-5", thinking.OutputPolicy{Mode: "field", Field: "thoughts"}},
+		{"model", "other", "deepseek-r1(high)", thinking.OutputPolicy{Mode: "tags", Field: "thoughts"}},
+		{"client", "strip-key", "deepseek-r1", thinking.OutputPolicy{Mode: "strip", Field: "thoughts"}},
+	}
+	for _, tc := range cases {
+		if got := h.reasoningOutputPolicy(withKey(tc.key), tc.model); got != tc.want {
+			t.Fatalf("%s: policy = %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestReasoningOutputPolicyForBatchOwner(t *testing.T) {
+	h := NewBaseAPIHandlers(&sdkconfig.SDKConfig{
+		APIKeys: []string{"other", "strip-key"},
+		ReasoningOutput: sdkconfig.ReasoningOutputConfig{
+			ReasoningOutputPolicy: sdkconfig.ReasoningOutputPolicy{Mode: "field", Field: "thoughts"},
+			Clients: []sdkconfig.ReasoningOutputClient{{
+				APIKeys:               []string{"strip-key"},
+				ReasoningOutputPolicy: sdkconfig.ReasoningOutputPolicy{Mode: "strip"},
+			}},
+		},
+	}, nil)
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set("apiKey", "strip-key")
+	owner := RequestOwner(c)
+
+	// Batch lines run on a background context without the originating gin context.
+	ctx := h.WithOwnerAPIKey(context.Background(), owner)
+	if got := requestAPIKey(ctx); got != "strip-key" {
+		t.Fatalf("api key = %q, want strip-key", got)
+	}
+	want := thinking.OutputPolicy{Mode: "strip", Field: "thoughts"}
+	if got := h.reasoningOutputPolicy(ctx, "gpt-5"); got != want {
+		t.Fatalf("policy = %+v, want %+v", got, want)
+	}
+	if got := requestAPIKey(h.WithOwnerAPIKey(context.Background(), "unknown")); got != "" {
+		t.Fatalf("unknown owner resolved to %q", got)
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// isContentChunk reports whether a translated stream chunk carries generated content
+// (text, reasoning, or tool call deltas) rather than only metadata such as role
+// announcements, usage, or lifecycle events. It understands the OpenAI chat/completions,
+// OpenAI Responses, Claude, and Gemini stream shapes.
+func isContentChunk(chunk []byte) bool {
+	for _, line := range bytes.Split(chunk, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if bytes.HasPrefix(line, []byte("data:")) {
+			line = bytes.TrimSpace(line[len("data:"):])
+		}
+		if len(line) == 0 || line[0] != '{' || !gjson.ValidBytes(line) {
+			continue
+		}
+		if payloadHasContent(gjson.ParseBytes(line)) {
+			return true
+		}
+	}
+	return false
+}
+
+func payloadHasContent(payload gjson.Result) bool {
+	if eventType := payload.Get("type").String(); eventType != "" {
+		switch {
+		case eventType == "content_block_delta":
+			return true
+		case strings.HasPrefix(eventType, "response.") && strings.HasSuffix(eventType, ".delta"):
+			return true
+		}
+	}
+
+	if choices := payload.Get("choices"); choices.IsArray() {
+		for _, choice := range choices.Array() {
+			delta := choice.Get("delta")
+			if delta.Get("content").String() != "" ||
+				delta.Get("reasoning_content").String() != "" ||
+				len(delta.Get("tool_calls").Array()) > 0 ||
+				choice.Get("text").String() != "" {
+				return true
+			}
+		}
+	}
+
+	candidates := payload.Get("candidates")
+	if !candidates.Exists() {
+		candidates = payload.Get("response.candidates")
+	}
+	for _, candidate := range candidates.Array() {
+		for _, part := range candidate.Get("content.parts").Array() {
+			if part.Get("text").String() != "" || part.Get("functionCall").Exists() || part.Get("inlineData").Exists() {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import "testing"
+
+func TestIsContentChunk(t *testing.T) {
+	cases := []struct {
+		name  string
+		chunk string
+		want  bool
+	}{
+		{"openai role only", `data: {"choices":[{"delta":{"role":"assistant"}}]}`, false},
+		{"openai content", `data: {"choices":[{"delta":{"content":"hi"}}]}`, true},
+		{"openai tool call", `data: {"choices":[{"delta":{"tool_calls":[{"index":0}]}}]}`, true},
+		{"claude message start", "event: message_start\ndata: {\"type\":\"message_start\"}", false},
+		{"claude delta", "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"text\":\"hi\"}}", true},
+		{"responses delta", `data: {"type":"response.output_text.delta","delta":"hi"}`, true},
+		{"gemini text", `{"candidates":[{"content":{"parts":[{"text":"hi"}]}}]}`, true},
+		{"gemini usage only", `{"usageMetadata":{"totalTokenCount":3}}`, false},
+		{"done marker", `data: [DONE]`, false},
+	}
+	for _, tc := range cases {
+		if got := isContentChunk([]byte(tc.chunk)); got != tc.want {
+			t.Errorf("%s: isContentChunk() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
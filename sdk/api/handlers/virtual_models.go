@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// findVirtualModel returns the configured virtual model with the given name, or nil.
+func findVirtualModel(cfg *config.SDKConfig, name string) *config.VirtualModel {
+	if cfg == nil {
+		return nil
+	}
+	for i := range cfg.VirtualModels {
+		if strings.EqualFold(cfg.VirtualModels[i].Name, name) {
+			return &cfg.VirtualModels[i]
+		}
+	}
+	return nil
+}
+
+// ResolveVirtualModel returns the model a request for modelName is served by: the target of
+// a virtual model, including its thinking suffix, or modelName itself.
+func (h *BaseAPIHandler) ResolveVirtualModel(modelName string) string {
+	resolved, _ := h.resolveVirtualModel(modelName)
+	return resolved
+}
+
+// applyVirtualModel resolves a virtual model name to its target model and rewrites the
+// request in its source format with the preset's overrides. Requests for other models are
+// returned unchanged. A thinking suffix on the requested name replaces the preset's.
+func (h *BaseAPIHandler) applyVirtualModel(handlerType, modelName string, rawJSON []byte) (string, []byte) {
+	resolved, vm := h.resolveVirtualModel(modelName)
+	if vm == nil {
+		return modelName, rawJSON
+	}
+	return resolved, applyVirtualModelOverrides(vm, handlerType, resolved, rawJSON)
+}
+
+// resolveVirtualModel returns the target model of a virtual model name and its preset, or
+// modelName and nil for other models.
+func (h *BaseAPIHandler) resolveVirtualModel(modelName string) (string, *config.VirtualModel) {
+	requested := thinking.ParseSuffix(modelName)
+	vm := findVirtualModel(h.Cfg, strings.TrimSpace(requested.ModelName))
+	if vm == nil {
+		return modelName, nil
+	}
+
+	target := thinking.ParseSuffix(vm.Model)
+	resolved := target.ModelName
+	switch {
+	case requested.HasSuffix:
+		resolved = fmt.Sprintf("%s(%s)", resolved, requested.RawSuffix)
+	case target.HasSuffix:
+		resolved = vm.Model
+	case vm.Thinking != "":
+		resolved = fmt.Sprintf("%s(%s)", resolved, vm.Thinking)
+	}
+	return resolved, vm
+}
+
+// applyVirtualModelOverrides writes the preset's overrides into a request of the given
+// source format.
+func applyVirtualModelOverrides(vm *config.VirtualModel, handlerType, model string, rawJSON []byte) []byte {
+	out := rawJSON
+	if gjson.GetBytes(out, "model").Exists() {
+		out, _ = sjson.SetBytes(out, "model", model)
+	}
+
+	prefix := ""
+	switch handlerType {
+	case constant.GeminiCLI:
+		prefix = "request."
+		fallthrough
+	case constant.Gemini:
+		if vm.Temperature != nil {
+			out, _ = sjson.SetBytes(out, prefix+"generationConfig.temperature", *vm.Temperature)
+		}
+		if vm.MaxTokens > 0 {
+			out, _ = sjson.SetBytes(out, prefix+"generationConfig.maxOutputTokens", vm.MaxTokens)
+		}
+		if vm.SystemPrompt != "" {
+			path := prefix + "systemInstruction"
+			if gjson.GetBytes(out, prefix+"system_instruction").Exists() {
+				path = prefix + "system_instruction"
+			}
+			out = prependArray(out, path+".parts", fmt.Sprintf(`{"text":%s}`, quoteJSON(vm.SystemPrompt)))
+		}
+		out = restrictGeminiTools(out, prefix+"tools", vm)
+
+	case constant.Claude:
+		if vm.Temperature != nil {
+			out, _ = sjson.SetBytes(out, "temperature", *vm.Temperature)
+		}
+		if vm.MaxTokens > 0 {
+			out, _ = sjson.SetBytes(out, "max_tokens", vm.MaxTokens)
+		}
+		if vm.SystemPrompt != "" {
+			system := gjson.GetBytes(out, "system")
+			if system.IsArray() {
+				out = prependArray(out, "system", fmt.Sprintf(`{"type":"text","text":%s}`, quoteJSON(vm.SystemPrompt)))
+			} else {
+				out, _ = sjson.SetBytes(out, "system", joinPrompt(vm.SystemPrompt, system.String()))
+			}
+		}
+		out = restrictTools(out, "tools", func(tool gjson.Result) string { return tool.Get("name").String() }, vm)
+		if vm.DisableTools || !gjson.GetBytes(out, "tools").Exists() {
+			out, _ = sjson.DeleteBytes(out, "tool_choice")
+		} else if name := gjson.GetBytes(out, "tool_choice.name").String(); name != "" && !toolAllowed(vm, name) {
+			out, _ = sjson.DeleteBytes(out, "tool_choice")
+		}
+
+	case constant.OpenaiResponse:
+		if vm.Temperature != nil {
+			out, _ = sjson.SetBytes(out, "temperature", *vm.Temperature)
+		}
+		if vm.MaxTokens > 0 {
+			out, _ = sjson.SetBytes(out, "max_output_tokens", vm.MaxTokens)
+		}
+		if vm.SystemPrompt != "" {
+			out, _ = sjson.SetBytes(out, "instructions", joinPrompt(vm.SystemPrompt, gjson.GetBytes(out, "instructions").String()))
+		}
+		out = restrictTools(out, "tools", func(tool gjson.Result) string {
+			if name := tool.Get("name").String(); name != "" {
+				return name
+			}
+			return tool.Get("type").String()
+		}, vm)
+		out = restrictOpenAIToolChoice(out, "tool_choice.name", vm)
+
+	default:
+		// OpenAI chat completions and legacy completions.
+		if vm.Temperature != nil {
+			out, _ = sjson.SetBytes(out, "temperature", *vm.Temperature)
+		}
+		if vm.MaxTokens > 0 {
+			if gjson.GetBytes(out, "max_completion_tokens").Exists() {
+				out, _ = sjson.SetBytes(out, "max_completion_tokens", vm.MaxTokens)
+			} else {
+				out, _ = sjson.SetBytes(out, "max_tokens", vm.MaxTokens)
+			}
+		}
+		if vm.SystemPrompt != "" {
+			if gjson.GetBytes(out, "messages").IsArray() {
+				out = prependArray(out, "messages", fmt.Sprintf(`{"role":"system","content":%s}`, quoteJSON(vm.SystemPrompt)))
+			} else {
+				out = prependPrompt(out, vm.SystemPrompt)
+			}
+		}
+		out = restrictTools(out, "tools", func(tool gjson.Result) string { return tool.Get("function.name").String() }, vm)
+		out = restrictOpenAIToolChoice(out, "tool_choice.function.name", vm)
+	}
+	return out
+}
+
+// restrictTools filters the tools array at path to the preset's allowed tools. The array
+// is removed when no tool remains.
+func restrictTools(rawJSON []byte, path string, nameOf func(gjson.Result) string, vm *config.VirtualModel) []byte {
+	tools := gjson.GetBytes(rawJSON, path)
+	if !tools.Exists() || (!vm.DisableTools && len(vm.AllowedTools) == 0) {
+		return rawJSON
+	}
+	kept := make([]string, 0)
+	if !vm.DisableTools {
+		for _, tool := range tools.Array() {
+			if toolAllowed(vm, nameOf(tool)) {
+				kept = append(kept, tool.Raw)
+			}
+		}
+	}
+	if len(kept) == 0 {
+		out, _ := sjson.DeleteBytes(rawJSON, path)
+		return out
+	}
+	out, _ := sjson.SetRawBytes(rawJSON, path, []byte("["+strings.Join(kept, ",")+"]"))
+	return out
+}
+
+// restrictGeminiTools filters the function declarations of Gemini tools. Tools without
+// function declarations, such as googleSearch, are kept only when named in the allow list.
+func restrictGeminiTools(rawJSON []byte, path string, vm *config.VirtualModel) []byte {
+	tools := gjson.GetBytes(rawJSON, path)
+	if !tools.Exists() || (!vm.DisableTools && len(vm.AllowedTools) == 0) {
+		return rawJSON
+	}
+	kept := make([]string, 0)
+	if !vm.DisableTools {
+		for _, tool := range tools.Array() {
+			key := "functionDeclarations"
+			if !tool.Get(key).Exists() {
+				key = "function_declarations"
+			}
+			declarations := tool.Get(key)
+			if !declarations.Exists() {
+				allowed := true
+				tool.ForEach(func(name, _ gjson.Result) bool {
+					allowed = allowed && toolAllowed(vm, name.String())
+					return allowed
+				})
+				if allowed {
+					kept = append(kept, tool.Raw)
+				}
+				continue
+			}
+			filtered := restrictTools([]byte(tool.Raw), key, func(fn gjson.Result) string { return fn.Get("name").String() }, vm)
+			if gjson.GetBytes(filtered, key).Exists() {
+				kept = append(kept, string(filtered))
+			}
+		}
+	}
+	if len(kept) == 0 {
+		out, _ := sjson.DeleteBytes(rawJSON, path)
+		return out
+	}
+	out, _ := sjson.SetRawBytes(rawJSON, path, []byte("["+strings.Join(kept, ",")+"]"))
+	return out
+}
+
+// restrictOpenAIToolChoice drops an OpenAI tool_choice that names a removed tool or that
+// requires tools when none remain.
+func restrictOpenAIToolChoice(rawJSON []byte, namePath string, vm *config.VirtualModel) []byte {
+	choice := gjson.GetBytes(rawJSON, "tool_choice")
+	if !choice.Exists() {
+		return rawJSON
+	}
+	if !gjson.GetBytes(rawJSON, "tools").Exists() && choice.String() != "none" {
+		out, _ := sjson.DeleteBytes(rawJSON, "tool_choice")
+		return out
+	}
+	if name := gjson.GetBytes(rawJSON, namePath).String(); name != "" && !toolAllowed(vm, name) {
+		out, _ := sjson.DeleteBytes(rawJSON, "tool_choice")
+		return out
+	}
+	return rawJSON
+}
+
+func toolAllowed(vm *config.VirtualModel, name string) bool {
+	if vm.DisableTools {
+		return false
+	}
+	if len(vm.AllowedTools) == 0 {
+		return true
+	}
+	for _, allowed := range vm.AllowedTools {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// prependArray inserts a raw JSON element at the start of the array at path, creating
+// the array when missing.
+func prependArray(rawJSON []byte, path, element string) []byte {
+	items := []string{element}
+	for _, item := range gjson.GetBytes(rawJSON, path).Array() {
+		items = append(items, item.Raw)
+	}
+	out, _ := sjson.SetRawBytes(rawJSON, path, []byte("["+strings.Join(items, ",")+"]"))
+	return out
+}
+
+// prependPrompt writes the system prompt ahead of a legacy completions prompt, which is a
+// string or an array of strings, one completion each.
+func prependPrompt(rawJSON []byte, systemPrompt string) []byte {
+	prompt := gjson.GetBytes(rawJSON, "prompt")
+	switch {
+	case prompt.Type == gjson.String:
+		rawJSON, _ = sjson.SetBytes(rawJSON, "prompt", joinPrompt(systemPrompt, prompt.String()))
+	case prompt.IsArray():
+		for i, item := range prompt.Array() {
+			if item.Type == gjson.String {
+				rawJSON, _ = sjson.SetBytes(rawJSON, fmt.Sprintf("prompt.%d", i), joinPrompt(systemPrompt, item.String()))
+			}
+		}
+	}
+	return rawJSON
+}
+
+func joinPrompt(prompt, existing string) string {
+	if strings.TrimSpace(existing) == "" {
+		return prompt
+	}
+	return prompt + "\n\n" + existing
+}
+
+func quoteJSON(s string) string {
+	out, _ := sjson.Set(`{}`, "v", s)
+	return gjson.Get(out, "v").Raw
+}
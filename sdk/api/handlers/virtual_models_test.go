@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"testing"
+
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func newVirtualModelHandler() *BaseAPIHandler {
+	temperature := 0.2
+	return NewBaseAPIHandlers(&sdkconfig.SDKConfig{VirtualModels: []sdkconfig.VirtualModel{{
+		Name:         "review-bot",
+		Model:        "gpt-5",
+		Thinking:     "high",
+		Temperature:  &temperature,
+		MaxTokens:    512,
+		SystemPrompt: "Review carefully.",
+		AllowedTools: []string{"read_file"},
+	}}}, nil)
+}
+
+func TestApplyVirtualModelResolvesTarget(t *testing.T) {
+	h := newVirtualModelHandler()
+	cases := map[string]string{
+		"review-bot":      "gpt-5(high)",
+		"Review-Bot(low)": "gpt-5(low)",
+		"gpt-5":           "gpt-5",
+	}
+	for requested, want := range cases {
+		if got, _ := h.applyVirtualModel("openai", requested, []byte(`{}`)); got != want {
+			t.Fatalf("%s resolved to %s, want %s", requested, got, want)
+		}
+	}
+}
+
+func TestApplyVirtualModelOverridesPerFormat(t *testing.T) {
+	h := newVirtualModelHandler()
+	cases := []struct {
+		format string
+		in     string
+		checks map[string]string
+	}{
+		{
+			format: "openai",
+			in:     `{"model":"review-bot","temperature":1,"max_completion_tokens":10,"messages":[{"role":"user","content":"hi"}],"tools":[{"type":"function","function":{"name":"read_file"}},{"type":"function","function":{"name":"rm"}}],"tool_choice":{"type":"function","function":{"name":"rm"}}}`,
+			checks: map[string]string{
+				"model": "gpt-5(high)", "temperature": "0.2", "max_completion_tokens": "512",
+				"messages.0.content": "Review carefully.", "messages.1.content": "hi",
+				"tools.#": "1", "tools.0.function.name": "read_file", "tool_choice": "",
+			},
+		},
+		{
+			format: "openai",
+			in:     `{"model":"review-bot","prompt":"Say hi"}`,
+			checks: map[string]string{
+				"model": "gpt-5(high)", "prompt": "Review carefully.\n\nSay hi", "messages": "",
+			},
+		},
+		{
+			format: "openai",
+			in:     `{"model":"review-bot","prompt":["one","two"]}`,
+			checks: map[string]string{
+				"prompt.0": "Review carefully.\n\none", "prompt.1": "Review carefully.\n\ntwo",
+			},
+		},
+		{
+			format: "openai-response",
+			in:     `{"model":"review-bot","instructions":"Be nice.","input":"hi","tools":[{"type":"function","name":"rm"},{"type":"web_search"}]}`,
+			checks: map[string]string{
+				"instructions": "Review carefully.\n\nBe nice.", "max_output_tokens": "512", "tools": "",
+			},
+		},
+		{
+			format: "claude",
+			in:     `{"model":"review-bot","max_tokens":4096,"system":[{"type":"text","text":"Be nice."}],"messages":[],"tools":[{"name":"read_file"},{"name":"rm"}],"tool_choice":{"type":"tool","name":"rm"}}`,
+			checks: map[string]string{
+				"max_tokens": "512", "system.0.text": "Review carefully.", "system.1.text": "Be nice.",
+				"tools.#": "1", "tool_choice": "",
+			},
+		},
+		{
+			format: "gemini",
+			in:     `{"contents":[],"tools":[{"functionDeclarations":[{"name":"read_file"},{"name":"rm"}]},{"googleSearch":{}}]}`,
+			checks: map[string]string{
+				"generationConfig.temperature": "0.2", "generationConfig.maxOutputTokens": "512",
+				"systemInstruction.parts.0.text": "Review carefully.",
+				"tools.#":                        "1", "tools.0.functionDeclarations.#": "1",
+			},
+		},
+		{
+			format: "gemini-cli",
+			in:     `{"model":"review-bot","request":{"contents":[]}}`,
+			checks: map[string]string{
+				"model": "gpt-5(high)", "request.generationConfig.maxOutputTokens": "512",
+				"request.systemInstruction.parts.0.text": "Review carefully.",
+			},
+		},
+	}
+	for _, tc := range cases {
+		_, out := h.applyVirtualModel(tc.format, "review-bot", []byte(tc.in))
+		for path, want := range tc.checks {
+			if got := gjson.GetBytes(out, path).String(); got != want {
+				t.Fatalf("%s: %s = %q, want %q in %s", tc.format, path, got, want, out)
+			}
+		}
+	}
+}
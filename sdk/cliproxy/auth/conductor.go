@@ -20,6 +20,7 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/events"
 	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
 	log "github.com/sirupsen/logrus"
 )
@@ -292,6 +293,10 @@ func (m *Manager) rebuildAPIKeyModelAliasLocked(cfg *internalconfig.Config) {
 			if entry := resolveClaudeAPIKeyConfig(cfg, auth); entry != nil {
 				compileAPIKeyModelAliasForModels(byAlias, entry.Models)
 			}
+		case "bedrock":
+			if entry := resolveBedrockAPIKeyConfig(cfg, auth); entry != nil {
+				compileAPIKeyModelAliasForModels(byAlias, entry.Models)
+			}
 		case "codex":
 			if entry := resolveCodexAPIKeyConfig(cfg, auth); entry != nil {
 				compileAPIKeyModelAliasForModels(byAlias, entry.Models)
@@ -420,6 +425,7 @@ func (m *Manager) Register(ctx context.Context, auth *Auth) (*Auth, error) {
 	m.rebuildAPIKeyModelAliasFromRuntimeConfig()
 	_ = m.persist(ctx, auth)
 	m.hook.OnAuthRegistered(ctx, auth.Clone())
+	PublishAuthEvent(EventAuthRegistered, auth)
 	return auth.Clone(), nil
 }
 
@@ -439,6 +445,7 @@ func (m *Manager) Update(ctx context.Context, auth *Auth) (*Auth, error) {
 	m.rebuildAPIKeyModelAliasFromRuntimeConfig()
 	_ = m.persist(ctx, auth)
 	m.hook.OnAuthUpdated(ctx, auth.Clone())
+	PublishAuthEvent(EventAuthUpdated, auth)
 	return auth.Clone(), nil
 }
 
@@ -826,6 +833,8 @@ func (m *Manager) applyAPIKeyModelAlias(auth *Auth, requestedModel string) strin
 		upstreamModel = resolveUpstreamModelForGeminiAPIKey(cfg, auth, requestedModel)
 	case "claude":
 		upstreamModel = resolveUpstreamModelForClaudeAPIKey(cfg, auth, requestedModel)
+	case "bedrock":
+		upstreamModel = resolveUpstreamModelForBedrockAPIKey(cfg, auth, requestedModel)
 	case "codex":
 		upstreamModel = resolveUpstreamModelForCodexAPIKey(cfg, auth, requestedModel)
 	case "vertex":
@@ -900,6 +909,13 @@ func resolveClaudeAPIKeyConfig(cfg *internalconfig.Config, auth *Auth) *internal
 	return resolveAPIKeyConfig(cfg.ClaudeKey, auth)
 }
 
+func resolveBedrockAPIKeyConfig(cfg *internalconfig.Config, auth *Auth) *internalconfig.BedrockKey {
+	if cfg == nil {
+		return nil
+	}
+	return resolveAPIKeyConfig(cfg.BedrockKey, auth)
+}
+
 func resolveCodexAPIKeyConfig(cfg *internalconfig.Config, auth *Auth) *internalconfig.CodexKey {
 	if cfg == nil {
 		return nil
@@ -930,6 +946,14 @@ func resolveUpstreamModelForClaudeAPIKey(cfg *internalconfig.Config, auth *Auth,
 	return resolveModelAliasFromConfigModels(requestedModel, asModelAliasEntries(entry.Models))
 }
 
+func resolveUpstreamModelForBedrockAPIKey(cfg *internalconfig.Config, auth *Auth, requestedModel string) string {
+	entry := resolveBedrockAPIKeyConfig(cfg, auth)
+	if entry == nil {
+		return ""
+	}
+	return resolveModelAliasFromConfigModels(requestedModel, asModelAliasEntries(entry.Models))
+}
+
 func resolveUpstreamModelForCodexAPIKey(cfg *internalconfig.Config, auth *Auth, requestedModel string) string {
 	entry := resolveCodexAPIKeyConfig(cfg, auth)
 	if entry == nil {
@@ -1130,6 +1154,7 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 	suspendReason := ""
 	clearModelQuota := false
 	setModelQuota := false
+	var cooldown *cooldownChange
 
 	m.mu.Lock()
 	if auth, ok := m.auths[result.AuthID]; ok && auth != nil {
@@ -1138,6 +1163,9 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 		if result.Success {
 			if result.Model != "" {
 				state := ensureModelState(auth, result.Model)
+				if state.Unavailable {
+					cooldown = &cooldownChange{eventType: EventCooldownEnded, data: events.CooldownData{AuthID: auth.ID, Provider: auth.Provider, Model: result.Model}}
+				}
 				resetModelState(state, now)
 				updateAggregatedAvailability(auth, now)
 				if !hasModelError(auth, now) {
@@ -1149,6 +1177,9 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 				shouldResumeModel = true
 				clearModelQuota = true
 			} else {
+				if auth.Unavailable {
+					cooldown = &cooldownChange{eventType: EventCooldownEnded, data: events.CooldownData{AuthID: auth.ID, Provider: auth.Provider}}
+				}
 				clearAuthStateOnSuccess(auth, now)
 			}
 		} else {
@@ -1217,8 +1248,28 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 				auth.Status = StatusError
 				auth.UpdatedAt = now
 				updateAggregatedAvailability(auth, now)
+				if state.NextRetryAfter.After(now) {
+					cooldown = &cooldownChange{eventType: EventCooldownStarted, data: events.CooldownData{
+						AuthID:     auth.ID,
+						Provider:   auth.Provider,
+						Model:      result.Model,
+						Reason:     cooldownReason(suspendReason, statusCode),
+						StatusCode: statusCode,
+						Until:      state.NextRetryAfter,
+					}}
+				}
 			} else {
 				applyAuthFailureState(auth, result.Error, result.RetryAfter, now)
+				if auth.NextRetryAfter.After(now) {
+					statusCode := statusCodeFromResult(result.Error)
+					cooldown = &cooldownChange{eventType: EventCooldownStarted, data: events.CooldownData{
+						AuthID:     auth.ID,
+						Provider:   auth.Provider,
+						Reason:     cooldownReason("", statusCode),
+						StatusCode: statusCode,
+						Until:      auth.NextRetryAfter,
+					}}
+				}
 			}
 		}
 
@@ -1237,6 +1288,7 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 	} else if shouldSuspendModel {
 		registry.GetGlobalRegistry().SuspendClientModel(result.AuthID, result.Model, suspendReason)
 	}
+	cooldown.publish()
 
 	m.hook.OnResult(ctx, result)
 }
@@ -1990,6 +2042,7 @@ func (m *Manager) refreshAuth(ctx context.Context, id string) {
 			m.auths[id] = current
 		}
 		m.mu.Unlock()
+		publishRefreshEvent(auth, err, now.Add(refreshFailureBackoff))
 		return
 	}
 	if updated == nil {
@@ -2007,6 +2060,7 @@ func (m *Manager) refreshAuth(ctx context.Context, id string) {
 	updated.LastError = nil
 	updated.UpdatedAt = now
 	_, _ = m.Update(ctx, updated)
+	publishRefreshEvent(updated, nil, time.Time{})
 }
 
 func (m *Manager) executorFor(provider string) ProviderExecutor {
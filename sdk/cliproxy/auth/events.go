@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/events"
+)
+
+// Event types published by the manager.
+const (
+	EventAuthRegistered  = "registered"
+	EventAuthUpdated     = "updated"
+	EventAuthRemoved     = "removed"
+	EventRefreshSuccess  = "succeeded"
+	EventRefreshFailure  = "failed"
+	EventCooldownStarted = "started"
+	EventCooldownEnded   = "ended"
+)
+
+// PublishAuthEvent publishes an auth lifecycle event for a on the default event bus.
+func PublishAuthEvent(eventType string, a *Auth) {
+	if a == nil || !events.HasSubscribers(events.TopicAuth) {
+		return
+	}
+	data := events.AuthData{
+		ID:          a.ID,
+		Provider:    a.Provider,
+		Label:       a.Label,
+		Index:       a.Index,
+		Status:      string(a.Status),
+		Message:     a.StatusMessage,
+		Disabled:    a.Disabled,
+		Unavailable: a.Unavailable,
+	}
+	events.Publish(events.TopicAuth, eventType, data)
+}
+
+func publishRefreshEvent(a *Auth, err error, nextRetry time.Time) {
+	if a == nil || !events.HasSubscribers(events.TopicRefresh) {
+		return
+	}
+	data := events.RefreshData{AuthID: a.ID, Provider: a.Provider}
+	eventType := EventRefreshSuccess
+	if err != nil {
+		eventType = EventRefreshFailure
+		data.Error = err.Error()
+		data.NextRetry = nextRetry
+	}
+	events.Publish(events.TopicRefresh, eventType, data)
+}
+
+// cooldownChange records a cooldown transition detected while the manager lock is held
+// so it can be published after the lock is released.
+type cooldownChange struct {
+	eventType string
+	data      events.CooldownData
+}
+
+func (c *cooldownChange) publish() {
+	if c == nil {
+		return
+	}
+	events.Publish(events.TopicCooldown, c.eventType, c.data)
+}
+
+func cooldownReason(suspendReason string, statusCode int) string {
+	if suspendReason != "" {
+		return suspendReason
+	}
+	switch statusCode {
+	case 401:
+		return "unauthorized"
+	case 402, 403:
+		return "payment_required"
+	case 404:
+		return "not_found"
+	case 429:
+		return "quota"
+	default:
+		return "transient"
+	}
+}
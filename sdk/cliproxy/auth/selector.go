@@ -19,12 +19,15 @@ import (
 type RoundRobinSelector struct {
 	mu      sync.Mutex
 	cursors map[string]int
+	quality streamQualityTracker
 }
 
 // FillFirstSelector selects the first available credential (deterministic ordering).
 // This "burns" one account before moving to the next, which can help stagger
 // rolling-window subscription caps (e.g. chat message limits).
-type FillFirstSelector struct{}
+type FillFirstSelector struct {
+	quality streamQualityTracker
+}
 
 type blockReason int
 
@@ -185,6 +188,7 @@ func (s *RoundRobinSelector) Pick(ctx context.Context, provider, model string, o
 	if err != nil {
 		return nil, err
 	}
+	available = s.quality.preferResponsive(available, model, now)
 	key := provider + ":" + model
 	s.mu.Lock()
 	if s.cursors == nil {
@@ -211,6 +215,7 @@ func (s *FillFirstSelector) Pick(ctx context.Context, provider, model string, op
 	if err != nil {
 		return nil, err
 	}
+	available = s.quality.preferResponsive(available, model, now)
 	return available[0], nil
 }
 
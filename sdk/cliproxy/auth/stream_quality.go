@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+const (
+	// streamQualityAlpha is the EWMA weight given to the newest observation.
+	streamQualityAlpha = 0.3
+	// streamQualityDegradedFactor marks an auth degraded when its smoothed time to first
+	// content exceeds the fastest available auth by this factor.
+	streamQualityDegradedFactor = 3.0
+	// streamQualityMinDegraded avoids demoting auths that are only slow in relative terms.
+	streamQualityMinDegraded = 5 * time.Second
+	// streamQualityMaxAge discards stale observations so demoted auths get retried eventually.
+	streamQualityMaxAge = 10 * time.Minute
+)
+
+// StreamMetricsObserver is implemented by selectors that use streaming latency as a
+// quality signal when choosing between otherwise equivalent credentials.
+type StreamMetricsObserver interface {
+	ObserveStreamMetrics(authID, model string, metrics coreusage.StreamMetrics)
+}
+
+// ReportStreamMetrics forwards streaming latency measurements to the selector when it
+// implements StreamMetricsObserver.
+func (m *Manager) ReportStreamMetrics(authID, model string, metrics coreusage.StreamMetrics) {
+	if m == nil || authID == "" {
+		return
+	}
+	m.mu.RLock()
+	selector := m.selector
+	m.mu.RUnlock()
+	if observer, ok := selector.(StreamMetricsObserver); ok {
+		observer.ObserveStreamMetrics(authID, model, metrics)
+	}
+}
+
+type streamQualityEntry struct {
+	latency time.Duration
+	updated time.Time
+}
+
+// streamQualityKey identifies one model served by one auth; a slow model must not
+// demote the auth for the other models it serves.
+type streamQualityKey struct {
+	authID string
+	model  string
+}
+
+func newStreamQualityKey(authID, model string) streamQualityKey {
+	model = strings.TrimSpace(model)
+	if parsed := thinking.ParseSuffix(model); parsed.ModelName != "" {
+		model = parsed.ModelName
+	}
+	return streamQualityKey{authID: authID, model: strings.ToLower(model)}
+}
+
+// streamQualityTracker keeps a smoothed time-to-first-content per auth and model and
+// filters out degraded auths from a candidate list. The zero value is ready to use.
+type streamQualityTracker struct {
+	mu      sync.Mutex
+	entries map[streamQualityKey]streamQualityEntry
+}
+
+func (t *streamQualityTracker) observe(authID, model string, metrics coreusage.StreamMetrics) {
+	latency := metrics.TimeToFirstContent
+	if latency <= 0 {
+		latency = metrics.TimeToFirstByte
+	}
+	if authID == "" || latency <= 0 {
+		return
+	}
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.entries == nil {
+		t.entries = make(map[streamQualityKey]streamQualityEntry)
+	}
+	key := newStreamQualityKey(authID, model)
+	entry, ok := t.entries[key]
+	if !ok || now.Sub(entry.updated) > streamQualityMaxAge {
+		entry.latency = latency
+	} else {
+		entry.latency = time.Duration(streamQualityAlpha*float64(latency) + (1-streamQualityAlpha)*float64(entry.latency))
+	}
+	entry.updated = now
+	t.entries[key] = entry
+}
+
+// preferResponsive drops auths that are degraded for model from available while always
+// keeping at least one.
+func (t *streamQualityTracker) preferResponsive(available []*Auth, model string, now time.Time) []*Auth {
+	if len(available) < 2 {
+		return available
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.entries) == 0 {
+		return available
+	}
+
+	latencies := make([]time.Duration, len(available))
+	var fastest time.Duration
+	for i, candidate := range available {
+		entry, ok := t.entries[newStreamQualityKey(candidate.ID, model)]
+		if !ok || now.Sub(entry.updated) > streamQualityMaxAge {
+			continue
+		}
+		latencies[i] = entry.latency
+		if fastest == 0 || entry.latency < fastest {
+			fastest = entry.latency
+		}
+	}
+	if fastest == 0 {
+		return available
+	}
+
+	threshold := time.Duration(streamQualityDegradedFactor * float64(fastest))
+	if threshold < streamQualityMinDegraded {
+		threshold = streamQualityMinDegraded
+	}
+	responsive := make([]*Auth, 0, len(available))
+	for i, candidate := range available {
+		if latencies[i] > threshold {
+			continue
+		}
+		responsive = append(responsive, candidate)
+	}
+	if len(responsive) == 0 {
+		return available
+	}
+	return responsive
+}
+
+// ObserveStreamMetrics implements StreamMetricsObserver.
+func (s *RoundRobinSelector) ObserveStreamMetrics(authID, model string, metrics coreusage.StreamMetrics) {
+	s.quality.observe(authID, model, metrics)
+}
+
+// ObserveStreamMetrics implements StreamMetricsObserver.
+func (s *FillFirstSelector) ObserveStreamMetrics(authID, model string, metrics coreusage.StreamMetrics) {
+	s.quality.observe(authID, model, metrics)
+}
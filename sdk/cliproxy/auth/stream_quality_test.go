@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+func TestFillFirstSelectorPick_SkipsDegradedStreamingAuth(t *testing.T) {
+	t.Parallel()
+
+	selector := &FillFirstSelector{}
+	auths := []*Auth{{ID: "a"}, {ID: "b"}}
+
+	selector.ObserveStreamMetrics("a", "gemini-2.5-pro", coreusage.StreamMetrics{TimeToFirstContent: 30 * time.Second})
+	selector.ObserveStreamMetrics("b", "gemini-2.5-pro", coreusage.StreamMetrics{TimeToFirstContent: 800 * time.Millisecond})
+
+	got, err := selector.Pick(context.Background(), "gemini", "gemini-2.5-pro", cliproxyexecutor.Options{}, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if got.ID != "b" {
+		t.Fatalf("Pick() auth.ID = %q, want %q", got.ID, "b")
+	}
+}
+
+func TestRoundRobinSelectorPick_KeepsAuthsWithinLatencyThreshold(t *testing.T) {
+	t.Parallel()
+
+	selector := &RoundRobinSelector{}
+	auths := []*Auth{{ID: "a"}, {ID: "b"}}
+
+	selector.ObserveStreamMetrics("a", "", coreusage.StreamMetrics{TimeToFirstContent: 2 * time.Second})
+	selector.ObserveStreamMetrics("b", "", coreusage.StreamMetrics{TimeToFirstContent: 500 * time.Millisecond})
+
+	seen := make(map[string]bool)
+	for i := 0; i < 4; i++ {
+		got, err := selector.Pick(context.Background(), "gemini", "", cliproxyexecutor.Options{}, auths)
+		if err != nil {
+			t.Fatalf("Pick() #%d error = %v", i, err)
+		}
+		seen[got.ID] = true
+	}
+	if !seen["a"] || !seen["b"] {
+		t.Fatalf("expected both auths to rotate, saw %v", seen)
+	}
+}
+
+func TestFillFirstSelectorPick_DegradationIsPerModel(t *testing.T) {
+	t.Parallel()
+
+	selector := &FillFirstSelector{}
+	auths := []*Auth{{ID: "a"}, {ID: "b"}}
+
+	// Auth "a" is slow for the pro model only; it stays first choice for flash.
+	selector.ObserveStreamMetrics("a", "gemini-2.5-pro", coreusage.StreamMetrics{TimeToFirstContent: 30 * time.Second})
+	selector.ObserveStreamMetrics("b", "gemini-2.5-pro", coreusage.StreamMetrics{TimeToFirstContent: 800 * time.Millisecond})
+	selector.ObserveStreamMetrics("a", "gemini-2.5-flash", coreusage.StreamMetrics{TimeToFirstContent: 400 * time.Millisecond})
+	selector.ObserveStreamMetrics("b", "gemini-2.5-flash", coreusage.StreamMetrics{TimeToFirstContent: 500 * time.Millisecond})
+
+	for model, want := range map[string]string{
+		"gemini-2.5-pro":          "b",
+		"gemini-2.5-pro(high)":    "b",
+		"gemini-2.5-flash":        "a",
+		"gemini-2.0-flash-unseen": "a",
+	} {
+		got, err := selector.Pick(context.Background(), "gemini", model, cliproxyexecutor.Options{}, auths)
+		if err != nil {
+			t.Fatalf("Pick(%s) error = %v", model, err)
+		}
+		if got.ID != want {
+			t.Fatalf("Pick(%s) auth.ID = %q, want %q", model, got.ID, want)
+		}
+	}
+}
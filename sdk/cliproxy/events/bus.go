@@ -0,0 +1,175 @@
+// Package events provides a lightweight publish/subscribe bus for runtime events such as
+// credential state changes, refresh results, cooldowns, config reloads and request
+// summaries. Publishing never blocks: each subscriber owns a bounded buffer and events
+// that do not fit are dropped and counted.
+package events
+
+import (
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Topics published by the proxy runtime.
+const (
+	TopicAuth     = "auth"
+	TopicRefresh  = "refresh"
+	TopicCooldown = "cooldown"
+	TopicConfig   = "config"
+	TopicRequest  = "request"
+)
+
+// DefaultBufferSize is the per-subscriber buffer used when SubscribeOptions.Buffer is not set.
+const DefaultBufferSize = 256
+
+// Event is a single published event.
+type Event struct {
+	ID    uint64    `json:"id"`
+	Topic string    `json:"topic"`
+	Type  string    `json:"type"`
+	Time  time.Time `json:"time"`
+	Data  any       `json:"data,omitempty"`
+}
+
+// SubscribeOptions configures a subscription.
+type SubscribeOptions struct {
+	// Topics limits delivery to the listed topics; empty means all topics.
+	Topics []string
+	// Buffer is the maximum number of undelivered events kept for the subscriber.
+	Buffer int
+	// RequestSampleRate is the fraction (0, 1] of request summaries delivered; 0 means all.
+	RequestSampleRate float64
+}
+
+// Subscription receives events published on a Bus.
+type Subscription struct {
+	bus        *Bus
+	topics     map[string]struct{}
+	sampleRate float64
+	ch         chan Event
+	dropped    atomic.Uint64
+	closeOnce  sync.Once
+}
+
+// Bus fans out published events to subscribers.
+type Bus struct {
+	mu     sync.RWMutex
+	subs   map[*Subscription]struct{}
+	active atomic.Int64
+	seq    atomic.Uint64
+}
+
+// NewBus constructs an empty bus.
+func NewBus() *Bus {
+	return &Bus{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscribe registers a new subscriber. Callers must Close the subscription when done.
+func (b *Bus) Subscribe(opts SubscribeOptions) *Subscription {
+	buffer := opts.Buffer
+	if buffer <= 0 {
+		buffer = DefaultBufferSize
+	}
+	sub := &Subscription{
+		bus:        b,
+		sampleRate: opts.RequestSampleRate,
+		ch:         make(chan Event, buffer),
+	}
+	if len(opts.Topics) > 0 {
+		sub.topics = make(map[string]struct{}, len(opts.Topics))
+		for _, topic := range opts.Topics {
+			sub.topics[topic] = struct{}{}
+		}
+	}
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+	b.active.Add(1)
+	return sub
+}
+
+// HasSubscribers reports whether anyone listens to topic, so publishers can skip
+// building payloads nobody will receive.
+func (b *Bus) HasSubscribers(topic string) bool {
+	if b == nil || b.active.Load() == 0 {
+		return false
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.subs {
+		if sub.wants(topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// Publish delivers an event to every matching subscriber without blocking.
+func (b *Bus) Publish(topic, eventType string, data any) {
+	if b == nil || b.active.Load() == 0 {
+		return
+	}
+	evt := Event{
+		ID:    b.seq.Add(1),
+		Topic: topic,
+		Type:  eventType,
+		Time:  time.Now(),
+		Data:  data,
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.subs {
+		if !sub.wants(topic) || !sub.sampled(topic) {
+			continue
+		}
+		select {
+		case sub.ch <- evt:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// C returns the channel delivering events. It is closed by Close.
+func (s *Subscription) C() <-chan Event { return s.ch }
+
+// Dropped returns how many events were discarded because the buffer was full.
+func (s *Subscription) Dropped() uint64 { return s.dropped.Load() }
+
+// Close unregisters the subscription and closes its channel.
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		s.bus.mu.Lock()
+		delete(s.bus.subs, s)
+		s.bus.mu.Unlock()
+		s.bus.active.Add(-1)
+		close(s.ch)
+	})
+}
+
+func (s *Subscription) wants(topic string) bool {
+	if len(s.topics) == 0 {
+		return true
+	}
+	_, ok := s.topics[topic]
+	return ok
+}
+
+func (s *Subscription) sampled(topic string) bool {
+	if topic != TopicRequest || s.sampleRate <= 0 || s.sampleRate >= 1 {
+		return true
+	}
+	return rand.Float64() < s.sampleRate
+}
+
+var defaultBus = NewBus()
+
+// DefaultBus returns the process-wide event bus.
+func DefaultBus() *Bus { return defaultBus }
+
+// Publish publishes an event on the default bus.
+func Publish(topic, eventType string, data any) { defaultBus.Publish(topic, eventType, data) }
+
+// HasSubscribers reports whether the default bus has subscribers for topic.
+func HasSubscribers(topic string) bool { return defaultBus.HasSubscribers(topic) }
@@ -0,0 +1,43 @@
+package events
+
+import "testing"
+
+func TestBusFiltersTopicsAndDropsWhenFull(t *testing.T) {
+	bus := NewBus()
+	sub := bus.Subscribe(SubscribeOptions{Topics: []string{TopicAuth}, Buffer: 2})
+	defer sub.Close()
+
+	if bus.HasSubscribers(TopicRequest) {
+		t.Fatalf("expected no subscribers for %s", TopicRequest)
+	}
+	bus.Publish(TopicRequest, "completed", nil)
+	for i := 0; i < 3; i++ {
+		bus.Publish(TopicAuth, "updated", AuthData{ID: "a"})
+	}
+
+	if got := len(sub.C()); got != 2 {
+		t.Fatalf("buffered events = %d, want 2", got)
+	}
+	if got := sub.Dropped(); got != 1 {
+		t.Fatalf("dropped = %d, want 1", got)
+	}
+	evt := <-sub.C()
+	if evt.Topic != TopicAuth || evt.Type != "updated" || evt.ID == 0 {
+		t.Fatalf("unexpected event: %+v", evt)
+	}
+}
+
+func TestBusCloseUnsubscribes(t *testing.T) {
+	bus := NewBus()
+	sub := bus.Subscribe(SubscribeOptions{})
+	sub.Close()
+	sub.Close()
+
+	if bus.HasSubscribers(TopicConfig) {
+		t.Fatalf("expected no subscribers after close")
+	}
+	bus.Publish(TopicConfig, "reloaded", nil)
+	if _, open := <-sub.C(); open {
+		t.Fatalf("expected closed channel")
+	}
+}
@@ -0,0 +1,53 @@
+package events
+
+import "time"
+
+// AuthData describes a credential in auth events.
+type AuthData struct {
+	ID          string `json:"id"`
+	Provider    string `json:"provider"`
+	Label       string `json:"label,omitempty"`
+	Index       string `json:"auth_index,omitempty"`
+	Status      string `json:"status,omitempty"`
+	Message     string `json:"message,omitempty"`
+	Disabled    bool   `json:"disabled"`
+	Unavailable bool   `json:"unavailable"`
+}
+
+// RefreshData describes the outcome of a credential refresh.
+type RefreshData struct {
+	AuthID    string    `json:"auth_id"`
+	Provider  string    `json:"provider"`
+	Error     string    `json:"error,omitempty"`
+	NextRetry time.Time `json:"next_retry,omitzero"`
+}
+
+// CooldownData describes a credential (or credential/model pair) entering or leaving cooldown.
+type CooldownData struct {
+	AuthID     string    `json:"auth_id"`
+	Provider   string    `json:"provider"`
+	Model      string    `json:"model,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Until      time.Time `json:"until,omitzero"`
+}
+
+// ConfigData describes a configuration reload.
+type ConfigData struct {
+	Path string `json:"path,omitempty"`
+}
+
+// RequestSummary is a compact per-request record for live monitoring.
+type RequestSummary struct {
+	RequestID    string `json:"request_id,omitempty"`
+	Method       string `json:"method"`
+	Path         string `json:"path"`
+	Status       int    `json:"status"`
+	LatencyMs    int64  `json:"latency_ms"`
+	Model        string `json:"model,omitempty"`
+	Provider     string `json:"provider,omitempty"`
+	AuthID       string `json:"auth_id,omitempty"`
+	AuthIndex    string `json:"auth_index,omitempty"`
+	InputTokens  int64  `json:"input_tokens,omitempty"`
+	OutputTokens int64  `json:"output_tokens,omitempty"`
+}
@@ -0,0 +1,21 @@
+package executor
+
+import "context"
+
+type clientAPIKeyKey struct{}
+
+// WithClientAPIKey returns a context that carries the client API key a request runs
+// under. It is used when the request is executed outside the HTTP request that
+// authenticated it, such as a batch line.
+func WithClientAPIKey(ctx context.Context, apiKey string) context.Context {
+	return context.WithValue(ctx, clientAPIKeyKey{}, apiKey)
+}
+
+// ClientAPIKey returns the client API key stored by WithClientAPIKey, or "" if none.
+func ClientAPIKey(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	apiKey, _ := ctx.Value(clientAPIKeyKey{}).(string)
+	return apiKey
+}
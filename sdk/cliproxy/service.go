@@ -22,6 +22,7 @@ import (
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
 	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/events"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
 	log "github.com/sirupsen/logrus"
@@ -318,6 +319,7 @@ func (s *Service) applyCoreAuthRemoval(ctx context.Context, id string) {
 		if _, err := s.coreManager.Update(ctx, existing); err != nil {
 			log.Errorf("failed to disable auth %s: %v", id, err)
 		}
+		coreauth.PublishAuthEvent(coreauth.EventAuthRemoved, existing)
 	}
 }
 
@@ -385,6 +387,8 @@ func (s *Service) ensureExecutorsForAuth(a *coreauth.Auth) {
 		s.coreManager.RegisterExecutor(executor.NewAntigravityExecutor(s.cfg))
 	case "claude":
 		s.coreManager.RegisterExecutor(executor.NewClaudeExecutor(s.cfg))
+	case "bedrock":
+		s.coreManager.RegisterExecutor(executor.NewBedrockExecutor(s.cfg))
 	case "codex":
 		s.coreManager.RegisterExecutor(executor.NewCodexExecutor(s.cfg))
 	case "qwen":
@@ -447,6 +451,7 @@ func (s *Service) Run(ctx context.Context) error {
 	}
 
 	s.applyRetryConfig(s.cfg)
+	registerVirtualModels(s.cfg)
 
 	if s.coreManager != nil {
 		if errLoad := s.coreManager.Load(ctx); errLoad != nil {
@@ -562,6 +567,7 @@ func (s *Service) Run(ctx context.Context) error {
 		}
 
 		s.applyRetryConfig(newCfg)
+		registerVirtualModels(newCfg)
 		if s.server != nil {
 			s.server.UpdateClients(newCfg)
 		}
@@ -573,6 +579,7 @@ func (s *Service) Run(ctx context.Context) error {
 			s.coreManager.SetOAuthModelAlias(newCfg.OAuthModelAlias)
 		}
 		s.rebindExecutors()
+		events.Publish(events.TopicConfig, "reloaded", events.ConfigData{Path: s.configPath})
 	}
 
 	watcherWrapper, err = s.watcherFactory(s.configPath, s.cfg.AuthDir, reloadCallback)
@@ -778,6 +785,13 @@ func (s *Service) registerModelsForAuth(a *coreauth.Auth) {
 			}
 		}
 		models = applyExcludedModels(models, excluded)
+	case "bedrock":
+		// Bedrock model IDs differ from Anthropic's, so only configured mappings are served.
+		if entry := s.resolveConfigBedrockKey(a); entry != nil {
+			models = buildBedrockConfigModels(entry)
+			excluded = entry.ExcludedModels
+		}
+		models = applyExcludedModels(models, excluded)
 	case "codex":
 		models = registry.GetOpenAIModels()
 		if entry := s.resolveConfigCodexKey(a); entry != nil {
@@ -860,6 +874,7 @@ func (s *Service) registerModelsForAuth(a *coreauth.Auth) {
 							OwnedBy:     compat.Name,
 							Type:        "openai-compatibility",
 							DisplayName: modelID,
+							ModelType:   configModelType(m, m.Name),
 							UserDefined: true,
 						})
 					}
@@ -935,6 +950,21 @@ func (s *Service) resolveConfigClaudeKey(auth *coreauth.Auth) *config.ClaudeKey
 	return nil
 }
 
+func (s *Service) resolveConfigBedrockKey(auth *coreauth.Auth) *config.BedrockKey {
+	if auth == nil || s.cfg == nil || auth.Attributes == nil {
+		return nil
+	}
+	attrKey := strings.TrimSpace(auth.Attributes["api_key"])
+	attrBase := strings.TrimSpace(auth.Attributes["base_url"])
+	for i := range s.cfg.BedrockKey {
+		entry := &s.cfg.BedrockKey[i]
+		if strings.EqualFold(entry.GetAPIKey(), attrKey) && strings.EqualFold(entry.GetBaseURL(), attrBase) {
+			return entry
+		}
+	}
+	return nil
+}
+
 func (s *Service) resolveConfigGeminiKey(auth *coreauth.Auth) *config.GeminiKey {
 	if auth == nil || s.cfg == nil {
 		return nil
@@ -1160,6 +1190,27 @@ type modelEntry interface {
 	GetAlias() string
 }
 
+// typedModelEntry is implemented by config models that can declare a model type.
+type typedModelEntry interface {
+	GetType() string
+}
+
+// configModelType resolves the registry model type for a configured model, falling
+// back to the static definition of the upstream model when none is configured.
+func configModelType(model any, name string) string {
+	if typed, ok := model.(typedModelEntry); ok {
+		if t := strings.ToLower(strings.TrimSpace(typed.GetType())); t != "" {
+			return t
+		}
+	}
+	if name != "" {
+		if upstream := registry.LookupStaticModelInfo(name); upstream != nil {
+			return upstream.ModelType
+		}
+	}
+	return ""
+}
+
 func buildConfigModels[T modelEntry](models []T, ownedBy, modelType string) []*ModelInfo {
 	if len(models) == 0 {
 		return nil
@@ -1193,6 +1244,7 @@ func buildConfigModels[T modelEntry](models []T, ownedBy, modelType string) []*M
 			OwnedBy:     ownedBy,
 			Type:        modelType,
 			DisplayName: display,
+			ModelType:   configModelType(model, name),
 			UserDefined: true,
 		}
 		if name != "" {
@@ -1226,6 +1278,27 @@ func buildClaudeConfigModels(entry *config.ClaudeKey) []*ModelInfo {
 	return buildConfigModels(entry.Models, "anthropic", "claude")
 }
 
+// buildBedrockConfigModels registers the aliases of a Bedrock entry. Aliases naming a
+// known Claude model inherit its context window and thinking support.
+func buildBedrockConfigModels(entry *config.BedrockKey) []*ModelInfo {
+	if entry == nil {
+		return nil
+	}
+	models := buildConfigModels(entry.Models, "anthropic", "bedrock")
+	for _, info := range models {
+		upstream := registry.LookupStaticModelInfo(info.ID)
+		if upstream == nil {
+			continue
+		}
+		if info.Thinking == nil {
+			info.Thinking = upstream.Thinking
+		}
+		info.ContextLength = upstream.ContextLength
+		info.MaxCompletionTokens = upstream.MaxCompletionTokens
+	}
+	return models
+}
+
 func buildCodexConfigModels(entry *config.CodexKey) []*ModelInfo {
 	if entry == nil {
 		return nil
@@ -19,6 +19,8 @@ type Record struct {
 	RequestedAt time.Time
 	Failed      bool
 	Detail      Detail
+	// Stream carries latency metrics for streaming requests; nil for non-streaming ones.
+	Stream *StreamMetrics
 }
 
 // Detail holds the token usage breakdown.
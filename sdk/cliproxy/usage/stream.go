@@ -0,0 +1,165 @@
+package usage
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// StreamMetrics captures latency characteristics of a single streaming request.
+type StreamMetrics struct {
+	// TimeToFirstByte is the delay until the first upstream payload reached the handler.
+	TimeToFirstByte time.Duration
+	// TimeToFirstContent is the delay until the first chunk carrying generated content.
+	TimeToFirstContent time.Duration
+	// Duration is the total stream duration.
+	Duration time.Duration
+	// OutputTokensPerSecond is the output token rate measured after the first content delta.
+	OutputTokensPerSecond float64
+}
+
+type streamTrackerKey struct{}
+
+type heldRecord struct {
+	ctx    context.Context
+	record Record
+}
+
+// StreamTracker measures a streaming request and holds back its usage records until the
+// stream completes, so the published record can carry the final StreamMetrics.
+type StreamTracker struct {
+	mu             sync.Mutex
+	startedAt      time.Time
+	firstByteAt    time.Time
+	firstContentAt time.Time
+	held           []heldRecord
+	finished       bool
+}
+
+// NewStreamTracker creates a tracker whose clock starts now.
+func NewStreamTracker() *StreamTracker {
+	return &StreamTracker{startedAt: time.Now()}
+}
+
+// WithStreamTracker attaches the tracker to ctx so executors can defer usage publication.
+func WithStreamTracker(ctx context.Context, tracker *StreamTracker) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, streamTrackerKey{}, tracker)
+}
+
+// StreamTrackerFromContext returns the tracker attached to ctx, if any.
+func StreamTrackerFromContext(ctx context.Context) *StreamTracker {
+	if ctx == nil {
+		return nil
+	}
+	tracker, _ := ctx.Value(streamTrackerKey{}).(*StreamTracker)
+	return tracker
+}
+
+// MarkFirstByte records the arrival of the first upstream payload. Later calls are ignored.
+func (t *StreamTracker) MarkFirstByte() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	if t.firstByteAt.IsZero() {
+		t.firstByteAt = time.Now()
+	}
+	t.mu.Unlock()
+}
+
+// MarkFirstContent records the arrival of the first content delta. Later calls are ignored.
+func (t *StreamTracker) MarkFirstContent() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	now := time.Now()
+	if t.firstByteAt.IsZero() {
+		t.firstByteAt = now
+	}
+	if t.firstContentAt.IsZero() {
+		t.firstContentAt = now
+	}
+	t.mu.Unlock()
+}
+
+// Hold defers publication of record until Finish is called.
+// It returns false when the tracker already finished, in which case the caller should publish directly.
+func (t *StreamTracker) Hold(ctx context.Context, record Record) bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.finished {
+		return false
+	}
+	t.held = append(t.held, heldRecord{ctx: ctx, record: record})
+	return true
+}
+
+// Finish stops the clock, attaches StreamMetrics to the last successful held record and
+// publishes every held record. It returns that record so callers can feed the metrics back
+// into credential selection. Calling Finish more than once is safe.
+func (t *StreamTracker) Finish() (Record, bool) {
+	if t == nil {
+		return Record{}, false
+	}
+	t.mu.Lock()
+	if t.finished {
+		t.mu.Unlock()
+		return Record{}, false
+	}
+	t.finished = true
+	held := t.held
+	t.held = nil
+	metrics := t.metricsLocked(time.Now())
+	t.mu.Unlock()
+
+	measured := -1
+	for i := len(held) - 1; i >= 0; i-- {
+		if !held[i].record.Failed {
+			measured = i
+			break
+		}
+	}
+	var result Record
+	for i := range held {
+		if i == measured {
+			m := metrics
+			m.OutputTokensPerSecond = tokensPerSecond(held[i].record.Detail.OutputTokens, m)
+			held[i].record.Stream = &m
+			result = held[i].record
+		}
+		PublishRecord(held[i].ctx, held[i].record)
+	}
+	return result, measured >= 0
+}
+
+func (t *StreamTracker) metricsLocked(now time.Time) StreamMetrics {
+	metrics := StreamMetrics{Duration: now.Sub(t.startedAt)}
+	if !t.firstByteAt.IsZero() {
+		metrics.TimeToFirstByte = t.firstByteAt.Sub(t.startedAt)
+	}
+	if !t.firstContentAt.IsZero() {
+		metrics.TimeToFirstContent = t.firstContentAt.Sub(t.startedAt)
+	}
+	return metrics
+}
+
+func tokensPerSecond(outputTokens int64, metrics StreamMetrics) float64 {
+	if outputTokens <= 0 {
+		return 0
+	}
+	generation := metrics.Duration - metrics.TimeToFirstContent
+	if metrics.TimeToFirstContent == 0 || generation <= 0 {
+		generation = metrics.Duration
+	}
+	if generation <= 0 {
+		return 0
+	}
+	return float64(outputTokens) / generation.Seconds()
+}
@@ -0,0 +1,31 @@
+package cliproxy
+
+import (
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// registerVirtualModels publishes the configured virtual models in the model registry.
+// The registry lists each one while its target model has an available credential and
+// routes it to the target's providers.
+func registerVirtualModels(cfg *config.Config) {
+	var models []registry.VirtualModel
+	if cfg != nil {
+		models = make([]registry.VirtualModel, 0, len(cfg.VirtualModels))
+		for _, vm := range cfg.VirtualModels {
+			description := vm.Description
+			if description == "" {
+				description = "Virtual model for " + vm.Model
+			}
+			models = append(models, registry.VirtualModel{
+				ID:          vm.Name,
+				Target:      strings.TrimSpace(thinking.ParseSuffix(vm.Model).ModelName),
+				Description: description,
+			})
+		}
+	}
+	registry.GetGlobalRegistry().SetVirtualModels(models)
+}
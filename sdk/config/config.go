@@ -13,17 +13,28 @@ type AccessProvider = internalconfig.AccessProvider
 type Config = internalconfig.Config
 
 type StreamingConfig = internalconfig.StreamingConfig
+type ResponsesStoreConfig = internalconfig.ResponsesStoreConfig
+type VirtualModel = internalconfig.VirtualModel
+type StructuredOutputConfig = internalconfig.StructuredOutputConfig
+type ReasoningOutputConfig = internalconfig.ReasoningOutputConfig
+type ReasoningOutputPolicy = internalconfig.ReasoningOutputPolicy
+type ReasoningOutputClient = internalconfig.ReasoningOutputClient
+type ReasoningOutputModel = internalconfig.ReasoningOutputModel
+type ContextGuardConfig = internalconfig.ContextGuardConfig
 type TLSConfig = internalconfig.TLSConfig
 type RemoteManagement = internalconfig.RemoteManagement
 type AmpCode = internalconfig.AmpCode
 type OAuthModelAlias = internalconfig.OAuthModelAlias
 type PayloadConfig = internalconfig.PayloadConfig
+type BatchConfig = internalconfig.BatchConfig
+type OllamaConfig = internalconfig.OllamaConfig
 type PayloadRule = internalconfig.PayloadRule
 type PayloadModelRule = internalconfig.PayloadModelRule
 
 type GeminiKey = internalconfig.GeminiKey
 type CodexKey = internalconfig.CodexKey
 type ClaudeKey = internalconfig.ClaudeKey
+type BedrockKey = internalconfig.BedrockKey
 type VertexCompatKey = internalconfig.VertexCompatKey
 type VertexCompatModel = internalconfig.VertexCompatModel
 type OpenAICompatibility = internalconfig.OpenAICompatibility
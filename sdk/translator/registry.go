@@ -2,6 +2,7 @@ package translator
 
 import (
 	"context"
+	"sort"
 	"sync"
 )
 
@@ -65,6 +66,52 @@ func (r *Registry) HasResponseTransformer(from, to Format) bool {
 	return false
 }
 
+// Pair describes a registered translation route from a client format to a provider format.
+type Pair struct {
+	From     Format
+	To       Format
+	Request  bool
+	Response bool
+}
+
+// Pairs lists every registered format pair, sorted by source and then target format.
+func (r *Registry) Pairs() []Pair {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	index := make(map[[2]Format]Pair)
+	for from, byTarget := range r.requests {
+		for to, fn := range byTarget {
+			if fn != nil {
+				index[[2]Format{from, to}] = Pair{From: from, To: to, Request: true}
+			}
+		}
+	}
+	for from, byTarget := range r.responses {
+		for to, fn := range byTarget {
+			if fn.Stream == nil && fn.NonStream == nil {
+				continue
+			}
+			key := [2]Format{from, to}
+			p := index[key]
+			p.From, p.To, p.Response = from, to, true
+			index[key] = p
+		}
+	}
+
+	out := make([]Pair, 0, len(index))
+	for _, p := range index {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].From != out[j].From {
+			return out[i].From < out[j].From
+		}
+		return out[i].To < out[j].To
+	})
+	return out
+}
+
 // TranslateStream applies the registered streaming response translator.
 func (r *Registry) TranslateStream(ctx context.Context, from, to Format, model string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, param *any) []string {
 	r.mu.RLock()
@@ -126,6 +173,11 @@ func HasResponseTransformer(from, to Format) bool {
 	return defaultRegistry.HasResponseTransformer(from, to)
 }
 
+// Pairs lists the format pairs registered on the default registry.
+func Pairs() []Pair {
+	return defaultRegistry.Pairs()
+}
+
 // TranslateStream is a helper on the default registry.
 func TranslateStream(ctx context.Context, from, to Format, model string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, param *any) []string {
 	return defaultRegistry.TranslateStream(ctx, from, to, model, originalRequestRawJSON, requestRawJSON, rawJSON, param)
@@ -0,0 +1,644 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator"
+
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// The conformance suite runs the canonical request of every client format in
+// testdata/translator/requests and the recorded upstream responses of every provider
+// format in testdata/translator/responses through each registered translator pair.
+// Summaries of the translated payloads are compared with testdata/translator/golden;
+// regenerate them with:
+//
+//	go test ./test -run TestTranslatorConformance -update-golden
+var updateGolden = flag.Bool("update-golden", false, "rewrite the translator conformance golden files")
+
+const (
+	conformanceDir    = "testdata/translator"
+	conformanceModel  = "conformance-model"
+	conformanceSystem = "You are a weather assistant."
+	formatKiro        = sdktranslator.Format("kiro")
+	formatBedrock     = sdktranslator.Format("bedrock")
+)
+
+// Fixture IDs are kept verbatim in golden files; any other ID was generated by a translator.
+var conformanceFixtureIDs = map[string]bool{
+	"call_weather_1":  true,
+	"toolu_weather_1": true,
+	"call_01":         true,
+	"toolu_01":        true,
+}
+
+// Formats whose tool calls carry an ID that must be echoed by the matching tool result.
+var conformanceIDFormats = map[sdktranslator.Format]bool{
+	sdktranslator.FormatOpenAI:         true,
+	sdktranslator.FormatOpenAIResponse: true,
+	sdktranslator.FormatClaude:         true,
+	sdktranslator.FormatCodex:          true,
+	formatBedrock:                      true,
+}
+
+// Pairs with a known behavioural gap, keyed "from->to". Their checks are logged instead of
+// failing the suite; golden files are still compared so any change shows up.
+var conformanceKnownGaps = map[string]string{
+	"claude->codex":           "string system prompts are dropped",
+	"gemini->claude":          "camelCase systemInstruction is ignored",
+	"gemini->codex":           "camelCase systemInstruction is ignored",
+	"gemini-cli->gemini":      "bare JSON stream payloads are dropped",
+	"openai->codex":           "tool calls finish with stop",
+	"openai-response->openai": "response.completed is emitted before the trailing usage chunk",
+}
+
+type conformanceRequest struct {
+	System      bool     `json:"system"`
+	Roles       []string `json:"roles"`
+	ToolCalls   []string `json:"tool_calls"`
+	ToolResults []string `json:"tool_results"`
+}
+
+type conformanceCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type conformanceTurn struct {
+	Text         string            `json:"text"`
+	Calls        []conformanceCall `json:"calls"`
+	Finish       string            `json:"finish"`
+	InputTokens  int64             `json:"input_tokens"`
+	OutputTokens int64             `json:"output_tokens"`
+}
+
+type conformanceGolden struct {
+	Request   conformanceRequest `json:"request"`
+	NonStream *conformanceTurn   `json:"non_stream,omitempty"`
+	Stream    *conformanceTurn   `json:"stream,omitempty"`
+}
+
+func TestTranslatorConformance(t *testing.T) {
+	pairs := sdktranslator.Pairs()
+	registered := make(map[[2]sdktranslator.Format]bool, len(pairs))
+	var sources, targets []sdktranslator.Format
+	for _, pair := range pairs {
+		registered[[2]sdktranslator.Format{pair.From, pair.To}] = pair.Request && pair.Response
+		request, errRead := os.ReadFile(filepath.Join(conformanceDir, "requests", string(pair.From)+".json"))
+		if errRead != nil {
+			t.Logf("skipping %s -> %s: no canonical %s request", pair.From, pair.To, pair.From)
+			continue
+		}
+		sources = appendFormat(sources, pair.From)
+		targets = appendFormat(targets, pair.To)
+		t.Run(string(pair.From)+"->"+string(pair.To), func(t *testing.T) {
+			runConformancePair(t, pair, request)
+		})
+	}
+
+	var missing []string
+	for _, from := range sources {
+		for _, to := range targets {
+			if from != to && !registered[[2]sdktranslator.Format{from, to}] {
+				missing = append(missing, fmt.Sprintf("%s -> %s", from, to))
+			}
+		}
+	}
+	report := "# Client/provider format pairs without a request or response translator.\n" + strings.Join(missing, "\n") + "\n"
+	t.Logf("pairs missing translators:\n%s", report)
+	checkGolden(t, filepath.Join(conformanceDir, "golden", "missing_pairs.txt"), []byte(report))
+}
+
+func runConformancePair(t *testing.T, pair sdktranslator.Pair, request []byte) {
+	// Executors expose the Gemini alt parameter to response translators; SSE streams use "".
+	ctx := context.WithValue(context.Background(), "alt", "")
+	golden := conformanceGolden{}
+	errorf := t.Errorf
+	if gap, ok := conformanceKnownGaps[string(pair.From)+"->"+string(pair.To)]; ok {
+		errorf = func(format string, args ...any) {
+			t.Logf("known gap (%s): "+format, append([]any{gap}, args...)...)
+		}
+	}
+
+	translated := sdktranslator.TranslateRequest(pair.From, pair.To, conformanceModel, request, false)
+	// The Kiro executor builds its wire payload itself; its translator passes the request through.
+	requestFormat := pair.To
+	if pair.To == formatKiro {
+		requestFormat = pair.From
+	}
+	golden.Request = summarizeRequest(requestFormat, translated)
+	checkRequest(errorf, requestFormat, golden.Request, translated)
+
+	streamOriginal := request
+	if pair.From != sdktranslator.FormatGemini && pair.From != sdktranslator.FormatGeminiCLI {
+		streamOriginal, _ = sjson.SetBytes(request, "stream", true)
+	}
+	streamRequest := sdktranslator.TranslateRequest(pair.From, pair.To, conformanceModel, streamOriginal, true)
+	if pair.To == sdktranslator.FormatOpenAI {
+		// The recorded OpenAI stream ends with a usage chunk, as requested by the Copilot and
+		// Qwen executors.
+		streamRequest, _ = sjson.SetBytes(streamRequest, "stream_options.include_usage", true)
+	}
+
+	responses := filepath.Join(conformanceDir, "responses", string(pair.To))
+	nonStreamFile := filepath.Join(responses, "nonstream.json")
+	if pair.To == sdktranslator.FormatClaude {
+		// The Claude executor streams upstream for other client formats and hands the whole
+		// SSE body to the non-stream translator.
+		nonStreamFile = filepath.Join(responses, "stream.txt")
+	}
+	if raw, errRead := os.ReadFile(nonStreamFile); errRead == nil {
+		var param any
+		out := sdktranslator.TranslateNonStream(ctx, pair.To, pair.From, conformanceModel, request, translated, bytes.TrimSpace(raw), &param)
+		turn := summarizeTurn(pair.From, []string{out})
+		checkTurn(errorf, pair.From, "non-stream", turn, out)
+		golden.NonStream = &turn
+	} else {
+		t.Logf("no recorded non-stream %s response", pair.To)
+	}
+	if raw, errRead := os.ReadFile(filepath.Join(responses, "stream.txt")); errRead == nil {
+		var param any
+		var chunks []string
+		for _, line := range feedStream(pair.To, string(raw)) {
+			chunks = append(chunks, sdktranslator.TranslateStream(ctx, pair.To, pair.From, conformanceModel, streamOriginal, streamRequest, line, &param)...)
+		}
+		turn := summarizeTurn(pair.From, chunks)
+		checkTurn(errorf, pair.From, "stream", turn, strings.Join(chunks, "\n"))
+		golden.Stream = &turn
+	} else {
+		t.Logf("no recorded stream %s response", pair.To)
+	}
+
+	maskGeneratedIDs(&golden)
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if errEncode := encoder.Encode(golden); errEncode != nil {
+		t.Fatalf("encode golden: %v", errEncode)
+	}
+	checkGolden(t, filepath.Join(conformanceDir, "golden", string(pair.From)+"_to_"+string(pair.To)+".json"), data.Bytes())
+}
+
+func checkRequest(errorf func(string, ...any), format sdktranslator.Format, got conformanceRequest, payload []byte) {
+	if !got.System {
+		errorf("system prompt lost: %s", payload)
+	}
+	if want := []string{"user", "assistant", "tool", "user"}; strings.Join(got.Roles, ",") != strings.Join(want, ",") {
+		errorf("role order = %v, want %v: %s", got.Roles, want, payload)
+	}
+	if len(got.ToolCalls) != 1 || len(got.ToolResults) != 1 {
+		errorf("expected one tool call and one tool result, got %v and %v: %s", got.ToolCalls, got.ToolResults, payload)
+		return
+	}
+	if conformanceIDFormats[format] && (got.ToolCalls[0] == "" || got.ToolCalls[0] != got.ToolResults[0]) {
+		errorf("tool result ID %q does not match tool call ID %q: %s", got.ToolResults[0], got.ToolCalls[0], payload)
+	}
+}
+
+func checkTurn(errorf func(string, ...any), format sdktranslator.Format, mode string, got conformanceTurn, payload string) {
+	if got.Text != "Checking the forecast." {
+		errorf("%s: text = %q: %s", mode, got.Text, payload)
+	}
+	if len(got.Calls) != 1 || got.Calls[0].Name != "get_weather" || got.Calls[0].Arguments != `{"city":"Paris"}` {
+		errorf("%s: unexpected tool calls %+v: %s", mode, got.Calls, payload)
+	} else if conformanceIDFormats[format] && got.Calls[0].ID == "" {
+		errorf("%s: tool call without ID: %s", mode, payload)
+	}
+	if got.Finish != "tool_use" {
+		errorf("%s: finish reason = %q, want tool_use: %s", mode, got.Finish, payload)
+	}
+	if got.InputTokens != 11 || got.OutputTokens != 7 {
+		errorf("%s: usage = %d/%d, want 11/7: %s", mode, got.InputTokens, got.OutputTokens, payload)
+	}
+}
+
+func checkGolden(t *testing.T, path string, got []byte) {
+	t.Helper()
+	if *updateGolden {
+		if errWrite := os.WriteFile(path, got, 0o644); errWrite != nil {
+			t.Fatalf("write golden %s: %v", path, errWrite)
+		}
+		return
+	}
+	want, errRead := os.ReadFile(path)
+	if errRead != nil {
+		t.Fatalf("read golden %s: %v (run with -update-golden)", path, errRead)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from the translated output (run with -update-golden if intended)\n got: %s\nwant: %s", path, got, want)
+	}
+}
+
+// feedStream splits a recorded upstream stream into the units the provider's executor
+// hands to TranslateStream.
+func feedStream(format sdktranslator.Format, raw string) [][]byte {
+	var out [][]byte
+	switch format {
+	case formatKiro:
+		for _, event := range strings.Split(raw, "\n\n") {
+			if strings.TrimSpace(event) != "" {
+				out = append(out, []byte(event+"\n\n"))
+			}
+		}
+	case sdktranslator.FormatClaude, sdktranslator.FormatCodex, formatBedrock:
+		// The Bedrock executor decodes event stream frames into one {"<event>": payload} line each.
+		for _, line := range strings.Split(strings.TrimSuffix(raw, "\n"), "\n") {
+			out = append(out, []byte(line))
+		}
+	default:
+		for _, line := range strings.Split(raw, "\n") {
+			if !strings.HasPrefix(line, "data:") {
+				continue
+			}
+			switch format {
+			case sdktranslator.FormatGemini, sdktranslator.FormatAntigravity:
+				out = append(out, []byte(strings.TrimSpace(strings.TrimPrefix(line, "data:"))))
+			default:
+				out = append(out, []byte(line))
+			}
+		}
+		if format != sdktranslator.FormatOpenAI {
+			out = append(out, []byte("[DONE]"))
+		}
+	}
+	return out
+}
+
+func summarizeRequest(format sdktranslator.Format, payload []byte) conformanceRequest {
+	root := gjson.ParseBytes(payload)
+	var summary conformanceRequest
+	add := func(role string) {
+		if n := len(summary.Roles); n == 0 || summary.Roles[n-1] != role {
+			summary.Roles = append(summary.Roles, role)
+		}
+	}
+
+	switch format {
+	case sdktranslator.FormatClaude:
+		summary.System = root.Get("system").Exists() && strings.TrimSpace(root.Get("system").String()) != ""
+		messages := root.Get("messages").Array()
+		// Claude Code requests carry client system prompts as a leading user message.
+		if len(messages) > 0 && messages[0].Get("role").String() == "user" && claudeText(messages[0].Get("content")) == conformanceSystem {
+			summary.System = true
+			messages = messages[1:]
+		}
+		for _, message := range messages {
+			role := message.Get("role").String()
+			content := message.Get("content")
+			if !content.IsArray() {
+				add(role)
+				continue
+			}
+			for _, block := range content.Array() {
+				switch block.Get("type").String() {
+				case "tool_use":
+					add("assistant")
+					summary.ToolCalls = append(summary.ToolCalls, block.Get("id").String())
+				case "tool_result":
+					add("tool")
+					summary.ToolResults = append(summary.ToolResults, block.Get("tool_use_id").String())
+				default:
+					add(role)
+				}
+			}
+		}
+
+	case sdktranslator.FormatOpenAI:
+		for _, message := range root.Get("messages").Array() {
+			role := message.Get("role").String()
+			switch role {
+			case "system", "developer":
+				summary.System = true
+				continue
+			case "tool":
+				summary.ToolResults = append(summary.ToolResults, message.Get("tool_call_id").String())
+			}
+			add(role)
+			for _, call := range message.Get("tool_calls").Array() {
+				summary.ToolCalls = append(summary.ToolCalls, call.Get("id").String())
+			}
+		}
+
+	case sdktranslator.FormatOpenAIResponse, sdktranslator.FormatCodex:
+		summary.System = strings.TrimSpace(root.Get("instructions").String()) != ""
+		for _, item := range root.Get("input").Array() {
+			switch item.Get("type").String() {
+			case "function_call":
+				add("assistant")
+				summary.ToolCalls = append(summary.ToolCalls, item.Get("call_id").String())
+			case "function_call_output":
+				add("tool")
+				summary.ToolResults = append(summary.ToolResults, item.Get("call_id").String())
+			default:
+				role := item.Get("role").String()
+				if role == "system" || role == "developer" {
+					summary.System = true
+					continue
+				}
+				// Codex requires an assistant message before a function call even when it is empty.
+				if role == "assistant" && len(item.Get("content").Array()) == 0 {
+					continue
+				}
+				add(role)
+			}
+		}
+
+	case formatBedrock:
+		summary.System = len(root.Get("system").Array()) > 0
+		for _, message := range root.Get("messages").Array() {
+			role := message.Get("role").String()
+			for _, block := range message.Get("content").Array() {
+				switch {
+				case block.Get("toolUse").Exists():
+					add("assistant")
+					summary.ToolCalls = append(summary.ToolCalls, block.Get("toolUse.toolUseId").String())
+				case block.Get("toolResult").Exists():
+					add("tool")
+					summary.ToolResults = append(summary.ToolResults, block.Get("toolResult.toolUseId").String())
+				default:
+					add(role)
+				}
+			}
+		}
+
+	default:
+		// Gemini, Gemini CLI and Antigravity share the Gemini content schema.
+		request := root
+		if root.Get("request").Exists() {
+			request = root.Get("request")
+		}
+		summary.System = len(request.Get("systemInstruction.parts").Array()) > 0 || len(request.Get("system_instruction.parts").Array()) > 0
+		for _, content := range request.Get("contents").Array() {
+			role := content.Get("role").String()
+			if role == "model" {
+				role = "assistant"
+			}
+			for _, part := range content.Get("parts").Array() {
+				switch {
+				case part.Get("functionCall").Exists():
+					add("assistant")
+					summary.ToolCalls = append(summary.ToolCalls, part.Get("functionCall.id").String())
+				case part.Get("functionResponse").Exists():
+					add("tool")
+					summary.ToolResults = append(summary.ToolResults, part.Get("functionResponse.id").String())
+				default:
+					add(role)
+				}
+			}
+		}
+	}
+	return summary
+}
+
+// summarizeTurn folds the translated response chunks of a client format into one turn.
+func summarizeTurn(format sdktranslator.Format, chunks []string) conformanceTurn {
+	var turn conformanceTurn
+	calls := make(map[int64]*conformanceCall)
+	var order []int64
+	call := func(index int64) *conformanceCall {
+		if c, ok := calls[index]; ok {
+			return c
+		}
+		calls[index] = &conformanceCall{}
+		order = append(order, index)
+		return calls[index]
+	}
+	rawFinish := ""
+
+	for _, event := range conformanceEvents(chunks) {
+		switch format {
+		case sdktranslator.FormatOpenAI:
+			if event.Get("object").String() == "chat.completion" {
+				message := event.Get("choices.0.message")
+				turn.Text += message.Get("content").String()
+				for i, tc := range message.Get("tool_calls").Array() {
+					c := call(int64(i))
+					c.ID, c.Name, c.Arguments = tc.Get("id").String(), tc.Get("function.name").String(), tc.Get("function.arguments").String()
+				}
+			} else {
+				turn.Text += event.Get("choices.0.delta.content").String()
+				for _, tc := range event.Get("choices.0.delta.tool_calls").Array() {
+					c := call(tc.Get("index").Int())
+					if id := tc.Get("id").String(); id != "" {
+						c.ID = id
+					}
+					if name := tc.Get("function.name").String(); name != "" {
+						c.Name = name
+					}
+					c.Arguments += tc.Get("function.arguments").String()
+				}
+			}
+			if reason := event.Get("choices.0.finish_reason").String(); reason != "" {
+				rawFinish = reason
+			}
+			if usage := event.Get("usage"); usage.IsObject() {
+				turn.InputTokens, turn.OutputTokens = usage.Get("prompt_tokens").Int(), usage.Get("completion_tokens").Int()
+			}
+
+		case sdktranslator.FormatClaude:
+			switch event.Get("type").String() {
+			case "message":
+				for i, block := range event.Get("content").Array() {
+					switch block.Get("type").String() {
+					case "text":
+						turn.Text += block.Get("text").String()
+					case "tool_use":
+						c := call(int64(i))
+						c.ID, c.Name, c.Arguments = block.Get("id").String(), block.Get("name").String(), block.Get("input").Raw
+					}
+				}
+				rawFinish = event.Get("stop_reason").String()
+				turn.InputTokens, turn.OutputTokens = event.Get("usage.input_tokens").Int(), event.Get("usage.output_tokens").Int()
+			case "message_start":
+				turn.InputTokens = event.Get("message.usage.input_tokens").Int()
+			case "content_block_start":
+				if block := event.Get("content_block"); block.Get("type").String() == "tool_use" {
+					c := call(event.Get("index").Int())
+					c.ID, c.Name = block.Get("id").String(), block.Get("name").String()
+				}
+			case "content_block_delta":
+				switch event.Get("delta.type").String() {
+				case "text_delta":
+					turn.Text += event.Get("delta.text").String()
+				case "input_json_delta":
+					call(event.Get("index").Int()).Arguments += event.Get("delta.partial_json").String()
+				}
+			case "message_delta":
+				rawFinish = event.Get("delta.stop_reason").String()
+				if input := event.Get("usage.input_tokens").Int(); input > 0 {
+					turn.InputTokens = input
+				}
+				turn.OutputTokens = event.Get("usage.output_tokens").Int()
+			}
+
+		case sdktranslator.FormatOpenAIResponse:
+			response := event
+			switch event.Get("type").String() {
+			case "response.output_text.delta":
+				turn.Text += event.Get("delta").String()
+				continue
+			case "response.output_item.done":
+				if item := event.Get("item"); item.Get("type").String() == "function_call" {
+					c := call(int64(len(order)))
+					c.ID, c.Name, c.Arguments = item.Get("call_id").String(), item.Get("name").String(), item.Get("arguments").String()
+				}
+				continue
+			case "response.completed":
+				response = event.Get("response")
+			default:
+				if event.Get("object").String() != "response" {
+					continue
+				}
+				for _, item := range event.Get("output").Array() {
+					switch item.Get("type").String() {
+					case "message":
+						for _, part := range item.Get("content").Array() {
+							turn.Text += part.Get("text").String()
+						}
+					case "function_call":
+						c := call(int64(len(order)))
+						c.ID, c.Name, c.Arguments = item.Get("call_id").String(), item.Get("name").String(), item.Get("arguments").String()
+					}
+				}
+			}
+			rawFinish = response.Get("status").String()
+			turn.InputTokens, turn.OutputTokens = response.Get("usage.input_tokens").Int(), response.Get("usage.output_tokens").Int()
+
+		default:
+			response := event
+			if event.Get("response").IsObject() {
+				response = event.Get("response")
+			}
+			for _, part := range response.Get("candidates.0.content.parts").Array() {
+				if fn := part.Get("functionCall"); fn.Exists() {
+					c := call(int64(len(order)))
+					c.ID, c.Name, c.Arguments = fn.Get("id").String(), fn.Get("name").String(), fn.Get("args").Raw
+					continue
+				}
+				turn.Text += part.Get("text").String()
+			}
+			if reason := response.Get("candidates.0.finishReason").String(); reason != "" {
+				rawFinish = reason
+			}
+			if usage := response.Get("usageMetadata"); usage.IsObject() {
+				turn.InputTokens, turn.OutputTokens = usage.Get("promptTokenCount").Int(), usage.Get("candidatesTokenCount").Int()
+			}
+		}
+	}
+
+	for _, index := range order {
+		c := *calls[index]
+		c.Arguments = compactJSON(c.Arguments)
+		turn.Calls = append(turn.Calls, c)
+	}
+	turn.Finish = canonicalFinish(rawFinish, len(turn.Calls) > 0)
+	return turn
+}
+
+// canonicalFinish maps a client-format finish reason onto tool_use, end_turn or
+// max_tokens. Gemini and the Responses API report a normal stop for tool turns.
+func canonicalFinish(reason string, hasCalls bool) string {
+	switch reason {
+	case "tool_calls", "tool_use", "function_call":
+		return "tool_use"
+	case "length", "max_tokens", "MAX_TOKENS", "incomplete":
+		return "max_tokens"
+	case "stop", "end_turn", "STOP", "completed":
+		if hasCalls && reason != "stop" && reason != "end_turn" {
+			return "tool_use"
+		}
+		return "end_turn"
+	}
+	return reason
+}
+
+// conformanceEvents decodes translated chunks, which may be bare JSON or SSE text holding
+// one or more data lines.
+func conformanceEvents(chunks []string) []gjson.Result {
+	var events []gjson.Result
+	for _, chunk := range chunks {
+		trimmed := strings.TrimSpace(chunk)
+		if gjson.Valid(trimmed) {
+			events = append(events, gjson.Parse(trimmed))
+			continue
+		}
+		for _, line := range strings.Split(chunk, "\n") {
+			data, ok := strings.CutPrefix(strings.TrimSpace(line), "data:")
+			if !ok {
+				continue
+			}
+			if data = strings.TrimSpace(data); data != "[DONE]" && gjson.Valid(data) {
+				events = append(events, gjson.Parse(data))
+			}
+		}
+	}
+	return events
+}
+
+func claudeText(content gjson.Result) string {
+	if !content.IsArray() {
+		return content.String()
+	}
+	var text strings.Builder
+	for _, block := range content.Array() {
+		text.WriteString(block.Get("text").String())
+	}
+	return text.String()
+}
+
+func maskGeneratedIDs(golden *conformanceGolden) {
+	mask := func(id string) string {
+		if id == "" || conformanceFixtureIDs[id] {
+			return id
+		}
+		return "<generated>"
+	}
+	for i := range golden.Request.ToolCalls {
+		golden.Request.ToolCalls[i] = mask(golden.Request.ToolCalls[i])
+	}
+	for i := range golden.Request.ToolResults {
+		golden.Request.ToolResults[i] = mask(golden.Request.ToolResults[i])
+	}
+	for _, turn := range []*conformanceTurn{golden.NonStream, golden.Stream} {
+		if turn == nil {
+			continue
+		}
+		for i := range turn.Calls {
+			turn.Calls[i].ID = mask(turn.Calls[i].ID)
+		}
+	}
+}
+
+func compactJSON(raw string) string {
+	var value any
+	if errUnmarshal := json.Unmarshal([]byte(raw), &value); errUnmarshal != nil {
+		return raw
+	}
+	out, _ := json.Marshal(value)
+	return string(out)
+}
+
+func appendFormat(formats []sdktranslator.Format, format sdktranslator.Format) []sdktranslator.Format {
+	for _, existing := range formats {
+		if existing == format {
+			return formats
+		}
+	}
+	formats = append(formats, format)
+	sort.Slice(formats, func(i, j int) bool { return formats[i] < formats[j] })
+	return formats
+}